@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accesspolicy"
+)
+
+// accessPolicyActions are S3 actions of API operations named differently,
+// other operations are checked as s3:<operation name>.
+var accessPolicyActions = map[string]string{
+	"HeadObject":                       "s3:GetObject",
+	"GetObjectACL":                     "s3:GetObjectAcl",
+	"GetObjectAttributes":              "s3:GetObjectAttributes",
+	"SelectObjectContent":              "s3:GetObject",
+	"CopyObject":                       "s3:PutObject",
+	"PutObjectACL":                     "s3:PutObjectAcl",
+	"PostObject":                       "s3:PutObject",
+	"UndeleteObject":                   "s3:PutObject",
+	"CreateMultipartUpload":            "s3:PutObject",
+	"UploadPart":                       "s3:PutObject",
+	"UploadPartCopy":                   "s3:PutObject",
+	"CompleteMultipartUpload":          "s3:PutObject",
+	"ListObjectParts":                  "s3:ListMultipartUploadParts",
+	"ListMultipartUploads":             "s3:ListBucketMultipartUploads",
+	"DeleteMultipleObjects":            "s3:DeleteObject",
+	"PurgePrefix":                      "s3:DeleteObject",
+	"HeadBucket":                       "s3:ListBucket",
+	"ListObjectsV1":                    "s3:ListBucket",
+	"ListObjectsV2":                    "s3:ListBucket",
+	"ListObjectsV2M":                   "s3:ListBucket",
+	"ExportObjects":                    "s3:ListBucket",
+	"SearchObjects":                    "s3:ListBucket",
+	"ListBuckets":                      "s3:ListAllMyBuckets",
+	"GetBucketACL":                     "s3:GetBucketAcl",
+	"PutBucketACL":                     "s3:PutBucketAcl",
+	"GetBucketCors":                    "s3:GetBucketCORS",
+	"PutBucketCors":                    "s3:PutBucketCORS",
+	"DeleteBucketCors":                 "s3:PutBucketCORS",
+	"GetBucketLifecycle":               "s3:GetLifecycleConfiguration",
+	"PutBucketLifecycle":               "s3:PutLifecycleConfiguration",
+	"DeleteBucketLifecycle":            "s3:PutLifecycleConfiguration",
+	"GetBucketEncryption":              "s3:GetEncryptionConfiguration",
+	"PutBucketEncryption":              "s3:PutEncryptionConfiguration",
+	"DeleteBucketEncryption":           "s3:PutEncryptionConfiguration",
+	"GetBucketTagging":                 "s3:GetBucketTagging",
+	"DeleteBucketTagging":              "s3:PutBucketTagging",
+	"GetBucketObjectLockConfig":        "s3:GetBucketObjectLockConfiguration",
+	"PutBucketObjectLockConfig":        "s3:PutBucketObjectLockConfiguration",
+	"GetBucketAccelerate":              "s3:GetAccelerateConfiguration",
+	"GetBucketReplication":             "s3:GetReplicationConfiguration",
+	"GetPublicAccessBlock":             "s3:GetBucketPublicAccessBlock",
+	"PutPublicAccessBlock":             "s3:PutBucketPublicAccessBlock",
+	"GetBucketMetricsConfiguration":    "s3:GetMetricsConfiguration",
+	"ListBucketMetricsConfigurations":  "s3:GetMetricsConfiguration",
+	"PutBucketMetricsConfiguration":    "s3:PutMetricsConfiguration",
+	"DeleteBucketMetricsConfiguration": "s3:PutMetricsConfiguration",
+	"CreateBatchJob":                   "s3:CreateJob",
+	"GetBatchJob":                      "s3:DescribeJob",
+}
+
+// accessPolicyObjectSets are operations on the objects which aren't known
+// before the request is processed, they're checked against all objects of
+// the bucket or of the purged prefix.
+var accessPolicyObjectSets = map[string]struct{}{
+	"DeleteMultipleObjects": {},
+	"PostObject":            {},
+	"PurgePrefix":           {},
+}
+
+// allowedByAccessPolicy checks if the request is allowed by the access
+// policy of the credentials it's made with. Copying requires permission to
+// get the source object additionally.
+func allowedByAccessPolicy(policy *accesspolicy.Document, r *http.Request) bool {
+	reqInfo := GetReqInfo(r.Context())
+	if reqInfo.API == "Options" {
+		return true
+	}
+
+	action, ok := accessPolicyActions[reqInfo.API]
+	if !ok {
+		action = "s3:" + reqInfo.API
+	}
+
+	conditions := accessPolicyConditions(r)
+
+	resource := policyResource(reqInfo.BucketName, reqInfo.ObjectName)
+	if _, ok = accessPolicyObjectSets[reqInfo.API]; ok {
+		var prefix string
+		if reqInfo.API == "PurgePrefix" {
+			prefix = r.URL.Query().Get("prefix")
+		}
+		resource = policyResource(reqInfo.BucketName, prefix+"*")
+	}
+
+	if !policy.IsAllowed(accesspolicy.Request{Action: action, Resource: resource, Conditions: conditions}) {
+		return false
+	}
+
+	if reqInfo.API != "CopyObject" && reqInfo.API != "UploadPartCopy" {
+		return true
+	}
+
+	src, err := url.Parse(r.Header.Get(AmzCopySource))
+	if err != nil {
+		// invalid source is rejected by the handler
+		return true
+	}
+	srcBucket, srcObject, _ := strings.Cut(strings.TrimPrefix(src.Path, "/"), "/")
+
+	return policy.IsAllowed(accesspolicy.Request{
+		Action:     "s3:GetObject",
+		Resource:   policyResource(srcBucket, srcObject),
+		Conditions: conditions,
+	})
+}
+
+func policyResource(bucket, object string) string {
+	switch {
+	case bucket == "":
+		return "*"
+	case object == "":
+		return accesspolicy.ResourcePrefix + bucket
+	default:
+		return accesspolicy.ResourcePrefix + bucket + "/" + object
+	}
+}
+
+// AccessPolicyConditions returns values of access policy condition keys
+// describing the client of the request. The source IP is the address of the
+// connection, X-Forwarded-For and similar headers are ignored since clients
+// set them.
+func AccessPolicyConditions(r *http.Request) map[string]string {
+	sourceIP, _, _ := net.SplitHostPort(r.RemoteAddr)
+	conditions := map[string]string{
+		accesspolicy.ConditionSourceIP:        sourceIP,
+		accesspolicy.ConditionSecureTransport: strconv.FormatBool(r.TLS != nil),
+	}
+
+	if userAgent := r.UserAgent(); userAgent != "" {
+		conditions[accesspolicy.ConditionUserAgent] = userAgent
+	}
+	if referer := r.Referer(); referer != "" {
+		conditions[accesspolicy.ConditionReferer] = referer
+	}
+
+	return conditions
+}
+
+func accessPolicyConditions(r *http.Request) map[string]string {
+	conditions := AccessPolicyConditions(r)
+
+	query := r.URL.Query()
+	for key, name := range map[string]string{
+		"prefix":    accesspolicy.ConditionPrefix,
+		"delimiter": accesspolicy.ConditionDelimiter,
+	} {
+		if query.Has(key) {
+			conditions[name] = query.Get(key)
+		}
+	}
+
+	return conditions
+}
@@ -0,0 +1,107 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/api/signing"
+)
+
+const (
+	// DefaultTimeout is a default timeout of a single validation.
+	DefaultTimeout = 5 * time.Second
+
+	// HeaderBucket is a webhook request header with the bucket name.
+	HeaderBucket = "X-Admission-Bucket"
+	// HeaderKey is a webhook request header with the URL-encoded object key.
+	HeaderKey = "X-Admission-Key"
+)
+
+type (
+	// Options are webhook parameters.
+	Options struct {
+		// URL is a webhook URL with http or https scheme.
+		URL     string
+		Timeout time.Duration
+		// Signer signs webhook requests, it's optional.
+		Signer signing.RequestSigner
+	}
+
+	// Webhook validates uploads by sending handler.AdmissionRequest in JSON
+	// in the body of POST requests, the server responds with
+	// WebhookResponse in JSON. Bucket and key are duplicated in headers, so
+	// they're covered by signatures of payload-less signers.
+	Webhook struct {
+		url    string
+		client *http.Client
+		signer signing.RequestSigner
+	}
+
+	// WebhookResponse is a verdict of the webhook.
+	WebhookResponse struct {
+		Allowed bool   `json:"allowed"`
+		Reason  string `json:"reason,omitempty"`
+	}
+)
+
+// New creates the webhook.
+func New(p *Options) (*Webhook, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported admission webhook url scheme '%s'", u.Scheme)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Webhook{url: p.URL, client: &http.Client{Timeout: timeout}, signer: p.Signer}, nil
+}
+
+// Admit implements handler.Admitter.
+func (x *Webhook) Admit(ctx context.Context, p handler.AdmissionRequest) (*handler.AdmissionVerdict, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderBucket, p.Bucket)
+	req.Header.Set(HeaderKey, url.PathEscape(p.Key))
+	if x.signer != nil {
+		if err = x.signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected webhook response status: %s", resp.Status)
+	}
+
+	var res WebhookResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("decode webhook response: %w", err)
+	}
+
+	return &handler.AdmissionVerdict{Allowed: res.Allowed, Reason: res.Reason}, nil
+}
@@ -0,0 +1,66 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "bucket", r.Header.Get(HeaderBucket))
+		require.Equal(t, "dir%2Fobj%20name", r.Header.Get(HeaderKey))
+
+		var req handler.AdmissionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "PutObject", req.Operation)
+
+		res := WebhookResponse{Allowed: true}
+		if req.Metadata["schema"] != "v2" {
+			res = WebhookResponse{Reason: "schema v2 is required"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer srv.Close()
+
+	a, err := New(&Options{URL: srv.URL})
+	require.NoError(t, err)
+
+	req := handler.AdmissionRequest{
+		Operation: "PutObject",
+		Bucket:    "bucket",
+		Key:       "dir/obj name",
+		Size:      5,
+		Metadata:  map[string]string{"schema": "v1"},
+	}
+	verdict, err := a.Admit(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &handler.AdmissionVerdict{Reason: "schema v2 is required"}, verdict)
+
+	req.Metadata["schema"] = "v2"
+	verdict, err = a.Admit(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, verdict.Allowed)
+}
+
+func TestWebhookFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	a, err := New(&Options{URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = a.Admit(context.Background(), handler.AdmissionRequest{Bucket: "bucket"})
+	require.Error(t, err)
+
+	_, err = New(&Options{URL: "icap://localhost:1344"})
+	require.Error(t, err)
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	v2acl "github.com/nspcc-dev/neofs-api-go/v2/acl"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+type (
+	// CredentialsBackend resolves access key IDs of requests to credentials
+	// of users.
+	CredentialsBackend interface {
+		// ResolveAccessKey returns the box with the secret access key and the
+		// bearer token of the access key ID. It returns InvalidAccessKeyId S3
+		// error if the access key ID is unknown to the backend.
+		ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error)
+	}
+
+	accessBoxBackend struct {
+		boxes tokens.Credentials
+	}
+
+	chainBackend []CredentialsBackend
+
+	// RevocationList reports revoked access key IDs.
+	RevocationList interface {
+		IsRevoked(accessKeyID string) bool
+	}
+
+	revocationBackend struct {
+		creds   CredentialsBackend
+		revoked RevocationList
+	}
+
+	// EpochSource provides the current NeoFS epoch.
+	EpochSource interface {
+		CurrentEpoch() uint64
+	}
+
+	// BearerRenewal re-issues expired bearer tokens of renewable credentials,
+	// they're credentials with the bearer token issued by the Signer itself,
+	// e.g. by the gateway for containers it owns.
+	BearerRenewal struct {
+		Signer user.Signer
+		// Lifetime of the re-issued token in epochs.
+		Lifetime uint64
+	}
+
+	lifetimeBackend struct {
+		creds   CredentialsBackend
+		epochs  EpochSource
+		renewal *BearerRenewal
+
+		mu sync.Mutex
+		// renewed are boxes with re-issued tokens by access key IDs.
+		renewed map[string]renewedBox
+	}
+
+	renewedBox struct {
+		// original is the token the box is renewed from.
+		original []byte
+		box      *accessbox.Box
+		exp      uint64
+	}
+)
+
+// NewAccessBoxBackend creates a backend resolving access key IDs issued by
+// authmate, they're addresses of access boxes stored in NeoFS with '0'
+// (zero) delimiter of container and object IDs.
+func NewAccessBoxBackend(boxes tokens.Credentials) CredentialsBackend {
+	return &accessBoxBackend{boxes: boxes}
+}
+
+func (b *accessBoxBackend) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	addr, err := accessBoxAddress(accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	box, err := b.boxes.GetBox(ctx, addr)
+	if err != nil {
+		// boxes removed or issued for other gateways are unknown as missing ones
+		if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) ||
+			errors.Is(err, apistatus.ErrContainerNotFound) || errors.Is(err, accessbox.ErrGateNotFound) {
+			return nil, fmt.Errorf("%w: get box: %s", s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID), err)
+		}
+		return nil, fmt.Errorf("get box: %w", err)
+	}
+
+	return box, nil
+}
+
+func accessBoxAddress(accessKeyID string) (oid.Address, error) {
+	var addr oid.Address
+	if err := addr.DecodeString(strings.ReplaceAll(accessKeyID, "0", "/")); err != nil {
+		return addr, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+	}
+	return addr, nil
+}
+
+// NewChainBackend creates a backend trying backends in the given order, the
+// next backend is tried if the access key ID is unknown to the previous one.
+func NewChainBackend(backends ...CredentialsBackend) CredentialsBackend {
+	if len(backends) == 1 {
+		return backends[0]
+	}
+	return chainBackend(backends)
+}
+
+func (c chainBackend) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	for _, backend := range c {
+		box, err := backend.ResolveAccessKey(ctx, accessKeyID)
+		if err == nil || !errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)) {
+			return box, err
+		}
+	}
+
+	return nil, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+}
+
+// NewRevocationBackend creates a backend rejecting revoked access key IDs
+// before resolving them with creds. Temporary credentials are resolved via
+// the credentials they are issued for, so they are revoked with them.
+func NewRevocationBackend(creds CredentialsBackend, revoked RevocationList) CredentialsBackend {
+	return &revocationBackend{creds: creds, revoked: revoked}
+}
+
+func (r *revocationBackend) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	if r.revoked.IsRevoked(accessKeyID) {
+		return nil, fmt.Errorf("%w: access key id '%s' is revoked",
+			s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID), accessKeyID)
+	}
+
+	return r.creds.ResolveAccessKey(ctx, accessKeyID)
+}
+
+// NewLifetimeBackend creates a backend rejecting credentials with the bearer
+// token expired according to epochs with ExpiredToken S3 error. Expired tokens
+// of credentials marked renewable and issued by the renewal signer are
+// re-issued instead if renewal isn't nil, the token is re-issued once and
+// used until it expires.
+func NewLifetimeBackend(creds CredentialsBackend, epochs EpochSource, renewal *BearerRenewal) CredentialsBackend {
+	return &lifetimeBackend{
+		creds:   creds,
+		epochs:  epochs,
+		renewal: renewal,
+		renewed: make(map[string]renewedBox),
+	}
+}
+
+func (l *lifetimeBackend) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	box, err := l.creds.ResolveAccessKey(ctx, accessKeyID)
+	if err != nil || box.Gate == nil || box.Gate.BearerToken == nil {
+		return box, err
+	}
+
+	// not before and issued at claims aren't checked, the epoch of the
+	// gateway can be behind the network one
+	epoch := l.epochs.CurrentEpoch()
+	exp := BearerExpiration(box.Gate.BearerToken)
+	if exp >= epoch {
+		return box, nil
+	}
+
+	if l.renewal != nil && box.Renewable && box.Gate.BearerToken.ResolveIssuer().Equals(l.renewal.Signer.UserID()) {
+		return l.renew(accessKeyID, box, epoch)
+	}
+
+	return nil, fmt.Errorf("%w: bearer token of access key id '%s' expired at epoch %d",
+		s3errors.GetAPIError(s3errors.ErrExpiredToken), accessKeyID, exp)
+}
+
+func (l *lifetimeBackend) renew(accessKeyID string, box *accessbox.Box, epoch uint64) (*accessbox.Box, error) {
+	original := box.Gate.BearerToken.Marshal()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if prev, ok := l.renewed[accessKeyID]; ok && prev.exp >= epoch && bytes.Equal(prev.original, original) {
+		return prev.box, nil
+	}
+
+	var token bearer.Token
+	if err := token.Unmarshal(original); err != nil {
+		return nil, fmt.Errorf("unmarshal bearer token: %w", err)
+	}
+	token.SetIat(epoch)
+	token.SetNbf(epoch)
+	token.SetExp(epoch + l.renewal.Lifetime)
+	if err := token.Sign(l.renewal.Signer); err != nil {
+		return nil, fmt.Errorf("sign renewed bearer token: %w", err)
+	}
+
+	// the box can be cached by the backend, so it isn't modified
+	renewed := *box
+	gate := *box.Gate
+	gate.BearerToken = &token
+	renewed.Gate = &gate
+
+	// tokens are renewed once per lifetime, so expired boxes are dropped here
+	// instead of a separate sweep
+	for id, prev := range l.renewed {
+		if prev.exp < epoch {
+			delete(l.renewed, id)
+		}
+	}
+	l.renewed[accessKeyID] = renewedBox{original: original, box: &renewed, exp: epoch + l.renewal.Lifetime}
+
+	return &renewed, nil
+}
+
+// BearerExpiration returns the last epoch the token is valid in.
+func BearerExpiration(token *bearer.Token) uint64 {
+	var m v2acl.BearerToken
+	token.WriteToV2(&m)
+	return m.GetBody().GetLifetime().GetExp()
+}
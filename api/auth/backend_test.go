@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+type staticBackend map[string]*accessbox.Box
+
+func (b staticBackend) ResolveAccessKey(_ context.Context, accessKeyID string) (*accessbox.Box, error) {
+	box, ok := b[accessKeyID]
+	if !ok {
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+	}
+	return box, nil
+}
+
+type failingBackend struct{}
+
+func (failingBackend) ResolveAccessKey(context.Context, string) (*accessbox.Box, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestChainBackend(t *testing.T) {
+	ctx := context.Background()
+	addr := oidtest.Address()
+	accessKeyID := accessKeyIDOf(addr)
+
+	boxBox := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "box"}}
+	fileBox := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "file"}}
+
+	chain := NewChainBackend(
+		NewAccessBoxBackend(&credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: boxBox}}),
+		staticBackend{"static-key": fileBox},
+	)
+
+	box, err := chain.ResolveAccessKey(ctx, accessKeyID)
+	require.NoError(t, err)
+	require.Equal(t, boxBox, box)
+
+	box, err = chain.ResolveAccessKey(ctx, "static-key")
+	require.NoError(t, err)
+	require.Equal(t, fileBox, box)
+
+	_, err = chain.ResolveAccessKey(ctx, "unknown-key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	// failures other than unknown access key aren't hidden by the next backends
+	chain = NewChainBackend(failingBackend{}, staticBackend{"static-key": fileBox})
+	_, err = chain.ResolveAccessKey(ctx, "static-key")
+	require.Error(t, err)
+	require.False(t, errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)))
+}
+
+type failingBoxes struct {
+	tokens.Credentials
+	err error
+}
+
+func (f failingBoxes) GetBox(context.Context, oid.Address) (*accessbox.Box, error) {
+	return nil, fmt.Errorf("get access box: %w", f.err)
+}
+
+func TestAccessBoxBackendErrors(t *testing.T) {
+	ctx := context.Background()
+	accessKeyID := accessKeyIDOf(oidtest.Address())
+
+	// missing boxes and boxes of other gateways are unknown access keys
+	for _, err := range []error{
+		apistatus.ErrObjectNotFound,
+		apistatus.ErrObjectAlreadyRemoved,
+		apistatus.ErrContainerNotFound,
+		accessbox.ErrGateNotFound,
+	} {
+		_, err = NewAccessBoxBackend(failingBoxes{err: err}).ResolveAccessKey(ctx, accessKeyID)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+	}
+
+	_, err := NewAccessBoxBackend(failingBoxes{err: errors.New("unavailable")}).ResolveAccessKey(ctx, accessKeyID)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)))
+}
+
+func accessKeyIDOf(addr oid.Address) string {
+	return addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString()
+}
+
+type revokedKeys map[string]bool
+
+func (r revokedKeys) IsRevoked(accessKeyID string) bool {
+	return r[accessKeyID]
+}
+
+func TestRevocationBackend(t *testing.T) {
+	ctx := context.Background()
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "secret"}}
+	revoked := revokedKeys{}
+	backend := NewRevocationBackend(staticBackend{"key": box}, revoked)
+
+	res, err := backend.ResolveAccessKey(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, box, res)
+
+	revoked["key"] = true
+	_, err = backend.ResolveAccessKey(ctx, "key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+}
+
+type epochs uint64
+
+func (e *epochs) CurrentEpoch() uint64 {
+	return uint64(*e)
+}
+
+func bearerBox(t *testing.T, signer user.Signer, exp uint64) *accessbox.Box {
+	var token bearer.Token
+	token.SetExp(exp)
+	require.NoError(t, token.Sign(signer))
+
+	return &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "secret", BearerToken: &token}}
+}
+
+func TestLifetimeBackend(t *testing.T) {
+	ctx := context.Background()
+
+	userKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	gateSigner := user.NewAutoIDSignerRFC6979(gateKey.PrivateKey)
+
+	userBox := bearerBox(t, user.NewAutoIDSignerRFC6979(userKey.PrivateKey), 10)
+	gateBox := bearerBox(t, gateSigner, 10)
+	gateBox.Renewable = true
+	stsBox := bearerBox(t, gateSigner, 10)
+	creds := staticBackend{"user": userBox, "gate": gateBox, "sts": stsBox}
+
+	current := epochs(10)
+	backend := NewLifetimeBackend(creds, &current, nil)
+
+	res, err := backend.ResolveAccessKey(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, userBox, res)
+
+	current++
+	_, err = backend.ResolveAccessKey(ctx, "user")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+	_, err = backend.ResolveAccessKey(ctx, "gate")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+
+	_, err = backend.ResolveAccessKey(ctx, "unknown")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	t.Run("renewal", func(t *testing.T) {
+		backend := NewLifetimeBackend(creds, &current, &BearerRenewal{Signer: gateSigner, Lifetime: 5})
+
+		// tokens issued by other users can't be renewed by the gateway
+		_, err = backend.ResolveAccessKey(ctx, "user")
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+
+		// tokens issued by the gateway without the renewable marker expire
+		_, err = backend.ResolveAccessKey(ctx, "sts")
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+
+		renewed, err := backend.ResolveAccessKey(ctx, "gate")
+		require.NoError(t, err)
+		require.Equal(t, "secret", renewed.Gate.AccessKey)
+		require.True(t, renewed.Gate.BearerToken.VerifySignature())
+		require.False(t, renewed.Gate.BearerToken.InvalidAt(uint64(current)))
+		require.False(t, renewed.Gate.BearerToken.InvalidAt(uint64(current)+5))
+		require.True(t, renewed.Gate.BearerToken.InvalidAt(uint64(current)+6))
+		require.True(t, gateBox.Gate.BearerToken.InvalidAt(uint64(current)))
+
+		// the renewed token is used until it expires
+		current += 5
+		res, err := backend.ResolveAccessKey(ctx, "gate")
+		require.NoError(t, err)
+		require.Same(t, renewed, res)
+
+		current++
+		res, err = backend.ResolveAccessKey(ctx, "gate")
+		require.NoError(t, err)
+		require.NotSame(t, renewed, res)
+		require.False(t, res.Gate.BearerToken.InvalidAt(uint64(current)))
+
+		// expired renewals are dropped
+		other := bearerBox(t, gateSigner, 10)
+		other.Renewable = true
+		creds["other"] = other
+		current += 6
+		_, err = backend.ResolveAccessKey(ctx, "other")
+		require.NoError(t, err)
+		require.Len(t, backend.(*lifetimeBackend).renewed, 1)
+		require.Contains(t, backend.(*lifetimeBackend).renewed, "other")
+	})
+}
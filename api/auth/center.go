@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -10,24 +11,26 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
-	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
-	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/limits"
-	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 )
 
 // authorizationFieldRegexp -- is regexp for credentials with Base58 encoded cid and oid and '0' (zero) as delimiter.
 var authorizationFieldRegexp = regexp.MustCompile(`AWS4-HMAC-SHA256 Credential=(?P<access_key_id>[^/]+)/(?P<date>[^/]+)/(?P<region>[^/]*)/(?P<service>[^/]+)/aws4_request,\s*SignedHeaders=(?P<signed_header_fields>.+),\s*Signature=(?P<v4_signature>.+)`)
 
+// authorizationV4ARegexp -- is regexp for SigV4A credentials, their scope doesn't include the region.
+var authorizationV4ARegexp = regexp.MustCompile(`AWS4-ECDSA-P256-SHA256 Credential=(?P<access_key_id>[^/]+)/(?P<date>[^/]+)/(?P<service>[^/]+)/aws4_request,\s*SignedHeaders=(?P<signed_header_fields>.+),\s*Signature=(?P<v4_signature>.+)`)
+
 // postPolicyCredentialRegexp -- is regexp for credentials when uploading file using POST with policy.
 var postPolicyCredentialRegexp = regexp.MustCompile(`(?P<access_key_id>[^/]+)/(?P<date>[^/]+)/(?P<region>[^/]*)/(?P<service>[^/]+)/aws4_request`)
 
@@ -37,21 +40,56 @@ type (
 		Authenticate(request *http.Request) (*Box, error)
 	}
 
+	// Scope restricts credential scopes of signatures, any region and service
+	// are accepted if they're empty.
+	Scope struct {
+		// Region is the canonical region of the gateway.
+		Region string
+		// AdditionalRegions are accepted along with the canonical one, e.g.
+		// us-east-1 of clients with the default configuration.
+		AdditionalRegions []string
+		// RegionMismatch defines what to do with signatures of other regions.
+		RegionMismatch RegionMismatch
+		// Services are accepted services.
+		Services []string
+	}
+
+	// RegionMismatch defines what to do with signatures whose credential
+	// scopes have regions other than the accepted ones.
+	RegionMismatch string
+
 	// Box contains access box and additional info.
 	Box struct {
 		AccessBox  *accessbox.Box
 		ClientTime time.Time
+		// AccessKeyID is the access key ID the request is signed with.
+		AccessKeyID string
+		// Session is set if the request is signed with temporary credentials.
+		Session *Session
 	}
 
 	center struct {
 		reg                        *RegexpSubmatcher
+		regV4A                     *RegexpSubmatcher
+		regV2                      *RegexpSubmatcher
 		postReg                    *RegexpSubmatcher
-		cli                        tokens.Credentials
+		creds                      CredentialsBackend
+		sessions                   *Sessions
 		allowedAccessKeyIDPrefixes []string // empty slice means all access key ids are allowed
+		// maxClockSkew is the allowed difference between the signature time
+		// and the gateway time, zero disables the check.
+		maxClockSkew time.Duration
+		// denyUnsignedPayload rejects requests signed with headers if their
+		// payloads aren't signed.
+		denyUnsignedPayload bool
+		// allowSignatureV2 accepts requests signed with AWS Signature
+		// Version 2.
+		allowSignatureV2 bool
+		scope            Scope
+		// replays rejects replayed requests signed with headers if it's set.
+		replays *ReplayCache
 	}
 
-	prs int
-
 	authHeader struct {
 		AccessKeyID  string
 		Service      string
@@ -61,13 +99,15 @@ type (
 		Date         string
 		IsPresigned  bool
 		Expiration   time.Duration
+		// Asymmetric is set for SigV4A signatures.
+		Asymmetric bool
 	}
 )
 
 const (
-	accessKeyPartsNum  = 2
-	authHeaderPartsNum = 6
-	maxFormSizeMemory  = 50 * 1048576 // 50 MB
+	authHeaderPartsNum    = 6
+	authHeaderV4APartsNum = 5
+	maxFormSizeMemory     = 50 * 1048576 // 50 MB
 
 	AmzAlgorithm              = "X-Amz-Algorithm"
 	AmzCredential             = "X-Amz-Credential"
@@ -75,10 +115,28 @@ const (
 	AmzSignedHeaders          = "X-Amz-SignedHeaders"
 	AmzExpires                = "X-Amz-Expires"
 	AmzDate                   = "X-Amz-Date"
+	DateHdr                   = "Date"
 	AuthorizationHdr          = "Authorization"
 	ContentTypeHdr            = "Content-Type"
 	ContentEncodingHdr        = "Content-Encoding"
 	ContentEncodingAwsChunked = "aws-chunked"
+	AmzContentSHA256          = "X-Amz-Content-Sha256"
+	AmzDecodedContentLength   = "X-Amz-Decoded-Content-Length"
+	AmzTrailer                = "X-Amz-Trailer"
+	AmzRegionSet              = "X-Amz-Region-Set"
+
+	// UnsignedPayload is X-Amz-Content-Sha256 value of payloads which
+	// aren't signed.
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// StreamingContentSHA256 is X-Amz-Content-Sha256 value of aws-chunked
+	// payload with signed chunks.
+	StreamingContentSHA256 = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	// StreamingContentSHA256Trailer is X-Amz-Content-Sha256 value of
+	// aws-chunked payload with signed chunks and signed trailing headers.
+	StreamingContentSHA256Trailer = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	// StreamingUnsignedPayloadTrailer is X-Amz-Content-Sha256 value of
+	// aws-chunked payload with unsigned chunks and trailing headers.
+	StreamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
 
 	timeFormatISO8601 = "20060102T150405Z"
 )
@@ -86,37 +144,62 @@ const (
 // ErrNoAuthorizationHeader is returned for unauthenticated requests.
 var ErrNoAuthorizationHeader = errors.New("no authorization header")
 
-func (p prs) Read(_ []byte) (n int, err error) {
-	panic("implement me")
-}
+const (
+	// RegionMismatchReject rejects signatures with AuthorizationHeaderMalformed.
+	RegionMismatchReject RegionMismatch = "reject"
+	// RegionMismatchIgnore accepts signatures of any region, they're still
+	// verified with the region of the scope.
+	RegionMismatchIgnore RegionMismatch = "ignore"
+)
 
-func (p prs) Seek(_ int64, _ int) (int64, error) {
-	panic("implement me")
+// ParseRegionMismatch parses the region mismatch policy, empty string means
+// RegionMismatchReject.
+func ParseRegionMismatch(s string) (RegionMismatch, error) {
+	switch policy := RegionMismatch(s); policy {
+	case "", RegionMismatchReject:
+		return RegionMismatchReject, nil
+	case RegionMismatchIgnore:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown region mismatch policy '%s'", s)
+	}
 }
 
-var _ io.ReadSeeker = prs(0)
-
-// New creates an instance of AuthCenter.
-func New(neoFS tokens.NeoFS, key *keys.PrivateKey, prefixes []string, config *cache.Config) Center {
+// New creates an instance of AuthCenter resolving credentials via creds.
+// Temporary credentials are resolved via sessions if they are not nil.
+// Requests signed with headers are rejected if the signature time differs
+// from the gateway time by more than maxClockSkew, unless it's zero. Payloads
+// of requests signed with headers must be signed unless allowUnsignedPayload
+// is set. AWS Signature Version 2 is accepted if allowSignatureV2 is set.
+// Signatures with credential scopes other than the given one are rejected.
+// Replayed requests signed with headers are rejected if replays are not nil.
+func New(creds CredentialsBackend, sessions *Sessions, prefixes []string, maxClockSkew time.Duration, allowUnsignedPayload, allowSignatureV2 bool, scope Scope, replays *ReplayCache) Center {
 	return &center{
-		cli:                        tokens.New(neoFS, key, config),
+		creds:                      creds,
+		sessions:                   sessions,
 		reg:                        NewRegexpMatcher(authorizationFieldRegexp),
+		regV4A:                     NewRegexpMatcher(authorizationV4ARegexp),
+		regV2:                      NewRegexpMatcher(authorizationV2Regexp),
 		postReg:                    NewRegexpMatcher(postPolicyCredentialRegexp),
 		allowedAccessKeyIDPrefixes: prefixes,
+		maxClockSkew:               maxClockSkew,
+		denyUnsignedPayload:        !allowUnsignedPayload,
+		allowSignatureV2:           allowSignatureV2,
+		scope:                      scope,
+		replays:                    replays,
 	}
 }
 
 func (c *center) parseAuthHeader(header string) (*authHeader, error) {
+	if strings.HasPrefix(header, v4.AsymmetricAlgorithm+" ") {
+		return c.parseAuthHeaderV4A(header)
+	}
+
 	submatches := c.reg.GetSubmatches(header)
 	if len(submatches) != authHeaderPartsNum {
 		return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
 	}
 
-	accessKey := strings.Split(submatches["access_key_id"], "0")
-	if len(accessKey) != accessKeyPartsNum {
-		return nil, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
-	}
-
 	signedFields := strings.Split(submatches["signed_header_fields"], ";")
 
 	return &authHeader{
@@ -129,15 +212,108 @@ func (c *center) parseAuthHeader(header string) (*authHeader, error) {
 	}, nil
 }
 
-func (a *authHeader) getAddress() (oid.Address, error) {
-	var addr oid.Address
-	if err := addr.DecodeString(strings.ReplaceAll(a.AccessKeyID, "0", "/")); err != nil {
-		return addr, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+func (c *center) parseAuthHeaderV4A(header string) (*authHeader, error) {
+	submatches := c.regV4A.GetSubmatches(header)
+	if len(submatches) != authHeaderV4APartsNum {
+		return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
 	}
-	return addr, nil
+
+	return &authHeader{
+		AccessKeyID:  submatches["access_key_id"],
+		Service:      submatches["service"],
+		SignatureV4:  submatches["v4_signature"],
+		SignedFields: strings.Split(submatches["signed_header_fields"], ";"),
+		Date:         submatches["date"],
+		Asymmetric:   true,
+	}, nil
+}
+
+// parsePresignedQuery parses query-string authentication parameters of the
+// presigned URL.
+func parsePresignedQuery(query url.Values) (*authHeader, error) {
+	// SigV4A credential scope doesn't include the region
+	var asymmetric bool
+	switch query.Get(AmzAlgorithm) {
+	case "AWS4-HMAC-SHA256":
+	case v4.AsymmetricAlgorithm:
+		asymmetric = true
+	default:
+		return nil, s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported)
+	}
+
+	for _, param := range []string{AmzCredential, AmzSignature, AmzSignedHeaders, AmzDate, AmzExpires} {
+		if query.Get(param) == "" {
+			return nil, s3errors.GetAPIError(s3errors.ErrInvalidQueryParams)
+		}
+	}
+
+	creds := strings.Split(query.Get(AmzCredential), "/")
+	if asymmetric && len(creds) == 4 {
+		creds = []string{creds[0], creds[1], "", creds[2], creds[3]}
+	}
+	if len(creds) != 5 || creds[4] != "aws4_request" {
+		return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
+	}
+
+	expires, err := strconv.ParseInt(query.Get(AmzExpires), 10, 64)
+	if err != nil {
+		return nil, s3errors.GetAPIError(s3errors.ErrMalformedExpires)
+	}
+	if expires < 0 {
+		return nil, s3errors.GetAPIError(s3errors.ErrNegativeExpires)
+	}
+
+	expiration := time.Duration(expires) * time.Second
+	if expiration > limits.MaxPreSignedLifetime {
+		return nil, s3errors.GetAPIError(s3errors.ErrMaximumExpires)
+	}
+
+	return &authHeader{
+		AccessKeyID:  creds[0],
+		Service:      creds[3],
+		Region:       creds[2],
+		SignatureV4:  query.Get(AmzSignature),
+		SignedFields: strings.Split(query.Get(AmzSignedHeaders), ";"),
+		Date:         creds[1],
+		IsPresigned:  true,
+		Expiration:   expiration,
+		Asymmetric:   asymmetric,
+	}, nil
 }
 
 func (c *center) Authenticate(r *http.Request) (*Box, error) {
+	box, err := c.authenticate(r)
+	// anonymous requests aren't authenticated
+	if !errors.Is(err, ErrNoAuthorizationHeader) {
+		metrics.IncAuthentications(authenticationResult(err))
+	}
+
+	return box, err
+}
+
+// authenticationResult returns the label of the authentication result in
+// metrics.
+func authenticationResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)):
+		return "bad_signature"
+	case errors.Is(err, s3errors.GetAPIError(s3errors.ErrExpiredToken)),
+		errors.Is(err, s3errors.GetAPIError(s3errors.ErrExpiredPresignRequest)),
+		errors.Is(err, s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed)):
+		return "expired"
+	case errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)),
+		errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidToken)):
+		return "unknown_key"
+	case errors.Is(err, s3errors.GetAPIError(s3errors.ErrAccessDenied)):
+		return "access_denied"
+	default:
+		return "other"
+	}
+}
+
+func (c *center) authenticate(r *http.Request) (*Box, error) {
 	var (
 		err                  error
 		authHdr              *authHeader
@@ -146,30 +322,13 @@ func (c *center) Authenticate(r *http.Request) (*Box, error) {
 	)
 
 	queryValues := r.URL.Query()
-	if queryValues.Get(AmzAlgorithm) == "AWS4-HMAC-SHA256" {
-		creds := strings.Split(queryValues.Get(AmzCredential), "/")
-		if len(creds) != 5 || creds[4] != "aws4_request" {
-			return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
-		}
-		authHdr = &authHeader{
-			AccessKeyID:  creds[0],
-			Service:      creds[3],
-			Region:       creds[2],
-			SignatureV4:  queryValues.Get(AmzSignature),
-			SignedFields: queryValues[AmzSignedHeaders],
-			Date:         creds[1],
-			IsPresigned:  true,
-		}
-		authHdr.Expiration, err = time.ParseDuration(queryValues.Get(AmzExpires) + "s")
-		if err != nil {
-			return nil, fmt.Errorf("couldn't parse X-Amz-Expires: %w", err)
-		}
-
-		if authHdr.Expiration > limits.MaxPreSignedLifetime {
-			return nil, s3errors.GetAPIError(s3errors.ErrMaximumExpires)
+	if queryValues.Has(AmzAlgorithm) {
+		if authHdr, err = parsePresignedQuery(queryValues); err != nil {
+			return nil, err
 		}
-
 		signatureDateTimeStr = queryValues.Get(AmzDate)
+	} else if isPresignedV2(queryValues) {
+		return c.authenticateV2(r, "")
 	} else {
 		authHeaderField := r.Header[AuthorizationHdr]
 		if len(authHeaderField) != 1 {
@@ -178,55 +337,205 @@ func (c *center) Authenticate(r *http.Request) (*Box, error) {
 			}
 			return nil, ErrNoAuthorizationHeader
 		}
+		if strings.HasPrefix(authHeaderField[0], "AWS ") {
+			return c.authenticateV2(r, authHeaderField[0])
+		}
 		authHdr, err = c.parseAuthHeader(authHeaderField[0])
 		if err != nil {
 			return nil, err
 		}
-		signatureDateTimeStr = r.Header.Get(AmzDate)
+		// Date header is used if some proxy or SDK removes X-Amz-Date.
+		if signatureDateTimeStr = r.Header.Get(AmzDate); signatureDateTimeStr == "" {
+			signatureDateTimeStr = r.Header.Get(DateHdr)
+		}
 		needClientTime = true
 	}
 
-	signatureDateTime, err := time.Parse(timeFormatISO8601, signatureDateTimeStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse x-amz-date header field: %w", err)
+	if signatureDateTimeStr == "" {
+		return nil, s3errors.GetAPIError(s3errors.ErrMissingDateHeader)
 	}
 
-	if err := c.checkAccessKeyID(authHdr.AccessKeyID); err != nil {
+	if err = c.checkScope(authHdr, r); err != nil {
 		return nil, err
 	}
 
-	addr, err := authHdr.getAddress()
+	signatureDateTime, err := parseSignatureTime(signatureDateTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request date '%s': %w", signatureDateTimeStr, err)
+	}
+	// presigned URLs are checked against their expiration instead
+	if needClientTime {
+		if err = c.checkClockSkew(signatureDateTime); err != nil {
+			return nil, err
+		}
+	}
+
+	sessionToken := r.Header.Get(SessionTokenHdr)
+	if authHdr.IsPresigned {
+		sessionToken = querySessionToken(queryValues)
+	}
+
+	box, session, err := c.resolveCredentials(r.Context(), authHdr.AccessKeyID, sessionToken)
 	if err != nil {
 		return nil, err
 	}
 
-	box, err := c.cli.GetBox(r.Context(), addr)
+	algorithm := "v4"
+	if authHdr.Asymmetric {
+		algorithm = "v4a"
+	}
+	start := time.Now()
+	err = c.checkSign(authHdr, box, sessionToken, r, signatureDateTime)
+	metrics.ObserveSignatureVerification(algorithm, time.Since(start))
 	if err != nil {
-		return nil, fmt.Errorf("get box: %w", err)
+		return nil, err
 	}
 
-	clonedRequest := cloneRequest(r, authHdr)
-	if err = c.checkSign(authHdr, box, clonedRequest, signatureDateTime); err != nil {
+	if needClientTime {
+		if err = c.checkReplay(authHdr.AccessKeyID, authHdr.SignatureV4, signatureDateTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if IsStreamingPayload(r.Header) {
+		if c.denyUnsignedPayload && !authHdr.IsPresigned && r.Header.Get(AmzContentSHA256) == StreamingUnsignedPayloadTrailer {
+			return nil, fmt.Errorf("%w: unsigned payloads aren't allowed", s3errors.GetAPIError(s3errors.ErrAccessDenied))
+		}
+
+		awsCreds := credentials.NewStaticCredentials(authHdr.AccessKeyID, box.Gate.AccessKey, sessionToken)
+		if r.Body, err = streamingPayloadReader(r, authHdr, awsCreds, signatureDateTime); err != nil {
+			return nil, err
+		}
+
+		if size, err := strconv.ParseInt(r.Header.Get(AmzDecodedContentLength), 10, 64); err == nil && size >= 0 {
+			r.Body = &decodedLengthReader{ReadCloser: r.Body, left: size}
+		}
+	} else if err = c.checkPayloadHash(r, authHdr); err != nil {
 		return nil, err
 	}
 
-	if hdr := r.Header.Get(ContentEncodingHdr); hdr == ContentEncodingAwsChunked {
+	result := &Box{AccessBox: box, AccessKeyID: authHdr.AccessKeyID, Session: session}
+	if needClientTime {
+		result.ClientTime = signatureDateTime
+	}
+
+	return result, nil
+}
+
+// checkPayloadHash verifies the payload against X-Amz-Content-Sha256 header
+// while it's read. Presigned URLs don't sign payloads, so they're allowed to
+// be unsigned.
+func (c *center) checkPayloadHash(r *http.Request, authHdr *authHeader) error {
+	switch contentSHA256 := r.Header.Get(AmzContentSHA256); contentSHA256 {
+	case "":
+		return nil
+	case UnsignedPayload:
+		if c.denyUnsignedPayload && !authHdr.IsPresigned {
+			return fmt.Errorf("%w: unsigned payloads aren't allowed", s3errors.GetAPIError(s3errors.ErrAccessDenied))
+		}
+		return nil
+	default:
+		return verifyPayloadHash(r, contentSHA256)
+	}
+}
+
+// IsAwsChunkedEncoding checks whether the Content-Encoding header value
+// contains aws-chunked encoding, possibly combined with other encodings
+// (e.g. "aws-chunked,gzip").
+func IsAwsChunkedEncoding(contentEncoding string) bool {
+	for _, enc := range strings.Split(contentEncoding, ",") {
+		if strings.TrimSpace(enc) == ContentEncodingAwsChunked {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsStreamingPayload checks whether the request payload is sent in aws-chunked
+// encoding. Some clients declare it with X-Amz-Content-Sha256 only and don't
+// set Content-Encoding.
+func IsStreamingPayload(header http.Header) bool {
+	switch header.Get(AmzContentSHA256) {
+	case StreamingContentSHA256, StreamingContentSHA256Trailer, StreamingUnsignedPayloadTrailer:
+		return true
+	}
+	return IsAwsChunkedEncoding(header.Get(ContentEncodingHdr))
+}
+
+// streamingPayloadReader decodes aws-chunked payload checking signatures of
+// chunks and the trailing checksum. Chunks of STREAMING-UNSIGNED-PAYLOAD-TRAILER
+// payload aren't signed, so it's allowed with SigV4A signature.
+func streamingPayloadReader(r *http.Request, authHdr *authHeader, awsCreds *credentials.Credentials, signatureDateTime time.Time) (io.ReadCloser, error) {
+	contentSHA256 := r.Header.Get(AmzContentSHA256)
+
+	var streamSigner *v4.ChunkSigner
+	if contentSHA256 != StreamingUnsignedPayloadTrailer {
+		if authHdr.Asymmetric {
+			return nil, fmt.Errorf("%w: aws-chunked payload with SigV4A signature", s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+		}
+
 		sig, err := hex.DecodeString(authHdr.SignatureV4)
 		if err != nil {
 			return nil, fmt.Errorf("DecodeString: %w", err)
 		}
+		streamSigner = v4.NewChunkSigner(authHdr.Region, authHdr.Service, sig, signatureDateTime, awsCreds)
+	}
 
-		awsCreds := credentials.NewStaticCredentials(authHdr.AccessKeyID, box.Gate.AccessKey, "")
-		streamSigner := v4.NewChunkSigner(authHdr.Region, authHdr.Service, sig, signatureDateTime, awsCreds)
-		r.Body = v4.NewChunkedReader(r.Body, streamSigner)
+	if contentSHA256 != StreamingContentSHA256Trailer && contentSHA256 != StreamingUnsignedPayloadTrailer {
+		return v4.NewChunkedReader(r.Body, streamSigner), nil
 	}
 
-	result := &Box{AccessBox: box}
-	if needClientTime {
-		result.ClientTime = signatureDateTime
+	return newTrailerChecksumReader(v4.NewTrailerChunkedReader(r.Body, streamSigner), r.Header.Get(AmzTrailer))
+}
+
+// decodedLengthReader fails if the size of the decoded aws-chunked payload
+// differs from X-Amz-Decoded-Content-Length.
+type decodedLengthReader struct {
+	io.ReadCloser
+	left int64
+}
+
+func (r *decodedLengthReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.left -= int64(n)
+	if r.left < 0 || (err == io.EOF && r.left != 0) {
+		return n, s3errors.GetAPIError(s3errors.ErrIncompleteBody)
 	}
+	return n, err
+}
 
-	return result, nil
+// resolveCredentials returns the access box of the access key ID. Temporary
+// credentials are resolved if the session token is set, the box of the
+// credentials they are issued for is returned with the temporary secret then.
+func (c *center) resolveCredentials(ctx context.Context, accessKeyID, sessionToken string) (*accessbox.Box, *Session, error) {
+	var session *Session
+	if sessionToken != "" {
+		if c.sessions == nil {
+			return nil, nil, s3errors.GetAPIError(s3errors.ErrInvalidToken)
+		}
+
+		var err error
+		if session, err = c.sessions.Resolve(sessionToken, accessKeyID, time.Now()); err != nil {
+			return nil, nil, err
+		}
+		accessKeyID = session.ParentAccessKeyID
+	}
+
+	if err := c.checkAccessKeyID(accessKeyID); err != nil {
+		return nil, nil, err
+	}
+
+	box, err := c.creds.ResolveAccessKey(ctx, accessKeyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve access key: %w", err)
+	}
+
+	if session != nil {
+		box = session.box(box)
+	}
+
+	return box, session, nil
 }
 
 func (c center) checkAccessKeyID(accessKeyID string) error {
@@ -257,63 +566,153 @@ func (c *center) checkFormData(r *http.Request) (*Box, error) {
 		return nil, ErrNoAuthorizationHeader
 	}
 
+	if algorithm := MultipartFormValue(r, strings.ToLower(AmzAlgorithm)); algorithm != postPolicyAlgorithm {
+		return nil, fmt.Errorf("%w: unsupported algorithm '%s'", s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported), algorithm)
+	}
+
 	submatches := c.postReg.GetSubmatches(MultipartFormValue(r, strings.ToLower(AmzCredential)))
 	if len(submatches) != 4 {
 		return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
 	}
 
-	signatureDateTime, err := time.Parse("20060102T150405Z", MultipartFormValue(r, "x-amz-date"))
+	signatureDateTime, err := parseSignatureTime(MultipartFormValue(r, "x-amz-date"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse x-amz-date field: %w", err)
 	}
 
-	var addr oid.Address
-	if err = addr.DecodeString(strings.ReplaceAll(submatches["access_key_id"], "0", "/")); err != nil {
-		return nil, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+	if date := signatureDateTime.UTC().Format("20060102"); submatches["date"] != date {
+		return nil, fmt.Errorf("%w: credential date '%s' doesn't match x-amz-date", s3errors.GetAPIError(s3errors.ErrCredMalformed), submatches["date"])
+	}
+
+	if err = c.checkScope(&authHeader{Service: submatches["service"], Region: submatches["region"]}, r); err != nil {
+		return nil, err
 	}
 
-	box, err := c.cli.GetBox(r.Context(), addr)
+	accessKeyID := submatches["access_key_id"]
+	box, session, err := c.resolveCredentials(r.Context(), accessKeyID, MultipartFormValue(r, strings.ToLower(SessionTokenHdr)))
 	if err != nil {
-		return nil, fmt.Errorf("get box: %w", err)
+		return nil, err
 	}
 
 	secret := box.Gate.AccessKey
 	service, region := submatches["service"], submatches["region"]
 
+	start := time.Now()
 	signature := signStr(secret, service, region, signatureDateTime, policy)
-	if signature != MultipartFormValue(r, "x-amz-signature") {
+	matched := hmac.Equal([]byte(signature), []byte(MultipartFormValue(r, "x-amz-signature")))
+	metrics.ObserveSignatureVerification("post", time.Since(start))
+	if !matched {
 		return nil, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)
 	}
 
-	return &Box{AccessBox: box}, nil
+	if err = checkPostPolicy(r, policy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &Box{AccessBox: box, AccessKeyID: accessKeyID, Session: session}, nil
 }
 
-func cloneRequest(r *http.Request, authHeader *authHeader) *http.Request {
-	otherRequest := r.Clone(context.TODO())
-	otherRequest.Header = make(http.Header)
+// checkScope rejects credential scopes with services and regions other than
+// the configured ones. SigV4A scopes don't include the region, X-Amz-Region-Set
+// of the signature must match one of the accepted regions then.
+func (c *center) checkScope(authHdr *authHeader, r *http.Request) error {
+	if len(c.scope.Services) > 0 && !containsFold(c.scope.Services, authHdr.Service) {
+		return scopeError("incorrect service '%s'; expecting '%s'", authHdr.Service, strings.Join(c.scope.Services, "', '"))
+	}
+
+	regions := c.scope.acceptedRegions()
+	if len(regions) == 0 || c.scope.RegionMismatch == RegionMismatchIgnore {
+		return nil
+	}
 
-	for key, val := range r.Header {
-		for _, name := range authHeader.SignedFields {
-			if strings.EqualFold(key, name) {
-				otherRequest.Header[key] = val
+	if !authHdr.Asymmetric {
+		for _, region := range regions {
+			if authHdr.Region == region {
+				return nil
 			}
 		}
+		return scopeError("the region '%s' is wrong; expecting '%s'", authHdr.Region, strings.Join(regions, "', '"))
 	}
 
-	if authHeader.IsPresigned {
-		otherQuery := otherRequest.URL.Query()
-		otherQuery.Del(AmzSignature)
-		otherRequest.URL.RawQuery = otherQuery.Encode()
+	regionSet := r.Header.Get(AmzRegionSet)
+	if authHdr.IsPresigned {
+		regionSet = r.URL.Query().Get(AmzRegionSet)
+	}
+	for _, pattern := range strings.Split(regionSet, ",") {
+		pattern = strings.TrimSpace(pattern)
+		prefix := strings.TrimSuffix(pattern, "*")
+		for _, region := range regions {
+			if pattern == region || (prefix != pattern && strings.HasPrefix(region, prefix)) {
+				return nil
+			}
+		}
 	}
 
-	return otherRequest
+	return scopeError("the region set '%s' is wrong; expecting '%s'", regionSet, strings.Join(regions, "', '"))
 }
 
-func (c *center) checkSign(authHeader *authHeader, box *accessbox.Box, request *http.Request, signatureDateTime time.Time) error {
-	awsCreds := credentials.NewStaticCredentials(authHeader.AccessKeyID, box.Gate.AccessKey, "")
-	signer := v4.NewSigner(awsCreds)
+func (s Scope) acceptedRegions() []string {
+	regions := make([]string, 0, 1+len(s.AdditionalRegions))
+	for _, region := range append([]string{s.Region}, s.AdditionalRegions...) {
+		if region != "" {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// scopeError is AuthorizationHeaderMalformed error with the reason the
+// credential scope isn't accepted.
+func scopeError(format string, args ...any) error {
+	err := s3errors.GetAPIError(s3errors.ErrAuthorizationHeaderMalformed)
+	err.Description = "The authorization header is malformed; " + fmt.Sprintf(format, args...) + "."
+	return err
+}
+
+// parseSignatureTime parses the request date in ISO8601 basic format or in
+// formats allowed for HTTP Date header (RFC1123 mostly), as AWS does.
+func parseSignatureTime(value string) (time.Time, error) {
+	if t, err := time.Parse(timeFormatISO8601, value); err == nil {
+		return t, nil
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, s3errors.GetAPIError(s3errors.ErrMalformedDate)
+	}
+
+	return t, nil
+}
+
+// checkClockSkew rejects requests signed too long before or after the
+// gateway time, so captured requests can't be replayed later.
+func (c *center) checkClockSkew(signatureTime time.Time) error {
+	if c.maxClockSkew <= 0 {
+		return nil
+	}
+
+	if skew := time.Since(signatureTime); skew > c.maxClockSkew || skew < -c.maxClockSkew {
+		return s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed)
+	}
+
+	return nil
+}
+
+// checkReplay rejects the request if its verified signature has already been
+// accepted. It's called for requests signed with headers by SigV4, SigV4A
+// and SigV2, signatures are keyed by the access key ID, so hex SigV4 and
+// base64 SigV2 ones can't collide.
+func (c *center) checkReplay(accessKeyID, signature string, signatureTime time.Time) error {
+	if c.replays == nil {
+		return nil
+	}
+
+	return c.replays.check(accessKeyID, signature, signatureTime)
+}
 
-	var signature string
+// checkSign verifies SigV4 and SigV4A signatures of the request, the
+// canonical request is built from the headers signed by the client.
+func (c *center) checkSign(authHeader *authHeader, box *accessbox.Box, sessionToken string, r *http.Request, signatureDateTime time.Time) error {
 	if authHeader.IsPresigned {
 		now := time.Now()
 		if signatureDateTime.Add(authHeader.Expiration).Before(now) {
@@ -322,25 +721,68 @@ func (c *center) checkSign(authHeader *authHeader, box *accessbox.Box, request *
 		if now.Before(signatureDateTime) {
 			return s3errors.GetAPIError(s3errors.ErrBadRequest)
 		}
-		if _, err := signer.Presign(request, nil, authHeader.Service, authHeader.Region, authHeader.Expiration, signatureDateTime); err != nil {
-			return fmt.Errorf("failed to pre-sign temporary HTTP request: %w", err)
-		}
-		signature = request.URL.Query().Get(AmzSignature)
-	} else {
-		signer.DisableURIPathEscaping = true
-		if _, err := signer.Sign(request, nil, authHeader.Service, authHeader.Region, signatureDateTime); err != nil {
-			return fmt.Errorf("failed to sign temporary HTTP request: %w", err)
-		}
-		signature = c.reg.GetSubmatches(request.Header.Get(AuthorizationHdr))["v4_signature"]
+	} else if sessionToken != "" && !containsFold(authHeader.SignedFields, SessionTokenHdr) {
+		// the token must be signed with the request
+		return s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)
+	}
+
+	signed := v4.SignedRequest{
+		Request:       r,
+		SignedHeaders: authHeader.SignedFields,
+		Service:       authHeader.Service,
+		Region:        authHeader.Region,
+		Time:          signatureDateTime,
+		IsPresign:     authHeader.IsPresigned,
+	}
+	if body, ok := r.Body.(signedBodyReader); ok {
+		signed.PayloadHash = body.hash
 	}
 
-	if authHeader.SignatureV4 != signature {
+	var err error
+	if authHeader.Asymmetric {
+		err = signed.VerifyAsymmetric(authHeader.AccessKeyID, box.Gate.AccessKey, authHeader.SignatureV4)
+	} else {
+		err = signed.Verify(box.Gate.AccessKey, authHeader.SignatureV4)
+	}
+	if errors.Is(err, v4.ErrSignatureMismatch) || errors.Is(err, v4.ErrAsymmetricSignatureMismatch) {
 		return s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
 
 	return nil
 }
 
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// signedBodyReader is a request body hashed to check the signature.
+type signedBodyReader struct {
+	*bytes.Reader
+	hash string
+}
+
+func (signedBodyReader) Close() error {
+	return nil
+}
+
+// WithSignedBody sets the payload as the body of the request, so the payload
+// is hashed to check the signature if X-Amz-Content-Sha256 header is missing.
+// S3 requests always have the header, but requests of other services
+// like STS don't.
+func WithSignedBody(r *http.Request, payload []byte) {
+	hash := sha256.Sum256(payload)
+	r.Body = signedBodyReader{Reader: bytes.NewReader(payload), hash: hex.EncodeToString(hash[:])}
+	r.ContentLength = int64(len(payload))
+}
+
 func signStr(secret, service, region string, t time.Time, strToSign string) string {
 	creds := deriveKey(secret, service, region, t)
 	signature := hmacSHA256(creds, []byte(strToSign))
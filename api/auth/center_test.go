@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	awsv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -47,9 +53,17 @@ func TestAuthHeaderParse(t *testing.T) {
 			expected: nil,
 		},
 		{
-			header:   strings.ReplaceAll(defaultHeader, "oid0cid", "oidcid"),
-			err:      s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID),
-			expected: nil,
+			// access key IDs are checked by credentials backends
+			header: strings.ReplaceAll(defaultHeader, "oid0cid", "oidcid"),
+			err:    nil,
+			expected: &authHeader{
+				AccessKeyID:  "oidcid",
+				Service:      "s3",
+				Region:       "us-east-1",
+				SignatureV4:  "2811ccb9e242f41426738fb1f",
+				SignedFields: []string{"host", "x-amz-content-sha256", "x-amz-date"},
+				Date:         "20210809",
+			},
 		},
 	} {
 		authHeader, err := center.parseAuthHeader(tc.header)
@@ -58,7 +72,7 @@ func TestAuthHeaderParse(t *testing.T) {
 	}
 }
 
-func TestAuthHeaderGetAddress(t *testing.T) {
+func TestAccessBoxAddress(t *testing.T) {
 	defaulErr := s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
 
 	for _, tc := range []struct {
@@ -90,7 +104,7 @@ func TestAuthHeaderGetAddress(t *testing.T) {
 			err: defaulErr,
 		},
 	} {
-		_, err := tc.authHeader.getAddress()
+		_, err := accessBoxAddress(tc.authHeader.AccessKeyID)
 		require.Equal(t, tc.err, err, tc.authHeader.AccessKeyID)
 	}
 }
@@ -108,6 +122,322 @@ func TestSignature(t *testing.T) {
 	require.Equal(t, "dfbe886241d9e369cf4b329ca0f15eb27306c97aa1022cc0bb5a914c4ef87634", signature)
 }
 
+func TestParseSignatureTime(t *testing.T) {
+	expected := time.Date(2015, 12, 29, 0, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{"20151229T000000Z", "Tue, 29 Dec 2015 00:00:00 GMT"} {
+		signTime, err := parseSignatureTime(value)
+		require.NoError(t, err, value)
+		require.True(t, expected.Equal(signTime), value)
+	}
+
+	_, err := parseSignatureTime("2015-12-29")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrMalformedDate))
+}
+
+func TestAuthenticationResult(t *testing.T) {
+	for expected, err := range map[string]error{
+		"success":       nil,
+		"bad_signature": s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch),
+		"expired":       fmt.Errorf("wrapped: %w", s3errors.GetAPIError(s3errors.ErrExpiredToken)),
+		"unknown_key":   fmt.Errorf("resolve access key: %w", s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)),
+		"access_denied": s3errors.GetAPIError(s3errors.ErrAccessDenied),
+		"other":         s3errors.GetAPIError(s3errors.ErrMalformedDate),
+	} {
+		require.Equal(t, expected, authenticationResult(err))
+	}
+}
+
+func TestCheckSignDateHeaders(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	signTime := time.Now().UTC().Truncate(time.Second)
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	c := &center{reg: NewRegexpMatcher(authorizationFieldRegexp)}
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "date", header: DateHdr, value: signTime.Format(http.TimeFormat)},
+		{name: "rfc1123 x-amz-date", header: AmzDate, value: signTime.Format(http.TimeFormat)},
+		{name: "iso8601 x-amz-date", header: AmzDate, value: signTime.Format(timeFormatISO8601)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			require.NoError(t, err)
+			req.Header.Set(tc.header, tc.value)
+
+			signer := v4.NewSigner(credentials.NewStaticCredentials("oid0cid", secret, ""))
+			signer.DisableURIPathEscaping = true
+			signer.KeepDateHeaders = true
+			_, err = signer.Sign(req, nil, "s3", "us-east-1", signTime)
+			require.NoError(t, err)
+
+			authHdr, err := c.parseAuthHeader(req.Header.Get(AuthorizationHdr))
+			require.NoError(t, err)
+			require.Contains(t, authHdr.SignedFields, strings.ToLower(tc.header))
+
+			parsed, err := parseSignatureTime(tc.value)
+			require.NoError(t, err)
+			require.NoError(t, c.checkSign(authHdr, box, "", req, parsed))
+
+			authHdr.SignatureV4 = strings.Repeat("0", len(authHdr.SignatureV4))
+			require.ErrorIs(t, c.checkSign(authHdr, box, "", req, parsed),
+				s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+		})
+	}
+}
+
+func TestCheckSignSessionToken(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	signTime := time.Now().UTC().Truncate(time.Second)
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	c := &center{reg: NewRegexpMatcher(authorizationFieldRegexp)}
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+	signer := v4.NewSigner(credentials.NewStaticCredentials("oid0cid", secret, "token"))
+	signer.DisableURIPathEscaping = true
+	_, err := signer.Sign(req, nil, "s3", "us-east-1", signTime)
+	require.NoError(t, err)
+
+	authHdr, err := c.parseAuthHeader(req.Header.Get(AuthorizationHdr))
+	require.NoError(t, err)
+	require.Contains(t, authHdr.SignedFields, strings.ToLower(SessionTokenHdr))
+	require.NoError(t, c.checkSign(authHdr, box, "token", req, signTime))
+
+	req.Header.Set(SessionTokenHdr, "another")
+	require.ErrorIs(t, c.checkSign(authHdr, box, "another", req, signTime),
+		s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+
+	// the token isn't signed
+	var unsigned []string
+	for _, name := range authHdr.SignedFields {
+		if name != strings.ToLower(SessionTokenHdr) {
+			unsigned = append(unsigned, name)
+		}
+	}
+	authHdr.SignedFields = unsigned
+	require.ErrorIs(t, c.checkSign(authHdr, box, "another", req, signTime),
+		s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+}
+
+func TestCheckSignPresigned(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	signTime := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	c := &center{}
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodHead} {
+		t.Run(method, func(t *testing.T) {
+			// the URL is presigned as AWS SDK does it for S3
+			req, err := http.NewRequest(method, "http://localhost:8084/bucket/dir/object%20name?versionId=1", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Amz-Meta-Key", "value")
+
+			signer := awsv4.NewSigner(credentials.NewStaticCredentials("oid0cid", secret, ""))
+			signer.DisableURIPathEscaping = true
+			_, err = signer.Presign(req, nil, "s3", "us-east-1", time.Hour, signTime)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(method, req.URL.String(), nil)
+			r.Header.Set("X-Amz-Meta-Key", "value")
+
+			authHdr, err := parsePresignedQuery(r.URL.Query())
+			require.NoError(t, err)
+			require.Equal(t, []string{"host", "x-amz-meta-key"}, authHdr.SignedFields)
+			require.Equal(t, time.Hour, authHdr.Expiration)
+
+			require.NoError(t, c.checkSign(authHdr, box, "", r, signTime))
+
+			r.Header.Set("X-Amz-Meta-Key", "another")
+			require.ErrorIs(t, c.checkSign(authHdr, box, "", r, signTime),
+				s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+
+			authHdr.Expiration = time.Second
+			require.ErrorIs(t, c.checkSign(authHdr, box, "", r, signTime),
+				s3errors.GetAPIError(s3errors.ErrExpiredPresignRequest))
+		})
+	}
+}
+
+func TestAuthenticateAsymmetric(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	c := New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, false, Scope{}, nil)
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials("key", secret, ""))
+	signer.DisableURIPathEscaping = true
+	signer.Asymmetric = true
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "http://localhost:8084/bucket/dir/object%20name", nil)
+		r.Header.Set("X-Amz-Region-Set", "us-east-1,us-west-2")
+		r.Header.Set(AmzContentSHA256, "UNSIGNED-PAYLOAD")
+		return r
+	}
+
+	r := newRequest()
+	_, err := signer.Sign(r, nil, "s3", "", time.Now())
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(r.Header.Get(AuthorizationHdr), "AWS4-ECDSA-P256-SHA256 Credential=key/"))
+
+	authHdr, err := c.(*center).parseAuthHeader(r.Header.Get(AuthorizationHdr))
+	require.NoError(t, err)
+	require.True(t, authHdr.Asymmetric)
+	require.Equal(t, "s3", authHdr.Service)
+	require.Contains(t, authHdr.SignedFields, "x-amz-region-set")
+
+	res, err := c.Authenticate(r)
+	require.NoError(t, err)
+	require.Equal(t, "key", res.AccessKeyID)
+
+	r.Header.Set("X-Amz-Region-Set", "*")
+	_, err = c.Authenticate(r)
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+
+	// chunk signatures of SigV4A aren't supported
+	r = newRequest()
+	r.Header.Set(AmzContentSHA256, "STREAMING-AWS4-ECDSA-P256-SHA256-PAYLOAD")
+	r.Header.Set(ContentEncodingHdr, ContentEncodingAwsChunked)
+	_, err = signer.Sign(r, nil, "s3", "", time.Now())
+	require.NoError(t, err)
+	_, err = c.Authenticate(r)
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+
+	// presigned URLs are created without the payload hash header
+	r = newRequest()
+	r.Header.Del(AmzContentSHA256)
+	_, err = signer.Presign(r, nil, "s3", "", time.Hour, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	r = httptest.NewRequest(http.MethodPut, r.URL.String(), nil)
+	_, err = c.Authenticate(r)
+	require.NoError(t, err)
+
+	query := r.URL.Query()
+	query.Set("X-Amz-Region-Set", "*")
+	r = httptest.NewRequest(http.MethodPut, "http://localhost:8084/bucket/dir/object%20name?"+query.Encode(), nil)
+	_, err = c.Authenticate(r)
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+}
+
+func TestAuthenticateScope(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	c := New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, false, Scope{Region: "eu-west-1", Services: []string{"s3"}}, nil)
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials("key", secret, ""))
+	signer.DisableURIPathEscaping = true
+
+	authenticate := func(service, region, regionSet string) error {
+		r := httptest.NewRequest(http.MethodPut, "http://localhost:8084/bucket/object", nil)
+		r.Header.Set(AmzContentSHA256, "UNSIGNED-PAYLOAD")
+		if regionSet != "" {
+			r.Header.Set(AmzRegionSet, regionSet)
+		}
+		_, err := signer.Sign(r, nil, service, region, time.Now())
+		require.NoError(t, err)
+		_, err = c.Authenticate(r)
+		return err
+	}
+	requireMalformed := func(err error) {
+		var s3Err s3errors.Error
+		require.ErrorAs(t, err, &s3Err)
+		require.Equal(t, s3errors.ErrAuthorizationHeaderMalformed, s3Err.ErrCode)
+	}
+
+	require.NoError(t, authenticate("s3", "eu-west-1", ""))
+	requireMalformed(authenticate("s3", "us-east-1", ""))
+	requireMalformed(authenticate("sts", "eu-west-1", ""))
+
+	signer.Asymmetric = true
+	require.NoError(t, authenticate("s3", "", "us-east-1,eu-west-1"))
+	require.NoError(t, authenticate("s3", "", "eu-*"))
+	require.NoError(t, authenticate("s3", "", "*"))
+	requireMalformed(authenticate("s3", "", "us-east-1"))
+	requireMalformed(authenticate("s3", "", "us-*"))
+	requireMalformed(authenticate("s3", "", ""))
+
+	c = New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, false, Scope{Region: "eu-west-1", AdditionalRegions: []string{"us-east-1"}}, nil)
+	signer.Asymmetric = false
+	require.NoError(t, authenticate("s3", "eu-west-1", ""))
+	require.NoError(t, authenticate("s3", "us-east-1", ""))
+	requireMalformed(authenticate("s3", "eu-north-1", ""))
+	signer.Asymmetric = true
+	require.NoError(t, authenticate("s3", "", "us-*"))
+	requireMalformed(authenticate("s3", "", "eu-north-1"))
+
+	c = New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, false, Scope{Region: "eu-west-1", Services: []string{"s3"}, RegionMismatch: RegionMismatchIgnore}, nil)
+	require.NoError(t, authenticate("s3", "", "eu-north-1"))
+	signer.Asymmetric = false
+	require.NoError(t, authenticate("s3", "eu-north-1", ""))
+	requireMalformed(authenticate("sts", "eu-west-1", ""))
+}
+
+func TestParseRegionMismatch(t *testing.T) {
+	for s, expected := range map[string]RegionMismatch{"": RegionMismatchReject, "reject": RegionMismatchReject, "ignore": RegionMismatchIgnore} {
+		policy, err := ParseRegionMismatch(s)
+		require.NoError(t, err)
+		require.Equal(t, expected, policy)
+	}
+
+	_, err := ParseRegionMismatch("warn")
+	require.Error(t, err)
+}
+
+func TestParsePresignedQuery(t *testing.T) {
+	valid := url.Values{
+		AmzAlgorithm:     []string{"AWS4-HMAC-SHA256"},
+		AmzCredential:    []string{"oid0cid/20231017/us-east-1/s3/aws4_request"},
+		AmzSignature:     []string{"signature"},
+		AmzSignedHeaders: []string{"host"},
+		AmzDate:          []string{"20231017T100000Z"},
+		AmzExpires:       []string{"60"},
+	}
+
+	authHdr, err := parsePresignedQuery(valid)
+	require.NoError(t, err)
+	require.Equal(t, "oid0cid", authHdr.AccessKeyID)
+	require.Equal(t, "us-east-1", authHdr.Region)
+	require.Equal(t, time.Minute, authHdr.Expiration)
+	require.True(t, authHdr.IsPresigned)
+
+	asymmetric := make(url.Values, len(valid))
+	for k, v := range valid {
+		asymmetric[k] = v
+	}
+	asymmetric.Set(AmzAlgorithm, "AWS4-ECDSA-P256-SHA256")
+	asymmetric.Set(AmzCredential, "oid0cid/20231017/s3/aws4_request")
+	authHdr, err = parsePresignedQuery(asymmetric)
+	require.NoError(t, err)
+	require.True(t, authHdr.Asymmetric)
+	require.Equal(t, "s3", authHdr.Service)
+	require.Empty(t, authHdr.Region)
+
+	for _, tc := range []struct {
+		param, value string
+		err          s3errors.ErrorCode
+	}{
+		{param: AmzAlgorithm, value: "AWS4-HMAC-SHA1", err: s3errors.ErrSignatureVersionNotSupported},
+		{param: AmzSignature, err: s3errors.ErrInvalidQueryParams},
+		{param: AmzExpires, err: s3errors.ErrInvalidQueryParams},
+		{param: AmzCredential, value: "oid0cid/20231017/us-east-1/s3", err: s3errors.ErrCredMalformed},
+		{param: AmzCredential, value: "oid0cid/20231017/s3/aws4_request", err: s3errors.ErrCredMalformed},
+		{param: AmzExpires, value: "1m", err: s3errors.ErrMalformedExpires},
+		{param: AmzExpires, value: "-1", err: s3errors.ErrNegativeExpires},
+		{param: AmzExpires, value: "604801", err: s3errors.ErrMaximumExpires},
+	} {
+		query := make(url.Values, len(valid))
+		for k, v := range valid {
+			query[k] = v
+		}
+		query.Set(tc.param, tc.value)
+
+		_, err = parsePresignedQuery(query)
+		require.ErrorIs(t, err, s3errors.GetAPIError(tc.err), tc.param+"="+tc.value)
+	}
+}
+
 // TestAwsEncodedChunkReader checks example from https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
 func TestAwsEncodedChunkReader(t *testing.T) {
 	chunkOnePayload := make([]byte, 65536)
@@ -348,6 +678,79 @@ func TestAwsEncodedChunkReader(t *testing.T) {
 	})
 }
 
+func TestAuthenticateStreamingPayload(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	addr := oidtest.Address()
+	accessKeyID := strings.ReplaceAll(addr.EncodeToString(), "/", "0")
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	awsCreds := credentials.NewStaticCredentials(accessKeyID, secret, "")
+
+	c := &center{
+		creds: NewAccessBoxBackend(&credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: box}}),
+		reg:   NewRegexpMatcher(authorizationFieldRegexp),
+	}
+
+	payload := []byte("streaming payload")
+
+	// newRequest signs the request as AWS SDK does it for aws-chunked uploads
+	// without Content-Encoding header.
+	newRequest := func(decodedLength int, tamper bool) *http.Request {
+		ts := time.Now().UTC().Truncate(time.Second)
+		req, err := http.NewRequest(http.MethodPut, "http://localhost:8084/bucket/object", nil)
+		require.NoError(t, err)
+		req.Header.Set(AmzContentSHA256, StreamingContentSHA256)
+		req.Header.Set(AmzDecodedContentLength, strconv.Itoa(decodedLength))
+
+		signer := awsv4.NewSigner(awsCreds)
+		signer.DisableURIPathEscaping = true
+		_, err = signer.Sign(req, nil, "s3", "us-east-1", ts)
+		require.NoError(t, err)
+
+		seedSignature, err := hex.DecodeString(c.reg.GetSubmatches(req.Header.Get(AuthorizationHdr))["v4_signature"])
+		require.NoError(t, err)
+		chunkSigner := v4.NewChunkSigner("us-east-1", "s3", seedSignature, ts, awsCreds)
+
+		var body bytes.Buffer
+		for _, chunk := range [][]byte{payload[:9], payload[9:], nil} {
+			signature, err := chunkSigner.GetSignature(chunk)
+			require.NoError(t, err)
+			if tamper && len(chunk) != 0 {
+				chunk = bytes.ToUpper(chunk)
+			}
+			body.WriteString(strconv.FormatInt(int64(len(chunk)), 16) + ";chunk-signature=" + hex.EncodeToString(signature) + "\r\n")
+			body.Write(chunk)
+			body.WriteString("\r\n")
+		}
+
+		r := httptest.NewRequest(http.MethodPut, req.URL.String(), &body)
+		for key := range req.Header {
+			r.Header.Set(key, req.Header.Get(key))
+		}
+		return r
+	}
+
+	r := newRequest(len(payload), false)
+	_, err := c.Authenticate(r)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+
+	r = newRequest(len(payload), true)
+	_, err = c.Authenticate(r)
+	require.NoError(t, err)
+	_, err = io.ReadAll(r.Body)
+	require.ErrorIs(t, err, v4.ErrInvalidChunkSignature)
+
+	for _, decodedLength := range []int{len(payload) - 1, len(payload) + 1} {
+		r = newRequest(decodedLength, false)
+		_, err = c.Authenticate(r)
+		require.NoError(t, err)
+		_, err = io.ReadAll(r.Body)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrIncompleteBody))
+	}
+}
+
 func TestAwsEncodedWithRequest(t *testing.T) {
 	t.Skipf("Only for manual launch")
 
@@ -444,3 +847,104 @@ func chunkSlice(payload []byte, chunkSize int) [][]byte {
 
 	return result
 }
+
+func TestAuthenticateClockSkew(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	awsCreds := credentials.NewStaticCredentials("key", secret, "")
+	c := New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, false, Scope{}, nil)
+
+	authenticate := func(signTime time.Time) error {
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		signer := awsv4.NewSigner(awsCreds)
+		signer.DisableURIPathEscaping = true
+		_, err := signer.Sign(r, nil, "s3", "us-east-1", signTime)
+		require.NoError(t, err)
+
+		_, err = c.Authenticate(r)
+		return err
+	}
+
+	require.NoError(t, authenticate(time.Now().Add(-10*time.Minute)))
+	require.NoError(t, authenticate(time.Now().Add(10*time.Minute)))
+	require.ErrorIs(t, authenticate(time.Now().Add(-20*time.Minute)), s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed))
+	require.ErrorIs(t, authenticate(time.Now().Add(20*time.Minute)), s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed))
+
+	// presigned URLs are limited by their expiration only
+	r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+	signer := awsv4.NewSigner(awsCreds)
+	signer.DisableURIPathEscaping = true
+	_, err := signer.Presign(r, nil, "s3", "us-east-1", 2*time.Hour, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	r = httptest.NewRequest(http.MethodGet, r.URL.String(), nil)
+	_, err = c.Authenticate(r)
+	require.NoError(t, err)
+
+	// zero skew disables the check
+	c = New(staticBackend{"key": box}, nil, nil, 0, true, false, Scope{}, nil)
+	require.NoError(t, authenticate(time.Now().Add(-24*time.Hour)))
+}
+
+func TestAuthenticateReplay(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	awsCreds := credentials.NewStaticCredentials("key", secret, "")
+	c := New(staticBackend{"key": box}, nil, nil, 15*time.Minute, true, true, Scope{}, NewReplayCache(2, 5*time.Minute))
+
+	sign := func(signTime time.Time) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		signer := awsv4.NewSigner(awsCreds)
+		signer.DisableURIPathEscaping = true
+		_, err := signer.Sign(r, nil, "s3", "us-east-1", signTime)
+		require.NoError(t, err)
+		return r
+	}
+	authenticate := func(r *http.Request) error {
+		_, err := c.Authenticate(r.Clone(r.Context()))
+		return err
+	}
+
+	now := time.Now()
+	first := sign(now)
+	require.NoError(t, authenticate(first))
+	require.ErrorIs(t, authenticate(first), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	// signatures are remembered once they're verified
+	forged := sign(now.Add(time.Second))
+	forged.Header.Set(AuthorizationHdr, strings.Replace(forged.Header.Get(AuthorizationHdr), "Credential=key", "Credential=unknown", 1))
+	require.Error(t, authenticate(forged))
+	second := sign(now.Add(time.Second))
+	require.NoError(t, authenticate(second))
+	require.ErrorIs(t, authenticate(second), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	// requests outside the window can't be remembered
+	require.ErrorIs(t, authenticate(sign(now.Add(-10*time.Minute))), s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed))
+
+	// the least recently used signatures are evicted
+	require.NoError(t, authenticate(sign(now.Add(2*time.Second))))
+	require.NoError(t, authenticate(first))
+
+	// presigned URLs can be reused till they expire
+	r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+	signer := awsv4.NewSigner(awsCreds)
+	signer.DisableURIPathEscaping = true
+	_, err := signer.Presign(r, nil, "s3", "us-east-1", time.Hour, now)
+	require.NoError(t, err)
+	require.NoError(t, authenticate(httptest.NewRequest(http.MethodGet, r.URL.String(), nil)))
+	require.NoError(t, authenticate(httptest.NewRequest(http.MethodGet, r.URL.String(), nil)))
+
+	// SigV2
+	date := now.UTC().Format(http.TimeFormat)
+	r = httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+	r.Header.Set(DateHdr, date)
+	r.Header.Set(AuthorizationHdr, "AWS key:"+signV2(secret, stringToSignV2(r, date)))
+	require.NoError(t, authenticate(r))
+	require.ErrorIs(t, authenticate(r), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	// SigV2 with X-Amz-Date, the signed Date is empty then
+	r = httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object2", nil)
+	r.Header.Set(AmzDate, now.UTC().Format(http.TimeFormat))
+	r.Header.Set(AuthorizationHdr, "AWS key:"+signV2(secret, stringToSignV2(r, "")))
+	require.NoError(t, authenticate(r))
+	require.ErrorIs(t, authenticate(r), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+}
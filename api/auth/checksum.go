@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"strings"
+
+	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// crc64NVMEPolynomial is the reversed polynomial of CRC-64/NVME.
+const crc64NVMEPolynomial = 0x9a6c9329ac4bc9b5
+
+var (
+	crc32cTable    = crc32.MakeTable(crc32.Castagnoli)
+	crc64NVMETable = crc64.MakeTable(crc64NVMEPolynomial)
+
+	// trailerChecksums are hashes of trailing checksums by their header
+	// names, values are base64 encoded big-endian sums.
+	trailerChecksums = map[string]func() hash.Hash{
+		"x-amz-checksum-crc32":     func() hash.Hash { return crc32.NewIEEE() },
+		"x-amz-checksum-crc32c":    func() hash.Hash { return crc32.New(crc32cTable) },
+		"x-amz-checksum-crc64nvme": func() hash.Hash { return crc64.New(crc64NVMETable) },
+		"x-amz-checksum-sha1":      sha1.New,
+		"x-amz-checksum-sha256":    sha256.New,
+	}
+)
+
+// trailerChecksumReader fails at the end of the payload if the checksum of
+// the read payload differs from the trailing one.
+type trailerChecksumReader struct {
+	v4.TrailerReader
+	name string
+	hash hash.Hash
+}
+
+// newTrailerChecksumReader verifies the checksum declared in X-Amz-Trailer
+// header, the payload isn't verified if no trailers are declared.
+func newTrailerChecksumReader(r v4.TrailerReader, declared string) (io.ReadCloser, error) {
+	var names []string
+	for _, name := range strings.Split(declared, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return r, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: only one trailing checksum is allowed", s3errors.GetAPIError(s3errors.ErrMalformedTrailer))
+	}
+
+	newHash, ok := trailerChecksums[names[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported trailer '%s'", s3errors.GetAPIError(s3errors.ErrMalformedTrailer), names[0])
+	}
+
+	return &trailerChecksumReader{TrailerReader: r, name: names[0], hash: newHash()}, nil
+}
+
+func (r *trailerChecksumReader) Read(p []byte) (int, error) {
+	n, err := r.TrailerReader.Read(p)
+	r.hash.Write(p[:n])
+	if err != io.EOF {
+		return n, err
+	}
+
+	expected, ok := r.Trailer()[r.name]
+	if !ok {
+		return n, fmt.Errorf("%w: no '%s' trailer", s3errors.GetAPIError(s3errors.ErrMalformedTrailer), r.name)
+	}
+	if expected != base64.StdEncoding.EncodeToString(r.hash.Sum(nil)) {
+		return n, s3errors.GetAPIError(s3errors.ErrChecksumMismatch)
+	}
+
+	return n, err
+}
+
+// payloadHashReader fails at the end of the payload if its SHA-256 differs
+// from X-Amz-Content-Sha256 header. The payload ends after Content-Length
+// bytes if it's known, so it's verified even if readers stop at the size.
+type payloadHashReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+	left     int64
+	verified bool
+}
+
+// verifyPayloadHash makes the body of the request verified against the
+// declared hex SHA-256, empty payloads are verified at once.
+func verifyPayloadHash(r *http.Request, declared string) error {
+	expected, err := hex.DecodeString(declared)
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("%w: invalid %s header", s3errors.GetAPIError(s3errors.ErrInvalidArgument), AmzContentSHA256)
+	}
+
+	res := &payloadHashReader{ReadCloser: r.Body, hash: sha256.New(), expected: expected, left: r.ContentLength}
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return res.verify()
+	}
+	r.Body = res
+
+	return nil
+}
+
+func (r *payloadHashReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if r.verified {
+		return n, err
+	}
+
+	if r.left > 0 {
+		r.left -= int64(n)
+	}
+	if err == io.EOF || (r.left == 0 && n > 0) {
+		if verr := r.verify(); verr != nil {
+			return n, verr
+		}
+	}
+
+	return n, err
+}
+
+func (r *payloadHashReader) verify() error {
+	r.verified = true
+	if !bytes.Equal(r.hash.Sum(nil), r.expected) {
+		return s3errors.GetAPIError(s3errors.ErrContentSHA256Mismatch)
+	}
+	return nil
+}
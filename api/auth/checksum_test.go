@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	awsv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticateTrailerChecksum(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	addr := oidtest.Address()
+	accessKeyID := strings.ReplaceAll(addr.EncodeToString(), "/", "0")
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	awsCreds := credentials.NewStaticCredentials(accessKeyID, secret, "")
+
+	c := &center{
+		creds: NewAccessBoxBackend(&credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: box}}),
+		reg:   NewRegexpMatcher(authorizationFieldRegexp),
+	}
+
+	payload := []byte("payload with trailing checksum")
+	crc := crc32.NewIEEE()
+	crc.Write(payload)
+	checksum := base64.StdEncoding.EncodeToString(crc.Sum(nil))
+
+	// newRequest frames the payload as AWS SDK v2 does it for uploads with
+	// trailing checksums.
+	newRequest := func(contentSHA256, trailerName, trailerValue string) *http.Request {
+		ts := time.Now().UTC().Truncate(time.Second)
+		req, err := http.NewRequest(http.MethodPut, "http://localhost:8084/bucket/object", nil)
+		require.NoError(t, err)
+		req.Header.Set(AmzContentSHA256, contentSHA256)
+		req.Header.Set(ContentEncodingHdr, ContentEncodingAwsChunked)
+		req.Header.Set(AmzDecodedContentLength, strconv.Itoa(len(payload)))
+		req.Header.Set(AmzTrailer, trailerName)
+
+		signer := awsv4.NewSigner(awsCreds)
+		signer.DisableURIPathEscaping = true
+		_, err = signer.Sign(req, nil, "s3", "us-east-1", ts)
+		require.NoError(t, err)
+
+		var chunkSigner *v4.ChunkSigner
+		if contentSHA256 == StreamingContentSHA256Trailer {
+			seedSignature, err := hex.DecodeString(c.reg.GetSubmatches(req.Header.Get(AuthorizationHdr))["v4_signature"])
+			require.NoError(t, err)
+			chunkSigner = v4.NewChunkSigner("us-east-1", "s3", seedSignature, ts, awsCreds)
+		}
+
+		var body bytes.Buffer
+		for _, chunk := range [][]byte{payload[:9], payload[9:], nil} {
+			body.WriteString(strconv.FormatInt(int64(len(chunk)), 16))
+			if chunkSigner != nil {
+				signature, err := chunkSigner.GetSignature(chunk)
+				require.NoError(t, err)
+				body.WriteString(";chunk-signature=" + hex.EncodeToString(signature))
+			}
+			body.WriteString("\r\n")
+			if len(chunk) != 0 {
+				body.Write(chunk)
+				body.WriteString("\r\n")
+			}
+		}
+
+		trailer := trailerName + ":" + trailerValue
+		body.WriteString(trailer + "\r\n")
+		if chunkSigner != nil {
+			signature, err := chunkSigner.GetTrailerSignature([]byte(trailer + "\n"))
+			require.NoError(t, err)
+			body.WriteString("x-amz-trailer-signature:" + hex.EncodeToString(signature) + "\r\n")
+		}
+		body.WriteString("\r\n")
+
+		r := httptest.NewRequest(http.MethodPut, req.URL.String(), &body)
+		for key := range req.Header {
+			r.Header.Set(key, req.Header.Get(key))
+		}
+		return r
+	}
+
+	for _, contentSHA256 := range []string{StreamingUnsignedPayloadTrailer, StreamingContentSHA256Trailer} {
+		t.Run(contentSHA256, func(t *testing.T) {
+			r := newRequest(contentSHA256, "x-amz-checksum-crc32", checksum)
+			_, err := c.Authenticate(r)
+			require.NoError(t, err)
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, payload, data)
+
+			r = newRequest(contentSHA256, "x-amz-checksum-crc32", "AAAAAA==")
+			_, err = c.Authenticate(r)
+			require.NoError(t, err)
+			_, err = io.ReadAll(r.Body)
+			require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrChecksumMismatch))
+		})
+	}
+
+	r := newRequest(StreamingUnsignedPayloadTrailer, "x-amz-checksum-md5", checksum)
+	_, err := c.Authenticate(r)
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrMalformedTrailer))
+
+	// unsigned chunks are rejected like UNSIGNED-PAYLOAD
+	c.denyUnsignedPayload = true
+	_, err = c.Authenticate(newRequest(StreamingUnsignedPayloadTrailer, "x-amz-checksum-crc32", checksum))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	r = newRequest(StreamingContentSHA256Trailer, "x-amz-checksum-crc32", checksum)
+	_, err = c.Authenticate(r)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+}
+
+func TestTrailerChecksums(t *testing.T) {
+	// check values of "123456789"
+	for name, expected := range map[string]string{
+		"x-amz-checksum-crc32":     "cbf43926",
+		"x-amz-checksum-crc32c":    "e3069283",
+		"x-amz-checksum-crc64nvme": "ae8b14860a799888",
+		"x-amz-checksum-sha1":      "f7c3bc1d808e04732adf679965ccc34ca7ae3441",
+	} {
+		h := trailerChecksums[name]()
+		h.Write([]byte("123456789"))
+		require.Equal(t, expected, hex.EncodeToString(h.Sum(nil)), name)
+	}
+}
+
+func TestAuthenticatePayloadHash(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+	awsCreds := credentials.NewStaticCredentials("key", secret, "")
+
+	payload := []byte("payload with declared hash")
+	hash := sha256.Sum256(payload)
+
+	newRequest := func(contentSHA256 string, body []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://localhost:8084/bucket/object", bytes.NewReader(body))
+		req.Header.Set(AmzContentSHA256, contentSHA256)
+
+		signer := awsv4.NewSigner(awsCreds)
+		signer.DisableURIPathEscaping = true
+		_, err := signer.Sign(req, nil, "s3", "us-east-1", time.Now())
+		require.NoError(t, err)
+		// the signer replaces the body with the signed one
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return req
+	}
+
+	for _, allowUnsigned := range []bool{true, false} {
+		c := New(staticBackend{"key": box}, nil, nil, 15*time.Minute, allowUnsigned, false, Scope{}, nil)
+
+		req := newRequest(hex.EncodeToString(hash[:]), payload)
+		_, err := c.Authenticate(req)
+		require.NoError(t, err)
+		data, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, data)
+
+		// the payload is verified once Content-Length bytes are read
+		req = newRequest(hex.EncodeToString(hash[:]), append([]byte("tampered"), payload[8:]...))
+		_, err = c.Authenticate(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(io.LimitReader(req.Body, req.ContentLength))
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrContentSHA256Mismatch))
+
+		req = newRequest(hex.EncodeToString(hash[:]), nil)
+		_, err = c.Authenticate(req)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrContentSHA256Mismatch))
+
+		req = newRequest("hash", payload)
+		_, err = c.Authenticate(req)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+		req = newRequest(UnsignedPayload, payload)
+		_, err = c.Authenticate(req)
+		if allowUnsigned {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+		}
+	}
+}
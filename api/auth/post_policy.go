@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+const (
+	postPolicyAlgorithm = "AWS4-HMAC-SHA256"
+	postPolicyService   = "s3"
+
+	// DefaultPostPolicyRegion is a region of credentials scope used if no
+	// region is specified.
+	DefaultPostPolicyRegion = "us-east-1"
+)
+
+type (
+	// PostPolicyParams contains restrictions of the browser-based upload with
+	// POST policy.
+	PostPolicyParams struct {
+		AccessKeyID string
+		SecretKey   string
+		Region      string
+		Bucket      string
+		// KeyPrefix is a prefix of uploaded object names, the name is the
+		// prefix followed by the name of the uploaded file.
+		KeyPrefix string
+		// ContentTypePrefix restricts Content-Type form field if not empty.
+		ContentTypePrefix string
+		MinSize           int64
+		MaxSize           int64
+		Expiration        time.Time
+	}
+
+	// PostPolicyForm contains fields of the HTML form to upload objects with
+	// POST policy, the file is to be sent in the last "file" field.
+	PostPolicyForm struct {
+		Expiration time.Time         `json:"expiration"`
+		Fields     map[string]string `json:"fields"`
+	}
+)
+
+// NewPostPolicyForm builds POST policy with the restrictions and signs it with
+// AWS Signature Version 4 at the given time.
+func NewPostPolicyForm(prm PostPolicyParams, now time.Time) (*PostPolicyForm, error) {
+	switch {
+	case prm.AccessKeyID == "" || prm.SecretKey == "":
+		return nil, errors.New("no credentials")
+	case prm.Bucket == "":
+		return nil, errors.New("no bucket")
+	case prm.MinSize < 0 || prm.MaxSize < prm.MinSize:
+		return nil, errors.New("invalid size limits")
+	case !prm.Expiration.After(now):
+		return nil, errors.New("expiration must be in the future")
+	}
+
+	region := prm.Region
+	if region == "" {
+		region = DefaultPostPolicyRegion
+	}
+
+	now = now.UTC()
+	credential := prm.AccessKeyID + "/" + now.Format("20060102") + "/" + region + "/" + postPolicyService + "/aws4_request"
+	date := now.Format("20060102T150405Z")
+
+	conditions := []any{
+		map[string]string{"bucket": prm.Bucket},
+		[]any{"starts-with", "$key", prm.KeyPrefix},
+		[]any{"content-length-range", prm.MinSize, prm.MaxSize},
+		map[string]string{"x-amz-algorithm": postPolicyAlgorithm},
+		map[string]string{"x-amz-credential": credential},
+		map[string]string{"x-amz-date": date},
+	}
+	if prm.ContentTypePrefix != "" {
+		conditions = append(conditions, []any{"starts-with", "$Content-Type", prm.ContentTypePrefix})
+	}
+
+	policy, err := json.Marshal(struct {
+		Expiration string `json:"expiration"`
+		Conditions []any  `json:"conditions"`
+	}{
+		Expiration: prm.Expiration.UTC().Format(time.RFC3339),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encodedPolicy := base64.StdEncoding.EncodeToString(policy)
+
+	return &PostPolicyForm{
+		Expiration: prm.Expiration.UTC(),
+		Fields: map[string]string{
+			"key":              prm.KeyPrefix + "${filename}",
+			"policy":           encodedPolicy,
+			"x-amz-algorithm":  postPolicyAlgorithm,
+			"x-amz-credential": credential,
+			"x-amz-date":       date,
+			"x-amz-signature":  signStr(prm.SecretKey, postPolicyService, region, now, encodedPolicy),
+		},
+	}, nil
+}
+
+// postPolicyCondition is a parsed condition of POST policy, range limits are
+// set for content-length-range conditions only.
+type postPolicyCondition struct {
+	raw      json.RawMessage
+	operator string
+	field    string
+	value    string
+	min, max int64
+}
+
+// PostPolicy is a parsed POST policy document. It's checked by the
+// authentication center against the form and by the handler against the
+// bucket and the fields it takes the object from.
+type PostPolicy struct {
+	Expiration time.Time
+	conditions []*postPolicyCondition
+}
+
+// ParsePostPolicy decodes base64 encoded POST policy document, it returns
+// MalformedPolicy error if the document is invalid.
+func ParsePostPolicy(encodedPolicy string) (*PostPolicy, error) {
+	rawPolicy, err := base64.StdEncoding.DecodeString(encodedPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 policy: %s", s3errors.GetAPIError(s3errors.ErrMalformedPolicy), err)
+	}
+
+	var policy struct {
+		Expiration *time.Time        `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	if err = json.Unmarshal(rawPolicy, &policy); err != nil {
+		return nil, fmt.Errorf("%w: invalid policy document: %s", s3errors.GetAPIError(s3errors.ErrMalformedPolicy), err)
+	}
+
+	if policy.Expiration == nil {
+		return nil, fmt.Errorf("%w: policy has no expiration", s3errors.GetAPIError(s3errors.ErrMalformedPolicy))
+	}
+
+	res := &PostPolicy{Expiration: *policy.Expiration}
+	for _, raw := range policy.Conditions {
+		cond, err := parsePostPolicyCondition(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid condition %s: %s", s3errors.GetAPIError(s3errors.ErrMalformedPolicy), raw, err)
+		}
+		res.conditions = append(res.conditions, cond)
+	}
+
+	return res, nil
+}
+
+// CheckForm checks the expiration of the policy, conditions on form fields
+// and the size of the uploaded file. The bucket condition is checked by
+// CheckBucket, since the bucket isn't resolved yet.
+func (p *PostPolicy) CheckForm(r *http.Request, now time.Time) error {
+	if !p.Expiration.After(now) {
+		return fmt.Errorf("%w: policy expired", s3errors.GetAPIError(s3errors.ErrAccessDenied))
+	}
+
+	for _, cond := range p.conditions {
+		if cond.field == "bucket" {
+			continue
+		}
+		if !cond.matchForm(r) {
+			return conditionError(cond)
+		}
+	}
+
+	return nil
+}
+
+// CheckBucket checks the bucket conditions of the policy.
+func (p *PostPolicy) CheckBucket(bucket string) error {
+	for _, cond := range p.conditions {
+		if cond.field == "bucket" && !cond.match(bucket) {
+			return conditionError(cond)
+		}
+	}
+
+	return nil
+}
+
+// CheckField checks that the form field is restricted by the policy and
+// matches all its conditions, the key is the lowercase field name.
+func (p *PostPolicy) CheckField(key, value string) error {
+	var found bool
+	for _, cond := range p.conditions {
+		if cond.field != key {
+			continue
+		}
+		if !cond.match(value) {
+			return conditionError(cond)
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("%w: policy has no condition for '%s' field", s3errors.GetAPIError(s3errors.ErrAccessDenied), key)
+	}
+
+	return nil
+}
+
+// CheckContentLength checks the size of the uploaded file against the
+// content-length-range conditions.
+func (p *PostPolicy) CheckContentLength(size int64) bool {
+	for _, cond := range p.conditions {
+		if cond.operator == "content-length-range" && (size < cond.min || size > cond.max) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func conditionError(cond *postPolicyCondition) error {
+	return fmt.Errorf("%w: policy condition failed: %s", s3errors.GetAPIError(s3errors.ErrAccessDenied), cond.raw)
+}
+
+// checkPostPolicy validates POST policy document of the form.
+func checkPostPolicy(r *http.Request, encodedPolicy string, now time.Time) error {
+	policy, err := ParsePostPolicy(encodedPolicy)
+	if err != nil {
+		return err
+	}
+
+	return policy.CheckForm(r, now)
+}
+
+func parsePostPolicyCondition(raw json.RawMessage) (*postPolicyCondition, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) != 1 {
+			return nil, errors.New("exactly one field expected")
+		}
+		for field, value := range v {
+			str, ok := value.(string)
+			if !ok {
+				return nil, errors.New("string value expected")
+			}
+			return &postPolicyCondition{raw: raw, operator: "eq", field: strings.ToLower(field), value: str}, nil
+		}
+	case []any:
+		if len(v) != 3 {
+			return nil, errors.New("three elements expected")
+		}
+		operator, ok := v[0].(string)
+		if !ok {
+			return nil, errors.New("string operator expected")
+		}
+		cond := &postPolicyCondition{raw: raw, operator: strings.ToLower(operator)}
+
+		switch cond.operator {
+		case "content-length-range":
+			min, ok := v[1].(float64)
+			max, ok2 := v[2].(float64)
+			if !ok || !ok2 || min < 0 || max < min {
+				return nil, errors.New("invalid range")
+			}
+			cond.min, cond.max = int64(min), int64(max)
+		case "eq", "starts-with":
+			field, ok := v[1].(string)
+			value, ok2 := v[2].(string)
+			if !ok || !ok2 || !strings.HasPrefix(field, "$") {
+				return nil, errors.New("field and string value expected")
+			}
+			cond.field, cond.value = strings.ToLower(strings.TrimPrefix(field, "$")), value
+		default:
+			return nil, fmt.Errorf("unknown operator '%s'", operator)
+		}
+
+		return cond, nil
+	}
+
+	return nil, errors.New("object or array expected")
+}
+
+// matchForm checks if the form satisfies the condition. The length range is
+// checked only if the file is in the form, the request without a file is
+// rejected by the handler.
+func (c *postPolicyCondition) matchForm(r *http.Request) bool {
+	if c.operator == "content-length-range" {
+		var size int64
+		if content, ok := r.MultipartForm.Value["file"]; ok {
+			size = int64(len(content[0]))
+		} else if files := r.MultipartForm.File["file"]; len(files) > 0 {
+			size = files[0].Size
+		} else {
+			return true
+		}
+		return size >= c.min && size <= c.max
+	}
+
+	return c.match(MultipartFormValue(r, c.field))
+}
+
+// match checks if the value of the field satisfies the condition.
+func (c *postPolicyCondition) match(value string) bool {
+	switch c.operator {
+	case "content-length-range":
+		return true
+	case "eq":
+		return value == c.value
+	}
+
+	// Content-Type can be a list of types, every one must match.
+	if c.field == "content-type" {
+		for _, contentType := range strings.Split(value, ",") {
+			if !strings.HasPrefix(contentType, c.value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return strings.HasPrefix(value, c.value)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPostPolicyForm(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	addr := oidtest.Address()
+	accessKeyID := strings.ReplaceAll(addr.EncodeToString(), "/", "0")
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+
+	c := &center{
+		creds:   NewAccessBoxBackend(&credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: box}}),
+		postReg: NewRegexpMatcher(postPolicyCredentialRegexp),
+	}
+
+	now := time.Now()
+	prm := PostPolicyParams{
+		AccessKeyID:       accessKeyID,
+		SecretKey:         secret,
+		Bucket:            "bucket",
+		KeyPrefix:         "uploads/",
+		ContentTypePrefix: "image/",
+		MinSize:           1,
+		MaxSize:           10 << 20,
+		Expiration:        now.Add(time.Hour),
+	}
+
+	form, err := NewPostPolicyForm(prm, now)
+	require.NoError(t, err)
+	require.Equal(t, "uploads/${filename}", form.Fields["key"])
+	require.Equal(t, accessKeyID+"/"+now.UTC().Format("20060102")+"/us-east-1/s3/aws4_request", form.Fields["x-amz-credential"])
+
+	rawPolicy, err := base64.StdEncoding.DecodeString(form.Fields["policy"])
+	require.NoError(t, err)
+	var policy struct {
+		Expiration time.Time         `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	require.NoError(t, json.Unmarshal(rawPolicy, &policy))
+	require.Equal(t, prm.Expiration.Unix(), policy.Expiration.Unix())
+	require.Len(t, policy.Conditions, 7)
+	require.JSONEq(t, `["content-length-range",1,10485760]`, string(policy.Conditions[2]))
+
+	newRequest := func(fields map[string]string) *http.Request {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for key, value := range fields {
+			require.NoError(t, writer.WriteField(key, value))
+		}
+		require.NoError(t, writer.Close())
+
+		r := httptest.NewRequest(http.MethodPost, "http://localhost/bucket", &body)
+		r.Header.Set(ContentTypeHdr, writer.FormDataContentType())
+		return r
+	}
+
+	_, err = c.Authenticate(newRequest(form.Fields))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	form.Fields["Content-Type"] = "image/png"
+	res, err := c.Authenticate(newRequest(form.Fields))
+	require.NoError(t, err)
+	require.Equal(t, box, res.AccessBox)
+
+	form.Fields["x-amz-signature"] = strings.Repeat("0", 64)
+	_, err = c.Authenticate(newRequest(form.Fields))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+
+	form.Fields["x-amz-date"] = now.Add(-48 * time.Hour).UTC().Format("20060102T150405Z")
+	_, err = c.Authenticate(newRequest(form.Fields))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrCredMalformed))
+
+	form.Fields["x-amz-algorithm"] = "AWS4-HMAC-SHA1"
+	_, err = c.Authenticate(newRequest(form.Fields))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+
+	for _, tc := range []struct {
+		name   string
+		modify func(prm *PostPolicyParams)
+	}{
+		{name: "no credentials", modify: func(prm *PostPolicyParams) { prm.SecretKey = "" }},
+		{name: "no bucket", modify: func(prm *PostPolicyParams) { prm.Bucket = "" }},
+		{name: "negative size", modify: func(prm *PostPolicyParams) { prm.MinSize = -1 }},
+		{name: "invalid size range", modify: func(prm *PostPolicyParams) { prm.MaxSize = 0 }},
+		{name: "expired", modify: func(prm *PostPolicyParams) { prm.Expiration = now }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := prm
+			tc.modify(&invalid)
+			_, err := NewPostPolicyForm(invalid, now)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCheckPostPolicy(t *testing.T) {
+	now := time.Now()
+	encode := func(policy string) string {
+		return base64.StdEncoding.EncodeToString([]byte(policy))
+	}
+	expiration := now.Add(time.Hour).UTC().Format(time.RFC3339)
+
+	newRequest := func(fields map[string]string, file string) *http.Request {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for key, value := range fields {
+			require.NoError(t, writer.WriteField(key, value))
+		}
+		if file != "" {
+			part, err := writer.CreateFormFile("file", "name.txt")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(file))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		r := httptest.NewRequest(http.MethodPost, "http://localhost/bucket", &body)
+		r.Header.Set(ContentTypeHdr, writer.FormDataContentType())
+		require.NoError(t, r.ParseMultipartForm(maxFormSizeMemory))
+		require.NoError(t, prepareForm(r.MultipartForm))
+		return r
+	}
+
+	fields := map[string]string{"key": "uploads/name.txt", "Content-Type": "text/plain"}
+	conditions := `[{"bucket":"bucket"},["starts-with","$key","uploads/"],["eq","$Content-Type","text/plain"],["content-length-range",1,10]]`
+
+	require.NoError(t, checkPostPolicy(newRequest(fields, "content"),
+		encode(`{"expiration":"`+expiration+`","conditions":`+conditions+`}`), now))
+
+	for _, tc := range []struct {
+		name   string
+		policy string
+		file   string
+		err    s3errors.ErrorCode
+	}{
+		{name: "invalid base64", policy: "!", err: s3errors.ErrMalformedPolicy},
+		{name: "invalid json", policy: encode("{"), err: s3errors.ErrMalformedPolicy},
+		{name: "no expiration", policy: encode(`{"conditions":[]}`), err: s3errors.ErrMalformedPolicy},
+		{name: "expired", policy: encode(`{"expiration":"` + now.Add(-time.Hour).UTC().Format(time.RFC3339) + `","conditions":[]}`), err: s3errors.ErrAccessDenied},
+		{name: "unknown operator", policy: encode(`{"expiration":"` + expiration + `","conditions":[["ends-with","$key","txt"]]}`), err: s3errors.ErrMalformedPolicy},
+		{name: "field without $", policy: encode(`{"expiration":"` + expiration + `","conditions":[["eq","key","uploads/name.txt"]]}`), err: s3errors.ErrMalformedPolicy},
+		{name: "key mismatch", policy: encode(`{"expiration":"` + expiration + `","conditions":[["starts-with","$key","other/"]]}`), err: s3errors.ErrAccessDenied},
+		{name: "missing field", policy: encode(`{"expiration":"` + expiration + `","conditions":[{"acl":"public-read"}]}`), err: s3errors.ErrAccessDenied},
+		{name: "file too large", policy: encode(`{"expiration":"` + expiration + `","conditions":` + conditions + `}`), file: "too large content", err: s3errors.ErrAccessDenied},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			file := tc.file
+			if file == "" {
+				file = "content"
+			}
+			err := checkPostPolicy(newRequest(fields, file), tc.policy, now)
+			require.ErrorIs(t, err, s3errors.GetAPIError(tc.err))
+		})
+	}
+}
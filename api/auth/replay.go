@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// ReplayCache remembers signatures of requests signed with headers (SigV4,
+// SigV4A and SigV2), so captured requests can't be replayed while their
+// signatures are valid.
+// Requests signed more than the window before or after the gateway time are
+// rejected, signatures are remembered till they're outside the window. The
+// least recently used signatures are evicted once the cache is full.
+//
+// Presigned URLs and POST policies are meant to be reused till they expire,
+// so they aren't checked. Signatures are remembered in memory of the process,
+// so other gateway instances accept the same request.
+type ReplayCache struct {
+	window time.Duration
+
+	// mu makes checks and stores of signatures atomic.
+	mu    sync.Mutex
+	cache gcache.Cache
+}
+
+// NewReplayCache creates a cache of at most size signatures accepted within
+// the window.
+func NewReplayCache(size int, window time.Duration) *ReplayCache {
+	return &ReplayCache{
+		window: window,
+		cache:  gcache.New(size).LRU().Build(),
+	}
+}
+
+// check rejects the request signed at the given time if its signature has
+// already been accepted, otherwise the signature is remembered.
+func (c *ReplayCache) check(accessKeyID, signature string, signed time.Time) error {
+	if skew := time.Since(signed); skew > c.window || skew < -c.window {
+		return s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed)
+	}
+
+	key := accessKeyID + "/" + signature
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cache.Has(key) {
+		return s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	return c.cache.SetWithExpire(key, struct{}{}, time.Until(signed.Add(c.window)))
+}
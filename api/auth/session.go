@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+)
+
+const (
+	// SessionTokenHdr is a header with the session token of temporary
+	// credentials.
+	SessionTokenHdr = "X-Amz-Security-Token"
+
+	// temporaryAccessKeyIDPrefix is used for temporary credentials by AWS.
+	temporaryAccessKeyIDPrefix = "ASIA"
+	temporaryAccessKeyIDSize   = 10
+	temporarySecretSize        = 30
+)
+
+type (
+	// Sessions issues and resolves temporary credentials. The session token
+	// contains the access key ID of the credentials the temporary ones are
+	// issued for and the temporary secret access key encrypted with the key
+	// of the gateway, so temporary credentials are resolved by any gateway
+	// with the same key and aren't stored anywhere. Tokens encrypted with
+	// previous keys are resolved too, so temporary credentials survive the
+	// rotation of the key.
+	Sessions struct {
+		aead     cipher.AEAD
+		previous []cipher.AEAD
+	}
+
+	// Session is temporary credentials.
+	Session struct {
+		AccessKeyID     string    `json:"k"`
+		SecretAccessKey string    `json:"s"`
+		Expiration      time.Time `json:"e"`
+		// ParentAccessKeyID is the access key ID of the credentials the
+		// temporary ones are issued for. Requests signed with the temporary
+		// credentials are made with the bearer token of the parent ones.
+		ParentAccessKeyID string `json:"p"`
+		// SessionToken is the encrypted session, it's sent by clients with
+		// every request.
+		SessionToken string `json:"-"`
+	}
+)
+
+// NewSessions creates Sessions encrypting session tokens with the 32 bytes key,
+// tokens encrypted with the previous keys are only decrypted.
+func NewSessions(key []byte, previous ...[]byte) (*Sessions, error) {
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sessions{aead: aead, previous: make([]cipher.AEAD, 0, len(previous))}
+	for i, prev := range previous {
+		if aead, err = newSessionAEAD(prev); err != nil {
+			return nil, fmt.Errorf("previous key %d: %w", i, err)
+		}
+		s.previous = append(s.previous, aead)
+	}
+
+	return s, nil
+}
+
+func newSessionAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create aead: %w", err)
+	}
+
+	return aead, nil
+}
+
+// Issue creates temporary credentials of the parent access key ID valid
+// until the expiration.
+func (s *Sessions) Issue(parentAccessKeyID string, expiration time.Time) (*Session, error) {
+	buf := make([]byte, temporaryAccessKeyIDSize+temporarySecretSize+s.aead.NonceSize())
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate session: %w", err)
+	}
+	nonce := buf[temporaryAccessKeyIDSize+temporarySecretSize:]
+
+	session := &Session{
+		AccessKeyID:       temporaryAccessKeyIDPrefix + base32.StdEncoding.EncodeToString(buf[:temporaryAccessKeyIDSize]),
+		SecretAccessKey:   base64.RawStdEncoding.EncodeToString(buf[temporaryAccessKeyIDSize : temporaryAccessKeyIDSize+temporarySecretSize]),
+		Expiration:        expiration.UTC().Truncate(time.Second),
+		ParentAccessKeyID: parentAccessKeyID,
+	}
+
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("marshal session: %w", err)
+	}
+
+	sealed := s.aead.Seal(append([]byte{}, nonce...), nonce, plaintext, nil)
+	session.SessionToken = base64.RawURLEncoding.EncodeToString(sealed)
+
+	return session, nil
+}
+
+// Resolve decrypts the session token of the temporary access key ID. It
+// returns InvalidTokenId S3 error if the token is invalid or doesn't belong
+// to the access key ID and ExpiredToken S3 error if the token has expired.
+func (s *Sessions) Resolve(sessionToken, accessKeyID string, now time.Time) (*Session, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(sessionToken)
+	if err != nil {
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidToken)
+	}
+
+	plaintext, ok := open(s.aead, sealed)
+	for i := 0; !ok && i < len(s.previous); i++ {
+		plaintext, ok = open(s.previous[i], sealed)
+	}
+	if !ok {
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidToken)
+	}
+
+	var session Session
+	if err = json.Unmarshal(plaintext, &session); err != nil || session.AccessKeyID != accessKeyID {
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidToken)
+	}
+
+	if !now.Before(session.Expiration) {
+		return nil, s3errors.GetAPIError(s3errors.ErrExpiredToken)
+	}
+	session.SessionToken = sessionToken
+
+	return &session, nil
+}
+
+// querySessionToken returns the session token of the presigned URL. SigV4
+// URLs have X-Amz-Security-Token parameter, while SigV2 ones are presigned
+// with the lowercase one by clients, so the name is matched case-insensitively.
+func querySessionToken(query url.Values) string {
+	if token := query.Get(SessionTokenHdr); token != "" {
+		return token
+	}
+
+	for key, values := range query {
+		if strings.EqualFold(key, SessionTokenHdr) && len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, bool) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, false
+	}
+
+	nonce := sealed[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, sealed[len(nonce):], nil)
+	return plaintext, err == nil
+}
+
+// box returns the box of the parent credentials with the temporary secret
+// access key. Boxes are cached, so the parent box is copied.
+func (s *Session) box(parent *accessbox.Box) *accessbox.Box {
+	gate := *parent.Gate
+	gate.AccessKey = s.SecretAccessKey
+
+	box := *parent
+	box.Gate = &gate
+
+	return &box
+}
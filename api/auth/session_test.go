@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	awsv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessions(t *testing.T, keyByte byte) *Sessions {
+	sessions, err := NewSessions(bytes.Repeat([]byte{keyByte}, 32))
+	require.NoError(t, err)
+	return sessions
+}
+
+func TestSessions(t *testing.T) {
+	sessions := newTestSessions(t, 1)
+	now := time.Now()
+
+	session, err := sessions.Issue("parent", now.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(session.AccessKeyID, temporaryAccessKeyIDPrefix))
+	require.NotEmpty(t, session.SecretAccessKey)
+
+	resolved, err := sessions.Resolve(session.SessionToken, session.AccessKeyID, now)
+	require.NoError(t, err)
+	require.Equal(t, session, resolved)
+
+	other, err := sessions.Issue("parent", now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotEqual(t, session.AccessKeyID, other.AccessKeyID)
+	require.NotEqual(t, session.SecretAccessKey, other.SecretAccessKey)
+
+	invalid := s3errors.GetAPIError(s3errors.ErrInvalidToken)
+
+	_, err = sessions.Resolve(session.SessionToken, other.AccessKeyID, now)
+	require.ErrorIs(t, err, invalid)
+
+	_, err = sessions.Resolve("garbage", session.AccessKeyID, now)
+	require.ErrorIs(t, err, invalid)
+
+	tampered := []byte(session.SessionToken)
+	tampered[len(tampered)/2] ^= 1
+	_, err = sessions.Resolve(string(tampered), session.AccessKeyID, now)
+	require.ErrorIs(t, err, invalid)
+
+	// tokens of other gateway keys aren't accepted
+	_, err = newTestSessions(t, 2).Resolve(session.SessionToken, session.AccessKeyID, now)
+	require.ErrorIs(t, err, invalid)
+
+	_, err = sessions.Resolve(session.SessionToken, session.AccessKeyID, now.Add(time.Hour))
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+
+	// tokens of previous keys are resolved, new ones are issued with the current key
+	rotated, err := NewSessions(bytes.Repeat([]byte{2}, 32), bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	resolved, err = rotated.Resolve(session.SessionToken, session.AccessKeyID, now)
+	require.NoError(t, err)
+	require.Equal(t, session, resolved)
+
+	session, err = rotated.Issue("parent", now.Add(time.Hour))
+	require.NoError(t, err)
+	_, err = newTestSessions(t, 2).Resolve(session.SessionToken, session.AccessKeyID, now)
+	require.NoError(t, err)
+	_, err = sessions.Resolve(session.SessionToken, session.AccessKeyID, now)
+	require.ErrorIs(t, err, invalid)
+
+	_, err = NewSessions(bytes.Repeat([]byte{2}, 32), []byte{1})
+	require.Error(t, err)
+}
+
+func TestAuthenticateTemporaryCredentials(t *testing.T) {
+	parent := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "parent-secret"}}
+	sessions := newTestSessions(t, 1)
+	c := New(staticBackend{"parent": parent}, sessions, nil, 15*time.Minute, true, true, Scope{}, nil)
+
+	session, err := sessions.Issue("parent", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	creds := credentials.NewStaticCredentials(session.AccessKeyID, session.SecretAccessKey, session.SessionToken)
+
+	t.Run("header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		_, err := awsv4.NewSigner(creds).Sign(r, nil, "s3", "us-east-1", time.Now())
+		require.NoError(t, err)
+
+		box, err := c.Authenticate(r)
+		require.NoError(t, err)
+		require.Equal(t, session.AccessKeyID, box.AccessKeyID)
+		require.Equal(t, "parent", box.Session.ParentAccessKeyID)
+		require.Equal(t, session.SecretAccessKey, box.AccessBox.Gate.AccessKey)
+		// the cached box of the parent credentials is not changed
+		require.Equal(t, "parent-secret", parent.Gate.AccessKey)
+
+		r.Header.Del(SessionTokenHdr)
+		_, err = c.Authenticate(r)
+		require.Error(t, err)
+	})
+
+	t.Run("presigned", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		require.NoError(t, err)
+		signer := awsv4.NewSigner(creds)
+		signer.DisableURIPathEscaping = true
+		_, err = signer.Presign(req, nil, "s3", "us-east-1", time.Hour, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+
+		box, err := c.Authenticate(httptest.NewRequest(http.MethodGet, req.URL.String(), nil))
+		require.NoError(t, err)
+		require.NotNil(t, box.Session)
+	})
+
+	t.Run("signature v2", func(t *testing.T) {
+		date := time.Now().UTC().Format(http.TimeFormat)
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		r.Header.Set(DateHdr, date)
+		r.Header.Set(SessionTokenHdr, session.SessionToken)
+		r.Header.Set(AuthorizationHdr, "AWS "+session.AccessKeyID+":"+signV2(session.SecretAccessKey, stringToSignV2(r, date)))
+
+		box, err := c.Authenticate(r)
+		require.NoError(t, err)
+		require.NotNil(t, box.Session)
+
+		r.Header.Del(SessionTokenHdr)
+		_, err = c.Authenticate(r)
+		require.Error(t, err)
+	})
+
+	t.Run("presigned v2", func(t *testing.T) {
+		expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		signed := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		signed.Header.Set(strings.ToLower(SessionTokenHdr), session.SessionToken)
+		query := url.Values{
+			AmzAccessKeyIDV2:                 []string{session.AccessKeyID},
+			AmzExpiresV2:                     []string{expires},
+			AmzSignatureV2:                   []string{signV2(session.SecretAccessKey, stringToSignV2(signed, expires))},
+			strings.ToLower(SessionTokenHdr): []string{session.SessionToken},
+		}
+
+		box, err := c.Authenticate(httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object?"+query.Encode(), nil))
+		require.NoError(t, err)
+		require.NotNil(t, box.Session)
+	})
+
+	t.Run("signed body", func(t *testing.T) {
+		payload := []byte("Action=GetSessionToken&Version=2011-06-15")
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8089/", nil)
+		require.NoError(t, err)
+		req.Header.Set(ContentTypeHdr, "application/x-www-form-urlencoded")
+		_, err = awsv4.NewSigner(creds).Sign(req, bytes.NewReader(payload), "sts", "us-east-1", time.Now())
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "http://localhost:8089/", nil)
+		r.Header = req.Header.Clone()
+		WithSignedBody(r, payload)
+		_, err = c.Authenticate(r)
+		require.NoError(t, err)
+
+		WithSignedBody(r, []byte("Action=AssumeRole&Version=2011-06-15"))
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := sessions.Issue("parent", time.Now().Add(-time.Second))
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		_, err = awsv4.NewSigner(credentials.NewStaticCredentials(expired.AccessKeyID, expired.SecretAccessKey, expired.SessionToken)).
+			Sign(r, nil, "s3", "us-east-1", time.Now())
+		require.NoError(t, err)
+
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredToken))
+	})
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/limits"
+)
+
+// authorizationV2Regexp -- is regexp for AWS Signature Version 2 credentials.
+var authorizationV2Regexp = regexp.MustCompile(`^AWS (?P<access_key_id>[^:\s]+):(?P<v2_signature>\S+)$`)
+
+const (
+	authHeaderV2PartsNum = 2
+
+	AmzAccessKeyIDV2 = "AWSAccessKeyId"
+	AmzSignatureV2   = "Signature"
+	AmzExpiresV2     = "Expires"
+)
+
+// subResourcesV2 are query parameters included in the resource signed with
+// AWS Signature Version 2.
+var subResourcesV2 = map[string]struct{}{
+	"acl": {}, "cors": {}, "delete": {}, "lifecycle": {}, "location": {}, "logging": {},
+	"notification": {}, "partNumber": {}, "policy": {}, "requestPayment": {}, "restore": {},
+	"tagging": {}, "torrent": {}, "uploadId": {}, "uploads": {}, "versionId": {},
+	"versioning": {}, "versions": {}, "website": {}, "object-lock": {}, "retention": {},
+	"legal-hold": {}, "encryption": {}, "publicAccessBlock": {}, "attributes": {},
+	"response-cache-control": {}, "response-content-disposition": {}, "response-content-encoding": {},
+	"response-content-language": {}, "response-content-type": {}, "response-expires": {},
+}
+
+type virtualHostedBucketKey struct{}
+
+// WithVirtualHostedBucket returns the context of virtual-hosted-style request
+// to the bucket, the bucket is a part of the resource signed with AWS
+// Signature Version 2.
+func WithVirtualHostedBucket(ctx context.Context, bucket string) context.Context {
+	return context.WithValue(ctx, virtualHostedBucketKey{}, bucket)
+}
+
+func virtualHostedBucket(ctx context.Context) string {
+	bucket, _ := ctx.Value(virtualHostedBucketKey{}).(string)
+	return bucket
+}
+
+// authenticateV2 checks the request signed with AWS Signature Version 2 in
+// Authorization header or in query parameters of the presigned URL.
+func (c *center) authenticateV2(r *http.Request, authHeaderField string) (*Box, error) {
+	if !c.allowSignatureV2 {
+		return nil, fmt.Errorf("%w: AWS Signature Version 2 is disabled", s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+	}
+
+	var (
+		accessKeyID, signature, date string
+		clientTime                   time.Time
+	)
+
+	if authHeaderField != "" {
+		// SigV2 never signs payloads, presigned URLs are allowed to be
+		// unsigned as V4 ones are.
+		if c.denyUnsignedPayload {
+			return nil, fmt.Errorf("%w: unsigned payloads aren't allowed", s3errors.GetAPIError(s3errors.ErrAccessDenied))
+		}
+
+		submatches := c.regV2.GetSubmatches(authHeaderField)
+		if len(submatches) != authHeaderV2PartsNum {
+			return nil, s3errors.GetAPIError(s3errors.ErrCredMalformed)
+		}
+		accessKeyID, signature = submatches["access_key_id"], submatches["v2_signature"]
+
+		// X-Amz-Date takes precedence and the signed Date is empty then.
+		dateStr := r.Header.Get(AmzDate)
+		if dateStr == "" {
+			dateStr = r.Header.Get(DateHdr)
+			date = dateStr
+		}
+		if dateStr == "" {
+			return nil, s3errors.GetAPIError(s3errors.ErrMissingDateHeader)
+		}
+
+		var err error
+		if clientTime, err = parseSignatureTime(dateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse request date '%s': %w", dateStr, err)
+		}
+		if err = c.checkClockSkew(clientTime); err != nil {
+			return nil, err
+		}
+	} else {
+		query := r.URL.Query()
+		accessKeyID, signature, date = query.Get(AmzAccessKeyIDV2), query.Get(AmzSignatureV2), query.Get(AmzExpiresV2)
+		if accessKeyID == "" || signature == "" || date == "" {
+			return nil, s3errors.GetAPIError(s3errors.ErrInvalidQueryParams)
+		}
+
+		expires, err := strconv.ParseInt(date, 10, 64)
+		if err != nil {
+			return nil, s3errors.GetAPIError(s3errors.ErrMalformedExpires)
+		}
+
+		expiration := time.Unix(expires, 0)
+		now := time.Now()
+		if expiration.Before(now) {
+			return nil, s3errors.GetAPIError(s3errors.ErrExpiredPresignRequest)
+		}
+		if expiration.Sub(now) > limits.MaxPreSignedLifetime {
+			return nil, s3errors.GetAPIError(s3errors.ErrMaximumExpires)
+		}
+	}
+
+	sessionToken := r.Header.Get(SessionTokenHdr)
+	if authHeaderField == "" {
+		sessionToken = querySessionToken(r.URL.Query())
+	}
+
+	box, session, err := c.resolveCredentials(r.Context(), accessKeyID, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	expected := signV2(box.Gate.AccessKey, stringToSignV2(r, date))
+	matched := hmac.Equal([]byte(expected), []byte(signature))
+	metrics.ObserveSignatureVerification("v2", time.Since(start))
+	if !matched {
+		return nil, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)
+	}
+
+	if authHeaderField != "" {
+		if err = c.checkReplay(accessKeyID, signature, clientTime); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Box{AccessBox: box, ClientTime: clientTime, AccessKeyID: accessKeyID, Session: session}, nil
+}
+
+// isPresignedV2 checks if the URL is presigned with AWS Signature Version 2.
+func isPresignedV2(query url.Values) bool {
+	return query.Has(AmzAccessKeyIDV2) && query.Has(AmzSignatureV2)
+}
+
+func signV2(secret, stringToSign string) string {
+	hash := hmac.New(sha1.New, []byte(secret))
+	hash.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// stringToSignV2 builds the string signed with AWS Signature Version 2, the
+// date is Date header or Expires parameter of the presigned URL. x-amz-
+// parameters of presigned URLs (like the session token) are signed as
+// headers.
+func stringToSignV2(r *http.Request, date string) string {
+	var buf strings.Builder
+
+	buf.WriteString(r.Method + "\n")
+	buf.WriteString(r.Header.Get("Content-MD5") + "\n")
+	buf.WriteString(r.Header.Get(ContentTypeHdr) + "\n")
+	buf.WriteString(date + "\n")
+
+	amzValues := make(map[string][]string)
+	for key, values := range r.Header {
+		if lowerKey := strings.ToLower(key); strings.HasPrefix(lowerKey, "x-amz-") {
+			amzValues[lowerKey] = append(amzValues[lowerKey], values...)
+		}
+	}
+	if query := r.URL.Query(); isPresignedV2(query) {
+		for key, values := range query {
+			if lowerKey := strings.ToLower(key); strings.HasPrefix(lowerKey, "x-amz-") {
+				amzValues[lowerKey] = append(amzValues[lowerKey], values...)
+			}
+		}
+	}
+
+	amzHeaders := make([]string, 0, len(amzValues))
+	for key := range amzValues {
+		amzHeaders = append(amzHeaders, key)
+	}
+	sort.Strings(amzHeaders)
+
+	for _, key := range amzHeaders {
+		values := make([]string, 0, len(amzValues[key]))
+		for _, value := range amzValues[key] {
+			values = append(values, strings.TrimSpace(value))
+		}
+		buf.WriteString(key + ":" + strings.Join(values, ",") + "\n")
+	}
+
+	buf.WriteString(canonicalResourceV2(r))
+
+	return buf.String()
+}
+
+func canonicalResourceV2(r *http.Request) string {
+	resource := r.URL.EscapedPath()
+	if bucket := virtualHostedBucket(r.Context()); bucket != "" {
+		resource = "/" + bucket + resource
+	}
+
+	query := r.URL.Query()
+	subResources := make([]string, 0, len(query))
+	for key := range query {
+		if _, ok := subResourcesV2[key]; ok {
+			subResources = append(subResources, key)
+		}
+	}
+	if len(subResources) == 0 {
+		return resource
+	}
+	sort.Strings(subResources)
+
+	for i, key := range subResources {
+		if value := query.Get(key); value != "" {
+			subResources[i] = key + "=" + value
+		}
+	}
+
+	return resource + "?" + strings.Join(subResources, "&")
+}
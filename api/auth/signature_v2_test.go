@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialsMock struct {
+	tokens.Credentials
+	boxes map[oid.Address]*accessbox.Box
+}
+
+func (m *credentialsMock) GetBox(_ context.Context, addr oid.Address) (*accessbox.Box, error) {
+	box, ok := m.boxes[addr]
+	if !ok {
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+	}
+	return box, nil
+}
+
+// TestSignatureV2 checks examples from https://docs.aws.amazon.com/AmazonS3/latest/userguide/RESTAuthentication.html
+func TestSignatureV2(t *testing.T) {
+	secret := "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"
+
+	r := httptest.NewRequest(http.MethodGet, "http://johnsmith.s3.amazonaws.com/photos/puppy.jpg", nil)
+	r.Header.Set(DateHdr, "Tue, 27 Mar 2007 19:36:42 +0000")
+	r = r.WithContext(WithVirtualHostedBucket(r.Context(), "johnsmith"))
+	signature := signV2(secret, stringToSignV2(r, r.Header.Get(DateHdr)))
+	require.Equal(t, "bWq2s1WEIj+Ydj0vQ697zp+IXMU=", signature)
+
+	r = httptest.NewRequest(http.MethodGet, "http://s3.amazonaws.com/johnsmith/?prefix=photos&max-keys=50&marker=puppy", nil)
+	r.Header.Set(DateHdr, "Tue, 27 Mar 2007 19:42:41 +0000")
+	require.Equal(t, "GET\n\n\nTue, 27 Mar 2007 19:42:41 +0000\n/johnsmith/", stringToSignV2(r, r.Header.Get(DateHdr)))
+
+	r = httptest.NewRequest(http.MethodGet, "http://s3.amazonaws.com/johnsmith/?acl", nil)
+	require.Equal(t, "/johnsmith/?acl", canonicalResourceV2(r))
+
+	r = httptest.NewRequest(http.MethodPut, "http://s3.amazonaws.com/bucket/obj?uploadId=id&partNumber=1&x-id=UploadPart", nil)
+	r.Header.Set("X-Amz-Meta-B", " b ")
+	r.Header.Add("X-Amz-Meta-A", "a1")
+	r.Header.Add("X-Amz-Meta-A", "a2")
+	r.Header.Set(AmzDate, "Tue, 27 Mar 2007 19:42:41 +0000")
+	r.Header.Set(ContentTypeHdr, "text/plain")
+	require.Equal(t, "PUT\n\ntext/plain\n\nx-amz-date:Tue, 27 Mar 2007 19:42:41 +0000\nx-amz-meta-a:a1,a2\nx-amz-meta-b:b\n/bucket/obj?partNumber=1&uploadId=id",
+		stringToSignV2(r, ""))
+}
+
+func TestAuthenticateV2(t *testing.T) {
+	secret := "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	addr := oidtest.Address()
+	accessKeyID := strings.ReplaceAll(addr.EncodeToString(), "/", "0")
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: secret}}
+
+	c := &center{
+		creds:            NewAccessBoxBackend(&credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: box}}),
+		reg:              NewRegexpMatcher(authorizationFieldRegexp),
+		regV2:            NewRegexpMatcher(authorizationV2Regexp),
+		allowSignatureV2: true,
+	}
+
+	t.Run("header", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		r := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+		r.Header.Set(DateHdr, now.Format(http.TimeFormat))
+		r.Header.Set(AuthorizationHdr, "AWS "+accessKeyID+":"+signV2(secret, stringToSignV2(r, now.Format(http.TimeFormat))))
+
+		res, err := c.Authenticate(r)
+		require.NoError(t, err)
+		require.Equal(t, box, res.AccessBox)
+		require.Equal(t, now, res.ClientTime.UTC())
+
+		r.Header.Set("X-Amz-Meta-Key", "value")
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+
+		r.Header.Set(AuthorizationHdr, "AWS "+accessKeyID)
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrCredMalformed))
+
+		c.maxClockSkew = 15 * time.Minute
+		defer func() { c.maxClockSkew = 0 }()
+		old := now.Add(-time.Hour).Format(http.TimeFormat)
+		r = httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+		r.Header.Set(DateHdr, old)
+		r.Header.Set(AuthorizationHdr, "AWS "+accessKeyID+":"+signV2(secret, stringToSignV2(r, old)))
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrRequestTimeTooSkewed))
+	})
+
+	t.Run("presigned", func(t *testing.T) {
+		presign := func(expires time.Time) *http.Request {
+			expiresStr := strconv.FormatInt(expires.Unix(), 10)
+			r := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+			signature := signV2(secret, stringToSignV2(r, expiresStr))
+
+			query := r.URL.Query()
+			query.Set(AmzAccessKeyIDV2, accessKeyID)
+			query.Set(AmzExpiresV2, expiresStr)
+			query.Set(AmzSignatureV2, signature)
+			r.URL.RawQuery = query.Encode()
+			return r
+		}
+
+		res, err := c.Authenticate(presign(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		require.Equal(t, box, res.AccessBox)
+		require.True(t, res.ClientTime.IsZero())
+
+		_, err = c.Authenticate(presign(time.Now().Add(-time.Second)))
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrExpiredPresignRequest))
+
+		_, err = c.Authenticate(presign(time.Now().Add(8 * 24 * time.Hour)))
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrMaximumExpires))
+	})
+
+	t.Run("unsigned payload denied", func(t *testing.T) {
+		c.denyUnsignedPayload = true
+		defer func() { c.denyUnsignedPayload = false }()
+
+		date := time.Now().UTC().Format(http.TimeFormat)
+		r := httptest.NewRequest(http.MethodPut, "http://localhost/bucket/object", strings.NewReader("payload"))
+		r.Header.Set(DateHdr, date)
+		r.Header.Set(AuthorizationHdr, "AWS "+accessKeyID+":"+signV2(secret, stringToSignV2(r, date)))
+		_, err := c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+		expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		r = httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+		query := r.URL.Query()
+		query.Set(AmzAccessKeyIDV2, accessKeyID)
+		query.Set(AmzExpiresV2, expires)
+		query.Set(AmzSignatureV2, signV2(secret, stringToSignV2(r, expires)))
+		r.URL.RawQuery = query.Encode()
+		_, err = c.Authenticate(r)
+		require.NoError(t, err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c.allowSignatureV2 = false
+		defer func() { c.allowSignatureV2 = true }()
+
+		date := time.Now().UTC().Format(http.TimeFormat)
+		r := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object", nil)
+		r.Header.Set(DateHdr, date)
+		r.Header.Set(AuthorizationHdr, "AWS "+accessKeyID+":"+signV2(secret, stringToSignV2(r, date)))
+		_, err := c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+
+		r = httptest.NewRequest(http.MethodGet, "http://localhost/bucket/object?"+AmzAccessKeyIDV2+"="+accessKeyID+"&"+AmzSignatureV2+"=sig&"+AmzExpiresV2+"=1", nil)
+		_, err = c.Authenticate(r)
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrSignatureVersionNotSupported))
+	})
+}
@@ -46,6 +46,31 @@ func (s *ChunkSigner) GetSignatureByHash(payloadHash hash.Hash) ([]byte, error)
 	return s.getSignature(payloadHash.Sum(nil))
 }
 
+// GetTrailerSignature takes trailing headers as `name:value\n` lines and returns a signature.
+func (s *ChunkSigner) GetTrailerSignature(trailer []byte) ([]byte, error) {
+	credValue, err := s.credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	sigKey := deriveSigningKey(s.region, s.service, credValue.SecretAccessKey, s.seedDate)
+
+	keyPath := buildSigningScope(s.region, s.service, s.seedDate)
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-TRAILER",
+		formatTime(s.seedDate),
+		keyPath,
+		hex.EncodeToString(s.prevSig),
+		hex.EncodeToString(hashSHA256(trailer)),
+	}, "\n")
+
+	signature := hmacSHA256(sigKey, []byte(stringToSign))
+	s.prevSig = signature
+
+	return signature, nil
+}
+
 func (s *ChunkSigner) getSignature(payloadHash []byte) ([]byte, error) {
 	credValue, err := s.credentials.Get()
 	if err != nil {
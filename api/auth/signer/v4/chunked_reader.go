@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"strings"
 )
 
 const maxLineLength = 4096 // assumed <= bufio.defaultBufSize
@@ -30,8 +31,26 @@ var (
 	// ErrNoChunksSeparator appears if chunks not properly separated between each other.
 	// They should be divided with \r\n bytes.
 	ErrNoChunksSeparator = errors.New("no chunk separator")
+
+	// ErrInvalidTrailerSignature appears if passed signature of trailing headers differs from calculated.
+	ErrInvalidTrailerSignature = errors.New("invalid trailer signature")
+
+	// ErrMalformedTrailer appears if trailing headers aren't `name:value` lines.
+	ErrMalformedTrailer = errors.New("malformed trailer")
 )
 
+// trailerSignatureHeader is a trailing header with the signature of other
+// trailing headers.
+const trailerSignatureHeader = "x-amz-trailer-signature"
+
+// TrailerReader reads aws-chunked payload followed by trailing headers.
+type TrailerReader interface {
+	io.ReadCloser
+	// Trailer returns trailing headers with lowercase names. They're
+	// available after the payload is read till io.EOF.
+	Trailer() map[string]string
+}
+
 // NewChunkedReader returns a new chunkedReader that translates the data read from r
 // out of HTTP "chunked" format before returning it.
 // The chunkedReader returns io.EOF when the final 0-length chunk is read.
@@ -44,6 +63,19 @@ func NewChunkedReader(r io.ReadCloser, streamSigner *ChunkSigner) io.ReadCloser
 	}
 }
 
+// NewTrailerChunkedReader returns a new chunkedReader reading trailing headers
+// after the final 0-length chunk. Chunks and trailing headers are unsigned if
+// streamSigner is nil, otherwise x-amz-trailer-signature trailing header must
+// sign the other ones.
+func NewTrailerChunkedReader(r io.ReadCloser, streamSigner *ChunkSigner) TrailerReader {
+	return &chunkedReader{
+		r:            bufio.NewReader(r),
+		origReader:   r,
+		streamSigner: streamSigner,
+		withTrailer:  true,
+	}
+}
+
 type chunkedReader struct {
 	chunkHash      hash.Hash
 	chunkSignature string
@@ -54,6 +86,8 @@ type chunkedReader struct {
 	buf            [2]byte
 	checkEnd       bool // whether need to check for \r\n chunk footer
 	streamSigner   *ChunkSigner
+	withTrailer    bool
+	trailer        map[string]string
 }
 
 // Close implements [io.ReadCloser].
@@ -61,10 +95,15 @@ func (cr *chunkedReader) Close() (err error) {
 	return cr.origReader.Close()
 }
 
+// Trailer implements [TrailerReader].
+func (cr *chunkedReader) Trailer() map[string]string {
+	return cr.trailer
+}
+
 func (cr *chunkedReader) beginChunk() {
 	// chunk-size CRLF
 	var line, chunkSignature []byte
-	line, chunkSignature, cr.err = readChunkLine(cr.r)
+	line, chunkSignature, cr.err = readChunkLine(cr.r, cr.streamSigner != nil)
 	if cr.err != nil {
 		return
 	}
@@ -79,10 +118,13 @@ func (cr *chunkedReader) beginChunk() {
 	}
 
 	// creating instance here to avoid validating non-existent chunk in the first validatePreviousChunkData call.
-	if cr.chunkHash == nil {
-		cr.chunkHash = sha256.New()
-	} else {
-		cr.chunkHash.Reset()
+	// Unsigned chunks aren't hashed.
+	if cr.streamSigner != nil {
+		if cr.chunkHash == nil {
+			cr.chunkHash = sha256.New()
+		} else {
+			cr.chunkHash.Reset()
+		}
 	}
 
 	cr.chunkSignature = string(chunkSignature)
@@ -93,10 +135,81 @@ func (cr *chunkedReader) beginChunk() {
 			return
 		}
 
+		if cr.withTrailer {
+			if err := cr.readTrailer(); err != nil {
+				cr.err = err
+				return
+			}
+		}
+
 		cr.err = io.EOF
 	}
 }
 
+// readTrailer reads `name:value` lines of trailing headers till the empty
+// line and checks their signature if chunks are signed.
+func (cr *chunkedReader) readTrailer() error {
+	var (
+		signature string
+		canonical strings.Builder
+	)
+
+	cr.trailer = make(map[string]string)
+	for {
+		line, err := cr.r.ReadSlice('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			if err == io.EOF {
+				// the final CRLF is omitted
+				break
+			}
+			if errors.Is(err, bufio.ErrBufferFull) {
+				return ErrLineTooLong
+			}
+			return err
+		}
+		if len(line) >= maxLineLength {
+			return ErrLineTooLong
+		}
+
+		line = trimTrailingWhitespace(line)
+		if len(line) == 0 {
+			break
+		}
+
+		name, value, found := bytes.Cut(line, []byte(":"))
+		if !found {
+			return ErrMalformedTrailer
+		}
+
+		key := strings.ToLower(string(bytes.TrimSpace(name)))
+		val := string(bytes.TrimSpace(value))
+		if key == trailerSignatureHeader {
+			signature = val
+		} else {
+			cr.trailer[key] = val
+			canonical.WriteString(key + ":" + val + "\n")
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if cr.streamSigner == nil {
+		return nil
+	}
+
+	calculatedSignature, err := cr.streamSigner.GetTrailerSignature([]byte(canonical.String()))
+	if err != nil {
+		return fmt.Errorf("GetTrailerSignature: %w", err)
+	}
+	if signature != hex.EncodeToString(calculatedSignature) {
+		return ErrInvalidTrailerSignature
+	}
+
+	return nil
+}
+
 func (cr *chunkedReader) validatePreviousChunkData() error {
 	if cr.chunkHash != nil {
 		calculatedSignature, err := cr.streamSigner.GetSignatureByHash(cr.chunkHash)
@@ -167,9 +280,11 @@ func (cr *chunkedReader) Read(b []uint8) (n int, err error) {
 		cr.n -= uint64(n0)
 		// Hashing chunk data to calculate the signature.
 		// rbuf may contain payload and empty bytes, taking only payload.
-		if _, err = cr.chunkHash.Write(rbuf[:n0]); err != nil {
-			cr.err = err
-			break
+		if cr.chunkHash != nil {
+			if _, err = cr.chunkHash.Write(rbuf[:n0]); err != nil {
+				cr.err = err
+				break
+			}
 		}
 
 		// If we're at the end of a chunk, read the next two
@@ -187,17 +302,25 @@ func (cr *chunkedReader) Read(b []uint8) (n int, err error) {
 // Give up if the line exceeds maxLineLength.
 // The returned bytes are owned by the bufio.Reader
 // so they are only valid until the next bufio read.
-func readChunkLine(b *bufio.Reader) ([]byte, []byte, error) {
+// Lines of unsigned chunks may have no chunk-signature extension.
+func readChunkLine(b *bufio.Reader, signed bool) ([]byte, []byte, error) {
 	p, err := b.ReadSlice('\n')
 	if err != nil {
-		// We always know when EOF is coming.
-		// If the caller asked for a line, there should be a line.
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		} else if errors.Is(err, bufio.ErrBufferFull) {
-			err = ErrLineTooLong
+		// Some clients omit the CRLF after the final zero-length chunk header,
+		// so a non-empty line terminated by EOF is accepted here. If it's not
+		// the final chunk, the subsequent payload read fails anyway.
+		if err == io.EOF && len(p) > 0 {
+			err = nil
+		} else {
+			// We always know when EOF is coming.
+			// If the caller asked for a line, there should be a line.
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			} else if errors.Is(err, bufio.ErrBufferFull) {
+				err = ErrLineTooLong
+			}
+			return nil, nil, err
 		}
-		return nil, nil, err
 	}
 	if len(p) >= maxLineLength {
 		return nil, nil, ErrLineTooLong
@@ -206,6 +329,11 @@ func readChunkLine(b *bufio.Reader) ([]byte, []byte, error) {
 	var signaturePart []byte
 
 	p = trimTrailingWhitespace(p)
+	if !signed {
+		p, _, _ = bytes.Cut(p, semi)
+		return p, nil, nil
+	}
+
 	p, signaturePart, err = removeChunkExtension(p)
 	if err != nil {
 		return nil, nil, err
@@ -254,6 +382,9 @@ func removeChunkExtension(p []byte) ([]byte, []byte, error) {
 }
 
 func parseHexUint(v []byte) (n uint64, err error) {
+	if len(v) == 0 {
+		return 0, errors.New("empty chunk length")
+	}
 	for i, b := range v {
 		switch {
 		case '0' <= b && b <= '9':
@@ -55,6 +55,7 @@
 package v4
 
 import (
+	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -215,6 +216,17 @@ type Signer struct {
 	// UnsignedPayload will prevent signing of the payload. This will only
 	// work for services that have support for this.
 	UnsignedPayload bool
+
+	// KeepDateHeaders prevents the Signer from setting X-Amz-Date header if
+	// the request already has X-Amz-Date or Date header. It's used to verify
+	// signatures of requests dated in RFC1123 format or with Date header only.
+	KeepDateHeaders bool
+
+	// Asymmetric makes the Signer create SigV4A (AWS4-ECDSA-P256-SHA256)
+	// signatures with the ECDSA key derived from the credentials. The signing
+	// scope of such signatures doesn't include the region, regions are set
+	// with X-Amz-Region-Set header the caller adds to the request.
+	Asymmetric bool
 }
 
 // NewSigner returns a Signer pointer configured with the credentials and optional
@@ -247,6 +259,10 @@ type signingCtx struct {
 	credValues      credentials.Value
 	isPresign       bool
 	unsignedPayload bool
+	keepDateHeaders bool
+
+	// signingKey is set for SigV4A signatures.
+	signingKey *ecdsa.PrivateKey
 
 	bodyDigest       string
 	signedHeaders    string
@@ -321,23 +337,28 @@ func (v4 Signer) Presign(r *http.Request, body io.ReadSeeker, service, region st
 }
 
 func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, service, region string, exp time.Duration, isPresign bool, signTime time.Time) (http.Header, error) {
+	return v4.signWithContext(&signingCtx{
+		Request:     r,
+		Body:        body,
+		Time:        signTime,
+		ExpireTime:  exp,
+		isPresign:   isPresign,
+		ServiceName: service,
+		Region:      region,
+	})
+}
+
+func (v4 Signer) signWithContext(ctx *signingCtx) (http.Header, error) {
+	r := ctx.Request
 	currentTimeFn := v4.currentTimeFn
 	if currentTimeFn == nil {
 		currentTimeFn = time.Now
 	}
 
-	ctx := &signingCtx{
-		Request:                r,
-		Body:                   body,
-		Query:                  r.URL.Query(),
-		Time:                   signTime,
-		ExpireTime:             exp,
-		isPresign:              isPresign,
-		ServiceName:            service,
-		Region:                 region,
-		DisableURIPathEscaping: v4.DisableURIPathEscaping,
-		unsignedPayload:        v4.UnsignedPayload,
-	}
+	ctx.Query = r.URL.Query()
+	ctx.DisableURIPathEscaping = v4.DisableURIPathEscaping
+	ctx.unsignedPayload = v4.UnsignedPayload
+	ctx.keepDateHeaders = v4.KeepDateHeaders
 
 	for key := range ctx.Query {
 		sort.Strings(ctx.Query[key])
@@ -354,6 +375,13 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, service, regi
 		return http.Header{}, err
 	}
 
+	if v4.Asymmetric {
+		ctx.signingKey, err = DeriveAsymmetricKey(ctx.credValues.AccessKeyID, ctx.credValues.SecretAccessKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	ctx.sanitizeHostForHeader()
 	ctx.assignAmzQueryValues()
 	if err := ctx.build(v4.DisableHeaderHoisting); err != nil {
@@ -365,10 +393,10 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, service, regi
 	// the body the request was signed for attached.
 	if !(v4.DisableRequestBodyOverwrite || ctx.isPresign) {
 		var reader io.ReadCloser
-		if body != nil {
+		if ctx.Body != nil {
 			var ok bool
-			if reader, ok = body.(io.ReadCloser); !ok {
-				reader = io.NopCloser(body)
+			if reader, ok = ctx.Body.(io.ReadCloser); !ok {
+				reader = io.NopCloser(ctx.Body)
 			}
 		}
 		r.Body = reader
@@ -401,7 +429,7 @@ func (ctx *signingCtx) handlePresignRemoval() {
 
 func (ctx *signingCtx) assignAmzQueryValues() {
 	if ctx.isPresign {
-		ctx.Query.Set("X-Amz-Algorithm", authHeaderPrefix)
+		ctx.Query.Set("X-Amz-Algorithm", ctx.algorithm())
 		if ctx.credValues.SessionToken != "" {
 			ctx.Query.Set("X-Amz-Security-Token", ctx.credValues.SessionToken)
 		} else {
@@ -545,13 +573,17 @@ func (ctx *signingCtx) build(disableHeaderHoisting bool) error {
 	}
 	ctx.buildCanonicalString() // depends on canon headers / signed headers
 	ctx.buildStringToSign()    // depends on canon string
-	ctx.buildSignature()       // depends on string to sign
+
+	// depends on string to sign
+	if err := ctx.buildSignature(); err != nil {
+		return err
+	}
 
 	if ctx.isPresign {
 		ctx.Request.URL.RawQuery += "&" + signatureQueryKey + "=" + ctx.signature
 	} else {
 		parts := []string{
-			authHeaderPrefix + " Credential=" + ctx.credValues.AccessKeyID + "/" + ctx.credentialString,
+			ctx.algorithm() + " Credential=" + ctx.credValues.AccessKeyID + "/" + ctx.credentialString,
 			"SignedHeaders=" + ctx.signedHeaders,
 			authHeaderSignatureElem + ctx.signature,
 		}
@@ -591,12 +623,19 @@ func (ctx *signingCtx) buildTime() {
 		ctx.Query.Set("X-Amz-Date", formatTime(ctx.Time))
 		ctx.Query.Set("X-Amz-Expires", strconv.FormatInt(duration, 10))
 	} else {
+		if ctx.keepDateHeaders && (ctx.Request.Header.Get("X-Amz-Date") != "" || ctx.Request.Header.Get("Date") != "") {
+			return
+		}
 		ctx.Request.Header.Set("X-Amz-Date", formatTime(ctx.Time))
 	}
 }
 
 func (ctx *signingCtx) buildCredentialString() {
-	ctx.credentialString = buildSigningScope(ctx.Region, ctx.ServiceName, ctx.Time)
+	if ctx.signingKey != nil {
+		ctx.credentialString = buildAsymmetricSigningScope(ctx.ServiceName, ctx.Time)
+	} else {
+		ctx.credentialString = buildSigningScope(ctx.Region, ctx.ServiceName, ctx.Time)
+	}
 
 	if ctx.isPresign {
 		ctx.Query.Set(amzCredential, ctx.credValues.AccessKeyID+"/"+ctx.credentialString)
@@ -683,17 +722,22 @@ func (ctx *signingCtx) buildCanonicalString() {
 
 func (ctx *signingCtx) buildStringToSign() {
 	ctx.stringToSign = strings.Join([]string{
-		authHeaderPrefix,
+		ctx.algorithm(),
 		formatTime(ctx.Time),
 		ctx.credentialString,
 		hex.EncodeToString(hashSHA256([]byte(ctx.canonicalString))),
 	}, "\n")
 }
 
-func (ctx *signingCtx) buildSignature() {
+func (ctx *signingCtx) buildSignature() error {
+	if ctx.signingKey != nil {
+		return ctx.buildAsymmetricSignature()
+	}
+
 	creds := deriveSigningKey(ctx.Region, ctx.ServiceName, ctx.credValues.SecretAccessKey, ctx.Time)
 	signature := hmacSHA256(creds, []byte(ctx.stringToSign))
 	ctx.signature = hex.EncodeToString(signature)
+	return nil
 }
 
 func (ctx *signingCtx) buildBodyDigest() error {
@@ -0,0 +1,78 @@
+package v4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveAsymmetricKey(t *testing.T) {
+	// test vector of AWS SDKs
+	key, err := DeriveAsymmetricKey("AKISORANDOMAASORANDOM", "q+jcrXGc+0zWN6uzclKVhvMmUsIfRPa4rlRandom")
+	require.NoError(t, err)
+	require.Equal(t, "15d242ceebf8d8169fd6a8b5a746c41140414c3b07579038da06af89190fffcb", key.X.Text(16))
+	require.Equal(t, "515242cedd82e94799482e4c0514b505afccf2c0c98d6a553bf539f424c5ec0", key.Y.Text(16))
+}
+
+func TestVerifyAsymmetric(t *testing.T) {
+	signTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner(credentials.NewStaticCredentials("AKID", "secret", ""), func(s *Signer) {
+		s.Asymmetric = true
+		s.DisableURIPathEscaping = true
+	})
+
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost:8084/bucket/object", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Amz-Region-Set", "*")
+		return req
+	}
+
+	t.Run("header", func(t *testing.T) {
+		req := newRequest()
+		_, err := signer.Sign(req, nil, "s3", "", signTime)
+		require.NoError(t, err)
+
+		auth := req.Header.Get(authorizationHeader)
+		require.True(t, strings.HasPrefix(auth, AsymmetricAlgorithm+" Credential=AKID/20240301/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-region-set, Signature="))
+		signature := auth[strings.Index(auth, authHeaderSignatureElem)+len(authHeaderSignatureElem):]
+
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: []string{"host", "x-amz-content-sha256", "x-amz-date", "x-amz-region-set"},
+			Service:       "s3",
+			Time:          signTime,
+		}
+		require.NoError(t, signed.VerifyAsymmetric("AKID", "secret", signature))
+		require.ErrorIs(t, signed.VerifyAsymmetric("AKID", "secret", "00"), ErrAsymmetricSignatureMismatch)
+		require.ErrorIs(t, signed.VerifyAsymmetric("AKID", "another", signature), ErrAsymmetricSignatureMismatch)
+
+		req.Header.Set("X-Amz-Region-Set", "us-east-1")
+		require.ErrorIs(t, signed.VerifyAsymmetric("AKID", "secret", signature), ErrAsymmetricSignatureMismatch)
+	})
+
+	t.Run("presigned", func(t *testing.T) {
+		req := newRequest()
+		_, err := signer.Presign(req, nil, "s3", "", time.Hour, signTime)
+		require.NoError(t, err)
+
+		query := req.URL.Query()
+		require.Equal(t, AsymmetricAlgorithm, query.Get("X-Amz-Algorithm"))
+		require.Equal(t, "AKID/20240301/s3/aws4_request", query.Get(amzCredential))
+		require.Equal(t, "*", query.Get("X-Amz-Region-Set"))
+
+		req.Header = make(http.Header)
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: []string{"host"},
+			Service:       "s3",
+			Time:          signTime,
+			IsPresign:     true,
+		}
+		require.NoError(t, signed.VerifyAsymmetric("AKID", "secret", query.Get(signatureQueryKey)))
+	})
+}
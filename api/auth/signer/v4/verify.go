@@ -0,0 +1,168 @@
+package v4
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSignatureMismatch is returned by SignedRequest.Verify if the signature
+// doesn't match the request.
+var ErrSignatureMismatch = errors.New("SigV4 signature mismatch")
+
+const (
+	hostHeader          = "host"
+	contentLengthHeader = "content-length"
+)
+
+// SignedRequest is a received request with SigV4 or SigV4A signature. Its
+// canonical form is built from the headers the client lists as signed, the
+// request isn't modified and its body isn't read.
+type SignedRequest struct {
+	Request *http.Request
+	// SignedHeaders are lowercase names of signed headers in the order they
+	// are listed by the client.
+	SignedHeaders []string
+	// PayloadHash is a hex SHA-256 hash of the payload computed in advance.
+	// It's used if X-Amz-Content-Sha256 header isn't signed, the payload is
+	// considered empty if it's not set either (except for S3 presigned URLs
+	// that don't sign the payload).
+	PayloadHash string
+	Service     string
+	// Region is ignored by SigV4A signatures.
+	Region string
+	Time   time.Time
+	// IsPresign is set if the signature is in the query of the URL.
+	IsPresign bool
+}
+
+// Verify checks the SigV4 signature of the request created with the secret
+// key, ErrSignatureMismatch is returned if the signature is invalid.
+// Signatures are compared in constant time.
+func (s SignedRequest) Verify(secretKey, signature string) error {
+	if !s.signs(hostHeader) {
+		return ErrSignatureMismatch
+	}
+
+	key := deriveSigningKey(s.Region, s.Service, secretKey, s.Time)
+	stringToSign := s.stringToSign(authHeaderPrefix, buildSigningScope(s.Region, s.Service, s.Time))
+	expected := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
+
+// VerifyAsymmetric checks the SigV4A signature of the request created with
+// the key derived from the access key pair, ErrAsymmetricSignatureMismatch is
+// returned if the signature is invalid.
+func (s SignedRequest) VerifyAsymmetric(accessKeyID, secretKey, signature string) error {
+	if !s.signs(hostHeader) {
+		return ErrAsymmetricSignatureMismatch
+	}
+
+	key, err := DeriveAsymmetricKey(accessKeyID, secretKey)
+	if err != nil {
+		return err
+	}
+
+	stringToSign := s.stringToSign(AsymmetricAlgorithm, buildAsymmetricSigningScope(s.Service, s.Time))
+	sig, err := hex.DecodeString(signature)
+	if err != nil || !ecdsa.VerifyASN1(&key.PublicKey, hashSHA256([]byte(stringToSign)), sig) {
+		return ErrAsymmetricSignatureMismatch
+	}
+
+	return nil
+}
+
+func (s SignedRequest) signs(header string) bool {
+	for _, name := range s.SignedHeaders {
+		if name == header {
+			return true
+		}
+	}
+	return false
+}
+
+func (s SignedRequest) stringToSign(algorithm, scope string) string {
+	return strings.Join([]string{
+		algorithm,
+		formatTime(s.Time),
+		scope,
+		hex.EncodeToString(hashSHA256([]byte(s.canonicalString()))),
+	}, "\n")
+}
+
+func (s SignedRequest) canonicalString() string {
+	return strings.Join([]string{
+		s.Request.Method,
+		getURIPath(s.Request.URL),
+		s.canonicalQuery(),
+		s.canonicalHeaders() + "\n",
+		strings.Join(s.SignedHeaders, ";"),
+		s.payloadHash(),
+	}, "\n")
+}
+
+func (s SignedRequest) canonicalQuery() string {
+	query := make(url.Values)
+	for key, values := range s.Request.URL.Query() {
+		if s.IsPresign && key == signatureQueryKey {
+			continue
+		}
+		sort.Strings(values)
+		query[key] = values
+	}
+
+	return strings.Replace(query.Encode(), "+", "%20", -1)
+}
+
+func (s SignedRequest) canonicalHeaders() string {
+	headerValues := make([]string, len(s.SignedHeaders))
+	for i, name := range s.SignedHeaders {
+		if name == hostHeader {
+			host := s.Request.Host
+			if host == "" {
+				host = s.Request.URL.Host
+			}
+			headerValues[i] = name + ":" + host
+			continue
+		}
+		// Server moves Content-Length header of the incoming request to
+		// the ContentLength field.
+		if name == contentLengthHeader && s.Request.ContentLength >= 0 && len(s.Request.Header.Values(name)) == 0 {
+			headerValues[i] = name + ":" + strconv.FormatInt(s.Request.ContentLength, 10)
+			continue
+		}
+		headerValues[i] = name + ":" + strings.Join(s.Request.Header.Values(name), ",")
+	}
+	stripExcessSpaces(headerValues)
+
+	return strings.Join(headerValues, "\n")
+}
+
+func (s SignedRequest) payloadHash() string {
+	if s.signs("x-amz-content-sha256") {
+		if hash := s.Request.Header.Get("X-Amz-Content-Sha256"); hash != "" {
+			return hash
+		}
+	}
+
+	switch {
+	case s.IsPresign && s.Service == "s3":
+		return "UNSIGNED-PAYLOAD"
+	case s.PayloadHash != "":
+		return s.PayloadHash
+	default:
+		return emptyStringSHA256
+	}
+}
@@ -0,0 +1,139 @@
+package v4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	signTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner(credentials.NewStaticCredentials("AKID", "secret", ""), func(s *Signer) {
+		s.DisableURIPathEscaping = true
+	})
+
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest(http.MethodPut, "http://localhost:8084/bucket/dir/object%20name?tagging&versionId=1", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Amz-Meta-Key", "value  with   spaces")
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		return req
+	}
+
+	t.Run("header", func(t *testing.T) {
+		req := newRequest()
+		_, err := signer.Sign(req, nil, "s3", "us-east-1", signTime)
+		require.NoError(t, err)
+
+		auth := req.Header.Get(authorizationHeader)
+		signedHeaders := "host;x-amz-content-sha256;x-amz-date;x-amz-meta-key"
+		require.Contains(t, auth, "SignedHeaders="+signedHeaders+",")
+		signature := auth[strings.Index(auth, authHeaderSignatureElem)+len(authHeaderSignatureElem):]
+
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: strings.Split(signedHeaders, ";"),
+			Service:       "s3",
+			Region:        "us-east-1",
+			Time:          signTime,
+		}
+		require.NoError(t, signed.Verify("secret", signature))
+
+		// unsigned headers aren't verified
+		req.Header.Set("User-Agent", "aws-cli")
+		require.NoError(t, signed.Verify("secret", signature))
+
+		require.ErrorIs(t, signed.Verify("another", signature), ErrSignatureMismatch)
+		require.ErrorIs(t, signed.Verify("secret", strings.ToUpper(signature)), ErrSignatureMismatch)
+
+		other := signed
+		other.Region = "us-west-2"
+		require.ErrorIs(t, other.Verify("secret", signature), ErrSignatureMismatch)
+
+		other = signed
+		other.SignedHeaders = other.SignedHeaders[1:]
+		require.ErrorIs(t, other.Verify("secret", signature), ErrSignatureMismatch)
+
+		req.Header.Set("X-Amz-Meta-Key", "another")
+		require.ErrorIs(t, signed.Verify("secret", signature), ErrSignatureMismatch)
+	})
+
+	t.Run("content length", func(t *testing.T) {
+		req := newRequest()
+		req.Header.Set("Content-Length", "14")
+		_, err := signer.Sign(req, nil, "s3", "us-east-1", signTime)
+		require.NoError(t, err)
+
+		auth := req.Header.Get(authorizationHeader)
+		signedHeaders := "content-length;host;x-amz-content-sha256;x-amz-date;x-amz-meta-key"
+		require.Contains(t, auth, "SignedHeaders="+signedHeaders+",")
+		signature := auth[strings.Index(auth, authHeaderSignatureElem)+len(authHeaderSignatureElem):]
+
+		// the header is parsed into ContentLength by the server
+		req.Header.Del("Content-Length")
+		req.ContentLength = 14
+
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: strings.Split(signedHeaders, ";"),
+			Service:       "s3",
+			Region:        "us-east-1",
+			Time:          signTime,
+		}
+		require.NoError(t, signed.Verify("secret", signature))
+
+		req.ContentLength = 15
+		require.ErrorIs(t, signed.Verify("secret", signature), ErrSignatureMismatch)
+	})
+
+	t.Run("presigned", func(t *testing.T) {
+		req := newRequest()
+		req.Header.Del("X-Amz-Content-Sha256")
+		_, err := signer.Presign(req, nil, "s3", "us-east-1", time.Hour, signTime)
+		require.NoError(t, err)
+
+		signature := req.URL.Query().Get(signatureQueryKey)
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: strings.Split(req.URL.Query().Get("X-Amz-SignedHeaders"), ";"),
+			Service:       "s3",
+			Region:        "us-east-1",
+			Time:          signTime,
+			IsPresign:     true,
+		}
+		require.NoError(t, signed.Verify("secret", signature))
+
+		req.URL.RawQuery += "&partNumber=1"
+		require.ErrorIs(t, signed.Verify("secret", signature), ErrSignatureMismatch)
+	})
+
+	t.Run("payload", func(t *testing.T) {
+		payload := []byte("Action=AssumeRole&Version=2011-06-15")
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8084/", nil)
+		require.NoError(t, err)
+		_, err = signer.Sign(req, bytes.NewReader(payload), "sts", "us-east-1", signTime)
+		require.NoError(t, err)
+
+		auth := req.Header.Get(authorizationHeader)
+		signature := auth[strings.Index(auth, authHeaderSignatureElem)+len(authHeaderSignatureElem):]
+
+		signed := SignedRequest{
+			Request:       req,
+			SignedHeaders: []string{"host", "x-amz-date"},
+			PayloadHash:   hex.EncodeToString(hashSHA256(payload)),
+			Service:       "sts",
+			Region:        "us-east-1",
+			Time:          signTime,
+		}
+		require.NoError(t, signed.Verify("secret", signature))
+
+		signed.PayloadHash = ""
+		require.ErrorIs(t, signed.Verify("secret", signature), ErrSignatureMismatch)
+	})
+}
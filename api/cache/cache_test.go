@@ -47,6 +47,16 @@ func TestBucketsCacheType(t *testing.T) {
 	assertInvalidCacheEntry(t, cache.Get(bktInfo.Name), observedLog)
 }
 
+func TestCacheStats(t *testing.T) {
+	cache := NewBucketCache(DefaultBucketConfig(zap.NewNop()))
+	require.Equal(t, Stats{}, cache.Stats())
+
+	require.NoError(t, cache.Put(&data.BucketInfo{Name: "bucket"}))
+	require.NotNil(t, cache.Get("bucket"))
+	require.Nil(t, cache.Get("unknown"))
+	require.Equal(t, Stats{Entries: 1, Hits: 1, Misses: 1}, cache.Stats())
+}
+
 func TestObjectNamesCacheType(t *testing.T) {
 	logger, observedLog := getObservedLogger()
 	cache := NewObjectsNameCache(DefaultObjectsNameConfig(logger))
@@ -194,6 +204,44 @@ func TestNotificationConfigurationCacheType(t *testing.T) {
 	assertInvalidCacheEntry(t, cache.GetNotificationConfiguration(key), observedLog)
 }
 
+func TestTransformedCacheLimits(t *testing.T) {
+	cfg := DefaultTransformedConfig(zap.NewNop())
+	cfg.Size = 3
+	cfg.MaxBytes = 10
+	cache := NewTransformedCache(cfg)
+
+	obj := func(size int) *data.TransformedObject {
+		return &data.TransformedObject{Payload: make([]byte, size)}
+	}
+
+	cache.Put("a", obj(4))
+	cache.Put("b", obj(4))
+	require.NotNil(t, cache.Get("a"))
+
+	// the least recently used entry is evicted to fit the size limit
+	cache.Put("c", obj(4))
+	require.Nil(t, cache.Get("b"))
+	require.NotNil(t, cache.Get("a"))
+	require.NotNil(t, cache.Get("c"))
+	require.EqualValues(t, 8, cache.bytes)
+
+	// objects larger than the limit aren't cached
+	cache.Put("d", obj(11))
+	require.Nil(t, cache.Get("d"))
+	require.NotNil(t, cache.Get("a"))
+
+	// the replaced entry is accounted once
+	cache.Put("a", obj(2))
+	require.EqualValues(t, 6, cache.bytes)
+
+	// and the number of entries is limited too
+	cache.Put("e", obj(1))
+	cache.Put("f", obj(1))
+	require.Equal(t, 3, cache.lru.Len())
+	require.Nil(t, cache.Get("c"))
+	require.EqualValues(t, 4, cache.bytes)
+}
+
 func assertInvalidCacheEntry(t *testing.T, val any, observedLog *observer.ObservedLogs) {
 	require.Nil(t, val)
 	require.Equal(t, 1, observedLog.Len())
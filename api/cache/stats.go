@@ -0,0 +1,36 @@
+package cache
+
+import "github.com/bluele/gcache"
+
+// Stats describes the cache usage since the gateway start.
+type Stats struct {
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+func statsOf(c gcache.Cache) Stats {
+	return Stats{
+		Entries: c.Len(true),
+		Hits:    c.HitCount(),
+		Misses:  c.MissCount(),
+	}
+}
+
+// Stats returns the cache usage statistics.
+func (o *AccessControlCache) Stats() Stats { return statsOf(o.cache) }
+
+// Stats returns the cache usage statistics.
+func (o *BucketCache) Stats() Stats { return statsOf(o.cache) }
+
+// Stats returns the cache usage statistics.
+func (o *ObjectsCache) Stats() Stats { return statsOf(o.cache) }
+
+// Stats returns the cache usage statistics.
+func (o *ObjectsListCache) Stats() Stats { return statsOf(o.cache) }
+
+// Stats returns the cache usage statistics.
+func (o *ObjectsNameCache) Stats() Stats { return statsOf(o.cache) }
+
+// Stats returns the cache usage statistics.
+func (o *SystemCache) Stats() Stats { return statsOf(o.cache) }
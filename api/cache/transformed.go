@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"go.uber.org/zap"
+)
+
+type (
+	// TransformedCache provides lru cache for objects derived by GET transformations.
+	// Key is object address + transformation specification. The cache is
+	// limited by both the number of entries and the total size of payloads.
+	TransformedCache struct {
+		size     int
+		maxBytes int64
+		lifetime time.Duration
+
+		mu    sync.Mutex
+		bytes int64
+		items map[string]*list.Element
+		lru   *list.List
+	}
+
+	// TransformedConfig contains parameters of the transformed objects cache.
+	TransformedConfig struct {
+		Config
+		// MaxBytes limits the total size of cached payloads.
+		MaxBytes int64
+	}
+
+	transformedEntry struct {
+		key     string
+		obj     *data.TransformedObject
+		expires time.Time
+	}
+)
+
+const (
+	// DefaultTransformedCacheSize is a default maximum number of entries in cache.
+	DefaultTransformedCacheSize = 1000
+	// DefaultTransformedCacheLifetime is a default lifetime of entries in cache.
+	DefaultTransformedCacheLifetime = 10 * time.Minute
+	// DefaultTransformedCacheMaxBytes is a default limit of the total size of
+	// payloads in cache.
+	DefaultTransformedCacheMaxBytes = 256 << 20
+)
+
+// DefaultTransformedConfig returns new default cache expiration values.
+func DefaultTransformedConfig(logger *zap.Logger) *TransformedConfig {
+	return &TransformedConfig{
+		Config: Config{
+			Size:     DefaultTransformedCacheSize,
+			Lifetime: DefaultTransformedCacheLifetime,
+			Logger:   logger,
+		},
+		MaxBytes: DefaultTransformedCacheMaxBytes,
+	}
+}
+
+// NewTransformedCache creates an object of TransformedCache.
+func NewTransformedCache(config *TransformedConfig) *TransformedCache {
+	return &TransformedCache{
+		size:     config.Size,
+		maxBytes: config.MaxBytes,
+		lifetime: config.Lifetime,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Get returns a cached transformed object.
+func (o *TransformedCache) Get(key string) *data.TransformedObject {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	el, ok := o.items[key]
+	if !ok {
+		return nil
+	}
+
+	entry := el.Value.(*transformedEntry)
+	if time.Now().After(entry.expires) {
+		o.remove(el)
+		return nil
+	}
+	o.lru.MoveToFront(el)
+
+	return entry.obj
+}
+
+// Put puts a transformed object to cache. Objects larger than the size limit
+// of the cache aren't cached, the least recently used ones are evicted to fit
+// the new one.
+func (o *TransformedCache) Put(key string, obj *data.TransformedObject) {
+	size := int64(len(obj.Payload))
+	if size > o.maxBytes {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if el, ok := o.items[key]; ok {
+		o.remove(el)
+	}
+
+	for o.lru.Len() > 0 && (o.lru.Len() >= o.size || o.bytes+size > o.maxBytes) {
+		o.remove(o.lru.Back())
+	}
+
+	o.items[key] = o.lru.PushFront(&transformedEntry{key: key, obj: obj, expires: time.Now().Add(o.lifetime)})
+	o.bytes += size
+}
+
+func (o *TransformedCache) remove(el *list.Element) {
+	entry := o.lru.Remove(el).(*transformedEntry)
+	delete(o.items, entry.key)
+	o.bytes -= int64(len(entry.obj.Payload))
+}
@@ -19,6 +19,17 @@ const (
 	VersioningUnversioned = "Unversioned"
 	VersioningEnabled     = "Enabled"
 	VersioningSuspended   = "Suspended"
+
+	// DeletionTombstone mode removes payloads of deleted versions at once.
+	DeletionTombstone = "Tombstone"
+	// DeletionSoft mode keeps payloads of deleted versions for the undelete
+	// window.
+	DeletionSoft = "Soft"
+
+	// SettingsSchemaVersion is the latest version of the bucket settings
+	// format supported by the gateway. Settings of newer versions can't be
+	// changed, since unknown fields would be lost.
+	SettingsSchemaVersion = 2
 )
 
 type (
@@ -50,6 +61,13 @@ type (
 		Headers     map[string]string
 	}
 
+	// TransformedObject is an object payload derived by GET transformations.
+	TransformedObject struct {
+		Payload     []byte
+		ContentType string
+		HashSum     string
+	}
+
 	// NotificationInfo store info to send s3 notification.
 	NotificationInfo struct {
 		Name    string
@@ -62,6 +80,50 @@ type (
 	BucketSettings struct {
 		Versioning        string                   `json:"versioning"`
 		LockConfiguration *ObjectLockConfiguration `json:"lock_configuration"`
+		Compression       string                   `json:"compression"`
+		Deduplication     bool                     `json:"deduplication"`
+		UploadConstraints *UploadConstraints       `json:"upload_constraints"`
+		AnonymousAccess   *AnonymousAccess         `json:"anonymous_access"`
+		Deletion          *DeletionConfiguration   `json:"deletion"`
+		Privacy           *PrivacyConfiguration    `json:"privacy"`
+		// SchemaVersion is a version of the settings format, zero for
+		// settings written before versioning of the format.
+		SchemaVersion int `json:"schema_version"`
+		// Revision is incremented on every update of the settings, updates
+		// based on outdated revisions are rejected.
+		Revision uint64 `json:"revision"`
+	}
+
+	// UploadConstraints limits objects uploaded to a bucket. Content types
+	// are matched by type and subtype, subtype may be "*".
+	UploadConstraints struct {
+		XMLName             xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ UploadConstraints" json:"-"`
+		AllowedContentTypes []string `xml:"AllowedContentType" json:"AllowedContentTypes,omitempty"`
+		DeniedContentTypes  []string `xml:"DeniedContentType" json:"DeniedContentTypes,omitempty"`
+		MaxObjectSize       int64    `xml:"MaxObjectSize,omitempty" json:"MaxObjectSize,omitempty"`
+	}
+
+	// AnonymousAccess restricts anonymous requests to a bucket on the gateway
+	// side in addition to the bucket ACL.
+	AnonymousAccess struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ AnonymousAccessConfiguration" json:"-"`
+		Mode    string   `xml:"Mode" json:"Mode"`
+	}
+
+	// DeletionConfiguration defines how payloads of deleted object versions
+	// are removed. In the Soft mode payloads are kept for the undelete window
+	// and are tombstoned by the gateway after it.
+	DeletionConfiguration struct {
+		XMLName            xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeletionConfiguration" json:"-"`
+		Mode               string   `xml:"Mode" json:"Mode"`
+		UndeleteWindowDays int      `xml:"UndeleteWindowDays,omitempty" json:"UndeleteWindowDays,omitempty"`
+	}
+
+	// PrivacyConfiguration defines how identities of owners are shown in
+	// listings and ACL of a bucket to requesters other than the bucket owner.
+	PrivacyConfiguration struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ PrivacyConfiguration" json:"-"`
+		Mode    string   `xml:"Mode" json:"Mode"`
 	}
 
 	// CORSConfiguration stores CORS configuration of a request.
@@ -132,3 +194,53 @@ func (b BucketSettings) VersioningEnabled() bool {
 func (b BucketSettings) VersioningSuspended() bool {
 	return b.Versioning == VersioningSuspended
 }
+
+// CompressionEnabled checks if new objects payloads are compressed.
+func (b BucketSettings) CompressionEnabled() bool {
+	return b.Compression != ""
+}
+
+// DeduplicationEnabled checks if identical payloads are stored once.
+func (b BucketSettings) DeduplicationEnabled() bool {
+	return b.Deduplication
+}
+
+// SoftDeletionEnabled checks if payloads of deleted versions are kept for
+// the undelete window.
+func (b BucketSettings) SoftDeletionEnabled() bool {
+	return b.Deletion != nil && b.Deletion.Mode == DeletionSoft
+}
+
+// UndeleteWindow returns the period deleted versions can be restored in.
+func (b BucketSettings) UndeleteWindow() time.Duration {
+	if !b.SoftDeletionEnabled() {
+		return 0
+	}
+	return time.Duration(b.Deletion.UndeleteWindowDays) * 24 * time.Hour
+}
+
+// SchemaSupported checks if the settings format is known to the gateway, so
+// the settings can be changed.
+func (b BucketSettings) SchemaSupported() bool {
+	return b.SchemaVersion <= SettingsSchemaVersion
+}
+
+// BucketUsage contains storage statistics of a bucket.
+type BucketUsage struct {
+	// Objects is the number of objects which latest version is not a delete marker.
+	Objects uint64
+	// Versions is the number of stored object versions, delete markers excluded.
+	Versions uint64
+	// Bytes is the total payload size of all stored versions.
+	Bytes uint64
+}
+
+// DeduplicatedPayload describes a payload shared by objects of a bucket.
+type DeduplicatedPayload struct {
+	// Hash is the hex-encoded SHA-256 of the stored payload.
+	Hash string
+	// OID is the object storing the payload.
+	OID oid.ID
+	// Refs is the number of object versions using the payload.
+	Refs uint64
+}
@@ -58,6 +58,14 @@ type BaseNodeVersion struct {
 	FilePath  string
 }
 
+// TrashedVersion is an object version deleted in a bucket with soft deletion,
+// its payload is kept until the undelete window passes.
+type TrashedVersion struct {
+	BaseNodeVersion
+	IsUnversioned bool
+	Deleted       time.Time
+}
+
 type ObjectTaggingInfo struct {
 	CnrID     cid.ID
 	ObjName   string
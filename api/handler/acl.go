@@ -185,7 +185,16 @@ func (h *handler) GetBucketACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, h.encodeBucketACL(bktInfo.Name, bucketACL)); err != nil {
+	privacy, err := h.ownerPrivacy(r, bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	acp := h.encodeBucketACL(bktInfo.Name, bucketACL)
+	privacy.acl(acp)
+
+	if err = api.EncodeToResponse(w, acp); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 		return
 	}
@@ -244,15 +253,15 @@ func (h *handler) PutBucketACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err = h.updateBucketACL(r, astBucket, bktInfo, token); err != nil {
+	if _, err = h.updateBucketACL(r.Context(), astBucket, bktInfo, token); err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *handler) updateBucketACL(r *http.Request, astChild *ast, bktInfo *data.BucketInfo, sessionToken *session.Container) (bool, error) {
-	bucketACL, err := h.obj.GetBucketACL(r.Context(), bktInfo)
+func (h *handler) updateBucketACL(ctx context.Context, astChild *ast, bktInfo *data.BucketInfo, sessionToken *session.Container) (bool, error) {
+	bucketACL, err := h.obj.GetBucketACL(ctx, bktInfo)
 	if err != nil {
 		return false, fmt.Errorf("could not get bucket eacl: %w", err)
 	}
@@ -282,7 +291,7 @@ func (h *handler) updateBucketACL(r *http.Request, astChild *ast, bktInfo *data.
 		SessionToken: sessionToken,
 	}
 
-	if err = h.obj.PutBucketACL(r.Context(), p); err != nil {
+	if err = h.obj.PutBucketACL(ctx, p); err != nil {
 		return false, fmt.Errorf("could not put bucket acl: %w", err)
 	}
 
@@ -316,7 +325,16 @@ func (h *handler) GetObjectACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeObjectACL(h.log, bucketACL, reqInfo.BucketName, objInfo.VersionID())); err != nil {
+	privacy, err := h.ownerPrivacy(r, bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	acp := encodeObjectACL(h.log, bucketACL, reqInfo.BucketName, objInfo.VersionID())
+	privacy.acl(acp)
+
+	if err = api.EncodeToResponse(w, acp); err != nil {
 		h.logAndSendError(w, "failed to encode response", reqInfo, err)
 	}
 }
@@ -378,7 +396,7 @@ func (h *handler) PutObjectACLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updated, err := h.updateBucketACL(r, astObject, bktInfo, token)
+	updated, err := h.updateBucketACL(r.Context(), astObject, bktInfo, token)
 	if err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
@@ -462,7 +480,7 @@ func (h *handler) PutBucketPolicyHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if _, err = h.updateBucketACL(r, astPolicy, bktInfo, token); err != nil {
+	if _, err = h.updateBucketACL(r.Context(), astPolicy, bktInfo, token); err != nil {
 		h.logAndSendError(w, "could not update bucket acl", reqInfo, err)
 		return
 	}
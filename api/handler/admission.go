@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"go.uber.org/zap"
+)
+
+type (
+	// Admitter validates uploads before the payload is accepted, it gets
+	// the object metadata only.
+	Admitter interface {
+		Admit(ctx context.Context, req AdmissionRequest) (*AdmissionVerdict, error)
+	}
+
+	// AdmissionRequest describes the upload being validated.
+	AdmissionRequest struct {
+		// Operation is an S3 operation name: PutObject, PostObject,
+		// CopyObject or CreateMultipartUpload.
+		Operation string `json:"operation"`
+		Bucket    string `json:"bucket"`
+		Key       string `json:"key"`
+		// Size is -1 if it's unknown before the upload (multipart uploads,
+		// chunked transfer encoding).
+		Size        int64             `json:"size"`
+		ContentType string            `json:"content_type,omitempty"`
+		Metadata    map[string]string `json:"metadata,omitempty"`
+		// Owner is the user ID of the requester, it's empty for anonymous
+		// requests.
+		Owner string `json:"owner,omitempty"`
+	}
+
+	// AdmissionVerdict is a decision of the Admitter.
+	AdmissionVerdict struct {
+		Allowed bool
+		// Reason explains the rejection, it's returned to the client.
+		Reason string
+	}
+)
+
+// admitUpload asks the admitter of the bucket whether the upload is accepted.
+// Buckets without admitters accept all uploads.
+func (h *handler) admitUpload(ctx context.Context, bktInfo *data.BucketInfo, key string, size int64, metadata map[string]string) error {
+	admitter, ok := h.cfg.Admission[bktInfo.Name]
+	if !ok {
+		return nil
+	}
+
+	req := AdmissionRequest{
+		Operation:   api.GetReqInfo(ctx).API,
+		Bucket:      bktInfo.Name,
+		Key:         key,
+		Size:        size,
+		ContentType: metadata[api.ContentType],
+		Metadata:    metadata,
+	}
+	if box, err := layer.GetBoxData(ctx); err == nil && box.Gate.BearerToken != nil {
+		req.Owner = box.Gate.BearerToken.ResolveIssuer().EncodeToString()
+	}
+
+	verdict, err := admitter.Admit(ctx, req)
+	if err != nil {
+		if h.cfg.AdmissionFailOpen {
+			h.log.Warn("couldn't validate upload, it's accepted", zap.String("bucket", bktInfo.Name),
+				zap.String("object", key), zap.Error(err))
+			return nil
+		}
+		return fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrAdmissionUnavailable), err.Error())
+	}
+
+	if verdict.Allowed {
+		return nil
+	}
+
+	h.log.Info("upload is rejected by admission webhook", zap.String("bucket", bktInfo.Name),
+		zap.String("object", key), zap.String("reason", verdict.Reason),
+		zap.String("reqId", api.GetReqInfo(ctx).RequestID))
+
+	if verdict.Reason == "" {
+		return s3errors.GetAPIError(s3errors.ErrUploadNotAdmitted)
+	}
+	return s3errors.GetAPIErrorWithError(s3errors.ErrUploadNotAdmitted, errors.New(verdict.Reason))
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+type admitterMock struct {
+	err      error
+	requests []AdmissionRequest
+}
+
+func (a *admitterMock) Admit(_ context.Context, req AdmissionRequest) (*AdmissionVerdict, error) {
+	a.requests = append(a.requests, req)
+
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	if req.Metadata["schema"] != "v2" {
+		return &AdmissionVerdict{Reason: "schema v2 is required"}, nil
+	}
+
+	return &AdmissionVerdict{Allowed: true}, nil
+}
+
+func TestAdmission(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	admitter := &admitterMock{}
+
+	bktName, otherBktName, objName := "bucket-for-admission", "bucket-without-admission", "object"
+	createTestBucket(hc, bktName)
+	createTestBucket(hc, otherBktName)
+	hc.h.cfg.Admission = map[string]Admitter{bktName: admitter}
+
+	putObject(t, hc, otherBktName, objName)
+	require.Empty(t, admitter.requests)
+
+	w, r := prepareTestPayloadRequest(hc, bktName, objName, bytes.NewReader([]byte("content")))
+	r.Header.Set(api.ContentType, "application/json")
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIErrorWithError(s3errors.ErrUploadNotAdmitted, errors.New("schema v2 is required")))
+	checkNotFound(t, hc, bktName, objName, emptyVersion)
+
+	require.Len(t, admitter.requests, 1)
+	require.Equal(t, bktName, admitter.requests[0].Bucket)
+	require.Equal(t, objName, admitter.requests[0].Key)
+	require.Equal(t, int64(len("content")), admitter.requests[0].Size)
+	require.Equal(t, "application/json", admitter.requests[0].ContentType)
+
+	w, r = prepareTestPayloadRequest(hc, bktName, objName, bytes.NewReader([]byte("content")))
+	r.Header.Set(api.MetadataPrefix+"Schema", "v2")
+	hc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	checkFound(t, hc, bktName, objName, emptyVersion)
+
+	w, r = prepareTestRequest(hc, bktName, objName+"-multipart", nil)
+	hc.Handler().CreateMultipartUploadHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIErrorWithError(s3errors.ErrUploadNotAdmitted, errors.New("schema v2 is required")))
+	require.Equal(t, int64(-1), admitter.requests[len(admitter.requests)-1].Size)
+
+	admitter.err = errors.New("webhook is down")
+	w, r = prepareTestPayloadRequest(hc, bktName, objName+"-unvalidated", bytes.NewReader([]byte("content")))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrAdmissionUnavailable))
+	checkNotFound(t, hc, bktName, objName+"-unvalidated", emptyVersion)
+
+	hc.h.cfg.AdmissionFailOpen = true
+	putObject(t, hc, bktName, objName+"-unvalidated")
+	checkFound(t, hc, bktName, objName+"-unvalidated", emptyVersion)
+}
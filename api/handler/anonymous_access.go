@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// AnonymousReadWithoutListing is a mode of anonymous access allowing reads of
+// known keys, but denying listing of the bucket.
+const AnonymousReadWithoutListing = "ReadWithoutListing"
+
+func (h *handler) PutBucketAnonymousAccessHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	access := &data.AnonymousAccess{}
+	if err = xml.NewDecoder(r.Body).Decode(access); err != nil {
+		h.logAndSendError(w, "couldn't parse anonymous access configuration", reqInfo, s3errors.GetAPIError(s3errors.ErrMalformedXML))
+		return
+	}
+
+	if access.Mode != AnonymousReadWithoutListing {
+		h.logAndSendError(w, "invalid anonymous access mode", reqInfo,
+			fmt.Errorf("%w: unknown mode '%s'", s3errors.GetAPIError(s3errors.ErrInvalidArgument), access.Mode))
+		return
+	}
+
+	if err = h.updateAnonymousAccess(r, bktInfo, access); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+}
+
+func (h *handler) GetBucketAnonymousAccessHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get bucket settings", reqInfo, err)
+		return
+	}
+
+	if settings.AnonymousAccess == nil {
+		h.logAndSendError(w, "anonymous access configuration not found", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+		return
+	}
+
+	if err = api.EncodeToResponse(w, settings.AnonymousAccess); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) DeleteBucketAnonymousAccessHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = h.updateAnonymousAccess(r, bktInfo, nil); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CheckAnonymousListing returns AccessDenied error if anonymous listing of the
+// requested bucket is disabled. Missing buckets are left to the handler, but
+// listing is denied if settings of the bucket can't be fetched.
+func (h *handler) CheckAnonymousListing(r *http.Request) error {
+	reqInfo := api.GetReqInfo(r.Context())
+	if reqInfo.BucketName == "" {
+		return nil
+	}
+
+	bktInfo, err := h.obj.GetBucketInfo(r.Context(), reqInfo.BucketName)
+	if err != nil {
+		return nil
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	if settings.AnonymousAccess != nil && settings.AnonymousAccess.Mode == AnonymousReadWithoutListing {
+		return s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	return nil
+}
+
+func (h *handler) updateAnonymousAccess(r *http.Request, bktInfo *data.BucketInfo, access *data.AnonymousAccess) error {
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return err
+	}
+
+	// settings pointer is stored in the cache, so modify a copy of the settings
+	newSettings := *settings
+	newSettings.AnonymousAccess = access
+
+	return h.obj.PutBucketSettings(r.Context(), &layer.PutSettingsParams{
+		BktInfo:  bktInfo,
+		Settings: &newSettings,
+	})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketAnonymousAccess(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-anonymous-access"
+	createTestBucket(hc, bktName)
+
+	query := make(url.Values)
+	query.Set("anonymous-access", "")
+
+	checkListing := func() error {
+		_, r := prepareTestRequest(hc, bktName, "", nil)
+		r = r.WithContext(context.WithValue(r.Context(), api.AnonymousRequest, true))
+		return hc.Handler().CheckAnonymousListing(r)
+	}
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketAnonymousAccessHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+	require.NoError(t, checkListing())
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, &data.AnonymousAccess{Mode: "Unknown"})
+	hc.Handler().PutBucketAnonymousAccessHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, &data.AnonymousAccess{Mode: AnonymousReadWithoutListing})
+	hc.Handler().PutBucketAnonymousAccessHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketAnonymousAccessHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	actual := &data.AnonymousAccess{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(actual))
+	require.Equal(t, AnonymousReadWithoutListing, actual.Mode)
+
+	require.ErrorIs(t, checkListing(), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().DeleteBucketAnonymousAccessHandler(w, r)
+	assertStatus(t, w, http.StatusNoContent)
+	require.NoError(t, checkListing())
+}
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/transform"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"go.uber.org/zap"
 )
@@ -16,6 +18,7 @@ type (
 		obj         layer.Client
 		notificator Notificator
 		cfg         *Config
+		scanSem     chan struct{}
 	}
 
 	Notificator interface {
@@ -30,6 +33,40 @@ type (
 		NotificatorEnabled  bool
 		CopiesNumber        uint32
 		MaxDeletePerRequest int
+		// Scanner inspects uploaded payloads if set, ScanMode is either
+		// ScanModeSync or ScanModeAsync, objects larger than ScanMaxSize
+		// (if positive) aren't scanned. Uploads are rejected in sync mode if
+		// Scanner fails unless ScanFailOpen is set.
+		Scanner      Scanner
+		ScanMode     string
+		ScanMaxSize  int64
+		ScanFailOpen bool
+		// Admission contains admitters of uploads by bucket names, uploads
+		// are rejected if admitters fail unless AdmissionFailOpen is set.
+		Admission         map[string]Admitter
+		AdmissionFailOpen bool
+		// Transform derives object content on GET requests with x-transform
+		// query parameter, they're rejected if it's nil.
+		Transform *transform.Pipeline
+		// ListingExport allows bucket owners to export listings as NDJSON.
+		ListingExport bool
+		// ObjectSearch allows bucket owners to search objects with NeoFS
+		// object search.
+		ObjectSearch bool
+		// GzipUploads makes PutObject decompress payloads with gzip
+		// Content-Encoding.
+		GzipUploads bool
+		// GzipMaxBufferedSize limits decompressed payloads of unknown size,
+		// they are buffered in memory to get their size.
+		GzipMaxBufferedSize int64
+		// BatchJobs keeps batch jobs of bucket owners, batch operations are
+		// disabled if it's nil.
+		BatchJobs *BatchJobs
+		// PrivacySalt is a secret key of owner pseudonyms in buckets with
+		// privacy configuration.
+		PrivacySalt []byte
+		// Unsupported contains modes of unsupported operations.
+		Unsupported UnsupportedOperations
 	}
 
 	PlacementPolicy interface {
@@ -62,10 +99,15 @@ func New(log *zap.Logger, obj layer.Client, notificator Notificator, cfg *Config
 		return nil, errors.New("empty notificator")
 	}
 
+	if cfg.Scanner != nil && cfg.ScanMode != ScanModeSync && cfg.ScanMode != ScanModeAsync {
+		return nil, fmt.Errorf("invalid scan mode '%s'", cfg.ScanMode)
+	}
+
 	return &handler{
 		log:         log,
 		obj:         obj,
 		cfg:         cfg,
 		notificator: notificator,
+		scanSem:     make(chan struct{}, maxAsyncScans),
 	}, nil
 }
@@ -0,0 +1,766 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accesspolicy"
+	"go.uber.org/zap"
+)
+
+// Batch job operations.
+const (
+	BatchOperationCopy   = "Copy"
+	BatchOperationTag    = "Tag"
+	BatchOperationACL    = "Acl"
+	BatchOperationDelete = "Delete"
+)
+
+// Batch job statuses.
+const (
+	BatchJobNew      = "New"
+	BatchJobActive   = "Active"
+	BatchJobComplete = "Complete"
+	BatchJobFailed   = "Failed"
+)
+
+const (
+	// DefaultBatchJobsLimit is a default number of batch jobs kept by the
+	// gateway.
+	DefaultBatchJobsLimit = 100
+
+	// DefaultBatchReportPrefix is a default prefix of batch job reports.
+	DefaultBatchReportPrefix = "batch-reports/"
+
+	maxBatchManifestSize = 16 << 20
+)
+
+type (
+	// BatchJobs keeps batch jobs submitted by bucket owners, pending jobs are
+	// processed one by one by Run. Jobs are kept in memory of the gateway
+	// they're submitted to, reports of finished jobs are stored in their
+	// buckets.
+	BatchJobs struct {
+		limit int
+
+		mu   sync.Mutex
+		jobs []*batchJob
+	}
+
+	// BatchJobRequest is a body of CreateBatchJob request.
+	BatchJobRequest struct {
+		XMLName   xml.Name `xml:"BatchJob"`
+		Operation string
+		// Manifest is a key of the CSV manifest in the bucket, rows contain
+		// the bucket, URL-encoded key and optional version ID.
+		Manifest string
+		// TagSet replaces tags of objects by Tag operation.
+		TagSet []Tag `xml:"TagSet>Tag"`
+		// ACL is a canned ACL set by Acl operation.
+		ACL string `xml:"ACL"`
+		// TargetBucket and TargetPrefix of objects copied by Copy operation.
+		TargetBucket string
+		TargetPrefix string
+		ReportPrefix string
+		// DryRun makes Delete operation only check objects exist, the report
+		// lists objects which would be deleted as succeeded.
+		DryRun bool
+	}
+
+	// BatchJobDescription is a response of CreateBatchJob and GetBatchJob
+	// requests.
+	BatchJobDescription struct {
+		XMLName          xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ BatchJob"`
+		JobID            string   `xml:"JobId"`
+		Operation        string
+		Status           string
+		Manifest         string
+		DryRun           bool `xml:",omitempty"`
+		TotalObjects     int
+		SucceededObjects int
+		FailedObjects    int
+		Report           string `xml:",omitempty"`
+		FailureReason    string `xml:",omitempty"`
+		CreationTime     string
+		CompletionTime   string `xml:",omitempty"`
+	}
+
+	// ListBatchJobsResult is a response of GetBatchJob request without a job
+	// ID.
+	ListBatchJobsResult struct {
+		XMLName xml.Name              `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBatchJobsResult"`
+		Jobs    []BatchJobDescription `xml:"BatchJob"`
+	}
+
+	batchJob struct {
+		id        string
+		bucket    string
+		operation string
+		manifest  string
+		dryRun    bool
+		created   time.Time
+		entries   []batchManifestEntry
+		// process applies the operation to the object.
+		process func(context.Context, batchManifestEntry) error
+		// report stores results of the entries and returns the report key.
+		report func(context.Context, []error) (string, error)
+
+		mu        sync.Mutex
+		status    string
+		succeeded int
+		failed    int
+		reportKey string
+		failure   string
+		completed time.Time
+	}
+
+	batchManifestEntry struct {
+		Bucket    string
+		Key       string
+		VersionID string
+	}
+)
+
+// NewBatchJobs creates the storage of batch jobs keeping the given number of
+// jobs, the oldest finished ones are evicted first.
+func NewBatchJobs(limit int) *BatchJobs {
+	if limit <= 0 {
+		limit = DefaultBatchJobsLimit
+	}
+
+	return &BatchJobs{limit: limit}
+}
+
+// Run processes pending jobs, objects are processed by functions started with
+// spawn, e.g. jobs.Task.Go. It returns the error of spawn.
+func (b *BatchJobs) Run(ctx context.Context, spawn func(context.Context, func(context.Context) error) error) error {
+	for {
+		j := b.next()
+		if j == nil {
+			return nil
+		}
+
+		if err := j.run(ctx, spawn); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *BatchJobs) add(j *batchJob) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.jobs) >= b.limit {
+		for i, job := range b.jobs {
+			if job.finished() {
+				b.jobs = append(b.jobs[:i], b.jobs[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(b.jobs) >= b.limit {
+		return s3errors.GetAPIError(s3errors.ErrTooManyBatchJobs)
+	}
+
+	b.jobs = append(b.jobs, j)
+	return nil
+}
+
+// next marks the first pending job active and returns it.
+func (b *BatchJobs) next() *batchJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, j := range b.jobs {
+		j.mu.Lock()
+		pending := j.status == BatchJobNew
+		if pending {
+			j.status = BatchJobActive
+		}
+		j.mu.Unlock()
+
+		if pending {
+			return j
+		}
+	}
+
+	return nil
+}
+
+func (b *BatchJobs) find(bucket, id string) *batchJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, j := range b.jobs {
+		if j.bucket == bucket && j.id == id {
+			return j
+		}
+	}
+
+	return nil
+}
+
+func (b *BatchJobs) list(bucket string) []BatchJobDescription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var res []BatchJobDescription
+	for _, j := range b.jobs {
+		if j.bucket == bucket {
+			res = append(res, j.description())
+		}
+	}
+
+	return res
+}
+
+func (j *batchJob) run(ctx context.Context, spawn func(context.Context, func(context.Context) error) error) error {
+	var (
+		wg      sync.WaitGroup
+		results = make([]error, len(j.entries))
+	)
+
+	for i := range j.entries {
+		i := i
+		wg.Add(1)
+		err := spawn(ctx, func(ctx context.Context) error {
+			defer wg.Done()
+
+			results[i] = j.process(ctx, j.entries[i])
+
+			j.mu.Lock()
+			if results[i] != nil {
+				j.failed++
+			} else {
+				j.succeeded++
+			}
+			j.mu.Unlock()
+
+			return results[i]
+		})
+		if err != nil {
+			wg.Done()
+			wg.Wait()
+			j.finish("", err)
+			return err
+		}
+	}
+	wg.Wait()
+
+	j.finish(j.report(ctx, results))
+	return nil
+}
+
+func (j *batchJob) finish(reportKey string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.completed = time.Now()
+	j.reportKey = reportKey
+	j.status = BatchJobComplete
+	if err != nil {
+		j.status = BatchJobFailed
+		j.failure = err.Error()
+	}
+}
+
+func (j *batchJob) finished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return !j.completed.IsZero()
+}
+
+func (j *batchJob) description() BatchJobDescription {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	res := BatchJobDescription{
+		JobID:            j.id,
+		Operation:        j.operation,
+		Status:           j.status,
+		Manifest:         j.manifest,
+		DryRun:           j.dryRun,
+		TotalObjects:     len(j.entries),
+		SucceededObjects: j.succeeded,
+		FailedObjects:    j.failed,
+		Report:           j.reportKey,
+		FailureReason:    j.failure,
+		CreationTime:     j.created.UTC().Format(time.RFC3339),
+	}
+	if !j.completed.IsZero() {
+		res.CompletionTime = j.completed.UTC().Format(time.RFC3339)
+	}
+
+	return res
+}
+
+// CreateBatchJobHandler is an extension submitting the job applying the
+// operation to objects listed in the CSV manifest of the bucket. The job is
+// processed in background with credentials of the request.
+func (h *handler) CreateBatchJobHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if h.cfg.BatchJobs == nil {
+		h.logAndSendError(w, "batch operations are disabled", reqInfo, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+		return
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = checkRequesterIsOwner(r, bktInfo); err != nil {
+		h.logAndSendError(w, "batch jobs are allowed to the bucket owner only", reqInfo, err)
+		return
+	}
+
+	req := new(BatchJobRequest)
+	if err = xml.NewDecoder(r.Body).Decode(req); err != nil {
+		h.logAndSendError(w, "couldn't parse batch job", reqInfo, s3errors.GetAPIError(s3errors.ErrMalformedXML))
+		return
+	}
+
+	process, err := h.batchOperation(r, bktInfo, req)
+	if err != nil {
+		h.logAndSendError(w, "invalid batch job", reqInfo, err)
+		return
+	}
+
+	entries, err := h.readBatchManifest(r.Context(), bktInfo, req.Manifest)
+	if err != nil {
+		h.logAndSendError(w, "couldn't read batch manifest", reqInfo, err)
+		return
+	}
+
+	// the request context is done when the job is processed, so the
+	// credentials are passed to the background context
+	box, err := layer.GetBoxData(r.Context())
+	if err != nil {
+		h.logAndSendError(w, "couldn't get access box", reqInfo, err)
+		return
+	}
+	itemInfo := &api.ReqInfo{API: "Batch" + req.Operation, BucketName: bktInfo.Name, RequestID: reqInfo.RequestID}
+	background := func(ctx context.Context) context.Context {
+		return api.SetReqInfo(context.WithValue(ctx, api.BoxData, box), itemInfo)
+	}
+
+	reportPrefix := req.ReportPrefix
+	if reportPrefix == "" {
+		reportPrefix = DefaultBatchReportPrefix
+	}
+
+	job := &batchJob{
+		id:        uuid.NewString(),
+		bucket:    bktInfo.Name,
+		operation: req.Operation,
+		manifest:  req.Manifest,
+		dryRun:    req.DryRun,
+		created:   time.Now(),
+		entries:   entries,
+		status:    BatchJobNew,
+	}
+	job.process = func(ctx context.Context, entry batchManifestEntry) error {
+		return process(background(ctx), entry)
+	}
+	job.report = func(ctx context.Context, results []error) (string, error) {
+		key := reportPrefix + "job-" + job.id + ".csv"
+		return key, h.putBatchReport(background(ctx), bktInfo, key, entries, results)
+	}
+
+	if err = h.cfg.BatchJobs.add(job); err != nil {
+		h.logAndSendError(w, "couldn't add batch job", reqInfo, err)
+		return
+	}
+
+	h.log.Info("batch job is created", zap.String("request_id", reqInfo.RequestID), zap.String("bucket", bktInfo.Name),
+		zap.String("job", job.id), zap.String("operation", job.operation), zap.Int("objects", len(entries)))
+
+	if err = api.EncodeToResponse(w, job.description()); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+// GetBatchJobHandler is an extension describing the batch job of the bucket,
+// jobs of the bucket are listed if the job ID is empty.
+func (h *handler) GetBatchJobHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if h.cfg.BatchJobs == nil {
+		h.logAndSendError(w, "batch operations are disabled", reqInfo, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+		return
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = checkRequesterIsOwner(r, bktInfo); err != nil {
+		h.logAndSendError(w, "batch jobs are allowed to the bucket owner only", reqInfo, err)
+		return
+	}
+
+	var res any
+	if id := reqInfo.URL.Query().Get("batch"); id == "" {
+		res = &ListBatchJobsResult{Jobs: h.cfg.BatchJobs.list(bktInfo.Name)}
+	} else if job := h.cfg.BatchJobs.find(bktInfo.Name, id); job != nil {
+		res = job.description()
+	} else {
+		h.logAndSendError(w, "batch job not found", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchBatchJob))
+		return
+	}
+
+	if err = api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+// batchOperation validates the job and returns the function applying its
+// operation to the object. Objects are checked against the access policy of
+// the credentials as if they're processed by separate requests.
+func (h *handler) batchOperation(r *http.Request, bktInfo *data.BucketInfo, req *BatchJobRequest) (func(context.Context, batchManifestEntry) error, error) {
+	box, err := layer.GetBoxData(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := api.AccessPolicyConditions(r)
+	allowed := func(action, bucket, key string) error {
+		if box.AccessPolicy != nil && !box.AccessPolicy.IsAllowed(accesspolicy.Request{
+			Action:     action,
+			Resource:   accesspolicy.ResourcePrefix + bucket + "/" + key,
+			Conditions: conditions,
+		}) {
+			return fmt.Errorf("%w: %s is denied by access policy", s3errors.GetAPIError(s3errors.ErrAccessDenied), action)
+		}
+		return nil
+	}
+
+	if req.DryRun && req.Operation != BatchOperationDelete {
+		return nil, fmt.Errorf("%w: dry run of %s operation", s3errors.GetAPIError(s3errors.ErrInvalidArgument), req.Operation)
+	}
+
+	switch req.Operation {
+	case BatchOperationTag:
+		if err = checkTagSet(req.TagSet); err != nil {
+			return nil, err
+		}
+		tagSet := make(map[string]string, len(req.TagSet))
+		for _, tag := range req.TagSet {
+			tagSet[tag.Key] = tag.Value
+		}
+
+		return func(ctx context.Context, entry batchManifestEntry) error {
+			if err := allowed("s3:PutObjectTagging", bktInfo.Name, entry.Key); err != nil {
+				return err
+			}
+
+			_, err := h.obj.PutObjectTagging(ctx, &layer.PutObjectTaggingParams{
+				ObjectVersion: &layer.ObjectVersion{
+					BktInfo:    bktInfo,
+					ObjectName: entry.Key,
+					VersionID:  entry.VersionID,
+				},
+				TagSet: tagSet,
+			})
+			return err
+		}, nil
+	case BatchOperationACL:
+		if req.ACL == "" {
+			return nil, fmt.Errorf("%w: no ACL", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+		}
+		key, err := h.bearerTokenIssuerKey(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		token, err := getSessionTokenSetEACL(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		acp, err := parseACLHeaders(http.Header{api.AmzACL: []string{req.ACL}}, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrInvalidArgument), err)
+		}
+
+		// objects ACLs are stored in the bucket eACL, so they're updated one
+		// by one not to lose concurrent updates
+		var mu sync.Mutex
+		return func(ctx context.Context, entry batchManifestEntry) error {
+			if err := allowed("s3:PutObjectAcl", bktInfo.Name, entry.Key); err != nil {
+				return err
+			}
+
+			objInfo, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{BktInfo: bktInfo, Object: entry.Key, VersionID: entry.VersionID})
+			if err != nil {
+				return err
+			}
+
+			astObject, err := aclToAst(acp, &resourceInfo{Bucket: bktInfo.Name, Object: entry.Key, Version: objInfo.VersionID()})
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			_, err = h.updateBucketACL(ctx, astObject, bktInfo, token)
+			return err
+		}, nil
+	case BatchOperationDelete:
+		return func(ctx context.Context, entry batchManifestEntry) error {
+			if err := allowed("s3:DeleteObject", bktInfo.Name, entry.Key); err != nil {
+				return err
+			}
+
+			if req.DryRun {
+				_, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{BktInfo: bktInfo, Object: entry.Key, VersionID: entry.VersionID})
+				return err
+			}
+
+			settings, err := h.obj.GetBucketSettings(ctx, bktInfo)
+			if err != nil {
+				return err
+			}
+
+			deleted := h.obj.DeleteObjects(ctx, &layer.DeleteObjectParams{
+				BktInfo:  bktInfo,
+				Objects:  []*layer.VersionedObject{{Name: entry.Key, VersionID: entry.VersionID}},
+				Settings: settings,
+			})
+			return deleted[0].Error
+		}, nil
+	case BatchOperationCopy:
+		if req.TargetBucket == "" {
+			return nil, fmt.Errorf("%w: no target bucket", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+		}
+		dstBktInfo, err := h.getBucketAndCheckOwner(r, req.TargetBucket)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, entry batchManifestEntry) error {
+			dstKey := req.TargetPrefix + entry.Key
+			if err := allowed("s3:GetObject", bktInfo.Name, entry.Key); err != nil {
+				return err
+			}
+			if err := allowed("s3:PutObject", dstBktInfo.Name, dstKey); err != nil {
+				return err
+			}
+
+			return h.batchCopy(ctx, bktInfo, dstBktInfo, dstKey, entry)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operation '%s'", s3errors.GetAPIError(s3errors.ErrInvalidArgument), req.Operation)
+	}
+}
+
+// batchCopy copies the object with its metadata and tags like CopyObject
+// with COPY directives does.
+func (h *handler) batchCopy(ctx context.Context, srcBktInfo, dstBktInfo *data.BucketInfo, dstKey string, entry batchManifestEntry) error {
+	extendedSrcObjInfo, err := h.obj.GetExtendedObjectInfo(ctx, &layer.HeadObjectParams{
+		BktInfo:   srcBktInfo,
+		Object:    entry.Key,
+		VersionID: entry.VersionID,
+	})
+	if err != nil {
+		return err
+	}
+	srcObjInfo := extendedSrcObjInfo.ObjectInfo
+
+	if err = (encryption.Params{}).MatchObjectEncryption(layer.FormEncryptionInfo(srcObjInfo.Headers)); err != nil {
+		return fmt.Errorf("encrypted object can't be copied: %w", err)
+	}
+
+	settings, err := h.obj.GetBucketSettings(ctx, dstBktInfo)
+	if err != nil {
+		return err
+	}
+
+	_, tagSet, err := h.obj.GetObjectTagging(ctx, &layer.GetObjectTaggingParams{
+		ObjectVersion: &layer.ObjectVersion{
+			BktInfo:    srcBktInfo,
+			ObjectName: entry.Key,
+			VersionID:  srcObjInfo.VersionID(),
+		},
+		NodeVersion: extendedSrcObjInfo.NodeVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("get object tagging: %w", err)
+	}
+
+	// source headers are cached, so they mustn't be changed
+	metadata := make(map[string]string, len(srcObjInfo.Headers)+1)
+	for k, v := range srcObjInfo.Headers {
+		metadata[k] = v
+	}
+	if len(srcObjInfo.ContentType) > 0 {
+		metadata[api.ContentType] = srcObjInfo.ContentType
+	}
+	if !srcBktInfo.CID.Equals(dstBktInfo.CID) {
+		delete(metadata, layer.AttributeNeofsCopiesNumber)
+	}
+
+	if err = checkUploadConstraints(settings.UploadConstraints, metadata[api.ContentType], srcObjInfo.Size); err != nil {
+		return err
+	}
+
+	if err = h.admitUpload(ctx, dstBktInfo, dstKey, srcObjInfo.Size, metadata); err != nil {
+		return err
+	}
+
+	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
+	if err != nil {
+		return err
+	}
+
+	lock, err := formObjectLock(ctx, dstBktInfo, settings.LockConfiguration, http.Header{})
+	if err != nil {
+		return err
+	}
+
+	extendedDstObjInfo, err := h.obj.CopyObject(ctx, &layer.CopyObjectParams{
+		SrcObject:   srcObjInfo,
+		ScrBktInfo:  srcBktInfo,
+		DstBktInfo:  dstBktInfo,
+		DstObject:   dstKey,
+		SrcSize:     srcObjInfo.Size,
+		Header:      metadata,
+		Lock:        lock,
+		CopiesNuber: copiesNumber,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = h.scanUploaded(ctx, dstBktInfo, extendedDstObjInfo.ObjectInfo, encryption.Params{}); err != nil {
+		return err
+	}
+
+	if len(tagSet) == 0 {
+		return nil
+	}
+
+	_, err = h.obj.PutObjectTagging(ctx, &layer.PutObjectTaggingParams{
+		ObjectVersion: &layer.ObjectVersion{
+			BktInfo:    dstBktInfo,
+			ObjectName: dstKey,
+			VersionID:  extendedDstObjInfo.ObjectInfo.VersionID(),
+		},
+		TagSet:      tagSet,
+		NodeVersion: extendedDstObjInfo.NodeVersion,
+	})
+	return err
+}
+
+func (h *handler) readBatchManifest(ctx context.Context, bktInfo *data.BucketInfo, key string) ([]batchManifestEntry, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: no manifest", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+	}
+
+	objInfo, err := h.obj.GetObjectInfo(ctx, &layer.HeadObjectParams{BktInfo: bktInfo, Object: key})
+	if err != nil {
+		return nil, err
+	}
+	if objInfo.Size > maxBatchManifestSize {
+		return nil, fmt.Errorf("%w: manifest is larger than %d bytes", s3errors.GetAPIError(s3errors.ErrInvalidArgument), maxBatchManifestSize)
+	}
+
+	var buf bytes.Buffer
+	if err = h.obj.GetObject(ctx, &layer.GetObjectParams{ObjectInfo: objInfo, BucketInfo: bktInfo, Writer: &buf}); err != nil {
+		return nil, err
+	}
+
+	return parseBatchManifest(&buf, bktInfo.Name)
+}
+
+// parseBatchManifest parses CSV manifest in the format of S3 Batch Operations,
+// all objects must be in the bucket of the job.
+func parseBatchManifest(r io.Reader, bucket string) ([]batchManifestEntry, error) {
+	rd := csv.NewReader(r)
+	rd.FieldsPerRecord = -1
+
+	var entries []batchManifestEntry
+	for {
+		record, err := rd.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid manifest: %s", s3errors.GetAPIError(s3errors.ErrInvalidArgument), err)
+		}
+		if len(record) != 2 && len(record) != 3 {
+			return nil, fmt.Errorf("%w: manifest line %d must contain bucket, key and optional version ID",
+				s3errors.GetAPIError(s3errors.ErrInvalidArgument), len(entries)+1)
+		}
+		if record[0] != bucket {
+			return nil, fmt.Errorf("%w: manifest line %d lists object of another bucket '%s'",
+				s3errors.GetAPIError(s3errors.ErrInvalidArgument), len(entries)+1, record[0])
+		}
+
+		key, err := url.QueryUnescape(record[1])
+		if err != nil || key == "" {
+			return nil, fmt.Errorf("%w: manifest line %d contains invalid key '%s'",
+				s3errors.GetAPIError(s3errors.ErrInvalidArgument), len(entries)+1, record[1])
+		}
+
+		entry := batchManifestEntry{Bucket: record[0], Key: key}
+		if len(record) == 3 {
+			entry.VersionID = record[2]
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: manifest is empty", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+	}
+
+	return entries, nil
+}
+
+// putBatchReport stores CSV report with the status and the error of every
+// manifest entry.
+func (h *handler) putBatchReport(ctx context.Context, bktInfo *data.BucketInfo, key string, entries []batchManifestEntry, results []error) error {
+	var buf bytes.Buffer
+	wr := csv.NewWriter(&buf)
+	for i, entry := range entries {
+		status, reason := "succeeded", ""
+		if results[i] != nil {
+			status, reason = "failed", results[i].Error()
+		}
+		if err := wr.Write([]string{entry.Bucket, url.QueryEscape(entry.Key), entry.VersionID, status, reason}); err != nil {
+			return err
+		}
+	}
+	wr.Flush()
+	if err := wr.Error(); err != nil {
+		return err
+	}
+
+	_, err := h.obj.PutObject(ctx, &layer.PutObjectParams{
+		BktInfo:      bktInfo,
+		Object:       key,
+		Size:         int64(buf.Len()),
+		Reader:       &buf,
+		Header:       map[string]string{api.ContentType: "text/csv"},
+		CopiesNumber: h.cfg.CopiesNumber,
+	})
+	return err
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchJobs(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-batch"
+	createTestBucket(hc, bktName)
+	putObject(t, hc, bktName, "a b")
+	putObject(t, hc, bktName, "c")
+
+	putManifest := func(name, manifest string) {
+		w, r := prepareTestPayloadRequest(hc, bktName, name, strings.NewReader(manifest))
+		hc.Handler().PutObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+	}
+	putManifest("manifest.csv", bktName+",a+b\n"+bktName+",c\n"+bktName+",missing\n")
+	putManifest("foreign.csv", "another-bucket,a\n")
+
+	createJob := func(req *BatchJobRequest) *httptest.ResponseRecorder {
+		w, r := prepareTestFullRequest(hc, bktName, "", url.Values{"batch": []string{""}}, req)
+		hc.Handler().CreateBatchJobHandler(w, r)
+		return w
+	}
+	getJob := func(id string) BatchJobDescription {
+		w, r := prepareTestFullRequest(hc, bktName, "", url.Values{"batch": []string{id}}, nil)
+		hc.Handler().GetBatchJobHandler(w, r)
+		var res BatchJobDescription
+		readResponse(t, w, http.StatusOK, &res)
+		return res
+	}
+	run := func() {
+		err := hc.Handler().cfg.BatchJobs.Run(hc.Context(), func(ctx context.Context, f func(context.Context) error) error {
+			_ = f(ctx)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	tagJob := &BatchJobRequest{Operation: BatchOperationTag, Manifest: "manifest.csv", TagSet: []Tag{{Key: "k", Value: "v"}}}
+
+	w := createJob(tagJob)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+
+	hc.Handler().cfg.BatchJobs = NewBatchJobs(2)
+
+	w = createJob(&BatchJobRequest{Operation: "Restore", Manifest: "manifest.csv"})
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+	w = createJob(&BatchJobRequest{Operation: BatchOperationDelete, Manifest: "foreign.csv"})
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	w = createJob(tagJob)
+	var job BatchJobDescription
+	readResponse(t, w, http.StatusOK, &job)
+	require.Equal(t, BatchJobNew, job.Status)
+	require.Equal(t, 3, job.TotalObjects)
+
+	run()
+
+	job = getJob(job.JobID)
+	require.Equal(t, BatchJobComplete, job.Status)
+	require.Equal(t, 2, job.SucceededObjects)
+	require.Equal(t, 1, job.FailedObjects)
+	require.Equal(t, DefaultBatchReportPrefix+"job-"+job.JobID+".csv", job.Report)
+	require.Equal(t, []Tag{{Key: "k", Value: "v"}}, getObjectTagging(t, hc, bktName, "a b", "").TagSet)
+
+	w, r := prepareTestRequest(hc, bktName, job.Report, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	report, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(report)), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, bktName+",a+b,,succeeded,", lines[0])
+	require.True(t, strings.HasPrefix(lines[2], bktName+",missing,,failed,"))
+
+	w = createJob(&BatchJobRequest{Operation: BatchOperationTag, Manifest: "manifest.csv", DryRun: true})
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	w = createJob(&BatchJobRequest{Operation: BatchOperationDelete, Manifest: "manifest.csv", DryRun: true})
+	var dryRunJob BatchJobDescription
+	readResponse(t, w, http.StatusOK, &dryRunJob)
+	require.True(t, dryRunJob.DryRun)
+
+	run()
+
+	dryRunJob = getJob(dryRunJob.JobID)
+	require.Equal(t, BatchJobComplete, dryRunJob.Status)
+	require.Equal(t, 2, dryRunJob.SucceededObjects)
+	require.Equal(t, 1, dryRunJob.FailedObjects)
+	checkFound(t, hc, bktName, "a b", "")
+	checkFound(t, hc, bktName, "c", "")
+
+	w = createJob(&BatchJobRequest{Operation: BatchOperationDelete, Manifest: "manifest.csv", ReportPrefix: "reports/"})
+	var deleteJob BatchJobDescription
+	readResponse(t, w, http.StatusOK, &deleteJob)
+
+	run()
+
+	deleteJob = getJob(deleteJob.JobID)
+	require.Equal(t, BatchJobComplete, deleteJob.Status)
+	require.Equal(t, "reports/job-"+deleteJob.JobID+".csv", deleteJob.Report)
+	checkNotFound(t, hc, bktName, "a b", "")
+	checkNotFound(t, hc, bktName, "c", "")
+
+	// the oldest finished job is evicted
+	w = createJob(tagJob)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"batch": []string{""}}, nil)
+	hc.Handler().GetBatchJobHandler(w, r)
+	var list ListBatchJobsResult
+	readResponse(t, w, http.StatusOK, &list)
+	require.Len(t, list.Jobs, 2)
+	require.Equal(t, deleteJob.JobID, list.Jobs[0].JobID)
+
+	// pending jobs aren't evicted
+	w = createJob(tagJob)
+	assertStatus(t, w, http.StatusOK)
+	w = createJob(tagJob)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrTooManyBatchJobs))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"batch": []string{job.JobID}}, nil)
+	hc.Handler().GetBatchJobHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchBatchJob))
+}
+
+func TestBatchCopy(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	hc.Handler().cfg.BatchJobs = NewBatchJobs(DefaultBatchJobsLimit)
+
+	bktName, dstBktName := "bucket-for-batch-copy", "bucket-for-batch-copies"
+	createTestBucket(hc, bktName)
+	createTestBucket(hc, dstBktName)
+	putObject(t, hc, bktName, "obj")
+	putObjectTagging(t, hc, bktName, "obj", map[string]string{"k": "v"})
+
+	w, r := prepareTestPayloadRequest(hc, bktName, "manifest.csv", strings.NewReader(bktName+",obj\n"))
+	hc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"batch": []string{""}}, &BatchJobRequest{
+		Operation:    BatchOperationCopy,
+		Manifest:     "manifest.csv",
+		TargetBucket: dstBktName,
+		TargetPrefix: "copies/",
+	})
+	hc.Handler().CreateBatchJobHandler(w, r)
+	var job BatchJobDescription
+	readResponse(t, w, http.StatusOK, &job)
+
+	err := hc.Handler().cfg.BatchJobs.Run(hc.Context(), func(ctx context.Context, f func(context.Context) error) error {
+		return f(ctx)
+	})
+	require.NoError(t, err)
+
+	job = hc.Handler().cfg.BatchJobs.find(bktName, job.JobID).description()
+	require.Equal(t, BatchJobComplete, job.Status)
+	require.Equal(t, 1, job.SucceededObjects)
+	require.Equal(t, "content", string(getObjectRange(t, hc, dstBktName, "copies/obj", 0, 6)))
+	require.Equal(t, []Tag{{Key: "k", Value: "v"}}, getObjectTagging(t, hc, dstBktName, "copies/obj", "").TagSet)
+}
+
+func TestParseBatchManifest(t *testing.T) {
+	entries, err := parseBatchManifest(strings.NewReader("bkt,dir%2Fkey\nbkt,other,version\n"), "bkt")
+	require.NoError(t, err)
+	require.Equal(t, []batchManifestEntry{
+		{Bucket: "bkt", Key: "dir/key"},
+		{Bucket: "bkt", Key: "other", VersionID: "version"},
+	}, entries)
+
+	for _, manifest := range []string{"", "bkt\n", "bkt,key,version,extra\n", "bkt,%zz\n", "bkt,\n"} {
+		_, err = parseBatchManifest(strings.NewReader(manifest), "bkt")
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidArgument), manifest)
+	}
+}
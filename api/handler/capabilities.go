@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/compression"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
+)
+
+type (
+	// Capabilities describes S3 operations and extensions supported by the gateway.
+	Capabilities struct {
+		Version    string               `json:"version"`
+		Operations []string             `json:"operations"`
+		Features   CapabilitiesFeatures `json:"features"`
+		Extensions []string             `json:"extensions"`
+	}
+
+	// CapabilitiesFeatures describes support of S3 features.
+	CapabilitiesFeatures struct {
+		Versioning    bool     `json:"versioning"`
+		ObjectLock    bool     `json:"object_lock"`
+		Select        bool     `json:"select"`
+		Notifications bool     `json:"notifications"`
+		SSEC          bool     `json:"sse_c"`
+		Lifecycle     bool     `json:"lifecycle"`
+		Website       bool     `json:"website"`
+		Compression   []string `json:"compression"`
+		Deduplication bool     `json:"deduplication"`
+	}
+)
+
+// supportedOperations lists operations which aren't answered with
+// NotImplemented or NotSupported errors, names match the ones in
+// docs/aws_s3_compat.md.
+var supportedOperations = []string{
+	"AbortMultipartUpload",
+	"CompleteMultipartUpload",
+	"CopyObject",
+	"CreateBucket",
+	"CreateMultipartUpload",
+	"DeleteBucket",
+	"DeleteBucketCors",
+	"DeleteBucketTagging",
+	"DeleteObject",
+	"DeleteObjectTagging",
+	"DeleteObjects",
+	"GetBucketAcl",
+	"GetBucketCors",
+	"GetBucketLocation",
+	"GetBucketMetricsConfiguration",
+	"GetBucketPolicy",
+	"GetBucketTagging",
+	"GetBucketVersioning",
+	"GetObject",
+	"GetObjectAcl",
+	"GetObjectAttributes",
+	"GetObjectLegalHold",
+	"GetObjectLockConfiguration",
+	"GetObjectRetention",
+	"GetObjectTagging",
+	"HeadBucket",
+	"HeadObject",
+	"ListBucketMetricsConfigurations",
+	"ListBuckets",
+	"ListMultipartUploads",
+	"ListObjectVersions",
+	"ListObjects",
+	"ListObjectsV2",
+	"ListParts",
+	"PostPolicyBucket",
+	"PutBucketAcl",
+	"PutBucketCors",
+	"PutBucketPolicy",
+	"PutBucketTagging",
+	"PutBucketVersioning",
+	"PutObject",
+	"PutObjectAcl",
+	"PutObjectLegalHold",
+	"PutObjectLockConfiguration",
+	"PutObjectRetention",
+	"PutObjectTagging",
+	"UploadPart",
+	"UploadPartCopy",
+}
+
+// supportedExtensions lists gateway specific headers and requests described
+// in docs/aws_s3_compat.md which don't depend on the configuration.
+var supportedExtensions = []string{
+	api.MoveSource,
+	api.RequestTimeout,
+	api.VerifyChecksum,
+	api.BucketCompression,
+	api.BucketDeduplication,
+	"PurgePrefix",
+	"UploadConstraints",
+	"AnonymousAccess",
+	"DeletionConfiguration",
+	"Undelete",
+	"PrivacyConfiguration",
+}
+
+// notificationOperations are supported if notifications are enabled.
+var notificationOperations = []string{
+	"GetBucketNotificationConfiguration",
+	"PutBucketNotificationConfiguration",
+}
+
+// extensions returns gateway specific headers and requests enabled by the
+// configuration.
+func (h *handler) extensions() []string {
+	extensions := supportedExtensions[:len(supportedExtensions):len(supportedExtensions)]
+	if h.cfg.Transform != nil {
+		extensions = append(extensions, api.QueryTransform)
+	}
+	if h.cfg.ListingExport {
+		extensions = append(extensions, "ExportObjects")
+	}
+	if h.cfg.ObjectSearch {
+		extensions = append(extensions, "SearchObjects")
+	}
+	if h.cfg.GzipUploads {
+		extensions = append(extensions, api.DecompressedContentLength)
+	}
+	if h.cfg.BatchJobs != nil {
+		extensions = append(extensions, "BatchOperations")
+	}
+
+	return extensions
+}
+
+// CapabilitiesHandler describes what the gateway supports, so clients can
+// adapt without probing with failing calls.
+func (h *handler) CapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	operations := supportedOperations
+	if h.cfg.NotificatorEnabled {
+		operations = append(operations[:len(operations):len(operations)], notificationOperations...)
+		sort.Strings(operations)
+	}
+
+	res := Capabilities{
+		Version:    version.Version,
+		Operations: operations,
+		Features: CapabilitiesFeatures{
+			Versioning:    true,
+			ObjectLock:    true,
+			Notifications: h.cfg.NotificatorEnabled,
+			SSEC:          true,
+			Compression:   []string{compression.AlgorithmZstd},
+			Deduplication: true,
+		},
+		Extensions: h.extensions(),
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		h.logAndSendError(w, "couldn't encode capabilities", api.GetReqInfo(r.Context()), err)
+		return
+	}
+
+	api.WriteResponse(w, http.StatusOK, data, api.MimeJSON)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/transform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	getCapabilities := func() Capabilities {
+		w, r := prepareTestRequest(hc, "", "", nil)
+		hc.Handler().CapabilitiesHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		require.Equal(t, "application/json", w.Header().Get(api.ContentType))
+
+		var res Capabilities
+		require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&res))
+		return res
+	}
+
+	res := getCapabilities()
+	require.Contains(t, res.Operations, "PutObject")
+	require.NotContains(t, res.Operations, "SelectObjectContent")
+	require.NotContains(t, res.Operations, "PutBucketNotificationConfiguration")
+	require.True(t, res.Features.Versioning)
+	require.False(t, res.Features.Select)
+	require.False(t, res.Features.Notifications)
+	require.Contains(t, res.Extensions, api.MoveSource)
+	require.NotContains(t, res.Extensions, api.QueryTransform)
+	require.NotContains(t, res.Extensions, "ExportObjects")
+	require.NotContains(t, res.Extensions, "SearchObjects")
+	require.NotContains(t, res.Extensions, api.DecompressedContentLength)
+	require.NotContains(t, res.Extensions, "BatchOperations")
+
+	hc.Handler().cfg.NotificatorEnabled = true
+	res = getCapabilities()
+	require.True(t, res.Features.Notifications)
+	require.Contains(t, res.Operations, "PutBucketNotificationConfiguration")
+	require.IsIncreasing(t, res.Operations)
+	require.NotContains(t, supportedOperations, "PutBucketNotificationConfiguration")
+
+	hc.Handler().cfg.Transform = new(transform.Pipeline)
+	require.Contains(t, getCapabilities().Extensions, api.QueryTransform)
+
+	hc.Handler().cfg.ListingExport = true
+	require.Contains(t, getCapabilities().Extensions, "ExportObjects")
+
+	hc.Handler().cfg.ObjectSearch = true
+	require.Contains(t, getCapabilities().Extensions, "SearchObjects")
+
+	hc.Handler().cfg.GzipUploads = true
+	require.Contains(t, getCapabilities().Extensions, api.DecompressedContentLength)
+
+	hc.Handler().cfg.BatchJobs = new(BatchJobs)
+	require.Contains(t, getCapabilities().Extensions, "BatchOperations")
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
@@ -19,6 +20,7 @@ type copyObjectArgs struct {
 	Conditional       *conditionalArgs
 	MetadataDirective string
 	TaggingDirective  string
+	MoveSource        bool
 }
 
 const (
@@ -115,8 +117,16 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if args.MoveSource && reqInfo.BucketName == srcBucket && reqInfo.ObjectName == srcObject {
+		h.logAndSendError(w, "moving to itself", reqInfo, s3errors.GetAPIError(s3errors.ErrInvalidCopyDest))
+		return
+	}
+
 	if args.MetadataDirective == replaceDirective {
-		metadata = parseMetadata(r)
+		if metadata, err = parseMetadata(r); err != nil {
+			h.logAndSendError(w, "invalid metadata", reqInfo, err)
+			return
+		}
 	}
 
 	if args.TaggingDirective == replaceDirective {
@@ -159,12 +169,34 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if metadata == nil {
+		// source headers are cached, so they mustn't be changed
+		metadata = make(map[string]string, len(srcObjInfo.Headers)+1)
+		for k, v := range srcObjInfo.Headers {
+			metadata[k] = v
+		}
 		if len(srcObjInfo.ContentType) > 0 {
-			srcObjInfo.Headers[api.ContentType] = srcObjInfo.ContentType
+			metadata[api.ContentType] = srcObjInfo.ContentType
+		}
+		// copies number of the source object can be unsatisfiable by placement
+		// policy of another container, so the destination one is used
+		if !srcObjPrm.BktInfo.CID.Equals(dstBktInfo.CID) {
+			delete(metadata, layer.AttributeNeofsCopiesNumber)
+		}
+		if copiesNumber := r.Header.Get(api.MetadataPrefix + layer.AttributeNeofsCopiesNumber); copiesNumber != "" {
+			metadata[layer.AttributeNeofsCopiesNumber] = copiesNumber
 		}
-		metadata = srcObjInfo.Headers
-	} else if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
-		metadata[api.ContentType] = contentType
+	} else {
+		setSystemMetadata(metadata, r.Header)
+	}
+
+	if err = checkUploadConstraints(settings.UploadConstraints, metadata[api.ContentType], srcObjInfo.Size); err != nil {
+		h.logAndSendError(w, "upload constraints violated", reqInfo, err)
+		return
+	}
+
+	if err = h.admitUpload(r.Context(), dstBktInfo, reqInfo.ObjectName, srcObjInfo.Size, metadata); err != nil {
+		h.logAndSendError(w, "upload isn't admitted", reqInfo, err)
+		return
 	}
 
 	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
@@ -198,6 +230,25 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	dstObjInfo := extendedDstObjInfo.ObjectInfo
 
+	if err = h.scanUploaded(r.Context(), dstBktInfo, dstObjInfo, encryptionParams); err != nil {
+		h.logAndSendError(w, "object rejected by scanner", reqInfo, err, additional...)
+		return
+	}
+
+	if args.MoveSource {
+		if err = h.deleteMovedObject(r, srcObjPrm.BktInfo, srcObject, versionID, dstBktInfo, settings, dstObjInfo); err != nil {
+			h.logAndSendError(w, "couldn't delete moved object", reqInfo, err, additional...)
+			return
+		}
+	}
+
+	if len(versionID) > 0 {
+		w.Header().Set(api.AmzCopySourceVersionID, srcObjInfo.VersionID())
+	}
+	if settings.VersioningEnabled() {
+		w.Header().Set(api.AmzVersionID, dstObjInfo.VersionID())
+	}
+
 	if err = api.EncodeToResponse(w, &CopyObjectResponse{LastModified: dstObjInfo.Created.UTC().Format(time.RFC3339), ETag: dstObjInfo.HashSum}); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err, additional...)
 		return
@@ -258,6 +309,38 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// deleteMovedObject deletes the source of a move. If it fails, the copy is
+// deleted too, so the source isn't left duplicated under the new key.
+func (h *handler) deleteMovedObject(r *http.Request, srcBktInfo *data.BucketInfo, srcObject, srcVersionID string,
+	dstBktInfo *data.BucketInfo, dstSettings *data.BucketSettings, dstObjInfo *data.ObjectInfo) error {
+	srcSettings, err := h.obj.GetBucketSettings(r.Context(), srcBktInfo)
+	if err != nil {
+		return err
+	}
+
+	deleted := h.obj.DeleteObjects(r.Context(), &layer.DeleteObjectParams{
+		BktInfo:  srcBktInfo,
+		Objects:  []*layer.VersionedObject{{Name: srcObject, VersionID: srcVersionID}},
+		Settings: srcSettings,
+	})
+	if err = deleted[0].Error; err == nil {
+		return nil
+	}
+
+	rollback := h.obj.DeleteObjects(r.Context(), &layer.DeleteObjectParams{
+		BktInfo:  dstBktInfo,
+		Objects:  []*layer.VersionedObject{{Name: dstObjInfo.Name, VersionID: dstObjInfo.VersionID()}},
+		Settings: dstSettings,
+	})
+	if rollback[0].Error != nil {
+		h.log.Error("couldn't delete copy of object that failed to move",
+			zap.String("bucket", dstBktInfo.Name), zap.String("object", dstObjInfo.Name),
+			zap.Stringer("object_id", dstObjInfo.ID), zap.Error(rollback[0].Error))
+	}
+
+	return err
+}
+
 func isCopyingToItselfForbidden(reqInfo *api.ReqInfo, srcBucket string, srcObject string, settings *data.BucketSettings, args *copyObjectArgs) bool {
 	if reqInfo.BucketName != srcBucket || reqInfo.ObjectName != srcObject {
 		return false
@@ -296,6 +379,12 @@ func parseCopyObjectArgs(headers http.Header) (*copyObjectArgs, error) {
 		return nil, s3errors.GetAPIError(s3errors.ErrInvalidTaggingDirective)
 	}
 
+	if move := headers.Get(api.MoveSource); move != "" {
+		if copyArgs.MoveSource, err = strconv.ParseBool(move); err != nil {
+			return nil, s3errors.GetAPIError(s3errors.ErrInvalidArgument)
+		}
+	}
+
 	return copyArgs, nil
 }
 
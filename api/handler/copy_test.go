@@ -4,9 +4,13 @@ import (
 	"encoding/xml"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,6 +19,8 @@ type CopyMeta struct {
 	Tags              map[string]string
 	MetadataDirective string
 	Metadata          map[string]string
+	Headers           map[string]string
+	Move              bool
 }
 
 func TestCopyWithTaggingDirective(t *testing.T) {
@@ -63,6 +69,168 @@ func TestCopyToItself(t *testing.T) {
 	copyObject(t, tc, bktName, objName, objName, copyMeta, http.StatusOK)
 }
 
+func TestCopyMoveSource(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName, newName := "bucket-for-move", "object-to-move", "moved-object"
+	createBucketAndObject(tc, bktName, objName)
+
+	copyObject(t, tc, bktName, objName, objName, CopyMeta{Move: true, MetadataDirective: replaceDirective}, http.StatusBadRequest)
+	headObject(t, tc, bktName, objName, nil, http.StatusOK)
+
+	copyObject(t, tc, bktName, objName, newName, CopyMeta{Move: true}, http.StatusOK)
+	headObject(t, tc, bktName, objName, nil, http.StatusNotFound)
+	headObject(t, tc, bktName, newName, nil, http.StatusOK)
+}
+
+func TestCopyWithMetadataDirective(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-copy", "object-from-copy"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestPayloadRequest(tc, bktName, objName, strings.NewReader("content"))
+	r.Header.Set(api.MetadataPrefix+"Key", "val")
+	r.Header.Set(api.ContentType, "text/plain")
+	r.Header.Set(api.CacheControl, "no-cache")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	headers := map[string]string{
+		api.ContentType:  "application/json",
+		api.CacheControl: "max-age=60",
+		api.Expires:      "Wed, 21 Oct 2015 07:28:00 GMT",
+	}
+	copyMeta := CopyMeta{Metadata: map[string]string{"Key2": "val2"}, Headers: headers}
+	copyObject(t, tc, bktName, objName, "copied", copyMeta, http.StatusOK)
+
+	// metadata of the source is copied, the request one is ignored
+	h := headObjectHeaders(t, tc, bktName, "copied")
+	require.Equal(t, []string{"val"}, h[api.MetadataPrefix+"key"])
+	require.Empty(t, h[api.MetadataPrefix+"key2"])
+	require.Equal(t, "text/plain", h.Get(api.ContentType))
+	require.Equal(t, "no-cache", h.Get(api.CacheControl))
+
+	copyMeta.MetadataDirective = replaceDirective
+	copyObject(t, tc, bktName, objName, "replaced", copyMeta, http.StatusOK)
+
+	h = headObjectHeaders(t, tc, bktName, "replaced")
+	require.Empty(t, h[api.MetadataPrefix+"key"])
+	require.Equal(t, []string{"val2"}, h[api.MetadataPrefix+"key2"])
+	require.Equal(t, "application/json", h.Get(api.ContentType))
+	require.Equal(t, "max-age=60", h.Get(api.CacheControl))
+	require.Equal(t, headers[api.Expires], h.Get(api.Expires))
+
+	copyObject(t, tc, bktName, objName, "invalid", CopyMeta{MetadataDirective: "MERGE"}, http.StatusBadRequest)
+	copyObject(t, tc, bktName, objName, "invalid", CopyMeta{TaggingDirective: "MERGE"}, http.StatusBadRequest)
+}
+
+func TestCopyConditional(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-copy", "object-from-copy"
+	createTestBucket(tc, bktName)
+	putObjectContent(tc, bktName, objName, "content")
+
+	h := headObjectHeaders(t, tc, bktName, objName)
+	etag, lastModified := h.Get(api.ETag), h.Get(api.LastModified)
+	modified, err := time.Parse(http.TimeFormat, lastModified)
+	require.NoError(t, err)
+	earlier := modified.Add(-time.Hour).Format(http.TimeFormat)
+
+	for _, tc2 := range []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "if-match", headers: map[string]string{api.AmzCopyIfMatch: etag}, status: http.StatusOK},
+		{name: "if-match quoted", headers: map[string]string{api.AmzCopyIfMatch: "\"" + etag + "\""}, status: http.StatusOK},
+		{name: "if-match list", headers: map[string]string{api.AmzCopyIfMatch: "\"other\", \"" + etag + "\""}, status: http.StatusOK},
+		{name: "if-match any", headers: map[string]string{api.AmzCopyIfMatch: "*"}, status: http.StatusOK},
+		{name: "if-match other", headers: map[string]string{api.AmzCopyIfMatch: "\"other\""}, status: http.StatusPreconditionFailed},
+		{name: "if-none-match", headers: map[string]string{api.AmzCopyIfNoneMatch: "\"other\""}, status: http.StatusOK},
+		{name: "if-none-match same", headers: map[string]string{api.AmzCopyIfNoneMatch: "\"" + etag + "\""}, status: http.StatusPreconditionFailed},
+		{name: "if-modified-since", headers: map[string]string{api.AmzCopyIfModifiedSince: earlier}, status: http.StatusOK},
+		{name: "if-modified-since last modified", headers: map[string]string{api.AmzCopyIfModifiedSince: lastModified}, status: http.StatusPreconditionFailed},
+		{name: "if-unmodified-since last modified", headers: map[string]string{api.AmzCopyIfUnmodifiedSince: lastModified}, status: http.StatusOK},
+		{name: "if-unmodified-since", headers: map[string]string{api.AmzCopyIfUnmodifiedSince: earlier}, status: http.StatusPreconditionFailed},
+		{
+			name:    "if-match, if-unmodified-since",
+			headers: map[string]string{api.AmzCopyIfMatch: etag, api.AmzCopyIfUnmodifiedSince: earlier},
+			status:  http.StatusOK,
+		},
+	} {
+		t.Run(tc2.name, func(t *testing.T) {
+			copyObject(t, tc, bktName, objName, "copied", CopyMeta{Headers: tc2.headers}, tc2.status)
+		})
+	}
+}
+
+func TestCopyVersionHeaders(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-copy", "object-from-copy"
+	createTestBucket(tc, bktName)
+	putBucketVersioning(t, tc, bktName, true)
+	putObjectContent(tc, bktName, objName, "content")
+	srcVersion := headObjectHeaders(t, tc, bktName, objName).Get(api.AmzVersionID)
+
+	w, r := prepareTestRequest(tc, bktName, "copied", nil)
+	r.Header.Set(api.AmzCopySource, bktName+"/"+objName+"?versionId="+srcVersion)
+	tc.Handler().CopyObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	require.Equal(t, srcVersion, w.Header().Get(api.AmzCopySourceVersionID))
+	require.Equal(t, headObjectHeaders(t, tc, bktName, "copied").Get(api.AmzVersionID), w.Header().Get(api.AmzVersionID))
+}
+
+func TestCopyToAnotherContainer(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	srcBkt, dstBkt, objName := "bucket-src", "bucket-dst", "object"
+	createTestBucket(tc, srcBkt)
+	dstBktInfo := createTestBucket(tc, dstBkt)
+
+	w, r := prepareTestPayloadRequest(tc, srcBkt, objName, strings.NewReader("content"))
+	r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsCopiesNumber), "3")
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	// copies number is kept inside the container
+	copyObject(t, tc, srcBkt, objName, "copied", CopyMeta{}, http.StatusOK)
+	h := headObjectHeaders(t, tc, srcBkt, "copied")
+	require.Equal(t, []string{"3"}, h[api.MetadataPrefix+layer.AttributeNeofsCopiesNumber])
+
+	copyToBucket := func(dstObject, copiesNumber string) *data.ObjectInfo {
+		w, r := prepareTestRequest(tc, dstBkt, dstObject, nil)
+		r.Header.Set(api.AmzCopySource, srcBkt+"/"+objName)
+		if copiesNumber != "" {
+			r.Header.Set(api.MetadataPrefix+strings.ToUpper(layer.AttributeNeofsCopiesNumber), copiesNumber)
+		}
+		tc.Handler().CopyObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+
+		objInfo, err := tc.Layer().GetObjectInfo(tc.Context(), &layer.HeadObjectParams{BktInfo: dstBktInfo, Object: dstObject})
+		require.NoError(t, err)
+		return objInfo
+	}
+
+	// placement policy of the destination container is used
+	objInfo := copyToBucket("copied", "")
+	require.NotContains(t, objInfo.Headers, layer.AttributeNeofsCopiesNumber)
+	require.EqualValues(t, len("content"), objInfo.Size)
+
+	objInfo = copyToBucket("copied-with-copies-number", "1")
+	require.Equal(t, "1", objInfo.Headers[layer.AttributeNeofsCopiesNumber])
+}
+
+func headObjectHeaders(t *testing.T, tc *handlerContext, bktName, objName string) http.Header {
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	return w.Header()
+}
+
 func copyObject(t *testing.T, tc *handlerContext, bktName, fromObject, toObject string, copyMeta CopyMeta, statusCode int) {
 	w, r := prepareTestRequest(tc, bktName, toObject, nil)
 	r.Header.Set(api.AmzCopySource, bktName+"/"+fromObject)
@@ -79,6 +247,13 @@ func copyObject(t *testing.T, tc *handlerContext, bktName, fromObject, toObject
 	}
 	r.Header.Set(api.AmzTagging, tagsQuery.Encode())
 
+	if copyMeta.Move {
+		r.Header.Set(api.MoveSource, "true")
+	}
+	for key, val := range copyMeta.Headers {
+		r.Header.Set(key, val)
+	}
+
 	tc.Handler().CopyObjectHandler(w, r)
 	assertStatus(t, w, statusCode)
 }
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -25,6 +27,19 @@ type DeleteObjectsRequest struct {
 	Objects []ObjectIdentifier `xml:"Object"`
 }
 
+// PurgePrefixProgress is a progress report of PurgePrefixHandler.
+type PurgePrefixProgress struct {
+	Found   uint64
+	Deleted uint64
+	Failed  uint64
+}
+
+// PurgePrefixError is an error which interrupted PurgePrefixHandler.
+type PurgePrefixError struct {
+	Code    string
+	Message string
+}
+
 // ObjectIdentifier carries the key name for the object to delete.
 type ObjectIdentifier struct {
 	ObjectName string `xml:"Key"`
@@ -65,6 +80,12 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 		VersionID: versionID,
 	}}
 
+	bypass, err := parseBypassGovernance(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid bypass governance header", reqInfo, err)
+		return
+	}
+
 	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
 	if err != nil {
 		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
@@ -78,9 +99,10 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	p := &layer.DeleteObjectParams{
-		BktInfo:  bktInfo,
-		Objects:  versionedObject,
-		Settings: bktSettings,
+		BktInfo:          bktInfo,
+		Objects:          versionedObject,
+		Settings:         bktSettings,
+		BypassGovernance: bypass,
 	}
 	deletedObjects := h.obj.DeleteObjects(r.Context(), p)
 	deletedObject := deletedObjects[0]
@@ -141,6 +163,21 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// parseBypassGovernance parses x-amz-bypass-governance-retention header.
+func parseBypassGovernance(header http.Header) (bool, error) {
+	bypassStr := header.Get(api.AmzBypassGovernanceRetention)
+	if len(bypassStr) == 0 {
+		return false, nil
+	}
+
+	bypass, err := strconv.ParseBool(bypassStr)
+	if err != nil {
+		return false, s3errors.GetAPIError(s3errors.ErrInvalidArgument)
+	}
+
+	return bypass, nil
+}
+
 func isErrObjectLocked(err error) bool {
 	switch err.(type) {
 	default:
@@ -181,6 +218,12 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	bypass, err := parseBypassGovernance(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid bypass governance header", reqInfo, err)
+		return
+	}
+
 	removed := make(map[string]*layer.VersionedObject)
 	toRemove := make([]*layer.VersionedObject, 0, len(requested.Objects))
 	for _, obj := range requested.Objects {
@@ -217,11 +260,13 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 	})
 
 	p := &layer.DeleteObjectParams{
-		BktInfo:  bktInfo,
-		Objects:  toRemove,
-		Settings: bktSettings,
+		BktInfo:          bktInfo,
+		Objects:          toRemove,
+		Settings:         bktSettings,
+		BypassGovernance: bypass,
 	}
 	deletedObjects := h.obj.DeleteObjects(r.Context(), p)
+	h.sendDeleteNotifications(r.Context(), bktInfo, reqInfo, deletedObjects)
 
 	var errs []error
 	for _, obj := range deletedObjects {
@@ -265,6 +310,123 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// sendDeleteNotifications sends events of objects removed by one request, the
+// notification configuration is fetched once for all of them.
+func (h *handler) sendDeleteNotifications(ctx context.Context, bktInfo *data.BucketInfo, reqInfo *api.ReqInfo, deleted []*layer.VersionedObject) {
+	if !h.cfg.NotificatorEnabled {
+		return
+	}
+
+	conf, err := h.obj.GetBucketNotificationConfiguration(ctx, bktInfo)
+	if err != nil {
+		h.log.Error("couldn't send notifications", zap.Error(err))
+		return
+	}
+
+	for _, obj := range deleted {
+		if obj.Error != nil {
+			continue
+		}
+
+		m := &SendNotificationParams{
+			Event: EventObjectRemovedDelete,
+			NotificationInfo: &data.NotificationInfo{
+				Name:    obj.Name,
+				Version: obj.VersionID,
+			},
+			BktInfo: bktInfo,
+			ReqInfo: reqInfo,
+		}
+		if obj.VersionID == "" && obj.DeleteMarkVersion != "" {
+			m.Event = EventObjectRemovedDeleteMarkerCreated
+			m.NotificationInfo = &data.NotificationInfo{
+				Name:    obj.Name,
+				HashSum: obj.DeleteMarkerEtag,
+			}
+		}
+
+		if err = h.notify(ctx, conf, m); err != nil {
+			h.log.Error("couldn't send notification", zap.String("object", obj.Name), zap.Error(err))
+		}
+	}
+}
+
+// PurgePrefixHandler is an extension permanently removing all versions of all
+// objects with the given prefix on the gateway side. The response is
+// streamed: a Progress element is sent after every processed batch and a
+// Summary (or an Error) element comes last. Nothing is removed if dry-run
+// parameter is true, versions which would be removed are counted as deleted.
+func (h *handler) PurgePrefixHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	prefix := reqInfo.URL.Query().Get("prefix")
+	if prefix == "" {
+		h.logAndSendError(w, "empty prefix to purge", reqInfo, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+		return
+	}
+
+	var dryRun bool
+	if value := reqInfo.URL.Query().Get("dry-run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			h.logAndSendError(w, "invalid dry-run parameter", reqInfo, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+			return
+		}
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	w.Header().Set(api.ContentType, "application/xml")
+	w.WriteHeader(http.StatusOK)
+
+	enc := xml.NewEncoder(w)
+	start := xml.StartElement{Name: xml.Name{Space: "http://s3.amazonaws.com/doc/2006-03-01/", Local: "PurgePrefixResult"}}
+
+	writeElement := func(v any, name string) {
+		if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			h.log.Warn("couldn't write purge response", zap.String("request_id", reqInfo.RequestID), zap.Error(err))
+			return
+		}
+		_ = enc.Flush()
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	_, _ = w.Write([]byte(xml.Header))
+	_ = enc.EncodeToken(start)
+
+	res, err := h.obj.PurgePrefix(r.Context(), &layer.PurgePrefixParams{
+		BktInfo: bktInfo,
+		Prefix:  prefix,
+		DryRun:  dryRun,
+		Progress: func(p layer.PurgeProgress) {
+			writeElement(PurgePrefixProgress(p), "Progress")
+		},
+	})
+	if err != nil {
+		h.log.Error("couldn't purge prefix", zap.String("request_id", reqInfo.RequestID),
+			zap.String("bucket", reqInfo.BucketName), zap.String("prefix", prefix), zap.Error(err))
+
+		var s3Err s3errors.Error
+		if errors.As(transformToS3Error(err), &s3Err) {
+			writeElement(PurgePrefixError{Code: s3Err.Code, Message: s3Err.Description}, "Error")
+		}
+	} else {
+		h.log.Info("prefix is purged", zap.String("request_id", reqInfo.RequestID), zap.String("bucket", reqInfo.BucketName),
+			zap.String("prefix", prefix), zap.Bool("dry_run", dryRun), zap.Uint64("found", res.Found),
+			zap.Uint64("deleted", res.Deleted), zap.Uint64("failed", res.Failed))
+		writeElement(PurgePrefixProgress(*res), "Summary")
+	}
+
+	_ = enc.EncodeToken(start.End())
+	_ = enc.Flush()
+}
+
 func (h *handler) DeleteBucketHandler(w http.ResponseWriter, r *http.Request) {
 	reqInfo := api.GetReqInfo(r.Context())
 	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
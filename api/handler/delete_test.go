@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -243,6 +245,74 @@ func TestDeleteObjectCheckMarkerReturn(t *testing.T) {
 	require.Equal(t, deleteMarkerVersion, deleteMarkerVersion2)
 }
 
+func TestPurgePrefix(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-purge"
+	createVersionedBucketAndObject(t, hc, bktName, "dir/obj1")
+	putObject(t, hc, bktName, "dir/obj1")
+	putObject(t, hc, bktName, "dir/obj2")
+	putObject(t, hc, bktName, "other")
+	deleteObject(t, hc, bktName, "dir/obj2", emptyVersion)
+
+	query := make(url.Values)
+	query.Set("purge", "")
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().PurgePrefixHandler(w, r)
+	assertStatus(t, w, http.StatusBadRequest)
+
+	query.Set("prefix", "dir/")
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().PurgePrefixHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	res := &struct {
+		Progress []PurgePrefixProgress
+		Summary  *PurgePrefixProgress
+		Error    *PurgePrefixError
+	}{}
+	parseTestResponse(t, w, res)
+	require.Nil(t, res.Error)
+	require.Equal(t, &PurgePrefixProgress{Found: 4, Deleted: 4}, res.Summary)
+	require.Len(t, res.Progress, 1)
+
+	versions := listVersions(t, hc, bktName)
+	require.Len(t, versions.Version, 1)
+	require.Empty(t, versions.DeleteMarker)
+	require.Equal(t, "other", versions.Version[0].Key)
+	require.Len(t, listOIDsFromMockedNeoFS(t, hc, bktName), 1)
+}
+
+func TestDeleteObjectUnderGovernance(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-lock-enabled", "object"
+	bktInfo := createTestBucketWithLock(hc, bktName, nil)
+	objInfo := createTestObject(hc, bktInfo, objName)
+
+	retention := &data.Retention{Mode: governanceMode, RetainUntilDate: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}
+	putObjectRetention(hc, bktName, objName, retention, false, 0)
+
+	query := make(url.Values)
+	query.Add(api.QueryVersionID, objInfo.VersionID())
+
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, nil)
+	hc.Handler().DeleteObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+
+	w, r = prepareTestFullRequest(hc, bktName, objName, query, nil)
+	r.Header.Set(api.AmzBypassGovernanceRetention, "invalid")
+	hc.Handler().DeleteObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	w, r = prepareTestFullRequest(hc, bktName, objName, query, nil)
+	r.Header.Set(api.AmzBypassGovernanceRetention, "true")
+	hc.Handler().DeleteObjectHandler(w, r)
+	assertStatus(t, w, http.StatusNoContent)
+
+	checkNotFound(t, hc, bktName, objName, objInfo.VersionID())
+}
+
 func createBucketAndObject(tc *handlerContext, bktName, objName string) (*data.BucketInfo, *data.ObjectInfo) {
 	bktInfo := createTestBucket(tc, bktName)
 
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+func (h *handler) PutBucketDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	deletion := &data.DeletionConfiguration{}
+	if err = xml.NewDecoder(r.Body).Decode(deletion); err != nil {
+		h.logAndSendError(w, "couldn't parse deletion configuration", reqInfo, s3errors.GetAPIError(s3errors.ErrMalformedXML))
+		return
+	}
+
+	if err = checkDeletionConfiguration(deletion); err != nil {
+		h.logAndSendError(w, "invalid deletion configuration", reqInfo, err)
+		return
+	}
+
+	if err = h.updateDeletion(r, bktInfo, deletion); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+}
+
+func (h *handler) GetBucketDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get bucket settings", reqInfo, err)
+		return
+	}
+
+	if settings.Deletion == nil {
+		h.logAndSendError(w, "deletion configuration not found", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+		return
+	}
+
+	if err = api.EncodeToResponse(w, settings.Deletion); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) DeleteBucketDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = h.updateDeletion(r, bktInfo, nil); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UndeleteObjectHandler is an extension restoring the object version deleted
+// in a bucket with soft deletion. The most recently deleted version is
+// restored unless versionId is specified.
+func (h *handler) UndeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	version, err := h.obj.UndeleteObject(r.Context(), &layer.UndeleteObjectParams{
+		BktInfo:   bktInfo,
+		Object:    reqInfo.ObjectName,
+		VersionID: reqInfo.URL.Query().Get(api.QueryVersionID),
+	})
+	if err != nil {
+		h.logAndSendError(w, "couldn't undelete object", reqInfo, err)
+		return
+	}
+
+	versionID := version.OID.EncodeToString()
+	if version.IsUnversioned {
+		versionID = data.UnversionedObjectVersionID
+	}
+	w.Header().Set(api.AmzVersionID, versionID)
+}
+
+func checkDeletionConfiguration(deletion *data.DeletionConfiguration) error {
+	switch deletion.Mode {
+	case data.DeletionTombstone:
+		if deletion.UndeleteWindowDays != 0 {
+			return fmt.Errorf("%w: undelete window is allowed in %s mode only",
+				s3errors.GetAPIError(s3errors.ErrInvalidArgument), data.DeletionSoft)
+		}
+	case data.DeletionSoft:
+		if deletion.UndeleteWindowDays <= 0 {
+			return fmt.Errorf("%w: undelete window must be positive",
+				s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode '%s'", s3errors.GetAPIError(s3errors.ErrInvalidArgument), deletion.Mode)
+	}
+
+	return nil
+}
+
+func (h *handler) updateDeletion(r *http.Request, bktInfo *data.BucketInfo, deletion *data.DeletionConfiguration) error {
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return err
+	}
+
+	// settings pointer is stored in the cache, so modify a copy of the settings
+	newSettings := *settings
+	newSettings.Deletion = deletion
+
+	return h.obj.PutBucketSettings(r.Context(), &layer.PutSettingsParams{
+		BktInfo:  bktInfo,
+		Settings: &newSettings,
+	})
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketDeletionConfiguration(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-deletion"
+	createTestBucket(hc, bktName)
+
+	query := make(url.Values)
+	query.Set("deletion", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketDeletionHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+
+	for _, cfg := range []*data.DeletionConfiguration{
+		{Mode: "Unknown"},
+		{Mode: data.DeletionSoft},
+		{Mode: data.DeletionTombstone, UndeleteWindowDays: 1},
+	} {
+		w, r = prepareTestFullRequest(hc, bktName, "", query, cfg)
+		hc.Handler().PutBucketDeletionHandler(w, r)
+		assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+	}
+
+	putBucketDeletion(t, hc, bktName, &data.DeletionConfiguration{Mode: data.DeletionSoft, UndeleteWindowDays: 7})
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketDeletionHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	actual := &data.DeletionConfiguration{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(actual))
+	require.Equal(t, data.DeletionSoft, actual.Mode)
+	require.Equal(t, 7, actual.UndeleteWindowDays)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().DeleteBucketDeletionHandler(w, r)
+	assertStatus(t, w, http.StatusNoContent)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketDeletionHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+}
+
+func TestSoftDeleteAndUndelete(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-soft-delete", "object"
+	bktInfo, objInfo := createBucketAndObject(hc, bktName, objName)
+	putBucketDeletion(t, hc, bktName, &data.DeletionConfiguration{Mode: data.DeletionSoft, UndeleteWindowDays: 1})
+
+	deleteObject(t, hc, bktName, objName, emptyVersion)
+	checkNotFound(t, hc, bktName, objName, emptyVersion)
+	require.True(t, existInMockedNeoFS(hc, bktInfo, objInfo))
+
+	versionID := undeleteObject(t, hc, bktName, objName, emptyVersion, http.StatusOK)
+	require.Equal(t, data.UnversionedObjectVersionID, versionID)
+	checkFound(t, hc, bktName, objName, emptyVersion)
+
+	undeleteObject(t, hc, bktName, objName, emptyVersion, http.StatusNotFound)
+
+	putObject(t, hc, bktName, objName)
+	deleteObject(t, hc, bktName, objName, emptyVersion)
+	putObject(t, hc, bktName, objName)
+	undeleteObject(t, hc, bktName, objName, emptyVersion, http.StatusForbidden)
+
+	// the undelete window has passed for the trashed version
+	ctx := context.WithValue(hc.Context(), api.ClientTime, time.Now().Add(48*time.Hour))
+	entries, err := hc.Layer().CleanupTrash(ctx, bktInfo, true)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Len(t, listOIDsFromMockedNeoFS(t, hc, bktName), 2)
+
+	entries, err = hc.Layer().CleanupTrash(ctx, bktInfo, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, entries[0].Err)
+	require.Len(t, listOIDsFromMockedNeoFS(t, hc, bktName), 1)
+
+	entries, err = hc.Layer().CleanupTrash(ctx, bktInfo, false)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSoftDeleteVersioned(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-soft-delete-versioned", "object"
+	bktInfo, objInfo := createVersionedBucketAndObject(t, hc, bktName, objName)
+	putBucketDeletion(t, hc, bktName, &data.DeletionConfiguration{Mode: data.DeletionSoft, UndeleteWindowDays: 1})
+
+	deleteObject(t, hc, bktName, objName, objInfo.VersionID())
+	checkNotFound(t, hc, bktName, objName, objInfo.VersionID())
+	require.True(t, existInMockedNeoFS(hc, bktInfo, objInfo))
+
+	undeleteObject(t, hc, bktName, objName, "unknown", http.StatusNotFound)
+	versionID := undeleteObject(t, hc, bktName, objName, objInfo.VersionID(), http.StatusOK)
+	require.Equal(t, objInfo.VersionID(), versionID)
+	checkFound(t, hc, bktName, objName, objInfo.VersionID())
+}
+
+func TestTombstoneDeletion(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-tombstone", "object"
+	bktInfo, objInfo := createBucketAndObject(hc, bktName, objName)
+	putBucketDeletion(t, hc, bktName, &data.DeletionConfiguration{Mode: data.DeletionTombstone})
+
+	deleteObject(t, hc, bktName, objName, emptyVersion)
+	require.False(t, existInMockedNeoFS(hc, bktInfo, objInfo))
+	undeleteObject(t, hc, bktName, objName, emptyVersion, http.StatusNotFound)
+}
+
+func putBucketDeletion(t *testing.T, hc *handlerContext, bktName string, cfg *data.DeletionConfiguration) {
+	query := make(url.Values)
+	query.Set("deletion", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, cfg)
+	hc.Handler().PutBucketDeletionHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+}
+
+func undeleteObject(t *testing.T, hc *handlerContext, bktName, objName, version string, code int) string {
+	query := make(url.Values)
+	query.Set("undelete", "")
+	if version != "" {
+		query.Set(api.QueryVersionID, version)
+	}
+
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, nil)
+	hc.Handler().UndeleteObjectHandler(w, r)
+	assertStatus(t, w, code)
+
+	return w.Header().Get(api.AmzVersionID)
+}
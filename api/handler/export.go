@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"go.uber.org/zap"
+)
+
+// exportPageSize is a number of objects listed at once by ExportObjectsHandler.
+const exportPageSize = 1000
+
+type (
+	// ExportedObject is a line of ExportObjectsHandler response.
+	ExportedObject struct {
+		Key          string            `json:"key"`
+		Size         int64             `json:"size"`
+		ETag         string            `json:"etag"`
+		LastModified time.Time         `json:"lastModified"`
+		ContentType  string            `json:"contentType,omitempty"`
+		Metadata     map[string]string `json:"metadata,omitempty"`
+	}
+
+	// ExportError is the last line of ExportObjectsHandler response if the
+	// listing is interrupted, it can be resumed after the last exported key.
+	ExportError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+)
+
+// ExportObjectsHandler streams the listing of latest object versions as
+// newline delimited JSON. The listing starts after the key from cursor query
+// parameter, so the interrupted export can be resumed. Only the bucket owner
+// is allowed to export the listing.
+func (h *handler) ExportObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if !h.cfg.ListingExport {
+		h.logAndSendError(w, "listing export is disabled", reqInfo, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+		return
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = checkRequesterIsOwner(r, bktInfo); err != nil {
+		h.logAndSendError(w, "listing export is allowed to the bucket owner only", reqInfo, err)
+		return
+	}
+
+	query := reqInfo.URL.Query()
+	params := &layer.ListObjectsParamsV2{
+		ListObjectsParamsCommon: layer.ListObjectsParamsCommon{
+			BktInfo: bktInfo,
+			MaxKeys: exportPageSize,
+			Prefix:  query.Get("prefix"),
+		},
+		StartAfter: query.Get("cursor"),
+	}
+
+	list, err := h.obj.ListObjectsV2(r.Context(), params)
+	if err != nil {
+		h.logAndSendError(w, "couldn't list objects", reqInfo, err)
+		return
+	}
+
+	w.Header().Set(api.ContentType, "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for {
+		for _, obj := range list.Objects {
+			if err = enc.Encode(exportedObject(obj)); err != nil {
+				h.log.Warn("couldn't write listing export", zap.String("request_id", reqInfo.RequestID), zap.Error(err))
+				return
+			}
+			params.StartAfter = obj.Name
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		if !list.IsTruncated {
+			return
+		}
+
+		if list, err = h.obj.ListObjectsV2(r.Context(), params); err != nil {
+			break
+		}
+	}
+
+	h.log.Error("couldn't export listing", zap.String("request_id", reqInfo.RequestID),
+		zap.String("bucket", reqInfo.BucketName), zap.String("cursor", params.StartAfter), zap.Error(err))
+
+	var s3Err s3errors.Error
+	if errors.As(transformToS3Error(err), &s3Err) {
+		_ = enc.Encode(map[string]ExportError{"error": {Code: s3Err.Code, Message: s3Err.Description}})
+	}
+}
+
+// checkRequesterIsOwner returns AccessDenied error if the request isn't signed
+// by the bucket owner.
+func checkRequesterIsOwner(r *http.Request, bktInfo *data.BucketInfo) error {
+	box, err := layer.GetBoxData(r.Context())
+	if err != nil || box.Gate.BearerToken == nil || !box.Gate.BearerToken.ResolveIssuer().Equals(bktInfo.Owner) {
+		return s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	return nil
+}
+
+func exportedObject(obj *data.ObjectInfo) ExportedObject {
+	res := ExportedObject{
+		Key:          obj.Name,
+		Size:         obj.Size,
+		ETag:         obj.HashSum,
+		LastModified: obj.Created.UTC(),
+		ContentType:  obj.ContentType,
+	}
+
+	for key, val := range obj.Headers {
+		if layer.IsSystemHeader(key) {
+			continue
+		}
+		if res.Metadata == nil {
+			res.Metadata = make(map[string]string)
+		}
+		res.Metadata[key] = val
+	}
+
+	return res
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportObjects(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-export"
+	createTestBucket(hc, bktName)
+
+	w, r := prepareTestPayloadRequest(hc, bktName, "dir/obj1", strings.NewReader("content"))
+	r.Header.Set(api.MetadataPrefix+"Key", "val")
+	r.Header.Set(api.ContentType, "text/plain")
+	hc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	putObject(t, hc, bktName, "dir/obj2")
+	putObject(t, hc, bktName, "other")
+
+	export := func(query url.Values) []ExportedObject {
+		query.Set("export", "")
+		w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+		hc.Handler().ExportObjectsHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		require.Equal(t, "application/x-ndjson", w.Header().Get(api.ContentType))
+
+		var res []ExportedObject
+		dec := json.NewDecoder(w.Result().Body)
+		for dec.More() {
+			var obj ExportedObject
+			require.NoError(t, dec.Decode(&obj))
+			res = append(res, obj)
+		}
+		return res
+	}
+
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"export": []string{""}}, nil)
+	hc.Handler().ExportObjectsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+
+	hc.Handler().cfg.ListingExport = true
+
+	res := export(make(url.Values))
+	require.Len(t, res, 3)
+	require.Equal(t, "dir/obj1", res[0].Key)
+	require.EqualValues(t, len("content"), res[0].Size)
+	require.NotEmpty(t, res[0].ETag)
+	require.False(t, res[0].LastModified.IsZero())
+	require.Equal(t, "text/plain", res[0].ContentType)
+	require.Equal(t, map[string]string{"key": "val"}, res[0].Metadata)
+	require.Equal(t, "other", res[2].Key)
+
+	// export is resumed after the cursor
+	res = export(url.Values{"cursor": []string{"dir/obj1"}})
+	require.Len(t, res, 2)
+	require.Equal(t, "dir/obj2", res[0].Key)
+
+	res = export(url.Values{"prefix": []string{"dir/"}})
+	require.Len(t, res, 2)
+
+	// only the bucket owner can export the listing
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"export": []string{""}}, nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, newTestAccessBox(t, nil)))
+	hc.Handler().ExportObjectsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+}
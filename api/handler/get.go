@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"go.uber.org/zap"
 )
@@ -20,6 +22,7 @@ type conditionalArgs struct {
 	IfUnmodifiedSince *time.Time
 	IfMatch           string
 	IfNoneMatch       string
+	IfRange           string
 }
 
 func fetchRangeHeader(headers http.Header, fullSize uint64) (*layer.RangeParams, error) {
@@ -32,11 +35,11 @@ func fetchRangeHeader(headers http.Header, fullSize uint64) (*layer.RangeParams,
 		return nil, s3errors.GetAPIError(s3errors.ErrInvalidRange)
 	}
 	if !strings.HasPrefix(rangeHeader, prefix) {
-		return nil, fmt.Errorf("unknown unit in range header")
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidRange)
 	}
 	arr := strings.Split(strings.TrimPrefix(rangeHeader, prefix), "-")
 	if len(arr) != 2 || (len(arr[0]) == 0 && len(arr[1]) == 0) {
-		return nil, fmt.Errorf("unknown byte-range-set")
+		return nil, s3errors.GetAPIError(s3errors.ErrInvalidRange)
 	}
 
 	var end, start uint64
@@ -45,7 +48,14 @@ func fetchRangeHeader(headers http.Header, fullSize uint64) (*layer.RangeParams,
 
 	if len(arr[0]) == 0 {
 		end, err1 = strconv.ParseUint(arr[1], base, bitSize)
-		start = fullSize - end
+		if end == 0 {
+			// the last zero bytes can't be satisfied
+			return nil, s3errors.GetAPIError(s3errors.ErrInvalidRange)
+		}
+		// suffix longer than the object selects the whole object
+		if end < fullSize {
+			start = fullSize - end
+		}
 		end = fullSize - 1
 	} else if len(arr[1]) == 0 {
 		start, err0 = strconv.ParseUint(arr[0], base, bitSize)
@@ -64,6 +74,8 @@ func fetchRangeHeader(headers http.Header, fullSize uint64) (*layer.RangeParams,
 	return &layer.RangeParams{Start: start, End: end}, nil
 }
 
+// overrideResponseHeaders replaces response headers with values of response-*
+// query parameters. It must be called after all object headers are written.
 func overrideResponseHeaders(h http.Header, query url.Values) {
 	for key, value := range query {
 		if hdr, ok := api.ResponseModifiers[strings.ToLower(key)]; ok {
@@ -92,6 +104,7 @@ func writeHeaders(h http.Header, requestHeader http.Header, extendedInfo *data.E
 	}
 
 	h.Set(api.ETag, info.HashSum)
+	h.Set(api.AcceptRanges, "bytes")
 	h.Set(api.AmzTaggingCount, strconv.Itoa(tagSetLength))
 
 	if !isBucketUnversioned {
@@ -161,19 +174,26 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fullSize := info.Size
-	if encryptionParams.Enabled() {
-		if fullSize, err = strconv.ParseInt(info.Headers[layer.AttributeDecryptedSize], 10, 64); err != nil {
-			h.logAndSendError(w, "invalid decrypted size header", reqInfo, s3errors.GetAPIError(s3errors.ErrBadRequest))
-			return
-		}
+	fullSize, err := payloadSize(info, encryptionParams)
+	if err != nil {
+		h.logAndSendError(w, "invalid decrypted size header", reqInfo, err)
+		return
 	}
 
-	if params, err = fetchRangeHeader(r.Header, uint64(fullSize)); err != nil {
+	// range is checked against the object size before any payload request
+	if params, err = fetchObjectRange(r.Header, info, conditional, fullSize); err != nil {
+		writeUnsatisfiedRangeHeaders(w, err, fullSize)
 		h.logAndSendError(w, "could not parse range header", reqInfo, err)
 		return
 	}
 
+	verifyChecksum := strings.EqualFold(r.Header.Get(api.VerifyChecksum), "true")
+	if verifyChecksum && (params != nil || reqInfo.URL.Query().Has(api.QueryTransform) || !layer.ChecksumVerifiable(info)) {
+		h.logAndSendError(w, "checksum can't be verified", reqInfo,
+			fmt.Errorf("%w: checksum of ranges, transformed and compressed objects can't be verified", s3errors.GetAPIError(s3errors.ErrInvalidRequest)))
+		return
+	}
+
 	t := &layer.ObjectVersion{
 		BktInfo:    bktInfo,
 		ObjectName: info.Name,
@@ -186,10 +206,6 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if layer.IsAuthenticatedRequest(r.Context()) {
-		overrideResponseHeaders(w.Header(), reqInfo.URL.Query())
-	}
-
 	if err = h.setLockingHeaders(bktInfo, lockInfo, w.Header()); err != nil {
 		h.logAndSendError(w, "could not get locking info", reqInfo, err)
 		return
@@ -201,36 +217,81 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if spec := reqInfo.URL.Query().Get(api.QueryTransform); spec != "" {
+		transformed, err := h.transformObject(r.Context(), spec, params, bktInfo, info, encryptionParams)
+		if err != nil {
+			h.logAndSendError(w, "could not transform object", reqInfo, err)
+			return
+		}
+
+		w.Header().Set(api.ContentType, transformed.ContentType)
+		writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
+		w.Header().Set(api.ContentLength, strconv.Itoa(len(transformed.Payload)))
+		w.Header().Set(api.ETag, transformed.HashSum)
+		w.Header().Set(api.AcceptRanges, "none")
+		if layer.IsAuthenticatedRequest(r.Context()) {
+			overrideResponseHeaders(w.Header(), reqInfo.URL.Query())
+		}
+		w.WriteHeader(http.StatusOK)
+
+		if _, err = w.Write(transformed.Payload); err != nil {
+			h.logAndSendError(w, "could not write transformed object", reqInfo, err)
+		}
+		return
+	}
+
+	contentType, err := h.objectContentType(r.Context(), bktInfo, info, encryptionParams, fullSize)
+	if err != nil {
+		h.logAndSendError(w, "could not detect content type", reqInfo, err, zap.Stringer("oid", info.ID))
+		return
+	}
+	w.Header().Set(api.ContentType, contentType)
+
 	writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
+	if layer.IsAuthenticatedRequest(r.Context()) {
+		overrideResponseHeaders(w.Header(), reqInfo.URL.Query())
+	}
 	if params != nil {
-		writeRangeHeaders(w, params, info.Size)
+		writeRangeHeaders(w, params, fullSize)
 	} else {
 		w.WriteHeader(http.StatusOK)
 	}
 
 	getParams := &layer.GetObjectParams{
-		ObjectInfo: info,
-		Writer:     w,
-		Range:      params,
-		BucketInfo: bktInfo,
-		Encryption: encryptionParams,
+		ObjectInfo:     info,
+		Writer:         w,
+		Range:          params,
+		BucketInfo:     bktInfo,
+		Encryption:     encryptionParams,
+		VerifyChecksum: verifyChecksum,
 	}
 	if err = h.obj.GetObject(r.Context(), getParams); err != nil {
+		if verifyChecksum && errors.Is(err, s3errors.GetAPIError(s3errors.ErrChecksumMismatch)) {
+			h.log.Error("stored checksum mismatch, response is aborted",
+				zap.String("request_id", reqInfo.RequestID), zap.String("bucket", reqInfo.BucketName),
+				zap.String("object", reqInfo.ObjectName), zap.Stringer("oid", info.ID))
+			// the payload is partially sent, so the client can detect the
+			// error only by the aborted connection
+			panic(http.ErrAbortHandler)
+		}
 		h.logAndSendError(w, "could not get object", reqInfo, err)
 	}
 }
 
 func checkPreconditions(info *data.ObjectInfo, args *conditionalArgs) error {
-	if len(args.IfMatch) > 0 && args.IfMatch != info.HashSum {
+	// dates of headers have second precision
+	modified := info.Created.Truncate(time.Second)
+
+	if len(args.IfMatch) > 0 && !matchETag(info.HashSum, args.IfMatch) {
 		return s3errors.GetAPIError(s3errors.ErrPreconditionFailed)
 	}
-	if len(args.IfNoneMatch) > 0 && args.IfNoneMatch == info.HashSum {
+	if len(args.IfNoneMatch) > 0 && matchETag(info.HashSum, args.IfNoneMatch) {
 		return s3errors.GetAPIError(s3errors.ErrNotModified)
 	}
-	if args.IfModifiedSince != nil && info.Created.Before(*args.IfModifiedSince) {
+	if args.IfModifiedSince != nil && !modified.After(*args.IfModifiedSince) {
 		return s3errors.GetAPIError(s3errors.ErrNotModified)
 	}
-	if args.IfUnmodifiedSince != nil && info.Created.After(*args.IfUnmodifiedSince) {
+	if args.IfUnmodifiedSince != nil && modified.After(*args.IfUnmodifiedSince) {
 		if len(args.IfMatch) == 0 {
 			return s3errors.GetAPIError(s3errors.ErrPreconditionFailed)
 		}
@@ -239,11 +300,77 @@ func checkPreconditions(info *data.ObjectInfo, args *conditionalArgs) error {
 	return nil
 }
 
+// matchETag checks whether the ETag matches the value of If-Match or
+// If-None-Match header: a list of ETags, quoted or not, or "*".
+func matchETag(etag, header string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.Trim(strings.TrimPrefix(value, "W/"), "\"") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// payloadSize returns the size of the object payload sent to clients, it's
+// the size of decrypted payload for encrypted objects.
+func payloadSize(info *data.ObjectInfo, enc encryption.Params) (int64, error) {
+	if !enc.Enabled() {
+		return info.Size, nil
+	}
+
+	size, err := strconv.ParseInt(info.Headers[layer.AttributeDecryptedSize], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrBadRequest), err.Error())
+	}
+
+	return size, nil
+}
+
+// fetchObjectRange returns the range requested with Range header. The range
+// is ignored if If-Range validator doesn't match the object.
+func fetchObjectRange(headers http.Header, info *data.ObjectInfo, conditional *conditionalArgs, fullSize int64) (*layer.RangeParams, error) {
+	if len(conditional.IfRange) > 0 && !checkIfRange(info, conditional.IfRange) {
+		return nil, nil
+	}
+
+	return fetchRangeHeader(headers, uint64(fullSize))
+}
+
+// writeUnsatisfiedRangeHeaders sets Content-Range header of the response to
+// the range which can't be satisfied, so clients can request a valid one.
+func writeUnsatisfiedRangeHeaders(w http.ResponseWriter, err error, size int64) {
+	if s3errors.IsS3Error(err, s3errors.ErrInvalidRange) {
+		w.Header().Set(api.ContentRange, "bytes */"+strconv.FormatInt(size, 10))
+	}
+}
+
+// checkIfRange reports whether the If-Range validator matches the object, so
+// the requested range can be sent. Otherwise, the object was changed and the
+// whole object must be sent. Weak ETags never match.
+func checkIfRange(info *data.ObjectInfo, ifRange string) bool {
+	if strings.HasPrefix(ifRange, "W/") {
+		return false
+	}
+	if strings.Trim(ifRange, "\"") == info.HashSum {
+		return true
+	}
+
+	date, err := time.Parse(http.TimeFormat, ifRange)
+	if err != nil {
+		return false
+	}
+
+	return info.Created.Truncate(time.Second).Equal(date)
+}
+
 func parseConditionalHeaders(headers http.Header) (*conditionalArgs, error) {
 	var err error
 	args := &conditionalArgs{
 		IfMatch:     headers.Get(api.IfMatch),
 		IfNoneMatch: headers.Get(api.IfNoneMatch),
+		IfRange:     headers.Get(api.IfRange),
 	}
 
 	if args.IfModifiedSince, err = parseHTTPTime(headers.Get(api.IfModifiedSince)); err != nil {
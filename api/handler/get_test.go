@@ -3,15 +3,24 @@ package handler
 import (
 	"bytes"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/transform"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestFetchRangeHeader(t *testing.T) {
@@ -26,6 +35,9 @@ func TestFetchRangeHeader(t *testing.T) {
 		{header: "bytes=0-256", expected: &layer.RangeParams{Start: 0, End: 255}, fullSize: 256, err: false},
 		{header: "bytes=0-", expected: &layer.RangeParams{Start: 0, End: 99}, fullSize: 100, err: false},
 		{header: "bytes=-10", expected: &layer.RangeParams{Start: 90, End: 99}, fullSize: 100, err: false},
+		{header: "bytes=-200", expected: &layer.RangeParams{Start: 0, End: 99}, fullSize: 100, err: false},
+		{header: "bytes=-0", fullSize: 100, err: true},
+		{header: "bytes=100-", fullSize: 100, err: true},
 		{header: "", err: false},
 		{header: "bytes=-1-256", err: true},
 		{header: "bytes=256-0", err: true},
@@ -59,6 +71,7 @@ func newInfo(etag string, created time.Time) *data.ObjectInfo {
 func TestPreconditions(t *testing.T) {
 	today := time.Now()
 	yesterday := today.Add(-24 * time.Hour)
+	second := today.Truncate(time.Second)
 	etag := "etag"
 	etag2 := "etag2"
 
@@ -116,6 +129,30 @@ func TestPreconditions(t *testing.T) {
 			args:     &conditionalArgs{IfUnmodifiedSince: &yesterday},
 			expected: s3errors.GetAPIError(s3errors.ErrPreconditionFailed)},
 
+		{
+			name:     "IfMatch quoted list",
+			info:     newInfo(etag, today),
+			args:     &conditionalArgs{IfMatch: "\"" + etag2 + "\", \"" + etag + "\""},
+			expected: nil,
+		},
+		{
+			name:     "IfNoneMatch any",
+			info:     newInfo(etag, today),
+			args:     &conditionalArgs{IfNoneMatch: "*"},
+			expected: s3errors.GetAPIError(s3errors.ErrNotModified),
+		},
+		{
+			name:     "IfModifiedSince the same second",
+			info:     newInfo(etag, second.Add(time.Millisecond)),
+			args:     &conditionalArgs{IfModifiedSince: &second},
+			expected: s3errors.GetAPIError(s3errors.ErrNotModified),
+		},
+		{
+			name:     "IfUnmodifiedSince the same second",
+			info:     newInfo(etag, second.Add(time.Millisecond)),
+			args:     &conditionalArgs{IfUnmodifiedSince: &second},
+			expected: nil,
+		},
 		{
 			name:     "IfMatch true, IfUnmodifiedSince false",
 			info:     newInfo(etag, today),
@@ -170,6 +207,97 @@ func TestGetRange(t *testing.T) {
 	require.Equal(t, "bcdef", string(end))
 }
 
+func TestGetIfRange(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-range", "object-to-range"
+	createTestBucket(hc, bktName)
+
+	content := "123456789abcdef"
+	putObjectContent(hc, bktName, objName, content)
+
+	w, r := prepareTestRequest(hc, bktName, objName, nil)
+	hc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	etag, lastModified := w.Header().Get(api.ETag), w.Header().Get(api.LastModified)
+
+	modified, err := time.Parse(http.TimeFormat, lastModified)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		ifRange string
+		partial bool
+	}{
+		{name: "etag", ifRange: etag, partial: true},
+		{name: "quoted etag", ifRange: "\"" + etag + "\"", partial: true},
+		{name: "weak etag", ifRange: "W/\"" + etag + "\""},
+		{name: "other etag", ifRange: "\"0123456789abcdef0123456789abcdef\""},
+		{name: "date", ifRange: lastModified, partial: true},
+		{name: "earlier date", ifRange: modified.Add(-time.Hour).Format(http.TimeFormat)},
+		{name: "later date", ifRange: modified.Add(time.Hour).Format(http.TimeFormat)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w, r := prepareTestRequest(hc, bktName, objName, nil)
+			r.Header.Set("Range", "bytes=5-10")
+			r.Header.Set(api.IfRange, tc.ifRange)
+			hc.Handler().GetObjectHandler(w, r)
+
+			payload, err := io.ReadAll(w.Result().Body)
+			require.NoError(t, err)
+			if tc.partial {
+				assertStatus(t, w, http.StatusPartialContent)
+				require.Equal(t, "6789ab", string(payload))
+			} else {
+				assertStatus(t, w, http.StatusOK)
+				require.Equal(t, content, string(payload))
+			}
+		})
+	}
+}
+
+func TestGetTransformed(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-transform", "image.png"
+	createTestBucket(hc, bktName)
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	putObjectContent(hc, bktName, objName, buf.String())
+
+	query := make(url.Values)
+	query.Set(api.QueryTransform, "resize:20x20")
+
+	w, r := prepareTestFullRequest(hc, bktName, objName, query, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+
+	hc.h.cfg.Transform = transform.NewPipeline(&transform.Config{Cache: cache.DefaultTransformedConfig(zap.NewNop())})
+
+	w, r = prepareTestFullRequest(hc, bktName, objName, query, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, "image/png", w.Header().Get(api.ContentType))
+	require.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get(api.ContentLength))
+	cfg, err := png.DecodeConfig(w.Body)
+	require.NoError(t, err)
+	require.Equal(t, 20, cfg.Width)
+	require.Equal(t, 10, cfg.Height)
+
+	query.Set(api.QueryTransform, "rotate:90")
+	w, r = prepareTestFullRequest(hc, bktName, objName, query, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	putObjectContent(hc, bktName, "text", "not an image")
+	query.Set(api.QueryTransform, "resize:20x20")
+	w, r = prepareTestFullRequest(hc, bktName, "text", query, nil)
+	hc.Handler().GetObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidRequest))
+}
+
 func putObjectContent(hc *handlerContext, bktName, objName, content string) {
 	body := bytes.NewReader([]byte(content))
 	w, r := prepareTestPayloadRequest(hc, bktName, objName, body)
@@ -177,6 +305,30 @@ func putObjectContent(hc *handlerContext, bktName, objName, content string) {
 	assertStatus(hc.t, w, http.StatusOK)
 }
 
+func TestGetUnsatisfiedRange(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-range", "object-to-range"
+	createTestBucket(tc, bktName)
+	putObjectContent(tc, bktName, objName, "123456789abcdef")
+
+	for _, rng := range []string{"bytes=15-", "bytes=20-30", "bytes=-0", "items=0-1"} {
+		t.Run(rng, func(t *testing.T) {
+			w, r := prepareTestRequest(tc, bktName, objName, nil)
+			r.Header.Set("Range", rng)
+			tc.Handler().GetObjectHandler(w, r)
+			assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidRange))
+			require.Equal(t, "bytes */15", w.Header().Get(api.ContentRange))
+
+			w, r = prepareTestRequest(tc, bktName, objName, nil)
+			r.Header.Set("Range", rng)
+			tc.Handler().HeadObjectHandler(w, r)
+			assertStatus(t, w, http.StatusRequestedRangeNotSatisfiable)
+			require.Equal(t, "bytes */15", w.Header().Get(api.ContentRange))
+		})
+	}
+}
+
 func getObjectRange(t *testing.T, tc *handlerContext, bktName, objName string, start, end int) []byte {
 	w, r := prepareTestRequest(tc, bktName, objName, nil)
 	r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
@@ -186,3 +338,43 @@ func getObjectRange(t *testing.T, tc *handlerContext, bktName, objName string, s
 	require.NoError(t, err)
 	return content
 }
+
+func TestGetVerifyChecksum(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-checksum", "object-to-verify"
+	createTestBucket(hc, bktName)
+
+	content := "123456789abcdef"
+	putObjectContent(hc, bktName, objName, content)
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		w, r := prepareTestRequest(hc, bktName, objName, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		hc.Handler().GetObjectHandler(w, r)
+		return w
+	}
+
+	w := get(map[string]string{api.VerifyChecksum: "true"})
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, content, w.Body.String())
+
+	w = get(map[string]string{api.VerifyChecksum: "true", "Range": "bytes=0-3"})
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidRequest))
+
+	for _, obj := range hc.tp.Objects() {
+		if string(obj.Payload()) == content {
+			obj.SetPayload([]byte("123456789abcdeF"))
+		}
+	}
+
+	// corrupted payload is sent as is without verification
+	w = get(nil)
+	assertStatus(t, w, http.StatusOK)
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		get(map[string]string{api.VerifyChecksum: "true"})
+	})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// DefaultGzipMaxBufferedSize is a default size limit of decompressed payloads
+// of unknown size spooled to temporary files.
+const DefaultGzipMaxBufferedSize = 64 << 20
+
+const contentEncodingGzip = "gzip"
+
+// gzipReader decompresses the payload, it fails if the decompressed size
+// differs from the declared one or the payload is corrupted.
+type gzipReader struct {
+	r *gzip.Reader
+	// left is negative if the size isn't declared
+	left int64
+}
+
+// isGzipEncoded checks whether the payload is compressed with gzip, other
+// content encodings except aws-chunked aren't supported with it.
+func isGzipEncoded(header http.Header) (bool, error) {
+	var gzipped, other bool
+	for _, enc := range strings.Split(header.Get(api.ContentEncoding), ",") {
+		switch enc = strings.ToLower(strings.TrimSpace(enc)); enc {
+		case "", auth.ContentEncodingAwsChunked:
+		case contentEncodingGzip, "x-gzip":
+			gzipped = true
+		default:
+			other = true
+		}
+	}
+
+	if gzipped && other {
+		return false, fmt.Errorf("%w: only gzip content encoding is supported with gzip", s3errors.GetAPIError(s3errors.ErrInvalidEncodingMethod))
+	}
+
+	return gzipped, nil
+}
+
+// decompressPayload replaces gzip compressed payload of the request with the
+// decompressed one and returns its size and the function releasing it. The
+// payload is decompressed while it's uploaded if X-Decompressed-Content-Length
+// header declares the size, otherwise it's spooled to a temporary file up to
+// the configured limit, so memory isn't taken by payloads of unknown size.
+// Content type of gzip files is dropped, so it's detected by the decompressed
+// payload.
+func (h *handler) decompressPayload(r *http.Request, metadata map[string]string) (io.Reader, int64, func(), error) {
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, 0, nil, gzipError(err)
+	}
+
+	switch strings.ToLower(metadata[api.ContentType]) {
+	case "application/gzip", "application/x-gzip":
+		delete(metadata, api.ContentType)
+	}
+
+	if declared := r.Header.Get(api.DecompressedContentLength); declared != "" {
+		size, err := strconv.ParseInt(declared, 10, 64)
+		if err != nil || size < 0 {
+			return nil, 0, nil, fmt.Errorf("%w: invalid %s header", s3errors.GetAPIError(s3errors.ErrInvalidArgument), api.DecompressedContentLength)
+		}
+
+		return &gzipReader{r: gz, left: size}, size, func() {}, nil
+	}
+
+	maxSize := h.cfg.GzipMaxBufferedSize
+	if maxSize <= 0 {
+		maxSize = DefaultGzipMaxBufferedSize
+	}
+
+	f, err := os.CreateTemp("", "s3-gw-gzip-*")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("create temporary file: %w", err)
+	}
+	release := func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	size, err := io.Copy(f, io.LimitReader(&gzipReader{r: gz, left: -1}, maxSize+1))
+	if err != nil {
+		release()
+		return nil, 0, nil, err
+	}
+	if size > maxSize {
+		release()
+		return nil, 0, nil, fmt.Errorf("%w: decompressed payload is larger than %d bytes, %s header must be set",
+			s3errors.GetAPIError(s3errors.ErrEntityTooLarge), maxSize, api.DecompressedContentLength)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		release()
+		return nil, 0, nil, fmt.Errorf("rewind temporary file: %w", err)
+	}
+
+	return f, size, release, nil
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.left >= 0 {
+		r.left -= int64(n)
+		if r.left < 0 || (err == io.EOF && r.left != 0) {
+			return n, fmt.Errorf("%w: decompressed payload size differs from %s",
+				s3errors.GetAPIError(s3errors.ErrIncompleteBody), api.DecompressedContentLength)
+		}
+	}
+
+	if err != io.EOF {
+		err = gzipError(err)
+	}
+
+	return n, err
+}
+
+// gzipError reports corrupted payloads with InvalidArgument error, errors of
+// the request body are returned as is.
+func gzipError(err error) error {
+	var corrupted flate.CorruptInputError
+	if errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &corrupted) {
+		return fmt.Errorf("%w: invalid gzip payload: %s", s3errors.GetAPIError(s3errors.ErrInvalidEncodingMethod), err)
+	}
+
+	return err
+}
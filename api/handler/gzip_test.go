@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutObjectGzip(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	bktName := "bucket-for-gzip"
+	createTestBucket(hc, bktName)
+
+	content := bytes.Repeat([]byte("log line\n"), 100)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	compressed := buf.Bytes()
+
+	send := func(objName string, payload []byte, headers map[string]string) *httptest.ResponseRecorder {
+		w, r := prepareTestPayloadRequest(hc, bktName, objName, bytes.NewReader(payload))
+		r.Header.Set(api.ContentEncoding, "gzip")
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		hc.Handler().PutObjectHandler(w, r)
+		return w
+	}
+	put := func(objName string, payload []byte, headers map[string]string) {
+		assertStatus(t, send(objName, payload, headers), http.StatusOK)
+	}
+	putErr := func(objName string, payload []byte, headers map[string]string, expected s3errors.ErrorCode) {
+		assertS3Error(t, send(objName, payload, headers), s3errors.GetAPIError(expected))
+	}
+	get := func(objName string) ([]byte, http.Header) {
+		w, r := prepareTestRequest(hc, bktName, objName, nil)
+		hc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		data, err := io.ReadAll(w.Result().Body)
+		require.NoError(t, err)
+		return data, w.Header()
+	}
+
+	// payloads are stored as they're sent by default
+	put("raw", compressed, nil)
+	data, _ := get("raw")
+	require.Equal(t, compressed, data)
+
+	hc.Handler().cfg.GzipUploads = true
+
+	put("buffered", compressed, map[string]string{api.ContentType: "application/gzip"})
+	data, header := get("buffered")
+	require.Equal(t, content, data)
+	require.Equal(t, strconv.Itoa(len(content)), header.Get(api.ContentLength))
+	require.NotEqual(t, "application/gzip", header.Get(api.ContentType))
+	// payloads are spooled to temporary files which are removed after the upload
+	assertEmptyDir(t, tmpDir)
+
+	put("streamed", compressed, map[string]string{api.DecompressedContentLength: strconv.Itoa(len(content))})
+	data, _ = get("streamed")
+	require.Equal(t, content, data)
+
+	putErr("mismatch", compressed, map[string]string{api.DecompressedContentLength: "10"}, s3errors.ErrIncompleteBody)
+	putErr("mismatch", compressed, map[string]string{api.DecompressedContentLength: strconv.Itoa(len(content) + 1)}, s3errors.ErrIncompleteBody)
+	putErr("corrupted", compressed[:len(compressed)-4], nil, s3errors.ErrInvalidEncodingMethod)
+	putErr("plain", content, nil, s3errors.ErrInvalidEncodingMethod)
+	putErr("combined", compressed, map[string]string{api.ContentEncoding: "gzip, br"}, s3errors.ErrInvalidEncodingMethod)
+
+	hc.Handler().cfg.GzipMaxBufferedSize = int64(len(content) - 1)
+	putErr("large", compressed, nil, s3errors.ErrEntityTooLarge)
+	checkNotFound(t, hc, bktName, "large", "")
+	assertEmptyDir(t, tmpDir)
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
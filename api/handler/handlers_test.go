@@ -104,6 +104,7 @@ func prepareHandlerContext(t *testing.T) *handlerContext {
 		cfg: &Config{
 			Policy: &placementPolicyMock{defaultPolicy: pp},
 		},
+		scanSem: make(chan struct{}, maxAsyncScans),
 	}
 
 	return &handlerContext{
@@ -135,13 +136,11 @@ func createTestBucketWithLock(hc *handlerContext, bktName string, conf *data.Obj
 	})
 	require.NoError(hc.t, err)
 
-	var ownerID user.ID
-
 	bktInfo := &data.BucketInfo{
 		CID:               cnrID,
 		Name:              bktName,
 		ObjectLockEnabled: true,
-		Owner:             ownerID,
+		Owner:             hc.owner,
 	}
 
 	sp := &layer.PutSettingsParams{
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"go.uber.org/zap"
 )
@@ -81,22 +83,25 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(info.ContentType) == 0 {
-		if info.ContentType = layer.MimeByFilePath(info.Name); len(info.ContentType) == 0 {
-			buffer := bytes.NewBuffer(make([]byte, 0, sizeToDetectType))
-			getParams := &layer.GetObjectParams{
-				ObjectInfo: info,
-				Writer:     buffer,
-				Range:      getRangeToDetectContentType(info.Size),
-				BucketInfo: bktInfo,
-			}
-			if err = h.obj.GetObject(r.Context(), getParams); err != nil {
-				h.logAndSendError(w, "could not get object", reqInfo, err, zap.Stringer("oid", info.ID))
-				return
-			}
-			info.ContentType = http.DetectContentType(buffer.Bytes())
-		}
+	fullSize, err := payloadSize(info, encryptionParams)
+	if err != nil {
+		h.logAndSendError(w, "invalid decrypted size header", reqInfo, err)
+		return
+	}
+
+	params, err := fetchObjectRange(r.Header, info, conditional, fullSize)
+	if err != nil {
+		writeUnsatisfiedRangeHeaders(w, err, fullSize)
+		h.logAndSendError(w, "could not parse range header", reqInfo, err)
+		return
+	}
+
+	contentType, err := h.objectContentType(r.Context(), bktInfo, info, encryptionParams, fullSize)
+	if err != nil {
+		h.logAndSendError(w, "could not detect content type", reqInfo, err, zap.Stringer("oid", info.ID))
+		return
 	}
+	w.Header().Set(api.ContentType, contentType)
 
 	if err = h.setLockingHeaders(bktInfo, lockInfo, w.Header()); err != nil {
 		h.logAndSendError(w, "could not get locking info", reqInfo, err)
@@ -110,7 +115,43 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeHeaders(w.Header(), r.Header, extendedInfo, len(tagSet), bktSettings.Unversioned())
-	w.WriteHeader(http.StatusOK)
+	if layer.IsAuthenticatedRequest(r.Context()) {
+		overrideResponseHeaders(w.Header(), reqInfo.URL.Query())
+	}
+	if params != nil {
+		writeRangeHeaders(w, params, fullSize)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// objectContentType returns the content type of the object. If it wasn't set
+// on upload, it's determined by the file extension or by the beginning of the
+// payload, so GET and HEAD responses are the same.
+func (h *handler) objectContentType(ctx context.Context, bktInfo *data.BucketInfo, info *data.ObjectInfo, enc encryption.Params, fullSize int64) (string, error) {
+	if len(info.ContentType) > 0 {
+		return info.ContentType, nil
+	}
+	if contentType := layer.MimeByFilePath(info.Name); len(contentType) > 0 {
+		return contentType, nil
+	}
+	if fullSize == 0 {
+		return http.DetectContentType(nil), nil
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, sizeToDetectType))
+	getParams := &layer.GetObjectParams{
+		ObjectInfo: info,
+		Writer:     buffer,
+		Range:      getRangeToDetectContentType(fullSize),
+		BucketInfo: bktInfo,
+		Encryption: enc,
+	}
+	if err := h.obj.GetObject(ctx, getParams); err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(buffer.Bytes()), nil
 }
 
 func (h *handler) HeadBucketHandler(w http.ResponseWriter, r *http.Request) {
@@ -2,7 +2,11 @@ package handler
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	neofsecdsa "github.com/nspcc-dev/neofs-sdk-go/crypto/ecdsa"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,6 +69,20 @@ func TestConditionalHead(t *testing.T) {
 	headObject(t, tc, bktName, objName, headers, http.StatusNotModified)
 }
 
+func TestHeadExpectedBucketOwner(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-owner", "object-for-owner"
+	bktInfo, _ := createBucketAndObject(tc, bktName, objName)
+
+	headObject(t, tc, bktName, objName, map[string]string{api.AmzExpectedBucketOwner: bktInfo.Owner.String()}, http.StatusOK)
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	otherOwner := user.ResolveFromECDSAPublicKey(key.PrivateKey.PublicKey)
+	headObject(t, tc, bktName, objName, map[string]string{api.AmzExpectedBucketOwner: otherOwner.String()}, http.StatusForbidden)
+}
+
 func headObject(t *testing.T, tc *handlerContext, bktName, objName string, headers map[string]string, status int) {
 	w, r := prepareTestRequest(tc, bktName, objName, nil)
 
@@ -105,3 +124,91 @@ func newTestAccessBox(t *testing.T, key *keys.PrivateKey) *accessbox.Box {
 		},
 	}
 }
+
+func TestResponseHeaderOverrides(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-overrides", "object-for-overrides"
+	createBucketAndObject(tc, bktName, objName)
+
+	query := make(url.Values)
+	expected := map[string]string{
+		api.ContentType:        "application/octet-stream",
+		api.ContentDisposition: `attachment; filename="report.txt"`,
+		api.CacheControl:       "no-cache",
+		api.Expires:            "Thu, 01 Dec 1994 16:00:00 GMT",
+		api.ContentLanguage:    "en-US",
+		api.ContentEncoding:    "identity",
+	}
+	for param, hdr := range api.ResponseModifiers {
+		query.Set(param, expected[hdr])
+	}
+
+	w, r := prepareTestFullRequest(tc, bktName, objName, query, nil)
+	tc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	for hdr, val := range expected {
+		require.Equal(t, val, w.Header().Get(hdr), hdr)
+	}
+
+	w, r = prepareTestFullRequest(tc, bktName, objName, query, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	for hdr, val := range expected {
+		require.Equal(t, val, w.Header().Get(hdr), hdr)
+	}
+}
+
+func TestHeadGetParity(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-parity"
+	createTestBucket(tc, bktName)
+
+	content := "<html><body>content</body></html>"
+	putObjectContent(tc, bktName, "plain", content)
+	putEncryptedObject(t, tc, bktName, "encrypted", content)
+
+	for _, c := range []struct {
+		name      string
+		objName   string
+		encrypted bool
+		rng       string
+		status    int
+		length    int
+	}{
+		{name: "plain", objName: "plain", status: http.StatusOK, length: len(content)},
+		{name: "plain range", objName: "plain", rng: "bytes=6-11", status: http.StatusPartialContent, length: 6},
+		{name: "encrypted", objName: "encrypted", encrypted: true, status: http.StatusOK, length: len(content)},
+		{name: "encrypted range", objName: "encrypted", encrypted: true, rng: "bytes=-7", status: http.StatusPartialContent, length: 7},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			request := func() (*httptest.ResponseRecorder, *http.Request) {
+				w, r := prepareTestRequest(tc, bktName, c.objName, nil)
+				if c.encrypted {
+					setEncryptHeaders(r)
+				}
+				if c.rng != "" {
+					r.Header.Set("Range", c.rng)
+				}
+				return w, r
+			}
+
+			getW, getR := request()
+			tc.Handler().GetObjectHandler(getW, getR)
+			assertStatus(t, getW, c.status)
+			payload, err := io.ReadAll(getW.Result().Body)
+			require.NoError(t, err)
+			require.Len(t, payload, c.length)
+
+			headW, headR := request()
+			tc.Handler().HeadObjectHandler(headW, headR)
+			assertStatus(t, headW, c.status)
+
+			require.Equal(t, getW.Header(), headW.Header())
+			require.Equal(t, strconv.Itoa(c.length), headW.Header().Get(api.ContentLength))
+			require.Equal(t, "bytes", headW.Header().Get(api.AcceptRanges))
+			require.Equal(t, "text/html; charset=utf-8", headW.Header().Get(api.ContentType))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// entireBucketMetricsID is the ID of the only metrics configuration a bucket
+// has. The gateway always collects statistics for the whole bucket (see the
+// admin usage endpoint), so filtered configurations aren't supported.
+const entireBucketMetricsID = "EntireBucket"
+
+func (h *handler) GetBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if _, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName); err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if reqInfo.URL.Query().Get("id") != entireBucketMetricsID {
+		h.logAndSendError(w, "unknown metrics configuration", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+		return
+	}
+
+	if err := api.EncodeToResponse(w, &MetricsConfiguration{ID: entireBucketMetricsID}); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) ListBucketMetricsConfigurationsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if _, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName); err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	res := &ListMetricsConfigurationsResult{
+		Configurations: []MetricsConfiguration{{ID: entireBucketMetricsID}},
+	}
+
+	if err := api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketMetricsConfiguration(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-metrics"
+	createTestBucket(hc, bktName)
+
+	query := make(url.Values)
+	query.Set("metrics", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().ListBucketMetricsConfigurationsHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	list := &ListMetricsConfigurationsResult{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(list))
+	require.False(t, list.IsTruncated)
+	require.Len(t, list.Configurations, 1)
+	require.Equal(t, entireBucketMetricsID, list.Configurations[0].ID)
+
+	query.Set("id", entireBucketMetricsID)
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketMetricsConfigurationHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	cfg := &MetricsConfiguration{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(cfg))
+	require.Equal(t, entireBucketMetricsID, cfg.ID)
+
+	query.Set("id", "unknown")
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketMetricsConfigurationHandler(w, r)
+	assertStatus(t, w, http.StatusNotFound)
+}
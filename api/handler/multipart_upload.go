@@ -11,6 +11,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
 	"go.uber.org/zap"
@@ -50,12 +51,12 @@ type (
 	ListPartsResponse struct {
 		XMLName              xml.Name      `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListPartsResult" json:"-"`
 		Bucket               string        `xml:"Bucket"`
-		Initiator            Initiator     `xml:"Initiator"`
+		Initiator            *Initiator    `xml:"Initiator,omitempty"`
 		IsTruncated          bool          `xml:"IsTruncated"`
 		Key                  string        `xml:"Key"`
 		MaxParts             int           `xml:"MaxParts,omitempty"`
 		NextPartNumberMarker int           `xml:"NextPartNumberMarker,omitempty"`
-		Owner                Owner         `xml:"Owner"`
+		Owner                *Owner        `xml:"Owner,omitempty"`
 		Parts                []*layer.Part `xml:"Part"`
 		PartNumberMarker     int           `xml:"PartNumberMarker,omitempty"`
 		StorageClass         string        `xml:"StorageClass,omitempty"`
@@ -63,12 +64,12 @@ type (
 	}
 
 	MultipartUpload struct {
-		Initiated    string    `xml:"Initiated"`
-		Initiator    Initiator `xml:"Initiator"`
-		Key          string    `xml:"Key"`
-		Owner        Owner     `xml:"Owner"`
-		StorageClass string    `xml:"StorageClass,omitempty"`
-		UploadID     string    `xml:"UploadId"`
+		Initiated    string     `xml:"Initiated"`
+		Initiator    *Initiator `xml:"Initiator,omitempty"`
+		Key          string     `xml:"Key"`
+		Owner        *Owner     `xml:"Owner,omitempty"`
+		StorageClass string     `xml:"StorageClass,omitempty"`
+		UploadID     string     `xml:"UploadId"`
 	}
 
 	Initiator struct {
@@ -143,11 +144,30 @@ func (h *handler) CreateMultipartUploadHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	p.Header = parseMetadata(r)
+	if p.Header, err = parseMetadata(r); err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
+	}
 	if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
 		p.Header[api.ContentType] = contentType
 	}
 
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket settings", reqInfo, err)
+		return
+	}
+
+	if err = checkContentTypeConstraints(settings.UploadConstraints, p.Header[api.ContentType]); err != nil {
+		h.logAndSendError(w, "upload constraints violated", reqInfo, err, additional...)
+		return
+	}
+
+	if err = h.admitUpload(r.Context(), bktInfo, reqInfo.ObjectName, -1, p.Header); err != nil {
+		h.logAndSendError(w, "upload isn't admitted", reqInfo, err, additional...)
+		return
+	}
+
 	p.CopiesNumber, err = getCopiesNumberOrDefault(p.Header, h.cfg.CopiesNumber)
 	if err != nil {
 		h.logAndSendError(w, "invalid copies number", reqInfo, err)
@@ -215,6 +235,12 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	size, err := getPayloadSize(r)
+	if err != nil {
+		h.logAndSendError(w, "invalid payload size", reqInfo, err, additional...)
+		return
+	}
+
 	p := &layer.UploadPartParams{
 		Info: &layer.UploadInfoParams{
 			UploadID: uploadID,
@@ -222,7 +248,7 @@ func (h *handler) UploadPartHandler(w http.ResponseWriter, r *http.Request) {
 			Key:      reqInfo.ObjectName,
 		},
 		PartNumber: partNumber,
-		Size:       r.ContentLength,
+		Size:       size,
 		Reader:     r.Body,
 	}
 
@@ -313,6 +339,12 @@ func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if srcRange != nil && srcRange.End >= uint64(srcInfo.Size) {
+		h.logAndSendError(w, "copy range exceeds source object", reqInfo,
+			s3errors.GetAPIError(s3errors.ErrInvalidCopyPartRangeSource), additional...)
+		return
+	}
+
 	if err = checkPreconditions(srcInfo, args.Conditional); err != nil {
 		h.logAndSendError(w, "precondition failed", reqInfo, s3errors.GetAPIError(s3errors.ErrPreconditionFailed),
 			additional...)
@@ -394,10 +426,19 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket settings", reqInfo, err, additional...)
+		return
+	}
+
 	c := &layer.CompleteMultipartParams{
 		Info:  uploadInfo,
 		Parts: reqBody.Parts,
 	}
+	if settings.UploadConstraints != nil {
+		c.MaxObjectSize = settings.UploadConstraints.MaxObjectSize
+	}
 
 	uploadData, extendedObjInfo, err := h.obj.CompleteMultipartUpload(r.Context(), c)
 	if err != nil {
@@ -406,6 +447,11 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 	}
 	objInfo := extendedObjInfo.ObjectInfo
 
+	if err = h.scanUploaded(r.Context(), bktInfo, objInfo, encryption.Params{}); err != nil {
+		h.logAndSendError(w, "object rejected by scanner", reqInfo, err, additional...)
+		return
+	}
+
 	if len(uploadData.TagSet) != 0 {
 		tagPrm := &layer.PutObjectTaggingParams{
 			ObjectVersion: &layer.ObjectVersion{
@@ -449,7 +495,7 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 			return
 		}
 
-		if _, err = h.updateBucketACL(r, astObject, bktInfo, sessionTokenSetEACL); err != nil {
+		if _, err = h.updateBucketACL(r.Context(), astObject, bktInfo, sessionTokenSetEACL); err != nil {
 			h.logAndSendError(w, "could not update bucket acl while completing multipart upload", reqInfo, err, additional...)
 			return
 		}
@@ -465,18 +511,13 @@ func (h *handler) CompleteMultipartUploadHandler(w http.ResponseWriter, r *http.
 		h.log.Error("couldn't send notification: %w", zap.Error(err))
 	}
 
-	bktSettings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
-	if err != nil {
-		h.logAndSendError(w, "could not get bucket settings", reqInfo, err)
-	}
-
 	response := CompleteMultipartUploadResponse{
 		Bucket: objInfo.Bucket,
 		ETag:   objInfo.HashSum,
 		Key:    objInfo.Name,
 	}
 
-	if bktSettings.VersioningEnabled() {
+	if settings.VersioningEnabled() {
 		w.Header().Set(api.AmzVersionID, objInfo.VersionID())
 	}
 
@@ -528,7 +569,13 @@ func (h *handler) ListMultipartUploadsHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeListMultipartUploadsToResponse(list, p)); err != nil {
+	privacy, err := h.ownerPrivacy(r, bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, encodeListMultipartUploadsToResponse(list, p, privacy)); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
 }
@@ -591,7 +638,13 @@ func (h *handler) ListPartsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeListPartsToResponse(list, p)); err != nil {
+	privacy, err := h.ownerPrivacy(r, bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, encodeListPartsToResponse(list, p, privacy)); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
 }
@@ -628,7 +681,7 @@ func (h *handler) AbortMultipartUploadHandler(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo, params *layer.ListMultipartUploadsParams) *ListMultipartUploadsResponse {
+func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo, params *layer.ListMultipartUploadsParams, privacy *ownerPrivacy) *ListMultipartUploadsResponse {
 	res := ListMultipartUploadsResponse{
 		Bucket:             params.Bkt.Name,
 		CommonPrefixes:     fillPrefixes(info.Prefixes, params.EncodingType),
@@ -647,16 +700,10 @@ func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo,
 	for _, u := range info.Uploads {
 		m := MultipartUpload{
 			Initiated: u.Created.UTC().Format(time.RFC3339),
-			Initiator: Initiator{
-				ID:          u.Owner.String(),
-				DisplayName: u.Owner.String(),
-			},
-			Key: u.Key,
-			Owner: Owner{
-				ID:          u.Owner.String(),
-				DisplayName: u.Owner.String(),
-			},
-			UploadID: u.UploadID,
+			Initiator: privacy.initiator(u.Owner.String()),
+			Key:       u.Key,
+			Owner:     privacy.owner(u.Owner.String()),
+			UploadID:  u.UploadID,
 		}
 		uploads = append(uploads, m)
 	}
@@ -666,24 +713,18 @@ func encodeListMultipartUploadsToResponse(info *layer.ListMultipartUploadsInfo,
 	return &res
 }
 
-func encodeListPartsToResponse(info *layer.ListPartsInfo, params *layer.ListPartsParams) *ListPartsResponse {
+func encodeListPartsToResponse(info *layer.ListPartsInfo, params *layer.ListPartsParams, privacy *ownerPrivacy) *ListPartsResponse {
 	return &ListPartsResponse{
-		XMLName: xml.Name{},
-		Bucket:  params.Info.Bkt.Name,
-		Initiator: Initiator{
-			ID:          info.Owner.String(),
-			DisplayName: info.Owner.String(),
-		},
+		XMLName:              xml.Name{},
+		Bucket:               params.Info.Bkt.Name,
+		Initiator:            privacy.initiator(info.Owner.String()),
 		IsTruncated:          info.IsTruncated,
 		Key:                  params.Info.Key,
 		MaxParts:             params.MaxParts,
 		NextPartNumberMarker: info.NextPartNumberMarker,
-		Owner: Owner{
-			ID:          info.Owner.String(),
-			DisplayName: info.Owner.String(),
-		},
-		PartNumberMarker: params.PartNumberMarker,
-		UploadID:         params.Info.UploadID,
-		Parts:            info.Parts,
+		Owner:                privacy.owner(info.Owner.String()),
+		PartNumberMarker:     params.PartNumberMarker,
+		UploadID:             params.Info.UploadID,
+		Parts:                info.Parts,
 	}
 }
@@ -156,10 +156,22 @@ func (h *handler) sendNotifications(ctx context.Context, p *SendNotificationPara
 	if err != nil {
 		return fmt.Errorf("failed to get notification configuration: %w", err)
 	}
+
+	return h.notify(ctx, conf, p)
+}
+
+// notify sends the event to queues of the configuration whose events and
+// filter rules match it, nothing is done if there are no such queues.
+func (h *handler) notify(ctx context.Context, conf *data.NotificationConfiguration, p *SendNotificationParams) error {
 	if conf.IsEmpty() {
 		return nil
 	}
 
+	topics := filterSubjects(conf, p.Event, p.NotificationInfo.Name)
+	if len(topics) == 0 {
+		return nil
+	}
+
 	box, err := layer.GetBoxData(ctx)
 	if err == nil && box.Gate.BearerToken != nil {
 		p.User = box.Gate.BearerToken.ResolveIssuer().EncodeToString()
@@ -167,8 +179,6 @@ func (h *handler) sendNotifications(ctx context.Context, p *SendNotificationPara
 
 	p.Time = layer.TimeNow(ctx)
 
-	topics := filterSubjects(conf, p.Event, p.NotificationInfo.Name)
-
 	return h.notificator.SendNotifications(topics, p)
 }
 
@@ -212,17 +222,18 @@ func checkRules(rules []data.FilterRule) error {
 	names := make(map[string]struct{})
 
 	for _, r := range rules {
-		if r.Name != filterRuleSuffixName && r.Name != filterRulePrefixName {
+		name := strings.ToLower(r.Name)
+		if name != filterRuleSuffixName && name != filterRulePrefixName {
 			return s3errors.GetAPIError(s3errors.ErrFilterNameInvalid)
 		}
-		if _, ok := names[r.Name]; ok {
-			if r.Name == filterRuleSuffixName {
+		if _, ok := names[name]; ok {
+			if name == filterRuleSuffixName {
 				return s3errors.GetAPIError(s3errors.ErrFilterNameSuffix)
 			}
 			return s3errors.GetAPIError(s3errors.ErrFilterNamePrefix)
 		}
 
-		names[r.Name] = struct{}{}
+		names[name] = struct{}{}
 	}
 
 	return nil
@@ -244,9 +255,7 @@ func filterSubjects(conf *data.NotificationConfiguration, eventType, objName str
 	for _, t := range conf.QueueConfigurations {
 		event := false
 		for _, e := range t.Events {
-			// the second condition is comparison with the events ending with *:
-			// s3:ObjectCreated:*, s3:ObjectRemoved:* etc without the last char
-			if eventType == e || strings.HasPrefix(eventType, e[:len(e)-1]) {
+			if matchEvent(e, eventType) {
 				event = true
 				break
 			}
@@ -258,8 +267,9 @@ func filterSubjects(conf *data.NotificationConfiguration, eventType, objName str
 
 		filter := true
 		for _, f := range t.Filter.Key.FilterRules {
-			if f.Name == filterRulePrefixName && !strings.HasPrefix(objName, f.Value) ||
-				f.Name == filterRuleSuffixName && !strings.HasSuffix(objName, f.Value) {
+			name := strings.ToLower(f.Name)
+			if name == filterRulePrefixName && !strings.HasPrefix(objName, f.Value) ||
+				name == filterRuleSuffixName && !strings.HasSuffix(objName, f.Value) {
 				filter = false
 				break
 			}
@@ -271,3 +281,14 @@ func filterSubjects(conf *data.NotificationConfiguration, eventType, objName str
 
 	return topics
 }
+
+// matchEvent checks whether the event matches the configured one, events
+// ending with * (s3:ObjectCreated:*, s3:ObjectRemoved:* etc) match all events
+// of the type.
+func matchEvent(configured, event string) bool {
+	if strings.HasSuffix(configured, "*") {
+		return strings.HasPrefix(event, configured[:len(configured)-1])
+	}
+
+	return configured == event
+}
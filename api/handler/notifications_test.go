@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/stretchr/testify/require"
@@ -56,6 +59,76 @@ func TestFilterSubjects(t *testing.T) {
 		require.Len(t, topics, 1)
 		require.Equal(t, topics["test2"], "test2")
 	})
+
+	t.Run("events without '*' match exactly", func(t *testing.T) {
+		topics := filterSubjects(config, EventObjectRemovedDeleteMarkerCreated, "dir/a.png")
+		require.Empty(t, topics)
+	})
+
+	t.Run("filter rule names are case insensitive", func(t *testing.T) {
+		conf := &data.NotificationConfiguration{QueueConfigurations: []data.QueueConfiguration{{
+			ID:       "test3",
+			QueueArn: "test3",
+			Events:   []string{EventObjectCreatedPost},
+			Filter: data.Filter{Key: data.Key{FilterRules: []data.FilterRule{
+				{Name: "Prefix", Value: "dir/"},
+			}}},
+		}}}
+		require.Empty(t, filterSubjects(conf, EventObjectCreatedPost, "a.png"))
+		require.Contains(t, filterSubjects(conf, EventObjectCreatedPost, "dir/a.png"), "test3")
+	})
+}
+
+type notificatorMock struct {
+	events []*SendNotificationParams
+}
+
+func (n *notificatorMock) SendNotifications(_ map[string]string, p *SendNotificationParams) error {
+	n.events = append(n.events, p)
+	return nil
+}
+
+func (n *notificatorMock) SendTestNotification(string, string, string, string, time.Time) error {
+	return nil
+}
+
+func TestDeleteObjectsNotifications(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	notificator := &notificatorMock{}
+	hc.Handler().notificator = notificator
+	hc.Handler().cfg.NotificatorEnabled = true
+	hc.Handler().cfg.MaxDeletePerRequest = 1000
+
+	bktName := "bucket-for-notifications"
+	createTestBucket(hc, bktName)
+	putBucketVersioning(t, hc, bktName, true)
+
+	conf := &data.NotificationConfiguration{QueueConfigurations: []data.QueueConfiguration{{
+		ID:       "removed",
+		QueueArn: "removed",
+		Events:   []string{EventObjectRemoved},
+		Filter: data.Filter{Key: data.Key{FilterRules: []data.FilterRule{
+			{Name: "suffix", Value: ".log"},
+		}}},
+	}}}
+	w, r := prepareTestRequest(hc, bktName, "", conf)
+	hc.Handler().PutBucketNotificationHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	putObject(t, hc, bktName, "a.log")
+	putObject(t, hc, bktName, "b.txt")
+
+	w, r = prepareTestRequest(hc, bktName, "", &DeleteObjectsRequest{Objects: []ObjectIdentifier{
+		{ObjectName: "a.log"},
+		{ObjectName: "b.txt"},
+	}})
+	r.Header.Set(api.ContentMD5, "")
+	hc.Handler().DeleteMultipleObjectsHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	require.Len(t, notificator.events, 1)
+	require.Equal(t, EventObjectRemovedDeleteMarkerCreated, notificator.events[0].Event)
+	require.Equal(t, "a.log", notificator.events[0].NotificationInfo.Name)
 }
 
 func TestCheckRules(t *testing.T) {
@@ -33,12 +33,18 @@ func (h *handler) ListObjectsV1Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeV1(params, list)); err != nil {
+	privacy, err := h.ownerPrivacy(r, params.BktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, encodeV1(params, list, privacy)); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
 }
 
-func encodeV1(p *layer.ListObjectsParamsV1, list *layer.ListObjectsInfoV1) *ListObjectsV1Response {
+func encodeV1(p *layer.ListObjectsParamsV1, list *layer.ListObjectsInfoV1, privacy *ownerPrivacy) *ListObjectsV1Response {
 	res := &ListObjectsV1Response{
 		Name:         p.BktInfo.Name,
 		EncodingType: p.Encode,
@@ -52,7 +58,7 @@ func encodeV1(p *layer.ListObjectsParamsV1, list *layer.ListObjectsInfoV1) *List
 
 	res.CommonPrefixes = fillPrefixes(list.Prefixes, p.Encode)
 
-	res.Contents = fillContentsWithOwner(list.Objects, p.Encode)
+	res.Contents = fillContentsWithOwner(list.Objects, p.Encode, privacy)
 
 	return res
 }
@@ -77,12 +83,18 @@ func (h *handler) ListObjectsV2Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = api.EncodeToResponse(w, encodeV2(params, list)); err != nil {
+	privacy, err := h.ownerPrivacy(r, params.BktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, encodeV2(params, list, privacy)); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
 }
 
-func encodeV2(p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2) *ListObjectsV2Response {
+func encodeV2(p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2, privacy *ownerPrivacy) *ListObjectsV2Response {
 	res := &ListObjectsV2Response{
 		Name:                  p.BktInfo.Name,
 		EncodingType:          p.Encode,
@@ -98,7 +110,7 @@ func encodeV2(p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2) *List
 
 	res.CommonPrefixes = fillPrefixes(list.Prefixes, p.Encode)
 
-	res.Contents = fillContents(list.Objects, p.Encode, p.FetchOwner)
+	res.Contents = fillContents(list.Objects, p.Encode, p.FetchOwner, privacy)
 
 	return res
 }
@@ -184,11 +196,11 @@ func fillPrefixes(src []string, encode string) []CommonPrefix {
 	return dst
 }
 
-func fillContentsWithOwner(src []*data.ObjectInfo, encode string) []Object {
-	return fillContents(src, encode, true)
+func fillContentsWithOwner(src []*data.ObjectInfo, encode string, privacy *ownerPrivacy) []Object {
+	return fillContents(src, encode, true, privacy)
 }
 
-func fillContents(src []*data.ObjectInfo, encode string, fetchOwner bool) []Object {
+func fillContents(src []*data.ObjectInfo, encode string, fetchOwner bool, privacy *ownerPrivacy) []Object {
 	var dst []Object
 	for _, obj := range src {
 		res := Object{
@@ -199,10 +211,7 @@ func fillContents(src []*data.ObjectInfo, encode string, fetchOwner bool) []Obje
 		}
 
 		if fetchOwner {
-			res.Owner = &Owner{
-				ID:          obj.Owner.String(),
-				DisplayName: obj.Owner.String(),
-			}
+			res.Owner = privacy.owner(obj.Owner.String())
 		}
 
 		dst = append(dst, res)
@@ -229,7 +238,13 @@ func (h *handler) ListBucketObjectVersionsHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	response := encodeListObjectVersionsToResponse(info, p.BktInfo.Name)
+	privacy, err := h.ownerPrivacy(r, p.BktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get owner privacy", reqInfo, err)
+		return
+	}
+
+	response := encodeListObjectVersionsToResponse(info, p.BktInfo.Name, privacy)
 	if err = api.EncodeToResponse(w, response); err != nil {
 		h.logAndSendError(w, "something went wrong", reqInfo, err)
 	}
@@ -257,7 +272,7 @@ func parseListObjectVersionsRequest(reqInfo *api.ReqInfo) (*layer.ListObjectVers
 	return &res, nil
 }
 
-func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, bucketName string) *ListObjectsVersionsResponse {
+func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, bucketName string, privacy *ownerPrivacy) *ListObjectsVersionsResponse {
 	res := ListObjectsVersionsResponse{
 		Name:                bucketName,
 		IsTruncated:         info.IsTruncated,
@@ -276,13 +291,10 @@ func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, buck
 			IsLatest:     ver.IsLatest,
 			Key:          ver.ObjectInfo.Name,
 			LastModified: ver.ObjectInfo.Created.UTC().Format(time.RFC3339),
-			Owner: Owner{
-				ID:          ver.ObjectInfo.Owner.String(),
-				DisplayName: ver.ObjectInfo.Owner.String(),
-			},
-			Size:      ver.ObjectInfo.Size,
-			VersionID: ver.Version(),
-			ETag:      ver.ObjectInfo.HashSum,
+			Owner:        privacy.owner(ver.ObjectInfo.Owner.String()),
+			Size:         ver.ObjectInfo.Size,
+			VersionID:    ver.Version(),
+			ETag:         ver.ObjectInfo.HashSum,
 		})
 	}
 	// this loop is not starting till versioning is not implemented
@@ -291,11 +303,8 @@ func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, buck
 			IsLatest:     del.IsLatest,
 			Key:          del.ObjectInfo.Name,
 			LastModified: del.ObjectInfo.Created.UTC().Format(time.RFC3339),
-			Owner: Owner{
-				ID:          del.ObjectInfo.Owner.String(),
-				DisplayName: del.ObjectInfo.Owner.String(),
-			},
-			VersionID: del.Version(),
+			Owner:        privacy.owner(del.ObjectInfo.Owner.String()),
+			VersionID:    del.Version(),
 		})
 	}
 
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+)
+
+const (
+	// PrivacyOmit mode removes owners from listings and their IDs from ACL.
+	PrivacyOmit = "Omit"
+	// PrivacyPseudonymize mode replaces identities with pseudonyms stable
+	// within the bucket.
+	PrivacyPseudonymize = "Pseudonymize"
+)
+
+// ownerPrivacy hides identities of owners in responses, nil value keeps them.
+type ownerPrivacy struct {
+	mode  string
+	salt  []byte
+	cnrID cid.ID
+}
+
+func (h *handler) PutBucketPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	privacy := &data.PrivacyConfiguration{}
+	if err = xml.NewDecoder(r.Body).Decode(privacy); err != nil {
+		h.logAndSendError(w, "couldn't parse privacy configuration", reqInfo, s3errors.GetAPIError(s3errors.ErrMalformedXML))
+		return
+	}
+
+	if privacy.Mode != PrivacyOmit && privacy.Mode != PrivacyPseudonymize {
+		h.logAndSendError(w, "invalid privacy mode", reqInfo,
+			fmt.Errorf("%w: unknown mode '%s'", s3errors.GetAPIError(s3errors.ErrInvalidArgument), privacy.Mode))
+		return
+	}
+
+	if err = h.updatePrivacy(r, bktInfo, privacy); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+}
+
+func (h *handler) GetBucketPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get bucket settings", reqInfo, err)
+		return
+	}
+
+	if settings.Privacy == nil {
+		h.logAndSendError(w, "privacy configuration not found", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+		return
+	}
+
+	if err = api.EncodeToResponse(w, settings.Privacy); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) DeleteBucketPrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = h.updatePrivacy(r, bktInfo, nil); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) updatePrivacy(r *http.Request, bktInfo *data.BucketInfo, privacy *data.PrivacyConfiguration) error {
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return err
+	}
+
+	// settings pointer is stored in the cache, so modify a copy of the settings
+	newSettings := *settings
+	newSettings.Privacy = privacy
+
+	return h.obj.PutBucketSettings(r.Context(), &layer.PutSettingsParams{
+		BktInfo:  bktInfo,
+		Settings: &newSettings,
+	})
+}
+
+// ownerPrivacy returns privacy of owners in responses about the bucket, the
+// bucket owner always sees real identities.
+func (h *handler) ownerPrivacy(r *http.Request, bktInfo *data.BucketInfo) (*ownerPrivacy, error) {
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return nil, fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	if settings.Privacy == nil || checkRequesterIsOwner(r, bktInfo) == nil {
+		return nil, nil
+	}
+
+	return &ownerPrivacy{mode: settings.Privacy.Mode, salt: h.cfg.PrivacySalt, cnrID: bktInfo.CID}, nil
+}
+
+// id returns the pseudonym of the identity, HMAC with the configured salt
+// makes pseudonyms impossible to match with known identities.
+func (p *ownerPrivacy) id(id string) string {
+	switch {
+	case p == nil:
+		return id
+	case p.mode == PrivacyOmit:
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, p.salt)
+	mac.Write(p.cnrID[:])
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// owner returns the owner element of listings, nil if owners are omitted.
+func (p *ownerPrivacy) owner(id string) *Owner {
+	if p != nil && p.mode == PrivacyOmit {
+		return nil
+	}
+
+	id = p.id(id)
+	return &Owner{ID: id, DisplayName: id}
+}
+
+// initiator returns the initiator element of multipart uploads listings, nil
+// if owners are omitted.
+func (p *ownerPrivacy) initiator(id string) *Initiator {
+	if owner := p.owner(id); owner != nil {
+		initiator := Initiator(*owner)
+		return &initiator
+	}
+	return nil
+}
+
+// acl hides identities of the owner and grantees of the policy. Display names
+// are addresses of users, so their pseudonyms match the ones of listings.
+func (p *ownerPrivacy) acl(acp *AccessControlPolicy) {
+	if p == nil {
+		return
+	}
+
+	acp.Owner.ID = p.id(acp.Owner.ID)
+	acp.Owner.DisplayName = p.id(acp.Owner.DisplayName)
+	for _, grant := range acp.AccessControlList {
+		if grant.Grantee != nil && grant.Grantee.Type == granteeCanonicalUser {
+			grant.Grantee.ID = p.id(grant.Grantee.ID)
+			grant.Grantee.DisplayName = p.id(grant.Grantee.DisplayName)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketPrivacyConfiguration(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-privacy"
+	createTestBucket(hc, bktName)
+
+	query := make(url.Values)
+	query.Set("privacy", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketPrivacyHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, &data.PrivacyConfiguration{Mode: "Unknown"})
+	hc.Handler().PutBucketPrivacyHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	putBucketPrivacy(t, hc, bktName, PrivacyOmit)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketPrivacyHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	actual := &data.PrivacyConfiguration{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(actual))
+	require.Equal(t, PrivacyOmit, actual.Mode)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().DeleteBucketPrivacyHandler(w, r)
+	assertStatus(t, w, http.StatusNoContent)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketPrivacyHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+}
+
+func TestOwnerPrivacy(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	hc.h.cfg.PrivacySalt = []byte("salt")
+
+	bktName, objName := "bucket-owner-privacy", "object"
+	bktInfo, objInfo := createBucketAndObject(hc, bktName, objName)
+	ownerID := objInfo.Owner.String()
+
+	otherBox := newTestAccessBox(t, nil)
+	privacy := func(box bool) *ownerPrivacy {
+		_, r := prepareTestFullRequest(hc, bktName, "", make(url.Values), nil)
+		if box {
+			r = r.WithContext(context.WithValue(r.Context(), api.BoxData, otherBox))
+		}
+		p, err := hc.Handler().ownerPrivacy(r, bktInfo)
+		require.NoError(t, err)
+		return p
+	}
+	aclOwner := func(p *ownerPrivacy) Owner {
+		acp := encodeObjectACL(hc.h.log, &layer.BucketACL{Info: bktInfo, EACL: eacl.NewTable()}, bktName, "")
+		p.acl(acp)
+		require.Equal(t, acp.Owner.ID, acp.AccessControlList[0].Grantee.ID)
+		return acp.Owner
+	}
+	listOwner := func(p *ownerPrivacy) *Owner {
+		res := encodeV1(&layer.ListObjectsParamsV1{ListObjectsParamsCommon: layer.ListObjectsParamsCommon{BktInfo: bktInfo}},
+			&layer.ListObjectsInfoV1{ListObjectsInfo: layer.ListObjectsInfo{Objects: []*data.ObjectInfo{objInfo}}}, p)
+		require.Len(t, res.Contents, 1)
+		return res.Contents[0].Owner
+	}
+
+	require.Nil(t, privacy(true))
+
+	putBucketPrivacy(t, hc, bktName, PrivacyOmit)
+	require.Nil(t, listOwner(privacy(true)))
+	require.Empty(t, aclOwner(privacy(true)).ID)
+	// the bucket owner sees real identities
+	require.Nil(t, privacy(false))
+	require.Equal(t, ownerID, listOwner(privacy(false)).ID)
+
+	putBucketPrivacy(t, hc, bktName, PrivacyPseudonymize)
+	pseudonym := listOwner(privacy(true)).ID
+	require.NotEmpty(t, pseudonym)
+	require.NotEqual(t, ownerID, pseudonym)
+	require.Equal(t, pseudonym, listOwner(privacy(true)).ID)
+	require.Equal(t, pseudonym, aclOwner(privacy(true)).DisplayName)
+
+	// pseudonyms differ between buckets
+	otherBktInfo := createTestBucket(hc, "bucket-owner-privacy-other")
+	other := &ownerPrivacy{mode: PrivacyPseudonymize, salt: hc.h.cfg.PrivacySalt, cnrID: otherBktInfo.CID}
+	require.NotEqual(t, pseudonym, other.id(ownerID))
+}
+
+func putBucketPrivacy(t *testing.T, hc *handlerContext, bktName, mode string) {
+	query := make(url.Values)
+	query.Set("privacy", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, &data.PrivacyConfiguration{Mode: mode})
+	hc.Handler().PutBucketPrivacyHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/base64"
-	"encoding/json"
 	"encoding/xml"
 	errorsStd "errors"
 	"fmt"
@@ -20,6 +19,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/compression"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
@@ -28,137 +28,9 @@ import (
 	"go.uber.org/zap"
 )
 
-type postPolicy struct {
-	Expiration time.Time          `json:"expiration"`
-	Conditions []*policyCondition `json:"conditions"`
-	empty      bool
-}
-
-func (p *postPolicy) condition(key string) *policyCondition {
-	for _, condition := range p.Conditions {
-		if condition.Key == key {
-			return condition
-		}
-	}
-	return nil
-}
-
-func (p *postPolicy) CheckContentLength(size int64) bool {
-	if p.empty {
-		return true
-	}
-	for _, condition := range p.Conditions {
-		if condition.Matching == "content-length-range" {
-			length := strconv.FormatInt(size, 10)
-			return condition.Key <= length && length <= condition.Value
-		}
-	}
-	return true
-}
-
-func (p *policyCondition) match(value string) bool {
-	switch p.Matching {
-	case "eq":
-		p.Matched = p.Value == value
-	case "starts-with":
-		if p.Key == api.ContentType {
-			p.Matched = true
-			for _, contentType := range strings.Split(value, ",") {
-				if !strings.HasPrefix(contentType, p.Value) {
-					p.Matched = false
-				}
-			}
-		} else {
-			p.Matched = strings.HasPrefix(value, p.Value)
-		}
-	}
-	return p.Matched
-}
-
-func (p *postPolicy) CheckField(key string, value string) error {
-	if p.empty {
-		return nil
-	}
-	cond := p.condition(key)
-	if cond == nil {
-		return s3errors.GetAPIError(s3errors.ErrPostPolicyConditionInvalidFormat)
-	}
-
-	if !cond.match(value) {
-		return s3errors.GetAPIError(s3errors.ErrPostPolicyConditionInvalidFormat)
-	}
-
-	return nil
-}
-
-func (p *postPolicy) AllConditionMatched() bool {
-	for _, condition := range p.Conditions {
-		if !condition.Matched {
-			return false
-		}
-	}
-	return true
-}
-
-type policyCondition struct {
-	Matching string
-	Key      string
-	Value    string
-	Matched  bool
-}
-
-var errInvalidCondition = fmt.Errorf("invalid condition")
-
-func (p *policyCondition) UnmarshalJSON(data []byte) error {
-	var (
-		ok bool
-		v  any
-	)
-
-	if err := json.Unmarshal(data, &v); err != nil {
-		return fmt.Errorf("unmarshal policy condition: %w", err)
-	}
-
-	switch v := v.(type) {
-	case []any:
-		if len(v) != 3 {
-			return errInvalidCondition
-		}
-		if p.Matching, ok = v[0].(string); !ok {
-			return errInvalidCondition
-		}
-
-		if p.Matching == "content-length-range" {
-			min, ok := v[1].(float64)
-			max, ok2 := v[2].(float64)
-			if !ok || !ok2 {
-				return errInvalidCondition
-			}
-			p.Key = strconv.FormatFloat(min, 'f', 0, 32)
-			p.Value = strconv.FormatFloat(max, 'f', 0, 32)
-		} else {
-			key, ok2 := v[1].(string)
-			p.Value, ok = v[2].(string)
-			if !ok || !ok2 {
-				return errInvalidCondition
-			}
-			p.Key = strings.ToLower(strings.TrimPrefix(key, "$"))
-		}
-
-	case map[string]any:
-		p.Matching = "eq"
-		for key, val := range v {
-			p.Key = strings.ToLower(key)
-			if p.Value, ok = val.(string); !ok {
-				return errInvalidCondition
-			}
-		}
-	default:
-		return fmt.Errorf("unknown condition type")
-	}
-
-	return nil
-}
+// maxMetadataSize is the limit of user-defined metadata size: the sum of
+// bytes of all keys and values, as in AWS S3.
+const maxMetadataSize = 2 * 1024
 
 // keywords of predefined basic ACL values.
 const (
@@ -201,16 +73,12 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	metadata := parseMetadata(r)
-	if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
-		metadata[api.ContentType] = contentType
-	}
-	if cacheControl := r.Header.Get(api.CacheControl); len(cacheControl) > 0 {
-		metadata[api.CacheControl] = cacheControl
-	}
-	if expires := r.Header.Get(api.Expires); len(expires) > 0 {
-		metadata[api.Expires] = expires
+	metadata, err := parseMetadata(r)
+	if err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
 	}
+	setSystemMetadata(metadata, r.Header)
 
 	copiesNumber, err := getCopiesNumberOrDefault(metadata, h.cfg.CopiesNumber)
 	if err != nil {
@@ -224,14 +92,43 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	size, err := getPayloadSize(r)
+	if err != nil {
+		h.logAndSendError(w, "invalid payload size", reqInfo, err)
+		return
+	}
+
+	var (
+		payload       io.Reader = r.Body
+		contentSHA256           = r.Header.Get(api.AmzContentSha256)
+	)
+	if h.cfg.GzipUploads {
+		gzipped, err := isGzipEncoded(r.Header)
+		if err != nil {
+			h.logAndSendError(w, "unsupported content encoding", reqInfo, err)
+			return
+		}
+		if gzipped {
+			var release func()
+			if payload, size, release, err = h.decompressPayload(r, metadata); err != nil {
+				h.logAndSendError(w, "couldn't decompress payload", reqInfo, err)
+				return
+			}
+			defer release()
+			// the declared hash describes the compressed payload
+			contentSHA256 = ""
+		}
+	}
+
 	params := &layer.PutObjectParams{
-		BktInfo:      bktInfo,
-		Object:       reqInfo.ObjectName,
-		Reader:       r.Body,
-		Size:         r.ContentLength,
-		Header:       metadata,
-		Encryption:   encryptionParams,
-		CopiesNumber: copiesNumber,
+		BktInfo:       bktInfo,
+		Object:        reqInfo.ObjectName,
+		Reader:        payload,
+		Size:          size,
+		Header:        metadata,
+		Encryption:    encryptionParams,
+		CopiesNumber:  copiesNumber,
+		ContentSHA256: contentSHA256,
 	}
 
 	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
@@ -240,12 +137,25 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = checkUploadConstraints(settings.UploadConstraints, metadata[api.ContentType], size); err != nil {
+		h.logAndSendError(w, "upload constraints violated", reqInfo, err)
+		return
+	}
+
+	if err = h.admitUpload(r.Context(), bktInfo, reqInfo.ObjectName, size, metadata); err != nil {
+		h.logAndSendError(w, "upload isn't admitted", reqInfo, err)
+		return
+	}
+
 	params.Lock, err = formObjectLock(r.Context(), bktInfo, settings.LockConfiguration, r.Header)
 	if err != nil {
 		h.logAndSendError(w, "could not form object lock", reqInfo, err)
 		return
 	}
 
+	stopScan := h.scanWhileUploading(r.Context(), params)
+	defer stopScan()
+
 	extendedObjInfo, err := h.obj.PutObject(r.Context(), params)
 	if err != nil {
 		_, err2 := io.Copy(io.Discard, r.Body)
@@ -255,6 +165,11 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	objInfo := extendedObjInfo.ObjectInfo
 
+	if h.cfg.ScanMode == ScanModeAsync {
+		// async scans never fail the request
+		_ = h.scanUploaded(r.Context(), bktInfo, objInfo, encryptionParams)
+	}
+
 	s := &SendNotificationParams{
 		Event:            EventObjectCreatedPut,
 		NotificationInfo: data.NotificationInfoFromObject(objInfo),
@@ -386,6 +301,11 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = checkMetadataSize(metadata); err != nil {
+		h.logAndSendError(w, "invalid metadata", reqInfo, err)
+		return
+	}
+
 	if tagging := auth.MultipartFormValue(r, "tagging"); tagging != "" {
 		buffer := bytes.NewBufferString(tagging)
 		tagSet, err = readTagSet(buffer)
@@ -417,7 +337,11 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		size = head.Size
 		reqInfo.ObjectName = strings.ReplaceAll(reqInfo.ObjectName, "${filename}", head.Filename)
 	}
-	if !policy.CheckContentLength(size) {
+	if err = api.CheckObjectKey(reqInfo.ObjectName); err != nil {
+		h.logAndSendError(w, "invalid object key", reqInfo, err)
+		return
+	}
+	if policy != nil && !policy.CheckContentLength(size) {
 		h.logAndSendError(w, "invalid content-length", reqInfo, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
 		return
 	}
@@ -428,6 +352,22 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket settings", reqInfo, err)
+		return
+	}
+
+	if err = checkUploadConstraints(settings.UploadConstraints, metadata[api.ContentType], size); err != nil {
+		h.logAndSendError(w, "upload constraints violated", reqInfo, err)
+		return
+	}
+
+	if err = h.admitUpload(r.Context(), bktInfo, reqInfo.ObjectName, size, metadata); err != nil {
+		h.logAndSendError(w, "upload isn't admitted", reqInfo, err)
+		return
+	}
+
 	params := &layer.PutObjectParams{
 		BktInfo: bktInfo,
 		Object:  reqInfo.ObjectName,
@@ -436,6 +376,9 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		Header:  metadata,
 	}
 
+	stopScan := h.scanWhileUploading(r.Context(), params)
+	defer stopScan()
+
 	extendedObjInfo, err := h.obj.PutObject(r.Context(), params)
 	if err != nil {
 		h.logAndSendError(w, "could not upload object", reqInfo, err)
@@ -443,6 +386,11 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 	}
 	objInfo := extendedObjInfo.ObjectInfo
 
+	if h.cfg.ScanMode == ScanModeAsync {
+		// async scans never fail the request
+		_ = h.scanUploaded(r.Context(), bktInfo, objInfo, encryption.Params{})
+	}
+
 	s := &SendNotificationParams{
 		Event:            EventObjectCreatedPost,
 		NotificationInfo: data.NotificationInfoFromObject(objInfo),
@@ -499,9 +447,7 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo); err != nil {
-		h.log.Warn("couldn't get bucket versioning", zap.String("bucket name", reqInfo.BucketName), zap.Error(err))
-	} else if settings.VersioningEnabled() {
+	if settings.VersioningEnabled() {
 		w.Header().Set(api.AmzVersionID, objInfo.VersionID())
 	}
 
@@ -533,20 +479,22 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(status)
 }
 
-func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]string) (*postPolicy, error) {
-	policy := &postPolicy{empty: true}
+// checkPostPolicy checks the form fields and the bucket against POST policy
+// of the form and fills the metadata and the object name from the fields.
+// The returned policy is nil if the form has no policy.
+func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]string) (*auth.PostPolicy, error) {
+	var policy *auth.PostPolicy
 	if policyStr := auth.MultipartFormValue(r, "policy"); policyStr != "" {
-		policyData, err := base64.StdEncoding.DecodeString(policyStr)
-		if err != nil {
-			return nil, fmt.Errorf("could not decode policy: %w", err)
+		var err error
+		if policy, err = auth.ParsePostPolicy(policyStr); err != nil {
+			return nil, err
 		}
-		if err = json.Unmarshal(policyData, policy); err != nil {
-			return nil, fmt.Errorf("could not unmarshal policy: %w", err)
+		if err = policy.CheckForm(r, time.Now()); err != nil {
+			return nil, err
 		}
-		if policy.Expiration.Before(time.Now()) {
-			return nil, fmt.Errorf("policy is expired: %w", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+		if err = policy.CheckBucket(reqInfo.BucketName); err != nil {
+			return nil, err
 		}
-		policy.empty = false
 	}
 
 	for key, v := range r.MultipartForm.Value {
@@ -554,8 +502,10 @@ func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]
 		if key == "file" || key == "policy" || key == "x-amz-signature" || strings.HasPrefix(key, "x-ignore-") {
 			continue
 		}
-		if err := policy.CheckField(key, value); err != nil {
-			return nil, fmt.Errorf("'%s' form field doesn't match the policy: %w", key, err)
+		if policy != nil {
+			if err := policy.CheckField(key, value); err != nil {
+				return nil, fmt.Errorf("'%s' form field doesn't match the policy: %w", key, err)
+			}
 		}
 
 		prefix := strings.ToLower(api.MetadataPrefix)
@@ -572,14 +522,6 @@ func checkPostPolicy(r *http.Request, reqInfo *api.ReqInfo, metadata map[string]
 		}
 	}
 
-	for _, cond := range policy.Conditions {
-		if cond.Key == "bucket" {
-			if !cond.match(reqInfo.BucketName) {
-				return nil, s3errors.GetAPIError(s3errors.ErrPostPolicyConditionInvalidFormat)
-			}
-		}
-	}
-
 	return policy, nil
 }
 
@@ -660,7 +602,7 @@ func parseTaggingHeader(header http.Header) (map[string]string, error) {
 	return tagSet, nil
 }
 
-func parseMetadata(r *http.Request) map[string]string {
+func parseMetadata(r *http.Request) (map[string]string, error) {
 	res := make(map[string]string)
 	for k, v := range r.Header {
 		if strings.HasPrefix(k, api.MetadataPrefix) {
@@ -668,7 +610,32 @@ func parseMetadata(r *http.Request) map[string]string {
 			res[key] = v[0]
 		}
 	}
-	return res
+	return res, checkMetadataSize(res)
+}
+
+// setSystemMetadata adds system metadata stored with the object from the
+// request headers.
+func setSystemMetadata(metadata map[string]string, headers http.Header) {
+	for _, key := range []string{api.ContentType, api.CacheControl, api.Expires} {
+		if value := headers.Get(key); len(value) > 0 {
+			metadata[key] = value
+		}
+	}
+}
+
+// checkMetadataSize returns MetadataTooLarge error if the total size of
+// user-defined metadata keys and values exceeds maxMetadataSize.
+func checkMetadataSize(metadata map[string]string) error {
+	var size int
+	for k, v := range metadata {
+		size += len(k) + len(v)
+	}
+
+	if size > maxMetadataSize {
+		return s3errors.GetAPIError(s3errors.ErrMetadataTooLarge)
+	}
+
+	return nil
 }
 
 func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
@@ -728,6 +695,18 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 
 	p.ObjectLockEnabled = isLockEnabled(r.Header)
 
+	compressionAlgorithm, err := parseBucketCompression(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid bucket compression", reqInfo, err)
+		return
+	}
+
+	deduplication, err := parseBucketDeduplication(r.Header)
+	if err != nil {
+		h.logAndSendError(w, "invalid bucket deduplication", reqInfo, err)
+		return
+	}
+
 	bktInfo, err := h.obj.CreateBucket(r.Context(), p)
 	if err != nil {
 		h.logAndSendError(w, "could not create bucket", reqInfo, err)
@@ -737,13 +716,22 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("bucket is created", zap.String("reqId", reqInfo.RequestID),
 		zap.String("bucket", reqInfo.BucketName), zap.Stringer("container_id", bktInfo.CID))
 
-	if p.ObjectLockEnabled {
+	if p.ObjectLockEnabled || compressionAlgorithm != "" || deduplication {
+		settings := &data.BucketSettings{
+			Versioning:    data.VersioningUnversioned,
+			Compression:   compressionAlgorithm,
+			Deduplication: deduplication,
+		}
+		if p.ObjectLockEnabled {
+			settings.Versioning = data.VersioningEnabled
+		}
+
 		sp := &layer.PutSettingsParams{
 			BktInfo:  bktInfo,
-			Settings: &data.BucketSettings{Versioning: data.VersioningEnabled},
+			Settings: settings,
 		}
 		if err = h.obj.PutBucketSettings(r.Context(), sp); err != nil {
-			h.logAndSendError(w, "couldn't enable bucket versioning", reqInfo, err,
+			h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err,
 				zap.String("container_id", bktInfo.CID.EncodeToString()))
 			return
 		}
@@ -779,6 +767,27 @@ func isLockEnabled(header http.Header) bool {
 	return lockEnabled
 }
 
+func parseBucketCompression(header http.Header) (string, error) {
+	algorithm := header.Get(api.BucketCompression)
+	if algorithm != "" && algorithm != compression.AlgorithmZstd {
+		return "", s3errors.GetAPIError(s3errors.ErrInvalidArgument)
+	}
+	return algorithm, nil
+}
+
+func parseBucketDeduplication(header http.Header) (bool, error) {
+	deduplicationStr := header.Get(api.BucketDeduplication)
+	if deduplicationStr == "" {
+		return false, nil
+	}
+
+	deduplication, err := strconv.ParseBool(deduplicationStr)
+	if err != nil {
+		return false, s3errors.GetAPIError(s3errors.ErrInvalidArgument)
+	}
+	return deduplication, nil
+}
+
 func checkBucketName(bucketName string) error {
 	if len(bucketName) < 3 || len(bucketName) > 63 {
 		return s3errors.GetAPIError(s3errors.ErrInvalidBucketName)
@@ -1,15 +1,30 @@
 package handler
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/compression"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/stretchr/testify/require"
 )
 
@@ -51,45 +66,51 @@ func TestCheckBucketName(t *testing.T) {
 	}
 }
 
-func TestCustomJSONMarshal(t *testing.T) {
-	data := []byte(`
-{ "expiration": "2015-12-30T12:00:00.000Z",
+func TestCheckPostPolicy(t *testing.T) {
+	policy := base64.StdEncoding.EncodeToString([]byte(`
+{ "expiration": "` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `",
   "conditions": [
 	["content-length-range", 1048576, 10485760],
     {"bucket": "bucketName"},
-    ["starts-with", "$key", "user/user1/"]
+    ["starts-with", "$key", "user/user1/"],
+    ["starts-with", "$Content-Type", "image/"]
   ]
-}`)
+}`))
 
-	parsedTime, err := time.Parse(time.RFC3339, "2015-12-30T12:00:00.000Z")
-	require.NoError(t, err)
-
-	expectedPolicy := &postPolicy{
-		Expiration: parsedTime,
-		Conditions: []*policyCondition{
-			{
-				Matching: "content-length-range",
-				Key:      "1048576",
-				Value:    "10485760",
-			},
-			{
-				Matching: "eq",
-				Key:      "bucket",
-				Value:    "bucketName",
-			},
-			{
-				Matching: "starts-with",
-				Key:      "key",
-				Value:    "user/user1/",
-			},
-		},
+	newRequest := func(fields map[string]string) *http.Request {
+		form := &multipart.Form{Value: map[string][]string{"policy": {policy}}}
+		for k, v := range fields {
+			form.Value[k] = []string{v}
+		}
+		return &http.Request{MultipartForm: form}
 	}
 
-	policy := &postPolicy{}
-	err = json.Unmarshal(data, policy)
+	reqInfo := &api.ReqInfo{BucketName: "bucketName"}
+	metadata := make(map[string]string)
+	res, err := checkPostPolicy(newRequest(map[string]string{"key": "user/user1/photo", "content-type": "image/png,image/jpeg"}), reqInfo, metadata)
 	require.NoError(t, err)
+	require.Equal(t, "user/user1/photo", reqInfo.ObjectName)
+	require.Equal(t, "image/png,image/jpeg", metadata[api.ContentType])
+
+	require.True(t, res.CheckContentLength(2000000))
+	require.False(t, res.CheckContentLength(20000000))
+	require.False(t, res.CheckContentLength(5))
 
-	require.Equal(t, expectedPolicy, policy)
+	for _, tc := range []struct {
+		name   string
+		bucket string
+		fields map[string]string
+	}{
+		{name: "bucket mismatch", bucket: "other", fields: map[string]string{"key": "user/user1/photo"}},
+		{name: "key mismatch", bucket: "bucketName", fields: map[string]string{"key": "user/user2/photo"}},
+		{name: "content type list mismatch", bucket: "bucketName", fields: map[string]string{"key": "user/user1/photo", "content-type": "image/png,text/plain"}},
+		{name: "field without condition", bucket: "bucketName", fields: map[string]string{"key": "user/user1/photo", "acl": "public-read"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := checkPostPolicy(newRequest(tc.fields), &api.ReqInfo{BucketName: tc.bucket}, make(map[string]string))
+			require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+		})
+	}
 }
 
 func TestEmptyPostPolicy(t *testing.T) {
@@ -126,3 +147,312 @@ func TestPutObjectOverrideCopiesNumber(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "1", objInfo.Headers[layer.AttributeNeofsCopiesNumber])
 }
+
+func TestPutObjectMetadataTooLarge(t *testing.T) {
+	tc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-metadata", "object-for-metadata"
+	createTestBucket(tc, bktName)
+
+	w, r := prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"Key", strings.Repeat("a", maxMetadataSize-len("key")))
+	tc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"Key", strings.Repeat("a", maxMetadataSize-len("key")+1))
+	tc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrMetadataTooLarge))
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set(api.MetadataPrefix+"Key", strings.Repeat("a", maxMetadataSize))
+	tc.Handler().CreateMultipartUploadHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrMetadataTooLarge))
+}
+
+func TestGetPayloadSize(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		header   map[string]string
+		length   int64
+		expected int64
+		err      bool
+	}{
+		{name: "plain", length: 10, expected: 10},
+		{name: "aws-chunked", length: 100, expected: 10,
+			header: map[string]string{api.ContentEncoding: "aws-chunked", api.AmzDecodedContentLength: "10"}},
+		{name: "aws-chunked with other encoding", length: 100, expected: 0,
+			header: map[string]string{api.ContentEncoding: "aws-chunked,gzip", api.AmzDecodedContentLength: "0"}},
+		{name: "aws-chunked without decoded length", length: 100, err: true,
+			header: map[string]string{api.ContentEncoding: "aws-chunked"}},
+		{name: "aws-chunked with invalid decoded length", length: 100, err: true,
+			header: map[string]string{api.ContentEncoding: "aws-chunked", api.AmzDecodedContentLength: "-1"}},
+		{name: "streaming payload without content encoding", length: 100, expected: 10,
+			header: map[string]string{api.AmzContentSha256: auth.StreamingContentSHA256, api.AmzDecodedContentLength: "10"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &http.Request{Header: make(http.Header), ContentLength: tc.length}
+			for k, v := range tc.header {
+				r.Header.Set(k, v)
+			}
+
+			size, err := getPayloadSize(r)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, size)
+		})
+	}
+}
+
+func TestBucketCompression(t *testing.T) {
+	tc := prepareHandlerContext(t)
+	box, _ := createAccessBox(t)
+
+	w, r := prepareTestRequest(tc, "bucket-invalid-compression", "", nil)
+	r.Header.Set(api.BucketCompression, "gzip")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	tc.Handler().CreateBucketHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	bktName, objName := "bucket-compression", "object"
+	w, r = prepareTestRequest(tc, bktName, "", nil)
+	r.Header.Set(api.BucketCompression, compression.AlgorithmZstd)
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	tc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	bktInfo, err := tc.Layer().GetBucketInfo(tc.Context(), bktName)
+	require.NoError(t, err)
+	settings, err := tc.Layer().GetBucketSettings(tc.Context(), bktInfo)
+	require.NoError(t, err)
+	require.True(t, settings.CompressionEnabled())
+	require.True(t, settings.Unversioned())
+
+	content := strings.Repeat("compressible content ", compression.DefaultBlockSize/10)
+	putObjectContent(tc, bktName, objName, content)
+
+	objects := tc.MockedPool().Objects()
+	require.Len(t, objects, 1)
+	require.Less(t, objects[0].PayloadSize(), uint64(len(content)/10))
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().HeadObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, strconv.Itoa(len(content)), w.Header().Get(api.ContentLength))
+
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	tc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, strconv.Itoa(len(content)), w.Header().Get(api.ContentLength))
+	require.Equal(t, content, w.Body.String())
+
+	start, end := compression.DefaultBlockSize-5, compression.DefaultBlockSize+5
+	w, r = prepareTestRequest(tc, bktName, objName, nil)
+	r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	tc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusPartialContent)
+	require.Equal(t, strconv.Itoa(end-start+1), w.Header().Get(api.ContentLength))
+	require.Equal(t, content[start:end+1], w.Body.String())
+
+	copyObject(t, tc, bktName, objName, "copy", CopyMeta{}, http.StatusOK)
+	w, r = prepareTestRequest(tc, bktName, "copy", nil)
+	tc.Handler().GetObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, content, w.Body.String())
+}
+
+func TestBucketDeduplication(t *testing.T) {
+	tc := prepareHandlerContext(t)
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-deduplication"
+	w, r := prepareTestRequest(tc, bktName, "", nil)
+	r.Header.Set(api.BucketDeduplication, "true")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	tc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	content := "duplicated content"
+	hash := sha256.Sum256([]byte(content))
+
+	putWithHash := func(objName, content, hash string) *httptest.ResponseRecorder {
+		w, r := prepareTestPayloadRequest(tc, bktName, objName, strings.NewReader(content))
+		if hash != "" {
+			r.Header.Set(api.AmzContentSha256, hash)
+		}
+		tc.Handler().PutObjectHandler(w, r)
+		return w
+	}
+
+	getContent := func(objName string) string {
+		w, r := prepareTestRequest(tc, bktName, objName, nil)
+		tc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		require.Equal(t, strconv.Itoa(len(content)), w.Header().Get(api.ContentLength))
+		require.Equal(t, hex.EncodeToString(hash[:]), w.Header().Get(api.ETag))
+		return w.Body.String()
+	}
+
+	assertStatus(t, putWithHash("original", content, ""), http.StatusOK)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 1)
+
+	// uploaded duplicate is replaced with a link
+	assertStatus(t, putWithHash("uploaded", content, ""), http.StatusOK)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 2)
+
+	// payload with known hash isn't uploaded, but verified
+	assertStatus(t, putWithHash("declared", content, hex.EncodeToString(hash[:])), http.StatusOK)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 3)
+
+	w = putWithHash("forged", "forged content", hex.EncodeToString(hash[:]))
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrContentSHA256Mismatch))
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 3)
+
+	for _, objName := range []string{"original", "uploaded", "declared"} {
+		require.Equal(t, content, getContent(objName))
+	}
+
+	// shared payload outlives the object it was uploaded with
+	deleteObject(t, tc, bktName, "original", emptyVersion)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 3)
+	require.Equal(t, content, getContent("uploaded"))
+
+	deleteObject(t, tc, bktName, "uploaded", emptyVersion)
+	require.Equal(t, content, getContent("declared"))
+
+	deleteObject(t, tc, bktName, "declared", emptyVersion)
+	require.Empty(t, listOIDsFromMockedNeoFS(t, tc, bktName))
+}
+
+func TestBucketDeduplicationDisabled(t *testing.T) {
+	tc := prepareHandlerContext(t)
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-deduplication-disabled"
+	w, r := prepareTestRequest(tc, bktName, "", nil)
+	r.Header.Set(api.BucketDeduplication, "true")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	tc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	content := "duplicated content"
+	put := func(objName string) {
+		w, r := prepareTestPayloadRequest(tc, bktName, objName, strings.NewReader(content))
+		tc.Handler().PutObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+	}
+	getContent := func(objName string) string {
+		w, r := prepareTestRequest(tc, bktName, objName, nil)
+		tc.Handler().GetObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		return w.Body.String()
+	}
+
+	put("original")
+	put("linked")
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 2)
+
+	bktInfo, err := tc.Layer().GetBucketInfo(tc.Context(), bktName)
+	require.NoError(t, err)
+	settings, err := tc.Layer().GetBucketSettings(tc.Context(), bktInfo)
+	require.NoError(t, err)
+	newSettings := *settings
+	newSettings.Deduplication = false
+	require.NoError(t, tc.Layer().PutBucketSettings(tc.Context(), &layer.PutSettingsParams{BktInfo: bktInfo, Settings: &newSettings}))
+
+	// the payload is stored again, and it has the same ETag
+	put("plain")
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 3)
+
+	// the object with the same payload doesn't release the shared one
+	deleteObject(t, tc, bktName, "plain", emptyVersion)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 2)
+
+	// the shared payload isn't deleted while it's referred
+	deleteObject(t, tc, bktName, "original", emptyVersion)
+	require.Len(t, listOIDsFromMockedNeoFS(t, tc, bktName), 2)
+	require.Equal(t, content, getContent("linked"))
+
+	deleteObject(t, tc, bktName, "linked", emptyVersion)
+	require.Empty(t, listOIDsFromMockedNeoFS(t, tc, bktName))
+}
+
+func TestCheckBucketWritable(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-settings-schema"
+	bktInfo := createTestBucket(hc, bktName)
+
+	checkWritable := func() error {
+		_, r := prepareTestRequest(hc, bktName, "", nil)
+		return hc.Handler().CheckBucketWritable(r)
+	}
+	require.NoError(t, checkWritable())
+
+	settings, err := hc.Layer().GetBucketSettings(hc.Context(), bktInfo)
+	require.NoError(t, err)
+	newSettings := *settings
+	newSettings.SchemaVersion = data.SettingsSchemaVersion + 1
+
+	err = hc.Layer().PutBucketSettings(hc.Context(), &layer.PutSettingsParams{
+		BktInfo:  bktInfo,
+		Settings: &newSettings,
+	})
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrBucketSettingsSchemaUnsupported))
+
+	// settings written by a newer gateway
+	settings.SchemaVersion = data.SettingsSchemaVersion + 1
+	require.ErrorIs(t, checkWritable(), s3errors.GetAPIError(s3errors.ErrBucketSettingsSchemaUnsupported))
+
+	buckets, err := hc.Layer().UnsupportedSettingsBuckets(hc.Context(), []user.ID{hc.owner})
+	require.NoError(t, err)
+	require.Equal(t, []string{bktName}, buckets)
+}
+
+func TestPutObjectStreamingPayload(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-streaming", "object-for-streaming"
+	createTestBucket(hc, bktName)
+
+	ts := time.Now()
+	seedSignature := make([]byte, sha256.Size)
+	awsCreds := credentials.NewStaticCredentials("access-key", "secret-key", "")
+	payload := []byte("streaming payload")
+
+	chunkedBody := func(signature []byte) []byte {
+		signer := v4.NewChunkSigner("us-east-1", "s3", seedSignature, ts, awsCreds)
+
+		var body bytes.Buffer
+		for _, chunk := range [][]byte{payload[:9], payload[9:], nil} {
+			chunkSignature, err := signer.GetSignature(chunk)
+			require.NoError(t, err)
+			if signature != nil {
+				chunkSignature = signature
+			}
+			body.WriteString(strconv.FormatInt(int64(len(chunk)), 16) + ";chunk-signature=" + hex.EncodeToString(chunkSignature) + "\r\n")
+			body.Write(chunk)
+			body.WriteString("\r\n")
+		}
+		return body.Bytes()
+	}
+
+	putChunked := func(body []byte, decodedLength int) *httptest.ResponseRecorder {
+		signer := v4.NewChunkSigner("us-east-1", "s3", seedSignature, ts, awsCreds)
+		w, r := prepareTestPayloadRequest(hc, bktName, objName, v4.NewChunkedReader(io.NopCloser(bytes.NewReader(body)), signer))
+		r.Header.Set(api.AmzContentSha256, auth.StreamingContentSHA256)
+		r.Header.Set(api.AmzDecodedContentLength, strconv.Itoa(decodedLength))
+		hc.Handler().PutObjectHandler(w, r)
+		return w
+	}
+
+	w := putChunked(chunkedBody(nil), len(payload))
+	assertStatus(t, w, http.StatusOK)
+	require.Equal(t, payload, getObjectRange(t, hc, bktName, objName, 0, len(payload)-1))
+
+	w = putChunked(chunkedBody(make([]byte, sha256.Size)), len(payload))
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch))
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"go.uber.org/zap"
+)
+
+// ReconcileStatus is a result of bringing the resource to the desired state.
+type ReconcileStatus string
+
+const (
+	// ReconcileCreated means the resource didn't exist and is created.
+	ReconcileCreated ReconcileStatus = "created"
+	// ReconcileUpdated means the resource is changed to the desired state.
+	ReconcileUpdated ReconcileStatus = "updated"
+	// ReconcileUnchanged means the resource is in the desired state already.
+	ReconcileUnchanged ReconcileStatus = "unchanged"
+)
+
+type (
+	// DesiredBucket is a declarative state of the bucket.
+	DesiredBucket struct {
+		Name string `json:"name"`
+		// LocationConstraint, ACL and ObjectLock are applied on creation
+		// only, the bucket is rejected if object lock is required, but the
+		// existing bucket is created without it.
+		LocationConstraint string `json:"location_constraint,omitempty"`
+		ACL                string `json:"acl,omitempty"`
+		ObjectLock         bool   `json:"object_lock,omitempty"`
+		// Versioning is Enabled or Suspended, it's kept as is if empty.
+		Versioning string `json:"versioning,omitempty"`
+		// Tags replace the bucket tag set, they're kept as is if nil.
+		Tags map[string]string `json:"tags,omitempty"`
+	}
+
+	// BucketReconciler brings buckets to the desired state on behalf of the
+	// owner of the access box of the context.
+	BucketReconciler interface {
+		ReconcileBucket(ctx context.Context, bucket DesiredBucket) (ReconcileStatus, error)
+	}
+)
+
+var _ BucketReconciler = (*handler)(nil)
+
+// ReconcileBucket creates the bucket if it doesn't exist and changes its
+// versioning and tags if they differ from the desired ones. The existing
+// bucket must be owned by the owner of the box.
+func (h *handler) ReconcileBucket(ctx context.Context, bucket DesiredBucket) (ReconcileStatus, error) {
+	if err := checkBucketName(bucket.Name); err != nil {
+		return "", err
+	}
+	if bucket.Versioning != "" && bucket.Versioning != data.VersioningEnabled && bucket.Versioning != data.VersioningSuspended {
+		return "", fmt.Errorf("invalid versioning '%s'", bucket.Versioning)
+	}
+	if bucket.Tags != nil {
+		if err := checkTagSet(encodeTagging(bucket.Tags).TagSet); err != nil {
+			return "", err
+		}
+	}
+
+	box, err := layer.GetBoxData(ctx)
+	if err != nil || box.Gate.BearerToken == nil {
+		return "", s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	status := ReconcileUnchanged
+	bktInfo, err := h.obj.GetBucketInfo(ctx, bucket.Name)
+	switch {
+	case s3errors.IsS3Error(err, s3errors.ErrNoSuchBucket):
+		if bktInfo, err = h.createDesiredBucket(ctx, bucket); err != nil {
+			return "", fmt.Errorf("create bucket: %w", err)
+		}
+		status = ReconcileCreated
+	case err != nil:
+		return "", fmt.Errorf("get bucket info: %w", err)
+	case !box.Gate.BearerToken.ResolveIssuer().Equals(bktInfo.Owner):
+		return "", s3errors.GetAPIError(s3errors.ErrBucketAlreadyExists)
+	case bucket.ObjectLock && !bktInfo.ObjectLockEnabled:
+		return "", s3errors.GetAPIError(s3errors.ErrObjectLockConfigurationNotAllowed)
+	}
+
+	settings, err := h.obj.GetBucketSettings(ctx, bktInfo)
+	if err != nil {
+		return "", fmt.Errorf("get bucket settings: %w", err)
+	}
+	if bucket.Versioning != "" && bucket.Versioning != settings.Versioning {
+		if bucket.Versioning == data.VersioningSuspended && bktInfo.ObjectLockEnabled {
+			return "", s3errors.GetAPIError(s3errors.ErrObjectLockConfigurationVersioningCannotBeChanged)
+		}
+
+		// settings pointer is stored in the cache, so modify a copy of the settings
+		newSettings := *settings
+		newSettings.Versioning = bucket.Versioning
+		if err = h.obj.PutBucketSettings(ctx, &layer.PutSettingsParams{BktInfo: bktInfo, Settings: &newSettings}); err != nil {
+			return "", fmt.Errorf("put bucket settings: %w", err)
+		}
+		status = updatedStatus(status)
+	}
+
+	if bucket.Tags != nil {
+		tagSet, err := h.obj.GetBucketTagging(ctx, bktInfo)
+		if err != nil {
+			return "", fmt.Errorf("get bucket tagging: %w", err)
+		}
+		if !equalTagSets(tagSet, bucket.Tags) {
+			if len(bucket.Tags) == 0 {
+				err = h.obj.DeleteBucketTagging(ctx, bktInfo)
+			} else {
+				err = h.obj.PutBucketTagging(ctx, bktInfo, bucket.Tags)
+			}
+			if err != nil {
+				return "", fmt.Errorf("put bucket tagging: %w", err)
+			}
+			status = updatedStatus(status)
+		}
+	}
+
+	return status, nil
+}
+
+// createDesiredBucket creates the bucket the same way CreateBucketHandler
+// does with the canned ACL of the desired bucket.
+func (h *handler) createDesiredBucket(ctx context.Context, bucket DesiredBucket) (*data.BucketInfo, error) {
+	key, err := h.bearerTokenIssuerKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get bearer token signature key: %w", err)
+	}
+
+	header := make(http.Header)
+	if bucket.ACL != "" {
+		header.Set(api.AmzACL, bucket.ACL)
+	}
+	bktACL, err := parseACLHeaders(header, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse bucket acl: %w", err)
+	}
+
+	p := &layer.CreateBucketParams{
+		Name:              bucket.Name,
+		ObjectLockEnabled: bucket.ObjectLock,
+	}
+	if p.EACL, err = bucketACLToTable(bktACL); err != nil {
+		return nil, fmt.Errorf("could translate bucket acl to eacl: %w", err)
+	}
+
+	box, err := layer.GetBoxData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.SessionContainerCreation = box.Gate.SessionTokenForPut()
+	p.SessionEACL = box.Gate.SessionTokenForSetEACL()
+	if p.SessionContainerCreation == nil || p.SessionEACL == nil {
+		return nil, s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	h.setPolicy(p, bucket.LocationConstraint, box.Policies)
+
+	bktInfo, err := h.obj.CreateBucket(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	h.log.Info("bucket is created", zap.String("bucket", bucket.Name), zap.Stringer("container_id", bktInfo.CID))
+
+	if p.ObjectLockEnabled {
+		sp := &layer.PutSettingsParams{
+			BktInfo:  bktInfo,
+			Settings: &data.BucketSettings{Versioning: data.VersioningEnabled},
+		}
+		if err = h.obj.PutBucketSettings(ctx, sp); err != nil {
+			return nil, fmt.Errorf("couldn't put bucket settings: %w", err)
+		}
+	}
+
+	return bktInfo, nil
+}
+
+func updatedStatus(status ReconcileStatus) ReconcileStatus {
+	if status == ReconcileCreated {
+		return status
+	}
+	return ReconcileUpdated
+}
+
+func equalTagSets(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileBucket(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	box, _ := createAccessBox(t)
+	ctx := context.WithValue(context.Background(), api.BoxData, box)
+
+	desired := DesiredBucket{
+		Name:       "reconciled",
+		Versioning: data.VersioningEnabled,
+		Tags:       map[string]string{"team": "media"},
+	}
+
+	status, err := hc.Handler().ReconcileBucket(ctx, desired)
+	require.NoError(t, err)
+	require.Equal(t, ReconcileCreated, status)
+
+	bktInfo, err := hc.Layer().GetBucketInfo(ctx, desired.Name)
+	require.NoError(t, err)
+	settings, err := hc.Layer().GetBucketSettings(ctx, bktInfo)
+	require.NoError(t, err)
+	require.True(t, settings.VersioningEnabled())
+	tagSet, err := hc.Layer().GetBucketTagging(ctx, bktInfo)
+	require.NoError(t, err)
+	require.Equal(t, desired.Tags, tagSet)
+
+	status, err = hc.Handler().ReconcileBucket(ctx, desired)
+	require.NoError(t, err)
+	require.Equal(t, ReconcileUnchanged, status)
+
+	desired.Versioning = data.VersioningSuspended
+	desired.Tags = map[string]string{}
+	status, err = hc.Handler().ReconcileBucket(ctx, desired)
+	require.NoError(t, err)
+	require.Equal(t, ReconcileUpdated, status)
+
+	settings, err = hc.Layer().GetBucketSettings(ctx, bktInfo)
+	require.NoError(t, err)
+	require.True(t, settings.VersioningSuspended())
+	tagSet, err = hc.Layer().GetBucketTagging(ctx, bktInfo)
+	require.NoError(t, err)
+	require.Empty(t, tagSet)
+
+	t.Run("object lock of existing bucket", func(t *testing.T) {
+		_, err = hc.Handler().ReconcileBucket(ctx, DesiredBucket{Name: desired.Name, ObjectLock: true})
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrObjectLockConfigurationNotAllowed))
+	})
+
+	t.Run("bucket of other owner", func(t *testing.T) {
+		other, _ := createAccessBox(t)
+		otherCtx := context.WithValue(context.Background(), api.BoxData, other)
+		_, err = hc.Handler().ReconcileBucket(otherCtx, DesiredBucket{Name: desired.Name})
+		require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrBucketAlreadyExists))
+	})
+
+	t.Run("invalid versioning", func(t *testing.T) {
+		_, err = hc.Handler().ReconcileBucket(ctx, DesiredBucket{Name: desired.Name, Versioning: "Unversioned"})
+		require.Error(t, err)
+	})
+}
@@ -203,7 +203,7 @@ type ObjectVersionResponse struct {
 	IsLatest     bool   `xml:"IsLatest"`
 	Key          string `xml:"Key"`
 	LastModified string `xml:"LastModified"`
-	Owner        Owner  `xml:"Owner"`
+	Owner        *Owner `xml:"Owner,omitempty"`
 	Size         int64  `xml:"Size"`
 	StorageClass string `xml:"StorageClass,omitempty"` // is empty!!
 	VersionID    string `xml:"VersionId"`
@@ -214,7 +214,7 @@ type DeleteMarkerEntry struct {
 	IsLatest     bool   `xml:"IsLatest"`
 	Key          string `xml:"Key"`
 	LastModified string `xml:"LastModified"`
-	Owner        Owner  `xml:"Owner"`
+	Owner        *Owner `xml:"Owner,omitempty"`
 	VersionID    string `xml:"VersionId"`
 }
 
@@ -262,6 +262,19 @@ type Tagging struct {
 	TagSet  []Tag    `xml:"TagSet>Tag"`
 }
 
+// MetricsConfiguration contains bucket metrics configuration.
+type MetricsConfiguration struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ MetricsConfiguration"`
+	ID      string   `xml:"Id"`
+}
+
+// ListMetricsConfigurationsResult is a response of ListBucketMetricsConfigurations.
+type ListMetricsConfigurationsResult struct {
+	XMLName        xml.Name               `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListMetricsConfigurationsResult"`
+	IsTruncated    bool                   `xml:"IsTruncated"`
+	Configurations []MetricsConfiguration `xml:"MetricsConfiguration"`
+}
+
 // PostResponse contains result of posting object.
 type PostResponse struct {
 	Bucket string `xml:"Bucket"`
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"go.uber.org/zap"
+)
+
+var errUploadAborted = errors.New("upload is aborted")
+
+type (
+	// Scanner inspects payloads of uploaded objects for malware.
+	Scanner interface {
+		Scan(ctx context.Context, obj ScanObject, payload io.Reader) (*ScanResult, error)
+	}
+
+	// ScanObject describes the scanned object.
+	ScanObject struct {
+		Bucket      string
+		Key         string
+		Size        int64
+		ContentType string
+	}
+
+	// ScanResult is a verdict of the Scanner.
+	ScanResult struct {
+		Infected bool
+		// Threat is a name of the detected malware if it's reported.
+		Threat string
+	}
+)
+
+const (
+	// ScanModeSync makes upload requests wait for the scan, infected objects
+	// are removed and requests fail.
+	ScanModeSync = "sync"
+	// ScanModeAsync makes objects scanned in background after the response,
+	// infected objects are tagged.
+	ScanModeAsync = "async"
+
+	// ScanStatusTag is a tag set to quarantined objects.
+	ScanStatusTag = "neofs-s3-gw:scan-status"
+	// ScanThreatTag is a tag with the detected malware name.
+	ScanThreatTag = "neofs-s3-gw:scan-threat"
+	// ScanStatusInfected is ScanStatusTag value of infected objects.
+	ScanStatusInfected = "infected"
+
+	// maxAsyncScans limits the number of objects scanned in background at
+	// the same time, other ones wait.
+	maxAsyncScans = 16
+)
+
+// detachedContext keeps values of the request context (credentials, request
+// info), but isn't canceled when the request is over.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// uploadScan scans the payload while it's uploaded.
+type uploadScan struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+	res  *ScanResult
+	err  error
+}
+
+// Write passes the payload to the scanner. The scanner may stop reading before
+// the end, the upload goes on anyway.
+func (s *uploadScan) Write(p []byte) (int, error) {
+	_, _ = s.pw.Write(p)
+	return len(p), nil
+}
+
+// scanWhileUploading makes the payload of the object put with the params
+// scanned while it's stored in sync mode, the version is added only if the
+// object is clean. The returned function must be called once the upload is
+// over.
+func (h *handler) scanWhileUploading(ctx context.Context, p *layer.PutObjectParams) func() {
+	if h.cfg.Scanner == nil || h.cfg.ScanMode == ScanModeAsync || h.cfg.ScanMaxSize > 0 && p.Size > h.cfg.ScanMaxSize {
+		return func() {}
+	}
+
+	pr, pw := io.Pipe()
+	scan := &uploadScan{pw: pw, done: make(chan struct{})}
+	obj := ScanObject{
+		Bucket:      p.BktInfo.Name,
+		Key:         p.Object,
+		Size:        p.Size,
+		ContentType: p.Header[api.ContentType],
+	}
+	go func() {
+		defer close(scan.done)
+		scan.res, scan.err = h.cfg.Scanner.Scan(ctx, obj, pr)
+		// Scanner may stop reading before the end, unblock the writer.
+		_ = pr.Close()
+	}()
+
+	p.Reader = io.TeeReader(p.Reader, scan)
+	p.Verify = func(ctx context.Context) error {
+		_ = pw.Close()
+		<-scan.done
+
+		if scan.err != nil {
+			if h.cfg.ScanFailOpen {
+				h.log.Warn("couldn't scan object, it's kept", zap.String("bucket", obj.Bucket),
+					zap.String("object", obj.Key), zap.Error(scan.err))
+				return nil
+			}
+			return fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrScanUnavailable), scan.err.Error())
+		}
+
+		if !scan.res.Infected {
+			return nil
+		}
+
+		h.log.Warn("infected object", zap.String("bucket", obj.Bucket), zap.String("object", obj.Key),
+			zap.String("threat", scan.res.Threat), zap.String("reqId", api.GetReqInfo(ctx).RequestID))
+		return fmt.Errorf("%w: threat '%s'", s3errors.GetAPIError(s3errors.ErrObjectInfected), scan.res.Threat)
+	}
+
+	return func() { _ = pw.CloseWithError(errUploadAborted) }
+}
+
+// scanUploaded scans the uploaded object if the scanner is configured. In
+// sync mode the returned error must fail the request. Objects put with
+// scanWhileUploading are scanned in async mode only.
+func (h *handler) scanUploaded(ctx context.Context, bktInfo *data.BucketInfo, objInfo *data.ObjectInfo, enc encryption.Params) error {
+	if h.cfg.Scanner == nil || h.cfg.ScanMaxSize > 0 && objInfo.Size > h.cfg.ScanMaxSize {
+		return nil
+	}
+
+	if layer.FormEncryptionInfo(objInfo.Headers).Enabled && !enc.Enabled() {
+		h.log.Debug("encrypted object without keys isn't scanned",
+			zap.String("bucket", bktInfo.Name), zap.String("object", objInfo.Name))
+		return nil
+	}
+
+	if h.cfg.ScanMode != ScanModeAsync {
+		return h.scanObject(ctx, bktInfo, objInfo, enc, true)
+	}
+
+	go func() {
+		h.scanSem <- struct{}{}
+		defer func() { <-h.scanSem }()
+
+		if err := h.scanObject(detachedContext{ctx}, bktInfo, objInfo, enc, false); err != nil {
+			h.log.Error("couldn't scan object", zap.String("bucket", bktInfo.Name),
+				zap.String("object", objInfo.Name), zap.String("version", objInfo.VersionID()), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (h *handler) scanObject(ctx context.Context, bktInfo *data.BucketInfo, objInfo *data.ObjectInfo, enc encryption.Params, remove bool) error {
+	pr, pw := io.Pipe()
+	go func() {
+		err := h.obj.GetObject(ctx, &layer.GetObjectParams{
+			ObjectInfo: objInfo,
+			BucketInfo: bktInfo,
+			Writer:     pw,
+			Encryption: enc,
+		})
+		_ = pw.CloseWithError(err)
+	}()
+
+	res, err := h.cfg.Scanner.Scan(ctx, ScanObject{
+		Bucket:      bktInfo.Name,
+		Key:         objInfo.Name,
+		Size:        objInfo.Size,
+		ContentType: objInfo.ContentType,
+	}, pr)
+	// Scanner may stop reading before the end, unblock the writer.
+	_ = pr.Close()
+
+	if err != nil {
+		if !remove {
+			return err
+		}
+		if h.cfg.ScanFailOpen {
+			h.log.Warn("couldn't scan object, it's kept", zap.String("bucket", bktInfo.Name),
+				zap.String("object", objInfo.Name), zap.Error(err))
+			return nil
+		}
+		h.removeRejected(ctx, bktInfo, objInfo)
+		return fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrScanUnavailable), err.Error())
+	}
+
+	if !res.Infected {
+		return nil
+	}
+
+	h.log.Warn("infected object", zap.String("bucket", bktInfo.Name), zap.String("object", objInfo.Name),
+		zap.String("version", objInfo.VersionID()), zap.String("threat", res.Threat),
+		zap.String("reqId", api.GetReqInfo(ctx).RequestID))
+	h.quarantine(ctx, bktInfo, objInfo, res.Threat, remove)
+
+	if remove {
+		return fmt.Errorf("%w: threat '%s'", s3errors.GetAPIError(s3errors.ErrObjectInfected), res.Threat)
+	}
+
+	return nil
+}
+
+// quarantine removes the infected object version if remove is set, the
+// object is tagged if it's not removed (because of the lock, for example).
+func (h *handler) quarantine(ctx context.Context, bktInfo *data.BucketInfo, objInfo *data.ObjectInfo, threat string, remove bool) {
+	if remove && h.removeRejected(ctx, bktInfo, objInfo) {
+		return
+	}
+
+	objVersion := &layer.ObjectVersion{
+		BktInfo:    bktInfo,
+		ObjectName: objInfo.Name,
+		VersionID:  objInfo.VersionID(),
+	}
+
+	_, tagSet, err := h.obj.GetObjectTagging(ctx, &layer.GetObjectTaggingParams{ObjectVersion: objVersion})
+	if err != nil {
+		h.log.Error("couldn't get tags of infected object", zap.String("bucket", bktInfo.Name),
+			zap.String("object", objInfo.Name), zap.String("version", objInfo.VersionID()), zap.Error(err))
+		return
+	}
+
+	newTagSet := make(map[string]string, len(tagSet)+2)
+	for k, v := range tagSet {
+		newTagSet[k] = v
+	}
+	newTagSet[ScanStatusTag] = ScanStatusInfected
+	if threat != "" {
+		if len(threat) > valueTagMaxLength {
+			threat = threat[:valueTagMaxLength]
+		}
+		newTagSet[ScanThreatTag] = threat
+	}
+
+	if _, err = h.obj.PutObjectTagging(ctx, &layer.PutObjectTaggingParams{ObjectVersion: objVersion, TagSet: newTagSet}); err != nil {
+		h.log.Error("couldn't tag infected object", zap.String("bucket", bktInfo.Name),
+			zap.String("object", objInfo.Name), zap.String("version", objInfo.VersionID()), zap.Error(err))
+	}
+}
+
+// removeRejected removes the object version rejected by the scanner.
+func (h *handler) removeRejected(ctx context.Context, bktInfo *data.BucketInfo, objInfo *data.ObjectInfo) bool {
+	settings, err := h.obj.GetBucketSettings(ctx, bktInfo)
+	if err == nil {
+		deleted := h.obj.DeleteObjects(ctx, &layer.DeleteObjectParams{
+			BktInfo:  bktInfo,
+			Objects:  []*layer.VersionedObject{{Name: objInfo.Name, VersionID: objInfo.VersionID()}},
+			Settings: settings,
+		})
+		err = deleted[0].Error
+	}
+
+	if err != nil {
+		h.log.Error("couldn't remove rejected object", zap.String("bucket", bktInfo.Name),
+			zap.String("object", objInfo.Name), zap.String("version", objInfo.VersionID()), zap.Error(err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+type scannerMock struct {
+	err   error
+	scans atomic.Int32
+}
+
+func (s *scannerMock) Scan(_ context.Context, _ ScanObject, payload io.Reader) (*ScanResult, error) {
+	defer s.scans.Add(1)
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	data, err := io.ReadAll(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, []byte("EICAR")) {
+		return &ScanResult{Infected: true, Threat: "Eicar-Test-Signature"}, nil
+	}
+
+	return &ScanResult{}, nil
+}
+
+func putObjectPayload(hc *handlerContext, bktName, objName, payload string) *http.Response {
+	w, r := prepareTestPayloadRequest(hc, bktName, objName, bytes.NewReader([]byte(payload)))
+	hc.Handler().PutObjectHandler(w, r)
+	return w.Result()
+}
+
+func TestScanSync(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	scanner := &scannerMock{}
+	hc.h.cfg.Scanner = scanner
+	hc.h.cfg.ScanMode = ScanModeSync
+
+	bktName, objName := "bucket-for-scan", "object"
+	createTestBucket(hc, bktName)
+
+	putObject(t, hc, bktName, objName)
+	checkFound(t, hc, bktName, objName, emptyVersion)
+
+	w, r := prepareTestPayloadRequest(hc, bktName, objName+"-infected", bytes.NewReader([]byte("EICAR payload")))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrObjectInfected))
+	checkNotFound(t, hc, bktName, objName+"-infected", emptyVersion)
+
+	// the infected object never replaces the clean one
+	w, r = prepareTestPayloadRequest(hc, bktName, objName, bytes.NewReader([]byte("EICAR payload")))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrObjectInfected))
+	require.Equal(t, "content", string(getObjectRange(t, hc, bktName, objName, 0, 6)))
+
+	scanner.err = errors.New("scanner is down")
+	w, r = prepareTestPayloadRequest(hc, bktName, objName+"-unscanned", bytes.NewReader([]byte("content")))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrScanUnavailable))
+	checkNotFound(t, hc, bktName, objName+"-unscanned", emptyVersion)
+
+	// payloads of rejected objects are removed
+	require.Len(t, listOIDsFromMockedNeoFS(t, hc, bktName), 1)
+
+	hc.h.cfg.ScanFailOpen = true
+	putObject(t, hc, bktName, objName+"-unscanned")
+	checkFound(t, hc, bktName, objName+"-unscanned", emptyVersion)
+
+	scanner.err = nil
+	hc.h.cfg.ScanMaxSize = 5
+	require.Equal(t, http.StatusOK, putObjectPayload(hc, bktName, objName+"-large", "EICAR payload").StatusCode)
+}
+
+func TestScanSyncDeduplicated(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	scanner := &scannerMock{}
+	hc.h.cfg.Scanner = scanner
+	hc.h.cfg.ScanMode = ScanModeSync
+	box, _ := createAccessBox(t)
+
+	bktName := "bucket-for-scan"
+	w, r := prepareTestRequest(hc, bktName, "", nil)
+	r.Header.Set(api.BucketDeduplication, "true")
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, box))
+	hc.Handler().CreateBucketHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	putObject(t, hc, bktName, "original")
+
+	scanner.err = errors.New("scanner is down")
+	w, r = prepareTestPayloadRequest(hc, bktName, "linked", bytes.NewReader([]byte("content")))
+	hc.Handler().PutObjectHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrScanUnavailable))
+	checkNotFound(t, hc, bktName, "linked", emptyVersion)
+	require.Len(t, listOIDsFromMockedNeoFS(t, hc, bktName), 1)
+
+	// the reference of the rejected object is released
+	deleteObject(t, hc, bktName, "original", emptyVersion)
+	require.Empty(t, listOIDsFromMockedNeoFS(t, hc, bktName))
+}
+
+func TestScanAsync(t *testing.T) {
+	hc := prepareHandlerContext(t)
+	scanner := &scannerMock{}
+	hc.h.cfg.Scanner = scanner
+	hc.h.cfg.ScanMode = ScanModeAsync
+
+	bktName, objName := "bucket-for-scan", "object"
+	createTestBucket(hc, bktName)
+
+	require.Equal(t, http.StatusOK, putObjectPayload(hc, bktName, objName, "EICAR payload").StatusCode)
+	require.Eventually(t, func() bool {
+		return scanner.scans.Load() == 1 && len(hc.h.scanSem) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	tagging := getObjectTagging(t, hc, bktName, objName, emptyVersion)
+	require.ElementsMatch(t, []Tag{
+		{Key: ScanStatusTag, Value: ScanStatusInfected},
+		{Key: ScanThreatTag, Value: "Eicar-Test-Signature"},
+	}, tagging.TagSet)
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// Names of search filter fields which aren't user metadata.
+const (
+	searchFieldKey         = "key"
+	searchFieldCreated     = "created"
+	searchFieldContentType = "content-type"
+)
+
+type (
+	// SearchResult is a response of SearchObjectsHandler.
+	SearchResult struct {
+		Objects     []ExportedObject `json:"objects"`
+		IsTruncated bool             `json:"isTruncated"`
+	}
+
+	searchToken struct {
+		value string
+		kind  searchTokenKind
+	}
+
+	searchTokenKind int
+)
+
+const (
+	searchTokenWord searchTokenKind = iota
+	searchTokenQuoted
+	searchTokenOperator
+)
+
+// SearchObjectsHandler finds latest object versions matching the filter with
+// NeoFS object search and returns them as JSON sorted by key. Only the bucket
+// owner is allowed to search.
+func (h *handler) SearchObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if !h.cfg.ObjectSearch {
+		h.logAndSendError(w, "object search is disabled", reqInfo, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+		return
+	}
+
+	query := reqInfo.URL.Query()
+	params := &layer.SearchObjectsParams{MaxKeys: maxObjectList}
+	if maxKeys := query.Get("max-keys"); maxKeys != "" {
+		var err error
+		if params.MaxKeys, err = strconv.Atoi(maxKeys); err != nil || params.MaxKeys < 0 {
+			h.logAndSendError(w, "invalid max keys", reqInfo, s3errors.GetAPIError(s3errors.ErrInvalidMaxKeys))
+			return
+		}
+		if params.MaxKeys > maxObjectList {
+			params.MaxKeys = maxObjectList
+		}
+	}
+
+	if err := parseSearchFilter(query.Get("filter"), params); err != nil {
+		h.logAndSendError(w, "invalid search filter", reqInfo, s3errors.GetAPIErrorWithError(s3errors.ErrInvalidArgument, err))
+		return
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = checkRequesterIsOwner(r, bktInfo); err != nil {
+		h.logAndSendError(w, "object search is allowed to the bucket owner only", reqInfo, err)
+		return
+	}
+	params.BktInfo = bktInfo
+
+	found, err := h.obj.SearchObjects(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, layer.ErrTooManySearchCandidates) {
+			err = s3errors.GetAPIErrorWithError(s3errors.ErrInvalidArgument, err)
+		}
+		h.logAndSendError(w, "couldn't search objects", reqInfo, err)
+		return
+	}
+
+	res := SearchResult{
+		Objects:     make([]ExportedObject, 0, len(found.Objects)),
+		IsTruncated: found.IsTruncated,
+	}
+	for _, obj := range found.Objects {
+		res.Objects = append(res.Objects, exportedObject(obj))
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		h.logAndSendError(w, "couldn't encode search result", reqInfo, err)
+		return
+	}
+
+	api.WriteResponse(w, http.StatusOK, data, api.MimeJSON)
+}
+
+// parseSearchFilter fills the search parameters from the filter. The filter
+// is a list of conditions joined with `and`, every condition is a field name,
+// an operator and a value, the value can be double quoted. Fields are `key`,
+// `content-type`, `created` and names of user metadata. `=`, `!=` and `^=`
+// (prefix) operators are accepted for all fields except `created`, which
+// accepts `=`, `<`, `<=`, `>` and `>=` with a date (2006-01-02, the whole day)
+// or RFC 3339 time (to the second) value.
+func parseSearchFilter(filter string, p *layer.SearchObjectsParams) error {
+	tokens, err := tokenizeSearchFilter(filter)
+	if err != nil {
+		return err
+	}
+	// conditions of three tokens are joined with one
+	if (len(tokens)+1)%4 != 0 {
+		return errors.New("incomplete condition")
+	}
+
+	for i := 0; i < len(tokens); i += 4 {
+		if i > 0 && (tokens[i-1].kind != searchTokenWord || !strings.EqualFold(tokens[i-1].value, "and")) {
+			return fmt.Errorf("conditions must be joined with 'and', got '%s'", tokens[i-1].value)
+		}
+
+		name, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		if name.kind != searchTokenWord {
+			return fmt.Errorf("invalid field name '%s'", name.value)
+		}
+		if op.kind != searchTokenOperator {
+			return fmt.Errorf("operator is expected after '%s', got '%s'", name.value, op.value)
+		}
+		if value.kind == searchTokenOperator {
+			return fmt.Errorf("value is expected after '%s', got '%s'", op.value, value.value)
+		}
+
+		field := strings.ToLower(name.value)
+		if field == searchFieldCreated {
+			err = parseCreatedCondition(op.value, value.value, p)
+		} else {
+			err = parseAttributeCondition(field, op.value, value.value, p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseAttributeCondition(field, op, value string, p *layer.SearchObjectsParams) error {
+	var f layer.SearchFilter
+	switch field {
+	case searchFieldKey:
+		f.Attribute = object.AttributeFilePath
+	case searchFieldContentType:
+		f.Attribute = object.AttributeContentType
+	default:
+		// user metadata is stored with lower case names
+		f.Attribute = field
+	}
+
+	switch op {
+	case "=":
+		f.Match = layer.SearchMatchEqual
+	case "!=":
+		f.Match = layer.SearchMatchNotEqual
+	case "^=":
+		f.Match = layer.SearchMatchPrefix
+	default:
+		return fmt.Errorf("invalid operator '%s' for '%s'", op, field)
+	}
+	f.Value = value
+
+	p.Filters = append(p.Filters, f)
+	return nil
+}
+
+func parseCreatedCondition(op, value string, p *layer.SearchObjectsParams) error {
+	// the value is an interval, a day or a second the timestamp is precise to
+	start, end, err := parseSearchTime(value)
+	if err != nil {
+		return err
+	}
+
+	since := func(t time.Time) {
+		if p.CreatedSince.IsZero() || t.After(p.CreatedSince) {
+			p.CreatedSince = t
+		}
+	}
+	until := func(t time.Time) {
+		if p.CreatedUntil.IsZero() || t.Before(p.CreatedUntil) {
+			p.CreatedUntil = t
+		}
+	}
+
+	switch op {
+	case "=":
+		since(start)
+		until(end)
+	case ">":
+		since(end)
+	case ">=":
+		since(start)
+	case "<":
+		until(start)
+	case "<=":
+		until(end)
+	default:
+		return fmt.Errorf("invalid operator '%s' for '%s'", op, searchFieldCreated)
+	}
+
+	return nil
+}
+
+func parseSearchTime(value string) (time.Time, time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time '%s', date or RFC 3339 time is expected", value)
+	}
+	t = t.Truncate(time.Second)
+
+	return t, t.Add(time.Second), nil
+}
+
+// tokenizeSearchFilter splits the filter into names, operators, values and
+// joining keywords.
+func tokenizeSearchFilter(filter string) ([]searchToken, error) {
+	var (
+		res   []searchToken
+		runes = []rune(filter)
+	)
+
+	isOperator := func(r rune) bool {
+		return strings.ContainsRune("=!^<>", r)
+	}
+
+	for i := 0; i < len(runes); {
+		switch c := runes[i]; {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, errors.New("unterminated quoted value")
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				} else if runes[i] == '"' {
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			res = append(res, searchToken{value: sb.String(), kind: searchTokenQuoted})
+		case isOperator(c):
+			start := i
+			for i < len(runes) && isOperator(runes[i]) {
+				i++
+			}
+			res = append(res, searchToken{value: string(runes[start:i]), kind: searchTokenOperator})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !isOperator(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			res = append(res, searchToken{value: string(runes[start:i])})
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, errors.New("empty filter")
+	}
+
+	return res, nil
+}
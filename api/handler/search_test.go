@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchObjects(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-search"
+	createTestBucket(hc, bktName)
+
+	putWithMeta := func(objName, contentType, color string) {
+		w, r := prepareTestPayloadRequest(hc, bktName, objName, strings.NewReader("content"))
+		r.Header.Set(api.MetadataPrefix+"Color", color)
+		r.Header.Set(api.ContentType, contentType)
+		hc.Handler().PutObjectHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+	}
+
+	putWithMeta("photos/a.png", "image/png", "red")
+	putWithMeta("photos/b.jpg", "image/jpeg", "red")
+	putWithMeta("docs/c.txt", "text/plain", "blue")
+	// overwritten objects are found by the current version only
+	putWithMeta("docs/c.txt", "text/plain", "green")
+
+	search := func(query url.Values) SearchResult {
+		query.Set("search", "")
+		w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+		hc.Handler().SearchObjectsHandler(w, r)
+		assertStatus(t, w, http.StatusOK)
+		require.Equal(t, string(api.MimeJSON), w.Header().Get(api.ContentType))
+
+		var res SearchResult
+		require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&res))
+		return res
+	}
+	keys := func(res SearchResult) []string {
+		var keys []string
+		for _, obj := range res.Objects {
+			keys = append(keys, obj.Key)
+		}
+		return keys
+	}
+
+	w, r := prepareTestFullRequest(hc, bktName, "", url.Values{"search": []string{""}, "filter": []string{"color = red"}}, nil)
+	hc.Handler().SearchObjectsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNotImplemented))
+
+	hc.Handler().cfg.ObjectSearch = true
+
+	res := search(url.Values{"filter": []string{"color = red"}})
+	require.Equal(t, []string{"photos/a.png", "photos/b.jpg"}, keys(res))
+	require.False(t, res.IsTruncated)
+	require.Equal(t, "image/png", res.Objects[0].ContentType)
+	require.Equal(t, map[string]string{"color": "red"}, res.Objects[0].Metadata)
+
+	res = search(url.Values{"filter": []string{`key ^= "photos/" and content-type != image/png`}})
+	require.Equal(t, []string{"photos/b.jpg"}, keys(res))
+
+	res = search(url.Values{"filter": []string{"color = blue"}})
+	require.Empty(t, res.Objects)
+	res = search(url.Values{"filter": []string{"color = green"}})
+	require.Equal(t, []string{"docs/c.txt"}, keys(res))
+
+	today := time.Now().UTC().Format("2006-01-02")
+	res = search(url.Values{"filter": []string{"created >= " + today}})
+	require.Len(t, res.Objects, 3)
+	res = search(url.Values{"filter": []string{"created < " + today}})
+	require.Empty(t, res.Objects)
+
+	res = search(url.Values{"filter": []string{"created = " + today}, "max-keys": []string{"2"}})
+	require.Equal(t, []string{"docs/c.txt", "photos/a.png"}, keys(res))
+	require.True(t, res.IsTruncated)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"search": []string{""}, "filter": []string{"color ="}}, nil)
+	hc.Handler().SearchObjectsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIErrorWithError(s3errors.ErrInvalidArgument, errors.New("incomplete condition")))
+
+	// only the bucket owner can search objects
+	w, r = prepareTestFullRequest(hc, bktName, "", url.Values{"search": []string{""}, "filter": []string{"color = red"}}, nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, newTestAccessBox(t, nil)))
+	hc.Handler().SearchObjectsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+}
+
+func TestParseSearchFilter(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	moment := time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC)
+
+	for _, tc := range []struct {
+		filter   string
+		expected layer.SearchObjectsParams
+		err      bool
+	}{
+		{
+			filter: "key = a",
+			expected: layer.SearchObjectsParams{Filters: []layer.SearchFilter{
+				{Attribute: object.AttributeFilePath, Value: "a", Match: layer.SearchMatchEqual},
+			}},
+		},
+		{
+			filter: `Content-Type != "text/plain" AND Color^="dark \"red\""`,
+			expected: layer.SearchObjectsParams{Filters: []layer.SearchFilter{
+				{Attribute: object.AttributeContentType, Value: "text/plain", Match: layer.SearchMatchNotEqual},
+				{Attribute: "color", Value: `dark "red"`, Match: layer.SearchMatchPrefix},
+			}},
+		},
+		{
+			filter:   "created = 2024-03-01",
+			expected: layer.SearchObjectsParams{CreatedSince: day, CreatedUntil: day.AddDate(0, 0, 1)},
+		},
+		{
+			filter:   "created > 2024-03-01 and created <= 2024-03-05",
+			expected: layer.SearchObjectsParams{CreatedSince: day.AddDate(0, 0, 1), CreatedUntil: day.AddDate(0, 0, 5)},
+		},
+		{
+			filter:   "created >= 2024-03-01T12:30:15Z and created < 2024-03-01T13:00:00+01:00",
+			expected: layer.SearchObjectsParams{CreatedSince: moment, CreatedUntil: moment.Add(-30*time.Minute - 15*time.Second)},
+		},
+		{filter: "", err: true},
+		{filter: "key", err: true},
+		{filter: "key = a b = c", err: true},
+		{filter: "key = a or b = c", err: true},
+		{filter: "key < a", err: true},
+		{filter: "created ^= 2024-03-01", err: true},
+		{filter: "created = yesterday", err: true},
+		{filter: `key = "a`, err: true},
+		{filter: "= a = b", err: true},
+		{filter: "key = =", err: true},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			var p layer.SearchObjectsParams
+			err := parseSearchFilter(tc.filter, &p)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.Filters, p.Filters)
+			require.True(t, tc.expected.CreatedSince.Equal(p.CreatedSince), p.CreatedSince)
+			require.True(t, tc.expected.CreatedUntil.Equal(p.CreatedUntil), p.CreatedUntil)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/transform"
+)
+
+// transformObject returns the object content derived according to the
+// x-transform query parameter.
+func (h *handler) transformObject(ctx context.Context, spec string, rng *layer.RangeParams, bktInfo *data.BucketInfo,
+	info *data.ObjectInfo, enc encryption.Params) (*data.TransformedObject, error) {
+	if h.cfg.Transform == nil {
+		return nil, fmt.Errorf("%w: transformations are disabled", s3errors.GetAPIError(s3errors.ErrNotImplemented))
+	}
+
+	steps, err := h.cfg.Transform.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrInvalidArgument), err.Error())
+	}
+
+	if rng != nil {
+		return nil, fmt.Errorf("%w: range of transformed object", s3errors.GetAPIError(s3errors.ErrInvalidRequest))
+	}
+
+	if info.Size > h.cfg.Transform.MaxSourceSize() {
+		return nil, fmt.Errorf("%w: object is too large to be transformed", s3errors.GetAPIError(s3errors.ErrInvalidRequest))
+	}
+
+	res, err := h.cfg.Transform.Apply(ctx, info.Address().EncodeToString(), steps, func() ([]byte, string, error) {
+		buf := new(bytes.Buffer)
+		err := h.obj.GetObject(ctx, &layer.GetObjectParams{
+			ObjectInfo: info,
+			BucketInfo: bktInfo,
+			Writer:     buf,
+			Encryption: enc,
+		})
+		return buf.Bytes(), info.ContentType, err
+	})
+	if errors.Is(err, transform.ErrUnsupportedContent) {
+		return nil, fmt.Errorf("%w: %s", s3errors.GetAPIError(s3errors.ErrInvalidRequest), err.Error())
+	}
+
+	return res, err
+}
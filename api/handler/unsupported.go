@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"go.uber.org/zap"
+)
+
+// UnsupportedMode determines how S3 operations the gateway doesn't support are
+// answered.
+type UnsupportedMode string
+
+const (
+	// UnsupportedStrict answers with NotImplemented or NotSupported errors.
+	UnsupportedStrict UnsupportedMode = "strict"
+	// UnsupportedCompatible answers as AWS S3 does for buckets without the
+	// configuration, configurations are accepted and ignored.
+	UnsupportedCompatible UnsupportedMode = "compatible"
+)
+
+type (
+	// UnsupportedOperations contains modes of classes of unsupported bucket
+	// configuration operations: lifecycle, encryption, website, accelerate,
+	// request payment, logging, replication, public access block and
+	// metrics ones.
+	UnsupportedOperations struct {
+		// ConfigReads are answered with AWS S3 "not configured" errors or
+		// empty configurations in compatible mode.
+		ConfigReads UnsupportedMode
+		// ConfigWrites are accepted without applying the configuration in
+		// compatible mode.
+		ConfigWrites UnsupportedMode
+		// ConfigDeletes are answered with 204 No Content in compatible mode.
+		ConfigDeletes UnsupportedMode
+	}
+
+	// AccelerateConfiguration is an empty GetBucketAccelerate response.
+	AccelerateConfiguration struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ AccelerateConfiguration" json:"-"`
+	}
+
+	// RequestPaymentConfiguration is a GetBucketRequestPayment response.
+	RequestPaymentConfiguration struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ RequestPaymentConfiguration" json:"-"`
+		Payer   string   `xml:"Payer"`
+	}
+
+	// BucketLoggingStatus is an empty GetBucketLogging response.
+	BucketLoggingStatus struct {
+		XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ BucketLoggingStatus" json:"-"`
+	}
+)
+
+// ParseUnsupportedMode parses the mode, empty string means UnsupportedStrict.
+func ParseUnsupportedMode(s string) (UnsupportedMode, error) {
+	switch mode := UnsupportedMode(s); mode {
+	case "", UnsupportedStrict:
+		return UnsupportedStrict, nil
+	case UnsupportedCompatible:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown unsupported operations mode '%s'", s)
+	}
+}
+
+// compatibleRequest answers the request with strict error unless the mode is
+// compatible and reports whether the compatible response must be written.
+// The bucket must exist for compatible responses, owner-only requests are
+// checked to be made by the bucket owner.
+func (h *handler) compatibleRequest(w http.ResponseWriter, r *http.Request, mode UnsupportedMode, strict s3errors.ErrorCode, ownerOnly bool) bool {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if mode != UnsupportedCompatible {
+		h.logAndSendError(w, "not supported", reqInfo, s3errors.GetAPIError(strict))
+		return false
+	}
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return false
+	}
+
+	if ownerOnly {
+		if err = checkRequesterIsOwner(r, bktInfo); err != nil {
+			h.logAndSendError(w, "bucket configuration is allowed to the bucket owner only", reqInfo, err)
+			return false
+		}
+	}
+
+	return true
+}
+
+// compatibleRead answers the read of the configuration with res.
+func (h *handler) compatibleRead(w http.ResponseWriter, r *http.Request, strict s3errors.ErrorCode, res any) {
+	if !h.compatibleRequest(w, r, h.cfg.Unsupported.ConfigReads, strict, false) {
+		return
+	}
+
+	if err := api.EncodeToResponse(w, res); err != nil {
+		h.logAndSendError(w, "something went wrong", api.GetReqInfo(r.Context()), err)
+	}
+}
+
+// compatibleNotFound answers the read of the configuration with notFound
+// error AWS S3 returns if the bucket has no configuration.
+func (h *handler) compatibleNotFound(w http.ResponseWriter, r *http.Request, strict, notFound s3errors.ErrorCode) {
+	if !h.compatibleRequest(w, r, h.cfg.Unsupported.ConfigReads, strict, false) {
+		return
+	}
+
+	api.WriteErrorResponse(w, api.GetReqInfo(r.Context()), s3errors.GetAPIError(notFound))
+}
+
+func (h *handler) compatibleWrite(w http.ResponseWriter, r *http.Request, strict s3errors.ErrorCode) {
+	if !h.compatibleRequest(w, r, h.cfg.Unsupported.ConfigWrites, strict, true) {
+		return
+	}
+
+	reqInfo := api.GetReqInfo(r.Context())
+	h.log.Warn("unsupported bucket configuration is accepted, but isn't applied",
+		zap.String("request_id", reqInfo.RequestID), zap.String("method", reqInfo.API),
+		zap.String("bucket", reqInfo.BucketName))
+	api.WriteSuccessResponseHeadersOnly(w)
+}
+
+func (h *handler) compatibleDelete(w http.ResponseWriter, r *http.Request, strict s3errors.ErrorCode) {
+	if !h.compatibleRequest(w, r, h.cfg.Unsupported.ConfigDeletes, strict, true) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleNotFound(w, r, s3errors.ErrNotImplemented, s3errors.ErrNoSuchLifecycleConfiguration)
+}
+
+func (h *handler) GetBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleNotFound(w, r, s3errors.ErrNotImplemented, s3errors.ErrNoSuchBucketSSEConfig)
+}
+
+func (h *handler) GetBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleNotFound(w, r, s3errors.ErrNotImplemented, s3errors.ErrNoSuchWebsiteConfiguration)
+}
+
+func (h *handler) GetBucketAccelerateHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleRead(w, r, s3errors.ErrNotImplemented, &AccelerateConfiguration{})
+}
+
+func (h *handler) GetBucketRequestPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleRead(w, r, s3errors.ErrNotImplemented, &RequestPaymentConfiguration{Payer: "BucketOwner"})
+}
+
+func (h *handler) GetBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleRead(w, r, s3errors.ErrNotImplemented, &BucketLoggingStatus{})
+}
+
+func (h *handler) GetBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleNotFound(w, r, s3errors.ErrNotImplemented, s3errors.ErrReplicationConfigurationNotFoundError)
+}
+
+func (h *handler) GetPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleNotFound(w, r, s3errors.ErrNotSupported, s3errors.ErrNoSuchPublicAccessBlockConfiguration)
+}
+
+func (h *handler) PutBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleWrite(w, r, s3errors.ErrNotImplemented)
+}
+
+func (h *handler) PutBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleWrite(w, r, s3errors.ErrNotImplemented)
+}
+
+func (h *handler) PutBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleWrite(w, r, s3errors.ErrNotImplemented)
+}
+
+func (h *handler) PutPublicAccessBlockHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleWrite(w, r, s3errors.ErrNotSupported)
+}
+
+func (h *handler) DeleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleDelete(w, r, s3errors.ErrNotImplemented)
+}
+
+func (h *handler) DeleteBucketMetricsConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleDelete(w, r, s3errors.ErrNotImplemented)
+}
+
+func (h *handler) DeleteBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleDelete(w, r, s3errors.ErrNotSupported)
+}
+
+func (h *handler) DeleteBucketEncryptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.compatibleDelete(w, r, s3errors.ErrNotSupported)
+}
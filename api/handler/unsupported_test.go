@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedOperations(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName := "bucket-for-unsupported"
+	createTestBucket(hc, bktName)
+
+	call := func(handler http.HandlerFunc, bucket string) *httptest.ResponseRecorder {
+		w, r := prepareTestFullRequest(hc, bucket, "", url.Values{}, nil)
+		handler(w, r)
+		return w
+	}
+
+	// strict mode is the default one
+	assertS3Error(t, call(hc.Handler().GetBucketLifecycleHandler, bktName), s3errors.GetAPIError(s3errors.ErrNotImplemented))
+	assertS3Error(t, call(hc.Handler().PutPublicAccessBlockHandler, bktName), s3errors.GetAPIError(s3errors.ErrNotSupported))
+	assertS3Error(t, call(hc.Handler().DeleteBucketEncryptionHandler, bktName), s3errors.GetAPIError(s3errors.ErrNotSupported))
+
+	hc.Handler().cfg.Unsupported.ConfigReads = UnsupportedCompatible
+	assertS3Error(t, call(hc.Handler().GetBucketLifecycleHandler, bktName), s3errors.GetAPIError(s3errors.ErrNoSuchLifecycleConfiguration))
+	assertS3Error(t, call(hc.Handler().GetPublicAccessBlockHandler, bktName), s3errors.GetAPIError(s3errors.ErrNoSuchPublicAccessBlockConfiguration))
+	assertS3Error(t, call(hc.Handler().GetBucketWebsiteHandler, "unknown-bucket"), s3errors.GetAPIError(s3errors.ErrNoSuchBucket))
+
+	w := call(hc.Handler().GetBucketRequestPaymentHandler, bktName)
+	assertStatus(t, w, http.StatusOK)
+	var payment RequestPaymentConfiguration
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(&payment))
+	require.Equal(t, "BucketOwner", payment.Payer)
+
+	// classes have their own modes
+	assertS3Error(t, call(hc.Handler().PutBucketLifecycleHandler, bktName), s3errors.GetAPIError(s3errors.ErrNotImplemented))
+
+	hc.Handler().cfg.Unsupported.ConfigWrites = UnsupportedCompatible
+	hc.Handler().cfg.Unsupported.ConfigDeletes = UnsupportedCompatible
+	assertStatus(t, call(hc.Handler().PutBucketEncryptionHandler, bktName), http.StatusOK)
+	assertStatus(t, call(hc.Handler().DeleteBucketLifecycleHandler, bktName), http.StatusNoContent)
+
+	// configurations are ignored and can't be made by others
+	assertS3Error(t, call(hc.Handler().GetBucketEncryptionHandler, bktName), s3errors.GetAPIError(s3errors.ErrNoSuchBucketSSEConfig))
+
+	w, r := prepareTestFullRequest(hc, bktName, "", url.Values{}, nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.BoxData, newTestAccessBox(t, nil)))
+	hc.Handler().PutBucketLifecycleHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrAccessDenied))
+}
+
+func TestParseUnsupportedMode(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected UnsupportedMode
+	}{
+		{value: "", expected: UnsupportedStrict},
+		{value: "strict", expected: UnsupportedStrict},
+		{value: "compatible", expected: UnsupportedCompatible},
+	} {
+		mode, err := ParseUnsupportedMode(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, mode)
+	}
+
+	_, err := ParseUnsupportedMode("lenient")
+	require.Error(t, err)
+}
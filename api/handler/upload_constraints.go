@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// defaultContentType is the content type of objects uploaded without it.
+const defaultContentType = "application/octet-stream"
+
+func (h *handler) PutBucketUploadConstraintsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	constraints := &data.UploadConstraints{}
+	if err = xml.NewDecoder(r.Body).Decode(constraints); err != nil {
+		h.logAndSendError(w, "couldn't parse upload constraints", reqInfo, s3errors.GetAPIError(s3errors.ErrMalformedXML))
+		return
+	}
+
+	if err = checkUploadConstraintsConfig(constraints); err != nil {
+		h.logAndSendError(w, "invalid upload constraints", reqInfo, err)
+		return
+	}
+
+	if err = h.updateUploadConstraints(r, bktInfo, constraints); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+}
+
+func (h *handler) GetBucketUploadConstraintsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get bucket settings", reqInfo, err)
+		return
+	}
+
+	if settings.UploadConstraints == nil {
+		h.logAndSendError(w, "upload constraints not found", reqInfo, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+		return
+	}
+
+	if err = api.EncodeToResponse(w, settings.UploadConstraints); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func (h *handler) DeleteBucketUploadConstraintsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.getBucketAndCheckOwner(r, reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+
+	if err = h.updateUploadConstraints(r, bktInfo, nil); err != nil {
+		h.logAndSendError(w, "couldn't put bucket settings", reqInfo, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) updateUploadConstraints(r *http.Request, bktInfo *data.BucketInfo, constraints *data.UploadConstraints) error {
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return err
+	}
+
+	// settings pointer is stored in the cache, so modify a copy of the settings
+	newSettings := *settings
+	newSettings.UploadConstraints = constraints
+
+	return h.obj.PutBucketSettings(r.Context(), &layer.PutSettingsParams{
+		BktInfo:  bktInfo,
+		Settings: &newSettings,
+	})
+}
+
+func checkUploadConstraintsConfig(constraints *data.UploadConstraints) error {
+	if constraints.MaxObjectSize < 0 {
+		return fmt.Errorf("%w: negative max object size", s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+	}
+
+	for _, patterns := range [][]string{constraints.AllowedContentTypes, constraints.DeniedContentTypes} {
+		for i, pattern := range patterns {
+			typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(pattern)), "/")
+			if !ok || typ == "" || subtype == "" || strings.Contains(subtype, "/") || (typ == "*" && subtype != "*") {
+				return fmt.Errorf("%w: invalid content type '%s'", s3errors.GetAPIError(s3errors.ErrInvalidArgument), pattern)
+			}
+			patterns[i] = typ + "/" + subtype
+		}
+	}
+
+	return nil
+}
+
+// checkUploadConstraints checks the content type and the size of the uploaded
+// object against bucket constraints. Negative size means it's unknown.
+func checkUploadConstraints(constraints *data.UploadConstraints, contentType string, size int64) error {
+	if constraints == nil {
+		return nil
+	}
+
+	if constraints.MaxObjectSize > 0 {
+		if size < 0 {
+			return s3errors.GetAPIError(s3errors.ErrMissingContentLength)
+		}
+		if size > constraints.MaxObjectSize {
+			return fmt.Errorf("%w: bucket allows objects up to %d bytes", s3errors.GetAPIError(s3errors.ErrEntityTooLarge), constraints.MaxObjectSize)
+		}
+	}
+
+	return checkContentTypeConstraints(constraints, contentType)
+}
+
+// checkContentTypeConstraints checks the content type against bucket
+// constraints. Denied content types take precedence, if allowed ones are set,
+// the content type must match one of them.
+func checkContentTypeConstraints(constraints *data.UploadConstraints, contentType string) error {
+	if constraints == nil || len(constraints.AllowedContentTypes) == 0 && len(constraints.DeniedContentTypes) == 0 {
+		return nil
+	}
+
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	contentType = strings.ToLower(contentType)
+
+	if matchContentType(constraints.DeniedContentTypes, contentType) ||
+		len(constraints.AllowedContentTypes) > 0 && !matchContentType(constraints.AllowedContentTypes, contentType) {
+		return fmt.Errorf("%w: content type '%s'", s3errors.GetAPIError(s3errors.ErrContentTypeNotAllowed), contentType)
+	}
+
+	return nil
+}
+
+func matchContentType(patterns []string, contentType string) bool {
+	typ, _, _ := strings.Cut(contentType, "/")
+
+	for _, pattern := range patterns {
+		if pattern == "*/*" || pattern == contentType || pattern == typ+"/*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketUploadConstraints(t *testing.T) {
+	hc := prepareHandlerContext(t)
+
+	bktName, objName := "bucket-for-constraints", "object"
+	createTestBucket(hc, bktName)
+
+	query := make(url.Values)
+	query.Set("upload-constraints", "")
+
+	w, r := prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketUploadConstraintsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrNoSuchConfiguration))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, &data.UploadConstraints{AllowedContentTypes: []string{"text"}})
+	hc.Handler().PutBucketUploadConstraintsHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrInvalidArgument))
+
+	constraints := &data.UploadConstraints{
+		AllowedContentTypes: []string{"Text/*", "image/png"},
+		DeniedContentTypes:  []string{"text/html"},
+		MaxObjectSize:       10,
+	}
+	w, r = prepareTestFullRequest(hc, bktName, "", query, constraints)
+	hc.Handler().PutBucketUploadConstraintsHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().GetBucketUploadConstraintsHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+	actual := &data.UploadConstraints{}
+	require.NoError(t, xml.NewDecoder(w.Result().Body).Decode(actual))
+	require.Equal(t, []string{"text/*", "image/png"}, actual.AllowedContentTypes)
+	require.Equal(t, constraints.DeniedContentTypes, actual.DeniedContentTypes)
+	require.Equal(t, constraints.MaxObjectSize, actual.MaxObjectSize)
+
+	for _, tc := range []struct {
+		contentType string
+		size        int
+		err         s3errors.ErrorCode
+	}{
+		{contentType: "text/plain; charset=utf-8", size: 10},
+		{contentType: "IMAGE/PNG", size: 1},
+		{contentType: "text/html", size: 1, err: s3errors.ErrContentTypeNotAllowed},
+		{contentType: "image/jpeg", size: 1, err: s3errors.ErrContentTypeNotAllowed},
+		{contentType: "", size: 1, err: s3errors.ErrContentTypeNotAllowed},
+		{contentType: "text/plain", size: 11, err: s3errors.ErrEntityTooLarge},
+	} {
+		w, r = prepareTestRequestWithQuery(hc, bktName, objName, nil, make([]byte, tc.size))
+		r.Header.Set(api.ContentType, tc.contentType)
+		hc.Handler().PutObjectHandler(w, r)
+		if tc.err == 0 {
+			assertStatus(t, w, http.StatusOK)
+		} else {
+			assertS3Error(t, w, s3errors.GetAPIError(tc.err))
+		}
+	}
+
+	w, r = prepareTestRequest(hc, bktName, objName, nil)
+	r.Header.Set(api.ContentType, "application/json")
+	hc.Handler().CreateMultipartUploadHandler(w, r)
+	assertS3Error(t, w, s3errors.GetAPIError(s3errors.ErrContentTypeNotAllowed))
+
+	w, r = prepareTestFullRequest(hc, bktName, "", query, nil)
+	hc.Handler().DeleteBucketUploadConstraintsHandler(w, r)
+	assertStatus(t, w, http.StatusNoContent)
+
+	w, r = prepareTestRequestWithQuery(hc, bktName, objName, nil, make([]byte, 11))
+	r.Header.Set(api.ContentType, "text/html")
+	hc.Handler().PutObjectHandler(w, r)
+	assertStatus(t, w, http.StatusOK)
+}
@@ -3,11 +3,15 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	v4 "github.com/nspcc-dev/neofs-s3-gw/api/auth/signer/v4"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
@@ -52,6 +56,23 @@ func transformToS3Error(err error) error {
 		return s3errors.GetAPIError(s3errors.ErrBadRequest)
 	}
 
+	if errors.Is(err, context.DeadlineExceeded) {
+		return s3errors.GetAPIError(s3errors.ErrOperationTimedOut)
+	}
+
+	if errors.Is(err, v4.ErrInvalidChunkSignature) || errors.Is(err, v4.ErrInvalidTrailerSignature) {
+		return s3errors.GetAPIError(s3errors.ErrSignatureDoesNotMatch)
+	}
+
+	if errors.Is(err, v4.ErrMalformedTrailer) {
+		return s3errors.GetAPIError(s3errors.ErrMalformedTrailer)
+	}
+
+	if errors.Is(err, v4.ErrMissingSeparator) || errors.Is(err, v4.ErrNoChunksSeparator) ||
+		errors.Is(err, v4.ErrLineTooLong) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return s3errors.GetAPIError(s3errors.ErrIncompleteBody)
+	}
+
 	return s3errors.GetAPIError(s3errors.ErrInternalError)
 }
 
@@ -75,6 +96,53 @@ func (h *handler) getBucketAndCheckOwner(r *http.Request, bucket string, header
 	return bktInfo, checkOwner(bktInfo, expected)
 }
 
+// CheckBucketWritable returns InvalidBucketState error if settings of the
+// requested bucket are written by a newer gateway version. Missing buckets are
+// left to the handler.
+func (h *handler) CheckBucketWritable(r *http.Request) error {
+	reqInfo := api.GetReqInfo(r.Context())
+	if reqInfo.BucketName == "" {
+		return nil
+	}
+
+	bktInfo, err := h.obj.GetBucketInfo(r.Context(), reqInfo.BucketName)
+	if err != nil {
+		return nil
+	}
+
+	settings, err := h.obj.GetBucketSettings(r.Context(), bktInfo)
+	if err != nil {
+		return fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	if !settings.SchemaSupported() {
+		return s3errors.GetAPIError(s3errors.ErrBucketSettingsSchemaUnsupported)
+	}
+
+	return nil
+}
+
+// getPayloadSize returns the size of the object payload sent in the request body.
+// For aws-chunked bodies Content-Length includes chunk framing, so the size is
+// taken from X-Amz-Decoded-Content-Length.
+func getPayloadSize(r *http.Request) (int64, error) {
+	if !auth.IsStreamingPayload(r.Header) {
+		return r.ContentLength, nil
+	}
+
+	decoded := r.Header.Get(api.AmzDecodedContentLength)
+	if decoded == "" {
+		return 0, s3errors.GetAPIError(s3errors.ErrMissingContentLength)
+	}
+
+	size, err := strconv.ParseInt(decoded, 10, 64)
+	if err != nil || size < 0 {
+		return 0, s3errors.GetAPIError(s3errors.ErrMissingContentLength)
+	}
+
+	return size, nil
+}
+
 func parseRange(s string) (*layer.RangeParams, error) {
 	if s == "" {
 		return nil, nil
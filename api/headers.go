@@ -7,12 +7,14 @@ const (
 	AmzMetadataDirective      = "X-Amz-Metadata-Directive"
 	AmzTaggingDirective       = "X-Amz-Tagging-Directive"
 	AmzVersionID              = "X-Amz-Version-Id"
+	AmzCopySourceVersionID    = "X-Amz-Copy-Source-Version-Id"
 	AmzTaggingCount           = "X-Amz-Tagging-Count"
 	AmzTagging                = "X-Amz-Tagging"
 	AmzDeleteMarker           = "X-Amz-Delete-Marker"
 	AmzCopySource             = "X-Amz-Copy-Source"
 	AmzCopySourceRange        = "X-Amz-Copy-Source-Range"
 	AmzDate                   = "X-Amz-Date"
+	AmzDecodedContentLength   = "X-Amz-Decoded-Content-Length"
 
 	LastModified       = "Last-Modified"
 	Date               = "Date"
@@ -38,6 +40,7 @@ const (
 	IfUnmodifiedSince  = "If-Unmodified-Since"
 	IfMatch            = "If-Match"
 	IfNoneMatch        = "If-None-Match"
+	IfRange            = "If-Range"
 
 	AmzCopyIfModifiedSince       = "X-Amz-Copy-Source-If-Modified-Since"
 	AmzCopyIfUnmodifiedSince     = "X-Amz-Copy-Source-If-Unmodified-Since"
@@ -57,6 +60,7 @@ const (
 	AmzObjectAttributes          = "X-Amz-Object-Attributes"
 	AmzMaxParts                  = "X-Amz-Max-Parts"
 	AmzPartNumberMarker          = "X-Amz-Part-Number-Marker"
+	AmzContentSha256             = "X-Amz-Content-Sha256"
 
 	AmzServerSideEncryptionCustomerAlgorithm = "x-amz-server-side-encryption-customer-algorithm"
 	AmzServerSideEncryptionCustomerKey       = "x-amz-server-side-encryption-customer-key"
@@ -64,6 +68,30 @@ const (
 
 	ContainerID = "X-Container-Id"
 
+	// MoveSource makes CopyObject delete the source object once it's copied,
+	// so a key can be renamed with a single request.
+	MoveSource = "X-Move-Source"
+
+	// BucketCompression enables compression of objects payloads stored in the
+	// created bucket, the only supported value is "zstd".
+	BucketCompression = "X-Bucket-Compression"
+
+	// BucketDeduplication makes the created bucket store identical payloads
+	// once.
+	BucketDeduplication = "X-Bucket-Deduplication"
+
+	// RequestTimeout is a client hint limiting the time the gateway spends on
+	// a request, either a Go duration ("1m30s") or a number of seconds.
+	RequestTimeout = "X-Request-Timeout"
+
+	// DecompressedContentLength is the size of the gzip compressed payload of
+	// PutObject after decompression.
+	DecompressedContentLength = "X-Decompressed-Content-Length"
+
+	// VerifyChecksum makes GetObject verify the stored checksum of the whole
+	// payload while it's sent.
+	VerifyChecksum = "X-Verify-Checksum"
+
 	AccessControlAllowOrigin      = "Access-Control-Allow-Origin"
 	AccessControlAllowMethods     = "Access-Control-Allow-Methods"
 	AccessControlExposeHeaders    = "Access-Control-Expose-Headers"
@@ -83,6 +111,9 @@ const (
 // S3 request query params.
 const (
 	QueryVersionID = "versionId"
+	// QueryTransform is a gateway extension requesting derived content of
+	// the object, see transform package.
+	QueryTransform = "x-transform"
 )
 
 // ResponseModifiers maps response modifies headers to regular headers.
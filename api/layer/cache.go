@@ -55,6 +55,18 @@ func NewCache(cfg *CachesConfig) *Cache {
 	}
 }
 
+// Stats returns usage statistics of caches by their names.
+func (c *Cache) Stats() map[string]cache.Stats {
+	return map[string]cache.Stats{
+		"objects":       c.objCache.Stats(),
+		"list":          c.listsCache.Stats(),
+		"names":         c.namesCache.Stats(),
+		"buckets":       c.bucketCache.Stats(),
+		"system":        c.systemCache.Stats(),
+		"accesscontrol": c.accessCache.Stats(),
+	}
+}
+
 func (c *Cache) GetBucket(name string) *data.BucketInfo {
 	return c.bucketCache.Get(name)
 }
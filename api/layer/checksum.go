@@ -0,0 +1,62 @@
+package layer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+// checksumVerifier hashes the stored payload while it's read and holds the
+// last write back until the hash is verified, so clients don't get the whole
+// payload if it's corrupted.
+type checksumVerifier struct {
+	w        io.Writer
+	hash     hash.Hash
+	expected []byte
+	held     []byte
+}
+
+// ChecksumVerifiable checks if the stored checksum of the object can be
+// verified on read. Payloads of compressed objects are read partially, so
+// they can't be verified.
+func ChecksumVerifiable(objInfo *data.ObjectInfo) bool {
+	if _, ok := objInfo.Headers[AttributeCompressionAlgorithm]; ok {
+		return false
+	}
+
+	hash, err := hex.DecodeString(objInfo.HashSum)
+	return err == nil && len(hash) == sha256.Size
+}
+
+func newChecksumVerifier(objInfo *data.ObjectInfo, w io.Writer) *checksumVerifier {
+	expected, _ := hex.DecodeString(objInfo.HashSum)
+
+	return &checksumVerifier{w: w, hash: sha256.New(), expected: expected}
+}
+
+func (v *checksumVerifier) Write(p []byte) (int, error) {
+	if len(v.held) > 0 {
+		if _, err := v.w.Write(v.held); err != nil {
+			return 0, err
+		}
+	}
+	v.held = append(v.held[:0], p...)
+
+	return len(p), nil
+}
+
+// verify writes the held data if the hash of the read payload matches the
+// stored one.
+func (v *checksumVerifier) verify() error {
+	if !bytes.Equal(v.hash.Sum(nil), v.expected) {
+		return s3errors.GetAPIError(s3errors.ErrChecksumMismatch)
+	}
+
+	_, err := v.w.Write(v.held)
+	return err
+}
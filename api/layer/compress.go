@@ -0,0 +1,105 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/compression"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// compressionHeaders lists attributes of compressed objects.
+var compressionHeaders = []string{AttributeCompressionAlgorithm, AttributeDecompressedSize, AttributeCompressionBlockSize}
+
+// compressionInfo returns parameters of the object payload compression,
+// false is returned if the payload isn't compressed.
+func compressionInfo(headers map[string]string) (compression.Info, bool, error) {
+	algorithm, ok := headers[AttributeCompressionAlgorithm]
+	if !ok {
+		return compression.Info{}, false, nil
+	}
+
+	if algorithm != compression.AlgorithmZstd {
+		return compression.Info{}, true, fmt.Errorf("unknown compression algorithm: %s", algorithm)
+	}
+
+	size, err := strconv.ParseUint(headers[AttributeDecompressedSize], 10, 64)
+	if err != nil {
+		return compression.Info{}, true, fmt.Errorf("parse decompressed size: %w", err)
+	}
+
+	blockSize, err := strconv.ParseUint(headers[AttributeCompressionBlockSize], 10, 64)
+	if err != nil || blockSize == 0 {
+		return compression.Info{}, true, fmt.Errorf("invalid compression block size: '%s'", headers[AttributeCompressionBlockSize])
+	}
+
+	return compression.Info{Size: size, BlockSize: blockSize}, true, nil
+}
+
+// getCompressedObject writes decompressed payload (or its range) to p.Writer.
+// The index of compressed blocks is read from the end of the payload first,
+// then only the blocks covering the requested range.
+func (n *layer) getCompressedObject(ctx context.Context, p *GetObjectParams, payloadID oid.ID, info compression.Info) error {
+	if info.Size == 0 {
+		return nil
+	}
+
+	meta, err := n.objectHead(ctx, p.BucketInfo, payloadID)
+	if err != nil {
+		return fmt.Errorf("head compressed object: %w", err)
+	}
+
+	if meta.PayloadSize() < info.IndexSize() {
+		return fmt.Errorf("compressed payload size %d is less than index size %d", meta.PayloadSize(), info.IndexSize())
+	}
+
+	rawIndex, err := n.readObjectRange(ctx, p.BucketInfo, payloadID, meta.PayloadSize()-info.IndexSize(), info.IndexSize())
+	if err != nil {
+		return fmt.Errorf("init index reader: %w", err)
+	}
+
+	raw, err := io.ReadAll(rawIndex)
+	if err != nil {
+		return fmt.Errorf("read compression index: %w", err)
+	}
+
+	index, err := compression.ParseIndex(info, raw)
+	if err != nil {
+		return err
+	}
+
+	var rng *compression.Range
+	if p.Range != nil {
+		rng = &compression.Range{Start: p.Range.Start, End: p.Range.End}
+	}
+
+	dec, err := compression.NewDecompressor(info, index, rng)
+	if err != nil {
+		return fmt.Errorf("creating decompressor: %w", err)
+	}
+
+	payload, err := n.readObjectRange(ctx, p.BucketInfo, payloadID, dec.CompressedOffset(), dec.CompressedLength())
+	if err != nil {
+		return fmt.Errorf("init object payload reader: %w", err)
+	}
+	dec.SetReader(payload)
+
+	written, err := io.Copy(p.Writer, dec)
+	if err != nil {
+		return fmt.Errorf("copy object payload written: '%d', decLength: '%d': %w", written, dec.DecompressedLength(), err)
+	}
+
+	return nil
+}
+
+func (n *layer) readObjectRange(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID, off, ln uint64) (io.Reader, error) {
+	return n.initObjectPayloadReader(ctx, getParams{
+		oid:     id,
+		bktInfo: bktInfo,
+		off:     off,
+		ln:      ln,
+	})
+}
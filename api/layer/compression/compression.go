@@ -0,0 +1,251 @@
+// Package compression implements block-indexed zstd framing of object payloads.
+//
+// A payload is split into blocks of the fixed size, every block is compressed
+// into an independent zstd frame. The frames are followed by the index:
+// compressed sizes of all frames as big-endian uint32 values. Knowing the
+// decompressed size and the block size, a reader locates the index at the end
+// of the payload and decompresses only the frames covering a requested range.
+package compression
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// AlgorithmZstd is the only supported compression algorithm.
+const AlgorithmZstd = "zstd"
+
+// DefaultBlockSize is the size of decompressed payload stored in one frame.
+const DefaultBlockSize = 1 << 20 // 1MB
+
+const indexEntrySize = 4
+
+var (
+	// EncodeAll and DecodeAll are safe for concurrent use.
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// Info describes a compressed payload.
+type Info struct {
+	// Size is the decompressed payload size.
+	Size uint64
+	// BlockSize is the decompressed size of every frame but the last one.
+	BlockSize uint64
+}
+
+// Range stores decompressed payload interval.
+type Range struct {
+	Start uint64
+	End   uint64
+}
+
+// Index contains offsets of the frames in the compressed payload, the last
+// element is the offset of the index itself.
+type Index []uint64
+
+type compressor struct {
+	src   io.Reader
+	block []byte
+	buf   []byte
+	out   []byte
+	index []byte
+	done  bool
+}
+
+// Decompressor allows decompressing a range of compressed payload.
+type Decompressor struct {
+	reader io.Reader
+	info   Info
+	index  Index
+
+	block    uint64
+	skip     uint64
+	remain   uint64
+	frame    []byte
+	buf      []byte
+	out      []byte
+	rangeLen uint64
+	length   uint64
+}
+
+// Blocks returns the number of frames in the payload.
+func (i Info) Blocks() uint64 {
+	if i.BlockSize == 0 {
+		return 0
+	}
+	return (i.Size + i.BlockSize - 1) / i.BlockSize
+}
+
+// IndexSize returns the size of the index at the end of the payload.
+func (i Info) IndexSize() uint64 {
+	return i.Blocks() * indexEntrySize
+}
+
+// NewReader returns a reader of compressed payload read from src.
+func NewReader(src io.Reader, blockSize uint64) io.Reader {
+	return &compressor{
+		src:   src,
+		block: make([]byte, blockSize),
+	}
+}
+
+func (c *compressor) Read(p []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+func (c *compressor) next() error {
+	n, err := io.ReadFull(c.src, c.block)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	c.buf = c.buf[:0]
+	if n > 0 {
+		c.buf = encoder.EncodeAll(c.block[:n], c.buf)
+		c.index = binary.BigEndian.AppendUint32(c.index, uint32(len(c.buf)))
+	}
+
+	if n < len(c.block) {
+		c.buf = append(c.buf, c.index...)
+		c.done = true
+	}
+
+	c.out = c.buf
+	return nil
+}
+
+// ParseIndex parses the index read from the end of the payload.
+func ParseIndex(info Info, raw []byte) (Index, error) {
+	if uint64(len(raw)) != info.IndexSize() {
+		return nil, fmt.Errorf("invalid index size: %d, expected: %d", len(raw), info.IndexSize())
+	}
+
+	index := make(Index, info.Blocks()+1)
+	for i := uint64(0); i < info.Blocks(); i++ {
+		index[i+1] = index[i] + uint64(binary.BigEndian.Uint32(raw[i*indexEntrySize:]))
+	}
+
+	return index, nil
+}
+
+// NewDecompressor creates a decompressor of the range (full payload if r is
+// nil). Read the compressed payload starting from CompressedOffset and
+// having CompressedLength size and pass it to SetReader.
+func NewDecompressor(info Info, index Index, r *Range) (*Decompressor, error) {
+	if uint64(len(index)) != info.Blocks()+1 {
+		return nil, fmt.Errorf("invalid index length: %d", len(index))
+	}
+
+	rng := Range{End: info.Size - 1}
+	if r != nil {
+		rng = *r
+	}
+
+	if info.Size == 0 || rng.Start > rng.End || rng.End >= info.Size {
+		return nil, fmt.Errorf("invalid range: %d-%d, size: %d", rng.Start, rng.End, info.Size)
+	}
+
+	first, last := rng.Start/info.BlockSize, rng.End/info.BlockSize
+
+	return &Decompressor{
+		info:     info,
+		index:    index,
+		block:    first,
+		skip:     rng.Start - first*info.BlockSize,
+		remain:   last - first + 1,
+		rangeLen: rng.End - rng.Start + 1,
+		length:   rng.End - rng.Start + 1,
+	}, nil
+}
+
+// CompressedOffset returns the offset of the first frame covering the range.
+func (d *Decompressor) CompressedOffset() uint64 {
+	return d.index[d.block]
+}
+
+// CompressedLength returns the size of the frames covering the range.
+func (d *Decompressor) CompressedLength() uint64 {
+	return d.index[d.block+d.remain] - d.index[d.block]
+}
+
+// DecompressedLength returns the size of the range.
+func (d *Decompressor) DecompressedLength() uint64 {
+	return d.length
+}
+
+// SetReader sets the reader of the compressed frames.
+func (d *Decompressor) SetReader(r io.Reader) {
+	d.reader = r
+}
+
+func (d *Decompressor) Read(p []byte) (int, error) {
+	if d.rangeLen == 0 {
+		return 0, io.EOF
+	}
+
+	for len(d.out) == 0 {
+		if d.remain == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.out)
+	if uint64(n) > d.rangeLen {
+		n = int(d.rangeLen)
+	}
+	d.out = d.out[n:]
+	d.rangeLen -= uint64(n)
+
+	return n, nil
+}
+
+func (d *Decompressor) next() error {
+	if d.reader == nil {
+		return errors.New("reader isn't set")
+	}
+
+	size := d.index[d.block+1] - d.index[d.block]
+	if uint64(cap(d.frame)) < size {
+		d.frame = make([]byte, size)
+	}
+	d.frame = d.frame[:size]
+
+	if _, err := io.ReadFull(d.reader, d.frame); err != nil {
+		return fmt.Errorf("read frame %d: %w", d.block, err)
+	}
+
+	var err error
+	if d.buf, err = decoder.DecodeAll(d.frame, d.buf[:0]); err != nil {
+		return fmt.Errorf("decompress frame %d: %w", d.block, err)
+	}
+
+	if d.skip > uint64(len(d.buf)) {
+		return fmt.Errorf("invalid frame %d size: %d", d.block, len(d.buf))
+	}
+
+	d.out = d.buf[d.skip:]
+	d.skip = 0
+	d.block++
+	d.remain--
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testBlockSize = 1024
+
+func compress(t *testing.T, payload []byte) (Info, Index, []byte) {
+	compressed, err := io.ReadAll(NewReader(bytes.NewReader(payload), testBlockSize))
+	require.NoError(t, err)
+
+	info := Info{Size: uint64(len(payload)), BlockSize: testBlockSize}
+	require.GreaterOrEqual(t, uint64(len(compressed)), info.IndexSize())
+
+	indexOffset := uint64(len(compressed)) - info.IndexSize()
+	index, err := ParseIndex(info, compressed[indexOffset:])
+	require.NoError(t, err)
+	require.Equal(t, indexOffset, index[len(index)-1])
+
+	return info, index, compressed
+}
+
+func decompress(t *testing.T, info Info, index Index, compressed []byte, r *Range) []byte {
+	dec, err := NewDecompressor(info, index, r)
+	require.NoError(t, err)
+
+	off, ln := dec.CompressedOffset(), dec.CompressedLength()
+	dec.SetReader(bytes.NewReader(compressed[off : off+ln]))
+
+	res, err := io.ReadAll(dec)
+	require.NoError(t, err)
+	require.Len(t, res, int(dec.DecompressedLength()))
+
+	return res
+}
+
+func TestCompression(t *testing.T) {
+	random := make([]byte, 3*testBlockSize+100)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	for _, payload := range [][]byte{
+		bytes.Repeat([]byte("a"), 10),
+		bytes.Repeat([]byte("abcd"), testBlockSize),
+		random,
+	} {
+		t.Run(strconv.Itoa(len(payload)), func(t *testing.T) {
+			info, index, compressed := compress(t, payload)
+			require.Equal(t, payload, decompress(t, info, index, compressed, nil))
+
+			for _, r := range []Range{
+				{Start: 0, End: 0},
+				{Start: 5, End: 9},
+				{Start: uint64(len(payload)) - 1, End: uint64(len(payload)) - 1},
+				{Start: testBlockSize - 1, End: testBlockSize + 1},
+				{Start: testBlockSize + 5, End: uint64(len(payload)) - 3},
+			} {
+				if r.Start > r.End || r.End >= uint64(len(payload)) {
+					continue
+				}
+				require.Equal(t, payload[r.Start:r.End+1], decompress(t, info, index, compressed, &r))
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		info, index, compressed := compress(t, nil)
+		require.Empty(t, compressed)
+		require.Equal(t, Index{0}, index)
+
+		_, err := NewDecompressor(info, index, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("compressible", func(t *testing.T) {
+		payload := bytes.Repeat([]byte("neofs"), 10*testBlockSize)
+		_, _, compressed := compress(t, payload)
+		require.Less(t, len(compressed), len(payload)/10)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"go.uber.org/zap"
 )
 
@@ -93,9 +94,12 @@ func (n *layer) containerInfo(ctx context.Context, idCnr cid.ID) (*data.BucketIn
 }
 
 func (n *layer) containerList(ctx context.Context) ([]*data.BucketInfo, error) {
+	return n.userContainerList(ctx, n.Owner(ctx))
+}
+
+func (n *layer) userContainerList(ctx context.Context, own user.ID) ([]*data.BucketInfo, error) {
 	var (
 		err error
-		own = n.Owner(ctx)
 		res []cid.ID
 		rid = api.GetRequestID(ctx)
 	)
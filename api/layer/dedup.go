@@ -0,0 +1,236 @@
+package layer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// deduplicationHeaders lists attributes of objects linked to a shared payload.
+var deduplicationHeaders = []string{AttributePayloadObject, AttributePayloadSize, AttributePayloadHash}
+
+// payloadObjectID returns the ID of the object storing the payload of objInfo.
+func payloadObjectID(objInfo *data.ObjectInfo) (oid.ID, error) {
+	linked, ok := objInfo.Headers[AttributePayloadObject]
+	if !ok {
+		return objInfo.ID, nil
+	}
+
+	var id oid.ID
+	if err := id.DecodeString(linked); err != nil {
+		return oid.ID{}, fmt.Errorf("invalid payload object id '%s': %w", linked, err)
+	}
+
+	return id, nil
+}
+
+// lockPayload serializes updates of the reference counter of the payload,
+// also between gateways if distributed locking is enabled.
+func (n *layer) lockPayload(ctx context.Context, bktInfo *data.BucketInfo, hash string) (func(), error) {
+	return n.lock(ctx, bktInfo, payloadLockKeyPrefix+hash)
+}
+
+// putDeduplicated stores the payload once per bucket. If a payload with the
+// same SHA-256 is already stored, an object without payload linked to it is
+// created instead of a copy. When declaredHash is set and such a payload
+// exists, the data is only read to verify the hash and isn't uploaded at all.
+// Otherwise, the payload is uploaded and dropped after if it turns out to be
+// a duplicate.
+func (n *layer) putDeduplicated(ctx context.Context, p *PutObjectParams, prm PrmObjectCreate, declaredHash string) (oid.ID, []byte, error) {
+	if hash, err := hex.DecodeString(declaredHash); err == nil && len(hash) == sha256.Size {
+		declaredHash = hex.EncodeToString(hash)
+		unlock, err := n.lockPayload(ctx, p.BktInfo, declaredHash)
+		if err != nil {
+			return oid.ID{}, nil, err
+		}
+		payload, err := n.treeService.GetDeduplicatedPayload(ctx, p.BktInfo, declaredHash)
+		if err == nil {
+			defer unlock()
+
+			actual := sha256.New()
+			if _, err = io.Copy(actual, prm.Payload); err != nil {
+				return oid.ID{}, nil, fmt.Errorf("read payload: %w", err)
+			}
+			if !bytes.Equal(actual.Sum(nil), hash) {
+				return oid.ID{}, nil, s3errors.GetAPIError(s3errors.ErrContentSHA256Mismatch)
+			}
+
+			id, err := n.linkPayload(ctx, p, prm, payload)
+			return id, hash, err
+		}
+		unlock()
+
+		if !errors.Is(err, ErrNodeNotFound) {
+			return oid.ID{}, nil, fmt.Errorf("get deduplicated payload: %w", err)
+		}
+	}
+
+	id, hash, err := n.objectPutAndHash(ctx, prm, p.BktInfo)
+	if err != nil {
+		return oid.ID{}, nil, err
+	}
+
+	hashStr := hex.EncodeToString(hash)
+	unlock, err := n.lockPayload(ctx, p.BktInfo, hashStr)
+	if err != nil {
+		n.deleteDuplicate(ctx, p.BktInfo, id)
+		return oid.ID{}, nil, err
+	}
+	defer unlock()
+
+	payload, err := n.treeService.GetDeduplicatedPayload(ctx, p.BktInfo, hashStr)
+	if errors.Is(err, ErrNodeNotFound) {
+		payload = &data.DeduplicatedPayload{Hash: hashStr, OID: id, Refs: 1}
+		err = n.treeService.PutDeduplicatedPayload(ctx, p.BktInfo, payload)
+	} else if err == nil {
+		var linkID oid.ID
+		if linkID, err = n.linkPayload(ctx, p, prm, payload); err == nil {
+			n.deleteDuplicate(ctx, p.BktInfo, id)
+			return linkID, hash, nil
+		}
+	}
+
+	if err != nil {
+		n.deleteDuplicate(ctx, p.BktInfo, id)
+		return oid.ID{}, nil, fmt.Errorf("deduplicate payload: %w", err)
+	}
+
+	return id, hash, nil
+}
+
+// linkPayload creates an object without payload referring to the stored one
+// and increments its reference counter.
+func (n *layer) linkPayload(ctx context.Context, p *PutObjectParams, prm PrmObjectCreate, payload *data.DeduplicatedPayload) (oid.ID, error) {
+	linkHeaders := map[string]string{
+		AttributePayloadObject: payload.OID.EncodeToString(),
+		AttributePayloadSize:   strconv.FormatInt(p.Size, 10),
+		AttributePayloadHash:   payload.Hash,
+	}
+
+	prm.Payload = bytes.NewReader(nil)
+	prm.PayloadSize = 0
+	prm.Attributes = prm.Attributes[:len(prm.Attributes):len(prm.Attributes)]
+	for k, v := range linkHeaders {
+		prm.Attributes = append(prm.Attributes, [2]string{k, v})
+	}
+
+	id, _, err := n.objectPutAndHash(ctx, prm, p.BktInfo)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("put linked object: %w", err)
+	}
+
+	payload.Refs++
+	if err = n.treeService.PutDeduplicatedPayload(ctx, p.BktInfo, payload); err != nil {
+		n.deleteDuplicate(ctx, p.BktInfo, id)
+		return oid.ID{}, fmt.Errorf("put deduplicated payload: %w", err)
+	}
+
+	for k, v := range linkHeaders {
+		p.Header[k] = v
+	}
+
+	return id, nil
+}
+
+func (n *layer) deleteDuplicate(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID) {
+	if err := n.objectDelete(ctx, bktInfo, id); err != nil {
+		n.log.Warn("couldn't delete duplicated object",
+			zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+			zap.Stringer("oid", id), zap.Error(err))
+	}
+}
+
+// releasePayload deletes the stored object which isn't added to the tree,
+// the reference to the shared payload is released if it's deduplicated.
+// Failures are only logged.
+func (n *layer) releasePayload(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID, hash []byte, deduplicated bool) {
+	var err error
+	if deduplicated {
+		err = n.deleteObjectPayload(ctx, bktInfo, &data.NodeVersion{
+			BaseNodeVersion: data.BaseNodeVersion{OID: id, ETag: hex.EncodeToString(hash)},
+		})
+	} else {
+		err = n.objectDelete(ctx, bktInfo, id)
+	}
+
+	if err != nil {
+		n.log.Warn("couldn't release payload of rejected object",
+			zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+			zap.Stringer("oid", id), zap.Error(err))
+	}
+}
+
+// deleteObjectPayload deletes the object of the version. Shared payloads are
+// kept until the last object referring to them is deleted. Whether the object
+// refers to the shared payload is decided by the payload record and the
+// object attributes, so objects written while the bucket deduplicated
+// payloads are handled correctly after it's disabled.
+func (n *layer) deleteObjectPayload(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion) error {
+	// shared payloads are recorded by their SHA-256 which is the ETag of the
+	// objects referring to them
+	unlock, err := n.lockPayload(ctx, bktInfo, version.ETag)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	payload, err := n.treeService.GetDeduplicatedPayload(ctx, bktInfo, version.ETag)
+	if err != nil {
+		if errors.Is(err, ErrNodeNotFound) {
+			return n.objectDelete(ctx, bktInfo, version.OID)
+		}
+		return fmt.Errorf("get deduplicated payload: %w", err)
+	}
+
+	if version.OID != payload.OID {
+		linked, err := n.refersToPayload(ctx, bktInfo, version.OID, payload)
+		if err != nil {
+			return err
+		}
+		if err = n.objectDelete(ctx, bktInfo, version.OID); err != nil || !linked {
+			return err
+		}
+	}
+
+	if payload.Refs > 1 {
+		payload.Refs--
+		return n.treeService.PutDeduplicatedPayload(ctx, bktInfo, payload)
+	}
+
+	if err = n.objectDelete(ctx, bktInfo, payload.OID); err != nil {
+		return err
+	}
+
+	return n.treeService.DeleteDeduplicatedPayload(ctx, bktInfo, payload.Hash)
+}
+
+// refersToPayload checks if the object is linked to the shared payload, it
+// may store the same payload itself if it's written without deduplication.
+func (n *layer) refersToPayload(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID, payload *data.DeduplicatedPayload) (bool, error) {
+	meta, err := n.objectHead(ctx, bktInfo, id)
+	if err != nil {
+		if errors.Is(err, apistatus.ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("head object: %w", err)
+	}
+
+	for _, attr := range meta.Attributes() {
+		if attr.Key() == AttributePayloadObject {
+			return attr.Value() == payload.OID.EncodeToString(), nil
+		}
+	}
+
+	return false, nil
+}
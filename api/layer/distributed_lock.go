@@ -0,0 +1,191 @@
+package layer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+const (
+	attributeLock        = ".s3-lock"
+	attributeLockExpires = ".s3-lock-expires"
+
+	settingsLockKey        = "settings"
+	multipartLockKeyPrefix = "multipart/"
+	payloadLockKeyPrefix   = "payload/"
+
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// DistributedLockConfig defines locking of bucket settings updates, multipart
+// upload completion and updates of shared payload reference counters between
+// gateways serving the same buckets.
+//
+// The lock is a system object of the bucket container with the key in
+// .s3-lock attribute. The gateway puts the object only if no other unexpired
+// lock is found, and then searches the locks again, the lock is acquired if
+// the only lock found is the put one. Otherwise, the object is removed and the
+// attempt is retried.
+type DistributedLockConfig struct {
+	Enabled bool
+	// TTL is a time the lock is considered held if the gateway holding it
+	// failed to release it.
+	TTL time.Duration
+	// Timeout limits waiting for the lock held by another gateway.
+	Timeout time.Duration
+}
+
+// lock serializes operations on the key of the bucket by the gateway and
+// also between gateways if distributed locking is enabled. It returns
+// OperationAborted error if the lock isn't acquired in time.
+func (n *layer) lock(ctx context.Context, bktInfo *data.BucketInfo, key string) (func(), error) {
+	unlock := n.keyLocks.Lock(bktInfo.CID.EncodeToString() + "@" + key)
+	if !n.distributedLock.Enabled {
+		return unlock, nil
+	}
+
+	lockID, err := n.acquireDistributedLock(ctx, bktInfo, key)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return func() {
+		// the lock expires anyway, so the failure is not critical
+		if err := n.objectDelete(ctx, bktInfo, lockID); err != nil {
+			n.log.Warn("couldn't release distributed lock", zap.String("key", key),
+				zap.Stringer("cid", bktInfo.CID), zap.Stringer("oid", lockID), zap.Error(err))
+		}
+		unlock()
+	}, nil
+}
+
+func (n *layer) acquireDistributedLock(ctx context.Context, bktInfo *data.BucketInfo, key string) (oid.ID, error) {
+	deadline := time.Now().Add(n.distributedLock.Timeout)
+
+	for {
+		locks, err := n.activeDistributedLocks(ctx, bktInfo, key)
+		if err != nil {
+			return oid.ID{}, err
+		}
+
+		if len(locks) == 0 {
+			lockID, err := n.putDistributedLock(ctx, bktInfo, key)
+			if err != nil {
+				return oid.ID{}, err
+			}
+
+			if locks, err = n.activeDistributedLocks(ctx, bktInfo, key); err != nil {
+				return oid.ID{}, err
+			}
+			if len(locks) == 1 && locks[0] == lockID {
+				return lockID, nil
+			}
+
+			// another gateway put the lock concurrently, both back off
+			if err = n.objectDelete(ctx, bktInfo, lockID); err != nil {
+				return oid.ID{}, fmt.Errorf("delete conflicting lock: %w", err)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return oid.ID{}, fmt.Errorf("%w: lock '%s' is held by another gateway",
+				s3errors.GetAPIError(s3errors.ErrOperationAborted), key)
+		}
+
+		// random delay prevents repeated conflicts of concurrent attempts
+		delay := lockRetryInterval + time.Duration(rand.Int63n(int64(lockRetryInterval)))
+		select {
+		case <-ctx.Done():
+			return oid.ID{}, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (n *layer) putDistributedLock(ctx context.Context, bktInfo *data.BucketInfo, key string) (oid.ID, error) {
+	// the gateway clock is used, client time mustn't affect expiration
+	now := time.Now()
+	expiresAt := now.Add(n.distributedLock.TTL)
+
+	_, expEpoch, err := n.neoFS.TimeToEpoch(ctx, now, expiresAt)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("compute lock expiration epoch: %w", err)
+	}
+
+	prm := PrmObjectCreate{
+		Container:    bktInfo.CID,
+		Creator:      bktInfo.Owner,
+		CreationTime: now,
+		Attributes: [][2]string{
+			{attributeLock, key},
+			{attributeLockExpires, strconv.FormatInt(expiresAt.UnixMilli(), 10)},
+			{object.AttributeExpirationEpoch, strconv.FormatUint(expEpoch, 10)},
+		},
+		Payload: strings.NewReader(""),
+	}
+
+	id, _, err := n.objectPutAndHash(ctx, prm, bktInfo)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("put lock: %w", err)
+	}
+
+	return id, nil
+}
+
+// activeDistributedLocks returns unexpired locks of the key.
+func (n *layer) activeDistributedLocks(ctx context.Context, bktInfo *data.BucketInfo, key string) ([]oid.ID, error) {
+	prm := PrmObjectSearch{
+		Container: bktInfo.CID,
+		Attribute: attributeLock,
+		Value:     key,
+	}
+	n.prepareAuthParameters(ctx, &prm.PrmAuth, bktInfo.Owner)
+
+	ids, err := n.neoFS.SearchObjects(ctx, prm)
+	if err != nil {
+		return nil, fmt.Errorf("search locks: %w", err)
+	}
+
+	now := time.Now()
+	res := ids[:0]
+	for _, id := range ids {
+		header, err := n.objectHead(ctx, bktInfo, id)
+		if err != nil {
+			// the lock is released since it has been found
+			if errors.Is(err, apistatus.ErrObjectNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("head lock: %w", err)
+		}
+
+		if lockExpired(header, now) {
+			continue
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
+
+func lockExpired(header *object.Object, now time.Time) bool {
+	for _, attr := range header.Attributes() {
+		if attr.Key() == attributeLockExpires {
+			ms, err := strconv.ParseInt(attr.Value(), 10, 64)
+			return err != nil || !now.Before(time.UnixMilli(ms))
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+package layer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistributedLock(t *testing.T) {
+	tc := prepareContext(t)
+	l := tc.layer.(*layer)
+	l.distributedLock = DistributedLockConfig{Enabled: true, TTL: time.Minute, Timeout: 300 * time.Millisecond}
+
+	unlock, err := l.lock(tc.ctx, tc.bktInfo, "key")
+	require.NoError(t, err)
+	require.Len(t, tc.testNeoFS.AllObjects(tc.bktInfo.CID), 1)
+
+	// lock held by another gateway
+	_, err = l.acquireDistributedLock(tc.ctx, tc.bktInfo, "key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrOperationAborted))
+
+	// other keys aren't locked
+	otherID, err := l.acquireDistributedLock(tc.ctx, tc.bktInfo, "other-key")
+	require.NoError(t, err)
+	require.NoError(t, l.objectDelete(tc.ctx, tc.bktInfo, otherID))
+
+	unlock()
+	require.Empty(t, tc.testNeoFS.AllObjects(tc.bktInfo.CID))
+
+	unlock, err = l.lock(tc.ctx, tc.bktInfo, "key")
+	require.NoError(t, err)
+	unlock()
+
+	// locks of failed gateways expire
+	l.distributedLock.TTL = -time.Second
+	_, err = l.putDistributedLock(tc.ctx, tc.bktInfo, "key")
+	require.NoError(t, err)
+	locks, err := l.activeDistributedLocks(tc.ctx, tc.bktInfo, "key")
+	require.NoError(t, err)
+	require.Empty(t, locks)
+}
+
+func TestBucketSettingsRevision(t *testing.T) {
+	tc := prepareContext(t)
+	tc.layer.(*layer).distributedLock = DistributedLockConfig{Enabled: true, TTL: time.Minute, Timeout: time.Second}
+
+	settings, err := tc.layer.GetBucketSettings(tc.ctx, tc.bktInfo)
+	require.NoError(t, err)
+
+	first, second := *settings, *settings
+	first.Versioning = data.VersioningEnabled
+	require.NoError(t, tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{BktInfo: tc.bktInfo, Settings: &first}))
+
+	// the update based on the outdated settings is rejected
+	second.Compression = "zstd"
+	err = tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{BktInfo: tc.bktInfo, Settings: &second})
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrOperationAborted))
+
+	settings, err = tc.layer.GetBucketSettings(tc.ctx, tc.bktInfo)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), settings.Revision)
+	require.Equal(t, data.VersioningEnabled, settings.Versioning)
+
+	third := *settings
+	third.Compression = "zstd"
+	require.NoError(t, tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{BktInfo: tc.bktInfo, Settings: &third}))
+	require.Equal(t, uint64(2), third.Revision)
+
+	// locks are released
+	require.Empty(t, tc.testNeoFS.AllObjects(tc.bktInfo.CID))
+}
@@ -0,0 +1,170 @@
+package layer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"go.uber.org/zap"
+)
+
+// firstChunkSize is the size of the payload chunk an attempt must read to
+// be chosen by hedged reading.
+const firstChunkSize = 32 * 1024
+
+type (
+	// readAttempt is a result of a single payload reading attempt.
+	readAttempt struct {
+		payload io.Reader
+		chunk   []byte
+		err     error
+		cancel  context.CancelFunc
+		// id is the index of the attempt in the order of starts.
+		id int
+	}
+
+	// hedgedReader returns the first chunk read by the chosen attempt and
+	// then proceeds with reading its payload.
+	hedgedReader struct {
+		readAttempt
+	}
+)
+
+// readPayloadHedged reads the payload and, if it produces no data during
+// firstByteTimeout, starts one more identical request. The attempt which
+// returns the first chunk earlier is used, another one is canceled at once.
+func (n *layer) readPayloadHedged(ctx context.Context, prm PrmObjectRead) (io.Reader, error) {
+	results := make(chan readAttempt, 2)
+	var cancels []context.CancelFunc
+	start := func(prm PrmObjectRead) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		id := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			res := n.readFirstChunk(attemptCtx, prm, cancel)
+			res.id = id
+			results <- res
+		}()
+	}
+
+	start(prm)
+	running := 1
+
+	timer := time.NewTimer(n.firstByteTimeout)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			n.log.Debug("payload reading exceeded first byte timeout, hedging",
+				zap.Stringer("cid", prm.Container), zap.Stringer("oid", prm.Object),
+				zap.Duration("timeout", n.firstByteTimeout))
+			metrics.IncHedgedReads()
+			hedged := prm
+			hedged.Hedged = true
+			start(hedged)
+			running++
+		case res := <-results:
+			running--
+			if res.err == nil {
+				// in-flight attempts mustn't keep their streams open
+				for id, cancel := range cancels {
+					if id != res.id {
+						cancel()
+					}
+				}
+				if running > 0 {
+					go discardAttempts(results, running)
+				}
+				return &hedgedReader{readAttempt: res}, nil
+			}
+
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			// Fail immediately unless the hedged request is still in progress,
+			// slow requests are hedged, failed ones are not retried.
+			if running == 0 {
+				return nil, firstErr
+			}
+		case <-ctx.Done():
+			if running > 0 {
+				go discardAttempts(results, running)
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (n *layer) readFirstChunk(ctx context.Context, prm PrmObjectRead, cancel context.CancelFunc) readAttempt {
+	res := readAttempt{cancel: cancel}
+
+	part, err := n.neoFS.ReadObject(ctx, prm)
+	if err != nil {
+		cancel()
+		res.err = err
+		return res
+	}
+	res.payload = part.Payload
+
+	buf := make([]byte, firstChunkSize)
+	var ln int
+	for ln == 0 && err == nil {
+		ln, err = res.payload.Read(buf)
+	}
+	res.chunk = buf[:ln]
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		res.close()
+		res.err = err
+		return res
+	}
+	if err != nil {
+		// The whole payload fits the chunk, so the rest of reading is
+		// served from memory.
+		res.close()
+		res.payload = eofReader{}
+	}
+
+	return res
+}
+
+// discardAttempts closes attempts finished after another one was chosen.
+func discardAttempts(results <-chan readAttempt, count int) {
+	for i := 0; i < count; i++ {
+		res := <-results
+		if res.err == nil {
+			res.close()
+		}
+	}
+}
+
+func (a readAttempt) close() {
+	a.cancel()
+	if c, ok := a.payload.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+func (r *hedgedReader) Read(p []byte) (int, error) {
+	if len(r.chunk) > 0 {
+		ln := copy(p, r.chunk)
+		r.chunk = r.chunk[ln:]
+		return ln, nil
+	}
+
+	ln, err := r.payload.Read(p)
+	if err != nil {
+		r.cancel()
+	}
+	return ln, err
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
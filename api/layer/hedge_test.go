@@ -0,0 +1,75 @@
+package layer
+
+import (
+	"context"
+	"crypto/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowNeoFS delays the first payload reading.
+type slowNeoFS struct {
+	*TestNeoFS
+	delay    time.Duration
+	reads    atomic.Int32
+	hedged   atomic.Int32
+	canceled atomic.Int32
+}
+
+func (x *slowNeoFS) ReadObject(ctx context.Context, prm PrmObjectRead) (*ObjectPart, error) {
+	if prm.Hedged {
+		x.hedged.Add(1)
+	}
+
+	if prm.WithPayload && !prm.WithHeader && x.reads.Add(1) == 1 {
+		select {
+		case <-time.After(x.delay):
+		case <-ctx.Done():
+			x.canceled.Add(1)
+			return nil, ctx.Err()
+		}
+	}
+
+	return x.TestNeoFS.ReadObject(ctx, prm)
+}
+
+func TestHedgedPayloadReading(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		delay   time.Duration
+		timeout time.Duration
+		size    int
+		reads   int32
+	}{
+		{name: "fast", delay: 0, timeout: time.Minute, size: 10, reads: 1},
+		{name: "slow", delay: time.Minute, timeout: 10 * time.Millisecond, size: 10, reads: 2},
+		{name: "slow large", delay: time.Minute, timeout: 10 * time.Millisecond, size: 3*firstChunkSize + 1, reads: 2},
+		{name: "slow empty", delay: time.Minute, timeout: 10 * time.Millisecond, size: 0, reads: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := prepareContext(t)
+			neoFS := &slowNeoFS{TestNeoFS: tc.testNeoFS, delay: tt.delay}
+			l := tc.layer.(*layer)
+			l.neoFS = neoFS
+			l.firstByteTimeout = tt.timeout
+
+			content := make([]byte, tt.size)
+			_, err := rand.Read(content)
+			require.NoError(t, err)
+
+			tc.putObject(content)
+
+			start := time.Now()
+			_, res := tc.getObject(tc.obj, "", false)
+			require.Less(t, time.Since(start), tt.delay+time.Second)
+			require.Equal(t, content, res)
+			require.Equal(t, tt.reads, neoFS.reads.Load())
+			require.Equal(t, tt.reads-1, neoFS.hedged.Load())
+			// the slow attempt is canceled once the hedged one wins
+			require.Eventually(t, func() bool { return neoFS.canceled.Load() == tt.reads-1 }, time.Second, time.Millisecond)
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package layer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+const (
+	journalFileExt    = ".json"
+	journalTmpFileExt = ".tmp"
+)
+
+type (
+	// Journal persists intents of object writes before they're acknowledged.
+	// An intent is begun once the payload is stored and committed once the
+	// version is added to the tree and the lock is put (and the parts are
+	// deleted for completed multipart uploads) or once the failed write is
+	// rolled back, so intents pending on startup belong to writes interrupted
+	// by a crash of the gateway.
+	Journal interface {
+		// Begin persists the intent, it must survive crashes once it returns.
+		Begin(context.Context, Intent) error
+		// Commit removes the intent with the ID.
+		Commit(ctx context.Context, id string) error
+		// Pending returns intents which aren't committed.
+		Pending(context.Context) ([]Intent, error)
+	}
+
+	// Intent is a write of the object payload to the bucket.
+	Intent struct {
+		ID        string    `json:"id"`
+		Started   time.Time `json:"started"`
+		Bucket    string    `json:"bucket"`
+		Container string    `json:"container"`
+		Owner     string    `json:"owner"`
+		Object    string    `json:"object"`
+		ObjectID  string    `json:"object_id"`
+		// Hash is hex encoded SHA-256 of the payload.
+		Hash string `json:"hash,omitempty"`
+		// Deduplicated payloads are shared by other objects, so only the
+		// reference to them is released if the write is rolled back.
+		Deduplicated bool `json:"deduplicated,omitempty"`
+		// UploadID is the multipart upload completed by the write.
+		UploadID     string           `json:"upload_id,omitempty"`
+		Lock         *data.ObjectLock `json:"lock,omitempty"`
+		CopiesNumber uint32           `json:"copies_number,omitempty"`
+	}
+
+	// FileJournal keeps intents in files of the local directory, they're
+	// synced to the disk before Begin returns.
+	FileJournal struct {
+		dir string
+	}
+)
+
+// NewFileJournal creates a journal in the directory, it's created if it
+// doesn't exist.
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create journal directory: %w", err)
+	}
+
+	return &FileJournal{dir: dir}, nil
+}
+
+// Begin implements Journal. The intent is written to the temporary file
+// renamed once it's synced, so partially written intents are never pending.
+func (j *FileJournal) Begin(_ context.Context, in Intent) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("marshal intent: %w", err)
+	}
+
+	tmp := filepath.Join(j.dir, in.ID+journalTmpFileExt)
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(raw); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write intent: %w", err)
+	}
+
+	if err = os.Rename(tmp, filepath.Join(j.dir, in.ID+journalFileExt)); err != nil {
+		return fmt.Errorf("rename intent: %w", err)
+	}
+
+	return syncDir(j.dir)
+}
+
+// Commit implements Journal. Removal isn't synced, intents reappearing after
+// a crash are reconciled as completed writes.
+func (j *FileJournal) Commit(_ context.Context, id string) error {
+	if err := os.Remove(filepath.Join(j.dir, id+journalFileExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Pending implements Journal. Intents are sorted by the time they're begun,
+// leftover temporary files are removed.
+func (j *FileJournal) Pending(_ context.Context) ([]Intent, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Intent
+	for _, entry := range entries {
+		path := filepath.Join(j.dir, entry.Name())
+		switch {
+		case strings.HasSuffix(entry.Name(), journalTmpFileExt):
+			_ = os.Remove(path)
+		case strings.HasSuffix(entry.Name(), journalFileExt):
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			var in Intent
+			if err = json.Unmarshal(raw, &in); err != nil {
+				return nil, fmt.Errorf("unmarshal intent '%s': %w", entry.Name(), err)
+			}
+			res = append(res, in)
+		}
+	}
+
+	sort.Slice(res, func(i, k int) bool {
+		return res[i].Started.Before(res[k].Started)
+	})
+
+	return res, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// beginWrite journals the write of the stored payload, the returned intent
+// must be committed once the write is completed. Nothing is journaled if the
+// journal isn't configured.
+func (n *layer) beginWrite(ctx context.Context, p *PutObjectParams, id oid.ID, hash []byte, deduplicated bool, uploadID string) (*Intent, error) {
+	if n.journal == nil {
+		return nil, nil
+	}
+
+	in := Intent{
+		ID:           uuid.NewString(),
+		Started:      time.Now(),
+		Bucket:       p.BktInfo.Name,
+		Container:    p.BktInfo.CID.EncodeToString(),
+		Owner:        p.BktInfo.Owner.EncodeToString(),
+		Object:       p.Object,
+		ObjectID:     id.EncodeToString(),
+		Hash:         hex.EncodeToString(hash),
+		Deduplicated: deduplicated,
+		UploadID:     uploadID,
+		CopiesNumber: p.CopiesNumber,
+	}
+	if p.Lock != nil && (p.Lock.Retention != nil || p.Lock.LegalHold != nil) {
+		in.Lock = p.Lock
+	}
+
+	if err := n.journal.Begin(ctx, in); err != nil {
+		return nil, fmt.Errorf("journal write: %w", err)
+	}
+
+	return &in, nil
+}
+
+// commitWrite commits the intent of the completed write. Failures are only
+// logged since the write is reconciled as completed on startup anyway.
+func (n *layer) commitWrite(ctx context.Context, in *Intent) {
+	if in == nil {
+		return
+	}
+
+	if err := n.journal.Commit(ctx, in.ID); err != nil {
+		n.log.Warn("couldn't commit write intent", zap.String("intent", in.ID), zap.Error(err))
+	}
+}
+
+// rollbackWrite undoes the failed write: the version is removed from the tree
+// if it's added, the payload is released and the intent is committed, so the
+// write isn't completed on startup. Failures are only logged.
+func (n *layer) rollbackWrite(ctx context.Context, bktInfo *data.BucketInfo, id oid.ID, hash []byte, deduplicated bool,
+	version *data.NodeVersion, in *Intent) {
+	defer n.commitWrite(ctx, in)
+
+	if version != nil {
+		if err := n.treeService.RemoveVersion(ctx, bktInfo, version.ID); err != nil {
+			// the payload is kept while the version refers to it
+			n.log.Warn("couldn't remove version of failed write",
+				zap.String("bucket", bktInfo.Name), zap.String("object", version.FilePath),
+				zap.Stringer("oid", id), zap.Error(err))
+			return
+		}
+	}
+
+	n.releasePayload(ctx, bktInfo, id, hash, deduplicated)
+}
+
+// ReconcileJournal completes writes interrupted after the version was added
+// to the tree (the lock is put if it's requested and parts of the completed
+// multipart upload are deleted) and rolls back the other ones by deleting
+// their payloads or releasing references to deduplicated ones. Intents that
+// failed to reconcile are kept pending.
+func (n *layer) ReconcileJournal(ctx context.Context) error {
+	if n.journal == nil {
+		return nil
+	}
+
+	pending, err := n.journal.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("get pending intents: %w", err)
+	}
+
+	var failed int
+	for _, in := range pending {
+		if err = n.reconcileWrite(ctx, in); err != nil {
+			failed++
+			n.log.Error("couldn't reconcile interrupted write", zap.String("intent", in.ID),
+				zap.String("bucket", in.Bucket), zap.String("object", in.Object), zap.Error(err))
+			continue
+		}
+
+		if err = n.journal.Commit(ctx, in.ID); err != nil {
+			return fmt.Errorf("commit intent: %w", err)
+		}
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d interrupted writes aren't reconciled", failed, len(pending))
+	}
+
+	return nil
+}
+
+func (n *layer) reconcileWrite(ctx context.Context, in Intent) error {
+	bktInfo := &data.BucketInfo{Name: in.Bucket}
+	if err := bktInfo.CID.DecodeString(in.Container); err != nil {
+		return fmt.Errorf("invalid container: %w", err)
+	}
+	if err := bktInfo.Owner.DecodeString(in.Owner); err != nil {
+		return fmt.Errorf("invalid owner: %w", err)
+	}
+	var objID oid.ID
+	if err := objID.DecodeString(in.ObjectID); err != nil {
+		return fmt.Errorf("invalid object id: %w", err)
+	}
+	fields := []zap.Field{zap.String("bucket", in.Bucket), zap.String("object", in.Object), zap.Stringer("oid", objID)}
+
+	versions, err := n.treeService.GetVersions(ctx, bktInfo, in.Object)
+	if err != nil && !errors.Is(err, ErrNodeNotFound) {
+		return fmt.Errorf("get versions: %w", err)
+	}
+
+	for _, version := range versions {
+		if version.OID != objID {
+			continue
+		}
+
+		if in.Lock != nil {
+			if err = n.PutLockInfo(ctx, &PutLockInfoParams{
+				ObjVersion: &ObjectVersion{
+					BktInfo:    bktInfo,
+					ObjectName: in.Object,
+					VersionID:  in.ObjectID,
+				},
+				NewLock:      in.Lock,
+				CopiesNumber: in.CopiesNumber,
+				NodeVersion:  version,
+			}); err != nil {
+				return fmt.Errorf("put lock: %w", err)
+			}
+		}
+
+		if in.UploadID != "" {
+			if err = n.completeUpload(ctx, bktInfo, in.Object, in.UploadID); err != nil {
+				return fmt.Errorf("complete multipart upload: %w", err)
+			}
+		}
+
+		n.log.Info("interrupted write is completed", fields...)
+		return nil
+	}
+
+	if in.Deduplicated {
+		err = n.deleteObjectPayload(ctx, bktInfo, &data.NodeVersion{
+			BaseNodeVersion: data.BaseNodeVersion{OID: objID, ETag: in.Hash},
+		})
+	} else {
+		err = n.objectDelete(ctx, bktInfo, objID)
+	}
+	if err != nil && !errors.Is(err, apistatus.ErrObjectNotFound) {
+		return fmt.Errorf("delete payload: %w", err)
+	}
+
+	n.log.Info("interrupted write is rolled back", fields...)
+	return nil
+}
+
+// completeUpload deletes parts of the multipart upload completed by the
+// interrupted write, there is nothing to do if it's already deleted.
+func (n *layer) completeUpload(ctx context.Context, bktInfo *data.BucketInfo, key, uploadID string) error {
+	unlock, err := n.lock(ctx, bktInfo, multipartLockKeyPrefix+uploadID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	multipartInfo, parts, err := n.getUploadParts(ctx, &UploadInfoParams{UploadID: uploadID, Bkt: bktInfo, Key: key})
+	if err != nil {
+		if errors.Is(err, s3errors.GetAPIError(s3errors.ErrNoSuchUpload)) {
+			return nil
+		}
+		return err
+	}
+
+	return n.deleteCompletedUpload(ctx, bktInfo, multipartInfo, parts)
+}
@@ -0,0 +1,226 @@
+package layer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJournal(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "journal")
+	j, err := NewFileJournal(dir)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now()
+	first := Intent{ID: "first", Started: now, Object: "a", Lock: &data.ObjectLock{LegalHold: &data.LegalHoldLock{Enabled: true}}}
+	second := Intent{ID: "second", Started: now.Add(-time.Minute), Object: "b"}
+	require.NoError(t, j.Begin(ctx, first))
+	require.NoError(t, j.Begin(ctx, second))
+
+	// partially written intents are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "third"+journalTmpFileExt), []byte("{"), 0o600))
+
+	pending, err := j.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, "second", pending[0].ID)
+	require.Equal(t, "first", pending[1].ID)
+	require.True(t, pending[1].Lock.LegalHold.Enabled)
+	require.NoFileExists(t, filepath.Join(dir, "third"+journalTmpFileExt))
+
+	require.NoError(t, j.Commit(ctx, "first"))
+	require.NoError(t, j.Commit(ctx, "first"))
+	pending, err = j.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "second", pending[0].ID)
+}
+
+func TestReconcileJournal(t *testing.T) {
+	tc := prepareContext(t)
+	j, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	tc.layer.(*layer).journal = j
+
+	// completed writes are committed
+	objInfo := tc.putObject([]byte("content"))
+	pending, err := j.Pending(tc.ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// the version is added, but the write isn't committed
+	completed := Intent{
+		ID:        "completed",
+		Bucket:    tc.bktInfo.Name,
+		Container: tc.bktInfo.CID.EncodeToString(),
+		Owner:     tc.bktInfo.Owner.EncodeToString(),
+		Object:    tc.obj,
+		ObjectID:  objInfo.ID.EncodeToString(),
+	}
+	require.NoError(t, j.Begin(tc.ctx, completed))
+
+	// the payload is stored only
+	orphanID, _, err := tc.layer.(*layer).objectPutAndHash(tc.ctx, PrmObjectCreate{
+		Container: tc.bktInfo.CID,
+		Creator:   tc.bktInfo.Owner,
+		Filepath:  "orphan",
+	}, tc.bktInfo)
+	require.NoError(t, err)
+	require.True(t, containsObject(tc.testNeoFS.Objects(), orphanID))
+	orphan := completed
+	orphan.ID, orphan.Object, orphan.ObjectID = "orphan", "orphan", orphanID.EncodeToString()
+	require.NoError(t, j.Begin(tc.ctx, orphan))
+
+	require.NoError(t, tc.layer.ReconcileJournal(tc.ctx))
+
+	pending, err = j.Pending(tc.ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.True(t, containsObject(tc.testNeoFS.Objects(), objInfo.ID))
+	require.False(t, containsObject(tc.testNeoFS.Objects(), orphanID))
+
+	_, content := tc.getObject(tc.obj, "", false)
+	require.Equal(t, []byte("content"), content)
+}
+
+func TestReconcileJournalDeduplicated(t *testing.T) {
+	tc := prepareContext(t)
+	j, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	n := tc.layer.(*layer)
+	n.journal = j
+
+	require.NoError(t, tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{
+		BktInfo:  tc.bktInfo,
+		Settings: &data.BucketSettings{Versioning: data.VersioningEnabled, Deduplication: true},
+	}))
+
+	first := tc.putObject([]byte("content"))
+	second := tc.putObject([]byte("content"))
+	payload, err := n.treeService.GetDeduplicatedPayload(tc.ctx, tc.bktInfo, first.HashSum)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, payload.Refs)
+
+	// the second write is interrupted before the version is added
+	versions, err := n.treeService.GetVersions(tc.ctx, tc.bktInfo, tc.obj)
+	require.NoError(t, err)
+	for _, version := range versions {
+		if version.OID == second.ID {
+			require.NoError(t, n.treeService.RemoveVersion(tc.ctx, tc.bktInfo, version.ID))
+		}
+	}
+	require.NoError(t, j.Begin(tc.ctx, Intent{
+		ID:           "interrupted",
+		Bucket:       tc.bktInfo.Name,
+		Container:    tc.bktInfo.CID.EncodeToString(),
+		Owner:        tc.bktInfo.Owner.EncodeToString(),
+		Object:       tc.obj,
+		ObjectID:     second.ID.EncodeToString(),
+		Hash:         second.HashSum,
+		Deduplicated: true,
+	}))
+
+	require.NoError(t, tc.layer.ReconcileJournal(tc.ctx))
+
+	// the reference is released, the payload is kept for the first object
+	payload, err = n.treeService.GetDeduplicatedPayload(tc.ctx, tc.bktInfo, first.HashSum)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, payload.Refs)
+	require.False(t, containsObject(tc.testNeoFS.Objects(), second.ID))
+	require.True(t, containsObject(tc.testNeoFS.Objects(), payload.OID))
+}
+
+func TestReconcileJournalMultipart(t *testing.T) {
+	tc := prepareContext(t)
+	j, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	tc.layer.(*layer).journal = j
+
+	info := &UploadInfoParams{UploadID: "upload", Bkt: tc.bktInfo, Key: tc.obj}
+	require.NoError(t, tc.layer.CreateMultipartUpload(tc.ctx, &CreateMultipartParams{Info: info, Data: &UploadData{}}))
+	_, err = tc.layer.UploadPart(tc.ctx, &UploadPartParams{Info: info, PartNumber: 1, Size: 4, Reader: bytes.NewReader([]byte("part"))})
+	require.NoError(t, err)
+	_, parts, err := tc.layer.(*layer).getUploadParts(tc.ctx, info)
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+
+	// the completed object is added, but parts aren't deleted yet
+	objInfo := tc.putObject([]byte("part"))
+	require.NoError(t, j.Begin(tc.ctx, Intent{
+		ID:        "completion",
+		Bucket:    tc.bktInfo.Name,
+		Container: tc.bktInfo.CID.EncodeToString(),
+		Owner:     tc.bktInfo.Owner.EncodeToString(),
+		Object:    tc.obj,
+		ObjectID:  objInfo.ID.EncodeToString(),
+		UploadID:  info.UploadID,
+	}))
+
+	require.NoError(t, tc.layer.ReconcileJournal(tc.ctx))
+
+	_, _, err = tc.layer.(*layer).getUploadParts(tc.ctx, info)
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrNoSuchUpload))
+	require.False(t, containsObject(tc.testNeoFS.Objects(), parts[1].OID))
+	require.True(t, containsObject(tc.testNeoFS.Objects(), objInfo.ID))
+
+	pending, err := j.Pending(tc.ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+type failingTreeService struct {
+	TreeService
+}
+
+func (failingTreeService) AddVersion(context.Context, *data.BucketInfo, *data.NodeVersion) (uint64, []*data.NodeVersion, error) {
+	return 0, nil, errors.New("tree service is unavailable")
+}
+
+func TestPutObjectRollback(t *testing.T) {
+	tc := prepareContext(t)
+	j, err := NewFileJournal(t.TempDir())
+	require.NoError(t, err)
+	n := tc.layer.(*layer)
+	n.journal = j
+
+	// settings are read before the tree service fails
+	_, err = tc.layer.GetBucketSettings(tc.ctx, tc.bktInfo)
+	require.NoError(t, err)
+	objects := len(tc.testNeoFS.Objects())
+	n.treeService = failingTreeService{n.treeService}
+
+	_, err = tc.layer.PutObject(tc.ctx, &PutObjectParams{
+		BktInfo: tc.bktInfo,
+		Object:  tc.obj,
+		Size:    7,
+		Reader:  bytes.NewReader([]byte("content")),
+		Header:  make(map[string]string),
+	})
+	require.Error(t, err)
+
+	// the payload is deleted and the failed write isn't reconciled
+	require.Len(t, tc.testNeoFS.Objects(), objects)
+	pending, err := j.Pending(tc.ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func containsObject(objects []*object.Object, id oid.ID) bool {
+	for _, obj := range objects {
+		if objID, ok := obj.ID(); ok && objID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package layer
+
+import (
+	"sync"
+)
+
+type (
+	// keyMutex provides mutual exclusion for operations on the same key.
+	// Entries are dropped as soon as nobody holds or waits for them.
+	keyMutex struct {
+		mu    sync.Mutex
+		locks map[string]*keyMutexEntry
+	}
+
+	keyMutexEntry struct {
+		mu   sync.Mutex
+		refs int
+	}
+)
+
+func newKeyMutex() *keyMutex {
+	return &keyMutex{
+		locks: make(map[string]*keyMutexEntry),
+	}
+}
+
+// Lock locks the key and returns a function to unlock it.
+func (m *keyMutex) Lock(key string) func() {
+	m.mu.Lock()
+	entry, ok := m.locks[key]
+	if !ok {
+		entry = new(keyMutexEntry)
+		m.locks[key] = entry
+	}
+	entry.refs++
+	m.mu.Unlock()
+
+	entry.mu.Lock()
+
+	return func() {
+		entry.mu.Unlock()
+
+		m.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
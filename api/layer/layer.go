@@ -14,11 +14,13 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer/encryption"
 	"github.com/nspcc-dev/neofs-s3-gw/api/resolver"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
@@ -50,6 +52,15 @@ type (
 		ncontroller EventListener
 		cache       *Cache
 		treeService TreeService
+		// serializes replacing of unversioned objects.
+		keyLocks *keyMutex
+		// payload reading is hedged if no data is received during it.
+		firstByteTimeout time.Duration
+		gateOwner        user.ID
+		ownership        OwnershipConfig
+		distributedLock  DistributedLockConfig
+		maxObjectSize    int64
+		journal          Journal
 	}
 
 	Config struct {
@@ -59,6 +70,19 @@ type (
 		Anonymous    user.ID
 		Resolver     resolver.Resolver
 		TreeService  TreeService
+		// FirstByteTimeout enables hedged payload reading, zero disables it.
+		FirstByteTimeout time.Duration
+		// Ownership defines access to buckets of containers owned by others.
+		Ownership OwnershipConfig
+		// DistributedLock enables locking between gateways serving the same
+		// buckets.
+		DistributedLock DistributedLockConfig
+		// MaxObjectSize is the maximum payload size of NeoFS objects, objects
+		// not exceeding it are deleted in batches. Zero disables batching.
+		MaxObjectSize int64
+		// Journal persists intents of object writes, see ReconcileJournal.
+		// Writes aren't journaled if it's nil.
+		Journal Journal
 	}
 
 	// GetObjectParams stores object get request parameters.
@@ -68,6 +92,9 @@ type (
 		BucketInfo *data.BucketInfo
 		Writer     io.Writer
 		Encryption encryption.Params
+		// VerifyChecksum makes the whole payload verified against the stored
+		// checksum, see ChecksumVerifiable.
+		VerifyChecksum bool
 	}
 
 	// HeadObjectParams stores object head request parameters.
@@ -101,12 +128,21 @@ type (
 		Lock         *data.ObjectLock
 		Encryption   encryption.Params
 		CopiesNumber uint32
+		// ContentSHA256 is the payload hash declared by the client (optional).
+		ContentSHA256 string
+		// Verify is called once the payload is stored, but the version isn't
+		// added yet (optional). If it fails, the payload is released and the
+		// error is returned.
+		Verify func(ctx context.Context) error
 	}
 
 	DeleteObjectParams struct {
 		BktInfo  *data.BucketInfo
 		Objects  []*VersionedObject
 		Settings *data.BucketSettings
+		// BypassGovernance allows the bucket owner to remove versions under
+		// governance retention.
+		BypassGovernance bool
 	}
 
 	// PutSettingsParams stores object copy request parameters.
@@ -180,9 +216,12 @@ type (
 	// Client provides S3 API client interface.
 	Client interface {
 		Initialize(ctx context.Context, c EventListener) error
+		ReconcileJournal(ctx context.Context) error
 
 		GetBucketSettings(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketSettings, error)
 		PutBucketSettings(ctx context.Context, p *PutSettingsParams) error
+		UnsupportedSettingsBuckets(ctx context.Context, owners []user.ID) ([]string, error)
+		OwnersBuckets(ctx context.Context, owners []user.ID) ([]*data.BucketInfo, error)
 
 		PutBucketCORS(ctx context.Context, p *PutCORSParams) error
 		GetBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (*data.CORSConfiguration, error)
@@ -194,6 +233,8 @@ type (
 		PutBucketACL(ctx context.Context, p *PutBucketACLParams) error
 		CreateBucket(ctx context.Context, p *CreateBucketParams) (*data.BucketInfo, error)
 		DeleteBucket(ctx context.Context, p *DeleteBucketParams) error
+		GetBucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketUsage, error)
+		GetUsageByTag(ctx context.Context, p *UsageByTagParams) ([]*TagUsage, error)
 
 		GetObject(ctx context.Context, p *GetObjectParams) error
 		GetObjectInfo(ctx context.Context, p *HeadObjectParams) (*data.ObjectInfo, error)
@@ -217,8 +258,12 @@ type (
 		ListObjectsV1(ctx context.Context, p *ListObjectsParamsV1) (*ListObjectsInfoV1, error)
 		ListObjectsV2(ctx context.Context, p *ListObjectsParamsV2) (*ListObjectsInfoV2, error)
 		ListObjectVersions(ctx context.Context, p *ListObjectVersionsParams) (*ListObjectVersionsInfo, error)
+		SearchObjects(ctx context.Context, p *SearchObjectsParams) (*SearchObjectsInfo, error)
 
 		DeleteObjects(ctx context.Context, p *DeleteObjectParams) []*VersionedObject
+		PurgePrefix(ctx context.Context, p *PurgePrefixParams) (*PurgeProgress, error)
+		UndeleteObject(ctx context.Context, p *UndeleteObjectParams) (*data.NodeVersion, error)
+		CleanupTrash(ctx context.Context, bktInfo *data.BucketInfo, dryRun bool) ([]TrashCleanupEntry, error)
 
 		CreateMultipartUpload(ctx context.Context, p *CreateMultipartParams) error
 		CompleteMultipartUpload(ctx context.Context, p *CompleteMultipartParams) (*UploadData, *data.ExtendedObjectInfo, error)
@@ -235,6 +280,9 @@ type (
 
 		// GetObjectTaggingAndLock unifies GetObjectTagging and GetLock methods in single tree service invocation.
 		GetObjectTaggingAndLock(ctx context.Context, p *ObjectVersion, nodeVersion *data.NodeVersion) (map[string]string, *data.LockInfo, error)
+
+		// CacheStats returns usage statistics of caches by their names.
+		CacheStats() map[string]cache.Stats
 	}
 )
 
@@ -248,6 +296,14 @@ const (
 	AttributeHMACSalt            = api.NeoFSSystemMetadataPrefix + "HMAC-Salt"
 	AttributeHMACKey             = api.NeoFSSystemMetadataPrefix + "HMAC-Key"
 
+	AttributeCompressionAlgorithm = api.NeoFSSystemMetadataPrefix + "Compression-Algorithm"
+	AttributeDecompressedSize     = api.NeoFSSystemMetadataPrefix + "Decompressed-Size"
+	AttributeCompressionBlockSize = api.NeoFSSystemMetadataPrefix + "Compression-Block-Size"
+
+	AttributePayloadObject = api.NeoFSSystemMetadataPrefix + "Payload-OID"
+	AttributePayloadSize   = api.NeoFSSystemMetadataPrefix + "Payload-Size"
+	AttributePayloadHash   = api.NeoFSSystemMetadataPrefix + "Payload-Hash"
+
 	AttributeNeofsCopiesNumber = "neofs-copies-number" // such formate to match X-Amz-Meta-Neofs-Copies-Number header
 )
 
@@ -266,16 +322,32 @@ func (f MsgHandlerFunc) HandleMessage(ctx context.Context, msg *nats.Msg) error
 // NewLayer creates an instance of a layer. It checks credentials
 // and establishes gRPC connection with the node.
 func NewLayer(log *zap.Logger, neoFS NeoFS, config *Config) Client {
+	var gateOwner user.ID
+	if config.GateKey != nil {
+		gateOwner = user.NewAutoIDSignerRFC6979(config.GateKey.PrivateKey).UserID()
+	}
+
 	return &layer{
-		neoFS:       neoFS,
-		log:         log,
-		anonymous:   config.Anonymous,
-		resolver:    config.Resolver,
-		cache:       NewCache(config.Caches),
-		treeService: config.TreeService,
+		neoFS:            neoFS,
+		log:              log,
+		anonymous:        config.Anonymous,
+		resolver:         config.Resolver,
+		cache:            NewCache(config.Caches),
+		treeService:      config.TreeService,
+		keyLocks:         newKeyMutex(),
+		firstByteTimeout: config.FirstByteTimeout,
+		gateOwner:        gateOwner,
+		ownership:        config.Ownership,
+		distributedLock:  config.DistributedLock,
+		maxObjectSize:    config.MaxObjectSize,
+		journal:          config.Journal,
 	}
 }
 
+func (n *layer) CacheStats() map[string]cache.Stats {
+	return n.cache.Stats()
+}
+
 func (n *layer) Initialize(ctx context.Context, c EventListener) error {
 	if n.IsNotificationEnabled() {
 		return fmt.Errorf("already initialized")
@@ -308,6 +380,13 @@ func TimeNow(ctx context.Context) time.Time {
 	return time.Now()
 }
 
+// creationTime returns the object creation time with the precision of the
+// Timestamp attribute, so Last-Modified returned right after creation
+// matches the one read from the object later.
+func creationTime(ctx context.Context) time.Time {
+	return time.Unix(TimeNow(ctx).Unix(), 0)
+}
+
 // Owner returns owner id from BearerToken (context) or from client owner.
 func (n *layer) Owner(ctx context.Context) user.ID {
 	if bd, ok := ctx.Value(api.BoxData).(*accessbox.Box); ok && bd != nil && bd.Gate != nil && bd.Gate.BearerToken != nil {
@@ -349,6 +428,9 @@ func (n *layer) GetBucketInfo(ctx context.Context, name string) (*data.BucketInf
 	}
 
 	if bktInfo := n.cache.GetBucket(name); bktInfo != nil {
+		if err = n.checkOwnership(bktInfo, true); err != nil {
+			return nil, err
+		}
 		return bktInfo, nil
 	}
 
@@ -358,7 +440,16 @@ func (n *layer) GetBucketInfo(ctx context.Context, name string) (*data.BucketInf
 		return nil, s3errors.GetAPIError(s3errors.ErrNoSuchBucket)
 	}
 
-	return n.containerInfo(ctx, containerID)
+	bktInfo, err := n.containerInfo(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = n.checkOwnership(bktInfo, false); err != nil {
+		return nil, err
+	}
+
+	return bktInfo, nil
 }
 
 // GetBucketACL returns bucket acl info by name.
@@ -389,9 +480,21 @@ func (n *layer) ListBuckets(ctx context.Context) ([]*data.BucketInfo, error) {
 func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	var params getParams
 
-	params.oid = p.ObjectInfo.ID
+	payloadID, err := payloadObjectID(p.ObjectInfo)
+	if err != nil {
+		return err
+	}
+
+	params.oid = payloadID
 	params.bktInfo = p.BucketInfo
 
+	if compInfo, ok, err := compressionInfo(p.ObjectInfo.Headers); ok {
+		if err != nil {
+			return err
+		}
+		return n.getCompressedObject(ctx, p, payloadID, compInfo)
+	}
+
 	var decReader *encryption.Decrypter
 	if p.Encryption.Enabled() {
 		var err error
@@ -424,6 +527,14 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	// alloc buffer for copying
 	buf := make([]byte, bufSize) // sync-pool it?
 
+	w := p.Writer
+	var verifier *checksumVerifier
+	if p.VerifyChecksum {
+		verifier = newChecksumVerifier(p.ObjectInfo, w)
+		payload = io.TeeReader(payload, verifier.hash)
+		w = verifier
+	}
+
 	r := payload
 	if decReader != nil {
 		if err = decReader.SetReader(payload); err != nil {
@@ -433,7 +544,7 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	}
 
 	// copy full payload
-	written, err := io.CopyBuffer(p.Writer, r, buf)
+	written, err := io.CopyBuffer(w, r, buf)
 	if err != nil {
 		if decReader != nil {
 			return fmt.Errorf("copy object payload written: '%d', decLength: '%d', params.ln: '%d' : %w", written, decReader.DecryptedLength(), params.ln, err)
@@ -441,6 +552,16 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 		return fmt.Errorf("copy object payload written: '%d': %w", written, err)
 	}
 
+	if verifier != nil {
+		if decReader != nil {
+			// the rest of the payload isn't read by the decrypter
+			if _, err = io.Copy(io.Discard, payload); err != nil {
+				return fmt.Errorf("read object payload: %w", err)
+			}
+		}
+		return verifier.verify()
+	}
+
 	return nil
 }
 
@@ -510,6 +631,12 @@ func (n *layer) GetExtendedObjectInfo(ctx context.Context, p *HeadObjectParams)
 
 // CopyObject from one bucket into another bucket.
 func (n *layer) CopyObject(ctx context.Context, p *CopyObjectParams) (*data.ExtendedObjectInfo, error) {
+	// header can be shared with the source object which is read concurrently
+	header := make(map[string]string, len(p.Header))
+	for k, v := range p.Header {
+		header[k] = v
+	}
+
 	pr, pw := io.Pipe()
 
 	go func() {
@@ -531,7 +658,7 @@ func (n *layer) CopyObject(ctx context.Context, p *CopyObjectParams) (*data.Exte
 		Object:       p.DstObject,
 		Size:         p.SrcSize,
 		Reader:       pr,
-		Header:       p.Header,
+		Header:       header,
 		Encryption:   p.Encryption,
 		CopiesNumber: p.CopiesNuber,
 	})
@@ -548,24 +675,25 @@ func getRandomOID() (oid.ID, error) {
 	return objID, nil
 }
 
-func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings *data.BucketSettings, obj *VersionedObject) *VersionedObject {
+// deleteObject removes the version of the object or adds the delete marker.
+// If tombstones isn't nil, NeoFS objects may be queued to it, the object is
+// updated with the result when the batch is flushed then.
+func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings *data.BucketSettings, obj *VersionedObject, bypassGovernance bool, tombstones *tombstoneBatch) *VersionedObject {
 	if len(obj.VersionID) != 0 || settings.Unversioned() {
 		var nodeVersion *data.NodeVersion
 		if nodeVersion, obj.Error = n.getNodeVersionToDelete(ctx, bkt, obj); obj.Error != nil {
 			return dismissNotFoundError(obj)
 		}
 
-		if obj.DeleteMarkVersion, obj.Error = n.removeOldVersion(ctx, bkt, nodeVersion, obj); obj.Error != nil {
-			return obj
-		}
-
-		obj.Error = n.treeService.RemoveVersion(ctx, bkt, nodeVersion.ID)
-		n.cache.CleanListCacheEntriesContainingObject(obj.Name, bkt.CID)
+		obj.Error = n.removeOldVersion(ctx, bkt, settings, nodeVersion, obj, bypassGovernance, tombstones, func() error {
+			err := n.treeService.RemoveVersion(ctx, bkt, nodeVersion.ID)
+			n.cache.DeleteObjectName(bkt.CID, bkt.Name, obj.Name)
+			n.cache.CleanListCacheEntriesContainingObject(obj.Name, bkt.CID)
+			return err
+		})
 		return obj
 	}
 
-	var newVersion *data.NodeVersion
-
 	if settings.VersioningSuspended() {
 		obj.VersionID = data.UnversionedObjectVersionID
 
@@ -574,20 +702,26 @@ func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings
 			return dismissNotFoundError(obj)
 		}
 
-		if obj.DeleteMarkVersion, obj.Error = n.removeOldVersion(ctx, bkt, nodeVersion, obj); obj.Error != nil {
-			return obj
-		}
+		obj.Error = n.removeOldVersion(ctx, bkt, settings, nodeVersion, obj, bypassGovernance, tombstones, func() error {
+			return n.addDeleteMarker(ctx, bkt, settings, obj)
+		})
+		return obj
 	}
 
+	obj.Error = n.addDeleteMarker(ctx, bkt, settings, obj)
+
+	return obj
+}
+
+func (n *layer) addDeleteMarker(ctx context.Context, bkt *data.BucketInfo, settings *data.BucketSettings, obj *VersionedObject) error {
 	randOID, err := getRandomOID()
 	if err != nil {
-		obj.Error = fmt.Errorf("couldn't get random oid: %w", err)
-		return obj
+		return fmt.Errorf("couldn't get random oid: %w", err)
 	}
 
 	obj.DeleteMarkVersion = randOID.EncodeToString()
 
-	newVersion = &data.NodeVersion{
+	newVersion := &data.NodeVersion{
 		BaseNodeVersion: data.BaseNodeVersion{
 			OID:      randOID,
 			FilePath: obj.Name,
@@ -599,13 +733,13 @@ func (n *layer) deleteObject(ctx context.Context, bkt *data.BucketInfo, settings
 		IsUnversioned: settings.VersioningSuspended(),
 	}
 
-	if _, obj.Error = n.treeService.AddVersion(ctx, bkt, newVersion); obj.Error != nil {
-		return obj
+	if _, _, err = n.treeService.AddVersion(ctx, bkt, newVersion); err != nil {
+		return err
 	}
 
 	n.cache.DeleteObjectName(bkt.CID, bkt.Name, obj.Name)
 
-	return obj
+	return nil
 }
 
 func dismissNotFoundError(obj *VersionedObject) *VersionedObject {
@@ -628,19 +762,79 @@ func (n *layer) getNodeVersionToDelete(ctx context.Context, bkt *data.BucketInfo
 	return n.getNodeVersion(ctx, objVersion)
 }
 
-func (n *layer) removeOldVersion(ctx context.Context, bkt *data.BucketInfo, nodeVersion *data.NodeVersion, obj *VersionedObject) (string, error) {
+// removeOldVersion deletes the payload of the version and calls removed then.
+// If the payload is queued to tombstones, removed is called on flush and its
+// result is written to obj.
+func (n *layer) removeOldVersion(ctx context.Context, bkt *data.BucketInfo, settings *data.BucketSettings, nodeVersion *data.NodeVersion, obj *VersionedObject, bypassGovernance bool, tombstones *tombstoneBatch, removed func() error) error {
 	if nodeVersion.IsDeleteMarker() {
-		return obj.VersionID, nil
+		obj.DeleteMarkVersion = obj.VersionID
+		return removed()
+	}
+
+	bypassed, err := n.checkDeletionLock(ctx, bkt, nodeVersion, bypassGovernance)
+	if err != nil {
+		return err
+	}
+
+	payloadRemoved := func() error {
+		if bypassed {
+			n.auditBypassedDeletion(ctx, bkt, nodeVersion)
+		}
+		return removed()
 	}
 
-	return "", n.objectDelete(ctx, bkt, nodeVersion.OID)
+	switch {
+	case settings.SoftDeletionEnabled():
+		err = n.trashVersion(ctx, bkt, nodeVersion)
+	case tombstones != nil && !bypassed && n.canBatchDeletion(settings, nodeVersion):
+		// the same version requested twice is removed once
+		tombstones.add(nodeVersion.OID, func(err error) {
+			if err == nil {
+				err = payloadRemoved()
+			}
+			obj.Error = err
+		})
+		return nil
+	default:
+		err = n.deleteObjectPayload(ctx, bkt, nodeVersion)
+		if bypassed && errors.Is(err, apistatus.ErrObjectLocked) {
+			// governance retention is locked in NeoFS too, the payload is
+			// kept till the lock expires
+			n.log.Warn("payload of version under governance retention is kept till the lock expires",
+				zap.String("bucket", bkt.Name), zap.Stringer("cid", bkt.CID),
+				zap.String("object", nodeVersion.FilePath), zap.Stringer("oid", nodeVersion.OID))
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return payloadRemoved()
+}
+
+// auditBypassedDeletion records removal of the object version protected by
+// governance retention.
+func (n *layer) auditBypassedDeletion(ctx context.Context, bkt *data.BucketInfo, nodeVersion *data.NodeVersion) {
+	reqInfo := api.GetReqInfo(ctx)
+	n.log.Named("audit").Warn("object under governance retention deleted",
+		zap.String("reqId", reqInfo.RequestID),
+		zap.String("remote", reqInfo.RemoteHost),
+		zap.Stringer("actor", n.Owner(ctx)),
+		zap.String("bucket", bkt.Name),
+		zap.Stringer("cid", bkt.CID),
+		zap.String("object", nodeVersion.FilePath),
+		zap.Stringer("version", nodeVersion.OID))
 }
 
-// DeleteObjects from the storage.
+// DeleteObjects from the storage. NeoFS objects of removed versions are
+// deleted in batches after all the versions are processed.
 func (n *layer) DeleteObjects(ctx context.Context, p *DeleteObjectParams) []*VersionedObject {
+	tombstones := newTombstoneBatch(p.BktInfo)
 	for i, obj := range p.Objects {
-		p.Objects[i] = n.deleteObject(ctx, p.BktInfo, p.Settings, obj)
+		p.Objects[i] = n.deleteObject(ctx, p.BktInfo, p.Settings, obj, p.BypassGovernance, tombstones)
 	}
+	n.flushTombstones(ctx, tombstones)
 
 	return p.Objects
 }
@@ -1,12 +1,19 @@
 package layer
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestObjectLockAttributes(t *testing.T) {
@@ -27,7 +34,8 @@ func TestObjectLockAttributes(t *testing.T) {
 		},
 		NewLock: &data.ObjectLock{
 			Retention: &data.RetentionLock{
-				Until: time.Now(),
+				Until:        time.Now(),
+				IsCompliance: true,
 			},
 		},
 		CopiesNumber: 0,
@@ -51,3 +59,108 @@ func TestObjectLockAttributes(t *testing.T) {
 
 	require.Truef(t, expEpoch, "system header %s presence", object.AttributeExpirationEpoch)
 }
+
+// lockedNeoFS rejects removal of the locked object like NeoFS does.
+type lockedNeoFS struct {
+	NeoFS
+	locked oid.ID
+}
+
+func (x *lockedNeoFS) DeleteObject(ctx context.Context, prm PrmObjectDelete) error {
+	if prm.Object == x.locked {
+		return apistatus.ErrObjectLocked
+	}
+	return x.NeoFS.DeleteObject(ctx, prm)
+}
+
+func TestDeleteWithGovernanceBypass(t *testing.T) {
+	tc := prepareContext(t)
+	settings := &data.BucketSettings{Versioning: data.VersioningEnabled}
+	err := tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{
+		BktInfo:  tc.bktInfo,
+		Settings: settings,
+	})
+	require.NoError(t, err)
+
+	loggerCore, observedLog := observer.New(zap.WarnLevel)
+	tc.layer.(*layer).log = zap.New(loggerCore)
+
+	putLock := func(lock *data.ObjectLock) *data.ObjectInfo {
+		obj := tc.putObject([]byte("content"))
+		err := tc.layer.PutLockInfo(tc.ctx, &PutLockInfoParams{
+			ObjVersion: &ObjectVersion{
+				BktInfo:    tc.bktInfo,
+				ObjectName: obj.Name,
+				VersionID:  obj.VersionID(),
+			},
+			NewLock: lock,
+		})
+		require.NoError(t, err)
+		return obj
+	}
+
+	deleteVersion := func(bktInfo *data.BucketInfo, obj *data.ObjectInfo, bypass bool) error {
+		res := tc.layer.DeleteObjects(tc.ctx, &DeleteObjectParams{
+			BktInfo:          bktInfo,
+			Objects:          []*VersionedObject{{Name: obj.Name, VersionID: obj.VersionID()}},
+			Settings:         settings,
+			BypassGovernance: bypass,
+		})
+		return res[0].Error
+	}
+
+	until := time.Now().Add(time.Hour)
+
+	t.Run("governance", func(t *testing.T) {
+		obj := putLock(&data.ObjectLock{Retention: &data.RetentionLock{Until: until}})
+
+		lockInfo, err := tc.layer.GetLockInfo(tc.ctx, &ObjectVersion{BktInfo: tc.bktInfo, ObjectName: obj.Name, VersionID: obj.VersionID()})
+		require.NoError(t, err)
+		require.True(t, lockInfo.IsRetentionSet())
+		require.NotNil(t, tc.getObjectByID(lockInfo.Retention()), "governance retention must be locked in NeoFS")
+
+		err = deleteVersion(tc.bktInfo, obj, false)
+		require.True(t, s3errors.IsS3Error(err, s3errors.ErrAccessDenied), err)
+
+		notOwned := *tc.bktInfo
+		notOwned.Owner = user.ID{}
+		err = deleteVersion(&notOwned, obj, true)
+		require.True(t, s3errors.IsS3Error(err, s3errors.ErrAccessDenied), err)
+		require.Zero(t, observedLog.Len())
+
+		neoFS := tc.layer.(*layer).neoFS
+		tc.layer.(*layer).neoFS = &lockedNeoFS{NeoFS: neoFS, locked: obj.ID}
+		defer func() { tc.layer.(*layer).neoFS = neoFS }()
+
+		require.NoError(t, deleteVersion(tc.bktInfo, obj, true))
+		tc.getObject(obj.Name, obj.VersionID(), true)
+
+		entries := observedLog.Filter(func(e observer.LoggedEntry) bool { return e.LoggerName == "audit" }).All()
+		require.Len(t, entries, 1)
+		require.Equal(t, "audit", entries[0].LoggerName)
+		fields := entries[0].ContextMap()
+		require.Equal(t, obj.Name, fields["object"])
+		require.Equal(t, obj.VersionID(), fields["version"])
+		require.Equal(t, tc.bktInfo.Owner.String(), fields["actor"])
+	})
+
+	t.Run("compliance", func(t *testing.T) {
+		obj := putLock(&data.ObjectLock{Retention: &data.RetentionLock{Until: until, IsCompliance: true}})
+
+		err := deleteVersion(tc.bktInfo, obj, true)
+		require.True(t, s3errors.IsS3Error(err, s3errors.ErrAccessDenied), err)
+	})
+
+	t.Run("legal hold", func(t *testing.T) {
+		obj := putLock(&data.ObjectLock{LegalHold: &data.LegalHoldLock{Enabled: true}})
+
+		err := deleteVersion(tc.bktInfo, obj, true)
+		require.True(t, s3errors.IsS3Error(err, s3errors.ErrAccessDenied), err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		obj := putLock(&data.ObjectLock{Retention: &data.RetentionLock{Until: time.Now().Add(-time.Hour)}})
+
+		require.NoError(t, deleteVersion(tc.bktInfo, obj, false))
+	})
+}
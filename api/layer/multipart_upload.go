@@ -75,6 +75,8 @@ type (
 	CompleteMultipartParams struct {
 		Info  *UploadInfoParams
 		Parts []*CompletedPart
+		// MaxObjectSize limits the size of the completed object if positive.
+		MaxObjectSize int64
 	}
 
 	CompletedPart struct {
@@ -286,7 +288,7 @@ func (n *layer) UploadPartCopy(ctx context.Context, p *UploadCopyParams) (*data.
 	size := p.SrcObjInfo.Size
 	if p.Range != nil {
 		size = int64(p.Range.End - p.Range.Start + 1)
-		if p.Range.End > uint64(p.SrcObjInfo.Size) {
+		if p.Range.End >= uint64(p.SrcObjInfo.Size) {
 			return nil, s3errors.GetAPIError(s3errors.ErrInvalidCopyPartRangeSource)
 		}
 	}
@@ -365,6 +367,13 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		}
 	}
 
+	// the upload mustn't be completed twice by concurrent requests
+	unlock, err := n.lock(ctx, p.Info.Bkt, multipartLockKeyPrefix+p.Info.UploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
 	multipartInfo, partsInfo, err := n.getUploadParts(ctx, p.Info)
 	if err != nil {
 		return nil, nil, err
@@ -409,6 +418,10 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		}
 	}
 
+	if p.MaxObjectSize > 0 && multipartObjetSize > p.MaxObjectSize {
+		return nil, nil, fmt.Errorf("%w: bucket allows objects up to %d bytes", s3errors.GetAPIError(s3errors.ErrEntityTooLarge), p.MaxObjectSize)
+	}
+
 	initMetadata := make(map[string]string, len(multipartInfo.Meta)+1)
 	initMetadata[UploadCompletedParts] = completedPartsHeader.String()
 
@@ -442,7 +455,9 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 
 	r.prm.bktInfo = p.Info.Bkt
 
-	extObjInfo, err := n.PutObject(ctx, &PutObjectParams{
+	// the write is journaled till the parts are deleted, so the completion
+	// interrupted by a crash is finished on startup
+	extObjInfo, intent, err := n.putObject(ctx, &PutObjectParams{
 		BktInfo:      p.Info.Bkt,
 		Object:       p.Info.Key,
 		Reader:       r,
@@ -450,7 +465,7 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		Size:         multipartObjetSize,
 		Encryption:   p.Info.Encryption,
 		CopiesNumber: multipartInfo.CopiesNumber,
-	})
+	}, p.Info.UploadID)
 	if err != nil {
 		n.log.Error("could not put a completed object (multipart upload)",
 			zap.String("uploadID", p.Info.UploadID),
@@ -460,20 +475,33 @@ func (n *layer) CompleteMultipartUpload(ctx context.Context, p *CompleteMultipar
 		return nil, nil, s3errors.GetAPIError(s3errors.ErrInternalError)
 	}
 
+	if err = n.deleteCompletedUpload(ctx, p.Info.Bkt, multipartInfo, partsInfo); err != nil {
+		// the intent is kept pending, so the upload is deleted on startup
+		return uploadData, extObjInfo, err
+	}
+	n.commitWrite(ctx, intent)
+
+	return uploadData, extObjInfo, nil
+}
+
+// deleteCompletedUpload deletes parts of the completed multipart upload and
+// the upload itself. Parts failed to be deleted are only logged.
+func (n *layer) deleteCompletedUpload(ctx context.Context, bktInfo *data.BucketInfo, multipartInfo *data.MultipartInfo,
+	parts map[int]*data.PartInfo) error {
 	var addr oid.Address
-	addr.SetContainer(p.Info.Bkt.CID)
-	for _, partInfo := range partsInfo {
-		if err = n.objectDelete(ctx, p.Info.Bkt, partInfo.OID); err != nil {
+	addr.SetContainer(bktInfo.CID)
+	for _, partInfo := range parts {
+		if err := n.objectDelete(ctx, bktInfo, partInfo.OID); err != nil {
 			n.log.Warn("could not delete upload part",
 				zap.Stringer("object id", &partInfo.OID),
-				zap.Stringer("bucket id", p.Info.Bkt.CID),
+				zap.Stringer("bucket id", bktInfo.CID),
 				zap.Error(err))
 		}
 		addr.SetObject(partInfo.OID)
 		n.cache.DeleteObject(addr)
 	}
 
-	return uploadData, extObjInfo, n.treeService.DeleteMultipartUpload(ctx, p.Info.Bkt, multipartInfo.ID)
+	return n.treeService.DeleteMultipartUpload(ctx, bktInfo, multipartInfo.ID)
 }
 
 func (n *layer) ListMultipartUploads(ctx context.Context, p *ListMultipartUploadsParams) (*ListMultipartUploadsInfo, error) {
@@ -537,6 +565,12 @@ func (n *layer) ListMultipartUploads(ctx context.Context, p *ListMultipartUpload
 }
 
 func (n *layer) AbortMultipartUpload(ctx context.Context, p *UploadInfoParams) error {
+	unlock, err := n.lock(ctx, p.Bkt, multipartLockKeyPrefix+p.UploadID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	multipartInfo, parts, err := n.getUploadParts(ctx, p)
 	if err != nil {
 		return err
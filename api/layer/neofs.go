@@ -71,6 +71,10 @@ type PrmObjectRead struct {
 
 	// Offset-length range of the object payload to be read.
 	PayloadRange [2]uint64
+
+	// Flag of the request duplicating the slow one, it should be served by
+	// another node if possible.
+	Hedged bool
 }
 
 // ObjectPart represents partially read NeoFS object.
@@ -128,6 +132,58 @@ type PrmObjectDelete struct {
 	Object oid.ID
 }
 
+// PrmObjectsDelete groups parameters of NeoFS.DeleteObjects operation.
+type PrmObjectsDelete struct {
+	// Authentication parameters.
+	PrmAuth
+
+	// Container to delete the objects from.
+	Container cid.ID
+
+	// NeoFS identifier of the tombstone creator.
+	Creator user.ID
+
+	// Identifiers of the removed objects, they must not be split.
+	Objects []oid.ID
+}
+
+// PrmObjectSearch groups parameters of NeoFS.SearchObjects operation.
+type PrmObjectSearch struct {
+	// Authentication parameters.
+	PrmAuth
+
+	// Container to search the objects in.
+	Container cid.ID
+
+	// Attribute the objects must have.
+	Attribute string
+
+	// Value of the attribute, any value matches if empty.
+	Value string
+
+	// Filters are additional attribute filters the objects must match.
+	Filters []SearchFilter
+}
+
+// SearchMatch is a type of the attribute match of NeoFS.SearchObjects operation.
+type SearchMatch int
+
+const (
+	// SearchMatchEqual matches attributes equal to the value.
+	SearchMatchEqual SearchMatch = iota
+	// SearchMatchNotEqual matches attributes not equal to the value.
+	SearchMatchNotEqual
+	// SearchMatchPrefix matches attributes starting with the value.
+	SearchMatchPrefix
+)
+
+// SearchFilter is an attribute filter of NeoFS.SearchObjects operation.
+type SearchFilter struct {
+	Attribute string
+	Value     string
+	Match     SearchMatch
+}
+
 // ErrAccessDenied is returned from NeoFS in case of access violation.
 var ErrAccessDenied = errors.New("access denied")
 
@@ -208,6 +264,14 @@ type NeoFS interface {
 	// prevented the container from being created.
 	CreateObject(context.Context, PrmObjectCreate) (oid.ID, error)
 
+	// SearchObjects returns identifiers of the container objects matching the
+	// parameters.
+	//
+	// It returns ErrAccessDenied on search access violation.
+	//
+	// It returns any error encountered which prevented the objects from being found.
+	SearchObjects(context.Context, PrmObjectSearch) ([]oid.ID, error)
+
 	// DeleteObject marks the object to be removed from the NeoFS container by identifier.
 	// Successful return does not guarantee actual removal.
 	//
@@ -216,6 +280,17 @@ type NeoFS interface {
 	// It returns any error encountered which prevented the removal request from being sent.
 	DeleteObject(context.Context, PrmObjectDelete) error
 
+	// DeleteObjects marks the objects to be removed from the NeoFS container
+	// with a single tombstone. Unlike DeleteObject, children of split objects
+	// aren't removed, so only objects not exceeding the maximum object size
+	// can be passed. Either all the objects are marked or none of them.
+	// Successful return does not guarantee actual removal.
+	//
+	// It returns ErrAccessDenied on remove access violation.
+	//
+	// It returns any error encountered which prevented the tombstone from being saved.
+	DeleteObjects(context.Context, PrmObjectsDelete) error
+
 	// TimeToEpoch computes current epoch and the epoch that corresponds to the provided now and future time.
 	// Note:
 	// * future time must be after the now
@@ -9,6 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
@@ -26,10 +29,13 @@ import (
 type TestNeoFS struct {
 	NeoFS
 
+	// objectsMu protects objects deleted concurrently on purge.
+	objectsMu    sync.RWMutex
 	objects      map[string]*object.Object
 	containers   map[string]*container.Container
 	eaclTables   map[string]*eacl.Table
 	currentEpoch uint64
+	tombstones   int
 }
 
 func NewTestNeoFS() *TestNeoFS {
@@ -45,6 +51,9 @@ func (t *TestNeoFS) CurrentEpoch() uint64 {
 }
 
 func (t *TestNeoFS) Objects() []*object.Object {
+	t.objectsMu.RLock()
+	defer t.objectsMu.RUnlock()
+
 	res := make([]*object.Object, 0, len(t.objects))
 
 	for _, obj := range t.objects {
@@ -55,6 +64,9 @@ func (t *TestNeoFS) Objects() []*object.Object {
 }
 
 func (t *TestNeoFS) AddObject(key string, obj *object.Object) {
+	t.objectsMu.Lock()
+	defer t.objectsMu.Unlock()
+
 	t.objects[key] = obj
 }
 
@@ -143,7 +155,11 @@ func (t *TestNeoFS) ReadObject(ctx context.Context, prm PrmObjectRead) (*ObjectP
 
 	sAddr := addr.EncodeToString()
 
-	if obj, ok := t.objects[sAddr]; ok {
+	t.objectsMu.RLock()
+	obj, ok := t.objects[sAddr]
+	t.objectsMu.RUnlock()
+
+	if ok {
 		owner := getOwner(ctx)
 		if !obj.OwnerID().Equals(owner) {
 			return nil, ErrAccessDenied
@@ -175,6 +191,13 @@ func (t *TestNeoFS) CreateObject(_ context.Context, prm PrmObjectCreate) (oid.ID
 
 	attrs := make([]object.Attribute, 0)
 
+	if !prm.CreationTime.IsZero() {
+		a := object.NewAttribute()
+		a.SetKey(object.AttributeTimestamp)
+		a.SetValue(strconv.FormatInt(prm.CreationTime.Unix(), 10))
+		attrs = append(attrs, *a)
+	}
+
 	if prm.Filepath != "" {
 		a := object.NewAttribute()
 		a.SetKey(object.AttributeFilePath)
@@ -220,7 +243,9 @@ func (t *TestNeoFS) CreateObject(_ context.Context, prm PrmObjectCreate) (oid.ID
 	objID, _ := obj.ID()
 
 	addr := newAddress(cnrID, objID)
+	t.objectsMu.Lock()
 	t.objects[addr.EncodeToString()] = obj
+	t.objectsMu.Unlock()
 	return objID, nil
 }
 
@@ -229,6 +254,9 @@ func (t *TestNeoFS) DeleteObject(ctx context.Context, prm PrmObjectDelete) error
 	addr.SetContainer(prm.Container)
 	addr.SetObject(prm.Object)
 
+	t.objectsMu.Lock()
+	defer t.objectsMu.Unlock()
+
 	if obj, ok := t.objects[addr.EncodeToString()]; ok {
 		owner := getOwner(ctx)
 		if !obj.OwnerID().Equals(owner) {
@@ -241,11 +269,98 @@ func (t *TestNeoFS) DeleteObject(ctx context.Context, prm PrmObjectDelete) error
 	return nil
 }
 
+func (t *TestNeoFS) DeleteObjects(ctx context.Context, prm PrmObjectsDelete) error {
+	t.objectsMu.Lock()
+	defer t.objectsMu.Unlock()
+
+	owner := getOwner(ctx)
+	for _, id := range prm.Objects {
+		if obj, ok := t.objects[newAddress(prm.Container, id).EncodeToString()]; ok && !obj.OwnerID().Equals(owner) {
+			return ErrAccessDenied
+		}
+	}
+
+	for _, id := range prm.Objects {
+		delete(t.objects, newAddress(prm.Container, id).EncodeToString())
+	}
+	t.tombstones++
+
+	return nil
+}
+
+// Tombstones returns the number of tombstones saved by DeleteObjects.
+func (t *TestNeoFS) Tombstones() int {
+	t.objectsMu.RLock()
+	defer t.objectsMu.RUnlock()
+
+	return t.tombstones
+}
+
+func (t *TestNeoFS) SearchObjects(_ context.Context, prm PrmObjectSearch) ([]oid.ID, error) {
+	t.objectsMu.RLock()
+	defer t.objectsMu.RUnlock()
+
+	var res []oid.ID
+	for _, obj := range t.objects {
+		cnrID, _ := obj.ContainerID()
+		if !cnrID.Equals(prm.Container) {
+			continue
+		}
+		filters := append([]SearchFilter{{Attribute: prm.Attribute, Value: prm.Value}}, prm.Filters...)
+		if prm.Value == "" {
+			filters[0].Match = SearchMatchPrefix
+		}
+		if matchesSearchFilters(obj.Attributes(), filters) {
+			objID, _ := obj.ID()
+			res = append(res, objID)
+		}
+	}
+
+	return res, nil
+}
+
+// matchesSearchFilters checks attributes as NeoFS does, filters of missing
+// attributes match nothing.
+func matchesSearchFilters(attrs []object.Attribute, filters []SearchFilter) bool {
+	for _, f := range filters {
+		var (
+			value string
+			found bool
+		)
+		for _, attr := range attrs {
+			if attr.Key() == f.Attribute {
+				value, found = attr.Value(), true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+
+		switch f.Match {
+		case SearchMatchEqual:
+			found = value == f.Value
+		case SearchMatchNotEqual:
+			found = value != f.Value
+		case SearchMatchPrefix:
+			found = strings.HasPrefix(value, f.Value)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (t *TestNeoFS) TimeToEpoch(_ context.Context, now, futureTime time.Time) (uint64, uint64, error) {
 	return t.currentEpoch, t.currentEpoch + uint64(futureTime.Sub(now).Seconds()), nil
 }
 
 func (t *TestNeoFS) AllObjects(cnrID cid.ID) []oid.ID {
+	t.objectsMu.RLock()
+	defer t.objectsMu.RUnlock()
+
 	result := make([]oid.ID, 0, len(t.objects))
 
 	for _, val := range t.objects {
@@ -18,6 +18,8 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer/compression"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
@@ -110,6 +112,10 @@ func (n *layer) initObjectPayloadReader(ctx context.Context, p getParams) (io.Re
 
 	n.prepareAuthParameters(ctx, &prm.PrmAuth, p.bktInfo.Owner)
 
+	if n.firstByteTimeout > 0 {
+		return n.readPayloadHedged(ctx, prm)
+	}
+
 	res, err := n.neoFS.ReadObject(ctx, prm)
 	if err != nil {
 		return nil, err
@@ -182,15 +188,70 @@ func ParseCompletedPartHeader(hdr string) (*Part, error) {
 	}, nil
 }
 
+// addVersion adds a new version to the tree service. The version of
+// unversioned object replaces the current one, which object is deleted
+// afterwards along with the objects of duplicated unversioned nodes, so
+// the last writer wins and no orphaned objects are left for a key.
+func (n *layer) addVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion) (uint64, error) {
+	if !version.IsUnversioned {
+		id, _, err := n.treeService.AddVersion(ctx, bktInfo, version)
+		return id, err
+	}
+
+	unlock := n.keyLocks.Lock(bktInfo.CID.EncodeToString() + "/" + version.FilePath)
+	defer unlock()
+
+	prev, err := n.treeService.GetUnversioned(ctx, bktInfo, version.FilePath)
+	if err != nil && !errors.Is(err, ErrNodeNotFound) {
+		return 0, fmt.Errorf("get current unversioned node: %w", err)
+	}
+
+	id, duplicates, err := n.treeService.AddVersion(ctx, bktInfo, version)
+	if err != nil {
+		return 0, err
+	}
+
+	replaced := make(map[oid.ID]*data.NodeVersion, len(duplicates)+1)
+	for _, old := range append(duplicates, prev) {
+		if old != nil && !old.IsDeleteMarker() && old.OID != version.OID {
+			replaced[old.OID] = old
+		}
+	}
+
+	for _, old := range replaced {
+		if err = n.deleteObjectPayload(ctx, bktInfo, old); err != nil {
+			n.log.Warn("couldn't delete replaced unversioned object",
+				zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+				zap.String("object", version.FilePath), zap.Stringer("oid", old.OID), zap.Error(err))
+		}
+	}
+
+	return id, nil
+}
+
 // PutObject stores object into NeoFS, took payload from io.Reader.
 //
 // Returns [ErrMetaEmptyParameterValue] error if any attribute parameter is empty.
 func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.ExtendedObjectInfo, error) {
+	extObjInfo, intent, err := n.putObject(ctx, p, "")
+	if err != nil {
+		return nil, err
+	}
+	n.commitWrite(ctx, intent)
+
+	return extObjInfo, nil
+}
+
+// putObject stores the object like PutObject does, but the returned intent
+// of the write isn't committed, so the write can be continued by the caller.
+// The intent records the multipart upload if uploadID is set. Failed writes
+// are rolled back and their intents are committed.
+func (n *layer) putObject(ctx context.Context, p *PutObjectParams, uploadID string) (*data.ExtendedObjectInfo, *Intent, error) {
 	owner := n.Owner(ctx)
 
 	bktSettings, err := n.GetBucketSettings(ctx, p.BktInfo)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't get versioning settings object: %w", err)
+		return nil, nil, fmt.Errorf("couldn't get versioning settings object: %w", err)
 	}
 
 	newVersion := &data.NodeVersion{
@@ -201,20 +262,16 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 		IsUnversioned: !bktSettings.VersioningEnabled(),
 	}
 
-	r := p.Reader
-	if p.Encryption.Enabled() {
-		p.Header[AttributeDecryptedSize] = strconv.FormatInt(p.Size, 10)
-		if err = addEncryptionHeaders(p.Header, p.Encryption); err != nil {
-			return nil, fmt.Errorf("add encryption header: %w", err)
-		}
-
-		var encSize uint64
-		if r, encSize, err = encryptionReader(p.Reader, uint64(p.Size), p.Encryption.Key()); err != nil {
-			return nil, fmt.Errorf("create encrypter: %w", err)
-		}
-		p.Size = int64(encSize)
+	// headers of the copied object mustn't describe the new payload
+	for _, key := range compressionHeaders {
+		delete(p.Header, key)
+	}
+	for _, key := range deduplicationHeaders {
+		delete(p.Header, key)
 	}
 
+	// content type is detected by the plain payload, not the encrypted one
+	r := p.Reader
 	if r != nil {
 		if len(p.Header[api.ContentType]) == 0 {
 			if contentType := MimeByFilePath(p.Object); len(contentType) == 0 {
@@ -229,13 +286,40 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 		}
 	}
 
+	if p.Encryption.Enabled() {
+		p.Header[AttributeDecryptedSize] = strconv.FormatInt(p.Size, 10)
+		if err = addEncryptionHeaders(p.Header, p.Encryption); err != nil {
+			return nil, nil, fmt.Errorf("add encryption header: %w", err)
+		}
+
+		var encSize uint64
+		if r, encSize, err = encryptionReader(r, uint64(p.Size), p.Encryption.Key()); err != nil {
+			return nil, nil, fmt.Errorf("create encrypter: %w", err)
+		}
+		p.Size = int64(encSize)
+	}
+
+	payloadSize := uint64(p.Size)
+	// client declared hash describes uncompressed data only
+	declaredHash := p.ContentSHA256
+	if r != nil && bktSettings.CompressionEnabled() && !p.Encryption.Enabled() {
+		p.Header[AttributeCompressionAlgorithm] = compression.AlgorithmZstd
+		p.Header[AttributeDecompressedSize] = strconv.FormatInt(p.Size, 10)
+		p.Header[AttributeCompressionBlockSize] = strconv.Itoa(compression.DefaultBlockSize)
+
+		r = compression.NewReader(r, compression.DefaultBlockSize)
+		// compressed size isn't known in advance
+		payloadSize = 0
+		declaredHash = ""
+	}
+
 	prm := PrmObjectCreate{
 		Container:    p.BktInfo.CID,
 		Creator:      owner,
-		PayloadSize:  uint64(p.Size),
+		PayloadSize:  payloadSize,
 		Filepath:     p.Object,
 		Payload:      r,
-		CreationTime: TimeNow(ctx),
+		CreationTime: creationTime(ctx),
 		CopiesNumber: p.CopiesNumber,
 	}
 
@@ -243,15 +327,37 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 
 	for k, v := range p.Header {
 		if v == "" {
-			return nil, ErrMetaEmptyParameterValue
+			return nil, nil, ErrMetaEmptyParameterValue
 		}
 
 		prm.Attributes = append(prm.Attributes, [2]string{k, v})
 	}
 
-	id, hash, err := n.objectPutAndHash(ctx, prm, p.BktInfo)
+	var (
+		id   oid.ID
+		hash []byte
+	)
+	deduplicated := r != nil && bktSettings.DeduplicationEnabled() && !p.Encryption.Enabled()
+	if deduplicated {
+		id, hash, err = n.putDeduplicated(ctx, p, prm, declaredHash)
+	} else {
+		id, hash, err = n.objectPutAndHash(ctx, prm, p.BktInfo)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if p.Verify != nil {
+		if err = p.Verify(ctx); err != nil {
+			n.releasePayload(ctx, p.BktInfo, id, hash, deduplicated)
+			return nil, nil, err
+		}
+	}
+
+	intent, err := n.beginWrite(ctx, p, id, hash, deduplicated, uploadID)
+	if err != nil {
+		n.releasePayload(ctx, p.BktInfo, id, hash, deduplicated)
+		return nil, nil, err
 	}
 
 	reqInfo := api.GetReqInfo(ctx)
@@ -262,8 +368,9 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 
 	newVersion.OID = id
 	newVersion.ETag = hex.EncodeToString(hash)
-	if newVersion.ID, err = n.treeService.AddVersion(ctx, p.BktInfo, newVersion); err != nil {
-		return nil, fmt.Errorf("couldn't add new verion to tree service: %w", err)
+	if newVersion.ID, err = n.addVersion(ctx, p.BktInfo, newVersion); err != nil {
+		n.rollbackWrite(ctx, p.BktInfo, id, hash, deduplicated, nil, intent)
+		return nil, nil, fmt.Errorf("couldn't add new verion to tree service: %w", err)
 	}
 
 	if p.Lock != nil && (p.Lock.Retention != nil || p.Lock.LegalHold != nil) {
@@ -279,7 +386,8 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 		}
 
 		if err = n.PutLockInfo(ctx, putLockInfoPrms); err != nil {
-			return nil, err
+			n.rollbackWrite(ctx, p.BktInfo, id, hash, deduplicated, newVersion, intent)
+			return nil, nil, err
 		}
 	}
 
@@ -306,7 +414,7 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*data.Extend
 
 	n.cache.PutObjectWithName(owner, extendedObjInfo)
 
-	return extendedObjInfo, nil
+	return extendedObjInfo, intent, nil
 }
 
 func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *data.BucketInfo, objectName string) (*data.ExtendedObjectInfo, error) {
@@ -331,7 +439,7 @@ func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *data.Bucke
 	if err != nil {
 		return nil, err
 	}
-	objInfo := objectInfoFromMeta(bkt, meta)
+	objInfo := objectInfoFromMeta(bkt, meta, node)
 
 	extObjInfo := &data.ExtendedObjectInfo{
 		ObjectInfo:  objInfo,
@@ -383,7 +491,7 @@ func (n *layer) headVersion(ctx context.Context, bkt *data.BucketInfo, p *HeadOb
 		}
 		return nil, err
 	}
-	objInfo := objectInfoFromMeta(bkt, meta)
+	objInfo := objectInfoFromMeta(bkt, meta, foundVersion)
 
 	extObjInfo := &data.ExtendedObjectInfo{
 		ObjectInfo:  objInfo,
@@ -589,14 +697,7 @@ func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams,
 				wg.Add(1)
 				err = pool.Submit(func() {
 					defer wg.Done()
-					oi := n.objectInfoFromObjectsCacheOrNeoFS(ctx, p.Bucket, node, p.Prefix, p.Delimiter)
-					if oi == nil {
-						// try to get object again
-						if oi = n.objectInfoFromObjectsCacheOrNeoFS(ctx, p.Bucket, node, p.Prefix, p.Delimiter); oi == nil {
-							// form object info with data that the tree node contains
-							oi = getPartialObjectInfo(p.Bucket, node)
-						}
-					}
+					oi := n.listedObjectInfo(ctx, p.Bucket, node, p.Prefix, p.Delimiter)
 					select {
 					case <-ctx.Done():
 					case objCh <- oi:
@@ -616,6 +717,26 @@ func (n *layer) initWorkerPool(ctx context.Context, size int, p allObjectParams,
 	return objCh, nil
 }
 
+// listedObjectInfo returns the info of the listed object. If the object header
+// can't be received (storage nodes are down, for example), the info is formed
+// with data of the tree node, so a few unavailable objects don't fail the
+// whole listing.
+func (n *layer) listedObjectInfo(ctx context.Context, bktInfo *data.BucketInfo, node *data.NodeVersion, prefix, delimiter string) *data.ObjectInfo {
+	oi := n.objectInfoFromObjectsCacheOrNeoFS(ctx, bktInfo, node, prefix, delimiter)
+	if oi == nil {
+		// try to get object again
+		if oi = n.objectInfoFromObjectsCacheOrNeoFS(ctx, bktInfo, node, prefix, delimiter); oi == nil {
+			n.log.Warn("object header is unavailable, tree data is listed",
+				zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+				zap.String("object", node.FilePath), zap.Stringer("oid", node.OID))
+			metrics.IncListingPartialObjects()
+			oi = getPartialObjectInfo(bktInfo, node)
+		}
+	}
+
+	return oi
+}
+
 // getPartialObjectInfo form data.ObjectInfo using data available in data.NodeVersion.
 func getPartialObjectInfo(bktInfo *data.BucketInfo, node *data.NodeVersion) *data.ObjectInfo {
 	return &data.ObjectInfo{
@@ -665,9 +786,7 @@ func (n *layer) getAllObjectsVersions(ctx context.Context, bkt *data.BucketInfo,
 			oi.Created = nodeVersion.DeleteMarker.Created
 			oi.IsDeleteMarker = true
 		} else {
-			if oi = n.objectInfoFromObjectsCacheOrNeoFS(ctx, bkt, nodeVersion, prefix, delimiter); oi == nil {
-				continue
-			}
+			oi = n.listedObjectInfo(ctx, bkt, nodeVersion, prefix, delimiter)
 		}
 
 		eoi := &data.ExtendedObjectInfo{
@@ -758,11 +877,11 @@ func (n *layer) objectInfoFromObjectsCacheOrNeoFS(ctx context.Context, bktInfo *
 
 	meta, err := n.objectHead(ctx, bktInfo, node.OID)
 	if err != nil {
-		n.log.Warn("could not fetch object meta", zap.Error(err))
+		n.log.Warn("could not fetch object meta", zap.String("object", node.FilePath), zap.Stringer("oid", node.OID), zap.Error(err))
 		return nil
 	}
 
-	oi = objectInfoFromMeta(bktInfo, meta)
+	oi = objectInfoFromMeta(bktInfo, meta, node)
 	n.cache.PutObject(owner, &data.ExtendedObjectInfo{ObjectInfo: oi, NodeVersion: node})
 
 	return oi
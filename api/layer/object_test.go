@@ -2,12 +2,16 @@ package layer
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"io"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestWrapReader(t *testing.T) {
@@ -27,3 +31,59 @@ func TestWrapReader(t *testing.T) {
 	require.Equal(t, src, dst)
 	require.Equal(t, h[:], streamHash.Sum(nil))
 }
+
+func TestObjectInfoConsistentAcrossInstances(t *testing.T) {
+	tc := prepareContext(t)
+	tc.ctx = context.WithValue(tc.ctx, api.ClientTime, time.Date(2023, 10, 1, 12, 30, 15, 500_000_000, time.UTC))
+
+	putInfo := tc.putObject([]byte("content"))
+
+	// another gateway instance with empty caches
+	logger := zap.NewExample()
+	other := NewLayer(logger, tc.testNeoFS, &Config{
+		Caches:      DefaultCachesConfigs(logger),
+		TreeService: tc.layer.(*layer).treeService,
+	})
+
+	for _, l := range []Client{tc.layer, other} {
+		objInfo, err := l.GetObjectInfo(tc.ctx, &HeadObjectParams{BktInfo: tc.bktInfo, Object: tc.obj})
+		require.NoError(t, err)
+		require.True(t, putInfo.Created.Equal(objInfo.Created))
+		require.Equal(t, putInfo.HashSum, objInfo.HashSum)
+	}
+
+	require.Equal(t, int64(1696163415), putInfo.Created.Unix())
+	require.Zero(t, putInfo.Created.Nanosecond())
+}
+
+func TestListingWithUnavailableHeaders(t *testing.T) {
+	tc := prepareContext(t)
+
+	tc.obj = "available"
+	tc.putObject([]byte("content"))
+	tc.obj = "unavailable"
+	lost := tc.putObject([]byte("lost content"))
+	require.NoError(t, tc.testNeoFS.DeleteObject(tc.ctx, PrmObjectDelete{Container: lost.CID, Object: lost.ID}))
+
+	// another gateway instance with empty caches
+	logger := zap.NewExample()
+	other := NewLayer(logger, tc.testNeoFS, &Config{
+		Caches:      DefaultCachesConfigs(logger),
+		TreeService: tc.layer.(*layer).treeService,
+	})
+
+	res, err := other.ListObjectsV2(tc.ctx, &ListObjectsParamsV2{
+		ListObjectsParamsCommon: ListObjectsParamsCommon{BktInfo: tc.bktInfo, MaxKeys: 1000},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Objects, 2)
+	require.Equal(t, "unavailable", res.Objects[1].Name)
+	require.Equal(t, lost.Size, res.Objects[1].Size)
+	require.Equal(t, lost.HashSum, res.Objects[1].HashSum)
+
+	versions, err := other.ListObjectVersions(tc.ctx, &ListObjectVersionsParams{BktInfo: tc.bktInfo, MaxKeys: 1000})
+	require.NoError(t, err)
+	require.Len(t, versions.Version, 2)
+	require.Equal(t, "unavailable", versions.Version[1].ObjectInfo.Name)
+	require.Equal(t, lost.ID, versions.Version[1].ObjectInfo.ID)
+}
@@ -0,0 +1,78 @@
+package layer
+
+import (
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// OwnershipMode defines what to do with buckets whose containers are owned
+// neither by the gateway key nor by shared owners.
+type OwnershipMode string
+
+const (
+	// OwnershipOff disables the check.
+	OwnershipOff OwnershipMode = "off"
+	// OwnershipWarn logs a warning, the bucket is still accessible.
+	OwnershipWarn OwnershipMode = "warn"
+	// OwnershipDeny logs a warning and denies access to the bucket.
+	OwnershipDeny OwnershipMode = "deny"
+)
+
+// OwnershipConfig contains owners of containers the gateway works with.
+type OwnershipConfig struct {
+	Mode OwnershipMode
+	// SharedOwners are owners of containers explicitly shared with the
+	// gateway, containers of the gateway key owner are always allowed.
+	SharedOwners []user.ID
+}
+
+// ParseOwnershipMode parses the ownership mode, empty string means OwnershipOff.
+func ParseOwnershipMode(s string) (OwnershipMode, error) {
+	switch mode := OwnershipMode(s); mode {
+	case "", OwnershipOff:
+		return OwnershipOff, nil
+	case OwnershipWarn, OwnershipDeny:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown container ownership mode '%s'", s)
+	}
+}
+
+func (n *layer) ownedBy(owner user.ID) bool {
+	if owner.Equals(n.gateOwner) {
+		return true
+	}
+
+	for i := range n.ownership.SharedOwners {
+		if owner.Equals(n.ownership.SharedOwners[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkOwnership checks that the container of the bucket is owned by the
+// gateway or shared with it. The warning is logged only for buckets just
+// fetched from NeoFS, not for cached ones.
+func (n *layer) checkOwnership(bktInfo *data.BucketInfo, cached bool) error {
+	if n.ownership.Mode == "" || n.ownership.Mode == OwnershipOff || n.ownedBy(bktInfo.Owner) {
+		return nil
+	}
+
+	if !cached {
+		n.log.Warn("container of the bucket is owned by someone other than the gateway",
+			zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+			zap.Stringer("owner", bktInfo.Owner), zap.String("mode", string(n.ownership.Mode)))
+	}
+
+	if n.ownership.Mode == OwnershipDeny {
+		return s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package layer
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCheckOwnership(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	gateOwner := user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID()
+	shared, other := usertest.ID(t), usertest.ID(t)
+
+	newLayer := func(mode OwnershipMode) *layer {
+		return NewLayer(zap.NewNop(), NewTestNeoFS(), &Config{
+			Caches:    DefaultCachesConfigs(zap.NewNop()),
+			GateKey:   key,
+			Ownership: OwnershipConfig{Mode: mode, SharedOwners: []user.ID{shared}},
+		}).(*layer)
+	}
+
+	for _, owner := range []user.ID{gateOwner, shared} {
+		require.NoError(t, newLayer(OwnershipDeny).checkOwnership(&data.BucketInfo{Owner: owner}, false))
+	}
+
+	bktInfo := &data.BucketInfo{Owner: other}
+	require.NoError(t, newLayer(OwnershipOff).checkOwnership(bktInfo, false))
+	require.NoError(t, newLayer(OwnershipWarn).checkOwnership(bktInfo, false))
+	require.True(t, s3errors.IsS3Error(newLayer(OwnershipDeny).checkOwnership(bktInfo, true), s3errors.ErrAccessDenied))
+}
+
+func TestParseOwnershipMode(t *testing.T) {
+	for s, expected := range map[string]OwnershipMode{
+		"":     OwnershipOff,
+		"off":  OwnershipOff,
+		"warn": OwnershipWarn,
+		"deny": OwnershipDeny,
+	} {
+		mode, err := ParseOwnershipMode(s)
+		require.NoError(t, err)
+		require.Equal(t, expected, mode)
+	}
+
+	_, err := ParseOwnershipMode("allow")
+	require.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/panjf2000/ants/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// purgeBatchSize is the number of object versions removed between
+	// progress reports.
+	purgeBatchSize = 1000
+	// purgeWorkers is the number of concurrent object removals in NeoFS.
+	purgeWorkers = 16
+)
+
+type (
+	// PurgePrefixParams stores PurgePrefix request parameters.
+	PurgePrefixParams struct {
+		BktInfo *data.BucketInfo
+		Prefix  string
+		// Progress is called after every processed batch of versions.
+		Progress func(PurgeProgress)
+		// DryRun only counts versions which would be removed, nothing is
+		// deleted.
+		DryRun bool
+	}
+
+	// PurgeProgress contains statistics of the prefix removal.
+	PurgeProgress struct {
+		// Found is the number of versions (delete markers included) under the prefix.
+		Found uint64
+		// Deleted is the number of versions removed so far (or which would be
+		// removed if it's a dry run).
+		Deleted uint64
+		// Failed is the number of versions which couldn't be removed.
+		Failed uint64
+	}
+)
+
+// PurgePrefix permanently removes all versions of all objects with the given
+// prefix. Versions are selected by the tree service, their objects are found
+// by NeoFS search with the prefix filter. Versions are removed in batches:
+// found objects of a batch are deleted from NeoFS with a few tombstones, the
+// rest of them (shared payloads, for example) are deleted concurrently, then
+// tree nodes of the versions are removed. Versions failed to be deleted from
+// NeoFS (locked ones, for example) are kept in the tree so the operation can
+// be repeated.
+func (n *layer) PurgePrefix(ctx context.Context, p *PurgePrefixParams) (*PurgeProgress, error) {
+	settings, err := n.GetBucketSettings(ctx, p.BktInfo)
+	if err != nil {
+		return nil, fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	versions, err := n.treeService.GetAllVersionsByPrefix(ctx, p.BktInfo, p.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("get versions by prefix: %w", err)
+	}
+
+	var found map[oid.ID]struct{}
+	if !p.DryRun {
+		if found, err = n.searchPurgedObjects(ctx, p.BktInfo, p.Prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	pool, err := ants.NewPool(purgeWorkers, ants.WithLogger(&logWrapper{n.log}))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't init go pool for purge: %w", err)
+	}
+	defer pool.Release()
+
+	progress := PurgeProgress{Found: uint64(len(versions))}
+
+	for start := 0; start < len(versions); start += purgeBatchSize {
+		if err = ctx.Err(); err != nil {
+			return &progress, err
+		}
+
+		end := start + purgeBatchSize
+		if end > len(versions) {
+			end = len(versions)
+		}
+
+		batch := versions[start:end]
+		var deleted []bool
+		if p.DryRun {
+			deleted = n.purgeableObjects(ctx, p.BktInfo, batch)
+		} else {
+			deleted = n.purgeObjects(ctx, pool, p.BktInfo, settings, batch, found)
+		}
+
+		for i, version := range batch {
+			if !deleted[i] {
+				progress.Failed++
+				continue
+			}
+			if p.DryRun {
+				progress.Deleted++
+				continue
+			}
+
+			if err = n.treeService.RemoveVersion(ctx, p.BktInfo, version.ID); err != nil {
+				n.log.Warn("couldn't remove purged version from tree", zap.String("object", version.FilePath),
+					zap.Uint64("node", version.ID), zap.Error(err))
+				progress.Failed++
+				continue
+			}
+
+			n.cache.DeleteObjectName(p.BktInfo.CID, p.BktInfo.Name, version.FilePath)
+			n.cache.CleanListCacheEntriesContainingObject(version.FilePath, p.BktInfo.CID)
+			progress.Deleted++
+		}
+
+		if p.Progress != nil {
+			p.Progress(progress)
+		}
+	}
+
+	return &progress, nil
+}
+
+// searchPurgedObjects returns identifiers of the bucket objects stored under
+// the prefix.
+func (n *layer) searchPurgedObjects(ctx context.Context, bktInfo *data.BucketInfo, prefix string) (map[oid.ID]struct{}, error) {
+	prm := PrmObjectSearch{
+		Container: bktInfo.CID,
+		Attribute: object.AttributeFilePath,
+	}
+	if prefix != "" {
+		prm.Filters = []SearchFilter{{Attribute: object.AttributeFilePath, Value: prefix, Match: SearchMatchPrefix}}
+	}
+	n.prepareAuthParameters(ctx, &prm.PrmAuth, bktInfo.Owner)
+
+	ids, err := n.neoFS.SearchObjects(ctx, prm)
+	if err != nil {
+		return nil, fmt.Errorf("search objects by prefix: %w", err)
+	}
+
+	found := make(map[oid.ID]struct{}, len(ids))
+	for _, id := range ids {
+		found[id] = struct{}{}
+	}
+
+	return found, nil
+}
+
+// purgeableObjects reports which versions would be removed by purgeObjects,
+// locked versions are kept.
+func (n *layer) purgeableObjects(ctx context.Context, bktInfo *data.BucketInfo, versions []*data.NodeVersion) []bool {
+	purgeable := make([]bool, len(versions))
+	for i, version := range versions {
+		if _, err := n.checkDeletionLock(ctx, bktInfo, version, false); err != nil {
+			n.log.Debug("locked object wouldn't be purged", zap.String("object", version.FilePath),
+				zap.Stringer("oid", version.OID), zap.Error(err))
+			continue
+		}
+		purgeable[i] = true
+	}
+
+	return purgeable
+}
+
+// purgeObjects deletes NeoFS objects of the versions and reports which of them
+// are gone. Only found objects are deleted with tombstones. Delete markers have
+// no objects, so they are always reported.
+func (n *layer) purgeObjects(ctx context.Context, pool *ants.Pool, bktInfo *data.BucketInfo, settings *data.BucketSettings,
+	versions []*data.NodeVersion, found map[oid.ID]struct{}) []bool {
+	var (
+		wg         sync.WaitGroup
+		deleted    = make([]bool, len(versions))
+		tombstones = newTombstoneBatch(bktInfo)
+	)
+
+	for i, version := range versions {
+		if version.IsDeleteMarker() {
+			deleted[i] = true
+			continue
+		}
+
+		i, version := i, version
+		wg.Add(1)
+		err := pool.Submit(func() {
+			defer wg.Done()
+			if _, err := n.checkDeletionLock(ctx, bktInfo, version, false); err != nil {
+				n.log.Warn("couldn't purge locked object", zap.String("object", version.FilePath),
+					zap.Stringer("oid", version.OID), zap.Error(err))
+				return
+			}
+			if _, ok := found[version.OID]; ok && n.canBatchDeletion(settings, version) {
+				tombstones.add(version.OID, func(err error) {
+					deleted[i] = n.purgedObject(version, err)
+				})
+				return
+			}
+			deleted[i] = n.purgedObject(version, n.deleteObjectPayload(ctx, bktInfo, version))
+		})
+		if err != nil {
+			wg.Done()
+			n.log.Warn("failed to submit task to pool", zap.Error(err))
+		}
+	}
+
+	wg.Wait()
+	n.flushTombstones(ctx, tombstones)
+
+	return deleted
+}
+
+// purgedObject logs the deletion error of the purged version and reports
+// whether its object is gone.
+func (n *layer) purgedObject(version *data.NodeVersion, err error) bool {
+	if err != nil {
+		n.log.Warn("couldn't delete purged object", zap.String("object", version.FilePath),
+			zap.Stringer("oid", version.OID), zap.Error(err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+package layer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/panjf2000/ants/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// searchMaxCandidates limits the number of objects found by NeoFS
+	// search, every one of them is headed to be matched with the current
+	// version of its key.
+	searchMaxCandidates = 10000
+	// searchWorkers is the number of concurrent heads of found objects.
+	searchWorkers = 16
+)
+
+type (
+	// SearchObjectsParams stores SearchObjects request parameters.
+	SearchObjectsParams struct {
+		BktInfo *data.BucketInfo
+		// Filters are attribute filters passed to NeoFS search.
+		Filters []SearchFilter
+		// CreatedSince and CreatedUntil limit the creation time of objects,
+		// the first one is inclusive, the second one is exclusive. Zero
+		// values don't limit.
+		CreatedSince time.Time
+		CreatedUntil time.Time
+		MaxKeys      int
+	}
+
+	// SearchObjectsInfo holds latest object versions matching the search
+	// sorted by key.
+	SearchObjectsInfo struct {
+		Objects     []*data.ObjectInfo
+		IsTruncated bool
+	}
+)
+
+// ErrTooManySearchCandidates is returned by SearchObjects if filters match
+// too many objects in NeoFS.
+var ErrTooManySearchCandidates = errors.New("too many objects match search filters")
+
+// SearchObjects finds latest object versions with NeoFS object search.
+// Objects found by NeoFS are matched with the current versions of their keys,
+// so old versions and deleted objects aren't returned.
+func (n *layer) SearchObjects(ctx context.Context, p *SearchObjectsParams) (*SearchObjectsInfo, error) {
+	prm := PrmObjectSearch{
+		Container: p.BktInfo.CID,
+		Attribute: object.AttributeFilePath,
+		Filters:   p.Filters,
+	}
+	n.prepareAuthParameters(ctx, &prm.PrmAuth, p.BktInfo.Owner)
+
+	ids, err := n.neoFS.SearchObjects(ctx, prm)
+	if err != nil {
+		return nil, fmt.Errorf("search objects: %w", err)
+	}
+	if len(ids) > searchMaxCandidates {
+		return nil, fmt.Errorf("%w: %d found, %d allowed", ErrTooManySearchCandidates, len(ids), searchMaxCandidates)
+	}
+
+	pool, err := ants.NewPool(searchWorkers, ants.WithLogger(&logWrapper{n.log}))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't init go pool for search: %w", err)
+	}
+	defer pool.Release()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		res = new(SearchObjectsInfo)
+	)
+
+	for _, id := range ids {
+		id := id
+		wg.Add(1)
+		err = pool.Submit(func() {
+			defer wg.Done()
+			objInfo := n.currentSearchedObject(ctx, p, id)
+			if objInfo == nil {
+				return
+			}
+
+			mu.Lock()
+			res.Objects = append(res.Objects, objInfo)
+			mu.Unlock()
+		})
+		if err != nil {
+			wg.Done()
+			n.log.Warn("failed to submit task to pool", zap.Error(err))
+		}
+	}
+
+	wg.Wait()
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(res.Objects, func(i, j int) bool {
+		return res.Objects[i].Name < res.Objects[j].Name
+	})
+	if p.MaxKeys >= 0 && len(res.Objects) > p.MaxKeys {
+		res.Objects = res.Objects[:p.MaxKeys]
+		res.IsTruncated = true
+	}
+
+	return res, nil
+}
+
+// currentSearchedObject returns the info of the found object if it's the
+// current version of its key created in the searched time range.
+func (n *layer) currentSearchedObject(ctx context.Context, p *SearchObjectsParams, id oid.ID) *data.ObjectInfo {
+	header, err := n.objectHead(ctx, p.BktInfo, id)
+	if err != nil {
+		n.log.Warn("couldn't head found object", zap.Stringer("oid", id), zap.Error(err))
+		return nil
+	}
+
+	key := filepathFromObject(header)
+	extObjInfo, err := n.headLastVersionIfNotDeleted(ctx, p.BktInfo, key)
+	if err != nil {
+		if !s3errors.IsS3Error(err, s3errors.ErrNoSuchKey) {
+			n.log.Warn("couldn't get current version of found object", zap.String("object", key),
+				zap.Stringer("oid", id), zap.Error(err))
+		}
+		return nil
+	}
+
+	objInfo := extObjInfo.ObjectInfo
+	if objInfo.ID != id {
+		return nil
+	}
+	if !p.CreatedSince.IsZero() && objInfo.Created.Before(p.CreatedSince) {
+		return nil
+	}
+	if !p.CreatedUntil.IsZero() && !objInfo.Created.Before(p.CreatedUntil) {
+		return nil
+	}
+
+	return objInfo
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 )
 
 const (
@@ -55,6 +56,9 @@ func (n *layer) PutLockInfo(ctx context.Context, p *PutLockInfoParams) (err erro
 			if !newLock.Retention.ByPassedGovernance {
 				return fmt.Errorf("you cannot bypass governence mode")
 			}
+			if !n.canBypassGovernance(ctx, p.ObjVersion.BktInfo) {
+				return s3errors.GetAPIError(s3errors.ErrAccessDenied)
+			}
 
 			untilDate := lockInfo.UntilDate()
 			if len(untilDate) > 0 {
@@ -151,6 +155,57 @@ func (n *layer) GetLockInfo(ctx context.Context, objVersion *ObjectVersion) (*da
 	return lockInfo, nil
 }
 
+// checkDeletionLock returns an error if the object version can't be removed
+// because of its legal hold or active retention period. Governance retention
+// is ignored if bypass is requested by the bucket owner, the result reports
+// whether it happened.
+func (n *layer) checkDeletionLock(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion, bypass bool) (bool, error) {
+	if version.IsDeleteMarker() {
+		return false, nil
+	}
+
+	lockInfo, err := n.treeService.GetLock(ctx, bktInfo, version.ID)
+	if err != nil {
+		if errorsStd.Is(err, ErrNodeNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if lockInfo == nil {
+		return false, nil
+	}
+
+	if lockInfo.IsLegalHoldSet() {
+		return false, s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	if !lockInfo.IsRetentionSet() {
+		return false, nil
+	}
+
+	until, err := time.Parse(time.RFC3339, lockInfo.UntilDate())
+	if err != nil {
+		return false, fmt.Errorf("couldn't parse time '%s': %w", lockInfo.UntilDate(), err)
+	}
+
+	if !until.After(TimeNow(ctx)) {
+		return false, nil
+	}
+
+	if lockInfo.IsCompliance() || !bypass || !n.canBypassGovernance(ctx, bktInfo) {
+		return false, s3errors.GetAPIError(s3errors.ErrAccessDenied)
+	}
+
+	return true, nil
+}
+
+// canBypassGovernance checks if the requester is allowed to override
+// governance retention of the bucket objects.
+func (n *layer) canBypassGovernance(ctx context.Context, bktInfo *data.BucketInfo) bool {
+	owner := n.Owner(ctx)
+	return owner.Equals(bktInfo.Owner)
+}
+
 func (n *layer) getCORS(ctx context.Context, bkt *data.BucketInfo) (*data.CORSConfiguration, error) {
 	owner := n.Owner(ctx)
 	if cors := n.cache.GetCORS(owner, bkt); cors != nil {
@@ -208,7 +263,37 @@ func (n *layer) GetBucketSettings(ctx context.Context, bktInfo *data.BucketInfo)
 }
 
 func (n *layer) PutBucketSettings(ctx context.Context, p *PutSettingsParams) error {
-	if err := n.treeService.PutSettingsNode(ctx, p.BktInfo, p.Settings); err != nil {
+	if !p.Settings.SchemaSupported() {
+		return s3errors.GetAPIError(s3errors.ErrBucketSettingsSchemaUnsupported)
+	}
+
+	unlock, err := n.lock(ctx, p.BktInfo, settingsLockKey)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := n.treeService.GetSettingsNode(ctx, p.BktInfo)
+	if err != nil && !errorsStd.Is(err, ErrNodeNotFound) {
+		return fmt.Errorf("get settings node: %w", err)
+	}
+
+	var revision uint64
+	if current != nil {
+		revision = current.Revision
+	}
+	if p.Settings.Revision != revision {
+		// the settings have been changed since they were read, the new
+		// settings would lose the change
+		if current != nil {
+			n.cache.PutSettings(n.Owner(ctx), p.BktInfo, current)
+		}
+		return fmt.Errorf("%w: bucket settings have been changed concurrently",
+			s3errors.GetAPIError(s3errors.ErrOperationAborted))
+	}
+	p.Settings.Revision = revision + 1
+
+	if err = n.treeService.PutSettingsNode(ctx, p.BktInfo, p.Settings); err != nil {
 		return fmt.Errorf("failed to get settings node: %w", err)
 	}
 
@@ -217,6 +302,31 @@ func (n *layer) PutBucketSettings(ctx context.Context, p *PutSettingsParams) err
 	return nil
 }
 
+// UnsupportedSettingsBuckets returns names of the owners buckets with
+// settings of newer format than supported.
+func (n *layer) UnsupportedSettingsBuckets(ctx context.Context, owners []user.ID) ([]string, error) {
+	var res []string
+	for _, owner := range owners {
+		buckets, err := n.userContainerList(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("list buckets of %s: %w", owner, err)
+		}
+
+		for _, bktInfo := range buckets {
+			settings, err := n.GetBucketSettings(ctx, bktInfo)
+			if err != nil {
+				return nil, fmt.Errorf("get settings of bucket %s: %w", bktInfo.Name, err)
+			}
+
+			if !settings.SchemaSupported() {
+				res = append(res, bktInfo.Name)
+			}
+		}
+	}
+
+	return res, nil
+}
+
 func (n *layer) attributesFromLock(ctx context.Context, lock *data.ObjectLock) ([][2]string, error) {
 	var (
 		err      error
@@ -0,0 +1,120 @@
+package layer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/minio/sio"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// maxTombstoneMembers limits the number of objects deleted with one tombstone.
+const maxTombstoneMembers = 1000
+
+// tombstoneBatch collects NeoFS objects of the bucket to delete them with
+// a few tombstones instead of a tombstone per object.
+type tombstoneBatch struct {
+	bktInfo *data.BucketInfo
+
+	mu     sync.Mutex
+	ids    []oid.ID
+	done   []func(error)
+	queued map[oid.ID]struct{}
+}
+
+func newTombstoneBatch(bktInfo *data.BucketInfo) *tombstoneBatch {
+	return &tombstoneBatch{
+		bktInfo: bktInfo,
+		queued:  make(map[oid.ID]struct{}),
+	}
+}
+
+// add queues the object to be deleted, done is called with the result of the
+// deletion on flush. It returns false if the object is queued already, done
+// isn't called then.
+func (b *tombstoneBatch) add(id oid.ID, done func(error)) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queued[id]; ok {
+		return false
+	}
+
+	b.queued[id] = struct{}{}
+	b.ids = append(b.ids, id)
+	b.done = append(b.done, done)
+
+	return true
+}
+
+// canBatchDeletion checks whether the object of the version can be deleted with
+// a batch tombstone. Such an object must not be split, because tombstones saved
+// by the gateway don't include children of split objects, and must not be
+// a shared payload of the deduplicating bucket.
+func (n *layer) canBatchDeletion(settings *data.BucketSettings, version *data.NodeVersion) bool {
+	if n.maxObjectSize <= 0 || settings.DeduplicationEnabled() {
+		return false
+	}
+
+	// encrypted payloads are a bit larger than the version size
+	size, err := sio.EncryptedSize(uint64(version.Size))
+	return err == nil && size <= uint64(n.maxObjectSize)
+}
+
+// flushTombstones deletes queued objects with tombstones of up to
+// maxTombstoneMembers members. If a tombstone can't be saved (one of the
+// objects is locked, for example), its objects are deleted one by one, so
+// only failed ones are reported.
+func (n *layer) flushTombstones(ctx context.Context, b *tombstoneBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for start := 0; start < len(b.ids); start += maxTombstoneMembers {
+		end := start + maxTombstoneMembers
+		if end > len(b.ids) {
+			end = len(b.ids)
+		}
+
+		// a single object is deleted with one request anyway
+		if end-start > 1 && n.tombstoneObjects(ctx, b.bktInfo, b.ids[start:end]) {
+			for i := start; i < end; i++ {
+				b.done[i](nil)
+			}
+			continue
+		}
+
+		for i := start; i < end; i++ {
+			b.done[i](n.objectDelete(ctx, b.bktInfo, b.ids[i]))
+		}
+	}
+
+	b.ids, b.done = nil, nil
+	b.queued = make(map[oid.ID]struct{})
+}
+
+// tombstoneObjects deletes the objects with one tombstone and reports whether
+// it's saved.
+func (n *layer) tombstoneObjects(ctx context.Context, bktInfo *data.BucketInfo, ids []oid.ID) bool {
+	prm := PrmObjectsDelete{
+		Container: bktInfo.CID,
+		Creator:   bktInfo.Owner,
+		Objects:   ids,
+	}
+
+	n.prepareAuthParameters(ctx, &prm.PrmAuth, bktInfo.Owner)
+
+	for _, id := range ids {
+		n.cache.DeleteObject(newAddress(bktInfo.CID, id))
+	}
+
+	if err := n.neoFS.DeleteObjects(ctx, prm); err != nil {
+		n.log.Warn("couldn't delete objects with one tombstone, deleting them one by one",
+			zap.String("bucket", bktInfo.Name), zap.Stringer("cid", bktInfo.CID),
+			zap.Int("objects", len(ids)), zap.Error(err))
+		return false
+	}
+
+	return true
+}
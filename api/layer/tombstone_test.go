@@ -0,0 +1,96 @@
+package layer
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObjectsWithTombstones(t *testing.T) {
+	tc := prepareContext(t)
+	tc.layer.(*layer).maxObjectSize = 100
+
+	settings := &data.BucketSettings{Versioning: data.VersioningUnversioned}
+
+	var (
+		ids     []oid.ID
+		objects []*VersionedObject
+	)
+	for i := 0; i < 5; i++ {
+		tc.obj = "obj" + strconv.Itoa(i)
+		ids = append(ids, tc.putObject([]byte("content")).ID)
+		objects = append(objects, &VersionedObject{Name: tc.obj})
+	}
+	// objects larger than the maximum size are split, so they aren't batched
+	tc.obj = "big"
+	ids = append(ids, tc.putObject(bytes.Repeat([]byte{'a'}, 200)).ID)
+	objects = append(objects, &VersionedObject{Name: tc.obj}, &VersionedObject{Name: "obj0"})
+
+	deleted := tc.layer.DeleteObjects(tc.ctx, &DeleteObjectParams{
+		BktInfo:  tc.bktInfo,
+		Settings: settings,
+		Objects:  objects,
+	})
+	for _, obj := range deleted {
+		require.NoError(t, obj.Error)
+	}
+
+	require.Equal(t, 1, tc.testNeoFS.Tombstones())
+	for _, id := range ids {
+		require.Nil(t, tc.getObjectByID(id))
+	}
+	tc.checkListObjects()
+
+	tc.obj = "purged1"
+	tc.putObject([]byte("content"))
+	tc.obj = "purged2"
+	tc.putObject([]byte("content"))
+	tc.obj = "purged3"
+	hidden := tc.putObject([]byte("content")).ID
+
+	progress, err := tc.layer.PurgePrefix(tc.ctx, &PurgePrefixParams{BktInfo: tc.bktInfo, Prefix: "purged", DryRun: true})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, progress.Found)
+	require.EqualValues(t, 3, progress.Deleted)
+	require.Equal(t, 1, tc.testNeoFS.Tombstones())
+	require.Len(t, tc.testNeoFS.Objects(), 3)
+
+	// objects which aren't found by the search are deleted one by one
+	neoFS := &searchingNeoFS{NeoFS: tc.layer.(*layer).neoFS, hidden: hidden}
+	tc.layer.(*layer).neoFS = neoFS
+
+	progress, err = tc.layer.PurgePrefix(tc.ctx, &PurgePrefixParams{BktInfo: tc.bktInfo, Prefix: "purged"})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, progress.Deleted)
+	require.Equal(t, 2, tc.testNeoFS.Tombstones())
+	require.Empty(t, tc.testNeoFS.Objects())
+	require.Equal(t, []SearchFilter{{Attribute: object.AttributeFilePath, Value: "purged", Match: SearchMatchPrefix}}, neoFS.filters)
+}
+
+// searchingNeoFS remembers search filters and hides the object from search
+// results.
+type searchingNeoFS struct {
+	NeoFS
+	hidden  oid.ID
+	filters []SearchFilter
+}
+
+func (x *searchingNeoFS) SearchObjects(ctx context.Context, prm PrmObjectSearch) ([]oid.ID, error) {
+	x.filters = prm.Filters
+
+	ids, err := x.NeoFS.SearchObjects(ctx, prm)
+	res := ids[:0]
+	for _, id := range ids {
+		if id != x.hidden {
+			res = append(res, id)
+		}
+	}
+
+	return res, err
+}
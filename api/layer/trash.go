@@ -0,0 +1,207 @@
+package layer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// UndeleteObjectParams stores UndeleteObject request parameters.
+type UndeleteObjectParams struct {
+	BktInfo *data.BucketInfo
+	Object  string
+	// VersionID selects the deleted version to restore, the most recently
+	// deleted one is restored if it's empty.
+	VersionID string
+}
+
+// TrashCleanupEntry is a trashed version which undelete window has passed.
+type TrashCleanupEntry struct {
+	Version *data.TrashedVersion
+	// Err is the error of the version removal, it's nil if the version is
+	// removed or the cleanup is a dry run.
+	Err error
+}
+
+// trashVersion keeps the payload of the version deleted in a bucket with soft
+// deletion, so it can be restored during the undelete window.
+func (n *layer) trashVersion(ctx context.Context, bkt *data.BucketInfo, nodeVersion *data.NodeVersion) error {
+	return n.treeService.AddTrashedVersion(ctx, bkt, &data.TrashedVersion{
+		BaseNodeVersion: nodeVersion.BaseNodeVersion,
+		IsUnversioned:   nodeVersion.IsUnversioned,
+		Deleted:         TimeNow(ctx),
+	})
+}
+
+// UndeleteObject restores the version deleted in a bucket with soft deletion
+// if its undelete window hasn't passed. Tags and locks of the version aren't
+// restored. The unversioned version is restored only if the object has no
+// current unversioned version other than a delete marker.
+func (n *layer) UndeleteObject(ctx context.Context, p *UndeleteObjectParams) (*data.NodeVersion, error) {
+	settings, err := n.GetBucketSettings(ctx, p.BktInfo)
+	if err != nil {
+		return nil, fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	versions, err := n.treeService.GetTrashedVersions(ctx, p.BktInfo, p.Object)
+	if err != nil {
+		return nil, fmt.Errorf("get trashed versions: %w", err)
+	}
+
+	deadline := TimeNow(ctx).Add(-settings.UndeleteWindow())
+
+	var trashed *data.TrashedVersion
+	for _, version := range versions {
+		if !version.Deleted.After(deadline) {
+			continue
+		}
+
+		switch {
+		case p.VersionID == "":
+		case p.VersionID == data.UnversionedObjectVersionID:
+			if !version.IsUnversioned {
+				continue
+			}
+		default:
+			if version.IsUnversioned || version.OID.EncodeToString() != p.VersionID {
+				continue
+			}
+		}
+
+		if trashed == nil || version.Deleted.After(trashed.Deleted) {
+			trashed = version
+		}
+	}
+
+	if trashed == nil {
+		if p.VersionID == "" {
+			return nil, s3errors.GetAPIError(s3errors.ErrNoSuchKey)
+		}
+		return nil, s3errors.GetAPIError(s3errors.ErrNoSuchVersion)
+	}
+
+	if trashed.IsUnversioned {
+		current, err := n.treeService.GetUnversioned(ctx, p.BktInfo, p.Object)
+		if err != nil && !errors.Is(err, ErrNodeNotFound) {
+			return nil, fmt.Errorf("get unversioned version: %w", err)
+		}
+		if current != nil && !current.IsDeleteMarker() {
+			return nil, fmt.Errorf("%w: object has a newer unversioned version",
+				s3errors.GetAPIError(s3errors.ErrInvalidObjectState))
+		}
+	}
+
+	restored := &data.NodeVersion{
+		BaseNodeVersion: data.BaseNodeVersion{
+			OID:      trashed.OID,
+			ETag:     trashed.ETag,
+			Size:     trashed.Size,
+			FilePath: trashed.FilePath,
+		},
+		IsUnversioned: trashed.IsUnversioned,
+	}
+
+	if restored.ID, _, err = n.treeService.AddVersion(ctx, p.BktInfo, restored); err != nil {
+		return nil, fmt.Errorf("add restored version: %w", err)
+	}
+
+	if err = n.treeService.RemoveTrashedVersion(ctx, p.BktInfo, trashed.ID); err != nil {
+		return nil, fmt.Errorf("remove trashed version: %w", err)
+	}
+
+	n.cache.DeleteObjectName(p.BktInfo.CID, p.BktInfo.Name, p.Object)
+	n.cache.CleanListCacheEntriesContainingObject(p.Object, p.BktInfo.CID)
+
+	return restored, nil
+}
+
+// CleanupTrash removes payloads of versions which undelete window has passed.
+// All deleted versions are removed if soft deletion was disabled in the
+// bucket. Versions failed to be removed are kept, so the cleanup can be
+// repeated. Expired versions are returned with errors of their removal, they
+// aren't removed if it's a dry run.
+func (n *layer) CleanupTrash(ctx context.Context, bktInfo *data.BucketInfo, dryRun bool) ([]TrashCleanupEntry, error) {
+	settings, err := n.GetBucketSettings(ctx, bktInfo)
+	if err != nil {
+		return nil, fmt.Errorf("get bucket settings: %w", err)
+	}
+
+	versions, err := n.treeService.GetTrashedVersions(ctx, bktInfo, "")
+	if err != nil {
+		return nil, fmt.Errorf("get trashed versions: %w", err)
+	}
+
+	var (
+		deadline   = TimeNow(ctx).Add(-settings.UndeleteWindow())
+		tombstones = newTombstoneBatch(bktInfo)
+		res        []TrashCleanupEntry
+	)
+
+	for _, version := range versions {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		if version.Deleted.After(deadline) {
+			continue
+		}
+
+		res = append(res, TrashCleanupEntry{Version: version})
+		if dryRun {
+			continue
+		}
+
+		i := len(res) - 1
+		nodeVersion := &data.NodeVersion{BaseNodeVersion: version.BaseNodeVersion}
+		if n.canBatchDeletion(settings, nodeVersion) {
+			version := version
+			tombstones.add(version.OID, func(err error) {
+				res[i].Err = n.removeTrashedVersion(ctx, bktInfo, version, err)
+			})
+			continue
+		}
+
+		res[i].Err = n.removeTrashedVersion(ctx, bktInfo, version, n.deleteObjectPayload(ctx, bktInfo, nodeVersion))
+	}
+
+	n.flushTombstones(ctx, tombstones)
+
+	return res, err
+}
+
+// removeTrashedVersion removes the trashed version from the tree if its payload
+// is deleted with no error.
+func (n *layer) removeTrashedVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.TrashedVersion, err error) error {
+	if err != nil {
+		n.log.Warn("couldn't remove payload of trashed version", zap.String("bucket", bktInfo.Name),
+			zap.String("object", version.FilePath), zap.Stringer("oid", version.OID), zap.Error(err))
+		return fmt.Errorf("remove payload: %w", err)
+	}
+
+	if err = n.treeService.RemoveTrashedVersion(ctx, bktInfo, version.ID); err != nil {
+		n.log.Warn("couldn't remove trashed version from tree", zap.String("bucket", bktInfo.Name),
+			zap.String("object", version.FilePath), zap.Stringer("oid", version.OID), zap.Error(err))
+		return fmt.Errorf("remove trashed version: %w", err)
+	}
+
+	return nil
+}
+
+// OwnersBuckets lists buckets of the given owners.
+func (n *layer) OwnersBuckets(ctx context.Context, owners []user.ID) ([]*data.BucketInfo, error) {
+	var res []*data.BucketInfo
+	for _, owner := range owners {
+		buckets, err := n.userContainerList(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("list buckets of %s: %w", owner, err)
+		}
+		res = append(res, buckets...)
+	}
+
+	return res, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
@@ -16,8 +17,19 @@ type TreeServiceMock struct {
 	system     map[string]map[string]*data.BaseNodeVersion
 	locks      map[string]map[uint64]*data.LockInfo
 	tags       map[string]map[uint64]map[string]string
+	bucketTags map[string]map[string]string
 	multiparts map[string]map[string][]*data.MultipartInfo
 	parts      map[string]map[int]*data.PartInfo
+	trash      map[string][]*data.TrashedVersion
+
+	notifications map[string]oid.ID
+
+	// payloads are updated concurrently on purge.
+	payloadsMu sync.Mutex
+	payloads   map[string]map[string]data.DeduplicatedPayload
+
+	// lastVersionID makes version node IDs unique like in the real tree.
+	lastVersionID uint64
 }
 
 func (t *TreeServiceMock) GetObjectTaggingAndLock(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) (map[string]string, *data.LockInfo, error) {
@@ -59,19 +71,23 @@ func (t *TreeServiceMock) DeleteObjectTagging(_ context.Context, bktInfo *data.B
 	return nil
 }
 
-func (t *TreeServiceMock) GetBucketTagging(_ context.Context, _ *data.BucketInfo) (map[string]string, error) {
-	// TODO implement me
-	panic("implement me")
+func (t *TreeServiceMock) GetBucketTagging(_ context.Context, bktInfo *data.BucketInfo) (map[string]string, error) {
+	tags, ok := t.bucketTags[bktInfo.CID.EncodeToString()]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+
+	return tags, nil
 }
 
-func (t *TreeServiceMock) PutBucketTagging(_ context.Context, _ *data.BucketInfo, _ map[string]string) error {
-	// TODO implement me
-	panic("implement me")
+func (t *TreeServiceMock) PutBucketTagging(_ context.Context, bktInfo *data.BucketInfo, tagSet map[string]string) error {
+	t.bucketTags[bktInfo.CID.EncodeToString()] = tagSet
+	return nil
 }
 
-func (t *TreeServiceMock) DeleteBucketTagging(_ context.Context, _ *data.BucketInfo) error {
-	// TODO implement me
-	panic("implement me")
+func (t *TreeServiceMock) DeleteBucketTagging(_ context.Context, bktInfo *data.BucketInfo) error {
+	delete(t.bucketTags, bktInfo.CID.EncodeToString())
+	return nil
 }
 
 func NewTreeService() *TreeServiceMock {
@@ -81,8 +97,13 @@ func NewTreeService() *TreeServiceMock {
 		system:     make(map[string]map[string]*data.BaseNodeVersion),
 		locks:      make(map[string]map[uint64]*data.LockInfo),
 		tags:       make(map[string]map[uint64]map[string]string),
+		bucketTags: make(map[string]map[string]string),
 		multiparts: make(map[string]map[string][]*data.MultipartInfo),
 		parts:      make(map[string]map[int]*data.PartInfo),
+		payloads:   make(map[string]map[string]data.DeduplicatedPayload),
+		trash:      make(map[string][]*data.TrashedVersion),
+
+		notifications: make(map[string]oid.ID),
 	}
 }
 
@@ -100,12 +121,23 @@ func (t *TreeServiceMock) GetSettingsNode(_ context.Context, bktInfo *data.Bucke
 	return settings, nil
 }
 
-func (t *TreeServiceMock) GetNotificationConfigurationNode(_ context.Context, _ *data.BucketInfo) (oid.ID, error) {
-	panic("implement me")
+func (t *TreeServiceMock) GetNotificationConfigurationNode(_ context.Context, bktInfo *data.BucketInfo) (oid.ID, error) {
+	objID, ok := t.notifications[bktInfo.CID.EncodeToString()]
+	if !ok {
+		return oid.ID{}, ErrNodeNotFound
+	}
+
+	return objID, nil
 }
 
-func (t *TreeServiceMock) PutNotificationConfigurationNode(_ context.Context, _ *data.BucketInfo, _ oid.ID) (oid.ID, error) {
-	panic("implement me")
+func (t *TreeServiceMock) PutNotificationConfigurationNode(_ context.Context, bktInfo *data.BucketInfo, objID oid.ID) (oid.ID, error) {
+	prev, ok := t.notifications[bktInfo.CID.EncodeToString()]
+	t.notifications[bktInfo.CID.EncodeToString()] = objID
+	if !ok {
+		return oid.ID{}, ErrNoNodeToRemove
+	}
+
+	return prev, nil
 }
 
 func (t *TreeServiceMock) GetBucketCORS(_ context.Context, _ *data.BucketInfo) (oid.ID, error) {
@@ -120,6 +152,72 @@ func (t *TreeServiceMock) DeleteBucketCORS(_ context.Context, _ *data.BucketInfo
 	panic("implement me")
 }
 
+func (t *TreeServiceMock) GetDeduplicatedPayload(_ context.Context, bktInfo *data.BucketInfo, hash string) (*data.DeduplicatedPayload, error) {
+	t.payloadsMu.Lock()
+	defer t.payloadsMu.Unlock()
+
+	payload, ok := t.payloads[bktInfo.CID.EncodeToString()][hash]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+
+	return &payload, nil
+}
+
+func (t *TreeServiceMock) PutDeduplicatedPayload(_ context.Context, bktInfo *data.BucketInfo, payload *data.DeduplicatedPayload) error {
+	t.payloadsMu.Lock()
+	defer t.payloadsMu.Unlock()
+
+	cnrPayloads, ok := t.payloads[bktInfo.CID.EncodeToString()]
+	if !ok {
+		cnrPayloads = make(map[string]data.DeduplicatedPayload)
+		t.payloads[bktInfo.CID.EncodeToString()] = cnrPayloads
+	}
+	cnrPayloads[payload.Hash] = *payload
+
+	return nil
+}
+
+func (t *TreeServiceMock) DeleteDeduplicatedPayload(_ context.Context, bktInfo *data.BucketInfo, hash string) error {
+	t.payloadsMu.Lock()
+	defer t.payloadsMu.Unlock()
+
+	delete(t.payloads[bktInfo.CID.EncodeToString()], hash)
+
+	return nil
+}
+
+func (t *TreeServiceMock) AddTrashedVersion(_ context.Context, bktInfo *data.BucketInfo, version *data.TrashedVersion) error {
+	t.lastVersionID++
+	version.ID = t.lastVersionID
+
+	t.trash[bktInfo.CID.EncodeToString()] = append(t.trash[bktInfo.CID.EncodeToString()], version)
+	return nil
+}
+
+func (t *TreeServiceMock) GetTrashedVersions(_ context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.TrashedVersion, error) {
+	var result []*data.TrashedVersion
+	for _, version := range t.trash[bktInfo.CID.EncodeToString()] {
+		if objectName == "" || version.FilePath == objectName {
+			result = append(result, version)
+		}
+	}
+
+	return result, nil
+}
+
+func (t *TreeServiceMock) RemoveTrashedVersion(_ context.Context, bktInfo *data.BucketInfo, nodeID uint64) error {
+	versions := t.trash[bktInfo.CID.EncodeToString()]
+	for i, version := range versions {
+		if version.ID == nodeID {
+			t.trash[bktInfo.CID.EncodeToString()] = append(versions[:i], versions[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNodeNotFound
+}
+
 func (t *TreeServiceMock) GetVersions(_ context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.NodeVersion, error) {
 	cnrVersionsMap, ok := t.versions[bktInfo.CID.EncodeToString()]
 	if !ok {
@@ -201,19 +299,22 @@ func (t *TreeServiceMock) GetUnversioned(_ context.Context, bktInfo *data.Bucket
 	return nil, ErrNodeNotFound
 }
 
-func (t *TreeServiceMock) AddVersion(_ context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, error) {
+func (t *TreeServiceMock) AddVersion(_ context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, []*data.NodeVersion, error) {
+	t.lastVersionID++
+	newVersion.ID = t.lastVersionID
+
 	cnrVersionsMap, ok := t.versions[bktInfo.CID.EncodeToString()]
 	if !ok {
 		t.versions[bktInfo.CID.EncodeToString()] = map[string][]*data.NodeVersion{
 			newVersion.FilePath: {newVersion},
 		}
-		return newVersion.ID, nil
+		return newVersion.ID, nil, nil
 	}
 
 	versions, ok := cnrVersionsMap[newVersion.FilePath]
 	if !ok {
 		cnrVersionsMap[newVersion.FilePath] = []*data.NodeVersion{newVersion}
-		return newVersion.ID, nil
+		return newVersion.ID, nil, nil
 	}
 
 	sort.Slice(versions, func(i, j int) bool {
@@ -221,24 +322,31 @@ func (t *TreeServiceMock) AddVersion(_ context.Context, bktInfo *data.BucketInfo
 	})
 
 	if len(versions) != 0 {
-		newVersion.ID = versions[len(versions)-1].ID + 1
 		newVersion.Timestamp = versions[len(versions)-1].Timestamp + 1
 	}
 
 	result := versions
+	var removed []*data.NodeVersion
 
 	if newVersion.IsUnversioned {
 		result = make([]*data.NodeVersion, 0, len(versions))
 		for _, node := range versions {
 			if !node.IsUnversioned {
 				result = append(result, node)
+			} else {
+				removed = append(removed, node)
 			}
 		}
+		// The node returned by GetUnversioned is replaced, the rest are
+		// duplicates.
+		if len(removed) != 0 {
+			removed = removed[1:]
+		}
 	}
 
 	cnrVersionsMap[newVersion.FilePath] = append(result, newVersion)
 
-	return newVersion.ID, nil
+	return newVersion.ID, removed, nil
 }
 
 func (t *TreeServiceMock) RemoveVersion(_ context.Context, bktInfo *data.BucketInfo, nodeID uint64) error {
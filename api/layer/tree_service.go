@@ -44,6 +44,27 @@ type TreeService interface {
 	// If object id to remove is not found returns ErrNoNodeToRemove error.
 	DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketInfo) (oid.ID, error)
 
+	// GetDeduplicatedPayload gets the payload with the hash stored in the bucket.
+	//
+	// If tree node is not found returns ErrNodeNotFound error.
+	GetDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, hash string) (*data.DeduplicatedPayload, error)
+
+	// PutDeduplicatedPayload update or create new node of the payload in a system tree.
+	PutDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, payload *data.DeduplicatedPayload) error
+
+	// DeleteDeduplicatedPayload removes the node of the payload from a system tree.
+	DeleteDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, hash string) error
+
+	// AddTrashedVersion stores the version deleted in a bucket with soft deletion.
+	AddTrashedVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.TrashedVersion) error
+
+	// GetTrashedVersions returns deleted versions of the object kept in the
+	// bucket, versions of all objects are returned if the name is empty.
+	GetTrashedVersions(ctx context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.TrashedVersion, error)
+
+	// RemoveTrashedVersion removes the node of the deleted version.
+	RemoveTrashedVersion(ctx context.Context, bktInfo *data.BucketInfo, nodeID uint64) error
+
 	GetObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) (map[string]string, error)
 	PutObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion, tagSet map[string]string) error
 	DeleteObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) error
@@ -57,7 +78,10 @@ type TreeService interface {
 	GetLatestVersionsByPrefix(ctx context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.NodeVersion, error)
 	GetAllVersionsByPrefix(ctx context.Context, bktInfo *data.BucketInfo, prefix string) ([]*data.NodeVersion, error)
 	GetUnversioned(ctx context.Context, bktInfo *data.BucketInfo, objectName string) (*data.NodeVersion, error)
-	AddVersion(ctx context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, error)
+	// AddVersion adds the version and returns its node ID. Adding an
+	// unversioned version also returns the duplicated unversioned nodes
+	// removed along the way, their objects are left to the caller.
+	AddVersion(ctx context.Context, bktInfo *data.BucketInfo, newVersion *data.NodeVersion) (uint64, []*data.NodeVersion, error)
 	RemoveVersion(ctx context.Context, bktInfo *data.BucketInfo, nodeID uint64) error
 
 	PutLock(ctx context.Context, bktInfo *data.BucketInfo, nodeID uint64, lock *data.LockInfo) error
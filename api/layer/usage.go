@@ -0,0 +1,121 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+type (
+	// UsageByTagParams stores GetUsageByTag request parameters.
+	UsageByTagParams struct {
+		// Owners are users which buckets are included in the report.
+		Owners []user.ID
+		// Tag is the bucket tag key to group buckets by.
+		Tag string
+	}
+
+	// TagUsage contains summary storage statistics of buckets having the same
+	// tag value.
+	TagUsage struct {
+		data.BucketUsage
+		// Value is the tag value, empty for buckets without the tag.
+		Value string
+		// Buckets are names of the grouped buckets in alphabetical order.
+		Buckets []string
+	}
+)
+
+// GetBucketUsage calculates storage statistics of the bucket using object
+// versions from the tree service.
+func (n *layer) GetBucketUsage(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketUsage, error) {
+	versions, err := n.treeService.GetAllVersionsByPrefix(ctx, bktInfo, "")
+	if err != nil {
+		return nil, fmt.Errorf("get all versions: %w", err)
+	}
+
+	var (
+		usage  data.BucketUsage
+		latest = make(map[string]*data.NodeVersion)
+	)
+
+	for _, version := range versions {
+		if last, ok := latest[version.FilePath]; !ok || isNewerVersion(version, last) {
+			latest[version.FilePath] = version
+		}
+
+		if version.IsDeleteMarker() {
+			continue
+		}
+
+		usage.Versions++
+		if version.Size > 0 {
+			usage.Bytes += uint64(version.Size)
+		}
+	}
+
+	for _, version := range latest {
+		if !version.IsDeleteMarker() {
+			usage.Objects++
+		}
+	}
+
+	return &usage, nil
+}
+
+// GetUsageByTag calculates storage statistics of the owners buckets and
+// groups them by the value of the bucket tag. Groups are sorted by value.
+func (n *layer) GetUsageByTag(ctx context.Context, p *UsageByTagParams) ([]*TagUsage, error) {
+	groups := make(map[string]*TagUsage)
+
+	for _, owner := range p.Owners {
+		buckets, err := n.userContainerList(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("list buckets of %s: %w", owner, err)
+		}
+
+		for _, bktInfo := range buckets {
+			tags, err := n.GetBucketTagging(ctx, bktInfo)
+			if err != nil {
+				return nil, fmt.Errorf("get tagging of bucket %s: %w", bktInfo.Name, err)
+			}
+
+			usage, err := n.GetBucketUsage(ctx, bktInfo)
+			if err != nil {
+				return nil, fmt.Errorf("get usage of bucket %s: %w", bktInfo.Name, err)
+			}
+
+			value := tags[p.Tag]
+			group, ok := groups[value]
+			if !ok {
+				group = &TagUsage{Value: value}
+				groups[value] = group
+			}
+
+			group.Buckets = append(group.Buckets, bktInfo.Name)
+			group.Objects += usage.Objects
+			group.Versions += usage.Versions
+			group.Bytes += usage.Bytes
+		}
+	}
+
+	res := make([]*TagUsage, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.Buckets)
+		res = append(res, group)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Value < res[j].Value })
+
+	return res, nil
+}
+
+func isNewerVersion(a, b *data.NodeVersion) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp > b.Timestamp
+	}
+
+	return a.ID > b.ID
+}
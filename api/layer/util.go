@@ -84,12 +84,30 @@ func extractHeaders(headers map[string]string) (map[string]string, string, time.
 	return headers, mimeType, creation
 }
 
-func objectInfoFromMeta(bkt *data.BucketInfo, meta *object.Object) *data.ObjectInfo {
+// objectInfoFromMeta forms object info from the object header. ETag saved in
+// the tree node on object creation takes precedence over the one derived from
+// the header for all gateway instances to return the same value.
+func objectInfoFromMeta(bkt *data.BucketInfo, meta *object.Object, node *data.NodeVersion) *data.ObjectInfo {
 	attributes := userHeaders(meta.Attributes())
 	customHeaders, mimeType, creation := extractHeaders(attributes)
 
 	objID, _ := meta.ID()
 	payloadChecksum, _ := meta.PayloadChecksum()
+	size := int64(meta.PayloadSize())
+	hashSum := hex.EncodeToString(payloadChecksum.Value())
+	if _, ok := customHeaders[AttributePayloadObject]; ok {
+		if payloadSize, err := strconv.ParseInt(customHeaders[AttributePayloadSize], 10, 64); err == nil {
+			size = payloadSize
+		}
+		hashSum = customHeaders[AttributePayloadHash]
+	}
+	if compInfo, ok, err := compressionInfo(customHeaders); ok && err == nil {
+		size = int64(compInfo.Size)
+	}
+	if node != nil && len(node.ETag) > 0 {
+		hashSum = node.ETag
+	}
+
 	return &data.ObjectInfo{
 		ID:    objID,
 		CID:   bkt.CID,
@@ -101,8 +119,8 @@ func objectInfoFromMeta(bkt *data.BucketInfo, meta *object.Object) *data.ObjectI
 		ContentType: mimeType,
 		Headers:     customHeaders,
 		Owner:       *meta.OwnerID(),
-		Size:        int64(meta.PayloadSize()),
-		HashSum:     hex.EncodeToString(payloadChecksum.Value()),
+		Size:        size,
+		HashSum:     hashSum,
 	}
 }
 
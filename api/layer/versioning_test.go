@@ -142,6 +142,7 @@ func prepareContext(t *testing.T, cachesConfig ...*CachesConfig) *testContext {
 	anonSigner := user.NewAutoIDSignerRFC6979(anonKey.PrivateKey)
 
 	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	owner := signer.UserID()
 
 	bearerToken := bearertest.Token(t)
 	require.NoError(t, bearerToken.Sign(signer))
@@ -156,7 +157,8 @@ func prepareContext(t *testing.T, cachesConfig ...*CachesConfig) *testContext {
 
 	bktName := "testbucket1"
 	bktID, err := tp.CreateContainer(ctx, PrmContainerCreate{
-		Name: bktName,
+		Creator: owner,
+		Name:    bktName,
 	})
 	require.NoError(t, err)
 
@@ -165,8 +167,6 @@ func prepareContext(t *testing.T, cachesConfig ...*CachesConfig) *testContext {
 		config = cachesConfig[0]
 	}
 
-	owner := signer.UserID()
-
 	layerCfg := &Config{
 		Caches:      config,
 		GateKey:     key,
@@ -227,6 +227,116 @@ func TestSimpleNoVersioning(t *testing.T) {
 	tc.checkListObjects(obj1v2.ID)
 }
 
+func TestNoVersioningOverwriteDeletesReplacedObject(t *testing.T) {
+	tc := prepareContext(t)
+
+	tc.putObject([]byte("content obj1 v1"))
+	obj1v2 := tc.putObject([]byte("content obj1 v2"))
+
+	require.Equal(t, []oid.ID{obj1v2.ID}, tc.testNeoFS.AllObjects(tc.bktInfo.CID))
+
+	versions, err := tc.layer.ListObjectVersions(tc.ctx, &ListObjectVersionsParams{
+		BktInfo: tc.bktInfo,
+		MaxKeys: 1000,
+	})
+	require.NoError(t, err)
+	require.Len(t, versions.Version, 1)
+	require.Equal(t, obj1v2.ID, versions.Version[0].ObjectInfo.ID)
+}
+
+func TestNoVersioningOverwriteDeletesDuplicatedObjects(t *testing.T) {
+	tc := prepareContext(t)
+
+	obj1v1 := tc.putObject([]byte("content obj1 v1"))
+
+	// A concurrent upload through another gateway left a second
+	// unversioned node for the same key.
+	dupID, err := tc.testNeoFS.CreateObject(tc.ctx, PrmObjectCreate{
+		Container: tc.bktInfo.CID,
+		Creator:   tc.bktInfo.Owner,
+		Filepath:  tc.obj,
+		Payload:   bytes.NewReader([]byte("content obj1 duplicate")),
+	})
+	require.NoError(t, err)
+
+	treeService := tc.layer.(*layer).treeService.(*TreeServiceMock)
+	treeService.lastVersionID++
+	versions := treeService.versions[tc.bktInfo.CID.EncodeToString()]
+	versions[tc.obj] = append(versions[tc.obj], &data.NodeVersion{
+		BaseNodeVersion: data.BaseNodeVersion{
+			ID:       treeService.lastVersionID,
+			OID:      dupID,
+			FilePath: tc.obj,
+		},
+		IsUnversioned: true,
+	})
+
+	obj1v2 := tc.putObject([]byte("content obj1 v2"))
+
+	require.Nil(t, tc.getObjectByID(obj1v1.ID))
+	require.Nil(t, tc.getObjectByID(dupID))
+	require.Equal(t, []oid.ID{obj1v2.ID}, tc.testNeoFS.AllObjects(tc.bktInfo.CID))
+}
+
+func TestGetBucketUsage(t *testing.T) {
+	tc := prepareContext(t)
+	settings := &data.BucketSettings{Versioning: data.VersioningEnabled}
+	err := tc.layer.PutBucketSettings(tc.ctx, &PutSettingsParams{
+		BktInfo:  tc.bktInfo,
+		Settings: settings,
+	})
+	require.NoError(t, err)
+
+	tc.putObject([]byte("obj1 v1"))
+	tc.putObject([]byte("obj1 v2"))
+	tc.deleteObject(tc.obj, "", settings)
+
+	tc.obj = "obj2"
+	tc.putObject([]byte("obj2 content"))
+
+	usage, err := tc.layer.GetBucketUsage(tc.ctx, tc.bktInfo)
+	require.NoError(t, err)
+	require.Equal(t, &data.BucketUsage{Objects: 1, Versions: 3, Bytes: 26}, usage)
+}
+
+func TestGetUsageByTag(t *testing.T) {
+	tc := prepareContext(t)
+	bucket1 := tc.bktInfo
+
+	createBucket := func(name string) *data.BucketInfo {
+		cnrID, err := tc.testNeoFS.CreateContainer(tc.ctx, PrmContainerCreate{Name: name, Creator: bucket1.Owner})
+		require.NoError(t, err)
+		return &data.BucketInfo{Name: name, CID: cnrID, Owner: bucket1.Owner}
+	}
+	bucket2, bucket3 := createBucket("testbucket2"), createBucket("testbucket3")
+
+	for _, bktInfo := range []*data.BucketInfo{bucket1, bucket2, bucket3} {
+		tc.bktInfo = bktInfo
+		tc.putObject([]byte("content of " + bktInfo.Name))
+	}
+
+	require.NoError(t, tc.layer.PutBucketTagging(tc.ctx, bucket1, map[string]string{"team": "storage"}))
+	require.NoError(t, tc.layer.PutBucketTagging(tc.ctx, bucket2, map[string]string{"team": "storage", "project": "s3"}))
+	require.NoError(t, tc.layer.PutBucketTagging(tc.ctx, bucket3, map[string]string{"project": "s3"}))
+
+	groups, err := tc.layer.GetUsageByTag(tc.ctx, &UsageByTagParams{
+		Owners: []user.ID{bucket1.Owner},
+		Tag:    "team",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*TagUsage{
+		{
+			BucketUsage: data.BucketUsage{Objects: 1, Versions: 1, Bytes: 22},
+			Buckets:     []string{"testbucket3"},
+		},
+		{
+			BucketUsage: data.BucketUsage{Objects: 2, Versions: 2, Bytes: 44},
+			Value:       "storage",
+			Buckets:     []string{"testbucket1", "testbucket2"},
+		},
+	}, groups)
+}
+
 func TestVersioningDeleteObject(t *testing.T) {
 	tc := prepareContext(t)
 	settings := &data.BucketSettings{Versioning: data.VersioningEnabled}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+const (
+	// MaxHeaderSize is the limit of the total size of request header names
+	// and values, it's the same as in AWS S3.
+	MaxHeaderSize = 8 * 1024
+
+	// DefaultMaxHeaderCount is the default limit of the number of request
+	// header values. AWS S3 limits the header size only, so it's generous
+	// enough for requests with a lot of user metadata fitting MaxHeaderSize.
+	DefaultMaxHeaderCount = 500
+
+	// MaxObjectKeyLength is the limit of object key length in bytes of
+	// UTF-8 representation, it's the same as in AWS S3.
+	MaxObjectKeyLength = 1024
+)
+
+// RequestLimits restricts requests served by the S3 API.
+type RequestLimits struct {
+	// MaxHeaderCount is the limit of the number of request header values,
+	// 0 disables the limit.
+	MaxHeaderCount int
+}
+
+// checkRequestLimits rejects requests with too large headers or too long
+// object keys before they reach authentication and NeoFS, where they'd fail
+// as opaque attribute errors.
+func checkRequestLimits(limits RequestLimits) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqInfo := GetReqInfo(r.Context())
+
+			if err := limits.checkHeader(r.Header); err != nil {
+				WriteErrorResponse(w, reqInfo, err)
+				return
+			}
+
+			if err := CheckObjectKey(reqInfo.ObjectName); err != nil {
+				WriteErrorResponse(w, reqInfo, err)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l RequestLimits) checkHeader(header http.Header) error {
+	var size, count int
+	for name, values := range header {
+		for _, value := range values {
+			size += len(name) + len(value)
+			count++
+		}
+	}
+
+	if size > MaxHeaderSize || l.MaxHeaderCount > 0 && count > l.MaxHeaderCount {
+		return s3errors.GetAPIError(s3errors.ErrRequestHeaderSectionTooLarge)
+	}
+
+	return nil
+}
+
+// CheckObjectKey returns KeyTooLongError if the object key exceeds
+// MaxObjectKeyLength.
+func CheckObjectKey(key string) error {
+	if len(key) > MaxObjectKeyLength {
+		return s3errors.GetAPIError(s3errors.ErrKeyTooLongError)
+	}
+
+	return nil
+}
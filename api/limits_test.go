@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRequestLimits(t *testing.T) {
+	withHeaders := func(count, size int) http.Header {
+		header := make(http.Header)
+		for i := 0; i < count; i++ {
+			header.Add("X-Amz-Meta-"+strconv.Itoa(i), strings.Repeat("v", size))
+		}
+		return header
+	}
+
+	for _, tc := range []struct {
+		name   string
+		limits RequestLimits
+		header http.Header
+		object string
+		status int
+	}{
+		{name: "valid", limits: RequestLimits{MaxHeaderCount: DefaultMaxHeaderCount}, header: withHeaders(10, 10), object: "obj", status: http.StatusOK},
+		{name: "metadata of default count", limits: RequestLimits{MaxHeaderCount: DefaultMaxHeaderCount}, header: withHeaders(200, 1), status: http.StatusOK},
+		{name: "too many headers", limits: RequestLimits{MaxHeaderCount: 10}, header: withHeaders(11, 1), status: http.StatusBadRequest},
+		{name: "count isn't limited", header: withHeaders(DefaultMaxHeaderCount+1, 1), status: http.StatusOK},
+		{name: "too large header", header: withHeaders(1, MaxHeaderSize), status: http.StatusBadRequest},
+		{name: "too large headers", limits: RequestLimits{MaxHeaderCount: DefaultMaxHeaderCount}, header: withHeaders(10, MaxHeaderSize/10), status: http.StatusBadRequest},
+		{name: "too long key", object: strings.Repeat("a", MaxObjectKeyLength+1), status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var called bool
+			h := checkRequestLimits(tc.limits)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+
+			r := httptest.NewRequest(http.MethodPut, "/bucket", nil)
+			r.Header = tc.header
+			r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{ObjectName: tc.object}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.status == http.StatusOK, called)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -28,6 +29,21 @@ type (
 
 		totalInputBytes  uint64
 		totalOutputBytes uint64
+
+		buckets bucketsStats
+	}
+
+	// BucketRequests holds statistics of requests made to a single bucket.
+	BucketRequests struct {
+		Requests    uint64 `json:"requests"`
+		Errors      uint64 `json:"errors"`
+		InputBytes  uint64 `json:"input_bytes"`
+		OutputBytes uint64 `json:"output_bytes"`
+	}
+
+	bucketsStats struct {
+		sync.RWMutex
+		stats map[string]*BucketRequests
 	}
 
 	readCounter struct {
@@ -44,12 +60,20 @@ type (
 		sync.Once
 		http.ResponseWriter
 
-		statusCode int
-		startTime  time.Time
+		statusCode    int
+		startTime     time.Time
+		headerTime    time.Time
+		firstByteTime time.Time
 	}
 )
 
-const systemPath = "/system"
+const (
+	systemPath = "/system"
+
+	// maxTrackedBuckets limits the number of buckets with request statistics,
+	// so requests to random bucket names can't exhaust memory.
+	maxTrackedBuckets = 10000
+)
 
 var (
 	httpStatsMetric      = new(HTTPStats)
@@ -61,6 +85,49 @@ var (
 		},
 		[]string{"api"},
 	)
+	httpFirstByteDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "neofs_s3_first_byte_seconds",
+			Help:    "Time to the first byte of responses served by current NeoFS S3 Gate instance",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"api"},
+	)
+	hedgedReads = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_hedged_reads_total",
+			Help: "Total number of payload reads hedged after first byte timeout in current NeoFS S3 Gate instance",
+		},
+	)
+	listingPartialObjects = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_listing_partial_objects_total",
+			Help: "Total number of listed objects with unavailable headers returned with tree data by current NeoFS S3 Gate instance",
+		},
+	)
+
+	rateLimitedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_rate_limited_requests_total",
+			Help: "Total number of requests rejected by current NeoFS S3 Gate instance because of the access key rate limit",
+		},
+	)
+
+	authentications = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_authentications_total",
+			Help: "Total number of signed requests authenticated by current NeoFS S3 Gate instance by result",
+		},
+		[]string{"result"},
+	)
+	signatureVerificationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "neofs_s3_signature_verification_seconds",
+			Help:    "Time taken by verification of request signatures in current NeoFS S3 Gate instance",
+			Buckets: []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05},
+		},
+		[]string{"algorithm"},
+	)
 )
 
 // Collects HTTP metrics for NeoFS S3 Gate in Prometheus specific format
@@ -124,15 +191,51 @@ func APIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 		// Time duration in secs since the call started.
 		// We don't need to do nanosecond precision here
 		// simply for the fact that it is not human readable.
-		durationSecs := time.Since(statsWriter.startTime).Seconds()
+		duration := time.Since(statsWriter.startTime)
+		durationSecs := duration.Seconds()
 
 		httpStatsMetric.updateStats(api, statsWriter, r, durationSecs)
+		statsWriter.observeFirstByte(api)
+		sloObjectives.observe(api, duration, time.Now())
+		slowRequests.observe(api, statsWriter, r, duration)
+		if bucket := mux.Vars(r)["bucket"]; bucket != "" {
+			httpStatsMetric.buckets.update(bucket, statsWriter.statusCode, in.countBytes, out.countBytes)
+		}
 
 		atomic.AddUint64(&httpStatsMetric.totalInputBytes, in.countBytes)
 		atomic.AddUint64(&httpStatsMetric.totalOutputBytes, out.countBytes)
 	}
 }
 
+// IncHedgedReads increments the number of hedged payload reads.
+func IncHedgedReads() {
+	hedgedReads.Inc()
+}
+
+// IncListingPartialObjects increments the number of listed objects with
+// unavailable headers.
+func IncListingPartialObjects() {
+	listingPartialObjects.Inc()
+}
+
+// IncRateLimitedRequests increments the number of requests rejected because
+// of the access key rate limit.
+func IncRateLimitedRequests() {
+	rateLimitedRequests.Inc()
+}
+
+// IncAuthentications increments the number of authentications with the
+// result, it's either "success" or the failure reason.
+func IncAuthentications(result string) {
+	authentications.WithLabelValues(result).Inc()
+}
+
+// ObserveSignatureVerification records the time taken by verification of the
+// request signature of the algorithm.
+func ObserveSignatureVerification(algorithm string, d time.Duration) {
+	signatureVerificationDuration.WithLabelValues(algorithm).Observe(d.Seconds())
+}
+
 // Inc increments the api stats counter.
 func (stats *HTTPAPIStats) Inc(api string) {
 	if stats == nil {
@@ -169,6 +272,47 @@ func (stats *HTTPAPIStats) Load() map[string]int {
 	return apiStats
 }
 
+// LoadBucketRequests returns request statistics of the bucket.
+func LoadBucketRequests(bucket string) BucketRequests {
+	return httpStatsMetric.buckets.load(bucket)
+}
+
+func (b *bucketsStats) update(bucket string, code int, in, out uint64) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.stats == nil {
+		b.stats = make(map[string]*BucketRequests)
+	}
+
+	st, ok := b.stats[bucket]
+	if !ok {
+		if len(b.stats) >= maxTrackedBuckets {
+			return
+		}
+		st = new(BucketRequests)
+		b.stats[bucket] = st
+	}
+
+	st.Requests++
+	if code != 0 && (code < http.StatusOK || code >= http.StatusMultipleChoices) {
+		st.Errors++
+	}
+	st.InputBytes += in
+	st.OutputBytes += out
+}
+
+func (b *bucketsStats) load(bucket string) BucketRequests {
+	b.RLock()
+	defer b.RUnlock()
+
+	if st, ok := b.stats[bucket]; ok {
+		return *st
+	}
+
+	return BucketRequests{}
+}
+
 func (st *HTTPStats) getInputBytes() uint64 {
 	return atomic.LoadUint64(&st.totalInputBytes)
 }
@@ -205,10 +349,33 @@ func (st *HTTPStats) updateStats(api string, w http.ResponseWriter, r *http.Requ
 func (w *responseWrapper) WriteHeader(code int) {
 	w.Do(func() {
 		w.statusCode = code
+		w.headerTime = time.Now()
 		w.ResponseWriter.WriteHeader(code)
 	})
 }
 
+// Write -- writes body and remembers the time of the first byte.
+func (w *responseWrapper) Write(p []byte) (int, error) {
+	if w.firstByteTime.IsZero() && len(p) > 0 {
+		w.firstByteTime = time.Now()
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// observeFirstByte records time to the first byte of the body or to the
+// header for responses without body.
+func (w *responseWrapper) observeFirstByte(api string) {
+	first := w.firstByteTime
+	if first.IsZero() {
+		first = w.headerTime
+	}
+	if first.IsZero() {
+		return
+	}
+
+	httpFirstByteDuration.With(prometheus.Labels{"api": api}).Observe(first.Sub(w.startTime).Seconds())
+}
+
 // Flush -- calls the underlying Flush.
 func (w *responseWrapper) Flush() {
 	if f, ok := w.ResponseWriter.(http.Flusher); ok {
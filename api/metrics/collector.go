@@ -31,6 +31,12 @@ func init() {
 	prometheus.MustRegister(versionInfo)
 	prometheus.MustRegister(statsMetrics)
 	prometheus.MustRegister(httpRequestsDuration)
+	prometheus.MustRegister(httpFirstByteDuration)
+	prometheus.MustRegister(hedgedReads)
+	prometheus.MustRegister(listingPartialObjects)
+	prometheus.MustRegister(rateLimitedRequests)
+	prometheus.MustRegister(authentications)
+	prometheus.MustRegister(signatureVerificationDuration)
 }
 
 func collectNetworkMetrics(ch chan<- prometheus.Metric) {
@@ -65,4 +71,5 @@ func (s *stats) Collect(ch chan<- prometheus.Metric) {
 	// connect collectors
 	collectHTTPMetrics(ch)
 	collectNetworkMetrics(ch)
+	collectSLOMetrics(ch)
 }
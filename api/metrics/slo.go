@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sloSlots is a number of parts of the SLO window, requests of the oldest part
+// are forgotten at once.
+const sloSlots = 10
+
+type (
+	// Objective is a latency objective of the API: Quantile of its requests
+	// must be served faster than Threshold, e.g. p99 of getobject < 200ms.
+	Objective struct {
+		API       string
+		Quantile  float64
+		Threshold time.Duration
+	}
+
+	// SLOConfig contains latency objectives checked over the sliding window.
+	SLOConfig struct {
+		Objectives []Objective
+		Window     time.Duration
+		// MinRequests is a number of requests in the window required to
+		// consider the objective breached.
+		MinRequests uint64
+	}
+
+	// SLOBreach describes the objective breached over the window.
+	SLOBreach struct {
+		API       string  `json:"api"`
+		Quantile  float64 `json:"quantile"`
+		Threshold string  `json:"threshold"`
+		Requests  uint64  `json:"requests"`
+		// SlowRatio is a part of requests served slower than the threshold.
+		SlowRatio float64 `json:"slowRatio"`
+	}
+
+	sloTracker struct {
+		mu          sync.RWMutex
+		objectives  map[string][]*objectiveState
+		slot        time.Duration
+		minRequests uint64
+	}
+
+	objectiveState struct {
+		Objective
+
+		mu    sync.Mutex
+		slots [sloSlots]sloSlot
+	}
+
+	sloSlot struct {
+		index int64
+		total uint64
+		slow  uint64
+	}
+)
+
+var sloObjectives = new(sloTracker)
+
+// SetSLO replaces latency objectives, requests observed before aren't taken
+// into account.
+func SetSLO(cfg SLOConfig) {
+	objectives := make(map[string][]*objectiveState, len(cfg.Objectives))
+	for _, o := range cfg.Objectives {
+		objectives[o.API] = append(objectives[o.API], &objectiveState{Objective: o})
+	}
+
+	sloObjectives.mu.Lock()
+	sloObjectives.objectives = objectives
+	sloObjectives.slot = cfg.Window / sloSlots
+	sloObjectives.minRequests = cfg.MinRequests
+	sloObjectives.mu.Unlock()
+}
+
+// SLOBreaches returns latency objectives breached over the window.
+func SLOBreaches() []SLOBreach {
+	var res []SLOBreach
+	sloObjectives.evaluate(time.Now(), func(o *objectiveState, total, slow uint64, breached bool) {
+		if breached {
+			res = append(res, o.breach(total, slow))
+		}
+	})
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].API != res[j].API {
+			return res[i].API < res[j].API
+		}
+		return res[i].Quantile < res[j].Quantile
+	})
+
+	return res
+}
+
+func (t *sloTracker) observe(api string, duration time.Duration, now time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.slot <= 0 {
+		return
+	}
+
+	index := now.UnixNano() / int64(t.slot)
+	for _, o := range t.objectives[api] {
+		o.mu.Lock()
+		slot := &o.slots[index%sloSlots]
+		if slot.index != index {
+			*slot = sloSlot{index: index}
+		}
+		slot.total++
+		if duration > o.Threshold {
+			slot.slow++
+		}
+		o.mu.Unlock()
+	}
+}
+
+// evaluate calls f for every objective with the number of requests in the
+// window, the number of slow ones and whether the objective is breached.
+func (t *sloTracker) evaluate(now time.Time, f func(o *objectiveState, total, slow uint64, breached bool)) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.slot <= 0 {
+		return
+	}
+
+	index := now.UnixNano() / int64(t.slot)
+	for _, list := range t.objectives {
+		for _, o := range list {
+			var total, slow uint64
+			o.mu.Lock()
+			for _, slot := range o.slots {
+				if slot.index > index-sloSlots {
+					total += slot.total
+					slow += slot.slow
+				}
+			}
+			o.mu.Unlock()
+
+			f(o, total, slow, breached(o.Quantile, total, slow, t.minRequests))
+		}
+	}
+}
+
+// breached checks whether the part of slow requests is greater than allowed
+// by the quantile: p99 < 200ms is breached if more than 1% of requests take
+// more than 200ms.
+func breached(quantile float64, total, slow, minRequests uint64) bool {
+	if total == 0 || total < minRequests {
+		return false
+	}
+
+	return float64(slow)/float64(total) > 1-quantile
+}
+
+func (o *objectiveState) breach(total, slow uint64) SLOBreach {
+	return SLOBreach{
+		API:       o.API,
+		Quantile:  o.Quantile,
+		Threshold: o.Threshold.String(),
+		Requests:  total,
+		SlowRatio: float64(slow) / float64(total),
+	}
+}
+
+func collectSLOMetrics(ch chan<- prometheus.Metric) {
+	desc := prometheus.NewDesc(
+		prometheus.BuildFQName("neofs_s3", "slo", "breached"),
+		"Latency objective of the API is breached over the window in current NeoFS S3 Gate instance",
+		[]string{"api", "quantile", "threshold"}, nil)
+
+	sloObjectives.evaluate(time.Now(), func(o *objectiveState, _, _ uint64, breached bool) {
+		var value float64
+		if breached {
+			value = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value,
+			o.API, strconv.FormatFloat(o.Quantile, 'f', -1, 64), o.Threshold.String())
+	})
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTracker(t *testing.T) {
+	tracker := new(sloTracker)
+	tracker.objectives = map[string][]*objectiveState{
+		"getobject": {{Objective: Objective{API: "getobject", Quantile: 0.9, Threshold: 200 * time.Millisecond}}},
+	}
+	tracker.slot = time.Second
+	tracker.minRequests = 10
+
+	breaches := func(now time.Time) []SLOBreach {
+		var res []SLOBreach
+		tracker.evaluate(now, func(o *objectiveState, total, slow uint64, breached bool) {
+			if breached {
+				res = append(res, o.breach(total, slow))
+			}
+		})
+		return res
+	}
+
+	now := time.Unix(1000, 0)
+	for i := 0; i < 9; i++ {
+		tracker.observe("getobject", 300*time.Millisecond, now)
+	}
+	tracker.observe("listbuckets", time.Second, now)
+	// not enough requests
+	require.Empty(t, breaches(now))
+
+	for i := 0; i < 11; i++ {
+		tracker.observe("getobject", 100*time.Millisecond, now)
+	}
+	res := breaches(now)
+	require.Len(t, res, 1)
+	require.Equal(t, "getobject", res[0].API)
+	require.Equal(t, uint64(20), res[0].Requests)
+	require.InDelta(t, 0.45, res[0].SlowRatio, 1e-9)
+
+	// fast requests recover the objective
+	later := now.Add(5 * time.Second)
+	for i := 0; i < 80; i++ {
+		tracker.observe("getobject", 100*time.Millisecond, later)
+	}
+	require.Empty(t, breaches(later))
+
+	// slow requests are forgotten after the window
+	later = now.Add(20 * time.Second)
+	for i := 0; i < 10; i++ {
+		tracker.observe("getobject", 300*time.Millisecond, later)
+	}
+	require.Len(t, breaches(later.Add(9*time.Second)), 1)
+	require.Empty(t, breaches(later.Add(10*time.Second)))
+}
+
+func TestSLOBreached(t *testing.T) {
+	require.False(t, breached(0.99, 0, 0, 0))
+	require.False(t, breached(0.99, 100, 1, 0))
+	require.True(t, breached(0.99, 100, 2, 0))
+	require.False(t, breached(0.99, 100, 2, 101))
+}
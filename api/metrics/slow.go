@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type (
+	// SlowRequestsConfig defines requests kept for diagnostics.
+	SlowRequestsConfig struct {
+		// Threshold is a duration of requests considered slow, requests
+		// aren't kept if it's not positive.
+		Threshold time.Duration
+		// Size is a number of the most recent slow requests kept.
+		Size int
+	}
+
+	// SlowRequest describes the request served slower than the threshold.
+	SlowRequest struct {
+		Time      time.Time `json:"time"`
+		API       string    `json:"api"`
+		RequestID string    `json:"request_id,omitempty"`
+		Bucket    string    `json:"bucket,omitempty"`
+		Object    string    `json:"object,omitempty"`
+		Status    int       `json:"status"`
+		Duration  string    `json:"duration"`
+	}
+
+	slowRequestsRing struct {
+		mu        sync.Mutex
+		threshold time.Duration
+		requests  []SlowRequest
+		// next is an index of the oldest request once the ring is full.
+		next int
+		full bool
+	}
+)
+
+// hdrAmzRequestID is set by the router before API handlers are called.
+const hdrAmzRequestID = "X-Amz-Request-Id"
+
+var slowRequests = new(slowRequestsRing)
+
+// SetSlowRequests replaces the parameters of kept slow requests, requests kept
+// before are forgotten.
+func SetSlowRequests(cfg SlowRequestsConfig) {
+	slowRequests.set(cfg)
+}
+
+// SlowRequests returns the most recent slow requests from the oldest one.
+func SlowRequests() []SlowRequest {
+	return slowRequests.load()
+}
+
+// observe keeps the request if it's served slower than the threshold.
+func (s *slowRequestsRing) observe(api string, w *responseWrapper, r *http.Request, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 || duration < s.threshold {
+		return
+	}
+
+	vars := mux.Vars(r)
+	s.requests[s.next] = SlowRequest{
+		Time:      w.startTime,
+		API:       api,
+		RequestID: w.Header().Get(hdrAmzRequestID),
+		Bucket:    vars["bucket"],
+		Object:    vars["object"],
+		Status:    w.statusCode,
+		Duration:  duration.String(),
+	}
+	s.next = (s.next + 1) % len(s.requests)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+func (s *slowRequestsRing) set(cfg SlowRequestsConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.threshold = cfg.Threshold
+	s.requests = nil
+	if cfg.Threshold > 0 && cfg.Size > 0 {
+		s.requests = make([]SlowRequest, cfg.Size)
+	}
+	s.next = 0
+	s.full = false
+}
+
+func (s *slowRequestsRing) load() []SlowRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.full {
+		return append([]SlowRequest(nil), s.requests[:s.next]...)
+	}
+
+	res := make([]SlowRequest, 0, len(s.requests))
+	res = append(res, s.requests[s.next:]...)
+	return append(res, s.requests[:s.next]...)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowRequestsRing(t *testing.T) {
+	ring := new(slowRequestsRing)
+
+	observe := func(object string, duration time.Duration) {
+		w := &responseWrapper{ResponseWriter: httptest.NewRecorder(), statusCode: http.StatusOK}
+		w.Header().Set(hdrAmzRequestID, "id-"+object)
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"bucket": "bkt", "object": object})
+		ring.observe("getobject", w, r, duration)
+	}
+	objects := func() []string {
+		var res []string
+		for _, req := range ring.load() {
+			res = append(res, req.Object)
+		}
+		return res
+	}
+
+	// nothing is kept until configured
+	observe("a", time.Hour)
+	require.Empty(t, ring.load())
+
+	ring.set(SlowRequestsConfig{Threshold: time.Second, Size: 3})
+	observe("a", 2*time.Second)
+	observe("fast", time.Millisecond)
+	observe("b", time.Second)
+
+	res := ring.load()
+	require.Len(t, res, 2)
+	require.Equal(t, SlowRequest{
+		API:       "getobject",
+		RequestID: "id-a",
+		Bucket:    "bkt",
+		Object:    "a",
+		Status:    http.StatusOK,
+		Duration:  "2s",
+	}, res[0])
+
+	// the oldest requests are replaced
+	observe("c", time.Second)
+	observe("d", time.Second)
+	require.Equal(t, []string{"b", "c", "d"}, objects())
+
+	ring.set(SlowRequestsConfig{Threshold: time.Second, Size: 3})
+	require.Empty(t, ring.load())
+}
@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/signing"
 	"go.uber.org/zap"
 )
 
@@ -24,6 +26,9 @@ const (
 	EventVersion21 = "2.1"
 )
 
+// ErrDeadLettersDisabled is returned if dead letter store isn't configured.
+var ErrDeadLettersDisabled = errors.New("dead letters are disabled")
+
 type (
 	Options struct {
 		URL                       string
@@ -31,6 +36,11 @@ type (
 		TLSAuthPrivateKeyFilePath string
 		Timeout                   time.Duration
 		RootCAFiles               []string
+		// Signer adds HMAC signature headers to messages, it's optional.
+		Signer *signing.HMAC
+		// DeadLetters keeps notifications failed to be delivered, it's
+		// optional.
+		DeadLetters DeadLetterStore
 	}
 
 	Controller struct {
@@ -39,6 +49,9 @@ type (
 		jsClient            nats.JetStreamContext
 		handlers            map[string]Stream
 		mu                  sync.RWMutex
+		signer              *signing.HMAC
+		deadLetters         DeadLetterStore
+		timeout             time.Duration
 	}
 
 	Stream struct {
@@ -128,6 +141,9 @@ func NewController(p *Options, l *zap.Logger) (*Controller, error) {
 		taskQueueConnection: nc,
 		jsClient:            js,
 		handlers:            make(map[string]Stream),
+		signer:              p.Signer,
+		deadLetters:         p.DeadLetters,
+		timeout:             p.Timeout,
 	}, nil
 }
 
@@ -191,7 +207,82 @@ func (c *Controller) SendNotifications(topics map[string]string, p *handler.Send
 		}
 		if err = c.publish(topic, msg); err != nil {
 			c.logger.Error("couldn't send an event to topic", zap.String("subject", topic), zap.Error(err))
+			c.saveDeadLetter(topic, msg, err)
+		}
+	}
+
+	return nil
+}
+
+// saveDeadLetter keeps the event failed to be delivered if the dead letter
+// store is configured.
+func (c *Controller) saveDeadLetter(topic string, msg []byte, sendErr error) {
+	if c.deadLetters == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.deadLetters.Put(ctx, &DeadLetter{
+		Topic:   topic,
+		Time:    time.Now(),
+		Error:   sendErr.Error(),
+		Payload: msg,
+	}); err != nil {
+		c.logger.Error("couldn't save dead letter, event is lost", zap.String("subject", topic), zap.Error(err))
+	}
+}
+
+// DeadLetters returns events failed to be delivered.
+func (c *Controller) DeadLetters(ctx context.Context) ([]*DeadLetter, error) {
+	if c.deadLetters == nil {
+		return nil, ErrDeadLettersDisabled
+	}
+
+	return c.deadLetters.List(ctx)
+}
+
+// ReplayDeadLetters sends events failed to be delivered again, delivered ones
+// are removed from the dead letter store.
+func (c *Controller) ReplayDeadLetters(ctx context.Context) (*ReplayResult, error) {
+	letters, err := c.DeadLetters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res ReplayResult
+	for _, letter := range letters {
+		if err = ctx.Err(); err != nil {
+			return &res, err
+		}
+
+		if err = c.ReplayDeadLetter(ctx, letter); err != nil {
+			res.Failed++
+			continue
 		}
+		res.Replayed++
+	}
+
+	return &res, nil
+}
+
+// ReplayDeadLetter sends the event failed to be delivered again and deletes it
+// from the dead letter store.
+func (c *Controller) ReplayDeadLetter(ctx context.Context, letter *DeadLetter) error {
+	if c.deadLetters == nil {
+		return ErrDeadLettersDisabled
+	}
+
+	if err := c.publish(letter.Topic, letter.Payload); err != nil {
+		c.logger.Error("couldn't replay dead letter", zap.String("id", letter.ID),
+			zap.String("subject", letter.Topic), zap.Error(err))
+		return err
+	}
+
+	// the event is delivered, so it's replayed even if it can't be deleted
+	if err := c.deadLetters.Delete(ctx, letter.ID); err != nil {
+		c.logger.Error("couldn't delete replayed dead letter", zap.String("id", letter.ID), zap.Error(err))
 	}
 
 	return nil
@@ -253,9 +344,21 @@ func prepareEvent(p *handler.SendNotificationParams) *Event {
 }
 
 func (c *Controller) publish(topic string, msg []byte) error {
-	if _, err := c.jsClient.Publish(topic, msg); err != nil {
+	if _, err := c.jsClient.PublishMsg(c.newMsg(topic, msg)); err != nil {
 		return fmt.Errorf("couldn't send  event: %w", err)
 	}
 
 	return nil
 }
+
+func (c *Controller) newMsg(topic string, payload []byte) *nats.Msg {
+	msg := nats.NewMsg(topic)
+	msg.Data = payload
+	if c.signer != nil {
+		timestamp, signature := c.signer.SignMessage(payload, time.Now())
+		msg.Header.Set(signing.HeaderTimestamp, timestamp)
+		msg.Header.Set(signing.HeaderSignature, signature)
+	}
+
+	return msg
+}
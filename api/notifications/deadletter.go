@@ -0,0 +1,159 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+const (
+	attributeDeadLetterTopic = "S3-Dead-Letter-Topic"
+	attributeDeadLetterError = "S3-Dead-Letter-Error"
+
+	// maxDeadLetterErrorLength limits the delivery error kept in the object
+	// header.
+	maxDeadLetterErrorLength = 256
+)
+
+type (
+	// DeadLetter is a notification failed to be delivered.
+	DeadLetter struct {
+		ID      string    `json:"id"`
+		Topic   string    `json:"topic"`
+		Time    time.Time `json:"time"`
+		Error   string    `json:"error"`
+		Payload []byte    `json:"-"`
+	}
+
+	// DeadLetterStore persists notifications failed to be delivered, so they
+	// can be replayed later.
+	DeadLetterStore interface {
+		// Put saves the dead letter, its ID is ignored.
+		Put(context.Context, *DeadLetter) error
+		// List returns all saved dead letters with payloads sorted by time.
+		List(context.Context) ([]*DeadLetter, error)
+		// Delete removes the dead letter by ID.
+		Delete(context.Context, string) error
+	}
+
+	// ReplayResult contains numbers of replayed dead letters and of ones
+	// failed to be delivered again.
+	ReplayResult struct {
+		Replayed int `json:"replayed"`
+		Failed   int `json:"failed"`
+	}
+
+	// NeoFSDeadLetters stores dead letters as objects of the NeoFS container.
+	// The gateway must be allowed to put, search, get and delete objects of
+	// the container.
+	NeoFSDeadLetters struct {
+		neoFS layer.NeoFS
+		cnrID cid.ID
+		owner user.ID
+	}
+)
+
+// NewNeoFSDeadLetters creates a dead letter store in the container, objects
+// are created on behalf of the owner.
+func NewNeoFSDeadLetters(neoFS layer.NeoFS, cnrID cid.ID, owner user.ID) *NeoFSDeadLetters {
+	return &NeoFSDeadLetters{neoFS: neoFS, cnrID: cnrID, owner: owner}
+}
+
+// Put implements DeadLetterStore.
+func (x *NeoFSDeadLetters) Put(ctx context.Context, letter *DeadLetter) error {
+	errMsg := letter.Error
+	if len(errMsg) > maxDeadLetterErrorLength {
+		errMsg = errMsg[:maxDeadLetterErrorLength]
+	}
+
+	_, err := x.neoFS.CreateObject(ctx, layer.PrmObjectCreate{
+		Container:    x.cnrID,
+		Creator:      x.owner,
+		CreationTime: letter.Time,
+		Attributes: [][2]string{
+			{attributeDeadLetterTopic, letter.Topic},
+			{attributeDeadLetterError, errMsg},
+		},
+		Payload: bytes.NewReader(letter.Payload),
+	})
+	if err != nil {
+		return fmt.Errorf("create dead letter object: %w", err)
+	}
+
+	return nil
+}
+
+// List implements DeadLetterStore.
+func (x *NeoFSDeadLetters) List(ctx context.Context) ([]*DeadLetter, error) {
+	ids, err := x.neoFS.SearchObjects(ctx, layer.PrmObjectSearch{
+		Container: x.cnrID,
+		Attribute: attributeDeadLetterTopic,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search dead letters: %w", err)
+	}
+
+	letters := make([]*DeadLetter, 0, len(ids))
+	for _, id := range ids {
+		obj, err := x.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+			Container:   x.cnrID,
+			Object:      id,
+			WithHeader:  true,
+			WithPayload: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read dead letter '%s': %w", id, err)
+		}
+
+		letter := &DeadLetter{
+			ID:      id.EncodeToString(),
+			Payload: obj.Head.Payload(),
+		}
+		for _, attr := range obj.Head.Attributes() {
+			switch attr.Key() {
+			case attributeDeadLetterTopic:
+				letter.Topic = attr.Value()
+			case attributeDeadLetterError:
+				letter.Error = attr.Value()
+			case object.AttributeTimestamp:
+				if unix, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+					letter.Time = time.Unix(unix, 0).UTC()
+				}
+			}
+		}
+
+		letters = append(letters, letter)
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].Time.Before(letters[j].Time)
+	})
+
+	return letters, nil
+}
+
+// Delete implements DeadLetterStore.
+func (x *NeoFSDeadLetters) Delete(ctx context.Context, id string) error {
+	var objID oid.ID
+	if err := objID.DecodeString(id); err != nil {
+		return fmt.Errorf("invalid dead letter id '%s': %w", id, err)
+	}
+
+	if err := x.neoFS.DeleteObject(ctx, layer.PrmObjectDelete{
+		Container: x.cnrID,
+		Object:    objID,
+	}); err != nil {
+		return fmt.Errorf("delete dead letter object: %w", err)
+	}
+
+	return nil
+}
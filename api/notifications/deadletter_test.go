@@ -0,0 +1,54 @@
+package notifications
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeoFSDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	neoFS := layer.NewTestNeoFS()
+	store := NewNeoFSDeadLetters(neoFS, cidtest.ID(), user.ID{})
+
+	now := time.Now().Truncate(time.Second).UTC()
+	require.NoError(t, store.Put(ctx, &DeadLetter{
+		Topic:   "second",
+		Time:    now,
+		Error:   "nats: timeout",
+		Payload: []byte("event2"),
+	}))
+	require.NoError(t, store.Put(ctx, &DeadLetter{
+		Topic:   "first",
+		Time:    now.Add(-time.Minute),
+		Error:   strings.Repeat("e", 2*maxDeadLetterErrorLength),
+		Payload: []byte("event1"),
+	}))
+
+	letters, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+
+	require.Equal(t, "first", letters[0].Topic)
+	require.Equal(t, now.Add(-time.Minute), letters[0].Time)
+	require.Equal(t, []byte("event1"), letters[0].Payload)
+	require.Len(t, letters[0].Error, maxDeadLetterErrorLength)
+
+	require.Equal(t, "second", letters[1].Topic)
+	require.Equal(t, "nats: timeout", letters[1].Error)
+	require.Equal(t, []byte("event2"), letters[1].Payload)
+
+	require.NoError(t, store.Delete(ctx, letters[0].ID))
+	letters, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	require.Equal(t, "second", letters[0].Topic)
+
+	require.Error(t, store.Delete(ctx, "invalid"))
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -47,6 +48,8 @@ const (
 
 	// Response request id.
 	hdrAmzRequestID = "x-amz-request-id"
+	// Response host id, it's the deployment id of the gateway.
+	hdrAmzID2 = "x-amz-id-2"
 
 	// hdrSSE is the general AWS SSE HTTP header key.
 	hdrSSE = "X-Amz-Server-Side-Encryption"
@@ -113,7 +116,8 @@ var s3ErrorResponseMap = map[string]string{
 func WriteErrorResponse(w http.ResponseWriter, reqInfo *ReqInfo, err error) int {
 	code := http.StatusInternalServerError
 
-	if e, ok := err.(s3errors.Error); ok {
+	var e s3errors.Error
+	if errors.As(err, &e) {
 		code = e.HTTPStatusCode
 
 		switch e.Code {
@@ -231,7 +235,8 @@ func getAPIErrorResponse(info *ReqInfo, err error) ErrorResponse {
 	code := "InternalError"
 	desc := err.Error()
 
-	if e, ok := err.(s3errors.Error); ok {
+	var e s3errors.Error
+	if errors.As(err, &e) {
 		code = e.Code
 		desc = e.Description
 	}
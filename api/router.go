@@ -2,13 +2,19 @@ package api
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/ratelimit"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
@@ -73,6 +79,11 @@ type (
 		DeleteBucketLifecycleHandler(http.ResponseWriter, *http.Request)
 		DeleteBucketEncryptionHandler(http.ResponseWriter, *http.Request)
 		DeleteBucketHandler(http.ResponseWriter, *http.Request)
+		PurgePrefixHandler(http.ResponseWriter, *http.Request)
+		ExportObjectsHandler(http.ResponseWriter, *http.Request)
+		SearchObjectsHandler(http.ResponseWriter, *http.Request)
+		CreateBatchJobHandler(http.ResponseWriter, *http.Request)
+		GetBatchJobHandler(http.ResponseWriter, *http.Request)
 		ListBucketsHandler(http.ResponseWriter, *http.Request)
 		Preflight(w http.ResponseWriter, r *http.Request)
 		AppendCORSHeaders(w http.ResponseWriter, r *http.Request)
@@ -86,6 +97,38 @@ type (
 		GetObjectTorrentHandler(http.ResponseWriter, *http.Request)
 		PutPublicAccessBlockHandler(http.ResponseWriter, *http.Request)
 		GetPublicAccessBlockHandler(http.ResponseWriter, *http.Request)
+		GetBucketMetricsConfigurationHandler(http.ResponseWriter, *http.Request)
+		ListBucketMetricsConfigurationsHandler(http.ResponseWriter, *http.Request)
+		PutBucketMetricsConfigurationHandler(http.ResponseWriter, *http.Request)
+		DeleteBucketMetricsConfigurationHandler(http.ResponseWriter, *http.Request)
+		PutBucketUploadConstraintsHandler(http.ResponseWriter, *http.Request)
+		GetBucketUploadConstraintsHandler(http.ResponseWriter, *http.Request)
+		DeleteBucketUploadConstraintsHandler(http.ResponseWriter, *http.Request)
+		PutBucketAnonymousAccessHandler(http.ResponseWriter, *http.Request)
+		GetBucketAnonymousAccessHandler(http.ResponseWriter, *http.Request)
+		DeleteBucketAnonymousAccessHandler(http.ResponseWriter, *http.Request)
+		PutBucketDeletionHandler(http.ResponseWriter, *http.Request)
+		GetBucketDeletionHandler(http.ResponseWriter, *http.Request)
+		DeleteBucketDeletionHandler(http.ResponseWriter, *http.Request)
+		UndeleteObjectHandler(http.ResponseWriter, *http.Request)
+		PutBucketPrivacyHandler(http.ResponseWriter, *http.Request)
+		GetBucketPrivacyHandler(http.ResponseWriter, *http.Request)
+		DeleteBucketPrivacyHandler(http.ResponseWriter, *http.Request)
+		CheckAnonymousListing(*http.Request) error
+		CheckBucketWritable(*http.Request) error
+
+		CapabilitiesHandler(http.ResponseWriter, *http.Request)
+	}
+
+	// Domains are base domains the gateway is reachable at.
+	Domains struct {
+		// VirtualHosted are domains with buckets addressed as their
+		// subdomains. The most specific domain is matched first.
+		VirtualHosted []string
+		// PathStyle are domains served with path-style requests only, their
+		// subdomains aren't treated as buckets even if they are subdomains of
+		// a virtual-hosted one.
+		PathStyle []string
 	}
 
 	// mimeType represents various MIME types used in API responses.
@@ -108,6 +151,13 @@ const (
 
 	// MimeXML means response type is XML.
 	MimeXML mimeType = "application/xml"
+
+	// MimeJSON means response type is JSON.
+	MimeJSON mimeType = "application/json"
+
+	// CapabilitiesPath is the path of gateway capabilities discovery endpoint.
+	// Bucket can't be named "-", so it never clashes with path-style requests.
+	CapabilitiesPath = "/-/capabilities"
 )
 
 var _ = logErrorResponse
@@ -126,6 +176,7 @@ func setRequestID(h http.Handler) http.Handler {
 
 		// set request id into response header
 		w.Header().Set(hdrAmzRequestID, id.String())
+		w.Header().Set(hdrAmzID2, deploymentID.String())
 
 		// set request id into gRPC meta header
 		r = r.WithContext(metadata.AppendToOutgoingContext(
@@ -140,6 +191,38 @@ func setRequestID(h http.Handler) http.Handler {
 	})
 }
 
+// setRequestDeadline bounds the request context with the client timeout hint.
+// The hint can only shorten the time spent on a request, invalid values are
+// ignored.
+func setRequestDeadline(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if timeout := parseRequestTimeout(r.Header.Get(RequestTimeout)); timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func parseRequestTimeout(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+
+	return timeout
+}
+
 func appendCORS(handler Handler) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -149,6 +232,53 @@ func appendCORS(handler Handler) mux.MiddlewareFunc {
 	}
 }
 
+// listingRoutes are names of routes listing bucket contents.
+var listingRoutes = map[string]struct{}{
+	"ListObjectsV1":        {},
+	"ListObjectsV2":        {},
+	"ListObjectsV2M":       {},
+	"ListBucketVersions":   {},
+	"ListMultipartUploads": {},
+	"ListObjectParts":      {},
+	"ExportObjects":        {},
+}
+
+// checkAnonymousListing rejects anonymous listing of buckets where it's disabled.
+func checkAnonymousListing(handler Handler) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsAnonymousRequest(r.Context()) {
+				if route := mux.CurrentRoute(r); route != nil {
+					if _, ok := listingRoutes[route.GetName()]; ok {
+						if err := handler.CheckAnonymousListing(r); err != nil {
+							WriteErrorResponse(w, GetReqInfo(r.Context()), err)
+							return
+						}
+					}
+				}
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkBucketWritable rejects requests changing buckets which settings are
+// written by a newer gateway version.
+func checkBucketWritable(handler Handler) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPut, http.MethodPost, http.MethodDelete:
+				if err := handler.CheckBucketWritable(r); err != nil {
+					WriteErrorResponse(w, GetReqInfo(r.Context()), err)
+					return
+				}
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
 func logErrorResponse(l *zap.Logger) mux.MiddlewareFunc {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -187,27 +317,52 @@ func GetRequestID(v any) string {
 	}
 }
 
-// Attach adds S3 API handlers from h to r for domains with m client limit using
-// center authentication and log logger.
-func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center auth.Center, log *zap.Logger) {
+// Attach adds S3 API handlers from h to r for domains with m client limit and
+// request limits using center authentication, anon restrictions of anonymous
+// requests, limiter of access key request rates and log logger. Requests are rejected while storage
+// reports all nodes unhealthy.
+func Attach(r *mux.Router, domains Domains, m MaxClients, limits RequestLimits, h Handler, center auth.Center, anon AnonymousAccess, limiter *ratelimit.Limiter, storage StorageState, log *zap.Logger) {
+	// capabilities are public, so they're attached before authentication
+	r.Methods(http.MethodGet).Path(CapabilitiesPath).MatcherFunc(notBucketHost(domains)).HandlerFunc(
+		m.Handle(metrics.APIStats("capabilities", h.CapabilitiesHandler))).Name("Capabilities")
+	// readiness isn't limited, so load balancers can check it under load
+	r.Methods(http.MethodGet).Path(ReadinessPath).MatcherFunc(notBucketHost(domains)).HandlerFunc(
+		readinessHandler(storage)).Name("Readiness")
+
 	api := r.PathPrefix(SlashSeparator).Subrouter()
 
 	api.Use(
 		// -- prepare request
 		setRequestID,
+		setRequestDeadline,
 
 		// -- logging error requests
 		logErrorResponse(log),
+
+		// -- reject abusive requests
+		checkRequestLimits(limits),
+
+		// -- fail fast if storage is down
+		rejectIfStorageDown(storage),
+
+		// -- keep the bucket of virtual-hosted-style request for authentication
+		setVirtualHostedBucket(domains),
 	)
 
 	// Attach user authentication for all S3 routes.
-	AttachUserAuth(api, center, log)
+	AttachUserAuth(api, center, anon, limiter, log)
 
-	buckets := make([]*mux.Router, 0, len(domains)+1)
-	buckets = append(buckets, api.PathPrefix("/{bucket}").Subrouter())
+	virtualHosted := make([]string, len(domains.VirtualHosted))
+	copy(virtualHosted, domains.VirtualHosted)
+	sort.SliceStable(virtualHosted, func(i, j int) bool {
+		return len(virtualHosted[i]) > len(virtualHosted[j])
+	})
+
+	buckets := make([]*mux.Router, 0, len(virtualHosted)+1)
+	buckets = append(buckets, api.MatcherFunc(notBucketHost(domains)).PathPrefix("/{bucket}").Subrouter())
 
-	for _, domain := range domains {
-		buckets = append(buckets, api.Host("{bucket:.+}."+domain).Subrouter())
+	for _, domain := range virtualHosted {
+		buckets = append(buckets, api.Host("{bucket:.+}."+domain).MatcherFunc(isBucketHost(domains)).Subrouter())
 	}
 
 	for _, bucket := range buckets {
@@ -216,6 +371,12 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Use(
 			// -- append CORS headers to a response for
 			appendCORS(h),
+
+			// -- deny anonymous listing if it's disabled for the bucket
+			checkAnonymousListing(h),
+
+			// -- deny changes of buckets with unsupported settings
+			checkBucketWritable(h),
 		)
 		bucket.Methods(http.MethodOptions).HandlerFunc(m.Handle(metrics.APIStats("preflight", h.Preflight))).Name("Options")
 		bucket.Methods(http.MethodHead).Path("/{object:.+}").HandlerFunc(
@@ -235,6 +396,10 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
 			m.Handle(metrics.APIStats("completemutipartupload", h.CompleteMultipartUploadHandler))).Queries("uploadId", "{uploadId:.*}").
 			Name("CompleteMultipartUpload")
+		// UndeleteObject -- this is an extension.
+		bucket.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
+			m.Handle(metrics.APIStats("undeleteobject", h.UndeleteObjectHandler))).Queries("undelete", "").
+			Name("UndeleteObject")
 		// CreateMultipartUpload
 		bucket.Methods(http.MethodPost).Path("/{object:.+}").HandlerFunc(
 			m.Handle(metrics.APIStats("createmultipartupload", h.CreateMultipartUploadHandler))).Queries("uploads", "").
@@ -423,6 +588,42 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("getpublicaccessblock", h.GetPublicAccessBlockHandler))).Queries("publicAccessBlock", "").
 			Name("GetPublicAccessBlock")
+		// GetBucketMetricsConfiguration
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketmetricsconfiguration", h.GetBucketMetricsConfigurationHandler))).Queries("metrics", "", "id", "{id:.*}").
+			Name("GetBucketMetricsConfiguration")
+		// ListBucketMetricsConfigurations
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("listbucketmetricsconfigurations", h.ListBucketMetricsConfigurationsHandler))).Queries("metrics", "").
+			Name("ListBucketMetricsConfigurations")
+		// GetBucketUploadConstraints
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketuploadconstraints", h.GetBucketUploadConstraintsHandler))).Queries("upload-constraints", "").
+			Name("GetBucketUploadConstraints")
+		// GetBucketAnonymousAccess
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketanonymousaccess", h.GetBucketAnonymousAccessHandler))).Queries("anonymous-access", "").
+			Name("GetBucketAnonymousAccess")
+		// GetBucketDeletion
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketdeletion", h.GetBucketDeletionHandler))).Queries("deletion", "").
+			Name("GetBucketDeletion")
+		// GetBucketPrivacy
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketprivacy", h.GetBucketPrivacyHandler))).Queries("privacy", "").
+			Name("GetBucketPrivacy")
+		// ExportObjects -- this is an extension.
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("exportobjects", h.ExportObjectsHandler))).Queries("export", "").
+			Name("ExportObjects")
+		// SearchObjects -- this is an extension.
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("searchobjects", h.SearchObjectsHandler))).Queries("search", "").
+			Name("SearchObjects")
+		// GetBatchJob -- this is an extension.
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbatchjob", h.GetBatchJobHandler))).Queries("batch", "").
+			Name("GetBatchJob")
 		// ListObjectsV1 (Legacy)
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("listobjectsv1", h.ListObjectsV1Handler))).
@@ -436,6 +637,28 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 			m.Handle(metrics.APIStats("putbucketencryption", h.PutBucketEncryptionHandler))).Queries("encryption", "").
 			Name("PutBucketEncryption")
 
+		// PutBucketMetricsConfiguration -- this is a dummy call.
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketmetricsconfiguration", h.PutBucketMetricsConfigurationHandler))).Queries("metrics", "").
+			Name("PutBucketMetricsConfiguration")
+
+		// PutBucketUploadConstraints
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketuploadconstraints", h.PutBucketUploadConstraintsHandler))).Queries("upload-constraints", "").
+			Name("PutBucketUploadConstraints")
+		// PutBucketAnonymousAccess
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketanonymousaccess", h.PutBucketAnonymousAccessHandler))).Queries("anonymous-access", "").
+			Name("PutBucketAnonymousAccess")
+		// PutBucketDeletion
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketdeletion", h.PutBucketDeletionHandler))).Queries("deletion", "").
+			Name("PutBucketDeletion")
+		// PutBucketPrivacy
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketprivacy", h.PutBucketPrivacyHandler))).Queries("privacy", "").
+			Name("PutBucketPrivacy")
+
 		// PutBucketPolicy
 		bucket.Methods(http.MethodPut).HandlerFunc(
 			m.Handle(metrics.APIStats("putbucketpolicy", h.PutBucketPolicyHandler))).Queries("policy", "").
@@ -473,6 +696,30 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodPost).HandlerFunc(
 			m.Handle(metrics.APIStats("deletemultipleobjects", h.DeleteMultipleObjectsHandler))).Queries("delete", "").
 			Name("DeleteMultipleObjects")
+		// CreateBatchJob -- this is an extension.
+		bucket.Methods(http.MethodPost).HandlerFunc(
+			m.Handle(metrics.APIStats("createbatchjob", h.CreateBatchJobHandler))).Queries("batch", "").
+			Name("CreateBatchJob")
+		// DeleteBucketMetricsConfiguration -- this is a dummy call.
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("deletebucketmetricsconfiguration", h.DeleteBucketMetricsConfigurationHandler))).Queries("metrics", "").
+			Name("DeleteBucketMetricsConfiguration")
+		// DeleteBucketUploadConstraints
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("deletebucketuploadconstraints", h.DeleteBucketUploadConstraintsHandler))).Queries("upload-constraints", "").
+			Name("DeleteBucketUploadConstraints")
+		// DeleteBucketAnonymousAccess
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("deletebucketanonymousaccess", h.DeleteBucketAnonymousAccessHandler))).Queries("anonymous-access", "").
+			Name("DeleteBucketAnonymousAccess")
+		// DeleteBucketDeletion
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("deletebucketdeletion", h.DeleteBucketDeletionHandler))).Queries("deletion", "").
+			Name("DeleteBucketDeletion")
+		// DeleteBucketPrivacy
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("deletebucketprivacy", h.DeleteBucketPrivacyHandler))).Queries("privacy", "").
+			Name("DeleteBucketPrivacy")
 		// DeleteBucketPolicy
 		bucket.Methods(http.MethodDelete).HandlerFunc(
 			m.Handle(metrics.APIStats("deletebucketpolicy", h.DeleteBucketPolicyHandler))).Queries("policy", "").
@@ -485,6 +732,10 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodDelete).HandlerFunc(
 			m.Handle(metrics.APIStats("deletebucketencryption", h.DeleteBucketEncryptionHandler))).Queries("encryption", "").
 			Name("DeleteBucketEncryption")
+		// PurgePrefix -- this is an extension.
+		bucket.Methods(http.MethodDelete).HandlerFunc(
+			m.Handle(metrics.APIStats("purgeprefix", h.PurgePrefixHandler))).Queries("purge", "").
+			Name("PurgePrefix")
 		// DeleteBucket
 		bucket.Methods(http.MethodDelete).HandlerFunc(
 			m.Handle(metrics.APIStats("deletebucket", h.DeleteBucketHandler))).
@@ -507,3 +758,77 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 	api.NotFoundHandler = metrics.APIStats("notfound", errorResponseHandler)
 	api.MethodNotAllowedHandler = metrics.APIStats("methodnotallowed", errorResponseHandler)
 }
+
+// notBucketHost matches requests which aren't virtual-hosted-style ones, so
+// objects of such buckets aren't shadowed by gateway endpoints.
+func notBucketHost(domains Domains) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return !domains.isBucketHost(r.Host)
+	}
+}
+
+// isBucketHost matches virtual-hosted-style requests.
+func isBucketHost(domains Domains) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		return domains.isBucketHost(r.Host)
+	}
+}
+
+// setVirtualHostedBucket puts the bucket of virtual-hosted-style request to
+// the context, AWS Signature Version 2 signs it as a part of the path.
+func setVirtualHostedBucket(domains Domains) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if bucket := domains.virtualHostedBucket(r.Host); bucket != "" {
+				r = r.WithContext(auth.WithVirtualHostedBucket(r.Context(), bucket))
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// virtualHostedBucket returns the bucket of virtual-hosted-style request to
+// the host, the most specific domain is matched.
+func (d Domains) virtualHostedBucket(host string) string {
+	if !d.isBucketHost(host) {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	var bucket string
+	for _, domain := range d.VirtualHosted {
+		if b := strings.TrimSuffix(host, "."+domain); b != host && (bucket == "" || len(b) < len(bucket)) {
+			bucket = b
+		}
+	}
+
+	return bucket
+}
+
+// isBucketHost checks if the host is a subdomain of some virtual-hosted
+// domain and isn't served in path-style only.
+func (d Domains) isBucketHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	for _, domain := range d.PathStyle {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+
+	var res bool
+	for _, domain := range d.VirtualHosted {
+		if host == domain {
+			// Base domain is served in path-style even if it's a subdomain
+			// of another one.
+			return false
+		}
+		res = res || strings.HasSuffix(host, "."+domain)
+	}
+
+	return res
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainsBucketHost(t *testing.T) {
+	domains := Domains{
+		VirtualHosted: []string{"s3.example.com", "eu.s3.example.com"},
+		PathStyle:     []string{"internal.s3.example.com"},
+	}
+
+	for _, tc := range []struct {
+		host   string
+		bucket string
+	}{
+		// base domains are served in path-style
+		{host: "s3.example.com"},
+		{host: "s3.example.com:8080"},
+		{host: "eu.s3.example.com"},
+		{host: "bucket.s3.example.com", bucket: "bucket"},
+		{host: "bucket.s3.example.com:8080", bucket: "bucket"},
+		{host: "my.dotted.bucket.s3.example.com", bucket: "my.dotted.bucket"},
+		// the most specific domain is matched
+		{host: "bucket.eu.s3.example.com", bucket: "bucket"},
+		// path-style domains and their subdomains aren't buckets
+		{host: "internal.s3.example.com"},
+		{host: "bucket.internal.s3.example.com"},
+		{host: "bucket.example.com"},
+		{host: "bucket.nots3.example.com"},
+		{host: "localhost:8080"},
+		{host: "127.0.0.1:8080"},
+	} {
+		t.Run(tc.host, func(t *testing.T) {
+			require.Equal(t, tc.bucket != "", domains.isBucketHost(tc.host))
+			require.Equal(t, tc.bucket, domains.virtualHostedBucket(tc.host))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tc.host
+			require.Equal(t, tc.bucket != "", isBucketHost(domains)(r, nil))
+			require.Equal(t, tc.bucket == "", notBucketHost(domains)(r, nil))
+		})
+	}
+
+	// without virtual-hosted domains every request is path-style
+	require.False(t, Domains{}.isBucketHost("bucket.s3.example.com"))
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"":           0,
+		"10":         10 * time.Second,
+		"0":          0,
+		"1.5s":       1500 * time.Millisecond,
+		"2m":         2 * time.Minute,
+		"-1":         0,
+		"-1s":        0,
+		"10x":        0,
+		"often":      0,
+		"4294967296": 0,
+	} {
+		require.Equal(t, expected, parseRequestTimeout(value), value)
+	}
+}
+
+func TestSetRequestDeadline(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		hint    string
+		shorter bool
+	}{
+		{name: "no hint"},
+		{name: "invalid hint", hint: "soon"},
+		{name: "zero hint", hint: "0"},
+		{name: "longer hint", hint: "1h"},
+		{name: "shorter hint", hint: "1", shorter: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			parent, _ := ctx.Deadline()
+
+			var deadline time.Time
+			h := setRequestDeadline(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				var ok bool
+				deadline, ok = r.Context().Deadline()
+				require.True(t, ok)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			if tc.hint != "" {
+				r.Header.Set(RequestTimeout, tc.hint)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if tc.shorter {
+				require.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+			} else {
+				require.Equal(t, parent, deadline)
+			}
+		})
+	}
+}
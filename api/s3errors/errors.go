@@ -62,8 +62,10 @@ const (
 	ErrNoSuchLifecycleConfiguration
 	ErrNoSuchBucketSSEConfig
 	ErrNoSuchCORSConfiguration
+	ErrNoSuchConfiguration
 	ErrNoSuchWebsiteConfiguration
 	ErrReplicationConfigurationNotFoundError
+	ErrNoSuchPublicAccessBlockConfiguration
 	ErrNoSuchKey
 	ErrNoSuchUpload
 	ErrNoSuchVersion
@@ -119,10 +121,14 @@ const (
 	ErrInvalidPrefixMarker
 	ErrBadRequest
 	ErrKeyTooLongError
+	ErrRequestHeaderSectionTooLarge
 	ErrInvalidBucketObjectLockConfiguration
 	ErrObjectLockConfigurationNotFound
 	ErrObjectLockConfigurationNotAllowed
 	ErrObjectLockConfigurationVersioningCannotBeChanged
+	ErrBucketSettingsSchemaUnsupported
+	ErrOperationAborted
+	ErrExpiredToken
 	ErrNoSuchObjectLockConfiguration
 	ErrObjectLocked
 	ErrInvalidRetentionDate
@@ -170,11 +176,21 @@ const (
 
 	// S3 extended errors.
 	ErrContentSHA256Mismatch
+	ErrChecksumMismatch
+	ErrMalformedTrailer
 
 	// Add new extended error codes here.
 	ErrInvalidObjectName
 	ErrOperationTimedOut
 	ErrOperationMaxedOut
+	ErrStorageUnavailable
+	ErrContentTypeNotAllowed
+	ErrObjectInfected
+	ErrScanUnavailable
+	ErrUploadNotAdmitted
+	ErrAdmissionUnavailable
+	ErrNoSuchBatchJob
+	ErrTooManyBatchJobs
 	ErrInvalidRequest
 	ErrInvalidStorageClass
 
@@ -593,7 +609,7 @@ var errorCodes = errorCodeMap{
 	ErrAuthorizationHeaderMalformed: {
 		ErrCode:        ErrAuthorizationHeaderMalformed,
 		Code:           "AuthorizationHeaderMalformed",
-		Description:    "The authorization header is malformed; the region is wrong; expecting 'us-east-1'.",
+		Description:    "The authorization header is malformed; the credential scope is wrong.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
 	ErrMalformedPOSTRequest: {
@@ -782,6 +798,12 @@ var errorCodes = errorCodeMap{
 		Description:    "Your key is too long",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrRequestHeaderSectionTooLarge: {
+		ErrCode:        ErrRequestHeaderSectionTooLarge,
+		Code:           "RequestHeaderSectionTooLarge",
+		Description:    "Your request header section exceeds the maximum allowed size.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 
 	// FIXME: Actual XML error response also contains the header which is missed in the list of signed header parameters.
 	ErrUnsignedHeaders: {
@@ -838,12 +860,36 @@ var errorCodes = errorCodeMap{
 		Description:    "An Object Lock configuration is present on this bucket, so the versioning state cannot be changed.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrBucketSettingsSchemaUnsupported: {
+		ErrCode:        ErrBucketSettingsSchemaUnsupported,
+		Code:           "InvalidBucketState",
+		Description:    "Bucket settings are written by a newer gateway version, the bucket is read-only.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrOperationAborted: {
+		ErrCode:        ErrOperationAborted,
+		Code:           "OperationAborted",
+		Description:    "A conflicting conditional operation is currently in progress against this resource. Try again.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrExpiredToken: {
+		ErrCode:        ErrExpiredToken,
+		Code:           "ExpiredToken",
+		Description:    "The provided token has expired.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 	ErrNoSuchCORSConfiguration: {
 		ErrCode:        ErrNoSuchCORSConfiguration,
 		Code:           "NoSuchCORSConfiguration",
 		Description:    "The CORS configuration does not exist",
 		HTTPStatusCode: http.StatusNotFound,
 	},
+	ErrNoSuchConfiguration: {
+		ErrCode:        ErrNoSuchConfiguration,
+		Code:           "NoSuchConfiguration",
+		Description:    "The specified configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
 	ErrNoSuchWebsiteConfiguration: {
 		ErrCode:        ErrNoSuchWebsiteConfiguration,
 		Code:           "NoSuchWebsiteConfiguration",
@@ -856,6 +902,12 @@ var errorCodes = errorCodeMap{
 		Description:    "The replication configuration was not found",
 		HTTPStatusCode: http.StatusNotFound,
 	},
+	ErrNoSuchPublicAccessBlockConfiguration: {
+		ErrCode:        ErrNoSuchPublicAccessBlockConfiguration,
+		Code:           "NoSuchPublicAccessBlockConfiguration",
+		Description:    "The public access block configuration was not found",
+		HTTPStatusCode: http.StatusNotFound,
+	},
 	ErrNoSuchObjectLockConfiguration: {
 		ErrCode:        ErrNoSuchObjectLockConfiguration,
 		Code:           "NoSuchObjectLockConfiguration",
@@ -973,7 +1025,7 @@ var errorCodes = errorCodeMap{
 	},
 	ErrMetadataTooLarge: {
 		ErrCode:        ErrMetadataTooLarge,
-		Code:           "InvalidArgument",
+		Code:           "MetadataTooLarge",
 		Description:    "Your metadata headers exceed the maximum allowed metadata size.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
@@ -1087,6 +1139,18 @@ var errorCodes = errorCodeMap{
 	},
 
 	// S3 extensions.
+	ErrChecksumMismatch: {
+		ErrCode:        ErrChecksumMismatch,
+		Code:           "BadDigest",
+		Description:    "The checksum you specified did not match the calculated checksum.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrMalformedTrailer: {
+		ErrCode:        ErrMalformedTrailer,
+		Code:           "MalformedTrailerError",
+		Description:    "The request contained trailing data that was not well-formed or did not conform to our published schema.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrContentSHA256Mismatch: {
 		ErrCode:        ErrContentSHA256Mismatch,
 		Code:           "XAmzContentSHA256Mismatch",
@@ -1123,6 +1187,54 @@ var errorCodes = errorCodeMap{
 		Description:    "A timeout exceeded while waiting to proceed with the request, please reduce your request rate",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrStorageUnavailable: {
+		ErrCode:        ErrStorageUnavailable,
+		Code:           "ServiceUnavailable",
+		Description:    "All storage nodes are unavailable. Please retry with another endpoint.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrContentTypeNotAllowed: {
+		ErrCode:        ErrContentTypeNotAllowed,
+		Code:           "InvalidArgument",
+		Description:    "Content type of the object isn't allowed in the bucket.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrObjectInfected: {
+		ErrCode:        ErrObjectInfected,
+		Code:           "AccessDenied",
+		Description:    "The object payload was rejected by the content scanner.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrScanUnavailable: {
+		ErrCode:        ErrScanUnavailable,
+		Code:           "ServiceUnavailable",
+		Description:    "The object payload can't be scanned now. Please retry later.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrUploadNotAdmitted: {
+		ErrCode:        ErrUploadNotAdmitted,
+		Code:           "AccessDenied",
+		Description:    "The upload was rejected by the bucket validation webhook.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrAdmissionUnavailable: {
+		ErrCode:        ErrAdmissionUnavailable,
+		Code:           "ServiceUnavailable",
+		Description:    "The upload can't be validated now. Please retry later.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
+	ErrNoSuchBatchJob: {
+		ErrCode:        ErrNoSuchBatchJob,
+		Code:           "NoSuchJob",
+		Description:    "The specified batch job does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrTooManyBatchJobs: {
+		ErrCode:        ErrTooManyBatchJobs,
+		Code:           "TooManyRequests",
+		Description:    "Too many batch jobs are pending. Please retry later.",
+		HTTPStatusCode: http.StatusTooManyRequests,
+	},
 	ErrUnsupportedMetadata: {
 		ErrCode:        ErrUnsupportedMetadata,
 		Code:           "InvalidArgument",
@@ -0,0 +1,292 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/api/signing"
+)
+
+const (
+	// DefaultTimeout is a default timeout of a single scan.
+	DefaultTimeout = 30 * time.Second
+
+	// HeaderBucket is a webhook request header with the bucket name.
+	HeaderBucket = "X-Scan-Bucket"
+	// HeaderKey is a webhook request header with the URL-encoded object key.
+	HeaderKey = "X-Scan-Key"
+	// HeaderContentType is a webhook request header with the object content type.
+	HeaderContentType = "X-Scan-Content-Type"
+
+	defaultICAPPort = "1344"
+)
+
+type (
+	// Options are scanner parameters.
+	Options struct {
+		// URL is a webhook (http or https scheme) or ICAP service (icap
+		// scheme) URL.
+		URL     string
+		Timeout time.Duration
+		// Signer signs webhook requests, it's optional and isn't supported
+		// by ICAP.
+		Signer signing.RequestSigner
+	}
+
+	// Webhook scans payloads by sending them in the body of POST requests,
+	// the server responds with WebhookResponse in JSON.
+	Webhook struct {
+		url    string
+		client *http.Client
+		signer signing.RequestSigner
+	}
+
+	// WebhookResponse is a verdict of the webhook.
+	WebhookResponse struct {
+		Infected bool   `json:"infected"`
+		Threat   string `json:"threat,omitempty"`
+	}
+
+	// ICAP scans payloads with RESPMOD requests to the ICAP service (RFC 3507).
+	ICAP struct {
+		addr    string
+		host    string
+		uri     string
+		timeout time.Duration
+	}
+)
+
+// New creates the scanner choosing the protocol by URL scheme.
+func New(p *Options) (handler.Scanner, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &Webhook{url: p.URL, client: &http.Client{Timeout: timeout}, signer: p.Signer}, nil
+	case "icap":
+		if p.Signer != nil {
+			return nil, errors.New("signing isn't supported for icap scanner")
+		}
+		addr := u.Host
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), defaultICAPPort)
+		}
+		return &ICAP{addr: addr, host: u.Host, uri: p.URL, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scanner url scheme '%s'", u.Scheme)
+	}
+}
+
+// Scan implements handler.Scanner.
+func (x *Webhook) Scan(ctx context.Context, obj handler.ScanObject, payload io.Reader) (*handler.ScanResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.url, payload)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = obj.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(HeaderBucket, obj.Bucket)
+	req.Header.Set(HeaderKey, url.PathEscape(obj.Key))
+	if obj.ContentType != "" {
+		req.Header.Set(HeaderContentType, obj.ContentType)
+	}
+	if x.signer != nil {
+		if err = x.signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected webhook response status: %s", resp.Status)
+	}
+
+	var res WebhookResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("decode webhook response: %w", err)
+	}
+
+	return &handler.ScanResult{Infected: res.Infected, Threat: res.Threat}, nil
+}
+
+// Scan implements handler.Scanner. The service responds with 204 status if
+// the payload is clean. Otherwise, the payload is infected if the response
+// contains X-Infection-Found or X-Virus-ID headers or the encapsulated HTTP
+// response isn't successful.
+func (x *ICAP) Scan(ctx context.Context, obj handler.ScanObject, payload io.Reader) (*handler.ScanResult, error) {
+	deadline := time.Now().Add(x.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", x.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial icap service: %w", err)
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("set deadline: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	if err = x.writeRequest(conn, obj, payload); err != nil {
+		return nil, err
+	}
+
+	res, err := readICAPResponse(bufio.NewReader(conn))
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return res, err
+}
+
+func (x *ICAP) writeRequest(conn net.Conn, obj handler.ScanObject, payload io.Reader) error {
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	resHdr := "HTTP/1.1 200 OK\r\nContent-Type: " + contentType + "\r\n"
+	if obj.Size >= 0 {
+		resHdr += "Content-Length: " + strconv.FormatInt(obj.Size, 10) + "\r\n"
+	}
+	resHdr += "\r\n"
+
+	w := bufio.NewWriter(conn)
+	_, _ = w.WriteString("RESPMOD " + x.uri + " ICAP/1.0\r\n" +
+		"Host: " + x.host + "\r\n" +
+		"Allow: 204\r\n" +
+		"Encapsulated: res-hdr=0, res-body=" + strconv.Itoa(len(resHdr)) + "\r\n\r\n" +
+		resHdr)
+
+	cw := httputil.NewChunkedWriter(w)
+	if _, err := io.Copy(cw, payload); err != nil {
+		return fmt.Errorf("send payload: %w", err)
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("send payload: %w", err)
+	}
+	_, _ = w.WriteString("\r\n")
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("send payload: %w", err)
+	}
+
+	return nil
+}
+
+func readICAPResponse(r *bufio.Reader) (*handler.ScanResult, error) {
+	tp := textproto.NewReader(r)
+
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("read icap response: %w", err)
+	}
+
+	code, err := parseStatusLine(line, "ICAP/")
+	if err != nil {
+		return nil, err
+	}
+
+	hdr, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read icap response headers: %w", err)
+	}
+
+	switch code {
+	case http.StatusNoContent:
+		return &handler.ScanResult{}, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("unexpected icap response status: %s", line)
+	}
+
+	if threat, ok := icapThreat(hdr); ok {
+		return &handler.ScanResult{Infected: true, Threat: threat}, nil
+	}
+
+	if !strings.Contains(hdr.Get("Encapsulated"), "res-hdr") {
+		return &handler.ScanResult{}, nil
+	}
+
+	// Service modified the response, it replaces infected content with an
+	// error page usually.
+	if line, err = tp.ReadLine(); err != nil {
+		return nil, fmt.Errorf("read encapsulated response: %w", err)
+	}
+	if code, err = parseStatusLine(line, "HTTP/"); err != nil {
+		return nil, err
+	}
+
+	return &handler.ScanResult{Infected: code < 200 || code > 299}, nil
+}
+
+func icapThreat(hdr textproto.MIMEHeader) (string, bool) {
+	if found := hdr.Get("X-Infection-Found"); found != "" {
+		for _, param := range strings.Split(found, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "Threat") {
+				return value, true
+			}
+		}
+		return "", true
+	}
+
+	if virus := hdr.Get("X-Virus-ID"); virus != "" {
+		return virus, true
+	}
+
+	return "", false
+}
+
+func parseStatusLine(line, proto string) (int, error) {
+	version, status, ok := strings.Cut(line, " ")
+	if !ok || !strings.HasPrefix(version, proto) {
+		return 0, fmt.Errorf("malformed status line: '%s'", line)
+	}
+
+	codeStr, _, _ := strings.Cut(status, " ")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return 0, fmt.Errorf("malformed status line: '%s'", line)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,195 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/api/signing"
+	"github.com/stretchr/testify/require"
+)
+
+const eicar = "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"
+
+func TestWebhook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "bucket", r.Header.Get(HeaderBucket))
+		require.Equal(t, "dir%2Fobj%20name", r.Header.Get(HeaderKey))
+		require.Equal(t, "text/plain", r.Header.Get(HeaderContentType))
+
+		payload, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		res := WebhookResponse{}
+		if strings.Contains(string(payload), "EICAR") {
+			res = WebhookResponse{Infected: true, Threat: "Eicar-Test-Signature"}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer srv.Close()
+
+	s, err := New(&Options{URL: srv.URL})
+	require.NoError(t, err)
+
+	obj := handler.ScanObject{Bucket: "bucket", Key: "dir/obj name", ContentType: "text/plain", Size: int64(len(eicar))}
+	res, err := s.Scan(context.Background(), obj, strings.NewReader(eicar))
+	require.NoError(t, err)
+	require.Equal(t, &handler.ScanResult{Infected: true, Threat: "Eicar-Test-Signature"}, res)
+
+	obj.Size = 5
+	res, err = s.Scan(context.Background(), obj, strings.NewReader("clean"))
+	require.NoError(t, err)
+	require.False(t, res.Infected)
+}
+
+// serveICAP accepts a single connection, checks the request and sends the
+// response.
+func serveICAP(t *testing.T, response string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		tp := textproto.NewReader(r)
+
+		line, err := tp.ReadLine()
+		if err != nil || !strings.HasPrefix(line, "RESPMOD icap://") {
+			return
+		}
+		hdr, err := tp.ReadMIMEHeader()
+		if err != nil || hdr.Get("Encapsulated") == "" {
+			return
+		}
+		if _, err = tp.ReadLine(); err != nil { // encapsulated response status
+			return
+		}
+		if _, err = tp.ReadMIMEHeader(); err != nil {
+			return
+		}
+		if _, err = io.ReadAll(httputil.NewChunkedReader(r)); err != nil {
+			return
+		}
+		if _, err = tp.ReadLine(); err != nil {
+			return
+		}
+
+		_, _ = conn.Write([]byte(response))
+	}()
+
+	return "icap://" + l.Addr().String() + "/avscan"
+}
+
+func TestICAP(t *testing.T) {
+	obj := handler.ScanObject{Bucket: "bucket", Key: "obj", Size: int64(len(eicar))}
+
+	for _, tc := range []struct {
+		name     string
+		response string
+		result   *handler.ScanResult
+		err      bool
+	}{
+		{
+			name:     "clean",
+			response: "ICAP/1.0 204 No Content\r\nISTag: \"test\"\r\n\r\n",
+			result:   &handler.ScanResult{},
+		},
+		{
+			name: "infection header",
+			response: "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n" +
+				"Encapsulated: res-hdr=0, res-body=19\r\n\r\nHTTP/1.1 403 Forbidden\r\n\r\n0\r\n\r\n",
+			result: &handler.ScanResult{Infected: true, Threat: "Eicar-Test-Signature"},
+		},
+		{
+			name:     "virus id header",
+			response: "ICAP/1.0 200 OK\r\nX-Virus-ID: Eicar-Test-Signature\r\nEncapsulated: null-body=0\r\n\r\n",
+			result:   &handler.ScanResult{Infected: true, Threat: "Eicar-Test-Signature"},
+		},
+		{
+			name:     "blocked response",
+			response: "ICAP/1.0 200 OK\r\nEncapsulated: res-hdr=0, res-body=19\r\n\r\nHTTP/1.1 403 Forbidden\r\n\r\n0\r\n\r\n",
+			result:   &handler.ScanResult{Infected: true},
+		},
+		{
+			name:     "unmodified response",
+			response: "ICAP/1.0 200 OK\r\nEncapsulated: res-hdr=0, res-body=19\r\n\r\nHTTP/1.1 200 OK\r\n\r\n0\r\n\r\n",
+			result:   &handler.ScanResult{},
+		},
+		{
+			name:     "service error",
+			response: "ICAP/1.0 500 Server Error\r\n\r\n",
+			err:      true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(&Options{URL: serveICAP(t, tc.response)})
+			require.NoError(t, err)
+
+			res, err := s.Scan(context.Background(), obj, strings.NewReader(eicar))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.result, res)
+		})
+	}
+}
+
+func TestWebhookSigning(t *testing.T) {
+	verifier, err := signing.NewHMAC("secret")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp := r.Header.Get(signing.HeaderTimestamp)
+		if r.Header.Get(signing.HeaderSignature) != verifier.Signature(signing.RequestStringToSign(r, timestamp)) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(WebhookResponse{}))
+	}))
+	defer srv.Close()
+
+	obj := handler.ScanObject{Bucket: "bucket", Key: "obj", Size: 5}
+
+	s, err := New(&Options{URL: srv.URL})
+	require.NoError(t, err)
+	_, err = s.Scan(context.Background(), obj, strings.NewReader("clean"))
+	require.Error(t, err)
+
+	s, err = New(&Options{URL: srv.URL, Signer: verifier})
+	require.NoError(t, err)
+	res, err := s.Scan(context.Background(), obj, strings.NewReader("clean"))
+	require.NoError(t, err)
+	require.False(t, res.Infected)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&Options{URL: "ftp://localhost"})
+	require.Error(t, err)
+
+	signer, err := signing.NewHMAC("secret")
+	require.NoError(t, err)
+	_, err = New(&Options{URL: "icap://localhost/avscan", Signer: signer})
+	require.Error(t, err)
+
+	s, err := New(&Options{URL: "icap://localhost/avscan"})
+	require.NoError(t, err)
+	require.Equal(t, "localhost:"+defaultICAPPort, s.(*ICAP).addr)
+}
@@ -0,0 +1,159 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	// TypeHMAC signs requests with HMAC-SHA256 of the shared secret.
+	TypeHMAC = "hmac"
+	// TypeSigV4 signs requests with AWS Signature Version 4.
+	TypeSigV4 = "sigv4"
+
+	// HeaderTimestamp is a header with Unix time of HMAC signing.
+	HeaderTimestamp = "X-Neofs-S3-Timestamp"
+	// HeaderSignature is a header with hex encoded HMAC-SHA256 signature.
+	HeaderSignature = "X-Neofs-S3-Signature"
+
+	defaultSigV4Service = "s3"
+	defaultSigV4Region  = "us-east-1"
+)
+
+type (
+	// Config contains parameters of outbound requests signing.
+	Config struct {
+		// Type is TypeHMAC or TypeSigV4, requests aren't signed if it's empty.
+		Type string
+		// Secret is a shared secret of HMAC signature.
+		Secret string
+
+		AccessKeyID     string
+		SecretAccessKey string
+		Region          string
+		Service         string
+	}
+
+	// RequestSigner signs outbound HTTP requests, so receivers can
+	// authenticate the gateway.
+	RequestSigner interface {
+		Sign(req *http.Request) error
+	}
+
+	// HMAC signs requests and messages with the shared secret.
+	//
+	// The signature of the request is calculated over the lines of the
+	// timestamp, the method, the request URI and lowercase `name:value` pairs
+	// of X- headers sorted by name. The signature of the message is
+	// calculated over the timestamp line and the message payload.
+	// Payloads of requests aren't signed, since they are streamed.
+	HMAC struct {
+		secret []byte
+	}
+
+	// SigV4 signs requests with AWS Signature Version 4, payloads are sent
+	// as UNSIGNED-PAYLOAD.
+	SigV4 struct {
+		signer  *v4.Signer
+		region  string
+		service string
+	}
+)
+
+// New creates the request signer, it returns nil if signing isn't configured.
+func New(cfg *Config) (RequestSigner, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case TypeHMAC:
+		return NewHMAC(cfg.Secret)
+	case TypeSigV4:
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, errors.New("sigv4 signing requires access key id and secret access key")
+		}
+
+		s := &SigV4{
+			signer: v4.NewSigner(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""), func(s *v4.Signer) {
+				s.UnsignedPayload = true
+				s.DisableRequestBodyOverwrite = true
+			}),
+			region:  cfg.Region,
+			service: cfg.Service,
+		}
+		if s.region == "" {
+			s.region = defaultSigV4Region
+		}
+		if s.service == "" {
+			s.service = defaultSigV4Service
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown signing type '%s'", cfg.Type)
+	}
+}
+
+// NewHMAC creates HMAC signer with the shared secret.
+func NewHMAC(secret string) (*HMAC, error) {
+	if secret == "" {
+		return nil, errors.New("hmac signing requires secret")
+	}
+
+	return &HMAC{secret: []byte(secret)}, nil
+}
+
+// Sign implements RequestSigner.
+func (x *HMAC) Sign(req *http.Request) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, x.Signature(RequestStringToSign(req, timestamp)))
+	return nil
+}
+
+// SignMessage returns the timestamp and the signature of the message payload.
+func (x *HMAC) SignMessage(payload []byte, now time.Time) (string, string) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	return timestamp, x.Signature(timestamp + "\n" + string(payload))
+}
+
+// Signature returns hex encoded HMAC-SHA256 of the data.
+func (x *HMAC) Signature(data string) string {
+	mac := hmac.New(sha256.New, x.secret)
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RequestStringToSign returns the data signed by HMAC signer for the request.
+func RequestStringToSign(req *http.Request, timestamp string) string {
+	var headers []string
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "x-") || name == strings.ToLower(HeaderTimestamp) || name == strings.ToLower(HeaderSignature) {
+			continue
+		}
+		headers = append(headers, name+":"+strings.Join(values, ","))
+	}
+	sort.Strings(headers)
+
+	lines := append([]string{timestamp, req.Method, req.URL.RequestURI()}, headers...)
+	return strings.Join(lines, "\n")
+}
+
+// Sign implements RequestSigner.
+func (x *SigV4) Sign(req *http.Request) error {
+	if _, err := x.signer.Sign(req, nil, x.service, x.region, time.Now()); err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package signing
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMAC(t *testing.T) {
+	s, err := New(&Config{Type: TypeHMAC, Secret: "secret"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/scan?id=1", strings.NewReader("payload"))
+	require.NoError(t, err)
+	req.Header.Set("X-Scan-Bucket", "bucket")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	require.NoError(t, s.Sign(req))
+
+	timestamp := req.Header.Get(HeaderTimestamp)
+	require.NotEmpty(t, timestamp)
+	require.Equal(t, timestamp+"\nPOST\n/scan?id=1\nx-scan-bucket:bucket", RequestStringToSign(req, timestamp))
+
+	verifier, err := NewHMAC("secret")
+	require.NoError(t, err)
+	require.Equal(t, verifier.Signature(RequestStringToSign(req, timestamp)), req.Header.Get(HeaderSignature))
+
+	req.Header.Set("X-Scan-Bucket", "other")
+	require.NotEqual(t, verifier.Signature(RequestStringToSign(req, timestamp)), req.Header.Get(HeaderSignature))
+
+	timestamp, signature := verifier.SignMessage([]byte("message"), time.Unix(100, 0))
+	require.Equal(t, "100", timestamp)
+	require.Equal(t, verifier.Signature("100\nmessage"), signature)
+}
+
+func TestSigV4(t *testing.T) {
+	s, err := New(&Config{Type: TypeSigV4, AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-west-1"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/scan", strings.NewReader("payload"))
+	require.NoError(t, err)
+	require.NoError(t, s.Sign(req))
+
+	require.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/"))
+	require.Contains(t, req.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+	require.Equal(t, "UNSIGNED-PAYLOAD", req.Header.Get("X-Amz-Content-Sha256"))
+
+	payload, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(payload))
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(&Config{})
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	for _, cfg := range []*Config{
+		{Type: TypeHMAC},
+		{Type: TypeSigV4, AccessKeyID: "key"},
+		{Type: "unknown"},
+	} {
+		_, err = New(cfg)
+		require.Error(t, err, cfg.Type)
+	}
+}
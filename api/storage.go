@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+)
+
+type (
+	// StorageState reports the state of NeoFS nodes the gateway is connected to.
+	StorageState interface {
+		StorageStatus() StorageStatus
+	}
+
+	// StorageStatus describes availability of NeoFS nodes. Storage is
+	// unavailable if every known node is unhealthy. The gateway is degraded
+	// if any latency objective is breached.
+	StorageStatus struct {
+		Ready       bool                `json:"ready"`
+		Degraded    bool                `json:"degraded,omitempty"`
+		Nodes       int                 `json:"nodes"`
+		Unhealthy   []UnhealthyNode     `json:"unhealthy,omitempty"`
+		SLOBreaches []metrics.SLOBreach `json:"sloBreaches,omitempty"`
+	}
+
+	// readinessResponse is the public part of StorageStatus, node addresses
+	// and errors are served by the admin API only.
+	readinessResponse struct {
+		Ready    bool `json:"ready"`
+		Degraded bool `json:"degraded,omitempty"`
+	}
+
+	// UnhealthyNode describes the node failing NeoFS operations.
+	UnhealthyNode struct {
+		Address   string `json:"address"`
+		Errors    uint32 `json:"errors"`
+		LastError string `json:"lastError,omitempty"`
+	}
+)
+
+// ReadinessPath is the path of the gateway readiness endpoint, it responds
+// with 503 status while all NeoFS nodes are unhealthy. Breached latency
+// objectives mark the gateway degraded, but it's still ready.
+const ReadinessPath = "/-/ready"
+
+// StorageReport returns the status of the storage with breached latency
+// objectives.
+func StorageReport(storage StorageState) StorageStatus {
+	status := storage.StorageStatus()
+	status.SLOBreaches = metrics.SLOBreaches()
+	status.Degraded = len(status.SLOBreaches) > 0
+
+	return status
+}
+
+// rejectIfStorageDown responds with ServiceUnavailable error immediately if
+// no NeoFS node is healthy, so requests don't hang until timeout and clients
+// or load balancers can retry with another gateway.
+func rejectIfStorageDown(storage StorageState) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !storage.StorageStatus().Ready {
+				WriteErrorResponse(w, GetReqInfo(r.Context()), s3errors.GetAPIError(s3errors.ErrStorageUnavailable))
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func readinessHandler(storage StorageState) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		status := StorageReport(storage)
+
+		data, err := json.Marshal(readinessResponse{Ready: status.Ready, Degraded: status.Degraded})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		WriteResponse(w, code, data, MimeJSON)
+	}
+}
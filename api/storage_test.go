@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type storageStateMock StorageStatus
+
+func (s storageStateMock) StorageStatus() StorageStatus {
+	return StorageStatus(s)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	unhealthy := []UnhealthyNode{{Address: "node1:8080", Errors: 10, LastError: "connection refused"}}
+
+	for _, tc := range []struct {
+		name   string
+		status StorageStatus
+		code   int
+	}{
+		{name: "ready", status: StorageStatus{Ready: true, Nodes: 2, Unhealthy: unhealthy}, code: http.StatusOK},
+		{name: "storage down", status: StorageStatus{Nodes: 1, Unhealthy: unhealthy}, code: http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			readinessHandler(storageStateMock(tc.status))(w, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+			require.Equal(t, tc.code, w.Code)
+
+			// node addresses and errors aren't disclosed
+			var res map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, map[string]any{"ready": tc.status.Ready}, res)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package transform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxDimension limits the width and the height of derived images.
+	maxDimension = 4096
+	// maxSourcePixels protects from images which are small, but take a lot of
+	// memory when decoded.
+	maxSourcePixels = 50_000_000
+
+	jpegQuality = 85
+)
+
+// imageResizer scales JPEG, PNG and GIF images. Arguments are `WxH`, images
+// are scaled to fit the box keeping the aspect ratio and aren't enlarged,
+// one of dimensions can be omitted. If crop is set, images are scaled to
+// cover the box and cropped to its size, both dimensions are required.
+type imageResizer struct {
+	crop bool
+}
+
+// Check implements Transformer.
+func (x imageResizer) Check(args string) error {
+	_, _, err := x.parseArgs(args)
+	return err
+}
+
+func (x imageResizer) parseArgs(args string) (int, int, error) {
+	wStr, hStr, ok := strings.Cut(args, "x")
+	if !ok {
+		return 0, 0, errors.New("size must be WxH")
+	}
+
+	var dims [2]int
+	for i, s := range []string{wStr, hStr} {
+		if s == "" && !x.crop {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 || v > maxDimension {
+			return 0, 0, fmt.Errorf("dimensions must be from 1 to %d", maxDimension)
+		}
+		dims[i] = v
+	}
+
+	if dims[0] == 0 && dims[1] == 0 {
+		return 0, 0, errors.New("size must be WxH")
+	}
+
+	return dims[0], dims[1], nil
+}
+
+// Transform implements Transformer.
+func (x imageResizer) Transform(args string, payload []byte, _ string) ([]byte, string, error) {
+	w, h, err := x.parseArgs(args)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedContent, err.Error())
+	}
+	if cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, "", fmt.Errorf("%w: image is too large", ErrUnsupportedContent)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedContent, err.Error())
+	}
+
+	region, dstW, dstH := x.geometry(img.Bounds(), w, h)
+	res := scale(img, region, dstW, dstH)
+
+	buf := new(bytes.Buffer)
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buf, res, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(buf, res)
+	case "gif":
+		err = gif.Encode(buf, res, nil)
+	default:
+		return nil, "", fmt.Errorf("%w: image format '%s'", ErrUnsupportedContent, format)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("encode image: %w", err)
+	}
+
+	return buf.Bytes(), "image/" + format, nil
+}
+
+// geometry returns the source region and the size of the derived image.
+func (x imageResizer) geometry(bounds image.Rectangle, w, h int) (image.Rectangle, int, int) {
+	sw, sh := float64(bounds.Dx()), float64(bounds.Dy())
+
+	if x.crop {
+		ratio := math.Max(float64(w)/sw, float64(h)/sh)
+		cw, ch := int(math.Round(float64(w)/ratio)), int(math.Round(float64(h)/ratio))
+		cw, ch = clamp(cw, 1, bounds.Dx()), clamp(ch, 1, bounds.Dy())
+		origin := bounds.Min.Add(image.Pt((bounds.Dx()-cw)/2, (bounds.Dy()-ch)/2))
+		return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(cw, ch))}, w, h
+	}
+
+	ratio := 1.0
+	if w > 0 {
+		ratio = math.Min(ratio, float64(w)/sw)
+	}
+	if h > 0 {
+		ratio = math.Min(ratio, float64(h)/sh)
+	}
+
+	return bounds, clamp(int(math.Round(sw*ratio)), 1, maxDimension), clamp(int(math.Round(sh*ratio)), 1, maxDimension)
+}
+
+// scale resizes the region of the image averaging source pixels covered by
+// each destination pixel.
+func scale(img image.Image, region image.Rectangle, w, h int) *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(src, src.Bounds(), img, region.Min, draw.Src)
+
+	sw, sh := region.Dx(), region.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := span(y, h, sh)
+		for x := 0; x < w; x++ {
+			x0, x1 := span(x, w, sw)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// span returns the range of source pixels covered by the destination pixel.
+func span(i, dstLen, srcLen int) (int, int) {
+	from, to := i*srcLen/dstLen, (i+1)*srcLen/dstLen
+	if to <= from {
+		to = from + 1
+	}
+
+	return from, to
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
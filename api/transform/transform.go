@@ -0,0 +1,174 @@
+package transform
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+)
+
+const (
+	// DefaultMaxSourceSize is a default limit of the size of transformed objects.
+	DefaultMaxSourceSize = 20 << 20
+	// DefaultMaxConcurrent is a default limit of objects transformed at once.
+	DefaultMaxConcurrent = 4
+)
+
+type (
+	// Transformer derives new content from the object payload.
+	Transformer interface {
+		// Check validates arguments of the transformation.
+		Check(args string) error
+		// Transform returns the derived payload and its content type.
+		Transform(args string, payload []byte, contentType string) ([]byte, string, error)
+	}
+
+	// Step is a single transformation of the pipeline.
+	Step struct {
+		Name string
+		Args string
+	}
+
+	// Config contains parameters of the pipeline.
+	Config struct {
+		// MaxSourceSize limits the size of transformed objects, objects are
+		// loaded in memory to be transformed.
+		MaxSourceSize int64
+		// MaxConcurrent limits the number of objects loaded and transformed
+		// at once, other requests wait for their turn.
+		MaxConcurrent int
+		Cache         *cache.TransformedConfig
+	}
+
+	// Pipeline applies transformations requested with x-transform query
+	// parameter, results are cached.
+	Pipeline struct {
+		transformers  map[string]Transformer
+		cache         *cache.TransformedCache
+		maxSourceSize int64
+		// sem bounds the memory taken by decoded objects.
+		sem chan struct{}
+	}
+
+	// Loader reads the payload and the content type of the source object.
+	Loader func() ([]byte, string, error)
+)
+
+var (
+	// ErrInvalidSpec is returned for malformed or unknown transformations.
+	ErrInvalidSpec = errors.New("invalid transformation")
+	// ErrUnsupportedContent is returned if the object can't be transformed.
+	ErrUnsupportedContent = errors.New("unsupported content")
+)
+
+// NewPipeline creates the pipeline with built-in image resize and thumbnail
+// transformations.
+func NewPipeline(cfg *Config) *Pipeline {
+	maxSourceSize := cfg.MaxSourceSize
+	if maxSourceSize <= 0 {
+		maxSourceSize = DefaultMaxSourceSize
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+
+	p := &Pipeline{
+		transformers:  make(map[string]Transformer),
+		cache:         cache.NewTransformedCache(cfg.Cache),
+		maxSourceSize: maxSourceSize,
+		sem:           make(chan struct{}, maxConcurrent),
+	}
+
+	p.Register("resize", imageResizer{})
+	p.Register("thumbnail", imageResizer{crop: true})
+
+	return p
+}
+
+// Register adds the transformation, it replaces the previous one with the same name.
+func (p *Pipeline) Register(name string, t Transformer) {
+	p.transformers[name] = t
+}
+
+// MaxSourceSize returns the limit of the size of transformed objects.
+func (p *Pipeline) MaxSourceSize() int64 {
+	return p.maxSourceSize
+}
+
+// Parse parses comma separated transformations like `resize:200x200`.
+func (p *Pipeline) Parse(spec string) ([]Step, error) {
+	var steps []Step
+	for _, s := range strings.Split(spec, ",") {
+		name, args, _ := strings.Cut(strings.TrimSpace(s), ":")
+		t, ok := p.transformers[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown transformation '%s'", ErrInvalidSpec, name)
+		}
+		if err := t.Check(args); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidSpec, name, err.Error())
+		}
+		steps = append(steps, Step{Name: name, Args: args})
+	}
+
+	return steps, nil
+}
+
+// Apply returns the cached result or loads the object and transforms it.
+// The key must identify the object version. Objects are transformed by at
+// most MaxConcurrent calls at once, Apply waits for its turn until the
+// context is done.
+func (p *Pipeline) Apply(ctx context.Context, key string, steps []Step, load Loader) (*data.TransformedObject, error) {
+	key += "|" + specString(steps)
+	if obj := p.cache.Get(key); obj != nil {
+		return obj, nil
+	}
+
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// the same object may have been transformed while waiting
+	if obj := p.cache.Get(key); obj != nil {
+		return obj, nil
+	}
+
+	payload, contentType, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range steps {
+		if payload, contentType, err = p.transformers[step.Name].Transform(step.Args, payload, contentType); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Name, err)
+		}
+	}
+
+	hash := md5.Sum(payload)
+	obj := &data.TransformedObject{
+		Payload:     payload,
+		ContentType: contentType,
+		HashSum:     hex.EncodeToString(hash[:]),
+	}
+
+	p.cache.Put(key, obj)
+
+	return obj, nil
+}
+
+func specString(steps []Step) string {
+	parts := make([]string, len(steps))
+	for i, step := range steps {
+		parts[i] = step.Name + ":" + step.Args
+	}
+
+	return strings.Join(parts, ",")
+}
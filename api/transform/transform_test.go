@@ -0,0 +1,132 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	p := NewPipeline(&Config{Cache: cache.DefaultTransformedConfig(zap.NewNop())})
+
+	steps, err := p.Parse("resize:200x, thumbnail:50x50")
+	require.NoError(t, err)
+	require.Equal(t, []Step{{Name: "resize", Args: "200x"}, {Name: "thumbnail", Args: "50x50"}}, steps)
+
+	for _, spec := range []string{"", "rotate:90", "resize", "resize:x", "resize:0x10", "resize:10x5000", "thumbnail:10x"} {
+		_, err = p.Parse(spec)
+		require.ErrorIs(t, err, ErrInvalidSpec, spec)
+	}
+}
+
+func TestImageResizer(t *testing.T) {
+	src := testPNG(t, 200, 100)
+
+	for _, tc := range []struct {
+		crop          bool
+		args          string
+		width, height int
+	}{
+		{args: "100x100", width: 100, height: 50},
+		{args: "x20", width: 40, height: 20},
+		{args: "400x400", width: 200, height: 100},
+		{crop: true, args: "50x50", width: 50, height: 50},
+		{crop: true, args: "400x100", width: 400, height: 100},
+	} {
+		res, contentType, err := imageResizer{crop: tc.crop}.Transform(tc.args, src, "")
+		require.NoError(t, err)
+		require.Equal(t, "image/png", contentType)
+
+		cfg, err := png.DecodeConfig(bytes.NewReader(res))
+		require.NoError(t, err)
+		require.Equal(t, tc.width, cfg.Width, tc.args)
+		require.Equal(t, tc.height, cfg.Height, tc.args)
+	}
+
+	_, _, err := imageResizer{}.Transform("10x10", []byte("not an image"), "text/plain")
+	require.ErrorIs(t, err, ErrUnsupportedContent)
+}
+
+func TestApplyCache(t *testing.T) {
+	p := NewPipeline(&Config{Cache: cache.DefaultTransformedConfig(zap.NewNop())})
+	src := testPNG(t, 20, 20)
+
+	var loads int
+	load := func() ([]byte, string, error) {
+		loads++
+		return src, "image/png", nil
+	}
+
+	steps, err := p.Parse("resize:10x10")
+	require.NoError(t, err)
+
+	res, err := p.Apply(context.Background(), "object", steps, load)
+	require.NoError(t, err)
+	cached, err := p.Apply(context.Background(), "object", steps, load)
+	require.NoError(t, err)
+	require.Equal(t, res, cached)
+	require.Equal(t, 1, loads)
+
+	steps, err = p.Parse("resize:5x5")
+	require.NoError(t, err)
+	_, err = p.Apply(context.Background(), "object", steps, load)
+	require.NoError(t, err)
+	require.Equal(t, 2, loads)
+}
+
+func TestApplyConcurrency(t *testing.T) {
+	p := NewPipeline(&Config{MaxConcurrent: 1, Cache: cache.DefaultTransformedConfig(zap.NewNop())})
+	src := testPNG(t, 20, 20)
+
+	steps, err := p.Parse("resize:10x10")
+	require.NoError(t, err)
+
+	loading, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := p.Apply(context.Background(), "first", steps, func() ([]byte, string, error) {
+			close(loading)
+			<-release
+			return src, "image/png", nil
+		})
+		done <- err
+	}()
+	<-loading
+
+	// other objects wait for their turn until the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Apply(ctx, "second", steps, func() ([]byte, string, error) {
+		t.Fatal("object is loaded over the limit")
+		return nil, "", nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	// cached results don't wait
+	p.sem <- struct{}{}
+	_, err = p.Apply(ctx, "first", steps, nil)
+	require.NoError(t, err)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/ratelimit"
 	"go.uber.org/zap"
 )
 
@@ -24,27 +26,87 @@ var ClientTime = KeyWrapper("__context_client_time")
 // Typical usage with `--no-sign-request`.
 var AnonymousRequest = KeyWrapper("__context_anonymous_request")
 
-// AttachUserAuth adds user authentication via center to router using log for logging.
-func AttachUserAuth(router *mux.Router, center auth.Center, log *zap.Logger) {
+// AnonymousAccess restricts requests made without authorization before they
+// reach NeoFS ACL checks. Anonymous requests are rejected with AccessDenied
+// error unless Enabled is set, non-empty PublicBuckets allow only reads of
+// the listed buckets. CORS preflight requests are always allowed.
+type AnonymousAccess struct {
+	Enabled       bool
+	PublicBuckets []string
+}
+
+// allows checks if the anonymous request is allowed.
+func (a AnonymousAccess) allows(r *http.Request) bool {
+	if r.Method == http.MethodOptions {
+		return true
+	}
+	if !a.Enabled {
+		return false
+	}
+	if len(a.PublicBuckets) == 0 {
+		return true
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	bucket := GetReqInfo(r.Context()).BucketName
+	for _, public := range a.PublicBuckets {
+		if bucket != "" && bucket == public {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AttachUserAuth adds user authentication via center to router using log for
+// logging. Requests failed authentication are rejected, anonymous ones are
+// checked against anon. Authenticated requests exceeding the rate limit of
+// their access key are rejected with SlowDown error, ones denied by the access
+// policy of the credentials are rejected with AccessDenied error.
+func AttachUserAuth(router *mux.Router, center auth.Center, anon AnonymousAccess, limiter *ratelimit.Limiter, log *zap.Logger) {
 	router.Use(func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var ctx context.Context
 			box, err := center.Authenticate(r)
 			if err != nil {
 				if errors.Is(err, auth.ErrNoAuthorizationHeader) {
+					if !anon.allows(r) {
+						log.Debug("anonymous request is denied", zap.String("method", r.Method),
+							zap.String("bucket", GetReqInfo(r.Context()).BucketName))
+						WriteErrorResponse(w, GetReqInfo(r.Context()), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+						return
+					}
 					log.Debug("couldn't receive access box for gate key, random key will be used")
 					// put clear indicator, that we should exec request as an anonymous user.
 					ctx = context.WithValue(r.Context(), AnonymousRequest, true)
 				} else {
 					log.Error("failed to pass authentication", zap.Error(err))
+					// wrapped S3 errors are written as they are, the
+					// details are logged only
 					var s3err s3errors.Error
 					if !errors.As(err, &s3err) {
-						err = s3errors.GetAPIError(s3errors.ErrAccessDenied)
+						s3err = s3errors.GetAPIError(s3errors.ErrAccessDenied)
 					}
-					WriteErrorResponse(w, GetReqInfo(r.Context()), err)
+					WriteErrorResponse(w, GetReqInfo(r.Context()), s3err)
 					return
 				}
 			} else {
+				if key := rateLimitKey(box); !limiter.Allow(key) {
+					log.Debug("request rate of access key is exceeded", zap.String("access_key_id", key))
+					metrics.IncRateLimitedRequests()
+					WriteErrorResponse(w, GetReqInfo(r.Context()), s3errors.GetAPIError(s3errors.ErrSlowDown))
+					return
+				}
+
+				if policy := box.AccessBox.AccessPolicy; policy != nil && !allowedByAccessPolicy(policy, r) {
+					log.Debug("request is denied by access policy", zap.String("access_key_id", box.AccessKeyID),
+						zap.String("api", GetReqInfo(r.Context()).API))
+					WriteErrorResponse(w, GetReqInfo(r.Context()), s3errors.GetAPIError(s3errors.ErrAccessDenied))
+					return
+				}
+
 				ctx = context.WithValue(r.Context(), BoxData, box.AccessBox)
 				if !box.ClientTime.IsZero() {
 					ctx = context.WithValue(ctx, ClientTime, box.ClientTime)
@@ -56,6 +118,15 @@ func AttachUserAuth(router *mux.Router, center auth.Center, log *zap.Logger) {
 	})
 }
 
+// rateLimitKey returns the access key the request rate is limited for,
+// temporary credentials share the limit of their parent key.
+func rateLimitKey(box *auth.Box) string {
+	if box.Session != nil {
+		return box.Session.ParentAccessKeyID
+	}
+	return box.AccessKeyID
+}
+
 // IsAnonymousRequest helps to check the request was made as an anonymous user.
 func IsAnonymousRequest(ctx context.Context) bool {
 	if bd, ok := ctx.Value(AnonymousRequest).(bool); ok {
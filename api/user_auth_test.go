@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type anonymousCenter struct{}
+
+func (anonymousCenter) Authenticate(*http.Request) (*auth.Box, error) {
+	return nil, auth.ErrNoAuthorizationHeader
+}
+
+func TestAnonymousAccess(t *testing.T) {
+	publicOnly := AnonymousAccess{Enabled: true, PublicBuckets: []string{"public"}}
+
+	for _, tc := range []struct {
+		name    string
+		anon    AnonymousAccess
+		method  string
+		bucket  string
+		allowed bool
+	}{
+		{name: "deny all", method: http.MethodGet, bucket: "public"},
+		{name: "deny all, preflight", method: http.MethodOptions, bucket: "public", allowed: true},
+		{name: "allow all", anon: AnonymousAccess{Enabled: true}, method: http.MethodPut, bucket: "private", allowed: true},
+		{name: "public bucket list", anon: publicOnly, method: http.MethodGet, bucket: "public", allowed: true},
+		{name: "public bucket head", anon: publicOnly, method: http.MethodHead, bucket: "public", allowed: true},
+		{name: "public bucket put", anon: publicOnly, method: http.MethodPut, bucket: "public"},
+		{name: "public bucket post", anon: publicOnly, method: http.MethodPost, bucket: "public"},
+		{name: "public bucket delete", anon: publicOnly, method: http.MethodDelete, bucket: "public"},
+		{name: "public bucket preflight", anon: publicOnly, method: http.MethodOptions, bucket: "public", allowed: true},
+		{name: "private bucket", anon: publicOnly, method: http.MethodGet, bucket: "private"},
+		{name: "bucket prefix", anon: publicOnly, method: http.MethodGet, bucket: "publicity"},
+		{name: "list buckets", anon: publicOnly, method: http.MethodGet},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/"+tc.bucket, nil)
+			r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{BucketName: tc.bucket}))
+			require.Equal(t, tc.allowed, tc.anon.allows(r))
+
+			var anonymous bool
+			router := mux.NewRouter()
+			AttachUserAuth(router, anonymousCenter{}, tc.anon, nil, zap.NewNop())
+			router.PathPrefix("/").HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				anonymous = IsAnonymousRequest(r.Context())
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			require.Equal(t, tc.allowed, anonymous)
+			if !tc.allowed {
+				require.Equal(t, http.StatusForbidden, w.Code)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accesspolicy"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
@@ -64,7 +66,7 @@ type NeoFS interface {
 	// It sets 'Timestamp' attribute to the current time.
 	// It returns the ID of the saved container.
 	//
-	// The container must be private with GET access for OTHERS group.
+	// The container must be private with GET, HEAD and SEARCH access for OTHERS group.
 	// Creation time should also be stamped.
 	//
 	// It returns exactly one non-nil value. It returns any error encountered which
@@ -106,6 +108,18 @@ type (
 		Lifetime              time.Duration
 		AwsCliCredentialsFile string
 		ContainerPolicies     ContainerPolicies
+		// Description is stored in the credentials registry (optional).
+		Description string
+		// AccessKeyID replaces the access box address in the access key ID
+		// (optional), such credentials are resolved by gateways with NeoFS
+		// credentials backend only.
+		AccessKeyID string
+		// AccessPolicy is a JSON policy document restricting requests made
+		// with the credentials (optional).
+		AccessPolicy []byte
+		// Renewable allows gateways to re-issue the expired bearer token if
+		// it's signed by their key.
+		Renewable bool
 	}
 
 	// ContainerOptions groups parameters of auth container to put the secret into.
@@ -129,12 +143,15 @@ type lifetimeOptions struct {
 }
 
 type (
-	issuingResult struct {
+	// IssuedSecret is the result of Agent.Issue.
+	IssuedSecret struct {
 		AccessKeyID     string `json:"access_key_id"`
 		SecretAccessKey string `json:"secret_access_key"`
 		OwnerPrivateKey string `json:"owner_private_key"`
 		WalletPublicKey string `json:"wallet_public_key"`
 		ContainerID     string `json:"container_id"`
+
+		objectID oid.ID
 	}
 
 	obtainingResult struct {
@@ -205,6 +222,38 @@ func preparePolicy(policy ContainerPolicies) ([]*accessbox.AccessBox_ContainerPo
 
 // IssueSecret creates an auth token, puts it in the NeoFS network and writes to io.Writer a new secret access key.
 func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecretOptions) error {
+	ir, err := a.Issue(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(ir); err != nil {
+		return err
+	}
+
+	if options.AwsCliCredentialsFile != "" {
+		profileName := "authmate_cred_" + ir.objectID.EncodeToString()
+		if _, err = os.Stat(options.AwsCliCredentialsFile); os.IsNotExist(err) {
+			profileName = "default"
+		}
+		file, err := os.OpenFile(options.AwsCliCredentialsFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			return fmt.Errorf("couldn't open aws cli credentials file: %w", err)
+		}
+		defer file.Close()
+		if _, err = file.WriteString(fmt.Sprintf("\n[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\n",
+			profileName, ir.AccessKeyID, ir.SecretAccessKey)); err != nil {
+			return fmt.Errorf("fails to write to file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Issue creates an auth token and puts it in the NeoFS network, it returns
+// the new credentials.
+func (a *Agent) Issue(ctx context.Context, options *IssueSecretOptions) (*IssuedSecret, error) {
 	var (
 		err      error
 		box      *accessbox.AccessBox
@@ -213,25 +262,34 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 
 	policies, err := preparePolicy(options.ContainerPolicies)
 	if err != nil {
-		return fmt.Errorf("prepare policies: %w", err)
+		return nil, fmt.Errorf("prepare policies: %w", err)
+	}
+
+	if len(options.AccessPolicy) != 0 {
+		if _, err = accesspolicy.Parse(options.AccessPolicy); err != nil {
+			return nil, fmt.Errorf("invalid access policy: %w", err)
+		}
 	}
 
-	lifetime.Iat, lifetime.Exp, err = a.neoFS.TimeToEpoch(ctx, time.Now().Add(options.Lifetime))
+	now := time.Now()
+	lifetime.Iat, lifetime.Exp, err = a.neoFS.TimeToEpoch(ctx, now.Add(options.Lifetime))
 	if err != nil {
-		return fmt.Errorf("fetch time to epoch: %w", err)
+		return nil, fmt.Errorf("fetch time to epoch: %w", err)
 	}
 
 	gatesData, err := createTokens(options, lifetime)
 	if err != nil {
-		return fmt.Errorf("create tokens: %w", err)
+		return nil, fmt.Errorf("create tokens: %w", err)
 	}
 
 	box, secrets, err := accessbox.PackTokens(gatesData)
 	if err != nil {
-		return fmt.Errorf("pack tokens: %w", err)
+		return nil, fmt.Errorf("pack tokens: %w", err)
 	}
 
 	box.ContainerPolicy = policies
+	box.AccessPolicy = options.AccessPolicy
+	box.Renewable = options.Renewable
 
 	signer := user.NewAutoIDSignerRFC6979(options.NeoFSKey.PrivateKey)
 	idOwner := signer.UserID()
@@ -241,7 +299,12 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		zap.String("placement_policy", options.Container.PlacementPolicy))
 	id, err := a.checkContainer(ctx, options.Container, idOwner, *options.NeoFSKey.PublicKey())
 	if err != nil {
-		return fmt.Errorf("check container: %w", err)
+		return nil, fmt.Errorf("check container: %w", err)
+	}
+
+	record, err := registryRecord(options, idOwner, now)
+	if err != nil {
+		return nil, fmt.Errorf("prepare registry record: %w", err)
 	}
 
 	a.log.Info("store bearer token into NeoFS",
@@ -249,46 +312,25 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 
 	addr, err := tokens.
 		New(a.neoFS, secrets.EphemeralKey, cache.DefaultAccessBoxConfig(a.log)).
-		Put(ctx, id, idOwner, box, lifetime.Exp, options.GatesPublicKeys...)
+		Put(ctx, id, idOwner, box, lifetime.Exp, record.Attributes(), options.GatesPublicKeys...)
 	if err != nil {
-		return fmt.Errorf("failed to put bearer token: %w", err)
+		return nil, fmt.Errorf("failed to put bearer token: %w", err)
 	}
 
 	objID := addr.Object()
-	strIDObj := objID.EncodeToString()
-
-	accessKeyID := addr.Container().EncodeToString() + "0" + strIDObj
+	accessKeyID := addr.Container().EncodeToString() + "0" + objID.EncodeToString()
+	if options.AccessKeyID != "" {
+		accessKeyID = options.AccessKeyID
+	}
 
-	ir := &issuingResult{
+	return &IssuedSecret{
 		AccessKeyID:     accessKeyID,
 		SecretAccessKey: secrets.AccessKey,
 		OwnerPrivateKey: hex.EncodeToString(secrets.EphemeralKey.Bytes()),
 		WalletPublicKey: hex.EncodeToString(options.NeoFSKey.PublicKey().Bytes()),
 		ContainerID:     id.EncodeToString(),
-	}
-
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	if err = enc.Encode(ir); err != nil {
-		return err
-	}
-
-	if options.AwsCliCredentialsFile != "" {
-		profileName := "authmate_cred_" + strIDObj
-		if _, err = os.Stat(options.AwsCliCredentialsFile); os.IsNotExist(err) {
-			profileName = "default"
-		}
-		file, err := os.OpenFile(options.AwsCliCredentialsFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-		if err != nil {
-			return fmt.Errorf("couldn't open aws cli credentials file: %w", err)
-		}
-		defer file.Close()
-		if _, err = file.WriteString(fmt.Sprintf("\n[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\n",
-			profileName, accessKeyID, secrets.AccessKey)); err != nil {
-			return fmt.Errorf("fails to write to file: %w", err)
-		}
-	}
-	return nil
+		objectID:        objID,
+	}, nil
 }
 
 // ObtainSecret receives an existing secret access key from NeoFS and
@@ -411,6 +453,44 @@ func buildSessionTokens(key *keys.PrivateKey, lifetime lifetimeOptions, ctxs []s
 	return sessionTokens, nil
 }
 
+// registryRecord describes the issued credentials for the registry.
+func registryRecord(options *IssueSecretOptions, issuer user.ID, now time.Time) (*registry.Record, error) {
+	record := &registry.Record{
+		Issuer:      issuer.EncodeToString(),
+		ExpiresAt:   now.Add(options.Lifetime),
+		Scopes:      []string{registry.ScopeObject},
+		Gates:       make([]string, len(options.GatesPublicKeys)),
+		Description: options.Description,
+		Alias:       options.AccessKeyID,
+	}
+
+	for i, key := range options.GatesPublicKeys {
+		record.Gates[i] = hex.EncodeToString(key.Bytes())
+	}
+
+	if !options.SkipSessionRules {
+		sessionRules, err := buildContext(options.SessionTokenRules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build context for session token: %w", err)
+		}
+
+		scopes := map[session.ContainerVerb]string{
+			session.VerbContainerPut:     registry.ScopeContainerPut,
+			session.VerbContainerDelete:  registry.ScopeContainerDelete,
+			session.VerbContainerSetEACL: registry.ScopeContainerEACL,
+		}
+		for _, rule := range sessionRules {
+			if scope, ok := scopes[rule.verb]; ok {
+				record.Scopes = append(record.Scopes, scope)
+				// rules can contain the same verb for different containers
+				delete(scopes, rule.verb)
+			}
+		}
+	}
+
+	return record, nil
+}
+
 func createTokens(options *IssueSecretOptions, lifetime lifetimeOptions) ([]*accessbox.GateData, error) {
 	gates := make([]*accessbox.GateData, len(options.GatesPublicKeys))
 
@@ -1,9 +1,14 @@
 package authmate
 
 import (
+	"encoding/hex"
 	"testing"
+	"time"
 
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +39,28 @@ func TestContainerSessionRules(t *testing.T) {
 	require.Equal(t, sessionContext[2].verb, session.VerbContainerSetEACL)
 	require.Zero(t, sessionContext[2].containerID)
 }
+
+func TestRegistryRecordScopes(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	options := &IssueSecretOptions{
+		GatesPublicKeys:   []*keys.PublicKey{key.PublicKey()},
+		SessionTokenRules: []byte(`[{"verb":"PUT"},{"verb":"DELETE","containerID":"6CcWg8LkcbfMUC8pt7wiy5zM1fyS3psNoxgfppcCgig1"},{"verb":"DELETE"}]`),
+		Lifetime:          time.Hour,
+		Description:       "test",
+	}
+
+	now := time.Now()
+	record, err := registryRecord(options, user.ID{}, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{registry.ScopeObject, registry.ScopeContainerPut, registry.ScopeContainerDelete, registry.ScopeContainerEACL}, record.Scopes)
+	require.Equal(t, []string{hex.EncodeToString(key.PublicKey().Bytes())}, record.Gates)
+	require.Equal(t, now.Add(time.Hour), record.ExpiresAt)
+	require.Equal(t, "test", record.Description)
+
+	options.SkipSessionRules = true
+	record, err = registryRecord(options, user.ID{}, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{registry.ScopeObject}, record.Scopes)
+}
@@ -17,9 +17,13 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accesspolicy"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/bucketsync"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/limits"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
@@ -43,6 +47,8 @@ const (
 	// a month.
 	defaultLifetime          = 30 * 24 * time.Hour
 	defaultPresignedLifetime = 12 * time.Hour
+
+	defaultSyncRegion = "us-east-1"
 )
 
 type PoolConfig struct {
@@ -81,6 +87,23 @@ var (
 	awcCliCredFile           string
 	timeoutFlag              time.Duration
 	slicerEnabledFlag        bool
+	descriptionFlag          string
+	issuedAccessKeyIDFlag    string
+	accessPolicyFlag         string
+	allowedOperationsFlag    string
+	renewableFlag            bool
+
+	// sync flags.
+	sourceEndpointFlag string
+	sourceBucketFlag   string
+	sourceProfileFlag  string
+	targetEndpointFlag string
+	targetBucketFlag   string
+	targetProfileFlag  string
+	prefixFlag         string
+	workersFlag        int
+	deleteFlag         bool
+	stateFileFlag      string
 
 	// pool timeouts flag.
 	poolDialTimeoutFlag        time.Duration
@@ -180,6 +203,7 @@ func appCommands() []*cli.Command {
 		issueSecret(),
 		obtainSecret(),
 		generatePresignedURL(),
+		syncBuckets(),
 	}
 }
 
@@ -267,6 +291,36 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				Required:    false,
 				Destination: &awcCliCredFile,
 			},
+			&cli.StringFlag{
+				Name:        "description",
+				Usage:       "description of the credentials stored in the registry",
+				Required:    false,
+				Destination: &descriptionFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id to use instead of the access box address, it requires NeoFS credentials backend of the gateway",
+				Required:    false,
+				Destination: &issuedAccessKeyIDFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-policy",
+				Usage:       "IAM-style policy restricting requests made with the credentials (filepath or a plain json string are allowed)",
+				Required:    false,
+				Destination: &accessPolicyFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "renewable",
+				Usage:       "allow gateways to re-issue the expired bearer token, it's renewed only if it's issued by the gateway key",
+				Required:    false,
+				Destination: &renewableFlag,
+			},
+			&cli.StringFlag{
+				Name:        "allowed-operations",
+				Usage:       "preset of the access policy restricting operations allowed to the credentials: " + strings.Join(accesspolicy.PresetNames(), ", "),
+				Required:    false,
+				Destination: &allowedOperationsFlag,
+			},
 			&cli.DurationFlag{
 				Name:        "pool-dial-timeout",
 				Usage:       `Timeout for connection to the node in pool to be established`,
@@ -371,6 +425,20 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				return cli.Exit(fmt.Sprintf("couldn't parse 'session-tokens' flag: %s", err.Error()), 8)
 			}
 
+			accessPolicy, err := getJSONRules(accessPolicyFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("couldn't parse 'access-policy' flag: %s", err.Error()), 8)
+			}
+
+			if allowedOperationsFlag != "" {
+				if accessPolicy != nil {
+					return cli.Exit("'access-policy' and 'allowed-operations' flags are mutually exclusive", 8)
+				}
+				if accessPolicy, err = accesspolicy.Preset(allowedOperationsFlag); err != nil {
+					return cli.Exit(fmt.Sprintf("couldn't parse 'allowed-operations' flag: %s", err.Error()), 8)
+				}
+			}
+
 			issueSecretOptions := &authmate.IssueSecretOptions{
 				Container: authmate.ContainerOptions{
 					ID:              containerID,
@@ -385,6 +453,10 @@ It will be ceil rounded to the nearest amount of epoch.`,
 				ContainerPolicies:     policies,
 				Lifetime:              lifetimeFlag,
 				AwsCliCredentialsFile: awcCliCredFile,
+				Description:           descriptionFlag,
+				AccessKeyID:           issuedAccessKeyIDFlag,
+				AccessPolicy:          accessPolicy,
+				Renewable:             renewableFlag,
 			}
 
 			var tcancel context.CancelFunc
@@ -515,6 +587,137 @@ It will be ceil rounded to the nearest amount of epoch.`,
 	}
 }
 
+func syncBuckets() *cli.Command {
+	return &cli.Command{
+		Name: "sync",
+		Description: `Copy objects of the bucket to another gateway or AWS S3. Objects missing in the target or
+differing by size or ETag are copied, payloads are verified with ETags of both buckets.
+Credentials are loaded from ~/.aws/credentials profiles, empty endpoint means AWS S3.
+Copied objects are saved to the state file, so interrupted synchronization is resumed
+without copying them again. The command isn't limited by --timeout flag.`,
+		Usage: "sync --source-endpoint http://s3.neofs.devenv:8080 --source-bucket bucket --source-profile neofs --target-bucket bucket --target-profile aws",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "source-endpoint",
+				Usage:       `Endpoint of the source gateway (default is AWS S3)`,
+				Destination: &sourceEndpointFlag,
+			},
+			&cli.StringFlag{
+				Name:        "source-bucket",
+				Usage:       `Bucket to copy objects from`,
+				Required:    true,
+				Destination: &sourceBucketFlag,
+			},
+			&cli.StringFlag{
+				Name:        "source-profile",
+				Usage:       `AWS profile with credentials of the source`,
+				Destination: &sourceProfileFlag,
+			},
+			&cli.StringFlag{
+				Name:        "target-endpoint",
+				Usage:       `Endpoint of the target gateway (default is AWS S3)`,
+				Destination: &targetEndpointFlag,
+			},
+			&cli.StringFlag{
+				Name:        "target-bucket",
+				Usage:       `Bucket to copy objects to, it must exist`,
+				Required:    true,
+				Destination: &targetBucketFlag,
+			},
+			&cli.StringFlag{
+				Name:        "target-profile",
+				Usage:       `AWS profile with credentials of the target`,
+				Destination: &targetProfileFlag,
+			},
+			&cli.StringFlag{
+				Name:        "prefix",
+				Usage:       `Synchronize only objects with the key prefix`,
+				Destination: &prefixFlag,
+			},
+			&cli.IntFlag{
+				Name:        "workers",
+				Usage:       `Number of objects copied in parallel`,
+				Destination: &workersFlag,
+				Value:       bucketsync.DefaultWorkers,
+			},
+			&cli.BoolFlag{
+				Name:        "delete",
+				Usage:       `Remove target objects missing in the source`,
+				Destination: &deleteFlag,
+			},
+			&cli.StringFlag{
+				Name:        "state-file",
+				Usage:       `File to save progress to and resume from`,
+				Destination: &stateFileFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			source, err := newS3Client(sourceEndpointFlag, sourceProfileFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("source: %s", err), 1)
+			}
+			target, err := newS3Client(targetEndpointFlag, targetProfileFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("target: %s", err), 1)
+			}
+
+			stats, err := bucketsync.Run(ctx, bucketsync.Config{
+				Source: bucketsync.Bucket{Client: source, Name: sourceBucketFlag},
+				Target: bucketsync.Target{
+					Bucket:   bucketsync.Bucket{Client: target, Name: targetBucketFlag},
+					Uploader: s3manager.NewUploaderWithClient(target),
+				},
+				Prefix:    prefixFlag,
+				Workers:   workersFlag,
+				Delete:    deleteFlag,
+				StateFile: stateFileFlag,
+				Log:       log,
+			})
+			if stats != nil {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(stats); encErr != nil {
+					return cli.Exit(fmt.Sprintf("failed to write stats: %s", encErr), 2)
+				}
+			}
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to sync buckets: %s", err), 3)
+			}
+			if stats.Failed > 0 {
+				return cli.Exit(fmt.Sprintf("failed to sync %d objects", stats.Failed), 4)
+			}
+
+			return nil
+		},
+	}
+}
+
+// newS3Client creates the client of the gateway or AWS S3 if the endpoint is empty.
+func newS3Client(endpoint, profile string) (*s3.S3, error) {
+	var cfg aws.Config
+	if endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            cfg,
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get credentials: %w", err)
+	}
+
+	if aws.StringValue(sess.Config.Region) == "" {
+		sess.Config.Region = aws.String(defaultSyncRegion)
+	}
+
+	return s3.New(sess), nil
+}
+
 func parsePolicies(val string) (authmate.ContainerPolicies, error) {
 	if val == "" {
 		return nil, nil
@@ -13,19 +13,37 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/admission"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
 	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/api/notifications"
 	"github.com/nspcc-dev/neofs-s3-gw/api/resolver"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/scanner"
+	"github.com/nspcc-dev/neofs-s3-gw/api/signing"
+	"github.com/nspcc-dev/neofs-s3-gw/api/transform"
+	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/backend"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/revocation"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/bandwidth"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/jobs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/leader"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/ratelimit"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/wallet"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/stat"
@@ -38,14 +56,31 @@ type (
 	// App is the main application structure.
 	App struct {
 		ctr      auth.Center
+		sessions *auth.Sessions
+		revoked  *revocation.List
 		log      *zap.Logger
 		cfg      *viper.Viper
-		pool     *pool.Pool
+		pool     *poolRecycler
 		poolStat *stat.PoolStat
 		gateKey  *keys.PrivateKey
+		prevKeys []*keys.PrivateKey
 		nc       *notifications.Controller
 		obj      layer.Client
 		api      api.Handler
+		creds    *registry.Registry
+		boxes    tokens.Credentials
+		agent    *authmate.Agent
+		backend  auth.CredentialsBackend
+		resolver auth.CredentialsBackend
+		epochs   auth.EpochSource
+		jobs     *jobs.Scheduler
+		batch    *handler.BatchJobs
+		elector  *leader.Elector
+		diag     *diagnostics
+
+		// writePool is set if objects and containers are stored and
+		// deleted with a separate connection pool.
+		writePool *poolRecycler
 
 		servers []Server
 
@@ -78,6 +113,8 @@ type (
 
 	GateMetricsCollector interface {
 		SetHealth(int32)
+		PoolEvent(event, node string)
+		SessionEvent(event, node string)
 		Unregister()
 	}
 
@@ -89,7 +126,17 @@ type (
 )
 
 func newApp(ctx context.Context, log *Logger, v *viper.Viper) *App {
-	conns, key, poolStat := getPool(ctx, log.logger, v)
+	poolPrm, key, poolStat := getPoolParameters(log.logger, v)
+	metrics := newMetrics(log.logger, v, poolStat)
+	st := newStartup(log.logger, v.GetDuration(cfgStartupTimeout))
+	recycler := newPoolRecycler(log.logger, poolPrm, poolStat, getPoolErrorThreshold(v), getConnectionTTL(v, log.logger),
+		getPoolDrainTimeout(v, log.logger), newPoolEventsLogger(log.logger, metrics))
+	peers := fetchPeers(log.logger, v)
+	var conns *pool.Pool
+	st.run(ctx, "dial connection pool", func(ctx context.Context) (err error) {
+		conns, err = recycler.Dial(ctx, peers)
+		return err
+	})
 
 	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
 
@@ -101,42 +148,101 @@ func newApp(ctx context.Context, log *Logger, v *viper.Viper) *App {
 	anonSigner := user.NewAutoIDSignerRFC6979(anonKey.PrivateKey)
 	log.logger.Info("anonymous signer", zap.String("userID", anonSigner.UserID().String()))
 
-	ni, err := conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	var ni netmap.NetworkInfo
+	st.run(ctx, "fetch network info", func(ctx context.Context) (err error) {
+		ni, err = conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
+		return err
+	})
+
+	previousKeys, err := fetchPreviousKeys(v)
 	if err != nil {
-		log.logger.Fatal("newApp: networkInfo", zap.Error(err))
+		log.logger.Fatal("newApp: couldn't load previous keys", zap.Error(err))
+	}
+	for _, prev := range previousKeys {
+		log.logger.Info("access boxes of the previous key are accepted", zap.String("key", hex.EncodeToString(prev.PublicKey().Bytes())))
 	}
 
 	neofsCfg := neofs.Config{
 		MaxObjectSize:        int64(ni.MaxObjectSize()),
 		IsSlicerEnabled:      v.GetBool(cfgSlicerEnabled),
 		IsHomomorphicEnabled: !ni.HomomorphicHashingDisabled(),
+		HedgeToReplicas:      v.GetBool(cfgHedgeToReplicas),
+		NodeDialTimeout:      getConnectTimeout(v),
+		NodeStreamTimeout:    getStreamTimeout(v),
+		PreviousKeys:         previousKeys,
 	}
 
-	// If slicer is disabled, we should use "static" getter, which doesn't make periodic requests to the NeoFS.
+	epochUpdateInterval := v.GetDuration(cfgEpochUpdateInterval)
+	if epochUpdateInterval == 0 {
+		epochUpdateInterval = time.Duration(int64(ni.EpochDuration())/2*ni.MsPerBlock()) * time.Millisecond
+	}
+	// Lifetime of bearer tokens is checked against the actual epoch.
+	actualEpoch := neofs.NewPeriodicGetter(ctx, ni.CurrentEpoch(), epochUpdateInterval, recycler, log.logger)
+
+	// If slicer is disabled, we should use "static" getter.
 	var epochGetter neofs.EpochGetter = ni
 
 	if neofsCfg.IsSlicerEnabled {
-		epochUpdateInterval := v.GetDuration(cfgEpochUpdateInterval)
-
-		if epochUpdateInterval == 0 {
-			epochUpdateInterval = time.Duration(int64(ni.EpochDuration())/2*ni.MsPerBlock()) * time.Millisecond
-		}
-
-		epochGetter = neofs.NewPeriodicGetter(ctx, ni.CurrentEpoch(), epochUpdateInterval, conns, log.logger)
+		epochGetter = actualEpoch
 	}
 
 	neoFS := neofs.NewNeoFS(conns, signer, anonSigner, neofsCfg, epochGetter)
+	recycler.Start(ctx, neoFS)
+
+	var writeRecycler *poolRecycler
+	if v.GetBool(cfgSeparateWritePool) {
+		writeLog := log.logger.With(zap.String("pool", "write"))
+		writeRecycler = newPoolRecycler(writeLog, poolPrm, poolStat, getPoolErrorThreshold(v), getConnectionTTL(v, log.logger),
+			getPoolDrainTimeout(v, log.logger), newPoolEventsLogger(writeLog, metrics))
+		var writeConns *pool.Pool
+		st.run(ctx, "dial write connection pool", func(ctx context.Context) (err error) {
+			writeConns, err = writeRecycler.Dial(ctx, peers)
+			return err
+		})
+		neoFS.SwapWritePool(writeConns)
+		writeRecycler.Start(ctx, poolSwapperFunc(neoFS.SwapWritePool))
+		log.logger.Info("separate connection pool is used for writes")
+	}
 
 	// prepare auth center
-	ctr := auth.New(neofs.NewAuthmateNeoFS(neoFS), key, v.GetStringSlice(cfgAllowedAccessKeyIDPrefixes), getAccessBoxCacheConfig(v, log.logger))
+	authmateNeoFS := neofs.NewAuthmateNeoFS(neoFS)
+	boxes := tokens.New(authmateNeoFS, key, getAccessBoxCacheConfig(v, log.logger), previousKeys...)
+	prevSessionKeys := make([][]byte, 0, len(previousKeys))
+	for _, prev := range previousKeys {
+		prevSessionKeys = append(prevSessionKeys, stsSessionKey(prev))
+	}
+	sessions, err := auth.NewSessions(stsSessionKey(key), prevSessionKeys...)
+	if err != nil {
+		log.logger.Fatal("newApp: couldn't create sessions", zap.Error(err))
+	}
+	baseBackend := newCredentialsBackend(v, log.logger, authmateNeoFS, boxes)
+	credsBackend := auth.NewLifetimeBackend(baseBackend, actualEpoch, getBearerRenewal(v, log.logger, signer))
+	revocations := newRevocationList(ctx, v, log.logger, authmateNeoFS, key)
+	if revocations != nil {
+		credsBackend = auth.NewRevocationBackend(credsBackend, revocations)
+	}
+	ctr := auth.New(credsBackend, sessions, v.GetStringSlice(cfgAllowedAccessKeyIDPrefixes), getMaxClockSkew(v, log.logger), v.GetBool(cfgAllowUnsignedPayload), v.GetBool(cfgSignatureV2Enabled),
+		getSignatureScope(v, log.logger), getReplayCache(v, log.logger))
 
 	app := &App{
 		ctr:      ctr,
+		sessions: sessions,
+		revoked:  revocations,
+		creds:    registry.New(authmateNeoFS),
+		boxes:    boxes,
+		agent:    authmate.New(log.logger, authmateNeoFS),
+		backend:  baseBackend,
+		resolver: credsBackend,
+		epochs:   actualEpoch,
 		log:      log.logger,
 		cfg:      v,
-		pool:     conns,
+		pool:     recycler,
 		poolStat: poolStat,
 		gateKey:  key,
+		prevKeys: previousKeys,
+		metrics:  metrics,
+
+		writePool: writeRecycler,
 
 		webDone: make(chan struct{}, 1),
 		wrkDone: make(chan struct{}, 1),
@@ -151,16 +257,18 @@ func newApp(ctx context.Context, log *Logger, v *viper.Viper) *App {
 }
 
 func (a *App) init(ctx context.Context, anonSigner user.Signer, neoFS *neofs.NeoFS) {
+	metrics.SetSLO(fetchSLOConfig(a.log, a.cfg))
+	metrics.SetSlowRequests(fetchSlowRequestsConfig(a.log, a.cfg))
 	a.initAPI(ctx, anonSigner, neoFS)
-	a.initMetrics()
 	a.initServers(ctx)
+	a.diag = newDiagnostics(a)
 }
 
 func (a *App) initLayer(ctx context.Context, anonSigner user.Signer, neoFS *neofs.NeoFS) {
 	a.initResolver(ctx)
 
 	treeServiceEndpoint := a.cfg.GetString(cfgTreeServiceEndpoint)
-	treeService, err := neofs.NewTreeClient(ctx, treeServiceEndpoint, a.gateKey)
+	treeService, err := neofs.NewTreeClient(ctx, treeServiceEndpoint, a.gateKey, a.prevKeys...)
 	if err != nil {
 		a.log.Fatal("failed to create tree service", zap.Error(err))
 	}
@@ -172,13 +280,40 @@ func (a *App) initLayer(ctx context.Context, anonSigner user.Signer, neoFS *neof
 		Anonymous:   anonSigner.UserID(),
 		Resolver:    a.resolverContainer,
 		TreeService: treeService,
+
+		FirstByteTimeout: getFirstByteTimeout(a.cfg, a.log),
+		Ownership:        getOwnershipConfig(a.cfg, a.log),
+		DistributedLock:  getDistributedLockConfig(a.cfg, a.log),
+		MaxObjectSize:    neoFS.MaxObjectSize(),
+	}
+
+	if path := a.cfg.GetString(cfgWriteJournalPath); path != "" {
+		journal, err := layer.NewFileJournal(path)
+		if err != nil {
+			a.log.Fatal("failed to open write journal", zap.String("path", path), zap.Error(err))
+		}
+		layerCfg.Journal = journal
+		a.log.Info("object writes are journaled", zap.String("path", path))
 	}
 
 	// prepare object layer
 	a.obj = layer.NewLayer(a.log, neoFS, layerCfg)
+	if err = a.obj.ReconcileJournal(ctx); err != nil {
+		a.log.Error("couldn't reconcile write journal", zap.Error(err))
+	}
+	a.checkBucketSettings(ctx)
+	a.checkContainersOwnership(ctx)
+	a.jobs = jobs.NewScheduler(a.log, a.cfg.GetInt(cfgJobsWorkers))
 
 	if a.cfg.GetBool(cfgEnableNATS) {
 		nopts := getNotificationsOptions(a.cfg, a.log)
+		if cnrStr := a.cfg.GetString(cfgNATSDeadLetterContainer); cnrStr != "" {
+			var cnrID cid.ID
+			if err = cnrID.DecodeString(cnrStr); err != nil {
+				a.log.Fatal("invalid dead letter container", zap.String("container", cnrStr), zap.Error(err))
+			}
+			nopts.DeadLetters = notifications.NewNeoFSDeadLetters(neoFS, cnrID, user.NewAutoIDSignerRFC6979(a.gateKey.PrivateKey).UserID())
+		}
 		a.nc, err = notifications.NewController(nopts, a.log)
 		if err != nil {
 			a.log.Fatal("failed to enable notifications", zap.Error(err))
@@ -187,6 +322,245 @@ func (a *App) initLayer(ctx context.Context, anonSigner user.Signer, neoFS *neof
 		if err = a.obj.Initialize(ctx, a.nc); err != nil {
 			a.log.Fatal("couldn't initialize layer", zap.Error(err))
 		}
+
+		if nopts.DeadLetters != nil {
+			a.registerJob(jobs.Job{
+				Name:      jobDeadLettersReplay,
+				Run:       a.replayDeadLetters,
+				Exclusive: true,
+				Settings: jobs.Settings{
+					Interval:    defaultDeadLettersReplayInterval,
+					Rate:        defaultDeadLettersReplayRate,
+					Concurrency: 1,
+				},
+			})
+		}
+	}
+
+	a.registerTrashCleanup(neoFS)
+	a.registerBatchOperations()
+
+	if a.revoked != nil {
+		// every replica refreshes its own list
+		a.registerJob(jobs.Job{
+			Name: jobRevocationsRefresh,
+			Run: func(ctx context.Context, _ *jobs.Task) error {
+				return a.revoked.Refresh(ctx)
+			},
+			Settings: jobs.Settings{
+				Interval:    defaultRevocationsRefreshInterval,
+				Concurrency: 1,
+			},
+		})
+	}
+
+	a.elector = newLeaderElector(a.cfg, a.log, neoFS, a.gateKey)
+}
+
+const (
+	jobDeadLettersReplay  = "dead_letters_replay"
+	jobTrashCleanup       = "trash_cleanup"
+	jobRevocationsRefresh = "revocations_refresh"
+	jobBatchOperations    = "batch_operations"
+)
+
+// registerJob adds the background job to the scheduler, default settings of
+// the job are overridden by `jobs.<name>` config section.
+func (a *App) registerJob(j jobs.Job) {
+	j.Settings = getJobSettings(a.cfg, j.Name, j.Settings)
+	if err := a.jobs.Register(j); err != nil {
+		a.log.Fatal("couldn't register job", zap.String("job", j.Name), zap.Error(err))
+	}
+}
+
+// newLeaderElector creates the elector of the replica running exclusive jobs,
+// it's nil if the election is disabled, every replica runs all jobs then.
+func newLeaderElector(v *viper.Viper, l *zap.Logger, neoFS layer.NeoFS, key *keys.PrivateKey) *leader.Elector {
+	cnrStr := v.GetString(cfgLeaderElectionContainer)
+	if cnrStr == "" {
+		return nil
+	}
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(cnrStr); err != nil {
+		l.Fatal("invalid leader election container", zap.String("container", cnrStr), zap.Error(err))
+	}
+
+	ttl := v.GetDuration(cfgLeaderElectionTTL)
+	if ttl <= 0 {
+		l.Error("invalid leader election ttl, default value is used",
+			zap.String("parameter", cfgLeaderElectionTTL),
+			zap.Duration("value in config", ttl),
+			zap.Duration("default", defaultLeaderElectionTTL))
+		ttl = defaultLeaderElectionTTL
+	}
+
+	return leader.NewElector(neoFS, leader.Config{
+		Container: cnrID,
+		Owner:     user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID(),
+		Name:      cfgJobs,
+		// replicas can share the key
+		CandidateID: hex.EncodeToString(key.PublicKey().Bytes()) + "/" + uuid.NewString(),
+		TTL:         ttl,
+	}, l)
+}
+
+// replayDeadLetters sends events failed to be delivered again.
+func (a *App) replayDeadLetters(ctx context.Context, task *jobs.Task) error {
+	letters, err := a.nc.DeadLetters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, letter := range letters {
+		letter := letter
+		if err = task.Go(ctx, func(ctx context.Context) error {
+			return a.nc.ReplayDeadLetter(ctx, letter)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerTrashCleanup schedules removal of payloads which undelete window has
+// passed in buckets of configured owners.
+func (a *App) registerTrashCleanup(neoFS layer.NeoFS) {
+	ownersStr := a.cfg.GetStringSlice(cfgTrashCleanupOwners)
+	if len(ownersStr) == 0 {
+		return
+	}
+
+	owners := make([]user.ID, len(ownersStr))
+	for i := range ownersStr {
+		if err := owners[i].DecodeString(ownersStr[i]); err != nil {
+			a.log.Fatal("invalid owner of buckets to clean up trash in", zap.String("owner", ownersStr[i]), zap.Error(err))
+		}
+	}
+
+	dryRun := a.cfg.GetBool(cfgTrashCleanupDryRun)
+	if dryRun {
+		a.log.Warn("trash cleanup is a dry run, payloads of trashed versions aren't removed")
+	}
+
+	var reports *trashCleanupReports
+	if cnrStr := a.cfg.GetString(cfgTrashCleanupReportContainer); cnrStr != "" {
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(cnrStr); err != nil {
+			a.log.Fatal("invalid trash cleanup report container", zap.String("container", cnrStr), zap.Error(err))
+		}
+		reports = &trashCleanupReports{neoFS: neoFS, cnrID: cnrID, owner: user.NewAutoIDSignerRFC6979(a.gateKey.PrivateKey).UserID()}
+	}
+
+	run := func(ctx context.Context, task *jobs.Task) error {
+		started := time.Now()
+		buckets, err := a.obj.OwnersBuckets(ctx, owners)
+		if err != nil {
+			return err
+		}
+
+		run := newTrashCleanupRun(dryRun)
+		for _, bktInfo := range buckets {
+			bktInfo := bktInfo
+			if err = task.Go(ctx, func(ctx context.Context) error {
+				entries, err := a.obj.CleanupTrash(ctx, bktInfo, dryRun)
+				run.add(bktInfo, entries, err)
+				return err
+			}); err != nil {
+				break
+			}
+		}
+		task.Wait()
+
+		a.log.Info("trash cleanup finished", append(run.summary(), zap.Duration("duration", time.Since(started)))...)
+		if reports != nil {
+			if reportErr := reports.put(ctx, started, run); reportErr != nil {
+				a.log.Error("couldn't put trash cleanup report", zap.Error(reportErr))
+			}
+		}
+
+		return err
+	}
+
+	a.registerJob(jobs.Job{
+		Name:      jobTrashCleanup,
+		Run:       run,
+		Exclusive: true,
+		Settings: jobs.Settings{
+			Interval:    defaultTrashCleanupInterval,
+			Concurrency: 1,
+		},
+	})
+}
+
+// registerBatchOperations schedules processing of batch jobs submitted to
+// the gateway, jobs are kept in memory, so every replica processes its own.
+func (a *App) registerBatchOperations() {
+	if !a.cfg.GetBool(cfgBatchOperationsEnabled) {
+		return
+	}
+
+	a.batch = handler.NewBatchJobs(a.cfg.GetInt(cfgBatchOperationsMaxJobs))
+
+	a.registerJob(jobs.Job{
+		Name: jobBatchOperations,
+		Run: func(ctx context.Context, task *jobs.Task) error {
+			return a.batch.Run(ctx, task.Go)
+		},
+		Settings: jobs.Settings{
+			Interval:    defaultBatchOperationsInterval,
+			Concurrency: defaultBatchOperationsConcurrency,
+		},
+	})
+}
+
+// checkBucketSettings looks for buckets with settings written by a newer
+// gateway version. Such buckets are read-only, the gateway refuses to start
+// if it's configured so.
+func (a *App) checkBucketSettings(ctx context.Context) {
+	ownersStr := a.cfg.GetStringSlice(cfgSettingsCheckOwners)
+	if len(ownersStr) == 0 {
+		return
+	}
+
+	owners := make([]user.ID, len(ownersStr))
+	for i := range ownersStr {
+		if err := owners[i].DecodeString(ownersStr[i]); err != nil {
+			a.log.Fatal("invalid owner of checked buckets", zap.String("owner", ownersStr[i]), zap.Error(err))
+		}
+	}
+
+	buckets, err := a.obj.UnsupportedSettingsBuckets(ctx, owners)
+	if err != nil {
+		a.log.Warn("couldn't check bucket settings versions", zap.Error(err))
+		return
+	}
+	if len(buckets) == 0 {
+		return
+	}
+
+	msg := "some buckets have settings of newer version than supported, upgrade the gateway"
+	fields := []zap.Field{zap.Strings("buckets", buckets), zap.Int("supported_version", data.SettingsSchemaVersion)}
+	if a.cfg.GetBool(cfgSettingsCheckRefuseStart) {
+		a.log.Fatal(msg, fields...)
+	}
+	a.log.Warn(msg+", buckets are read-only", fields...)
+}
+
+// checkContainersOwnership resolves configured buckets to make sure their
+// containers are owned by the gateway or shared with it.
+func (a *App) checkContainersOwnership(ctx context.Context) {
+	for _, bucket := range a.cfg.GetStringSlice(cfgOwnershipBuckets) {
+		_, err := a.obj.GetBucketInfo(ctx, bucket)
+		if err == nil {
+			continue
+		}
+
+		if s3errors.IsS3Error(err, s3errors.ErrAccessDenied) {
+			a.log.Fatal("container of the bucket isn't owned by the gateway", zap.String("bucket", bucket))
+		}
+		a.log.Warn("couldn't check ownership of the bucket container", zap.String("bucket", bucket), zap.Error(err))
 	}
 }
 
@@ -216,10 +590,10 @@ func (a *App) initAPI(ctx context.Context, anonSigner user.Signer, neoFS *neofs.
 	a.initHandler()
 }
 
-func (a *App) initMetrics() {
-	gateMetricsProvider := newGateMetrics(neofs.NewPoolStatistic(a.poolStat))
+func newMetrics(log *zap.Logger, v *viper.Viper, poolStat *stat.PoolStat) *appMetrics {
+	gateMetricsProvider := newGateMetrics(neofs.NewPoolStatistic(poolStat))
 	gateMetricsProvider.SetGWVersion(version.Version)
-	a.metrics = newAppMetrics(a.log, gateMetricsProvider, a.cfg.GetBool(cfgPrometheusEnabled))
+	return newAppMetrics(log, gateMetricsProvider, v.GetBool(cfgPrometheusEnabled))
 }
 
 func (a *App) initResolver(ctx context.Context) {
@@ -249,14 +623,44 @@ func newMaxClients(cfg *viper.Viper) api.MaxClients {
 	return api.NewMaxClientsMiddleware(maxClientsCount, maxClientsDeadline)
 }
 
-func getPool(ctx context.Context, logger *zap.Logger, cfg *viper.Viper) (*pool.Pool, *keys.PrivateKey, *stat.PoolStat) {
+// fetchKey loads the gateway private key either from the raw WIF or hex
+// string or from the NEP-6 wallet account.
+func fetchKey(cfg *viper.Viper) (*keys.PrivateKey, error) {
+	if rawKey := cfg.GetString(cfgWalletKey); len(rawKey) != 0 {
+		if len(cfg.GetString(cfgWalletPath)) != 0 {
+			return nil, fmt.Errorf("%s and %s are mutually exclusive", cfgWalletKey, cfgWalletPath)
+		}
+		return wallet.GetKeyFromString(rawKey)
+	}
+
+	password := wallet.GetPassword(cfg, cfgWalletPassphrase)
+	return wallet.GetKeyFromPath(cfg.GetString(cfgWalletPath), cfg.GetString(cfgWalletAddress), password)
+}
+
+// fetchPreviousKeys loads previous private keys of the gateway in WIF or hex
+// format, access boxes and session tokens issued for them are still accepted.
+func fetchPreviousKeys(cfg *viper.Viper) ([]*keys.PrivateKey, error) {
+	rawKeys := cfg.GetStringSlice(cfgWalletPrevKeys)
+	res := make([]*keys.PrivateKey, len(rawKeys))
+	for i := range rawKeys {
+		key, err := wallet.GetKeyFromString(rawKeys[i])
+		if err != nil {
+			return nil, fmt.Errorf("previous key %d: %w", i, err)
+		}
+		res[i] = key
+	}
+
+	return res, nil
+}
+
+// getPoolParameters prepares connection pool parameters except the list of
+// nodes, which is fetched separately on every (re)dial.
+func getPoolParameters(logger *zap.Logger, cfg *viper.Viper) (pool.InitParameters, *keys.PrivateKey, *stat.PoolStat) {
 	poolStat := stat.NewPoolStatistic()
 
 	var prm pool.InitParameters
-	prm.SetStatisticCallback(poolStat.OperationCallback)
 
-	password := wallet.GetPassword(cfg, cfgWalletPassphrase)
-	key, err := wallet.GetKeyFromPath(cfg.GetString(cfgWalletPath), cfg.GetString(cfgWalletAddress), password)
+	key, err := fetchKey(cfg)
 	if err != nil {
 		logger.Fatal("could not load NeoFS private key", zap.Error(err))
 	}
@@ -264,21 +668,8 @@ func getPool(ctx context.Context, logger *zap.Logger, cfg *viper.Viper) (*pool.P
 	prm.SetSigner(user.NewAutoIDSignerRFC6979(key.PrivateKey))
 	logger.Info("using credentials", zap.String("NeoFS", hex.EncodeToString(key.PublicKey().Bytes())))
 
-	for _, peer := range fetchPeers(logger, cfg) {
-		prm.AddNode(peer)
-	}
-
-	connTimeout := cfg.GetDuration(cfgConnectTimeout)
-	if connTimeout <= 0 {
-		connTimeout = defaultConnectTimeout
-	}
-	prm.SetNodeDialTimeout(connTimeout)
-
-	streamTimeout := cfg.GetDuration(cfgStreamTimeout)
-	if streamTimeout <= 0 {
-		streamTimeout = defaultStreamTimeout
-	}
-	prm.SetNodeStreamTimeout(streamTimeout)
+	prm.SetNodeDialTimeout(getConnectTimeout(cfg))
+	prm.SetNodeStreamTimeout(getStreamTimeout(cfg))
 
 	healthCheckTimeout := cfg.GetDuration(cfgHealthcheckTimeout)
 	if healthCheckTimeout <= 0 {
@@ -292,23 +683,218 @@ func getPool(ctx context.Context, logger *zap.Logger, cfg *viper.Viper) (*pool.P
 	}
 	prm.SetClientRebalanceInterval(rebalanceInterval)
 
-	errorThreshold := cfg.GetUint32(cfgPoolErrorThreshold)
+	prm.SetErrorThreshold(getPoolErrorThreshold(cfg))
+	prm.SetLogger(logger)
+
+	return prm, key, poolStat
+}
+
+func getPoolErrorThreshold(v *viper.Viper) uint32 {
+	errorThreshold := v.GetUint32(cfgPoolErrorThreshold)
 	if errorThreshold <= 0 {
 		errorThreshold = defaultPoolErrorThreshold
 	}
-	prm.SetErrorThreshold(errorThreshold)
-	prm.SetLogger(logger)
 
-	p, err := pool.NewPool(prm)
+	return errorThreshold
+}
+
+func getConnectionTTL(v *viper.Viper, l *zap.Logger) time.Duration {
+	ttl := v.GetDuration(cfgConnectionTTL)
+	if ttl < 0 {
+		l.Error("invalid connection TTL, connection recycling is disabled",
+			zap.String("parameter", cfgConnectionTTL),
+			zap.Duration("value in config", ttl))
+		return 0
+	}
+
+	return ttl
+}
+
+func getPoolDrainTimeout(v *viper.Viper, l *zap.Logger) time.Duration {
+	if !v.IsSet(cfgPoolDrainTimeout) {
+		return defaultPoolDrainTimeout
+	}
+
+	timeout := v.GetDuration(cfgPoolDrainTimeout)
+	if timeout < 0 {
+		l.Error("invalid connection drain timeout, default value is used",
+			zap.String("parameter", cfgPoolDrainTimeout),
+			zap.Duration("value in config", timeout),
+			zap.Duration("default", defaultPoolDrainTimeout))
+		return defaultPoolDrainTimeout
+	}
+
+	return timeout
+}
+
+func getConnectTimeout(v *viper.Viper) time.Duration {
+	connTimeout := v.GetDuration(cfgConnectTimeout)
+	if connTimeout <= 0 {
+		connTimeout = defaultConnectTimeout
+	}
+
+	return connTimeout
+}
+
+func getStreamTimeout(v *viper.Viper) time.Duration {
+	streamTimeout := v.GetDuration(cfgStreamTimeout)
+	if streamTimeout <= 0 {
+		streamTimeout = defaultStreamTimeout
+	}
+
+	return streamTimeout
+}
+
+func getMaxHeaderCount(v *viper.Viper, l *zap.Logger) int {
+	if !v.IsSet(cfgMaxHeaderCount) {
+		return api.DefaultMaxHeaderCount
+	}
+
+	count := v.GetInt(cfgMaxHeaderCount)
+	if count < 0 {
+		l.Error("invalid max header count, default value is used",
+			zap.String("parameter", cfgMaxHeaderCount),
+			zap.Int("value in config", count),
+			zap.Int("default", api.DefaultMaxHeaderCount))
+		return api.DefaultMaxHeaderCount
+	}
+	if count == 0 {
+		l.Warn("the number of request headers isn't limited")
+	}
+
+	return count
+}
+
+func getMaxClockSkew(v *viper.Viper, l *zap.Logger) time.Duration {
+	skew := v.GetDuration(cfgMaxClockSkew)
+	if skew < 0 {
+		l.Error("invalid max clock skew, default value is used",
+			zap.String("parameter", cfgMaxClockSkew),
+			zap.Duration("value in config", skew),
+			zap.Duration("default", defaultMaxClockSkew))
+		return defaultMaxClockSkew
+	}
+	if skew == 0 {
+		l.Warn("clock skew check of request signatures is disabled")
+	}
+
+	return skew
+}
+
+func getReplayCache(v *viper.Viper, l *zap.Logger) *auth.ReplayCache {
+	if !v.GetBool(cfgReplayProtectionEnabled) {
+		return nil
+	}
+
+	window := v.GetDuration(cfgReplayProtectionWindow)
+	if window <= 0 {
+		l.Fatal("invalid replay protection window", zap.String("parameter", cfgReplayProtectionWindow),
+			zap.Duration("value in config", window))
+	}
+	size := v.GetInt(cfgReplayProtectionSize)
+	if size <= 0 {
+		l.Fatal("invalid replay protection size", zap.String("parameter", cfgReplayProtectionSize),
+			zap.Int("value in config", size))
+	}
+
+	return auth.NewReplayCache(size, window)
+}
+
+func getSignatureScope(v *viper.Viper, l *zap.Logger) auth.Scope {
+	mismatch, err := auth.ParseRegionMismatch(v.GetString(cfgSignatureRegionMismatch))
+	if err != nil {
+		l.Fatal("invalid signature scope", zap.String("parameter", cfgSignatureRegionMismatch), zap.Error(err))
+	}
+
+	scope := auth.Scope{
+		Region:            v.GetString(cfgSignatureRegion),
+		AdditionalRegions: v.GetStringSlice(cfgSignatureAdditionalRegions),
+		RegionMismatch:    mismatch,
+		Services:          v.GetStringSlice(cfgSignatureServices),
+	}
+	if scope.Region == "" && len(scope.AdditionalRegions) != 0 {
+		l.Fatal("additional regions of signature scopes are set without the canonical one",
+			zap.String("parameter", cfgSignatureRegion))
+	}
+
+	return scope
+}
+
+func getFirstByteTimeout(v *viper.Viper, l *zap.Logger) time.Duration {
+	timeout := v.GetDuration(cfgFirstByteTimeout)
+	if timeout < 0 {
+		l.Error("invalid first byte timeout, hedged reading is disabled",
+			zap.String("parameter", cfgFirstByteTimeout),
+			zap.Duration("value in config", timeout))
+		return 0
+	}
+
+	return timeout
+}
+
+func getOwnershipConfig(v *viper.Viper, l *zap.Logger) layer.OwnershipConfig {
+	mode, err := layer.ParseOwnershipMode(v.GetString(cfgOwnershipMode))
 	if err != nil {
-		logger.Fatal("failed to create connection pool", zap.Error(err))
+		l.Fatal("invalid container ownership mode", zap.Error(err))
+	}
+
+	ownersStr := v.GetStringSlice(cfgOwnershipSharedOwners)
+	owners := make([]user.ID, len(ownersStr))
+	for i := range ownersStr {
+		if err = owners[i].DecodeString(ownersStr[i]); err != nil {
+			l.Fatal("invalid shared owner of containers", zap.String("owner", ownersStr[i]), zap.Error(err))
+		}
+	}
+
+	return layer.OwnershipConfig{Mode: mode, SharedOwners: owners}
+}
+
+// getUnsupportedOperations returns modes of unsupported operation classes,
+// classes without the mode use the common one.
+func getUnsupportedOperations(v *viper.Viper, l *zap.Logger) handler.UnsupportedOperations {
+	parse := func(param, def string) handler.UnsupportedMode {
+		value := v.GetString(param)
+		if value == "" {
+			value = def
+		}
+		mode, err := handler.ParseUnsupportedMode(value)
+		if err != nil {
+			l.Fatal("invalid unsupported operations mode", zap.String("parameter", param), zap.Error(err))
+		}
+		return mode
 	}
 
-	if err = p.Dial(ctx); err != nil {
-		logger.Fatal("failed to dial connection pool", zap.Error(err))
+	mode := string(parse(cfgUnsupportedMode, ""))
+	return handler.UnsupportedOperations{
+		ConfigReads:   parse(cfgUnsupportedConfigReads, mode),
+		ConfigWrites:  parse(cfgUnsupportedConfigWrites, mode),
+		ConfigDeletes: parse(cfgUnsupportedConfigDeletes, mode),
 	}
+}
 
-	return p, key, poolStat
+func getDistributedLockConfig(v *viper.Viper, l *zap.Logger) layer.DistributedLockConfig {
+	cfg := layer.DistributedLockConfig{
+		Enabled: v.GetBool(cfgDistributedLockEnabled),
+		TTL:     v.GetDuration(cfgDistributedLockTTL),
+		Timeout: v.GetDuration(cfgDistributedLockTimeout),
+	}
+
+	if cfg.TTL <= 0 {
+		l.Error("invalid distributed lock ttl, default value is used",
+			zap.String("parameter", cfgDistributedLockTTL),
+			zap.Duration("value in config", cfg.TTL),
+			zap.Duration("default", defaultDistributedLockTTL))
+		cfg.TTL = defaultDistributedLockTTL
+	}
+	if cfg.Timeout < 0 {
+		l.Error("invalid distributed lock timeout, default value is used",
+			zap.String("parameter", cfgDistributedLockTimeout),
+			zap.Duration("value in config", cfg.Timeout),
+			zap.Duration("default", defaultDistributedLockTimeout))
+		cfg.Timeout = defaultDistributedLockTimeout
+	}
+
+	return cfg
 }
 
 func newPlacementPolicy(defaultPolicy string, regionPolicyFilepath string) (*placementPolicy, error) {
@@ -399,6 +985,28 @@ func (m *appMetrics) SetHealth(status int32) {
 	m.provider.SetHealth(status)
 }
 
+func (m *appMetrics) PoolEvent(event, node string) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.PoolEvent(event, node)
+}
+
+func (m *appMetrics) SessionEvent(event, node string) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.SessionEvent(event, node)
+}
+
 func (m *appMetrics) Shutdown() {
 	m.mu.Lock()
 	if m.enabled {
@@ -434,17 +1042,50 @@ func (a *App) setHealthStatus() {
 // Serve runs HTTP server to handle S3 API requests.
 func (a *App) Serve(ctx context.Context) {
 	// Attach S3 API:
-	domains := a.cfg.GetStringSlice(cfgListenDomains)
-	a.log.Info("fetch domains, prepare to use API", zap.Strings("domains", domains))
+	domains := api.Domains{
+		VirtualHosted: a.cfg.GetStringSlice(cfgListenDomains),
+		PathStyle:     a.cfg.GetStringSlice(cfgPathStyleDomains),
+	}
+	a.log.Info("fetch domains, prepare to use API", zap.Strings("domains", domains.VirtualHosted),
+		zap.Strings("path_style_domains", domains.PathStyle))
+	anon := api.AnonymousAccess{
+		Enabled:       a.cfg.GetBool(cfgAnonymousEnabled),
+		PublicBuckets: a.cfg.GetStringSlice(cfgAnonymousPublicBuckets),
+	}
+	if !anon.Enabled {
+		a.log.Info("anonymous requests are denied")
+	} else if len(anon.PublicBuckets) > 0 {
+		a.log.Info("anonymous requests are restricted to reads of public buckets", zap.Strings("buckets", anon.PublicBuckets))
+	}
+	rps, burst := a.cfg.GetFloat64(cfgRateLimitRPS), a.cfg.GetInt(cfgRateLimitBurst)
+	limiter := ratelimit.NewLimiter(rps, burst)
+	if limiter != nil {
+		a.log.Info("request rate of access keys is limited", zap.Float64("rps", rps), zap.Int("burst", burst))
+	}
 	router := mux.NewRouter().SkipClean(true).UseEncodedPath()
-	api.Attach(router, domains, a.maxClients, a.api, a.ctr, a.log)
+	limits := api.RequestLimits{MaxHeaderCount: getMaxHeaderCount(a.cfg, a.log)}
+	api.Attach(router, domains, a.maxClients, limits, a.api, a.ctr, anon, limiter, a.pool, a.log)
 
 	// Use mux.Router as http.Handler
 	srv := new(http.Server)
 	srv.Handler = router
+	perConn, global := a.cfg.GetInt64(cfgBandwidthUploadPerConnection), a.cfg.GetInt64(cfgBandwidthUploadGlobal)
+	if perConn > 0 || global > 0 {
+		a.log.Info("upload bandwidth is limited", zap.Int64("per_connection", perConn), zap.Int64("global", global))
+		srv.ConnContext = bandwidth.ConnContext(perConn)
+		srv.Handler = bandwidth.Handler(router, bandwidth.NewLimiter(global))
+	}
+	// Larger headers are rejected by the API with S3 error, this limit
+	// protects from reading huge ones at all.
+	srv.MaxHeaderBytes = 2 * api.MaxHeaderSize
 	srv.ErrorLog = zap.NewStdLog(a.log)
 
 	a.startServices()
+	if a.elector != nil {
+		a.jobs.SetLeader(a.elector)
+		go a.elector.Run(ctx)
+	}
+	a.jobs.Start(ctx)
 
 	for i := range a.servers {
 		go func(i int) {
@@ -458,6 +1099,8 @@ func (a *App) Serve(ctx context.Context) {
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGHUP)
+	diagSigs := make(chan os.Signal, 1)
+	signal.Notify(diagSigs, syscall.SIGUSR1)
 
 LOOP:
 	for {
@@ -466,6 +1109,8 @@ LOOP:
 			break LOOP
 		case <-sigs:
 			a.configReload(ctx)
+		case <-diagSigs:
+			a.dumpDiagnostics()
 		}
 	}
 
@@ -500,6 +1145,11 @@ func (a *App) configReload(ctx context.Context) {
 		a.log.Warn("failed to update resolvers", zap.Error(err))
 	}
 
+	a.pool.UpdatePeers(ctx, fetchPeers(a.log, a.cfg))
+	if a.writePool != nil {
+		a.writePool.UpdatePeers(ctx, fetchPeers(a.log, a.cfg))
+	}
+
 	if err := a.updateServers(); err != nil {
 		a.log.Warn("failed to reload server parameters", zap.Error(err))
 	}
@@ -525,6 +1175,9 @@ func (a *App) updateSettings() {
 	if err := a.settings.policies.update(getDefaultPolicyValue(a.cfg), a.cfg.GetString(cfgPolicyRegionMapFile)); err != nil {
 		a.log.Warn("policies won't be updated", zap.Error(err))
 	}
+
+	metrics.SetSLO(fetchSLOConfig(a.log, a.cfg))
+	metrics.SetSlowRequests(fetchSlowRequestsConfig(a.log, a.cfg))
 }
 
 func (a *App) startServices() {
@@ -537,6 +1190,38 @@ func (a *App) startServices() {
 	prometheusService := NewPrometheusService(a.cfg, a.log)
 	a.services = append(a.services, prometheusService)
 	go prometheusService.Start()
+
+	buckets, _ := a.api.(handler.BucketReconciler)
+	rec := &reconciler{
+		log:     a.log.With(zap.String("service", "Admin")),
+		agent:   a.agent,
+		key:     a.gateKey,
+		creds:   a.creds,
+		backend: a.resolver,
+		revoked: a.revoked,
+		buckets: buckets,
+	}
+
+	pools := []*poolRecycler{a.pool}
+	if a.writePool != nil {
+		pools = append(pools, a.writePool)
+	}
+
+	adminService := NewAdminService(a.cfg, a.log, a.obj, a.creds, a.boxes, a.revoked, a.nc, a.jobs, a.diag, rec, a.pool, pools)
+	a.services = append(a.services, adminService)
+	go adminService.Start()
+
+	imdsService := NewIMDSService(a.cfg, a.log, a.sessions)
+	a.services = append(a.services, imdsService)
+	go imdsService.Start()
+
+	stsService := NewSTSService(a.cfg, a.log, a.ctr, a.sessions, a.agent, a.gateKey)
+	a.services = append(a.services, stsService)
+	go stsService.Start()
+
+	credentialsAPIService := NewCredentialsAPIService(a.cfg, a.log, a.agent, a.gateKey, a.backend, a.epochs, a.revoked)
+	a.services = append(a.services, credentialsAPIService)
+	go credentialsAPIService.Start()
 }
 
 func (a *App) initServers(ctx context.Context) {
@@ -564,7 +1249,7 @@ func (a *App) updateServers() error {
 		}
 
 		if serverInfo.TLS.Enabled {
-			if err := a.servers[i].UpdateCert(serverInfo.TLS.CertFile, serverInfo.TLS.KeyFile); err != nil {
+			if err := a.servers[i].UpdateCert(serverInfo.TLS); err != nil {
 				return fmt.Errorf("failed to update tls certs: %w", err)
 			}
 		}
@@ -597,9 +1282,34 @@ func getNotificationsOptions(v *viper.Viper, l *zap.Logger) *notifications.Optio
 	cfg.TLSAuthPrivateKeyFilePath = v.GetString(cfgNATSAuthPrivateKeyFile)
 	cfg.RootCAFiles = v.GetStringSlice(cfgNATSRootCAFiles)
 
+	if secret := v.GetString(cfgNATSSigningSecret); secret != "" {
+		// secret isn't empty, so there is no error
+		cfg.Signer, _ = signing.NewHMAC(secret)
+	}
+
 	return &cfg
 }
 
+func getJobSettings(v *viper.Viper, name string, defaults jobs.Settings) jobs.Settings {
+	key := cfgJobs + "." + name + "."
+
+	settings := defaults
+	if v.IsSet(key + "interval") {
+		settings.Interval = v.GetDuration(key + "interval")
+	}
+	if v.IsSet(key + "priority") {
+		settings.Priority = v.GetInt(key + "priority")
+	}
+	if v.IsSet(key + "rate") {
+		settings.Rate = v.GetFloat64(key + "rate")
+	}
+	if v.IsSet(key + "concurrency") {
+		settings.Concurrency = v.GetInt(key + "concurrency")
+	}
+
+	return settings
+}
+
 func getCacheOptions(v *viper.Viper, l *zap.Logger) *layer.CachesConfig {
 	cacheCfg := layer.DefaultCachesConfigs(l)
 
@@ -663,12 +1373,118 @@ func getAccessBoxCacheConfig(v *viper.Viper, l *zap.Logger) *cache.Config {
 	return cacheCfg
 }
 
+// Credentials backends resolving access key IDs.
+const (
+	credentialsBackendAccessBox = "accessbox"
+	credentialsBackendFile      = "file"
+	credentialsBackendVault     = "vault"
+	credentialsBackendNeoFS     = "neofs"
+)
+
+func newCredentialsBackend(v *viper.Viper, l *zap.Logger, neoFS *neofs.AuthmateNeoFS, boxes tokens.Credentials) auth.CredentialsBackend {
+	names := v.GetStringSlice(cfgCredentialsBackends)
+	if len(names) == 0 {
+		l.Fatal("no credentials backends configured", zap.String("parameter", cfgCredentialsBackends))
+	}
+
+	backends := make([]auth.CredentialsBackend, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case credentialsBackendAccessBox:
+			backends = append(backends, auth.NewAccessBoxBackend(boxes))
+		case credentialsBackendFile:
+			fileBackend, err := backend.NewFile(v.GetString(cfgCredentialsFilePath))
+			if err != nil {
+				l.Fatal("couldn't load credentials file", zap.String("parameter", cfgCredentialsFilePath), zap.Error(err))
+			}
+			backends = append(backends, fileBackend)
+		case credentialsBackendVault:
+			vaultBackend, err := backend.NewVault(backend.VaultConfig{
+				Address: v.GetString(cfgCredentialsVaultAddress),
+				Token:   v.GetString(cfgCredentialsVaultToken),
+				Mount:   v.GetString(cfgCredentialsVaultMount),
+				Path:    v.GetString(cfgCredentialsVaultPath),
+				Timeout: v.GetDuration(cfgCredentialsVaultTimeout),
+				Cache:   getAccessBoxCacheConfig(v, l),
+			})
+			if err != nil {
+				l.Fatal("couldn't init vault credentials backend", zap.Error(err))
+			}
+			backends = append(backends, vaultBackend)
+		case credentialsBackendNeoFS:
+			var cnrID cid.ID
+			if err := cnrID.DecodeString(v.GetString(cfgCredentialsNeoFSContainer)); err != nil {
+				l.Fatal("invalid credentials container", zap.String("parameter", cfgCredentialsNeoFSContainer), zap.Error(err))
+			}
+			backends = append(backends, backend.NewNeoFS(neoFS, boxes, cnrID, getAccessBoxCacheConfig(v, l)))
+		default:
+			l.Fatal("unknown credentials backend", zap.String("parameter", cfgCredentialsBackends), zap.String("backend", name))
+		}
+	}
+
+	return auth.NewChainBackend(backends...)
+}
+
+// newRevocationList loads revoked access keys from the configured store, it
+// returns nil if revocation isn't configured.
+func newRevocationList(ctx context.Context, v *viper.Viper, l *zap.Logger, neoFS *neofs.AuthmateNeoFS, key *keys.PrivateKey) *revocation.List {
+	var store revocation.Store
+	switch path, cnrStr := v.GetString(cfgRevocationFile), v.GetString(cfgRevocationContainer); {
+	case path != "" && cnrStr != "":
+		l.Fatal("only one revocation store can be configured",
+			zap.String("file", cfgRevocationFile), zap.String("container", cfgRevocationContainer))
+	case path != "":
+		store = revocation.NewFileStore(path)
+	case cnrStr != "":
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(cnrStr); err != nil {
+			l.Fatal("invalid revocation container", zap.String("parameter", cfgRevocationContainer), zap.Error(err))
+		}
+		store = revocation.NewNeoFSStore(neoFS, cnrID, user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID())
+	default:
+		return nil
+	}
+
+	list := revocation.NewList(store)
+	// revoked keys mustn't be accepted, so the gateway doesn't start without them
+	if err := list.Refresh(ctx); err != nil {
+		l.Fatal("couldn't load revoked access keys", zap.Error(err))
+	}
+
+	return list
+}
+
+// getBearerRenewal returns the renewal of bearer tokens issued by the gateway
+// key, it's nil if the renewal is disabled.
+func getBearerRenewal(v *viper.Viper, l *zap.Logger, signer user.Signer) *auth.BearerRenewal {
+	if !v.GetBool(cfgBearerRenewalEnabled) {
+		return nil
+	}
+
+	lifetime := v.GetUint64(cfgBearerRenewalLifetime)
+	if lifetime == 0 {
+		l.Error("invalid bearer token renewal lifetime, default value is used",
+			zap.String("parameter", cfgBearerRenewalLifetime),
+			zap.Uint64("default", defaultBearerRenewalLifetime))
+		lifetime = defaultBearerRenewalLifetime
+	}
+
+	return &auth.BearerRenewal{Signer: signer, Lifetime: lifetime}
+}
+
 func (a *App) initHandler() {
 	cfg := &handler.Config{
-		Policy:             a.settings.policies,
-		DefaultMaxAge:      handler.DefaultMaxAge,
-		NotificatorEnabled: a.cfg.GetBool(cfgEnableNATS),
-		CopiesNumber:       handler.DefaultCopiesNumber,
+		Policy:              a.settings.policies,
+		DefaultMaxAge:       handler.DefaultMaxAge,
+		NotificatorEnabled:  a.cfg.GetBool(cfgEnableNATS),
+		CopiesNumber:        handler.DefaultCopiesNumber,
+		ListingExport:       a.cfg.GetBool(cfgListingExportEnabled),
+		ObjectSearch:        a.cfg.GetBool(cfgObjectSearchEnabled),
+		GzipUploads:         a.cfg.GetBool(cfgGzipUploadsEnabled),
+		GzipMaxBufferedSize: a.cfg.GetInt64(cfgGzipUploadsMaxBufferedSize),
+		BatchJobs:           a.batch,
+		PrivacySalt:         []byte(a.cfg.GetString(cfgPrivacySalt)),
+		Unsupported:         getUnsupportedOperations(a.cfg, a.log),
 	}
 
 	if a.cfg.IsSet(cfgDefaultMaxAge) {
@@ -692,12 +1508,112 @@ func (a *App) initHandler() {
 	}
 
 	var err error
+	if mode := a.cfg.GetString(cfgScannerMode); mode != "" {
+		signer, err := signing.New(&signing.Config{
+			Type:            a.cfg.GetString(cfgScannerSigningType),
+			Secret:          a.cfg.GetString(cfgScannerSigningSecret),
+			AccessKeyID:     a.cfg.GetString(cfgScannerSigningAccessKeyID),
+			SecretAccessKey: a.cfg.GetString(cfgScannerSigningSecretAccessKey),
+			Region:          a.cfg.GetString(cfgScannerSigningRegion),
+			Service:         a.cfg.GetString(cfgScannerSigningService),
+		})
+		if err != nil {
+			a.log.Fatal("could not initialize scanner request signing", zap.Error(err))
+		}
+
+		cfg.Scanner, err = scanner.New(&scanner.Options{
+			URL:     a.cfg.GetString(cfgScannerURL),
+			Timeout: a.cfg.GetDuration(cfgScannerTimeout),
+			Signer:  signer,
+		})
+		if err != nil {
+			a.log.Fatal("could not initialize scanner", zap.Error(err))
+		}
+		cfg.ScanMode = mode
+		cfg.ScanMaxSize = a.cfg.GetInt64(cfgScannerMaxSize)
+		cfg.ScanFailOpen = a.cfg.GetBool(cfgScannerFailOpen)
+		a.log.Info("uploaded objects are scanned", zap.String("mode", mode),
+			zap.String("url", a.cfg.GetString(cfgScannerURL)))
+	}
+
+	cfg.Admission = a.fetchAdmission()
+	cfg.AdmissionFailOpen = a.cfg.GetBool(cfgAdmissionFailOpen)
+
+	if a.cfg.GetBool(cfgTransformEnabled) {
+		cacheCfg := cache.DefaultTransformedConfig(a.log)
+		cacheCfg.Lifetime = getLifetime(a.cfg, a.log, cfgTransformedCacheLifetime, cacheCfg.Lifetime)
+		cacheCfg.Size = getSize(a.cfg, a.log, cfgTransformedCacheSize, cacheCfg.Size)
+		if maxBytes := a.cfg.GetInt64(cfgTransformedCacheMaxBytes); maxBytes > 0 {
+			cacheCfg.MaxBytes = maxBytes
+		}
+
+		cfg.Transform = transform.NewPipeline(&transform.Config{
+			MaxSourceSize: a.cfg.GetInt64(cfgTransformMaxSourceSize),
+			MaxConcurrent: a.cfg.GetInt(cfgTransformMaxConcurrent),
+			Cache:         cacheCfg,
+		})
+	}
+
 	a.api, err = handler.New(a.log, a.obj, a.nc, cfg)
 	if err != nil {
 		a.log.Fatal("could not initialize API handler", zap.Error(err))
 	}
 }
 
+// fetchAdmission creates admission webhooks of the configured buckets, all
+// webhooks share the timeout and request signing.
+func (a *App) fetchAdmission() map[string]handler.Admitter {
+	var (
+		admitters map[string]handler.Admitter
+		signer    signing.RequestSigner
+		err       error
+	)
+	for i := 0; ; i++ {
+		key := cfgAdmissionWebhooks + "." + strconv.Itoa(i) + "."
+		webhookURL := a.cfg.GetString(key + "url")
+		if webhookURL == "" {
+			break
+		}
+
+		if admitters == nil {
+			admitters = make(map[string]handler.Admitter)
+			signer, err = signing.New(&signing.Config{
+				Type:            a.cfg.GetString(cfgAdmissionSigningType),
+				Secret:          a.cfg.GetString(cfgAdmissionSigningSecret),
+				AccessKeyID:     a.cfg.GetString(cfgAdmissionSigningAccessKeyID),
+				SecretAccessKey: a.cfg.GetString(cfgAdmissionSigningSecretAccessKey),
+				Region:          a.cfg.GetString(cfgAdmissionSigningRegion),
+				Service:         a.cfg.GetString(cfgAdmissionSigningService),
+			})
+			if err != nil {
+				a.log.Fatal("could not initialize admission webhook request signing", zap.Error(err))
+			}
+		}
+
+		webhook, err := admission.New(&admission.Options{
+			URL:     webhookURL,
+			Timeout: a.cfg.GetDuration(cfgAdmissionTimeout),
+			Signer:  signer,
+		})
+		if err != nil {
+			a.log.Fatal("could not initialize admission webhook", zap.String("url", webhookURL), zap.Error(err))
+		}
+
+		buckets := a.cfg.GetStringSlice(key + "buckets")
+		for _, bucket := range buckets {
+			if _, ok := admitters[bucket]; ok {
+				a.log.Fatal("several admission webhooks for the bucket", zap.String("bucket", bucket))
+			}
+			admitters[bucket] = webhook
+		}
+
+		a.log.Info("uploads are validated by admission webhook", zap.String("url", webhookURL),
+			zap.Strings("buckets", buckets))
+	}
+
+	return admitters
+}
+
 func readRegionMap(filePath string) (map[string]string, error) {
 	regionMap := make(map[string]string)
 
@@ -0,0 +1,748 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/api/notifications"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/revocation"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/jobs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/limits"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Roles of admin API tokens, every role is allowed to do everything the
+// previous one can.
+const (
+	adminRoleViewer = "viewer"
+	adminRoleIssuer = "issuer"
+	adminRoleAdmin  = "admin"
+)
+
+var adminRoleLevels = map[string]int{
+	adminRoleViewer: 1,
+	adminRoleIssuer: 2,
+	adminRoleAdmin:  3,
+}
+
+type (
+	adminHandler struct {
+		log     *zap.Logger
+		audit   *zap.Logger
+		obj     layer.Client
+		creds   *registry.Registry
+		boxes   tokens.Credentials
+		revoked *revocation.List
+		nc      *notifications.Controller
+		jobs    *jobs.Scheduler
+		diag    *diagnostics
+		rec     *reconciler
+		storage api.StorageState
+		// pools are connection pools sharing the configured peers.
+		pools  []*poolRecycler
+		tokens []adminToken
+		// region is the default region of post policy forms.
+		region string
+	}
+
+	// adminToken is a bearer token of the admin API.
+	adminToken struct {
+		name  string
+		value string
+		role  string
+	}
+
+	// statusWriter remembers the response status for the audit log.
+	statusWriter struct {
+		http.ResponseWriter
+		status int
+	}
+
+	// bucketUsageResponse is a JSON representation of bucket statistics.
+	bucketUsageResponse struct {
+		Bucket   string                 `json:"bucket"`
+		Objects  uint64                 `json:"objects"`
+		Versions uint64                 `json:"versions"`
+		Bytes    uint64                 `json:"bytes"`
+		Requests metrics.BucketRequests `json:"requests"`
+	}
+
+	// tagUsageResponse is a JSON representation of bucket statistics grouped
+	// by the tag.
+	tagUsageResponse struct {
+		Tag    string          `json:"tag"`
+		Groups []tagUsageGroup `json:"groups"`
+	}
+
+	tagUsageGroup struct {
+		Value    string                 `json:"value"`
+		Buckets  []string               `json:"buckets"`
+		Objects  uint64                 `json:"objects"`
+		Versions uint64                 `json:"versions"`
+		Bytes    uint64                 `json:"bytes"`
+		Requests metrics.BucketRequests `json:"requests"`
+	}
+
+	// credentialsResponse is a JSON representation of the credentials
+	// registry.
+	credentialsResponse struct {
+		Container   string            `json:"container"`
+		Credentials []registry.Record `json:"credentials"`
+	}
+
+	// revocationsResponse is a JSON representation of revoked access keys.
+	revocationsResponse struct {
+		Revocations []revocation.Entry `json:"revocations"`
+	}
+
+	// revokeRequest is a JSON representation of the access key to revoke.
+	revokeRequest struct {
+		AccessKeyID string `json:"access_key_id"`
+		Reason      string `json:"reason"`
+	}
+
+	// deadLettersResponse is a JSON representation of notifications failed
+	// to be delivered.
+	deadLettersResponse struct {
+		DeadLetters []*notifications.DeadLetter `json:"dead_letters"`
+	}
+
+	// peersResponse is a JSON representation of connection peers.
+	peersResponse struct {
+		Peers []peerResponse `json:"peers"`
+	}
+
+	peerResponse struct {
+		Address  string  `json:"address"`
+		Priority int     `json:"priority"`
+		Weight   float64 `json:"weight"`
+		Excluded bool    `json:"excluded"`
+	}
+
+	// peerRequest is a JSON representation of the connection peer to exclude
+	// or return to service.
+	peerRequest struct {
+		Address string `json:"address"`
+	}
+
+	// jobsResponse is a JSON representation of background jobs states.
+	jobsResponse struct {
+		Jobs []jobs.Status `json:"jobs"`
+	}
+
+	// diagnosticsResponse is a JSON representation of the dumped diagnostics
+	// bundle.
+	diagnosticsResponse struct {
+		Path string `json:"path"`
+	}
+
+	// postPolicyRequest is a JSON representation of restrictions of the
+	// browser-based upload.
+	postPolicyRequest struct {
+		AccessKeyID       string `json:"access_key_id"`
+		Bucket            string `json:"bucket"`
+		Prefix            string `json:"prefix"`
+		ContentTypePrefix string `json:"content_type_prefix"`
+		MinSize           int64  `json:"min_size"`
+		MaxSize           int64  `json:"max_size"`
+		Expires           string `json:"expires"`
+		Region            string `json:"region"`
+	}
+)
+
+// NewAdminService creates a new service exposing administrative endpoints,
+// bucket usage statistics for dashboards in particular. If tokens are
+// configured, requests must be authorized with one of them and all calls are
+// logged by the audit logger. Otherwise, only read-only endpoints are served.
+func NewAdminService(v *viper.Viper, l *zap.Logger, obj layer.Client, creds *registry.Registry, boxes tokens.Credentials, revoked *revocation.List, nc *notifications.Controller, scheduler *jobs.Scheduler, diag *diagnostics, rec *reconciler, storage api.StorageState, pools []*poolRecycler) *Service {
+	log := l.With(zap.String("service", "Admin"))
+	adminTokens, err := fetchAdminTokens(v)
+	if err != nil {
+		log.Fatal("invalid admin API tokens", zap.Error(err))
+	}
+
+	h := &adminHandler{
+		log:     log,
+		audit:   l.Named("audit").With(zap.String("service", "Admin")),
+		obj:     obj,
+		creds:   creds,
+		boxes:   boxes,
+		revoked: revoked,
+		nc:      nc,
+		jobs:    scheduler,
+		diag:    diag,
+		rec:     rec,
+		storage: storage,
+		pools:   pools,
+		tokens:  adminTokens,
+		region:  v.GetString(cfgSignatureRegion),
+	}
+
+	if len(h.tokens) == 0 {
+		log.Warn("admin API tokens aren't configured, authorization is disabled and only read-only endpoints are served")
+	}
+
+	addr := v.GetString(cfgAdminAddress)
+	enabled := v.GetBool(cfgAdminEnabled)
+	if enabled && len(h.tokens) == 0 && !isLoopbackAddress(addr) {
+		log.Error("admin API isn't started, it must be bound to a loopback address if tokens aren't configured", zap.String("address", addr))
+		enabled = false
+	}
+
+	return &Service{
+		Server: &http.Server{
+			Addr:    addr,
+			Handler: h.router(),
+		},
+		enabled:     enabled,
+		serviceType: "Admin",
+		log:         log,
+	}
+}
+
+func (h *adminHandler) router() http.Handler {
+	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path("/buckets/{bucket}/usage").HandlerFunc(h.authorize(adminRoleViewer, h.bucketUsage))
+	router.Methods(http.MethodGet).Path("/usage/tags/{tag}").HandlerFunc(h.authorize(adminRoleViewer, h.tagUsage))
+	router.Methods(http.MethodGet).Path("/credentials").HandlerFunc(h.authorize(adminRoleIssuer, h.credentials))
+	router.Methods(http.MethodGet).Path("/revocations").HandlerFunc(h.authorize(adminRoleIssuer, h.revocations))
+	router.Methods(http.MethodPost).Path("/revocations").HandlerFunc(h.authorize(adminRoleIssuer, h.revoke))
+	router.Methods(http.MethodPost).Path("/post-policy").HandlerFunc(h.authorize(adminRoleIssuer, h.postPolicy))
+	router.Methods(http.MethodGet).Path("/notifications/dead-letters").HandlerFunc(h.authorize(adminRoleViewer, h.deadLetters))
+	router.Methods(http.MethodPost).Path("/notifications/dead-letters/replay").HandlerFunc(h.authorize(adminRoleAdmin, h.replayDeadLetters))
+	router.Methods(http.MethodGet).Path("/storage").HandlerFunc(h.authorize(adminRoleViewer, h.storageStatus))
+	router.Methods(http.MethodGet).Path("/peers").HandlerFunc(h.authorize(adminRoleViewer, h.listPeers))
+	router.Methods(http.MethodPost).Path("/peers/exclude").HandlerFunc(h.authorize(adminRoleAdmin, h.excludePeer))
+	router.Methods(http.MethodPost).Path("/peers/include").HandlerFunc(h.authorize(adminRoleAdmin, h.includePeer))
+	router.Methods(http.MethodGet).Path("/jobs").HandlerFunc(h.authorize(adminRoleViewer, h.listJobs))
+	router.Methods(http.MethodPost).Path("/jobs/{job}/pause").HandlerFunc(h.authorize(adminRoleAdmin, h.pauseJob))
+	router.Methods(http.MethodPost).Path("/jobs/{job}/resume").HandlerFunc(h.authorize(adminRoleAdmin, h.resumeJob))
+	router.Methods(http.MethodPost).Path("/diagnostics").HandlerFunc(h.authorize(adminRoleAdmin, h.dumpDiagnostics))
+	router.Methods(http.MethodPost).Path("/reconcile").HandlerFunc(h.authorize(adminRoleAdmin, h.reconcile))
+	router.NotFoundHandler = h.authorize(adminRoleViewer, http.NotFound)
+
+	return router
+}
+
+// isLoopbackAddress checks if the listen address is bound to the loopback
+// interface only.
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authorize allows the request if it has a bearer token of the role or of a
+// more privileged one and logs the call. Requests changing anything are
+// refused if tokens aren't configured.
+func (h *adminHandler) authorize(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		token, err := h.authenticate(r)
+
+		defer func() {
+			h.audit.Info("admin call",
+				zap.String("remote", r.RemoteAddr),
+				zap.String("method", r.Method),
+				zap.String("uri", r.URL.RequestURI()),
+				zap.String("token", token.name),
+				zap.String("role", token.role),
+				zap.Int("status", sw.status))
+		}()
+
+		switch {
+		case err != nil:
+			sw.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(sw, err.Error(), http.StatusUnauthorized)
+		case len(h.tokens) == 0 && r.Method != http.MethodGet:
+			http.Error(sw, "admin API tokens must be configured", http.StatusForbidden)
+		case len(h.tokens) != 0 && adminRoleLevels[token.role] < adminRoleLevels[role]:
+			http.Error(sw, "role '"+role+"' is required", http.StatusForbidden)
+		default:
+			next(sw, r)
+		}
+	}
+}
+
+// authenticate returns the token of the request, it's empty if tokens aren't
+// configured.
+func (h *adminHandler) authenticate(r *http.Request) (adminToken, error) {
+	if len(h.tokens) == 0 {
+		return adminToken{}, nil
+	}
+
+	header := r.Header.Get(api.Authorization)
+	if !strings.HasPrefix(header, "Bearer ") {
+		return adminToken{}, errors.New("no bearer token")
+	}
+	value := header[len("Bearer "):]
+	if value == "" {
+		return adminToken{}, errors.New("no bearer token")
+	}
+
+	for _, token := range h.tokens {
+		if subtle.ConstantTimeCompare([]byte(token.value), []byte(value)) == 1 {
+			return token, nil
+		}
+	}
+
+	return adminToken{}, errors.New("invalid bearer token")
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (h *adminHandler) bucketUsage(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["bucket"]
+
+	bktInfo, err := h.obj.GetBucketInfo(r.Context(), name)
+	if err != nil {
+		if s3errors.IsS3Error(err, s3errors.ErrNoSuchBucket) {
+			http.Error(w, "bucket not found", http.StatusNotFound)
+			return
+		}
+		h.log.Error("could not get bucket info", zap.String("bucket", name), zap.Error(err))
+		http.Error(w, "could not get bucket info", http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := h.obj.GetBucketUsage(r.Context(), bktInfo)
+	if err != nil {
+		h.log.Error("could not get bucket usage", zap.String("bucket", name), zap.Error(err))
+		http.Error(w, "could not get bucket usage", http.StatusInternalServerError)
+		return
+	}
+
+	res := bucketUsageResponse{
+		Bucket:   name,
+		Objects:  usage.Objects,
+		Versions: usage.Versions,
+		Bytes:    usage.Bytes,
+		Requests: metrics.LoadBucketRequests(name),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write bucket usage", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) tagUsage(w http.ResponseWriter, r *http.Request) {
+	tag := mux.Vars(r)["tag"]
+
+	owners := r.URL.Query()["owner"]
+	if len(owners) == 0 {
+		http.Error(w, "no owners specified", http.StatusBadRequest)
+		return
+	}
+
+	prm := &layer.UsageByTagParams{
+		Owners: make([]user.ID, len(owners)),
+		Tag:    tag,
+	}
+	for i := range owners {
+		if err := prm.Owners[i].DecodeString(owners[i]); err != nil {
+			http.Error(w, "invalid owner: "+owners[i], http.StatusBadRequest)
+			return
+		}
+	}
+
+	groups, err := h.obj.GetUsageByTag(r.Context(), prm)
+	if err != nil {
+		h.log.Error("could not get usage by tag", zap.String("tag", tag), zap.Error(err))
+		http.Error(w, "could not get usage by tag", http.StatusInternalServerError)
+		return
+	}
+
+	res := tagUsageResponse{
+		Tag:    tag,
+		Groups: make([]tagUsageGroup, 0, len(groups)),
+	}
+	for _, group := range groups {
+		resGroup := tagUsageGroup{
+			Value:    group.Value,
+			Buckets:  group.Buckets,
+			Objects:  group.Objects,
+			Versions: group.Versions,
+			Bytes:    group.Bytes,
+		}
+		for _, bucket := range group.Buckets {
+			requests := metrics.LoadBucketRequests(bucket)
+			resGroup.Requests.Requests += requests.Requests
+			resGroup.Requests.Errors += requests.Errors
+			resGroup.Requests.InputBytes += requests.InputBytes
+			resGroup.Requests.OutputBytes += requests.OutputBytes
+		}
+		res.Groups = append(res.Groups, resGroup)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write usage by tag", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) credentials(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(query.Get("container")); err != nil {
+		http.Error(w, "invalid container: "+query.Get("container"), http.StatusBadRequest)
+		return
+	}
+
+	filter := registry.Filter{
+		Issuer:      query.Get("issuer"),
+		Gate:        query.Get("gate"),
+		Scope:       query.Get("scope"),
+		Description: query.Get("description"),
+	}
+	if active := query.Get("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			http.Error(w, "invalid active: "+active, http.StatusBadRequest)
+			return
+		}
+		if isActive {
+			filter.ActiveAt = time.Now()
+		}
+	}
+
+	records, err := h.creds.List(r.Context(), cnrID, filter)
+	if err != nil {
+		h.log.Error("could not list credentials", zap.Stringer("container", cnrID), zap.Error(err))
+		http.Error(w, "could not list credentials", http.StatusInternalServerError)
+		return
+	}
+
+	res := credentialsResponse{
+		Container:   cnrID.EncodeToString(),
+		Credentials: records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write credentials", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) revocations(w http.ResponseWriter, _ *http.Request) {
+	if h.revoked == nil {
+		http.Error(w, "revocation is disabled", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(revocationsResponse{Revocations: h.revoked.Entries()}); err != nil {
+		h.log.Error("could not write revocations", zap.Error(err))
+	}
+}
+
+// revoke rejects the access key at once, other gateways sharing the
+// revocation store reject it after refreshing their lists.
+func (h *adminHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	if h.revoked == nil {
+		http.Error(w, "revocation is disabled", http.StatusNotFound)
+		return
+	}
+
+	var req revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.AccessKeyID == "" {
+		http.Error(w, "no access key id specified", http.StatusBadRequest)
+		return
+	}
+
+	token, _ := h.authenticate(r)
+	entry, err := h.revoked.Revoke(r.Context(), revocation.Entry{
+		AccessKeyID: req.AccessKeyID,
+		RevokedBy:   token.name,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		h.log.Error("could not revoke access key", zap.String("access_key_id", req.AccessKeyID), zap.Error(err))
+		http.Error(w, "could not revoke access key", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("access key is revoked", zap.String("access_key_id", entry.AccessKeyID),
+		zap.String("revoked_by", entry.RevokedBy), zap.String("reason", entry.Reason))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(entry); err != nil {
+		h.log.Error("could not write revocation", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) postPolicy(w http.ResponseWriter, r *http.Request) {
+	var req postPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expires, err := time.ParseDuration(req.Expires)
+	if err != nil || expires <= 0 || expires > limits.MaxPreSignedLifetime {
+		http.Error(w, "invalid expires: "+req.Expires, http.StatusBadRequest)
+		return
+	}
+
+	var addr oid.Address
+	if err = addr.DecodeString(strings.ReplaceAll(req.AccessKeyID, "0", "/")); err != nil {
+		http.Error(w, "invalid access key id: "+req.AccessKeyID, http.StatusBadRequest)
+		return
+	}
+
+	if _, err = h.obj.GetBucketInfo(r.Context(), req.Bucket); err != nil {
+		if s3errors.IsS3Error(err, s3errors.ErrNoSuchBucket) {
+			http.Error(w, "bucket not found", http.StatusNotFound)
+			return
+		}
+		h.log.Error("could not get bucket info", zap.String("bucket", req.Bucket), zap.Error(err))
+		http.Error(w, "could not get bucket info", http.StatusInternalServerError)
+		return
+	}
+
+	box, err := h.boxes.GetBox(r.Context(), addr)
+	if err != nil {
+		h.log.Error("could not get access box", zap.String("access_key_id", req.AccessKeyID), zap.Error(err))
+		http.Error(w, "could not get credentials", http.StatusNotFound)
+		return
+	}
+
+	region := req.Region
+	if region == "" {
+		region = h.region
+	}
+
+	now := time.Now()
+	form, err := auth.NewPostPolicyForm(auth.PostPolicyParams{
+		AccessKeyID:       req.AccessKeyID,
+		SecretKey:         box.Gate.AccessKey,
+		Region:            region,
+		Bucket:            req.Bucket,
+		KeyPrefix:         req.Prefix,
+		ContentTypePrefix: req.ContentTypePrefix,
+		MinSize:           req.MinSize,
+		MaxSize:           req.MaxSize,
+		Expiration:        now.Add(expires),
+	}, now)
+	if err != nil {
+		http.Error(w, "invalid policy: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(form); err != nil {
+		h.log.Error("could not write post policy", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) deadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.nc == nil {
+		http.Error(w, "notifications are disabled", http.StatusNotFound)
+		return
+	}
+
+	letters, err := h.nc.DeadLetters(r.Context())
+	if err != nil {
+		if errors.Is(err, notifications.ErrDeadLettersDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.log.Error("could not list dead letters", zap.Error(err))
+		http.Error(w, "could not list dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(deadLettersResponse{DeadLetters: letters}); err != nil {
+		h.log.Error("could not write dead letters", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) replayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.nc == nil {
+		http.Error(w, "notifications are disabled", http.StatusNotFound)
+		return
+	}
+
+	res, err := h.nc.ReplayDeadLetters(r.Context())
+	if err != nil {
+		if errors.Is(err, notifications.ErrDeadLettersDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.log.Error("could not replay dead letters", zap.Error(err))
+		http.Error(w, "could not replay dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write replay result", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) storageStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.StorageReport(h.storage)); err != nil {
+		h.log.Error("could not write storage status", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) listPeers(w http.ResponseWriter, _ *http.Request) {
+	var res peersResponse
+	if len(h.pools) != 0 {
+		for _, peer := range h.pools[0].Peers() {
+			res.Peers = append(res.Peers, peerResponse{
+				Address:  peer.address,
+				Priority: peer.priority,
+				Weight:   peer.weight,
+				Excluded: peer.excluded,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write peers", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) excludePeer(w http.ResponseWriter, r *http.Request) {
+	h.changePeer(w, r, true)
+}
+
+func (h *adminHandler) includePeer(w http.ResponseWriter, r *http.Request) {
+	h.changePeer(w, r, false)
+}
+
+// changePeer excludes the connection peer from all pools or returns it to
+// service and redials them.
+func (h *adminHandler) changePeer(w http.ResponseWriter, r *http.Request, exclude bool) {
+	var req peerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Address == "" {
+		http.Error(w, "no address specified", http.StatusBadRequest)
+		return
+	}
+
+	for _, p := range h.pools {
+		err := p.ExcludePeer(r.Context(), req.Address, exclude)
+		switch {
+		case err == nil:
+		case errors.Is(err, errUnknownPeer):
+			http.Error(w, "peer not found", http.StatusNotFound)
+			return
+		case errors.Is(err, errNoPeers):
+			http.Error(w, "all peers would be excluded", http.StatusConflict)
+			return
+		default:
+			h.log.Error("could not redial connection pool", zap.String("address", req.Address), zap.Error(err))
+			http.Error(w, "could not redial connection pool", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.log.Info("connection peer exclusion changed", zap.String("address", req.Address), zap.Bool("excluded", exclude))
+	h.listPeers(w, r)
+}
+
+func (h *adminHandler) listJobs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(jobsResponse{Jobs: h.jobs.Status()}); err != nil {
+		h.log.Error("could not write jobs", zap.Error(err))
+	}
+}
+
+func (h *adminHandler) pauseJob(w http.ResponseWriter, r *http.Request) {
+	h.changeJob(w, r, h.jobs.Pause)
+}
+
+func (h *adminHandler) resumeJob(w http.ResponseWriter, r *http.Request) {
+	h.changeJob(w, r, h.jobs.Resume)
+}
+
+func (h *adminHandler) changeJob(w http.ResponseWriter, r *http.Request, change func(string) error) {
+	if err := change(mux.Vars(r)["job"]); err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.log.Error("could not change job", zap.Error(err))
+		http.Error(w, "could not change job", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *adminHandler) dumpDiagnostics(w http.ResponseWriter, _ *http.Request) {
+	path, err := h.diag.Dump()
+	if err != nil {
+		h.log.Error("could not dump diagnostics", zap.Error(err))
+		http.Error(w, "could not dump diagnostics", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("diagnostics dumped", zap.String("path", path))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(diagnosticsResponse{Path: path}); err != nil {
+		h.log.Error("could not write diagnostics response", zap.Error(err))
+	}
+}
+
+// reconcile brings credentials and buckets to the state of the document, the
+// response is 422 Unprocessable Entity if some of them failed.
+func (h *adminHandler) reconcile(w http.ResponseWriter, r *http.Request) {
+	var state desiredState
+	if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+		http.Error(w, "invalid desired state: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, ok := h.rec.reconcile(r.Context(), state)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write reconciliation results", zap.Error(err))
+	}
+}
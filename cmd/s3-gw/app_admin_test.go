@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFetchAdminTokens(t *testing.T) {
+	v := viper.New()
+	v.Set(cfgAdminTokens+".0.name", "dashboard")
+	v.Set(cfgAdminTokens+".0.value", "viewer-token")
+	v.Set(cfgAdminTokens+".0.role", adminRoleViewer)
+
+	tokens, err := fetchAdminTokens(v)
+	require.NoError(t, err)
+	require.Equal(t, []adminToken{{name: "dashboard", value: "viewer-token", role: adminRoleViewer}}, tokens)
+
+	v.Set(cfgAdminTokens+".1.name", "ops")
+	v.Set(cfgAdminTokens+".1.value", "ops-token")
+	v.Set(cfgAdminTokens+".1.role", "root")
+
+	_, err = fetchAdminTokens(v)
+	require.Error(t, err)
+}
+
+func TestAdminAuthorize(t *testing.T) {
+	tokens := []adminToken{
+		{name: "dashboard", value: "viewer-token", role: adminRoleViewer},
+		{name: "ops", value: "admin-token", role: adminRoleAdmin},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		tokens []adminToken
+		method string
+		role   string
+		token  string
+		header string
+		status int
+	}{
+		{name: "no tokens, read-only", method: http.MethodGet, role: adminRoleIssuer, status: http.StatusOK},
+		{name: "no tokens, mutating", method: http.MethodPost, role: adminRoleAdmin, status: http.StatusForbidden},
+		{name: "no bearer token", tokens: tokens, method: http.MethodGet, role: adminRoleViewer, status: http.StatusUnauthorized},
+		{name: "invalid bearer token", tokens: tokens, method: http.MethodGet, role: adminRoleViewer, token: "invalid", status: http.StatusUnauthorized},
+		{name: "no bearer scheme", tokens: tokens, method: http.MethodGet, role: adminRoleViewer, header: "viewer-token", status: http.StatusUnauthorized},
+		{name: "another scheme", tokens: tokens, method: http.MethodGet, role: adminRoleViewer, header: "Basic viewer-token", status: http.StatusUnauthorized},
+		{name: "insufficient role", tokens: tokens, method: http.MethodPost, role: adminRoleAdmin, token: "viewer-token", status: http.StatusForbidden},
+		{name: "sufficient role", tokens: tokens, method: http.MethodGet, role: adminRoleViewer, token: "viewer-token", status: http.StatusOK},
+		{name: "more privileged role", tokens: tokens, method: http.MethodPost, role: adminRoleIssuer, token: "admin-token", status: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &adminHandler{log: zap.NewNop(), audit: zap.NewNop(), tokens: tc.tokens}
+
+			var called bool
+			handler := h.authorize(tc.role, func(http.ResponseWriter, *http.Request) { called = true })
+
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.status == http.StatusOK, called)
+			if tc.status == http.StatusUnauthorized {
+				require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAdminMutatingRoutesWithoutTokens(t *testing.T) {
+	router := (&adminHandler{log: zap.NewNop(), audit: zap.NewNop()}).router()
+
+	for _, path := range []string{
+		"/revocations",
+		"/post-policy",
+		"/notifications/dead-letters/replay",
+		"/jobs/lifecycle/pause",
+		"/jobs/lifecycle/resume",
+		"/diagnostics",
+		"/reconcile",
+		"/peers/exclude",
+		"/peers/include",
+	} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+			require.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+}
+
+type storageStateMock api.StorageStatus
+
+func (s storageStateMock) StorageStatus() api.StorageStatus {
+	return api.StorageStatus(s)
+}
+
+func TestAdminStorageStatus(t *testing.T) {
+	status := api.StorageStatus{Nodes: 1, Unhealthy: []api.UnhealthyNode{{Address: "node1:8080", Errors: 10, LastError: "connection refused"}}}
+	router := (&adminHandler{log: zap.NewNop(), audit: zap.NewNop(), storage: storageStateMock(status)}).router()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/storage", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res api.StorageStatus
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.Equal(t, status, res)
+}
+
+func TestAdminPeers(t *testing.T) {
+	ctx := context.Background()
+	tokens := []adminToken{{name: "ops", value: "admin-token", role: adminRoleAdmin}}
+
+	r, _, _ := newTestPoolRecycler(time.Millisecond)
+	_, err := r.Dial(ctx, []connectionPeer{
+		{address: "node1:8080", priority: 1, weight: 1},
+		{address: "node2:8080", priority: 1, weight: 1},
+	})
+	require.NoError(t, err)
+	r.Start(ctx, poolSwapperFunc(func(*pool.Pool) <-chan struct{} { return nil }))
+
+	router := (&adminHandler{log: zap.NewNop(), audit: zap.NewNop(), tokens: tokens, pools: []*poolRecycler{r}}).router()
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(http.MethodPost, "/peers/exclude", `{"address": "node1:8080"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res peersResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.Equal(t, []peerResponse{
+		{Address: "node1:8080", Priority: 1, Weight: 1, Excluded: true},
+		{Address: "node2:8080", Priority: 1, Weight: 1},
+	}, res.Peers)
+	require.Equal(t, []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}}, r.peers)
+
+	require.Equal(t, http.StatusConflict, call(http.MethodPost, "/peers/exclude", `{"address": "node2:8080"}`).Code)
+	require.Equal(t, http.StatusNotFound, call(http.MethodPost, "/peers/exclude", `{"address": "node3:8080"}`).Code)
+	require.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/peers/include", `{}`).Code)
+
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/peers/include", `{"address": "node1:8080"}`).Code)
+	w = call(http.MethodGet, "/peers", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.False(t, res.Peers[0].Excluded)
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"localhost:8087": true,
+		"127.0.0.1:8087": true,
+		"[::1]:8087":     true,
+		":8087":          false,
+		"0.0.0.0:8087":   false,
+		"10.0.0.1:8087":  false,
+		"localhost":      false,
+	} {
+		require.Equal(t, expected, isLoopbackAddress(addr), addr)
+	}
+}
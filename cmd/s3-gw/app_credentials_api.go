@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/credsapi"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/revocation"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/session"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	credentialsAPI struct {
+		credsapi.UnimplementedCredentialsServer
+
+		log     *zap.Logger
+		audit   *zap.Logger
+		agent   *authmate.Agent
+		key     *keys.PrivateKey
+		backend auth.CredentialsBackend
+		epochs  auth.EpochSource
+		revoked *revocation.List
+		// clients are common names of allowed client certificates, any
+		// certificate signed by the CA is allowed if it's empty.
+		clients map[string]struct{}
+	}
+
+	// credentialsAPIClient is the common name of the client certificate of
+	// the call.
+	credentialsAPIClient struct{}
+)
+
+var sessionVerbNames = []struct {
+	verb session.ContainerVerb
+	name string
+}{
+	{session.VerbContainerPut, "PUT"},
+	{session.VerbContainerDelete, "DELETE"},
+	{session.VerbContainerSetEACL, "SETEACL"},
+}
+
+// NewCredentialsAPIService creates a new service serving the gRPC API of
+// credentials issuance, revocation and introspection. Clients must present TLS
+// certificates signed by the configured CA, credentials are issued with the
+// gateway key and resolved by the backend. All calls are logged by the audit
+// logger.
+func NewCredentialsAPIService(v *viper.Viper, l *zap.Logger, agent *authmate.Agent, key *keys.PrivateKey, backend auth.CredentialsBackend, epochs auth.EpochSource, revoked *revocation.List) *Service {
+	log := l.With(zap.String("service", "CredentialsAPI"))
+	svc := &Service{
+		Server: &http.Server{
+			Addr: v.GetString(cfgCredentialsAPIAddress),
+		},
+		enabled:     v.GetBool(cfgCredentialsAPIEnabled),
+		serviceType: "CredentialsAPI",
+		log:         log,
+	}
+	if !svc.enabled {
+		return svc
+	}
+
+	tlsConfig, err := fetchCredentialsAPITLSConfig(v)
+	if err != nil {
+		log.Error("invalid credentials API TLS configuration, service is disabled", zap.Error(err))
+		svc.enabled = false
+		return svc
+	}
+
+	h := &credentialsAPI{
+		log:     log,
+		audit:   l.Named("audit").With(zap.String("service", "CredentialsAPI")),
+		agent:   agent,
+		key:     key,
+		backend: backend,
+		epochs:  epochs,
+		revoked: revoked,
+		clients: make(map[string]struct{}),
+	}
+	for _, client := range v.GetStringSlice(cfgCredentialsAPIClients) {
+		h.clients[client] = struct{}{}
+	}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(h.authorize))
+	credsapi.RegisterCredentialsServer(srv, h)
+
+	// gRPC requires HTTP/2, it's negotiated with TLS
+	svc.Handler = srv
+	svc.TLSConfig = tlsConfig
+
+	return svc
+}
+
+// fetchCredentialsAPITLSConfig loads the certificate of the service and the CA
+// certificates client ones are verified with.
+func fetchCredentialsAPITLSConfig(v *viper.Viper) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(v.GetString(cfgCredentialsAPICertFile), v.GetString(cfgCredentialsAPIKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("load certificate: %w", err)
+	}
+
+	caFile := v.GetString(cfgCredentialsAPIClientCAFile)
+	if caFile == "" {
+		return nil, fmt.Errorf("no client CA, '%s' must be set", cfgCredentialsAPIClientCAFile)
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates in client CA file '%s'", caFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// authorize allows the call if the client certificate is allowed and logs the
+// call.
+func (h *credentialsAPI) authorize(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+	var client, remote string
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			client = tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+		}
+	}
+
+	defer func() {
+		h.audit.Info("credentials API call",
+			zap.String("remote", remote),
+			zap.String("method", info.FullMethod),
+			zap.String("client", client),
+			zap.Stringer("code", status.Code(err)))
+	}()
+
+	if client == "" {
+		return nil, status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	if _, ok := h.clients[client]; len(h.clients) != 0 && !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "client '%s' isn't allowed", client)
+	}
+
+	return handler(context.WithValue(ctx, credentialsAPIClient{}, client), req)
+}
+
+// Issue creates credentials with the bearer token issued by the gateway, so
+// they're renewable. The access box is available to the gateway and the
+// gateways of the request.
+func (h *credentialsAPI) Issue(ctx context.Context, req *credsapi.IssueRequest) (*credsapi.IssueResponse, error) {
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(req.GetContainerId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid container id '%s'", req.GetContainerId())
+	}
+
+	if req.GetLifetime() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "lifetime must be positive")
+	}
+	lifetime := time.Duration(req.GetLifetime()) * time.Second
+
+	gates := []*keys.PublicKey{h.key.PublicKey()}
+	for _, raw := range req.GetGatePublicKeys() {
+		gateKey, err := keys.NewPublicKeyFromBytes(raw, elliptic.P256())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid gate public key: %v", err)
+		}
+		gates = append(gates, gateKey)
+	}
+
+	secret, err := h.agent.Issue(ctx, &authmate.IssueSecretOptions{
+		Container:         authmate.ContainerOptions{ID: cnrID},
+		NeoFSKey:          h.key,
+		GatesPublicKeys:   gates,
+		EACLRules:         req.GetEaclRules(),
+		SessionTokenRules: req.GetSessionTokenRules(),
+		SkipSessionRules:  req.GetSkipSessionRules(),
+		Lifetime:          lifetime,
+		ContainerPolicies: req.GetContainerPolicies(),
+		Description:       req.GetDescription(),
+		AccessKeyID:       req.GetAccessKeyId(),
+		AccessPolicy:      req.GetAccessPolicy(),
+		Renewable:         true,
+	})
+	if err != nil {
+		h.log.Error("could not issue credentials", zap.Stringer("container", cnrID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "could not issue credentials: %v", err)
+	}
+
+	h.log.Info("credentials are issued", zap.String("access_key_id", secret.AccessKeyID),
+		zap.Any("client", ctx.Value(credentialsAPIClient{})))
+
+	return &credsapi.IssueResponse{
+		AccessKeyId:     secret.AccessKeyID,
+		SecretAccessKey: secret.SecretAccessKey,
+		ContainerId:     secret.ContainerID,
+		ExpiresAt:       time.Now().Add(lifetime).Unix(),
+	}, nil
+}
+
+// Revoke rejects the access key at once, other gateways sharing the
+// revocation store reject it after refreshing their lists.
+func (h *credentialsAPI) Revoke(ctx context.Context, req *credsapi.RevokeRequest) (*credsapi.RevokeResponse, error) {
+	if h.revoked == nil {
+		return nil, status.Error(codes.FailedPrecondition, "revocation is disabled")
+	}
+	if req.GetAccessKeyId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "no access key id specified")
+	}
+
+	client, _ := ctx.Value(credentialsAPIClient{}).(string)
+	entry, err := h.revoked.Revoke(ctx, revocation.Entry{
+		AccessKeyID: req.GetAccessKeyId(),
+		RevokedBy:   client,
+		Reason:      req.GetReason(),
+	})
+	if err != nil {
+		h.log.Error("could not revoke access key", zap.String("access_key_id", req.GetAccessKeyId()), zap.Error(err))
+		return nil, status.Error(codes.Internal, "could not revoke access key")
+	}
+
+	h.log.Info("access key is revoked", zap.String("access_key_id", entry.AccessKeyID),
+		zap.String("revoked_by", entry.RevokedBy), zap.String("reason", entry.Reason))
+
+	return &credsapi.RevokeResponse{
+		AccessKeyId: entry.AccessKeyID,
+		RevokedAt:   entry.RevokedAt.Unix(),
+		RevokedBy:   entry.RevokedBy,
+		Reason:      entry.Reason,
+	}, nil
+}
+
+// Introspect describes credentials the same way the gateway resolves them,
+// expired and revoked credentials are described too.
+func (h *credentialsAPI) Introspect(ctx context.Context, req *credsapi.IntrospectRequest) (*credsapi.IntrospectResponse, error) {
+	accessKeyID := req.GetAccessKeyId()
+	if accessKeyID == "" {
+		return nil, status.Error(codes.InvalidArgument, "no access key id specified")
+	}
+
+	box, err := h.backend.ResolveAccessKey(ctx, accessKeyID)
+	if err != nil {
+		if errors.Is(err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)) {
+			return nil, status.Errorf(codes.NotFound, "unknown access key id '%s'", accessKeyID)
+		}
+		h.log.Error("could not resolve access key", zap.String("access_key_id", accessKeyID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "could not resolve access key")
+	}
+
+	res := &credsapi.IntrospectResponse{
+		AccessKeyId:  accessKeyID,
+		CurrentEpoch: h.epochs.CurrentEpoch(),
+		Revoked:      h.revoked != nil && h.revoked.IsRevoked(accessKeyID),
+	}
+	if box.Gate == nil {
+		return res, nil
+	}
+
+	if token := box.Gate.BearerToken; token != nil {
+		res.Issuer = token.ResolveIssuer().EncodeToString()
+		res.ExpirationEpoch = auth.BearerExpiration(token)
+		res.Expired = res.ExpirationEpoch < res.CurrentEpoch
+	}
+
+	for _, verb := range sessionVerbNames {
+		for _, token := range box.Gate.SessionTokens {
+			if token.AssertVerb(verb.verb) {
+				res.SessionVerbs = append(res.SessionVerbs, verb.name)
+				break
+			}
+		}
+	}
+
+	return res, nil
+}
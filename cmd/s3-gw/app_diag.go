@@ -0,0 +1,233 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// redactedValue replaces sensitive values in the dumped config.
+const redactedValue = "[redacted]"
+
+// sensitiveConfigKeys are parts of config keys with sensitive values.
+var sensitiveConfigKeys = []string{"passphrase", "password", "secret", "token", "salt", "previous_keys"}
+
+type (
+	// diagnostics dumps the bundle of the gateway state to capture evidence
+	// of production incidents.
+	diagnostics struct {
+		log      *zap.Logger
+		cfg      *viper.Viper
+		obj      layer.Client
+		pool     *poolRecycler
+		poolStat *stat.PoolStat
+
+		// mu serializes dumps, so they don't race for the file name.
+		mu sync.Mutex
+	}
+
+	// poolDiagnostics is a JSON representation of the connection pool state.
+	poolDiagnostics struct {
+		Storage api.StorageStatus `json:"storage"`
+		Nodes   []nodeDiagnostics `json:"nodes"`
+	}
+
+	nodeDiagnostics struct {
+		Address  string `json:"address"`
+		Requests uint64 `json:"requests"`
+		Errors   uint64 `json:"errors"`
+	}
+)
+
+func newDiagnostics(a *App) *diagnostics {
+	return &diagnostics{
+		log:      a.log,
+		cfg:      a.cfg,
+		obj:      a.obj,
+		pool:     a.pool,
+		poolStat: a.poolStat,
+	}
+}
+
+// Dump writes the gzipped tarball with goroutine stacks, the connection pool
+// state, cache statistics, recent slow requests and the effective config
+// with sensitive values redacted to the configured directory. It returns the
+// path of the bundle.
+func (d *diagnostics) Dump() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dir := d.cfg.GetString(cfgDiagnosticsDir)
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	now := time.Now().UTC()
+	path := filepath.Join(dir, "s3-gw-diagnostics-"+now.Format("20060102T150405.000")+".tar.gz")
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("create diagnostics bundle: %w", err)
+	}
+
+	if err = d.write(f, now); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", fmt.Errorf("close diagnostics bundle: %w", err)
+	}
+
+	return path, nil
+}
+
+func (d *diagnostics) write(f *os.File, now time.Time) error {
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	var stacks bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&stacks, 2); err != nil {
+		return fmt.Errorf("dump goroutines: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data func() ([]byte, error)
+	}{
+		{"goroutines.txt", func() ([]byte, error) { return stacks.Bytes(), nil }},
+		{"pool.json", func() ([]byte, error) { return json.MarshalIndent(d.poolState(), "", "  ") }},
+		{"caches.json", func() ([]byte, error) { return json.MarshalIndent(d.obj.CacheStats(), "", "  ") }},
+		{"slow_requests.json", func() ([]byte, error) { return json.MarshalIndent(metrics.SlowRequests(), "", "  ") }},
+		{"config.json", func() ([]byte, error) { return json.MarshalIndent(redactConfig(d.cfg.AllSettings()), "", "  ") }},
+	}
+
+	for _, file := range files {
+		data, err := file.data()
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", file.name, err)
+		}
+
+		err = tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0o600,
+			Size:    int64(len(data)),
+			ModTime: now,
+		})
+		if err == nil {
+			_, err = tw.Write(data)
+		}
+		if err != nil {
+			return fmt.Errorf("write %s: %w", file.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("close gzip: %w", err)
+	}
+
+	return nil
+}
+
+func (d *diagnostics) poolState() poolDiagnostics {
+	res := poolDiagnostics{Storage: d.pool.StorageStatus()}
+	for _, node := range d.poolStat.Statistic().Nodes() {
+		res.Nodes = append(res.Nodes, nodeDiagnostics{
+			Address:  node.Address(),
+			Requests: node.Requests(),
+			Errors:   node.OverallErrors(),
+		})
+	}
+
+	return res
+}
+
+// dumpDiagnostics writes the diagnostics bundle on SIGUSR1.
+func (a *App) dumpDiagnostics() {
+	a.log.Info("SIGUSR1 diagnostics dump started")
+
+	path, err := a.diag.Dump()
+	if err != nil {
+		a.log.Error("failed to dump diagnostics", zap.Error(err))
+		return
+	}
+
+	a.log.Info("SIGUSR1 diagnostics dump completed", zap.String("path", path))
+}
+
+// redactConfig replaces non-empty values of sensitive keys in settings.
+func redactConfig(settings map[string]any) map[string]any {
+	res := make(map[string]any, len(settings))
+	for k, v := range settings {
+		if isSensitiveConfigKey(k) && !isEmptyConfigValue(v) {
+			res[k] = redactedValue
+			continue
+		}
+		res[k] = redactConfigValue(v)
+	}
+
+	return res
+}
+
+func redactConfigValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return redactConfig(v)
+	case []any:
+		res := make([]any, len(v))
+		for i := range v {
+			res[i] = redactConfigValue(v[i])
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	if key == "key" {
+		return true
+	}
+
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptyConfigValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
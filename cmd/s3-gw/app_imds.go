@@ -0,0 +1,266 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	imdsTokenHeader    = "X-aws-ec2-metadata-token"
+	imdsTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+	imdsMaxTokenTTL    = 6 * time.Hour
+	// imdsPruneInterval is a period of removal of expired session tokens.
+	imdsPruneInterval = time.Minute
+	// imdsMaxTokens is a limit of live session tokens, new ones aren't issued
+	// until some of them expire.
+	imdsMaxTokens = 4096
+
+	imdsCredentialsPath = "/latest/meta-data/iam/security-credentials/"
+)
+
+type (
+	imdsHandler struct {
+		log      *zap.Logger
+		sessions *auth.Sessions
+		roles    []imdsRole
+		ttl      time.Duration
+
+		mu sync.Mutex
+		// tokens are expirations of IMDSv2 session tokens.
+		tokens map[string]time.Time
+	}
+
+	// imdsRole maps the role name of instance metadata to credentials issued
+	// by authmate.
+	imdsRole struct {
+		name        string
+		accessKeyID string
+	}
+
+	// imdsCredentialsResponse is a JSON representation of role credentials
+	// in the format of EC2 instance metadata service.
+	imdsCredentialsResponse struct {
+		Code            string `json:"Code"`
+		LastUpdated     string `json:"LastUpdated"`
+		Type            string `json:"Type"`
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		Token           string `json:"Token"`
+		Expiration      string `json:"Expiration"`
+	}
+)
+
+// NewIMDSService creates a new service emulating credentials endpoints of EC2
+// instance metadata service, so workloads using the default credentials chain
+// of AWS SDKs can get temporary credentials of ones issued by authmate. Only
+// session-oriented (IMDSv2) requests are served.
+func NewIMDSService(v *viper.Viper, l *zap.Logger, sessions *auth.Sessions) *Service {
+	log := l.With(zap.String("service", "IMDS"))
+	h := &imdsHandler{
+		log:      log,
+		sessions: sessions,
+		roles:    fetchIMDSRoles(log, v),
+		ttl:      v.GetDuration(cfgIMDSCredentialsTTL),
+		tokens:   make(map[string]time.Time),
+	}
+
+	if h.ttl <= 0 {
+		h.ttl = defaultIMDSCredentialsTTL
+	}
+
+	addr := v.GetString(cfgIMDSAddress)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: h.router(),
+	}
+
+	enabled := v.GetBool(cfgIMDSEnabled)
+	if enabled && !isLoopbackAddress(addr) && !isLinkLocalAddress(addr) {
+		log.Error("IMDS isn't started, it must be bound to a loopback or link-local address", zap.String("address", addr))
+		enabled = false
+	}
+	if enabled {
+		stop := make(chan struct{})
+		go h.pruneSessions(stop)
+		srv.RegisterOnShutdown(func() { close(stop) })
+	}
+
+	return &Service{
+		Server:      srv,
+		enabled:     enabled,
+		serviceType: "IMDS",
+		log:         log,
+	}
+}
+
+// isLinkLocalAddress checks if the listen address is bound to a link-local
+// address, like 169.254.169.254 of EC2 instance metadata service.
+func isLinkLocalAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLinkLocalUnicast()
+}
+
+func (h *imdsHandler) router() http.Handler {
+	router := mux.NewRouter()
+	router.Methods(http.MethodPut).Path("/latest/api/token").HandlerFunc(h.issueToken)
+	router.Methods(http.MethodGet).Path(imdsCredentialsPath).HandlerFunc(h.checkToken(h.listRoles))
+	router.Methods(http.MethodGet).Path(imdsCredentialsPath + "{role}").HandlerFunc(h.checkToken(h.roleCredentials))
+
+	return router
+}
+
+// pruneSessions periodically removes expired session tokens until stop is
+// closed.
+func (h *imdsHandler) pruneSessions(stop <-chan struct{}) {
+	tm := time.NewTicker(imdsPruneInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-tm.C:
+			h.prune(now)
+		}
+	}
+}
+
+func (h *imdsHandler) prune(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.pruneLocked(now)
+}
+
+func (h *imdsHandler) pruneLocked(now time.Time) {
+	for token, expiration := range h.tokens {
+		if !expiration.After(now) {
+			delete(h.tokens, token)
+		}
+	}
+}
+
+// issueToken creates the session token of IMDSv2. Requests forwarded by
+// proxies are rejected as EC2 does it, requests exceeding the limit of live
+// tokens are rejected with 503.
+func (h *imdsHandler) issueToken(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Forwarded-For") != "" {
+		http.Error(w, "forwarded requests are forbidden", http.StatusForbidden)
+		return
+	}
+
+	ttl, err := strconv.Atoi(r.Header.Get(imdsTokenTTLHeader))
+	if err != nil || ttl <= 0 || time.Duration(ttl)*time.Second > imdsMaxTokenTTL {
+		http.Error(w, "invalid "+imdsTokenTTLHeader, http.StatusBadRequest)
+		return
+	}
+
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		h.log.Error("could not generate session token", zap.Error(err))
+		http.Error(w, "could not generate session token", http.StatusInternalServerError)
+		return
+	}
+	token := hex.EncodeToString(buf)
+
+	now := time.Now()
+	h.mu.Lock()
+	if len(h.tokens) >= imdsMaxTokens {
+		h.pruneLocked(now)
+	}
+	if len(h.tokens) >= imdsMaxTokens {
+		h.mu.Unlock()
+		h.log.Warn("session tokens limit is reached", zap.Int("limit", imdsMaxTokens))
+		http.Error(w, "too many session tokens", http.StatusServiceUnavailable)
+		return
+	}
+	h.tokens[token] = now.Add(time.Duration(ttl) * time.Second)
+	h.mu.Unlock()
+
+	w.Header().Set(imdsTokenTTLHeader, strconv.Itoa(ttl))
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(token))
+}
+
+// checkToken rejects requests without the session token or with unknown or
+// expired one.
+func (h *imdsHandler) checkToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		expiration, ok := h.tokens[r.Header.Get(imdsTokenHeader)]
+		h.mu.Unlock()
+
+		if !ok || !expiration.After(time.Now()) {
+			http.Error(w, "invalid session token", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (h *imdsHandler) listRoles(w http.ResponseWriter, _ *http.Request) {
+	names := make([]string, len(h.roles))
+	for i := range h.roles {
+		names[i] = h.roles[i].name
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(strings.Join(names, "\n")))
+}
+
+func (h *imdsHandler) roleCredentials(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["role"]
+
+	var role *imdsRole
+	for i := range h.roles {
+		if h.roles[i].name == name {
+			role = &h.roles[i]
+			break
+		}
+	}
+	if role == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now().UTC()
+	session, err := h.sessions.Issue(role.accessKeyID, now.Add(h.ttl))
+	if err != nil {
+		h.log.Error("could not issue temporary credentials", zap.String("role", name), zap.Error(err))
+		http.Error(w, "could not issue credentials", http.StatusInternalServerError)
+		return
+	}
+
+	res := imdsCredentialsResponse{
+		Code:            "Success",
+		LastUpdated:     now.Format(time.RFC3339),
+		Type:            "AWS-HMAC",
+		AccessKeyID:     session.AccessKeyID,
+		SecretAccessKey: session.SecretAccessKey,
+		Token:           session.SessionToken,
+		Expiration:      session.Expiration.Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write credentials", zap.Error(err))
+	}
+}
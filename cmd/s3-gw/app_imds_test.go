@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestIMDSHandler(t *testing.T) *imdsHandler {
+	sessions, err := auth.NewSessions(make([]byte, 32))
+	require.NoError(t, err)
+
+	return &imdsHandler{
+		log:      zap.NewNop(),
+		sessions: sessions,
+		roles:    []imdsRole{{name: "s3-workload", accessKeyID: "parentAccessKeyID"}},
+		ttl:      time.Hour,
+		tokens:   make(map[string]time.Time),
+	}
+}
+
+func imdsRequest(h http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIMDSToken(t *testing.T) {
+	router := newTestIMDSHandler(t).router()
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "valid", headers: map[string]string{imdsTokenTTLHeader: "60"}, status: http.StatusOK},
+		{name: "no ttl", status: http.StatusBadRequest},
+		{name: "too long ttl", headers: map[string]string{imdsTokenTTLHeader: "21601"}, status: http.StatusBadRequest},
+		{name: "forwarded", headers: map[string]string{imdsTokenTTLHeader: "60", "X-Forwarded-For": "10.0.0.1"}, status: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := imdsRequest(router, http.MethodPut, "/latest/api/token", tc.headers)
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestIMDSCredentials(t *testing.T) {
+	h := newTestIMDSHandler(t)
+	router := h.router()
+
+	w := imdsRequest(router, http.MethodPut, "/latest/api/token", map[string]string{imdsTokenTTLHeader: "60"})
+	require.Equal(t, http.StatusOK, w.Code)
+	token := map[string]string{imdsTokenHeader: w.Body.String()}
+
+	// IMDSv1 requests and unknown tokens are rejected
+	require.Equal(t, http.StatusUnauthorized, imdsRequest(router, http.MethodGet, imdsCredentialsPath, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, imdsRequest(router, http.MethodGet, imdsCredentialsPath+"s3-workload",
+		map[string]string{imdsTokenHeader: "unknown"}).Code)
+
+	w = imdsRequest(router, http.MethodGet, imdsCredentialsPath, token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "s3-workload", w.Body.String())
+
+	require.Equal(t, http.StatusNotFound, imdsRequest(router, http.MethodGet, imdsCredentialsPath+"unknown", token).Code)
+
+	w = imdsRequest(router, http.MethodGet, imdsCredentialsPath+"s3-workload", token)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var res imdsCredentialsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	require.NotEqual(t, "parentAccessKeyID", res.AccessKeyID)
+	require.NotEmpty(t, res.Token)
+
+	session, err := h.sessions.Resolve(res.Token, res.AccessKeyID, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "parentAccessKeyID", session.ParentAccessKeyID)
+	require.Equal(t, res.SecretAccessKey, session.SecretAccessKey)
+	require.Equal(t, session.Expiration.Format(time.RFC3339), res.Expiration)
+	require.WithinDuration(t, time.Now().Add(time.Hour), session.Expiration, time.Minute)
+}
+
+func TestIMDSPrune(t *testing.T) {
+	h := newTestIMDSHandler(t)
+	now := time.Now()
+	h.tokens["expired"] = now.Add(-time.Second)
+	h.tokens["valid"] = now.Add(time.Minute)
+
+	h.prune(now)
+	require.Equal(t, map[string]time.Time{"valid": now.Add(time.Minute)}, h.tokens)
+
+	// expired tokens are rejected before they're pruned
+	h.tokens["expired"] = now.Add(-time.Second)
+	w := imdsRequest(h.router(), http.MethodGet, imdsCredentialsPath, map[string]string{imdsTokenHeader: "expired"})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestIMDSTokensLimit(t *testing.T) {
+	h := newTestIMDSHandler(t)
+	router := h.router()
+
+	now := time.Now()
+	for i := 0; i < imdsMaxTokens-1; i++ {
+		h.tokens[strconv.Itoa(i)] = now.Add(time.Minute)
+	}
+
+	headers := map[string]string{imdsTokenTTLHeader: "60"}
+	require.Equal(t, http.StatusOK, imdsRequest(router, http.MethodPut, "/latest/api/token", headers).Code)
+	require.Equal(t, http.StatusServiceUnavailable, imdsRequest(router, http.MethodPut, "/latest/api/token", headers).Code)
+	require.Len(t, h.tokens, imdsMaxTokens)
+
+	// expired tokens free the room for new ones
+	h.tokens["0"] = now.Add(-time.Second)
+	require.Equal(t, http.StatusOK, imdsRequest(router, http.MethodPut, "/latest/api/token", headers).Code)
+	require.Len(t, h.tokens, imdsMaxTokens)
+}
+
+func TestIsLinkLocalAddress(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"169.254.169.254:80": true,
+		"[fe80::1]:80":       true,
+		"127.0.0.1:8088":     false,
+		":8088":              false,
+		"0.0.0.0:8088":       false,
+		"10.0.0.1:8088":      false,
+		"169.254.169.254":    false,
+	} {
+		require.Equal(t, expected, isLinkLocalAddress(addr), addr)
+	}
+}
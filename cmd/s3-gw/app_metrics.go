@@ -1,7 +1,11 @@
 package main
 
 import (
+	"crypto/subtle"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 
 	"github.com/nspcc-dev/neofs-sdk-go/stat"
 	"github.com/prometheus/client_golang/prometheus"
@@ -53,6 +57,8 @@ type poolMetricsCollector struct {
 	overallNodeRequests *prometheus.GaugeVec
 	currentErrors       *prometheus.GaugeVec
 	requestDuration     *prometheus.GaugeVec
+	events              *prometheus.CounterVec
+	sessions            *prometheus.CounterVec
 }
 
 func newGateMetrics(scraper StatisticScraper) *GateMetrics {
@@ -163,6 +169,32 @@ func newPoolMetricsCollector(scraper StatisticScraper) *poolMetricsCollector {
 		},
 	)
 
+	events := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: poolSubsystem,
+			Name:      "events_total",
+			Help:      "Number of connection pool events (connected, disconnected, dial_failed, node_unhealthy, node_recovered)",
+		},
+		[]string{
+			"event",
+			"node",
+		},
+	)
+
+	sessions := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: poolSubsystem,
+			Name:      "session_cache_events_total",
+			Help:      "Number of connection pool session cache events (lookup, created, invalidated)",
+		},
+		[]string{
+			"event",
+			"node",
+		},
+	)
+
 	return &poolMetricsCollector{
 		poolStatScraper:     scraper,
 		overallErrors:       overallErrors,
@@ -170,6 +202,8 @@ func newPoolMetricsCollector(scraper StatisticScraper) *poolMetricsCollector {
 		overallNodeRequests: overallNodeRequests,
 		currentErrors:       currentErrors,
 		requestDuration:     requestsDuration,
+		events:              events,
+		sessions:            sessions,
 	}
 }
 
@@ -180,6 +214,8 @@ func (m *poolMetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	m.overallNodeRequests.Collect(ch)
 	m.currentErrors.Collect(ch)
 	m.requestDuration.Collect(ch)
+	m.events.Collect(ch)
+	m.sessions.Collect(ch)
 }
 
 func (m *poolMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
@@ -188,12 +224,25 @@ func (m *poolMetricsCollector) Describe(descs chan<- *prometheus.Desc) {
 	m.overallNodeRequests.Describe(descs)
 	m.currentErrors.Describe(descs)
 	m.requestDuration.Describe(descs)
+	m.events.Describe(descs)
+	m.sessions.Describe(descs)
 }
 
 func (m *poolMetricsCollector) register() {
 	prometheus.MustRegister(m)
 }
 
+// PoolEvent counts connection pool event, node is empty for events related to
+// the whole pool.
+func (m *poolMetricsCollector) PoolEvent(event, node string) {
+	m.events.WithLabelValues(event, node).Inc()
+}
+
+// SessionEvent counts connection pool session cache event.
+func (m *poolMetricsCollector) SessionEvent(event, node string) {
+	m.sessions.WithLabelValues(event, node).Inc()
+}
+
 func (m *poolMetricsCollector) updateStatistic() {
 	st := m.poolStatScraper.Statistic()
 
@@ -230,21 +279,142 @@ func (m *poolMetricsCollector) updateRequestsDuration(node stat.NodeStatistic) {
 	m.requestDuration.WithLabelValues(node.Address(), methodCreateSession).Set(float64(node.AverageCreateSession().Milliseconds()))
 }
 
+// metricsAccess restricts scrapes of metrics to clients from allowed networks
+// authenticated with the basic auth or the bearer token, every check is
+// skipped if it isn't configured.
+type metricsAccess struct {
+	log        *zap.Logger
+	username   string
+	password   string
+	token      string
+	restricted bool
+	networks   []netip.Prefix
+}
+
 // NewPrometheusService creates a new service for gathering prometheus metrics.
 func NewPrometheusService(v *viper.Viper, log *zap.Logger) *Service {
 	if log == nil {
 		return nil
 	}
 
+	log = log.With(zap.String("service", "Prometheus"))
+	access := &metricsAccess{
+		log:      log,
+		username: v.GetString(cfgPrometheusAuthUsername),
+		password: v.GetString(cfgPrometheusAuthPassword),
+		token:    v.GetString(cfgPrometheusAuthToken),
+	}
+
+	// invalid networks are skipped, but scrapes are still restricted to the
+	// valid ones, so a typo doesn't open the endpoint to everyone
+	for _, network := range v.GetStringSlice(cfgPrometheusAllowedNetworks) {
+		access.restricted = true
+		prefix, err := parseNetwork(network)
+		if err != nil {
+			log.Error("skip invalid allowed network", zap.String("network", network), zap.Error(err))
+			continue
+		}
+		access.networks = append(access.networks, prefix)
+	}
+
 	return &Service{
 		Server: &http.Server{
 			Addr:    v.GetString(cfgPrometheusAddress),
-			Handler: promhttp.Handler(),
+			Handler: access.handler(promhttp.Handler()),
 		},
 		enabled:     v.GetBool(cfgPrometheusEnabled),
 		serviceType: "Prometheus",
-		log:         log.With(zap.String("service", "Prometheus")),
+		log:         log,
+	}
+}
+
+// parseNetwork parses CIDR or a single IP address.
+func parseNetwork(network string) (netip.Prefix, error) {
+	if !strings.Contains(network, "/") {
+		addr, err := netip.ParseAddr(network)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	return netip.ParsePrefix(network)
+}
+
+// handler rejects scrapes from disallowed addresses with 403 and not
+// authenticated ones with 401. The address of the connection is checked,
+// X-Forwarded-For and similar headers are ignored since clients set them.
+func (a *metricsAccess) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.allowedAddress(r.RemoteAddr) {
+			a.log.Debug("metrics scrape from disallowed address", zap.String("remote", r.RemoteAddr))
+			http.Error(w, "address is not allowed", http.StatusForbidden)
+			return
+		}
+
+		if !a.authenticated(r) {
+			if a.token != "" {
+				w.Header().Add("WWW-Authenticate", "Bearer")
+			}
+			if a.username != "" {
+				w.Header().Add("WWW-Authenticate", `Basic realm="metrics"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *metricsAccess) allowedAddress(remote string) bool {
+	if !a.restricted {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
 	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range a.networks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticated checks credentials of the request, any of configured
+// methods is enough.
+func (a *metricsAccess) authenticated(r *http.Request) bool {
+	if a.username == "" && a.token == "" {
+		return true
+	}
+
+	if a.token != "" {
+		value := r.Header.Get("Authorization")
+		if strings.HasPrefix(value, "Bearer ") &&
+			subtle.ConstantTimeCompare([]byte(a.token), []byte(strings.TrimPrefix(value, "Bearer "))) == 1 {
+			return true
+		}
+	}
+
+	if a.username != "" {
+		if username, password, ok := r.BasicAuth(); ok &&
+			subtle.ConstantTimeCompare([]byte(a.username), []byte(username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(a.password), []byte(password)) == 1 {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (g *GateMetrics) SetGWVersion(ver string) {
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"go.uber.org/zap"
+)
+
+// Connection pool events.
+const (
+	poolEventConnected     = "connected"
+	poolEventDisconnected  = "disconnected"
+	poolEventDialFailed    = "dial_failed"
+	poolEventNodeUnhealthy = "node_unhealthy"
+	poolEventNodeRecovered = "node_recovered"
+)
+
+// Session cache events of the connection pool.
+const (
+	sessionEventLookup      = "lookup"
+	sessionEventCreated     = "created"
+	sessionEventInvalidated = "invalidated"
+)
+
+type (
+	// poolRecycler replaces the NeoFS connection pool with a freshly dialed one
+	// once the current pool exceeds its TTL or the set of peers changes.
+	poolRecycler struct {
+		log          *zap.Logger
+		prm          pool.InitParameters
+		ttl          time.Duration
+		drainTimeout time.Duration
+		current      atomic.Pointer[pool.Pool]
+		events       poolEventHandler
+		// dial and close connection pools, they're replaced in tests.
+		dialPool  func(context.Context, []pool.NodeParam) (*pool.Pool, error)
+		closePool func(*pool.Pool)
+
+		poolStat       *stat.PoolStat
+		errorThreshold uint32
+		nodesMu        sync.Mutex
+		nodes          map[string]nodeHealth
+
+		mu sync.Mutex
+		// configured are peers of the configuration, excluded are addresses
+		// of ones excluded at runtime, peers are used by the current pool.
+		configured []connectionPeer
+		excluded   map[string]struct{}
+		peers      []connectionPeer
+		swapper    poolSwapper
+	}
+
+	// connectionPeer is a storage node of the connection pool.
+	connectionPeer struct {
+		address  string
+		priority int
+		weight   float64
+		// excluded is set if the node mustn't be used by the pool.
+		excluded bool
+	}
+
+	// nodeHealth is the result of the recent operations with the node.
+	nodeHealth struct {
+		errors  uint32
+		lastErr error
+	}
+
+	// poolEventHandler receives connection pool events. Node is empty for
+	// events related to the whole pool, err is set for failures only.
+	poolEventHandler interface {
+		HandlePoolEvent(event, node string, err error)
+		HandleSessionEvent(event, node string)
+	}
+
+	// poolEventsLogger is a poolEventHandler writing events to the log and
+	// to the metrics.
+	poolEventsLogger struct {
+		log     *zap.Logger
+		metrics *appMetrics
+	}
+
+	// poolSwapper is a consumer of the connection pool which supports
+	// replacing it at runtime. The returned channel is closed once the
+	// previous pool isn't borrowed by operations.
+	poolSwapper interface {
+		SwapPool(p *pool.Pool) <-chan struct{}
+	}
+
+	// poolSwapperFunc is a poolSwapper calling the function, it's used to
+	// replace pools other than the main one.
+	poolSwapperFunc func(p *pool.Pool) <-chan struct{}
+)
+
+var (
+	errNoPeers     = errors.New("no connection peers, all of them are either missing or excluded")
+	errUnknownPeer = errors.New("unknown connection peer")
+)
+
+func newPoolRecycler(log *zap.Logger, prm pool.InitParameters, poolStat *stat.PoolStat, errorThreshold uint32, ttl, drainTimeout time.Duration, events poolEventHandler) *poolRecycler {
+	r := &poolRecycler{
+		log:            log,
+		ttl:            ttl,
+		drainTimeout:   drainTimeout,
+		events:         events,
+		closePool:      (*pool.Pool).Close,
+		poolStat:       poolStat,
+		errorThreshold: errorThreshold,
+		nodes:          make(map[string]nodeHealth),
+		excluded:       make(map[string]struct{}),
+	}
+	r.dialPool = r.dial
+
+	prm.SetStatisticCallback(r.operationCallback)
+	r.prm = prm
+
+	return r
+}
+
+func newPoolEventsLogger(log *zap.Logger, metrics *appMetrics) *poolEventsLogger {
+	return &poolEventsLogger{
+		log:     log,
+		metrics: metrics,
+	}
+}
+
+// SwapPool implements poolSwapper.
+func (f poolSwapperFunc) SwapPool(p *pool.Pool) <-chan struct{} {
+	return f(p)
+}
+
+// HandlePoolEvent implements poolEventHandler.
+func (l *poolEventsLogger) HandlePoolEvent(event, node string, err error) {
+	fields := []zap.Field{zap.String("event", event)}
+	if node != "" {
+		fields = append(fields, zap.String("node", node))
+	}
+
+	if err != nil {
+		l.log.Warn("connection pool event", append(fields, zap.Error(err))...)
+	} else {
+		l.log.Info("connection pool event", fields...)
+	}
+
+	l.metrics.PoolEvent(event, node)
+}
+
+// HandleSessionEvent implements poolEventHandler.
+func (l *poolEventsLogger) HandleSessionEvent(event, node string) {
+	if event == sessionEventInvalidated {
+		l.log.Debug("node rejected session token, sessions dropped", zap.String("node", node))
+	}
+
+	l.metrics.SessionEvent(event, node)
+}
+
+// operationCallback collects pool statistic and tracks node health: a node
+// is reported unhealthy after errorThreshold consecutive failures and
+// recovered after the first successful operation.
+func (r *poolRecycler) operationCallback(nodeKey []byte, endpoint string, method stat.Method, duration time.Duration, err error) {
+	r.poolStat.OperationCallback(nodeKey, endpoint, method, duration, err)
+	r.handleSessionEvents(endpoint, method, err)
+
+	r.nodesMu.Lock()
+	errCount := r.nodes[endpoint].errors
+	if err != nil {
+		r.nodes[endpoint] = nodeHealth{errors: errCount + 1, lastErr: err}
+	} else {
+		r.nodes[endpoint] = nodeHealth{}
+	}
+	r.nodesMu.Unlock()
+
+	switch {
+	case err != nil && errCount+1 == r.errorThreshold:
+		r.events.HandlePoolEvent(poolEventNodeUnhealthy, endpoint, err)
+	case err == nil && errCount >= r.errorThreshold:
+		r.events.HandlePoolEvent(poolEventNodeRecovered, endpoint, nil)
+	}
+}
+
+// handleSessionEvents reports the use of the pool session cache. Sessions are
+// cached per node, signer, verb and container until their expiration epoch.
+// The cache is looked up by every object operation, a session is created on
+// a miss and all sessions of the node are dropped if it rejects the token.
+func (r *poolRecycler) handleSessionEvents(endpoint string, method stat.Method, err error) {
+	switch method {
+	default:
+		return
+	case stat.MethodSessionCreate:
+		if err == nil {
+			r.events.HandleSessionEvent(sessionEventCreated, endpoint)
+		}
+		return
+	case stat.MethodObjectPut, stat.MethodObjectDelete, stat.MethodObjectGet, stat.MethodObjectHead,
+		stat.MethodObjectRange, stat.MethodObjectHash, stat.MethodObjectSearch:
+		r.events.HandleSessionEvent(sessionEventLookup, endpoint)
+	}
+
+	if errors.Is(err, apistatus.ErrSessionTokenExpired) || errors.Is(err, apistatus.ErrSessionTokenNotFound) {
+		r.events.HandleSessionEvent(sessionEventInvalidated, endpoint)
+	}
+}
+
+// StorageStatus implements api.StorageState. Storage isn't ready if every node
+// the pool has reported about failed at least errorThreshold operations in a
+// row. Pool checks node health periodically, so recovery is detected even if
+// no requests are served.
+func (r *poolRecycler) StorageStatus() api.StorageStatus {
+	r.nodesMu.Lock()
+	defer r.nodesMu.Unlock()
+
+	res := api.StorageStatus{Nodes: len(r.nodes)}
+	for endpoint, health := range r.nodes {
+		if health.errors < r.errorThreshold {
+			continue
+		}
+
+		node := api.UnhealthyNode{Address: endpoint, Errors: health.errors}
+		if health.lastErr != nil {
+			node.LastError = health.lastErr.Error()
+		}
+		res.Unhealthy = append(res.Unhealthy, node)
+	}
+
+	sort.Slice(res.Unhealthy, func(i, j int) bool {
+		return res.Unhealthy[i].Address < res.Unhealthy[j].Address
+	})
+	res.Ready = len(res.Unhealthy) < res.Nodes || res.Nodes == 0
+
+	return res
+}
+
+// Dial creates the initial connection pool to the given peers, excluded ones
+// are skipped.
+func (r *poolRecycler) Dial(ctx context.Context, peers []connectionPeer) (*pool.Pool, error) {
+	active := r.activePeers(peers)
+	p, err := r.dialPool(ctx, nodeParams(active))
+	if err != nil {
+		return nil, err
+	}
+
+	r.configured = peers
+	r.peers = active
+	r.current.Store(p)
+	r.events.HandlePoolEvent(poolEventConnected, "", nil)
+
+	return p, nil
+}
+
+// NetworkInfo implements neofs.NetworkInfoGetter through the current pool.
+func (r *poolRecycler) NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	return r.current.Load().NetworkInfo(ctx, prm)
+}
+
+// Start makes the recycler replace pools of the swapper. If TTL is set,
+// connections are recycled periodically until ctx is done.
+func (r *poolRecycler) Start(ctx context.Context, swapper poolSwapper) {
+	r.mu.Lock()
+	r.swapper = swapper
+	r.mu.Unlock()
+
+	if r.ttl <= 0 {
+		return
+	}
+
+	r.log.Info("connection recycling enabled", zap.Duration("ttl", r.ttl))
+
+	go func() {
+		tm := time.NewTicker(r.ttl)
+		defer tm.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tm.C:
+				r.mu.Lock()
+				_ = r.recycle(ctx, r.peers)
+				r.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// UpdatePeers redials the pool if the set of peers differs from the current
+// one, so nodes can be excluded or returned to service without restart.
+// Peers excluded at runtime stay excluded.
+func (r *poolRecycler) UpdatePeers(ctx context.Context, peers []connectionPeer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.configured = peers
+	active := r.activePeers(peers)
+	if equalPeers(r.peers, active) {
+		return
+	}
+
+	r.log.Info("connection peers changed, redialing pool")
+	_ = r.recycle(ctx, active)
+}
+
+// Peers returns the configured peers, ones excluded by the configuration or
+// at runtime are marked as excluded.
+func (r *poolRecycler) Peers() []connectionPeer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peers := make([]connectionPeer, len(r.configured))
+	for i, peer := range r.configured {
+		_, excluded := r.excluded[peer.address]
+		peer.excluded = peer.excluded || excluded
+		peers[i] = peer
+	}
+
+	return peers
+}
+
+// ExcludePeer excludes the configured peer from the pool or returns it to
+// service if exclude is false and redials the pool. Runtime exclusion doesn't
+// change peers excluded by the configuration, it's kept till the restart.
+func (r *poolRecycler) ExcludePeer(ctx context.Context, address string, exclude bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var known bool
+	for _, peer := range r.configured {
+		if peer.address == address {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", errUnknownPeer, address)
+	}
+
+	_, wasExcluded := r.excluded[address]
+	if exclude {
+		r.excluded[address] = struct{}{}
+	} else {
+		delete(r.excluded, address)
+	}
+
+	active := r.activePeers(r.configured)
+	if equalPeers(r.peers, active) {
+		return nil
+	}
+
+	r.log.Info("connection peer exclusion changed, redialing pool",
+		zap.String("address", address), zap.Bool("excluded", exclude))
+	if err := r.recycle(ctx, active); err != nil {
+		if wasExcluded {
+			r.excluded[address] = struct{}{}
+		} else {
+			delete(r.excluded, address)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// activePeers returns peers which aren't excluded by the configuration or at
+// runtime, it must be called with r.mu held.
+func (r *poolRecycler) activePeers(peers []connectionPeer) []connectionPeer {
+	active := make([]connectionPeer, 0, len(peers))
+	for _, peer := range peers {
+		if _, ok := r.excluded[peer.address]; !ok && !peer.excluded {
+			active = append(active, peer)
+		}
+	}
+
+	return active
+}
+
+// recycle must be called with r.mu held.
+func (r *poolRecycler) recycle(ctx context.Context, peers []connectionPeer) error {
+	if r.swapper == nil {
+		return nil
+	}
+
+	p, err := r.dialPool(ctx, nodeParams(peers))
+	if err != nil {
+		r.log.Error("failed to recycle connection pool, keep the current one", zap.Error(err))
+		r.events.HandlePoolEvent(poolEventDialFailed, "", err)
+		return err
+	}
+
+	if !equalPeers(r.peers, peers) {
+		// Forget removed nodes, nodes of the new pool are reported by its
+		// health checks.
+		r.nodesMu.Lock()
+		r.nodes = make(map[string]nodeHealth)
+		r.nodesMu.Unlock()
+	}
+
+	r.peers = peers
+	drained := r.swapper.SwapPool(p)
+	old := r.current.Swap(p)
+	r.log.Info("connection pool recycled", zap.Duration("drain_timeout", r.drainTimeout))
+	r.events.HandlePoolEvent(poolEventConnected, "", nil)
+
+	// The old pool is closed once operations borrowing it (long downloads,
+	// for example) are finished, but not later than the drain timeout.
+	go func() {
+		tm := time.NewTimer(r.drainTimeout)
+		defer tm.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-drained:
+		case <-tm.C:
+			r.log.Warn("connection pool isn't drained in time, closing it")
+		}
+		r.closePool(old)
+		r.events.HandlePoolEvent(poolEventDisconnected, "", nil)
+	}()
+
+	return nil
+}
+
+func (r *poolRecycler) dial(ctx context.Context, peers []pool.NodeParam) (*pool.Pool, error) {
+	if len(peers) == 0 {
+		return nil, errNoPeers
+	}
+
+	prm := r.prm
+	for _, peer := range peers {
+		prm.AddNode(peer)
+	}
+
+	p, err := pool.NewPool(prm)
+	if err != nil {
+		return nil, fmt.Errorf("create connection pool: %w", err)
+	}
+
+	if err = p.Dial(ctx); err != nil {
+		return nil, fmt.Errorf("dial connection pool: %w", err)
+	}
+
+	return p, nil
+}
+
+func nodeParams(peers []connectionPeer) []pool.NodeParam {
+	params := make([]pool.NodeParam, len(peers))
+	for i, peer := range peers {
+		params[i] = pool.NewNodeParam(peer.priority, peer.address, peer.weight)
+	}
+
+	return params
+}
+
+func equalPeers(a, b []connectionPeer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
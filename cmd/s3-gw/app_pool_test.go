@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// poolEventsRecorder is a poolEventHandler remembering pool events and
+// session events with their nodes.
+type poolEventsRecorder struct {
+	mu       sync.Mutex
+	events   []string
+	sessions [][2]string
+}
+
+func (r *poolEventsRecorder) HandlePoolEvent(event, _ string, _ error) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *poolEventsRecorder) HandleSessionEvent(event, node string) {
+	r.mu.Lock()
+	r.sessions = append(r.sessions, [2]string{event, node})
+	r.mu.Unlock()
+}
+
+func (r *poolEventsRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// newTestPoolRecycler returns the recycler dialing empty pools, closed pools
+// are sent to the returned channel.
+func newTestPoolRecycler(drainTimeout time.Duration) (*poolRecycler, *poolEventsRecorder, <-chan *pool.Pool) {
+	events := new(poolEventsRecorder)
+	closed := make(chan *pool.Pool, 10)
+
+	r := newPoolRecycler(zap.NewNop(), pool.InitParameters{}, nil, 1, 0, drainTimeout, events)
+	r.dialPool = func(_ context.Context, peers []pool.NodeParam) (*pool.Pool, error) {
+		if len(peers) == 0 {
+			return nil, errNoPeers
+		}
+		return new(pool.Pool), nil
+	}
+	r.closePool = func(p *pool.Pool) { closed <- p }
+
+	return r, events, closed
+}
+
+func TestPoolRecyclerUpdatePeers(t *testing.T) {
+	ctx := context.Background()
+	r, events, closed := newTestPoolRecycler(10 * time.Millisecond)
+
+	peers := []connectionPeer{{address: "node1:8080", priority: 1, weight: 1}}
+	first, err := r.Dial(ctx, peers)
+	require.NoError(t, err)
+	require.Equal(t, first, r.current.Load())
+
+	// pools aren't replaced till the recycler is started
+	r.UpdatePeers(ctx, []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}})
+	require.Equal(t, first, r.current.Load())
+
+	var swapped *pool.Pool
+	r.Start(ctx, poolSwapperFunc(func(p *pool.Pool) <-chan struct{} {
+		swapped = p
+		return nil
+	}))
+
+	// the same peers aren't redialed
+	r.UpdatePeers(ctx, peers)
+	require.Nil(t, swapped)
+
+	r.nodes["node1:8080"] = nodeHealth{errors: 1}
+	newPeers := []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}}
+	r.UpdatePeers(ctx, newPeers)
+	require.NotNil(t, swapped)
+	require.NotSame(t, first, swapped)
+	require.Same(t, swapped, r.current.Load())
+	require.Equal(t, newPeers, r.peers)
+	require.Empty(t, r.nodes, "removed nodes must be forgotten")
+
+	// the old pool is closed once drained
+	select {
+	case p := <-closed:
+		require.Same(t, first, p)
+	case <-time.After(time.Second):
+		t.Fatal("old pool isn't closed")
+	}
+	require.Eventually(t, func() bool {
+		return len(events.Events()) == 3
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []string{poolEventConnected, poolEventConnected, poolEventDisconnected}, events.Events())
+}
+
+func TestPoolRecyclerDialFailure(t *testing.T) {
+	ctx := context.Background()
+	r, events, closed := newTestPoolRecycler(time.Millisecond)
+
+	_, err := r.Dial(ctx, nil)
+	require.ErrorIs(t, err, errNoPeers)
+
+	peers := []connectionPeer{{address: "node1:8080", priority: 1, weight: 1}}
+	current, err := r.Dial(ctx, peers)
+	require.NoError(t, err)
+	r.Start(ctx, poolSwapperFunc(func(p *pool.Pool) <-chan struct{} {
+		t.Fatal("pool mustn't be swapped")
+		return nil
+	}))
+
+	r.dialPool = func(context.Context, []pool.NodeParam) (*pool.Pool, error) {
+		return nil, errors.New("dial failed")
+	}
+	r.UpdatePeers(ctx, []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}})
+
+	// the current pool is kept with its peers
+	require.Same(t, current, r.current.Load())
+	require.Equal(t, peers, r.peers)
+	require.Equal(t, []string{poolEventConnected, poolEventDialFailed}, events.Events())
+	require.Empty(t, closed)
+}
+
+func TestPoolRecyclerExcludePeer(t *testing.T) {
+	ctx := context.Background()
+	r, _, _ := newTestPoolRecycler(time.Millisecond)
+
+	node1 := connectionPeer{address: "node1:8080", priority: 1, weight: 1}
+	node2 := connectionPeer{address: "node2:8080", priority: 2, weight: 1}
+	node3 := connectionPeer{address: "node3:8080", priority: 2, weight: 1, excluded: true}
+	_, err := r.Dial(ctx, []connectionPeer{node1, node2, node3})
+	require.NoError(t, err)
+	require.Equal(t, []connectionPeer{node1, node2}, r.peers)
+
+	var swaps int
+	r.Start(ctx, poolSwapperFunc(func(*pool.Pool) <-chan struct{} {
+		swaps++
+		return nil
+	}))
+
+	require.ErrorIs(t, r.ExcludePeer(ctx, "unknown:8080", true), errUnknownPeer)
+
+	require.NoError(t, r.ExcludePeer(ctx, node1.address, true))
+	require.Equal(t, 1, swaps)
+	require.Equal(t, []connectionPeer{node2}, r.peers)
+
+	excluded := node1
+	excluded.excluded = true
+	require.Equal(t, []connectionPeer{excluded, node2, node3}, r.Peers())
+
+	// the last peer can't be excluded
+	require.ErrorIs(t, r.ExcludePeer(ctx, node2.address, true), errNoPeers)
+	require.Equal(t, []connectionPeer{node2}, r.peers)
+	require.Equal(t, []connectionPeer{excluded, node2, node3}, r.Peers())
+
+	// runtime exclusion is kept on configuration updates
+	r.UpdatePeers(ctx, []connectionPeer{node1, node2, node3})
+	require.Equal(t, 1, swaps)
+	require.Equal(t, []connectionPeer{node2}, r.peers)
+
+	// configuration exclusion isn't changed at runtime
+	require.NoError(t, r.ExcludePeer(ctx, node3.address, false))
+	require.Equal(t, 1, swaps)
+
+	require.NoError(t, r.ExcludePeer(ctx, node1.address, false))
+	require.Equal(t, 2, swaps)
+	require.Equal(t, []connectionPeer{node1, node2}, r.peers)
+}
+
+func TestPoolRecyclerDrainInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _, closed := newTestPoolRecycler(time.Hour)
+
+	first, err := r.Dial(ctx, []connectionPeer{{address: "node1:8080", priority: 1, weight: 1}})
+	require.NoError(t, err)
+	r.Start(ctx, poolSwapperFunc(func(*pool.Pool) <-chan struct{} { return nil }))
+	r.UpdatePeers(ctx, []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}})
+
+	// the old pool isn't closed before the drain timeout
+	select {
+	case <-closed:
+		t.Fatal("old pool is closed before the drain timeout")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// but it's closed at once on shutdown
+	cancel()
+	select {
+	case p := <-closed:
+		require.Same(t, first, p)
+	case <-time.After(time.Second):
+		t.Fatal("old pool isn't closed on shutdown")
+	}
+}
+
+func TestPoolRecyclerDrained(t *testing.T) {
+	ctx := context.Background()
+	r, _, closed := newTestPoolRecycler(time.Hour)
+
+	first, err := r.Dial(ctx, []connectionPeer{{address: "node1:8080", priority: 1, weight: 1}})
+	require.NoError(t, err)
+
+	drained := make(chan struct{})
+	r.Start(ctx, poolSwapperFunc(func(*pool.Pool) <-chan struct{} { return drained }))
+	r.UpdatePeers(ctx, []connectionPeer{{address: "node2:8080", priority: 1, weight: 1}})
+
+	// the old pool is kept while it's borrowed
+	select {
+	case <-closed:
+		t.Fatal("borrowed pool is closed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// and closed once released without waiting for the drain timeout
+	close(drained)
+	select {
+	case p := <-closed:
+		require.Same(t, first, p)
+	case <-time.After(time.Second):
+		t.Fatal("drained pool isn't closed")
+	}
+}
+
+func TestPoolRecyclerTTL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, _, closed := newTestPoolRecycler(time.Millisecond)
+	r.ttl = 10 * time.Millisecond
+
+	first, err := r.Dial(ctx, []connectionPeer{{address: "node1:8080", priority: 1, weight: 1}})
+	require.NoError(t, err)
+	r.Start(ctx, poolSwapperFunc(func(*pool.Pool) <-chan struct{} { return nil }))
+
+	// pools are recycled with the same peers once TTL expires
+	select {
+	case p := <-closed:
+		require.Same(t, first, p)
+	case <-time.After(time.Second):
+		t.Fatal("pool isn't recycled")
+	}
+}
+
+func TestPoolRecyclerSessionEvents(t *testing.T) {
+	events := new(poolEventsRecorder)
+	r := newPoolRecycler(zap.NewNop(), pool.InitParameters{}, stat.NewPoolStatistic(), 10, 0, 0, events)
+
+	const node1, node2 = "node1:8080", "node2:8080"
+	for _, op := range []struct {
+		node   string
+		method stat.Method
+		err    error
+	}{
+		// the first operation misses the cache and creates the session
+		{node: node1, method: stat.MethodSessionCreate},
+		{node: node1, method: stat.MethodObjectPut},
+		{node: node1, method: stat.MethodObjectGet},
+		// failed session creations aren't cached
+		{node: node2, method: stat.MethodSessionCreate, err: errors.New("unavailable")},
+		// operations without sessions aren't lookups
+		{node: node2, method: stat.MethodContainerGet},
+		// the node rejecting the token invalidates its cached sessions
+		{node: node1, method: stat.MethodObjectHead, err: fmt.Errorf("head: %w", apistatus.ErrSessionTokenExpired)},
+		{node: node2, method: stat.MethodObjectDelete, err: apistatus.ErrSessionTokenNotFound},
+		// other errors keep sessions
+		{node: node2, method: stat.MethodObjectSearch, err: apistatus.ErrObjectNotFound},
+		// the next operation creates a new session
+		{node: node1, method: stat.MethodSessionCreate},
+	} {
+		r.operationCallback(nil, op.node, op.method, time.Millisecond, op.err)
+	}
+
+	require.Equal(t, [][2]string{
+		{sessionEventCreated, node1},
+		{sessionEventLookup, node1},
+		{sessionEventLookup, node1},
+		{sessionEventLookup, node1},
+		{sessionEventInvalidated, node1},
+		{sessionEventLookup, node2},
+		{sessionEventInvalidated, node2},
+		{sessionEventLookup, node2},
+		{sessionEventCreated, node1},
+	}, events.sessions)
+}
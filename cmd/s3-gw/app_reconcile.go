@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/revocation"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"go.uber.org/zap"
+)
+
+// reconcileFailed is a status of resources failed to be brought to the
+// desired state.
+const reconcileFailed = "failed"
+
+type (
+	// reconciler brings credentials and buckets to the state declared by the
+	// desired state document. Credentials are issued with the gateway key and
+	// identified by their access key IDs, buckets are created with
+	// credentials of their owners.
+	reconciler struct {
+		log     *zap.Logger
+		agent   *authmate.Agent
+		key     *keys.PrivateKey
+		creds   *registry.Registry
+		backend auth.CredentialsBackend
+		revoked *revocation.List
+		buckets handler.BucketReconciler
+	}
+
+	// desiredState is a JSON representation of the desired state document.
+	desiredState struct {
+		Credentials []desiredCredentials `json:"credentials"`
+		Buckets     []desiredBucket      `json:"buckets"`
+	}
+
+	desiredCredentials struct {
+		// AccessKeyID replaces the access box address, so the credentials
+		// can be found in the registry.
+		AccessKeyID       string            `json:"access_key_id"`
+		Container         string            `json:"container"`
+		Lifetime          string            `json:"lifetime"`
+		Description       string            `json:"description,omitempty"`
+		Gates             []string          `json:"gates,omitempty"`
+		ContainerPolicies map[string]string `json:"container_policies,omitempty"`
+		// AccessPolicy is applied on issuance only.
+		AccessPolicy json.RawMessage `json:"access_policy,omitempty"`
+	}
+
+	desiredBucket struct {
+		handler.DesiredBucket
+		// Owner is the access key ID of credentials the bucket is created
+		// and changed with.
+		Owner string `json:"owner"`
+	}
+
+	// reconcileResponse is a JSON representation of reconciliation results.
+	reconcileResponse struct {
+		Credentials []credentialsReconcileResult `json:"credentials"`
+		Buckets     []bucketReconcileResult      `json:"buckets"`
+	}
+
+	credentialsReconcileResult struct {
+		AccessKeyID string `json:"access_key_id"`
+		Status      string `json:"status"`
+		// SecretAccessKey is returned once on creation.
+		SecretAccessKey string `json:"secret_access_key,omitempty"`
+		Error           string `json:"error,omitempty"`
+	}
+
+	bucketReconcileResult struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+// reconcile ensures credentials and then buckets of the document, so buckets
+// can be owned by credentials of the same document. Failed resources don't
+// stop the reconciliation, the document reconciled again retries them only.
+func (r *reconciler) reconcile(ctx context.Context, state desiredState) (reconcileResponse, bool) {
+	res := reconcileResponse{
+		Credentials: make([]credentialsReconcileResult, 0, len(state.Credentials)),
+		Buckets:     make([]bucketReconcileResult, 0, len(state.Buckets)),
+	}
+	ok := true
+
+	for _, creds := range state.Credentials {
+		result := credentialsReconcileResult{AccessKeyID: creds.AccessKeyID}
+		status, secret, err := r.ensureCredentials(ctx, creds)
+		if err != nil {
+			r.log.Error("could not reconcile credentials", zap.String("access_key_id", creds.AccessKeyID), zap.Error(err))
+			result.Status, result.Error, ok = reconcileFailed, err.Error(), false
+		} else {
+			result.Status, result.SecretAccessKey = string(status), secret
+		}
+		res.Credentials = append(res.Credentials, result)
+	}
+
+	for _, bucket := range state.Buckets {
+		result := bucketReconcileResult{Name: bucket.Name}
+		status, err := r.ensureBucket(ctx, bucket)
+		if err != nil {
+			r.log.Error("could not reconcile bucket", zap.String("bucket", bucket.Name), zap.Error(err))
+			result.Status, result.Error, ok = reconcileFailed, err.Error(), false
+		} else {
+			result.Status = string(status)
+		}
+		res.Buckets = append(res.Buckets, result)
+	}
+
+	return res, ok
+}
+
+// ensureCredentials issues the credentials if there are no credentials with
+// the access key ID in the registry of the container. Existing credentials
+// aren't changed, it returns the secret access key of created ones only.
+func (r *reconciler) ensureCredentials(ctx context.Context, creds desiredCredentials) (handler.ReconcileStatus, string, error) {
+	if creds.AccessKeyID == "" {
+		return "", "", errors.New("no access key id")
+	}
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(creds.Container); err != nil {
+		return "", "", fmt.Errorf("invalid container '%s'", creds.Container)
+	}
+
+	lifetime, err := time.ParseDuration(creds.Lifetime)
+	if err != nil || lifetime <= 0 {
+		return "", "", fmt.Errorf("invalid lifetime '%s'", creds.Lifetime)
+	}
+
+	if r.revoked != nil && r.revoked.IsRevoked(creds.AccessKeyID) {
+		return "", "", errors.New("access key id is revoked")
+	}
+
+	// expired records are kept too, credentials with the same access key ID
+	// would be ambiguous
+	records, err := r.creds.List(ctx, cnrID, registry.Filter{})
+	if err != nil {
+		return "", "", fmt.Errorf("list credentials: %w", err)
+	}
+	for _, record := range records {
+		if record.Alias == creds.AccessKeyID {
+			return handler.ReconcileUnchanged, "", nil
+		}
+	}
+
+	gates := []*keys.PublicKey{r.key.PublicKey()}
+	for _, gate := range creds.Gates {
+		raw, err := hex.DecodeString(gate)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid gate public key '%s'", gate)
+		}
+		gateKey, err := keys.NewPublicKeyFromBytes(raw, elliptic.P256())
+		if err != nil {
+			return "", "", fmt.Errorf("invalid gate public key '%s': %w", gate, err)
+		}
+		gates = append(gates, gateKey)
+	}
+
+	secret, err := r.agent.Issue(ctx, &authmate.IssueSecretOptions{
+		Container:         authmate.ContainerOptions{ID: cnrID},
+		NeoFSKey:          r.key,
+		GatesPublicKeys:   gates,
+		Lifetime:          lifetime,
+		ContainerPolicies: creds.ContainerPolicies,
+		Description:       creds.Description,
+		AccessKeyID:       creds.AccessKeyID,
+		AccessPolicy:      creds.AccessPolicy,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("issue credentials: %w", err)
+	}
+
+	r.log.Info("credentials are issued", zap.String("access_key_id", secret.AccessKeyID))
+
+	return handler.ReconcileCreated, secret.SecretAccessKey, nil
+}
+
+// ensureBucket reconciles the bucket with the box of the owner credentials.
+func (r *reconciler) ensureBucket(ctx context.Context, bucket desiredBucket) (handler.ReconcileStatus, error) {
+	if r.buckets == nil {
+		return "", errors.New("buckets reconciliation isn't supported")
+	}
+	if bucket.Owner == "" {
+		return "", errors.New("no owner access key id")
+	}
+
+	box, err := r.backend.ResolveAccessKey(ctx, bucket.Owner)
+	if err != nil {
+		return "", fmt.Errorf("resolve owner credentials: %w", err)
+	}
+
+	return r.buckets.ReconcileBucket(context.WithValue(ctx, api.BoxData, box), bucket.DesiredBucket)
+}
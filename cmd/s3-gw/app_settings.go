@@ -9,8 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -23,6 +24,7 @@ const (
 	defaultConnectTimeout     = 10 * time.Second
 	defaultStreamTimeout      = 10 * time.Second
 	defaultShutdownTimeout    = 15 * time.Second
+	defaultPoolDrainTimeout   = 5 * time.Minute
 
 	defaultPoolErrorThreshold uint32 = 100
 
@@ -30,6 +32,43 @@ const (
 	defaultMaxClientsDeadline = time.Second * 30
 
 	defaultMaxObjectDeletePerRequest = 1000
+
+	defaultSLOWindow      = 5 * time.Minute
+	defaultSLOMinRequests = 100
+
+	defaultDeadLettersReplayInterval = 10 * time.Minute
+	defaultDeadLettersReplayRate     = 10
+
+	defaultTrashCleanupInterval = time.Hour
+
+	defaultBatchOperationsInterval    = 10 * time.Second
+	defaultBatchOperationsConcurrency = 4
+
+	defaultLeaderElectionTTL = 30 * time.Second
+
+	defaultIMDSCredentialsTTL = time.Hour
+
+	defaultSTSDefaultDuration = time.Hour
+	defaultSTSMaxDuration     = 12 * time.Hour
+
+	defaultSTSWebIdentityJWKSTimeout = 10 * time.Second
+
+	defaultCredentialsVaultTimeout = 5 * time.Second
+
+	defaultRevocationsRefreshInterval = time.Minute
+
+	defaultBearerRenewalLifetime = 10
+
+	defaultMaxClockSkew = 15 * time.Minute
+
+	defaultReplayProtectionWindow = 15 * time.Minute
+	defaultReplayProtectionSize   = 100000
+
+	defaultSlowRequestsThreshold = 5 * time.Second
+	defaultSlowRequestsSize      = 100
+
+	defaultDistributedLockTTL     = 5 * time.Minute
+	defaultDistributedLockTimeout = 10 * time.Second
 )
 
 const ( // Settings.
@@ -40,6 +79,8 @@ const ( // Settings.
 	cfgWalletPath       = "wallet.path"
 	cfgWalletAddress    = "wallet.address"
 	cfgWalletPassphrase = "wallet.passphrase"
+	cfgWalletKey        = "wallet.key"
+	cfgWalletPrevKeys   = "wallet.previous_keys"
 	cmdWallet           = "wallet"
 	cmdAddress          = "address"
 
@@ -48,6 +89,7 @@ const ( // Settings.
 	cfgTLSEnabled  = "tls.enabled"
 	cfgTLSKeyFile  = "tls.key_file"
 	cfgTLSCertFile = "tls.cert_file"
+	cfgTLSCerts    = "tls.certificates"
 
 	// Pool config.
 	cfgConnectTimeout     = "connect_timeout"
@@ -55,6 +97,11 @@ const ( // Settings.
 	cfgHealthcheckTimeout = "healthcheck_timeout"
 	cfgRebalanceInterval  = "rebalance_interval"
 	cfgPoolErrorThreshold = "pool_error_threshold"
+	cfgConnectionTTL      = "connection_ttl"
+	cfgPoolDrainTimeout   = "connection_drain_timeout"
+	cfgSeparateWritePool  = "separate_write_pool"
+	cfgFirstByteTimeout   = "first_byte_timeout"
+	cfgHedgeToReplicas    = "hedge_to_replicas"
 
 	// Caching.
 	cfgObjectsCacheLifetime       = "cache.objects.lifetime"
@@ -71,14 +118,137 @@ const ( // Settings.
 	cfgAccessBoxCacheSize         = "cache.accessbox.size"
 	cfgAccessControlCacheLifetime = "cache.accesscontrol.lifetime"
 	cfgAccessControlCacheSize     = "cache.accesscontrol.size"
+	cfgTransformedCacheLifetime   = "cache.transform.lifetime"
+	cfgTransformedCacheSize       = "cache.transform.size"
+	cfgTransformedCacheMaxBytes   = "cache.transform.max_bytes"
 
 	// NATS.
-	cfgEnableNATS             = "nats.enabled"
-	cfgNATSEndpoint           = "nats.endpoint"
-	cfgNATSTimeout            = "nats.timeout"
-	cfgNATSTLSCertFile        = "nats.cert_file"
-	cfgNATSAuthPrivateKeyFile = "nats.key_file"
-	cfgNATSRootCAFiles        = "nats.root_ca"
+	cfgEnableNATS              = "nats.enabled"
+	cfgNATSEndpoint            = "nats.endpoint"
+	cfgNATSTimeout             = "nats.timeout"
+	cfgNATSTLSCertFile         = "nats.cert_file"
+	cfgNATSAuthPrivateKeyFile  = "nats.key_file"
+	cfgNATSRootCAFiles         = "nats.root_ca"
+	cfgNATSSigningSecret       = "nats.signing_secret"
+	cfgNATSDeadLetterContainer = "nats.dead_letter_container"
+
+	// Scanner.
+	cfgScannerMode     = "scanner.mode"
+	cfgScannerURL      = "scanner.url"
+	cfgScannerTimeout  = "scanner.timeout"
+	cfgScannerMaxSize  = "scanner.max_size"
+	cfgScannerFailOpen = "scanner.fail_open"
+
+	cfgScannerSigningType            = "scanner.signing.type"
+	cfgScannerSigningSecret          = "scanner.signing.secret"
+	cfgScannerSigningAccessKeyID     = "scanner.signing.access_key_id"
+	cfgScannerSigningSecretAccessKey = "scanner.signing.secret_access_key"
+	cfgScannerSigningRegion          = "scanner.signing.region"
+	cfgScannerSigningService         = "scanner.signing.service"
+
+	// Admission.
+	cfgAdmissionWebhooks = "admission.webhooks"
+	cfgAdmissionTimeout  = "admission.timeout"
+	cfgAdmissionFailOpen = "admission.fail_open"
+
+	cfgAdmissionSigningType            = "admission.signing.type"
+	cfgAdmissionSigningSecret          = "admission.signing.secret"
+	cfgAdmissionSigningAccessKeyID     = "admission.signing.access_key_id"
+	cfgAdmissionSigningSecretAccessKey = "admission.signing.secret_access_key"
+	cfgAdmissionSigningRegion          = "admission.signing.region"
+	cfgAdmissionSigningService         = "admission.signing.service"
+
+	// Transform.
+	cfgTransformEnabled       = "transform.enabled"
+	cfgTransformMaxSourceSize = "transform.max_source_size"
+	cfgTransformMaxConcurrent = "transform.max_concurrent"
+
+	// Listing export.
+	cfgListingExportEnabled = "listing_export.enabled"
+
+	// Object search.
+	cfgObjectSearchEnabled = "search.enabled"
+
+	// Decompression of gzip uploads.
+	cfgGzipUploadsEnabled         = "gzip_uploads.enabled"
+	cfgGzipUploadsMaxBufferedSize = "gzip_uploads.max_buffered_size"
+
+	// Owner privacy.
+	cfgPrivacySalt = "privacy.salt"
+
+	// Answers to unsupported operations.
+	cfgUnsupportedMode          = "unsupported_operations.mode"
+	cfgUnsupportedConfigReads   = "unsupported_operations.config_reads"
+	cfgUnsupportedConfigWrites  = "unsupported_operations.config_writes"
+	cfgUnsupportedConfigDeletes = "unsupported_operations.config_deletes"
+
+	// Anonymous requests.
+	cfgAnonymousEnabled       = "anonymous.enabled"
+	cfgAnonymousPublicBuckets = "anonymous.public_buckets"
+
+	// Credentials backends.
+	cfgCredentialsBackends       = "credentials.backends"
+	cfgCredentialsFilePath       = "credentials.file.path"
+	cfgCredentialsVaultAddress   = "credentials.vault.address"
+	cfgCredentialsVaultToken     = "credentials.vault.token"
+	cfgCredentialsVaultMount     = "credentials.vault.mount"
+	cfgCredentialsVaultPath      = "credentials.vault.path"
+	cfgCredentialsVaultTimeout   = "credentials.vault.timeout"
+	cfgCredentialsNeoFSContainer = "credentials.neofs.container"
+
+	// Revocation of access keys.
+	cfgRevocationFile      = "credentials.revocation.file"
+	cfgRevocationContainer = "credentials.revocation.container"
+
+	// Renewal of bearer tokens issued by the gateway.
+	cfgBearerRenewalEnabled  = "credentials.bearer_renewal.enabled"
+	cfgBearerRenewalLifetime = "credentials.bearer_renewal.lifetime"
+
+	// Latency objectives.
+	cfgSLOWindow      = "slo.window"
+	cfgSLOMinRequests = "slo.min_requests"
+	cfgSLOObjectives  = "slo.objectives"
+
+	// Diagnostics bundle.
+	cfgDiagnosticsDir                   = "diagnostics.dir"
+	cfgDiagnosticsSlowRequestsThreshold = "diagnostics.slow_requests.threshold"
+	cfgDiagnosticsSlowRequestsSize      = "diagnostics.slow_requests.size"
+
+	// Background jobs.
+	cfgJobs        = "jobs"
+	cfgJobsWorkers = "jobs.workers"
+
+	cfgTrashCleanupOwners          = "jobs.trash_cleanup.owners"
+	cfgTrashCleanupDryRun          = "jobs.trash_cleanup.dry_run"
+	cfgTrashCleanupReportContainer = "jobs.trash_cleanup.report_container"
+
+	cfgBatchOperationsEnabled = "jobs.batch_operations.enabled"
+	cfgBatchOperationsMaxJobs = "jobs.batch_operations.max_jobs"
+
+	cfgLeaderElectionContainer = "jobs.leader_election.container"
+	cfgLeaderElectionTTL       = "jobs.leader_election.ttl"
+
+	// Bucket settings check.
+	cfgSettingsCheckOwners      = "settings_check.owners"
+	cfgSettingsCheckRefuseStart = "settings_check.refuse_start"
+
+	// Distributed locking.
+	cfgDistributedLockEnabled = "distributed_lock.enabled"
+	cfgDistributedLockTTL     = "distributed_lock.ttl"
+	cfgDistributedLockTimeout = "distributed_lock.timeout"
+
+	// Bandwidth limits.
+	cfgBandwidthUploadPerConnection = "bandwidth.upload.per_connection"
+	cfgBandwidthUploadGlobal        = "bandwidth.upload.global"
+
+	// Request rate limits of access keys.
+	cfgRateLimitRPS   = "rate_limit.rps"
+	cfgRateLimitBurst = "rate_limit.burst"
+
+	// Container ownership check.
+	cfgOwnershipMode         = "container_ownership.mode"
+	cfgOwnershipSharedOwners = "container_ownership.shared_owners"
+	cfgOwnershipBuckets      = "container_ownership.buckets"
 
 	// Policy.
 	cfgPolicyDefault       = "placement_policy.default"
@@ -91,13 +261,54 @@ const ( // Settings.
 	cfgMaxClientsCount    = "max_clients_count"
 	cfgMaxClientsDeadline = "max_clients_deadline"
 
+	// Request limits.
+	cfgMaxHeaderCount = "max_header_count"
+
 	// Metrics / Profiler / Web.
 	cfgPrometheusEnabled = "prometheus.enabled"
 	cfgPrometheusAddress = "prometheus.address"
 	cfgPProfEnabled      = "pprof.enabled"
 	cfgPProfAddress      = "pprof.address"
-
-	cfgListenDomains = "listen_domains"
+	cfgAdminEnabled      = "admin.enabled"
+	cfgAdminAddress      = "admin.address"
+	cfgAdminTokens       = "admin.tokens"
+
+	// Metrics access.
+	cfgPrometheusAuthUsername    = "prometheus.auth.username"
+	cfgPrometheusAuthPassword    = "prometheus.auth.password"
+	cfgPrometheusAuthToken       = "prometheus.auth.token"
+	cfgPrometheusAllowedNetworks = "prometheus.allowed_networks"
+
+	// Instance metadata service emulation.
+	cfgIMDSEnabled        = "imds.enabled"
+	cfgIMDSAddress        = "imds.address"
+	cfgIMDSCredentialsTTL = "imds.credentials_ttl"
+	cfgIMDSRoles          = "imds.roles"
+
+	// Security token service.
+	cfgSTSEnabled         = "sts.enabled"
+	cfgSTSAddress         = "sts.address"
+	cfgSTSDefaultDuration = "sts.default_duration"
+	cfgSTSMaxDuration     = "sts.max_duration"
+	cfgSTSRoles           = "sts.roles"
+
+	cfgSTSWebIdentityIssuer               = "sts.web_identity.issuer"
+	cfgSTSWebIdentityAudience             = "sts.web_identity.audience"
+	cfgSTSWebIdentityJWKSURL              = "sts.web_identity.jwks_url"
+	cfgSTSWebIdentityContainer            = "sts.web_identity.container"
+	cfgSTSWebIdentityEACLRulesTemplate    = "sts.web_identity.eacl_rules_template"
+	cfgSTSWebIdentityAccessPolicyTemplate = "sts.web_identity.access_policy_template"
+
+	// Credentials gRPC API.
+	cfgCredentialsAPIEnabled      = "credentials_api.enabled"
+	cfgCredentialsAPIAddress      = "credentials_api.address"
+	cfgCredentialsAPICertFile     = "credentials_api.tls.cert_file"
+	cfgCredentialsAPIKeyFile      = "credentials_api.tls.key_file"
+	cfgCredentialsAPIClientCAFile = "credentials_api.tls.client_ca_file"
+	cfgCredentialsAPIClients      = "credentials_api.clients"
+
+	cfgListenDomains    = "listen_domains"
+	cfgPathStyleDomains = "path_style_domains"
 
 	// Peers.
 	cfgPeers = "peers"
@@ -114,11 +325,16 @@ const ( // Settings.
 	cmdHelp    = "help"
 	cmdVersion = "version"
 	cmdConfig  = "config"
+	cmdProfile = "profile"
 	cmdPProf   = "pprof"
 	cmdMetrics = "metrics"
 
 	cmdListenAddress = "listen_address"
 
+	// Named profiles of the config file, the selected one overrides other
+	// values of the file.
+	cfgProfiles = "profiles"
+
 	// Configuration of parameters of requests to NeoFS.
 	// Number of the object copies to consider PUT to NeoFS successful.
 	cfgSetCopiesNumber = "neofs.set_copies_number"
@@ -132,6 +348,32 @@ const ( // Settings.
 	// List of allowed AccessKeyID prefixes.
 	cfgAllowedAccessKeyIDPrefixes = "allowed_access_key_id_prefixes"
 
+	// Allowed difference between the signature time and the gateway time.
+	cfgMaxClockSkew = "max_clock_skew"
+
+	// Rejection of replayed requests signed with headers.
+	cfgReplayProtectionEnabled = "replay_protection.enabled"
+	cfgReplayProtectionWindow  = "replay_protection.window"
+	cfgReplayProtectionSize    = "replay_protection.size"
+
+	// Accept payloads of requests signed with headers which aren't signed.
+	cfgAllowUnsignedPayload = "allow_unsigned_payload"
+
+	// Accept requests signed with AWS Signature Version 2.
+	cfgSignatureV2Enabled = "signature_v2.enabled"
+
+	// Time to retry failed startup stages depending on NeoFS.
+	cfgStartupTimeout = "startup_timeout"
+
+	// Region and services accepted in credential scopes of signatures.
+	cfgSignatureRegion            = "signature_scope.region"
+	cfgSignatureAdditionalRegions = "signature_scope.additional_regions"
+	cfgSignatureRegionMismatch    = "signature_scope.region_mismatch"
+	cfgSignatureServices          = "signature_scope.services"
+
+	// Local directory journaling object writes until they're completed.
+	cfgWriteJournalPath = "write_journal_path"
+
 	// envPrefix is an environment variables prefix used for configuration.
 	envPrefix = "S3_GW"
 
@@ -148,8 +390,8 @@ var ignore = map[string]struct{}{
 	cmdVersion: {},
 }
 
-func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
-	var nodes []pool.NodeParam
+func fetchPeers(l *zap.Logger, v *viper.Viper) []connectionPeer {
+	var nodes []connectionPeer
 	for i := 0; ; i++ {
 		key := cfgPeers + "." + strconv.Itoa(i) + "."
 		address := v.GetString(key + "address")
@@ -167,7 +409,15 @@ func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
 			priority = 1
 		}
 
-		nodes = append(nodes, pool.NewNodeParam(priority, address, weight))
+		peer := connectionPeer{address: address, priority: priority, weight: weight}
+		if v.GetBool(key + "excluded") {
+			l.Info("skip excluded connection peer", zap.String("address", address))
+			peer.excluded = true
+			nodes = append(nodes, peer)
+			continue
+		}
+
+		nodes = append(nodes, peer)
 
 		l.Info("added connection peer",
 			zap.String("address", address),
@@ -177,6 +427,129 @@ func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
 	return nodes
 }
 
+func fetchAdminTokens(v *viper.Viper) ([]adminToken, error) {
+	var tokens []adminToken
+	for i := 0; ; i++ {
+		key := cfgAdminTokens + "." + strconv.Itoa(i) + "."
+
+		token := adminToken{
+			name:  v.GetString(key + "name"),
+			value: v.GetString(key + "value"),
+			role:  v.GetString(key + "role"),
+		}
+
+		if token.value == "" {
+			break
+		}
+		if _, ok := adminRoleLevels[token.role]; !ok {
+			return nil, fmt.Errorf("admin token '%s' has unknown role '%s'", token.name, token.role)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func fetchIMDSRoles(l *zap.Logger, v *viper.Viper) []imdsRole {
+	var roles []imdsRole
+	for i := 0; ; i++ {
+		key := cfgIMDSRoles + "." + strconv.Itoa(i) + "."
+
+		role := imdsRole{
+			name:        v.GetString(key + "name"),
+			accessKeyID: v.GetString(key + "access_key_id"),
+		}
+
+		if role.name == "" {
+			break
+		}
+		var addr oid.Address
+		if err := addr.DecodeString(strings.ReplaceAll(role.accessKeyID, "0", "/")); err != nil {
+			l.Warn("skip imds role with invalid access key id", zap.String("name", role.name), zap.Error(err))
+			continue
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles
+}
+
+func fetchSTSRoles(l *zap.Logger, v *viper.Viper) []stsRole {
+	var roles []stsRole
+	for i := 0; ; i++ {
+		key := cfgSTSRoles + "." + strconv.Itoa(i) + "."
+
+		role := stsRole{
+			name:        v.GetString(key + "name"),
+			accessKeyID: v.GetString(key + "access_key_id"),
+			principals:  v.GetStringSlice(key + "principals"),
+		}
+
+		if role.name == "" {
+			break
+		}
+		if role.accessKeyID == "" {
+			l.Warn("skip sts role without access key id", zap.String("name", role.name))
+			continue
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles
+}
+
+func fetchSLOConfig(l *zap.Logger, v *viper.Viper) metrics.SLOConfig {
+	cfg := metrics.SLOConfig{
+		Window:      v.GetDuration(cfgSLOWindow),
+		MinRequests: v.GetUint64(cfgSLOMinRequests),
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSLOWindow
+	}
+
+	for i := 0; ; i++ {
+		key := cfgSLOObjectives + "." + strconv.Itoa(i) + "."
+
+		objective := metrics.Objective{
+			API:       v.GetString(key + "api"),
+			Quantile:  v.GetFloat64(key + "quantile"),
+			Threshold: v.GetDuration(key + "threshold"),
+		}
+
+		if objective.API == "" {
+			break
+		}
+		if objective.Quantile <= 0 || objective.Quantile >= 1 || objective.Threshold <= 0 {
+			l.Warn("skip invalid latency objective", zap.String("api", objective.API),
+				zap.Float64("quantile", objective.Quantile), zap.Duration("threshold", objective.Threshold))
+			continue
+		}
+
+		cfg.Objectives = append(cfg.Objectives, objective)
+	}
+
+	return cfg
+}
+
+func fetchSlowRequestsConfig(l *zap.Logger, v *viper.Viper) metrics.SlowRequestsConfig {
+	cfg := metrics.SlowRequestsConfig{
+		Threshold: v.GetDuration(cfgDiagnosticsSlowRequestsThreshold),
+		Size:      v.GetInt(cfgDiagnosticsSlowRequestsSize),
+	}
+	if cfg.Size < 0 {
+		l.Error("invalid number of slow requests, default value is used",
+			zap.String("parameter", cfgDiagnosticsSlowRequestsSize),
+			zap.Int("value in config", cfg.Size),
+			zap.Int("default", defaultSlowRequestsSize))
+		cfg.Size = defaultSlowRequestsSize
+	}
+
+	return cfg
+}
+
 func fetchServers(v *viper.Viper) []ServerInfo {
 	var servers []ServerInfo
 
@@ -193,6 +566,20 @@ func fetchServers(v *viper.Viper) []ServerInfo {
 			break
 		}
 
+		for j := 0; ; j++ {
+			certKey := key + cfgTLSCerts + "." + strconv.Itoa(j) + "."
+
+			var certInfo CertificateInfo
+			certInfo.CertFile = v.GetString(certKey + "cert_file")
+			certInfo.KeyFile = v.GetString(certKey + "key_file")
+
+			if certInfo.CertFile == "" {
+				break
+			}
+
+			serverInfo.TLS.Certificates = append(serverInfo.TLS.Certificates, certInfo)
+		}
+
 		servers = append(servers, serverInfo)
 	}
 
@@ -222,6 +609,7 @@ func newSettings() *viper.Viper {
 	flags.StringP(cmdWallet, "w", "", `path to the wallet`)
 	flags.String(cmdAddress, "", `address of wallet account`)
 	flags.String(cmdConfig, "", "config path")
+	flags.String(cmdProfile, "", "profile of the config file to apply")
 
 	flags.Duration(cfgHealthcheckTimeout, defaultHealthcheckTimeout, "set timeout to check node health during rebalance")
 	flags.Duration(cfgConnectTimeout, defaultConnectTimeout, "set timeout to connect to NeoFS nodes")
@@ -251,6 +639,41 @@ func newSettings() *viper.Viper {
 
 	v.SetDefault(cfgPProfAddress, "localhost:8085")
 	v.SetDefault(cfgPrometheusAddress, "localhost:8086")
+	v.SetDefault(cfgAdminAddress, "localhost:8087")
+	v.SetDefault(cfgIMDSAddress, "localhost:8088")
+	v.SetDefault(cfgIMDSCredentialsTTL, defaultIMDSCredentialsTTL)
+	v.SetDefault(cfgSTSAddress, "localhost:8089")
+	v.SetDefault(cfgSTSDefaultDuration, defaultSTSDefaultDuration)
+	v.SetDefault(cfgSTSMaxDuration, defaultSTSMaxDuration)
+	v.SetDefault(cfgCredentialsAPIAddress, "localhost:8090")
+
+	// anonymous requests:
+	v.SetDefault(cfgAnonymousEnabled, true)
+	v.SetDefault(cfgMaxClockSkew, defaultMaxClockSkew)
+	v.SetDefault(cfgReplayProtectionWindow, defaultReplayProtectionWindow)
+	v.SetDefault(cfgReplayProtectionSize, defaultReplayProtectionSize)
+	v.SetDefault(cfgAllowUnsignedPayload, true)
+	v.SetDefault(cfgSignatureV2Enabled, false)
+
+	// jobs:
+	v.SetDefault(cfgLeaderElectionTTL, defaultLeaderElectionTTL)
+
+	// distributed locking:
+	v.SetDefault(cfgDistributedLockTTL, defaultDistributedLockTTL)
+	v.SetDefault(cfgDistributedLockTimeout, defaultDistributedLockTimeout)
+
+	// credentials backends:
+	v.SetDefault(cfgCredentialsBackends, []string{credentialsBackendAccessBox})
+	v.SetDefault(cfgBearerRenewalLifetime, defaultBearerRenewalLifetime)
+	v.SetDefault(cfgCredentialsVaultTimeout, defaultCredentialsVaultTimeout)
+
+	// latency objectives:
+	v.SetDefault(cfgSLOWindow, defaultSLOWindow)
+	v.SetDefault(cfgSLOMinRequests, defaultSLOMinRequests)
+
+	// diagnostics:
+	v.SetDefault(cfgDiagnosticsSlowRequestsThreshold, defaultSlowRequestsThreshold)
+	v.SetDefault(cfgDiagnosticsSlowRequestsSize, defaultSlowRequestsSize)
 
 	// Bind flags
 	if err := bindFlags(v, flags); err != nil {
@@ -308,6 +731,7 @@ func newSettings() *viper.Viper {
 
 		fmt.Printf("%s_%s_[N]_ADDRESS = string\n", envPrefix, strings.ToUpper(cfgPeers))
 		fmt.Printf("%s_%s_[N]_WEIGHT = 0..1 (float)\n", envPrefix, strings.ToUpper(cfgPeers))
+		fmt.Printf("%s_%s_[N]_EXCLUDED = bool\n", envPrefix, strings.ToUpper(cfgPeers))
 
 		os.Exit(0)
 	case versionFlag != nil && *versionFlag:
@@ -319,6 +743,8 @@ func newSettings() *viper.Viper {
 		if err := readConfig(v); err != nil {
 			panic(err)
 		}
+	} else if v.GetString(cmdProfile) != "" {
+		panic(fmt.Errorf("profile '%s' is selected without config file", v.GetString(cmdProfile)))
 	}
 
 	return v
@@ -334,6 +760,9 @@ func bindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
 	if err := v.BindPFlag(cmdConfig, flags.Lookup(cmdConfig)); err != nil {
 		return err
 	}
+	if err := v.BindPFlag(cmdProfile, flags.Lookup(cmdProfile)); err != nil {
+		return err
+	}
 	if err := v.BindPFlag(cfgWalletPath, flags.Lookup(cmdWallet)); err != nil {
 		return err
 	}
@@ -378,8 +807,33 @@ func readConfig(v *viper.Viper) error {
 	if err = v.ReadConfig(cfgFile); err != nil {
 		return err
 	}
+	if err = cfgFile.Close(); err != nil {
+		return err
+	}
+
+	return applyProfile(v)
+}
+
+// applyProfile merges values of the selected profile of the config file over
+// other values of the file, so they're still overridden by environment
+// variables and flags.
+func applyProfile(v *viper.Viper) error {
+	name := v.GetString(cmdProfile)
+	if name == "" {
+		return nil
+	}
+
+	key := cfgProfiles + "." + name
+	if !v.IsSet(key) {
+		return fmt.Errorf("profile '%s' isn't found in config file", name)
+	}
+
+	profile := v.GetStringMap(key)
+	if len(profile) == 0 {
+		return fmt.Errorf("profile '%s' must be a map of config values", name)
+	}
 
-	return cfgFile.Close()
+	return v.MergeConfigMap(profile)
 }
 
 // newLogger constructs a Logger instance for the current application.
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+const profilesConfig = `
+logger:
+  level: info
+peers:
+  0:
+    address: node1:8080
+    weight: 1
+profiles:
+  dev:
+    logger:
+      level: debug
+    peers:
+      0:
+        address: localhost:8080
+  empty: {}
+  plain: value
+`
+
+// newTestSettings returns settings reading the config file with the content
+// like newSettings does.
+func newTestSettings(t *testing.T, content string) *viper.Viper {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetEnvPrefix(envPrefix)
+	v.SetConfigType("yaml")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AllowEmptyEnv(true)
+	v.Set(cmdConfig, path)
+
+	return v
+}
+
+func TestReadConfigProfile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		profile string
+		env     map[string]string
+		level   string
+		address string
+		weight  int
+	}{
+		{name: "no profile", level: "info", address: "node1:8080", weight: 1},
+		{name: "profile overrides file", profile: "dev", level: "debug", address: "localhost:8080", weight: 1},
+		{name: "profile from env", env: map[string]string{"S3_GW_PROFILE": "dev"}, level: "debug", address: "localhost:8080", weight: 1},
+		{name: "env overrides profile", profile: "dev", env: map[string]string{"S3_GW_LOGGER_LEVEL": "warn"}, level: "warn", address: "localhost:8080", weight: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, val := range tc.env {
+				t.Setenv(k, val)
+			}
+			v := newTestSettings(t, profilesConfig)
+			if tc.profile != "" {
+				v.Set(cmdProfile, tc.profile)
+			}
+
+			require.NoError(t, readConfig(v))
+			require.Equal(t, tc.level, v.GetString(cfgLoggerLevel))
+			require.Equal(t, tc.address, v.GetString(cfgPeers+".0.address"))
+			require.Equal(t, tc.weight, v.GetInt(cfgPeers+".0.weight"))
+		})
+	}
+}
+
+func TestReadConfigInvalidProfile(t *testing.T) {
+	for _, profile := range []string{"prod", "empty", "plain"} {
+		t.Run(profile, func(t *testing.T) {
+			v := newTestSettings(t, profilesConfig)
+			v.Set(cmdProfile, profile)
+			require.Error(t, readConfig(v))
+		})
+	}
+}
+
+func TestReadConfigProfileReload(t *testing.T) {
+	v := newTestSettings(t, profilesConfig)
+	v.Set(cmdProfile, "dev")
+	require.NoError(t, readConfig(v))
+
+	// values of the previous profile don't survive the reload
+	require.NoError(t, os.WriteFile(v.GetString(cmdConfig), []byte(`
+logger:
+  level: info
+profiles:
+  dev:
+    peers:
+      0:
+        address: localhost:8081
+`), 0o600))
+	require.NoError(t, readConfig(v))
+	require.Equal(t, "info", v.GetString(cfgLoggerLevel))
+	require.Equal(t, "localhost:8081", v.GetString(cfgPeers+".0.address"))
+}
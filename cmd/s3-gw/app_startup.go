@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	startupInitialBackoff = time.Second
+	startupMaxBackoff     = 30 * time.Second
+)
+
+// startup runs stages of the gateway startup depending on NeoFS. Failures of
+// the stages are retried with exponential backoff until the startup deadline,
+// so NeoFS may become available later than the gateway.
+type startup struct {
+	log      *zap.Logger
+	deadline time.Time
+}
+
+// newStartup creates a startup retrying stages for the given timeout, stages
+// aren't retried if it's not positive.
+func newStartup(l *zap.Logger, timeout time.Duration) *startup {
+	s := &startup{log: l, deadline: time.Now()}
+	if timeout > 0 {
+		s.deadline = s.deadline.Add(timeout)
+		l.Info("failed startup stages are retried", zap.Duration("timeout", timeout))
+	}
+
+	return s
+}
+
+// run runs the stage until it succeeds. The gateway fails if the stage
+// doesn't succeed before the deadline or ctx is done.
+func (s *startup) run(ctx context.Context, stage string, f func(context.Context) error) {
+	backoff := startupInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			s.log.Debug("startup stage completed", zap.String("stage", stage), zap.Int("attempts", attempt))
+			return
+		}
+
+		wait := time.Until(s.deadline)
+		if wait <= 0 || ctx.Err() != nil {
+			s.log.Fatal("startup stage failed", zap.String("stage", stage), zap.Int("attempts", attempt), zap.Error(err))
+		}
+		if wait > backoff {
+			wait = backoff
+		}
+
+		s.log.Warn("startup stage failed, retrying", zap.String("stage", stage), zap.Int("attempt", attempt),
+			zap.Duration("retry_in", wait), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			s.log.Fatal("startup interrupted", zap.String("stage", stage), zap.Error(err))
+		case <-time.After(wait):
+		}
+
+		if backoff *= 2; backoff > startupMaxBackoff {
+			backoff = startupMaxBackoff
+		}
+	}
+}
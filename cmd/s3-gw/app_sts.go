@@ -0,0 +1,543 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/oidc"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	stsNamespace = "https://sts.amazonaws.com/doc/2011-06-15/"
+	stsVersion   = "2011-06-15"
+
+	stsMinDuration  = 15 * time.Minute
+	stsMaxBodySize  = 64 << 10
+	stsAnyPrincipal = "*"
+
+	stsActionGetSessionToken           = "GetSessionToken"
+	stsActionAssumeRole                = "AssumeRole"
+	stsActionAssumeRoleWithWebIdentity = "AssumeRoleWithWebIdentity"
+)
+
+type (
+	stsHandler struct {
+		log             *zap.Logger
+		center          auth.Center
+		sessions        *auth.Sessions
+		roles           []stsRole
+		defaultDuration time.Duration
+		maxDuration     time.Duration
+		// webIdentity is nil if web identity federation isn't configured.
+		webIdentity *stsWebIdentity
+	}
+
+	// stsWebIdentity issues credentials for OIDC identity tokens of the
+	// configured issuer on AssumeRoleWithWebIdentity. Bearer token rules and
+	// access policies of the credentials are rendered from templates with
+	// claims of the tokens.
+	stsWebIdentity struct {
+		verifier     *oidc.Verifier
+		audience     string
+		agent        *authmate.Agent
+		key          *keys.PrivateKey
+		container    cid.ID
+		eaclRules    *template.Template
+		accessPolicy *template.Template
+	}
+
+	// stsTemplateData is available in templates of web identity credentials.
+	stsTemplateData struct {
+		Claims      oidc.Claims
+		SessionName string
+	}
+
+	// stsRole maps the role to credentials the temporary ones are issued for
+	// on AssumeRole.
+	stsRole struct {
+		name        string
+		accessKeyID string
+		// principals are access key IDs allowed to assume the role.
+		principals []string
+	}
+
+	stsCredentials struct {
+		AccessKeyID     string `xml:"AccessKeyId"`
+		SecretAccessKey string `xml:"SecretAccessKey"`
+		SessionToken    string `xml:"SessionToken"`
+		Expiration      string `xml:"Expiration"`
+	}
+
+	stsResponseMetadata struct {
+		RequestID string `xml:"RequestId"`
+	}
+
+	stsGetSessionTokenResponse struct {
+		XMLName          xml.Name            `xml:"GetSessionTokenResponse"`
+		Namespace        string              `xml:"xmlns,attr"`
+		Credentials      stsCredentials      `xml:"GetSessionTokenResult>Credentials"`
+		ResponseMetadata stsResponseMetadata `xml:"ResponseMetadata"`
+	}
+
+	stsAssumedRoleUser struct {
+		AssumedRoleID string `xml:"AssumedRoleId"`
+		Arn           string `xml:"Arn"`
+	}
+
+	stsAssumeRoleResponse struct {
+		XMLName          xml.Name            `xml:"AssumeRoleResponse"`
+		Namespace        string              `xml:"xmlns,attr"`
+		Credentials      stsCredentials      `xml:"AssumeRoleResult>Credentials"`
+		AssumedRoleUser  stsAssumedRoleUser  `xml:"AssumeRoleResult>AssumedRoleUser"`
+		ResponseMetadata stsResponseMetadata `xml:"ResponseMetadata"`
+	}
+
+	stsAssumeRoleWithWebIdentityResponse struct {
+		XMLName                     xml.Name            `xml:"AssumeRoleWithWebIdentityResponse"`
+		Namespace                   string              `xml:"xmlns,attr"`
+		Credentials                 stsCredentials      `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+		SubjectFromWebIdentityToken string              `xml:"AssumeRoleWithWebIdentityResult>SubjectFromWebIdentityToken"`
+		AssumedRoleUser             stsAssumedRoleUser  `xml:"AssumeRoleWithWebIdentityResult>AssumedRoleUser"`
+		Provider                    string              `xml:"AssumeRoleWithWebIdentityResult>Provider"`
+		Audience                    string              `xml:"AssumeRoleWithWebIdentityResult>Audience"`
+		ResponseMetadata            stsResponseMetadata `xml:"ResponseMetadata"`
+	}
+
+	stsErrorResponse struct {
+		XMLName   xml.Name `xml:"ErrorResponse"`
+		Namespace string   `xml:"xmlns,attr"`
+		Error     struct {
+			Type    string `xml:"Type"`
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		} `xml:"Error"`
+		RequestID string `xml:"RequestId"`
+	}
+)
+
+// NewSTSService creates a new service serving GetSessionToken, AssumeRole and
+// AssumeRoleWithWebIdentity actions of AWS Security Token Service. Requests are
+// signed with credentials of the gateway, temporary credentials are resolved
+// by the center. Credentials for web identities are issued by the agent with
+// the gateway key.
+func NewSTSService(v *viper.Viper, l *zap.Logger, center auth.Center, sessions *auth.Sessions, agent *authmate.Agent, key *keys.PrivateKey) *Service {
+	log := l.With(zap.String("service", "STS"))
+	h := &stsHandler{
+		log:             log,
+		center:          center,
+		sessions:        sessions,
+		roles:           fetchSTSRoles(log, v),
+		defaultDuration: v.GetDuration(cfgSTSDefaultDuration),
+		maxDuration:     v.GetDuration(cfgSTSMaxDuration),
+	}
+	if v.GetBool(cfgSTSEnabled) {
+		h.webIdentity = fetchSTSWebIdentity(log, v, agent, key)
+	}
+
+	if h.maxDuration < stsMinDuration {
+		log.Warn("sts max duration is too small, default one is used",
+			zap.Duration("max duration", h.maxDuration), zap.Duration("default", defaultSTSMaxDuration))
+		h.maxDuration = defaultSTSMaxDuration
+	}
+	if h.defaultDuration < stsMinDuration || h.defaultDuration > h.maxDuration {
+		log.Warn("sts default duration is out of range, max one is used",
+			zap.Duration("default duration", h.defaultDuration), zap.Duration("max duration", h.maxDuration))
+		h.defaultDuration = h.maxDuration
+	}
+
+	return &Service{
+		Server: &http.Server{
+			Addr:    v.GetString(cfgSTSAddress),
+			Handler: h,
+		},
+		enabled:     v.GetBool(cfgSTSEnabled),
+		serviceType: "STS",
+		log:         log,
+	}
+}
+
+// fetchSTSWebIdentity returns nil if web identity federation isn't configured
+// or its configuration is invalid.
+func fetchSTSWebIdentity(l *zap.Logger, v *viper.Viper, agent *authmate.Agent, key *keys.PrivateKey) *stsWebIdentity {
+	issuer := v.GetString(cfgSTSWebIdentityIssuer)
+	if issuer == "" {
+		return nil
+	}
+
+	wi, err := newSTSWebIdentity(v, agent, key)
+	if err != nil {
+		l.Error("invalid sts web identity configuration, AssumeRoleWithWebIdentity is disabled", zap.Error(err))
+		return nil
+	}
+
+	l.Info("web identities are federated", zap.String("issuer", issuer), zap.String("audience", wi.audience))
+
+	return wi
+}
+
+func newSTSWebIdentity(v *viper.Viper, agent *authmate.Agent, key *keys.PrivateKey) (*stsWebIdentity, error) {
+	wi := &stsWebIdentity{
+		audience: v.GetString(cfgSTSWebIdentityAudience),
+		agent:    agent,
+		key:      key,
+	}
+
+	var err error
+	wi.verifier, err = oidc.NewVerifier(oidc.Config{
+		Issuer:   v.GetString(cfgSTSWebIdentityIssuer),
+		Audience: wi.audience,
+		JWKSURL:  v.GetString(cfgSTSWebIdentityJWKSURL),
+		Client:   &http.Client{Timeout: defaultSTSWebIdentityJWKSTimeout},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = wi.container.DecodeString(v.GetString(cfgSTSWebIdentityContainer)); err != nil {
+		return nil, fmt.Errorf("invalid container: %w", err)
+	}
+
+	if wi.eaclRules, err = parseSTSTemplate(v.GetString(cfgSTSWebIdentityEACLRulesTemplate)); err != nil {
+		return nil, fmt.Errorf("eACL rules template: %w", err)
+	}
+	if path := v.GetString(cfgSTSWebIdentityAccessPolicyTemplate); path != "" {
+		if wi.accessPolicy, err = parseSTSTemplate(path); err != nil {
+			return nil, fmt.Errorf("access policy template: %w", err)
+		}
+	}
+
+	return wi, nil
+}
+
+// stsSessionKey derives the key of session tokens from the gateway key, so
+// gateways with the same key resolve temporary credentials issued by each
+// other.
+func stsSessionKey(key *keys.PrivateKey) []byte {
+	hash := sha256.Sum256(append([]byte("neofs-s3-gw sts"), key.Bytes()...))
+	return hash[:]
+}
+
+func (h *stsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := uuid.NewString()
+
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		h.writeError(w, requestID, http.StatusMethodNotAllowed, "InvalidAction", "unsupported method")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, stsMaxBodySize+1))
+	if err != nil || len(payload) > stsMaxBodySize {
+		h.writeError(w, requestID, http.StatusBadRequest, "MalformedInput", "couldn't read request body")
+		return
+	}
+	auth.WithSignedBody(r, payload)
+
+	params := r.URL.Query()
+	if strings.HasPrefix(r.Header.Get(auth.ContentTypeHdr), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(payload))
+		if err != nil {
+			h.writeError(w, requestID, http.StatusBadRequest, "MalformedInput", "couldn't parse request body")
+			return
+		}
+		for k, vs := range form {
+			params[k] = append(params[k], vs...)
+		}
+	}
+
+	if version := params.Get("Version"); version != stsVersion {
+		h.writeError(w, requestID, http.StatusBadRequest, "InvalidParameterValue", "unsupported version '"+version+"'")
+		return
+	}
+
+	action := params.Get("Action")
+	if action != stsActionGetSessionToken && action != stsActionAssumeRole && action != stsActionAssumeRoleWithWebIdentity {
+		h.writeError(w, requestID, http.StatusBadRequest, "InvalidAction", "unsupported action '"+action+"'")
+		return
+	}
+
+	duration := h.defaultDuration
+	if str := params.Get("DurationSeconds"); str != "" {
+		seconds, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || seconds < int64(stsMinDuration/time.Second) || seconds > int64(h.maxDuration/time.Second) {
+			h.writeError(w, requestID, http.StatusBadRequest, "ValidationError",
+				"DurationSeconds must be from "+strconv.FormatInt(int64(stsMinDuration/time.Second), 10)+
+					" to "+strconv.FormatInt(int64(h.maxDuration/time.Second), 10))
+			return
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+
+	// web identities are authenticated with their tokens, requests aren't signed
+	if action == stsActionAssumeRoleWithWebIdentity {
+		h.assumeRoleWithWebIdentity(r.Context(), w, requestID, params, duration)
+		return
+	}
+
+	box, err := h.center.Authenticate(r)
+	if err != nil {
+		h.log.Debug("failed to pass authentication", zap.String("action", action), zap.Error(err))
+		if errors.Is(err, auth.ErrNoAuthorizationHeader) {
+			h.writeError(w, requestID, http.StatusForbidden, "MissingAuthenticationToken", "request must be signed")
+			return
+		}
+		var s3err s3errors.Error
+		if !errors.As(err, &s3err) {
+			s3err = s3errors.GetAPIError(s3errors.ErrAccessDenied)
+		}
+		h.writeError(w, requestID, s3err.HTTPStatusCode, s3err.Code, s3err.Description)
+		return
+	}
+
+	if action == stsActionGetSessionToken {
+		h.getSessionToken(w, requestID, box, duration)
+	} else {
+		h.assumeRole(w, requestID, box, params, duration)
+	}
+}
+
+func (h *stsHandler) getSessionToken(w http.ResponseWriter, requestID string, box *auth.Box, duration time.Duration) {
+	// AWS rejects GetSessionToken with temporary credentials too
+	if box.Session != nil {
+		h.writeError(w, requestID, http.StatusForbidden, "AccessDenied",
+			"Cannot call GetSessionToken with session credentials")
+		return
+	}
+
+	session, err := h.sessions.Issue(box.AccessKeyID, time.Now().Add(duration))
+	if err != nil {
+		h.log.Error("could not issue temporary credentials", zap.Error(err))
+		h.writeError(w, requestID, http.StatusInternalServerError, "InternalFailure", "could not issue credentials")
+		return
+	}
+
+	h.log.Info("temporary credentials are issued", zap.String("action", "GetSessionToken"),
+		zap.String("access_key_id", box.AccessKeyID), zap.String("temporary_access_key_id", session.AccessKeyID),
+		zap.Time("expiration", session.Expiration))
+
+	h.writeResponse(w, &stsGetSessionTokenResponse{
+		Namespace:        stsNamespace,
+		Credentials:      newSTSCredentials(session),
+		ResponseMetadata: stsResponseMetadata{RequestID: requestID},
+	})
+}
+
+func (h *stsHandler) assumeRole(w http.ResponseWriter, requestID string, box *auth.Box, params url.Values, duration time.Duration) {
+	roleArn, sessionName := params.Get("RoleArn"), params.Get("RoleSessionName")
+	if roleArn == "" || sessionName == "" {
+		h.writeError(w, requestID, http.StatusBadRequest, "MissingParameter", "RoleArn and RoleSessionName are required")
+		return
+	}
+
+	// role ARN is arn:aws:iam::account:role/name, the name is used only
+	name := roleArn[strings.LastIndex(roleArn, "/")+1:]
+
+	// role chaining is allowed, credentials of the caller are checked then
+	caller := box.AccessKeyID
+	if box.Session != nil {
+		caller = box.Session.ParentAccessKeyID
+	}
+
+	var role *stsRole
+	for i := range h.roles {
+		if h.roles[i].name == name && h.roles[i].allows(caller) {
+			role = &h.roles[i]
+			break
+		}
+	}
+	if role == nil {
+		h.writeError(w, requestID, http.StatusForbidden, "AccessDenied", "Not authorized to perform sts:AssumeRole")
+		return
+	}
+
+	session, err := h.sessions.Issue(role.accessKeyID, time.Now().Add(duration))
+	if err != nil {
+		h.log.Error("could not issue temporary credentials", zap.Error(err))
+		h.writeError(w, requestID, http.StatusInternalServerError, "InternalFailure", "could not issue credentials")
+		return
+	}
+
+	h.log.Info("temporary credentials are issued", zap.String("action", "AssumeRole"),
+		zap.String("role", role.name), zap.String("session", sessionName), zap.String("access_key_id", caller),
+		zap.String("temporary_access_key_id", session.AccessKeyID), zap.Time("expiration", session.Expiration))
+
+	h.writeResponse(w, &stsAssumeRoleResponse{
+		Namespace:   stsNamespace,
+		Credentials: newSTSCredentials(session),
+		AssumedRoleUser: stsAssumedRoleUser{
+			AssumedRoleID: session.AccessKeyID + ":" + sessionName,
+			Arn:           "arn:aws:sts:::assumed-role/" + role.name + "/" + sessionName,
+		},
+		ResponseMetadata: stsResponseMetadata{RequestID: requestID},
+	})
+}
+
+func (h *stsHandler) assumeRoleWithWebIdentity(ctx context.Context, w http.ResponseWriter, requestID string, params url.Values, duration time.Duration) {
+	if h.webIdentity == nil {
+		h.writeError(w, requestID, http.StatusForbidden, "AccessDenied", "Web identity federation isn't configured")
+		return
+	}
+
+	roleArn, sessionName, token := params.Get("RoleArn"), params.Get("RoleSessionName"), params.Get("WebIdentityToken")
+	if roleArn == "" || sessionName == "" || token == "" {
+		h.writeError(w, requestID, http.StatusBadRequest, "MissingParameter", "RoleArn, RoleSessionName and WebIdentityToken are required")
+		return
+	}
+
+	claims, err := h.webIdentity.verifier.Verify(ctx, token)
+	if err != nil {
+		h.log.Debug("invalid web identity token", zap.Error(err))
+		switch {
+		case errors.Is(err, oidc.ErrExpiredToken):
+			h.writeError(w, requestID, http.StatusBadRequest, "ExpiredTokenException", "Token is expired")
+		case errors.Is(err, oidc.ErrInvalidToken):
+			h.writeError(w, requestID, http.StatusBadRequest, "InvalidIdentityToken", "Couldn't verify the identity token")
+		default:
+			h.log.Error("could not verify web identity token", zap.Error(err))
+			h.writeError(w, requestID, http.StatusBadRequest, "IDPCommunicationError", "Couldn't fetch keys of the identity provider")
+		}
+		return
+	}
+
+	secret, err := h.webIdentity.issue(ctx, stsTemplateData{Claims: claims, SessionName: sessionName}, duration)
+	if err != nil {
+		h.log.Error("could not issue web identity credentials", zap.String("subject", claims.Subject()), zap.Error(err))
+		h.writeError(w, requestID, http.StatusInternalServerError, "InternalFailure", "could not issue credentials")
+		return
+	}
+
+	h.log.Info("web identity credentials are issued", zap.String("action", stsActionAssumeRoleWithWebIdentity),
+		zap.String("subject", claims.Subject()), zap.String("session", sessionName),
+		zap.String("access_key_id", secret.AccessKeyID), zap.Duration("duration", duration))
+
+	name := roleArn[strings.LastIndex(roleArn, "/")+1:]
+	h.writeResponse(w, &stsAssumeRoleWithWebIdentityResponse{
+		Namespace: stsNamespace,
+		Credentials: stsCredentials{
+			AccessKeyID:     secret.AccessKeyID,
+			SecretAccessKey: secret.SecretAccessKey,
+			Expiration:      time.Now().Add(duration).Format(time.RFC3339),
+		},
+		SubjectFromWebIdentityToken: claims.Subject(),
+		AssumedRoleUser: stsAssumedRoleUser{
+			AssumedRoleID: secret.AccessKeyID + ":" + sessionName,
+			Arn:           "arn:aws:sts:::assumed-role/" + name + "/" + sessionName,
+		},
+		Audience:         h.webIdentity.audience,
+		ResponseMetadata: stsResponseMetadata{RequestID: requestID},
+	})
+}
+
+// issue creates credentials with the bearer token rules and the access policy
+// rendered from the templates, they're available to the gateway only.
+func (wi *stsWebIdentity) issue(ctx context.Context, data stsTemplateData, duration time.Duration) (*authmate.IssuedSecret, error) {
+	rules, err := renderSTSTemplate(wi.eaclRules, data)
+	if err != nil {
+		return nil, fmt.Errorf("render eACL rules: %w", err)
+	}
+
+	var policy []byte
+	if wi.accessPolicy != nil {
+		if policy, err = renderSTSTemplate(wi.accessPolicy, data); err != nil {
+			return nil, fmt.Errorf("render access policy: %w", err)
+		}
+	}
+
+	return wi.agent.Issue(ctx, &authmate.IssueSecretOptions{
+		Container:        authmate.ContainerOptions{ID: wi.container},
+		NeoFSKey:         wi.key,
+		GatesPublicKeys:  []*keys.PublicKey{wi.key.PublicKey()},
+		EACLRules:        rules,
+		SkipSessionRules: true,
+		Lifetime:         duration,
+		Description:      "web identity " + data.Claims.Subject(),
+		AccessPolicy:     policy,
+	})
+}
+
+func renderSTSTemplate(tmpl *template.Template, data stsTemplateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseSTSTemplate parses the template file. Values of claims must be
+// inserted with json function, so they're escaped.
+func parseSTSTemplate(path string) (*template.Template, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return template.New(path).Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(string(text))
+}
+
+func (r *stsRole) allows(accessKeyID string) bool {
+	for _, principal := range r.principals {
+		if principal == stsAnyPrincipal || principal == accessKeyID {
+			return true
+		}
+	}
+	return false
+}
+
+func newSTSCredentials(session *auth.Session) stsCredentials {
+	return stsCredentials{
+		AccessKeyID:     session.AccessKeyID,
+		SecretAccessKey: session.SecretAccessKey,
+		SessionToken:    session.SessionToken,
+		Expiration:      session.Expiration.Format(time.RFC3339),
+	}
+}
+
+func (h *stsHandler) writeResponse(w http.ResponseWriter, res interface{}) {
+	w.Header().Set("Content-Type", "text/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write response", zap.Error(err))
+	}
+}
+
+func (h *stsHandler) writeError(w http.ResponseWriter, requestID string, status int, code, message string) {
+	res := stsErrorResponse{Namespace: stsNamespace, RequestID: requestID}
+	res.Error.Type = "Sender"
+	if status >= http.StatusInternalServerError {
+		res.Error.Type = "Receiver"
+	}
+	res.Error.Code = code
+	res.Error.Message = message
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("could not write error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/data"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// Statuses of trashed versions in trash cleanup reports.
+const (
+	trashStatusRemoved = "removed"
+	trashStatusFailed  = "failed"
+	trashStatusDryRun  = "dry_run"
+)
+
+type (
+	// trashCleanupRun collects results of the trash cleanup run over buckets
+	// processed concurrently.
+	trashCleanupRun struct {
+		dryRun bool
+
+		mu            sync.Mutex
+		buckets       int
+		failedBuckets int
+		rows          [][]string
+		removed       int
+		failed        int
+	}
+
+	// trashCleanupReports stores reports of trash cleanup runs as CSV objects
+	// of the NeoFS container. The gateway must be allowed to put objects to
+	// the container.
+	trashCleanupReports struct {
+		neoFS layer.NeoFS
+		cnrID cid.ID
+		owner user.ID
+	}
+)
+
+func newTrashCleanupRun(dryRun bool) *trashCleanupRun {
+	return &trashCleanupRun{dryRun: dryRun}
+}
+
+// add records versions of the bucket processed by the cleanup, err is the
+// error which interrupted the cleanup of the bucket.
+func (r *trashCleanupRun) add(bktInfo *data.BucketInfo, entries []layer.TrashCleanupEntry, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buckets++
+	if err != nil {
+		r.failedBuckets++
+	}
+
+	for _, entry := range entries {
+		status, reason := trashStatusRemoved, ""
+		switch {
+		case r.dryRun:
+			status = trashStatusDryRun
+		case entry.Err != nil:
+			status, reason = trashStatusFailed, entry.Err.Error()
+			r.failed++
+		default:
+			r.removed++
+		}
+
+		r.rows = append(r.rows, []string{
+			bktInfo.Name,
+			url.QueryEscape(entry.Version.FilePath),
+			entry.Version.OID.EncodeToString(),
+			entry.Version.Deleted.UTC().Format(time.RFC3339),
+			status,
+			reason,
+		})
+	}
+}
+
+// summary returns log fields summing up the run.
+func (r *trashCleanupRun) summary() []zap.Field {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return []zap.Field{
+		zap.Bool("dry_run", r.dryRun),
+		zap.Int("buckets", r.buckets),
+		zap.Int("failed_buckets", r.failedBuckets),
+		zap.Int("expired_versions", len(r.rows)),
+		zap.Int("removed", r.removed),
+		zap.Int("failed", r.failed),
+	}
+}
+
+// put stores the report of the run started at the given time, rows contain
+// the bucket, URL-encoded key, version ID, deletion time, status and error.
+func (x *trashCleanupReports) put(ctx context.Context, started time.Time, run *trashCleanupRun) error {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	var buf bytes.Buffer
+	wr := csv.NewWriter(&buf)
+	if err := wr.WriteAll(run.rows); err != nil {
+		return err
+	}
+
+	name := "trash-cleanup-" + started.UTC().Format("20060102T150405Z") + ".csv"
+	_, err := x.neoFS.CreateObject(ctx, layer.PrmObjectCreate{
+		Container:    x.cnrID,
+		Creator:      x.owner,
+		CreationTime: started,
+		Filepath:     name,
+		Attributes:   [][2]string{{object.AttributeContentType, "text/csv"}},
+		PayloadSize:  uint64(buf.Len()),
+		Payload:      &buf,
+	})
+	if err != nil {
+		return fmt.Errorf("create report object: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
@@ -21,12 +22,20 @@ type (
 		Enabled  bool
 		CertFile string
 		KeyFile  string
+		// Certificates are additional key pairs chosen by SNI, the default
+		// one is used if none of them matches the requested server name.
+		Certificates []CertificateInfo
+	}
+
+	CertificateInfo struct {
+		CertFile string
+		KeyFile  string
 	}
 
 	Server interface {
 		Address() string
 		Listener() net.Listener
-		UpdateCert(certFile, keyFile string) error
+		UpdateCert(info ServerTLSInfo) error
 	}
 
 	server struct {
@@ -42,6 +51,7 @@ type (
 		certPath string
 		keyPath  string
 		cert     *tls.Certificate
+		sniCerts []*tls.Certificate
 	}
 )
 
@@ -53,8 +63,8 @@ func (s *server) Listener() net.Listener {
 	return s.listener
 }
 
-func (s *server) UpdateCert(certFile, keyFile string) error {
-	return s.tlsProvider.UpdateCert(certFile, keyFile)
+func (s *server) UpdateCert(info ServerTLSInfo) error {
+	return s.tlsProvider.UpdateCert(info)
 }
 
 func newServer(ctx context.Context, serverInfo ServerInfo, logger *zap.Logger) *server {
@@ -69,7 +79,7 @@ func newServer(ctx context.Context, serverInfo ServerInfo, logger *zap.Logger) *
 	}
 
 	if serverInfo.TLS.Enabled {
-		if err = tlsProvider.UpdateCert(serverInfo.TLS.CertFile, serverInfo.TLS.KeyFile); err != nil {
+		if err = tlsProvider.UpdateCert(serverInfo.TLS); err != nil {
 			logger.Fatal("failed to update cert", zap.Error(err))
 		}
 
@@ -85,34 +95,70 @@ func newServer(ctx context.Context, serverInfo ServerInfo, logger *zap.Logger) *
 	}
 }
 
-func (p *certProvider) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+// GetCertificate returns the first additional certificate valid for the
+// server name requested by the client (wildcard ones included) or the default
+// certificate.
+func (p *certProvider) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	if !p.Enabled {
 		return nil, errors.New("cert provider: disabled")
 	}
 
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+
+	if hello != nil && hello.ServerName != "" {
+		for _, cert := range p.sniCerts {
+			if hello.SupportsCertificate(cert) == nil {
+				return cert, nil
+			}
+		}
+	}
+
 	return p.cert, nil
 }
 
-func (p *certProvider) UpdateCert(certPath, keyPath string) error {
+func (p *certProvider) UpdateCert(info ServerTLSInfo) error {
 	if !p.Enabled {
 		return fmt.Errorf("tls disabled")
 	}
 
-	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	cert, err := loadKeyPair(info.CertFile, info.KeyFile)
 	if err != nil {
-		return fmt.Errorf("cannot load TLS key pair from certFile '%s' and keyFile '%s': %w", certPath, keyPath, err)
+		return err
+	}
+
+	sniCerts := make([]*tls.Certificate, 0, len(info.Certificates))
+	for _, certInfo := range info.Certificates {
+		sniCert, err := loadKeyPair(certInfo.CertFile, certInfo.KeyFile)
+		if err != nil {
+			return err
+		}
+		sniCerts = append(sniCerts, sniCert)
 	}
 
 	p.mu.Lock()
-	p.certPath = certPath
-	p.keyPath = keyPath
-	p.cert = &cert
+	p.certPath = info.CertFile
+	p.keyPath = info.KeyFile
+	p.cert = cert
+	p.sniCerts = sniCerts
 	p.mu.Unlock()
 	return nil
 }
 
+func loadKeyPair(certPath, keyPath string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load TLS key pair from certFile '%s' and keyFile '%s': %w", certPath, keyPath, err)
+	}
+
+	// Leaf is used to match certificate names on every handshake.
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, fmt.Errorf("cannot parse TLS certificate from certFile '%s': %w", certPath, err)
+	}
+
+	return &cert, nil
+}
+
 func (p *certProvider) FilePaths() (string, string) {
 	if !p.Enabled {
 		return "", ""
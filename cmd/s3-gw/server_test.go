@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes the self-signed certificate for the DNS names and its
+// key to the directory.
+func writeKeyPair(t *testing.T, dir, name string, dnsNames ...string) CertificateInfo {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	info := CertificateInfo{
+		CertFile: filepath.Join(dir, name+".crt"),
+		KeyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(info.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(info.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return info
+}
+
+func TestCertProviderGetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	def := writeKeyPair(t, dir, "default", "s3.example.com")
+
+	p := &certProvider{Enabled: true}
+	require.NoError(t, p.UpdateCert(ServerTLSInfo{
+		CertFile: def.CertFile,
+		KeyFile:  def.KeyFile,
+		Certificates: []CertificateInfo{
+			writeKeyPair(t, dir, "wildcard", "*.s3.example.org"),
+			writeKeyPair(t, dir, "other", "s3.example.net"),
+		},
+	}))
+
+	for _, tc := range []struct {
+		serverName string
+		expected   string
+	}{
+		{serverName: "s3.example.net", expected: "other"},
+		{serverName: "bucket.s3.example.org", expected: "wildcard"},
+		// wildcards match a single label only
+		{serverName: "a.bucket.s3.example.org", expected: "default"},
+		{serverName: "s3.example.com", expected: "default"},
+		{serverName: "unknown.example.com", expected: "default"},
+		// clients without SNI get the default certificate
+		{expected: "default"},
+	} {
+		t.Run(tc.serverName, func(t *testing.T) {
+			cert, err := p.GetCertificate(&tls.ClientHelloInfo{
+				ServerName:        tc.serverName,
+				SupportedVersions: []uint16{tls.VersionTLS13},
+				SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cert.Leaf.Subject.CommonName)
+		})
+	}
+
+	// invalid key pairs don't replace loaded ones
+	require.Error(t, p.UpdateCert(ServerTLSInfo{
+		CertFile:     def.CertFile,
+		KeyFile:      def.KeyFile,
+		Certificates: []CertificateInfo{{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: def.KeyFile}},
+	}))
+	cert, err := p.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:        "s3.example.net",
+		SupportedVersions: []uint16{tls.VersionTLS13},
+		SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "other", cert.Leaf.Subject.CommonName)
+
+	_, err = (&certProvider{}).GetCertificate(&tls.ClientHelloInfo{})
+	require.Error(t, err)
+}
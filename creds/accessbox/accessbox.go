@@ -7,10 +7,12 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accesspolicy"
 	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/session"
@@ -19,10 +21,19 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// ErrGateNotFound is returned if the box isn't issued for the gateway key.
+var ErrGateNotFound = errors.New("no gate data for the key")
+
 // Box represents friendly AccessBox.
 type Box struct {
 	Gate     *GateData
 	Policies []*ContainerPolicy
+	// AccessPolicy restricts requests made with the credentials, nil if the
+	// credentials aren't restricted.
+	AccessPolicy *accesspolicy.Document
+	// Renewable allows gateways to re-issue the expired bearer token signed
+	// by their key.
+	Renewable bool
 }
 
 // ContainerPolicy represents friendly AccessBox_ContainerPolicy.
@@ -134,7 +145,7 @@ func (x *AccessBox) GetTokens(owner *keys.PrivateKey) (*GateData, error) {
 		return gateData, nil
 	}
 
-	return nil, fmt.Errorf("no gate data for key  %x was found", ownerKey)
+	return nil, fmt.Errorf("%w: key %x", ErrGateNotFound, ownerKey)
 }
 
 // GetPlacementPolicy returns ContainerPolicy from AccessBox.
@@ -166,10 +177,19 @@ func (x *AccessBox) GetBox(owner *keys.PrivateKey) (*Box, error) {
 		return nil, fmt.Errorf("get policy: %w", err)
 	}
 
-	return &Box{
-		Gate:     tokens,
-		Policies: policy,
-	}, nil
+	box := &Box{
+		Gate:      tokens,
+		Policies:  policy,
+		Renewable: x.Renewable,
+	}
+
+	if len(x.AccessPolicy) != 0 {
+		if box.AccessPolicy, err = accesspolicy.Parse(x.AccessPolicy); err != nil {
+			return nil, fmt.Errorf("get access policy: %w", err)
+		}
+	}
+
+	return box, nil
 }
 
 func (x *AccessBox) addTokens(gatesData []*GateData, ephemeralKey *keys.PrivateKey, secret []byte) error {
@@ -171,3 +171,42 @@ func TestUnknownKey(t *testing.T) {
 	_, err = box.GetTokens(wrongCred)
 	require.Error(t, err)
 }
+
+func TestAccessPolicyInAccessBox(t *testing.T) {
+	var (
+		box2 AccessBox
+		tkn  bearer.Token
+	)
+
+	sec, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	cred, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	require.NoError(t, tkn.Sign(neofsecdsa.SignerRFC6979(sec.PrivateKey)))
+
+	box, _, err := PackTokens([]*GateData{NewGateData(cred.PublicKey(), &tkn)})
+	require.NoError(t, err)
+
+	parsed, err := box.GetBox(cred)
+	require.NoError(t, err)
+	require.Nil(t, parsed.AccessPolicy)
+	require.False(t, parsed.Renewable)
+
+	box.Renewable = true
+	box.AccessPolicy = []byte(`{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/*"}]}`)
+	data, err := box.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, box2.Unmarshal(data))
+
+	parsed, err = box2.GetBox(cred)
+	require.NoError(t, err)
+	require.NotNil(t, parsed.AccessPolicy)
+	require.Len(t, parsed.AccessPolicy.Statement, 1)
+	require.True(t, parsed.Renewable)
+
+	box2.AccessPolicy = []byte(`{"Statement":[]}`)
+	_, err = box2.GetBox(cred)
+	require.Error(t, err)
+}
@@ -0,0 +1,361 @@
+// Package accesspolicy implements IAM-style policies attached to issued
+// credentials. The policy restricts requests made with the credentials
+// additionally to NeoFS ACL: requests are allowed only if some statement
+// allows them and no statement denies them.
+package accesspolicy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Statement effects.
+const (
+	EffectAllow = "Allow"
+	EffectDeny  = "Deny"
+)
+
+// ResourcePrefix is the prefix of S3 resource ARNs.
+const ResourcePrefix = "arn:aws:s3:::"
+
+// Supported condition keys.
+const (
+	ConditionSourceIP        = "aws:SourceIp"
+	ConditionSecureTransport = "aws:SecureTransport"
+	ConditionUserAgent       = "aws:UserAgent"
+	ConditionReferer         = "aws:Referer"
+	ConditionPrefix          = "s3:prefix"
+	ConditionDelimiter       = "s3:delimiter"
+)
+
+var conditionKeys = []string{
+	ConditionSourceIP,
+	ConditionSecureTransport,
+	ConditionUserAgent,
+	ConditionReferer,
+	ConditionPrefix,
+	ConditionDelimiter,
+}
+
+// conditionOperators are supported condition operators, negated operators
+// are satisfied if the key is absent.
+var conditionOperators = map[string]struct {
+	negated bool
+	match   func(pattern, value string) bool
+}{
+	"StringEquals":              {match: func(p, v string) bool { return p == v }},
+	"StringNotEquals":           {negated: true, match: func(p, v string) bool { return p == v }},
+	"StringEqualsIgnoreCase":    {match: strings.EqualFold},
+	"StringNotEqualsIgnoreCase": {negated: true, match: strings.EqualFold},
+	"StringLike":                {match: matchWildcard},
+	"StringNotLike":             {negated: true, match: matchWildcard},
+	"IpAddress":                 {match: matchIP},
+	"NotIpAddress":              {negated: true, match: matchIP},
+	"Bool":                      {match: strings.EqualFold},
+}
+
+type (
+	// Document is a policy document.
+	Document struct {
+		Version   string      `json:"Version,omitempty"`
+		ID        string      `json:"Id,omitempty"`
+		Statement []Statement `json:"Statement"`
+	}
+
+	// Statement is a single rule of the policy. Actions are matched
+	// case-insensitively and resources case-sensitively, both may contain
+	// '*' and '?' wildcards.
+	Statement struct {
+		Sid       string                       `json:"Sid,omitempty"`
+		Effect    string                       `json:"Effect"`
+		Action    Values                       `json:"Action"`
+		Resource  Values                       `json:"Resource"`
+		Condition map[string]map[string]Values `json:"Condition,omitempty"`
+	}
+
+	// Values is a list of strings which is a single string or an array in JSON.
+	Values []string
+
+	// Request is a request checked against the policy.
+	Request struct {
+		// Action is an S3 action, e.g. s3:GetObject.
+		Action string
+		// Resource is a resource ARN. It's '*' for requests not related to
+		// buckets. Resources ending with '*' denote all objects with the
+		// prefix, such requests are allowed only if the whole prefix is
+		// allowed and no denied resources overlap with it.
+		Resource string
+		// Conditions are values of condition keys.
+		Conditions map[string]string
+	}
+)
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = Values{s}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(v))
+}
+
+// Parse decodes and validates the policy document.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode policy: %w", err)
+	}
+
+	if err := doc.validate(); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func (d *Document) validate() error {
+	if d.Version != "" && d.Version != "2012-10-17" && d.Version != "2008-10-17" {
+		return fmt.Errorf("unsupported policy version '%s'", d.Version)
+	}
+	if len(d.Statement) == 0 {
+		return errors.New("policy has no statements")
+	}
+
+	for i := range d.Statement {
+		if err := d.Statement[i].validate(); err != nil {
+			return fmt.Errorf("statement %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Statement) validate() error {
+	if s.Effect != EffectAllow && s.Effect != EffectDeny {
+		return fmt.Errorf("invalid effect '%s'", s.Effect)
+	}
+
+	if len(s.Action) == 0 {
+		return errors.New("no actions")
+	}
+	for _, action := range s.Action {
+		if action != "*" && !strings.HasPrefix(strings.ToLower(action), "s3:") {
+			return fmt.Errorf("invalid action '%s'", action)
+		}
+	}
+
+	if len(s.Resource) == 0 {
+		return errors.New("no resources")
+	}
+	for _, resource := range s.Resource {
+		if resource != "*" && !strings.HasPrefix(resource, ResourcePrefix) {
+			return fmt.Errorf("invalid resource '%s'", resource)
+		}
+	}
+
+	// keys are normalized to make lookups in Request.Conditions exact
+	for operator, keys := range s.Condition {
+		if _, ok := conditionOperators[operator]; !ok {
+			return fmt.Errorf("unsupported condition operator '%s'", operator)
+		}
+
+		normalized := make(map[string]Values, len(keys))
+		for key, values := range keys {
+			name := normalizeConditionKey(key)
+			if name == "" {
+				return fmt.Errorf("unsupported condition key '%s'", key)
+			}
+			if operator == "IpAddress" || operator == "NotIpAddress" {
+				for _, value := range values {
+					if parseIPNet(value) == nil {
+						return fmt.Errorf("invalid ip address '%s'", value)
+					}
+				}
+			}
+			normalized[name] = values
+		}
+		s.Condition[operator] = normalized
+	}
+
+	return nil
+}
+
+func normalizeConditionKey(key string) string {
+	for _, name := range conditionKeys {
+		if strings.EqualFold(key, name) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// IsAllowed checks if the policy allows the request.
+func (d *Document) IsAllowed(req Request) bool {
+	var allowed bool
+
+	for i := range d.Statement {
+		s := &d.Statement[i]
+		if !s.matchAction(req.Action) || !s.matchConditions(req.Conditions) {
+			continue
+		}
+
+		if s.Effect == EffectDeny {
+			if s.overlapsResource(req.Resource) {
+				return false
+			}
+			continue
+		}
+
+		if !allowed {
+			allowed = s.coversResource(req.Resource)
+		}
+	}
+
+	return allowed
+}
+
+func (s *Statement) matchAction(action string) bool {
+	action = strings.ToLower(action)
+	for _, pattern := range s.Action {
+		if matchWildcard(strings.ToLower(pattern), action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Statement) coversResource(resource string) bool {
+	for _, pattern := range s.Resource {
+		if resource == "*" {
+			if pattern == "*" || pattern == ResourcePrefix+"*" {
+				return true
+			}
+			continue
+		}
+
+		// the trailing '*' of the requested resource is matched literally,
+		// so only patterns allowing any suffix cover it
+		if matchWildcard(pattern, resource) && (!strings.HasSuffix(resource, "*") || strings.HasSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Statement) overlapsResource(resource string) bool {
+	if resource == "*" || !strings.HasSuffix(resource, "*") {
+		return s.coversResource(resource)
+	}
+
+	prefix := strings.TrimSuffix(resource, "*")
+
+	for _, pattern := range s.Resource {
+		literal := pattern
+		if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+			literal = pattern[:i]
+			if strings.HasPrefix(prefix, literal) {
+				return true
+			}
+		}
+		if strings.HasPrefix(literal, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Statement) matchConditions(values map[string]string) bool {
+	for operator, keys := range s.Condition {
+		op := conditionOperators[operator]
+		for key, patterns := range keys {
+			value, ok := values[key]
+			if !ok {
+				if op.negated {
+					continue
+				}
+				return false
+			}
+
+			var matched bool
+			for _, pattern := range patterns {
+				if op.match(pattern, value) {
+					matched = true
+					break
+				}
+			}
+			if matched == op.negated {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// matchWildcard matches s against pattern with '*' matching any sequence of
+// characters and '?' matching any single character.
+func matchWildcard(pattern, s string) bool {
+	var (
+		p, i          int
+		star, starIdx = -1, 0
+	)
+
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, starIdx = p, i
+			p++
+		case star >= 0:
+			starIdx++
+			p, i = star+1, starIdx
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+func matchIP(pattern, value string) bool {
+	ip := net.ParseIP(value)
+	ipNet := parseIPNet(pattern)
+
+	return ip != nil && ipNet != nil && ipNet.Contains(ip)
+}
+
+// parseIPNet parses CIDR or a single IP address.
+func parseIPNet(s string) *net.IPNet {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
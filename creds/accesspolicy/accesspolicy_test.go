@@ -0,0 +1,125 @@
+package accesspolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Action": ["s3:GetObject", "s3:ListBucket"],
+			"Resource": "arn:aws:s3:::bucket/*",
+			"Condition": {"StringLike": {"S3:Prefix": "home/*"}}
+		}]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, Values{"s3:GetObject", "s3:ListBucket"}, doc.Statement[0].Action)
+	require.Equal(t, Values{"arn:aws:s3:::bucket/*"}, doc.Statement[0].Resource)
+	require.Equal(t, Values{"home/*"}, doc.Statement[0].Condition["StringLike"][ConditionPrefix])
+
+	for name, policy := range map[string]string{
+		"invalid json":       `{`,
+		"unknown field":      `{"Statement":[{"Effect":"Allow","NotAction":"s3:*","Resource":"*"}]}`,
+		"no statements":      `{"Statement":[]}`,
+		"invalid version":    `{"Version":"2020-01-01","Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`,
+		"invalid effect":     `{"Statement":[{"Effect":"Permit","Action":"*","Resource":"*"}]}`,
+		"no actions":         `{"Statement":[{"Effect":"Allow","Resource":"*"}]}`,
+		"invalid action":     `{"Statement":[{"Effect":"Allow","Action":"iam:*","Resource":"*"}]}`,
+		"no resources":       `{"Statement":[{"Effect":"Allow","Action":"*"}]}`,
+		"invalid resource":   `{"Statement":[{"Effect":"Allow","Action":"*","Resource":"bucket/*"}]}`,
+		"unknown operator":   `{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*","Condition":{"NumericEquals":{"s3:max-keys":"10"}}}]}`,
+		"unknown key":        `{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*","Condition":{"StringEquals":{"s3:x-amz-acl":"private"}}}]}`,
+		"invalid ip address": `{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*","Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/33"}}}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(policy))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestIsAllowed(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"Statement": [{
+			"Effect": "Allow",
+			"Action": "s3:ListAllMyBuckets",
+			"Resource": "*"
+		}, {
+			"Effect": "Allow",
+			"Action": "s3:ListBucket",
+			"Resource": "arn:aws:s3:::bucket",
+			"Condition": {"StringLike": {"s3:prefix": ["home/", "home/*"]}}
+		}, {
+			"Effect": "Allow",
+			"Action": "s3:*Object",
+			"Resource": "arn:aws:s3:::bucket/home/*"
+		}, {
+			"Effect": "Deny",
+			"Action": "s3:DeleteObject",
+			"Resource": "arn:aws:s3:::bucket/home/keep-*"
+		}, {
+			"Effect": "Deny",
+			"Action": "*",
+			"Resource": "*",
+			"Condition": {"NotIpAddress": {"aws:SourceIp": ["10.0.0.0/8", "192.168.1.1"]}}
+		}]
+	}`))
+	require.NoError(t, err)
+
+	ip := map[string]string{ConditionSourceIP: "10.1.2.3"}
+	withPrefix := func(prefix string) map[string]string {
+		return map[string]string{ConditionSourceIP: "192.168.1.1", ConditionPrefix: prefix}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		req     Request
+		allowed bool
+	}{
+		{"list buckets", Request{Action: "s3:ListAllMyBuckets", Resource: "*", Conditions: ip}, true},
+		{"create bucket", Request{Action: "s3:CreateBucket", Resource: "arn:aws:s3:::other", Conditions: ip}, false},
+		{"list prefix", Request{Action: "s3:ListBucket", Resource: "arn:aws:s3:::bucket", Conditions: withPrefix("home/docs")}, true},
+		{"list other prefix", Request{Action: "s3:ListBucket", Resource: "arn:aws:s3:::bucket", Conditions: withPrefix("etc/")}, false},
+		{"list without prefix", Request{Action: "s3:ListBucket", Resource: "arn:aws:s3:::bucket", Conditions: ip}, false},
+		{"get object", Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/home/file", Conditions: ip}, true},
+		{"action case", Request{Action: "S3:GETOBJECT", Resource: "arn:aws:s3:::bucket/home/file", Conditions: ip}, true},
+		{"get object outside prefix", Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/etc/file", Conditions: ip}, false},
+		{"get object acl", Request{Action: "s3:GetObjectAcl", Resource: "arn:aws:s3:::bucket/home/file", Conditions: ip}, false},
+		{"delete object", Request{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/home/file", Conditions: ip}, true},
+		{"delete denied object", Request{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/home/keep-me", Conditions: ip}, false},
+		{"put denied object", Request{Action: "s3:PutObject", Resource: "arn:aws:s3:::bucket/home/keep-me", Conditions: ip}, true},
+		{"foreign address", Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/home/file", Conditions: map[string]string{ConditionSourceIP: "8.8.8.8"}}, false},
+		{"unknown address", Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/home/file"}, false},
+		{"delete prefix", Request{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/home/tmp/*", Conditions: ip}, true},
+		{"delete prefix with denied objects", Request{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/home/*", Conditions: ip}, false},
+		{"delete all objects", Request{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/*", Conditions: ip}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.allowed, doc.IsAllowed(tc.req))
+		})
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, s string
+		matched    bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"home/*/docs", "home/user/docs", true},
+		{"home/*/docs", "home/user/docs/file", false},
+		{"*.txt", "dir/file.txt", true},
+		{"*.txt", "dir/file.txt.gz", false},
+		{"a*b*c", "aXbYbZc", true},
+	} {
+		require.Equal(t, tc.matched, matchWildcard(tc.pattern, tc.s), "%s ~ %s", tc.pattern, tc.s)
+	}
+}
@@ -0,0 +1,57 @@
+package accesspolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Presets are policies restricting operations allowed to the credentials.
+const (
+	// PresetReadOnly allows reading and listing objects and buckets only.
+	PresetReadOnly = "read-only"
+	// PresetNoDelete allows everything except deletions of objects and
+	// buckets, batch jobs and changes of versioning and lifecycle
+	// configurations, so versioned data can't be lost. Objects of unversioned
+	// buckets can still be overwritten.
+	PresetNoDelete = "no-delete"
+	// PresetListOnly allows listing objects and buckets only.
+	PresetListOnly = "list-only"
+)
+
+var presets = map[string]Document{
+	PresetReadOnly: {
+		Version: "2012-10-17",
+		Statement: []Statement{
+			{Effect: EffectAllow, Action: Values{"s3:Get*", "s3:List*"}, Resource: Values{"*"}},
+		},
+	},
+	PresetNoDelete: {
+		Version: "2012-10-17",
+		Statement: []Statement{
+			{Effect: EffectAllow, Action: Values{"*"}, Resource: Values{"*"}},
+			{Effect: EffectDeny, Action: Values{"s3:Delete*", "s3:CreateJob", "s3:PutBucketVersioning", "s3:PutLifecycleConfiguration"}, Resource: Values{"*"}},
+		},
+	},
+	PresetListOnly: {
+		Version: "2012-10-17",
+		Statement: []Statement{
+			{Effect: EffectAllow, Action: Values{"s3:List*"}, Resource: Values{"*"}},
+		},
+	},
+}
+
+// Preset returns the JSON policy document of the preset.
+func Preset(name string) ([]byte, error) {
+	doc, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy preset '%s', expected one of: %s", name, strings.Join(PresetNames(), ", "))
+	}
+
+	return json.Marshal(doc)
+}
+
+// PresetNames returns names of the supported presets.
+func PresetNames() []string {
+	return []string{PresetReadOnly, PresetNoDelete, PresetListOnly}
+}
@@ -0,0 +1,53 @@
+package accesspolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreset(t *testing.T) {
+	_, err := Preset("write-only")
+	require.Error(t, err)
+
+	requests := map[string]Request{
+		"list buckets":  {Action: "s3:ListAllMyBuckets", Resource: "*"},
+		"list objects":  {Action: "s3:ListBucket", Resource: "arn:aws:s3:::bucket"},
+		"get object":    {Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/file"},
+		"get acl":       {Action: "s3:GetBucketAcl", Resource: "arn:aws:s3:::bucket"},
+		"put object":    {Action: "s3:PutObject", Resource: "arn:aws:s3:::bucket/file"},
+		"create bucket": {Action: "s3:CreateBucket", Resource: "arn:aws:s3:::bucket"},
+		"delete object": {Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/file"},
+		"delete prefix": {Action: "s3:DeleteObject", Resource: "arn:aws:s3:::bucket/*"},
+		"delete bucket": {Action: "s3:DeleteBucket", Resource: "arn:aws:s3:::bucket"},
+		"versioning":    {Action: "s3:PutBucketVersioning", Resource: "arn:aws:s3:::bucket"},
+		"lifecycle":     {Action: "s3:PutLifecycleConfiguration", Resource: "arn:aws:s3:::bucket"},
+		"batch job":     {Action: "s3:CreateJob", Resource: "arn:aws:s3:::bucket"},
+	}
+
+	for preset, allowed := range map[string][]string{
+		PresetReadOnly: {"list buckets", "list objects", "get object", "get acl"},
+		PresetListOnly: {"list buckets", "list objects"},
+		PresetNoDelete: {"list buckets", "list objects", "get object", "get acl", "put object", "create bucket"},
+	} {
+		t.Run(preset, func(t *testing.T) {
+			raw, err := Preset(preset)
+			require.NoError(t, err)
+			doc, err := Parse(raw)
+			require.NoError(t, err)
+
+			for name, req := range requests {
+				require.Equal(t, contains(allowed, name), doc.IsAllowed(req), name)
+			}
+		})
+	}
+}
+
+func contains(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}
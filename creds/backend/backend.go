@@ -0,0 +1,49 @@
+// Package backend provides sources of credentials resolving access key IDs
+// which aren't issued by authmate.
+package backend
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+)
+
+// Credentials are static credentials of the user stored in a file or Vault.
+type Credentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	// BearerToken is a base64 encoded NeoFS bearer token issued by the user
+	// to the gateway key.
+	BearerToken string `json:"bearer_token"`
+}
+
+// errUnknownAccessKey is returned for access key IDs unknown to the backend.
+var errUnknownAccessKey = s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID)
+
+// box returns the access box of the credentials.
+func (c *Credentials) box() (*accessbox.Box, error) {
+	if c.SecretAccessKey == "" {
+		return nil, errors.New("empty secret access key")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(c.BearerToken)
+	if err != nil {
+		return nil, fmt.Errorf("decode bearer token: %w", err)
+	}
+
+	var token bearer.Token
+	if err = token.Unmarshal(raw); err != nil {
+		return nil, fmt.Errorf("unmarshal bearer token: %w", err)
+	}
+
+	return &accessbox.Box{
+		Gate: &accessbox.GateData{
+			AccessKey:   c.SecretAccessKey,
+			BearerToken: &token,
+		},
+	}, nil
+}
@@ -0,0 +1,179 @@
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/s3errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	bearertest "github.com/nspcc-dev/neofs-sdk-go/bearer/test"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testCredentials(t *testing.T, accessKeyID string) Credentials {
+	token := bearertest.Token(t)
+	return Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: "secret-" + accessKeyID,
+		BearerToken:     base64.StdEncoding.EncodeToString(token.Marshal()),
+	}
+}
+
+func testCacheConfig() *cache.Config {
+	return &cache.Config{Size: 10, Lifetime: time.Minute, Logger: zap.NewNop()}
+}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	writeFile := func(list ...Credentials) string {
+		data, err := json.Marshal(list)
+		require.NoError(t, err)
+		path := filepath.Join(dir, "credentials.json")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		return path
+	}
+
+	f, err := NewFile(writeFile(testCredentials(t, "first"), testCredentials(t, "second")))
+	require.NoError(t, err)
+
+	box, err := f.ResolveAccessKey(ctx, "second")
+	require.NoError(t, err)
+	require.Equal(t, "secret-second", box.Gate.AccessKey)
+	require.NotNil(t, box.Gate.BearerToken)
+
+	_, err = f.ResolveAccessKey(ctx, "third")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	_, err = NewFile(writeFile(testCredentials(t, "first"), testCredentials(t, "first")))
+	require.Error(t, err)
+
+	_, err = NewFile(writeFile(testCredentials(t, "")))
+	require.Error(t, err)
+
+	invalid := testCredentials(t, "first")
+	invalid.BearerToken = "not a token"
+	_, err = NewFile(writeFile(invalid))
+	require.Error(t, err)
+
+	_, err = NewFile(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	creds := testCredentials(t, "vault-key")
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/s3/vault-key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var resp vaultSecretResponse
+		resp.Data.Data = creds
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer srv.Close()
+
+	v, err := NewVault(VaultConfig{Address: srv.URL, Token: "root", Mount: "kv", Path: "/s3/", Timeout: time.Second, Cache: testCacheConfig()})
+	require.NoError(t, err)
+
+	box, err := v.ResolveAccessKey(ctx, "vault-key")
+	require.NoError(t, err)
+	require.Equal(t, creds.SecretAccessKey, box.Gate.AccessKey)
+
+	// cached
+	_, err = v.ResolveAccessKey(ctx, "vault-key")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, requests.Load())
+
+	_, err = v.ResolveAccessKey(ctx, "other-key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	_, err = v.ResolveAccessKey(ctx, "../s3/vault-key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	v, err = NewVault(VaultConfig{Address: srv.URL, Token: "wrong", Mount: "kv", Path: "s3", Cache: testCacheConfig()})
+	require.NoError(t, err)
+	_, err = v.ResolveAccessKey(ctx, "vault-key")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	_, err = NewVault(VaultConfig{Address: "not an url", Cache: testCacheConfig()})
+	require.Error(t, err)
+}
+
+type neoFSMock struct {
+	attributes map[oid.ID]string
+}
+
+func (m *neoFSMock) SearchObjectsByAttribute(_ context.Context, _ cid.ID, attribute, value string) ([]oid.ID, error) {
+	var res []oid.ID
+	for id, alias := range m.attributes {
+		if attribute == registry.AttributeAccessKeyID && alias == value {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
+
+type credentialsMock struct {
+	tokens.Credentials
+	boxes map[oid.Address]*accessbox.Box
+}
+
+func (m *credentialsMock) GetBox(_ context.Context, addr oid.Address) (*accessbox.Box, error) {
+	return m.boxes[addr], nil
+}
+
+func TestNeoFS(t *testing.T) {
+	ctx := context.Background()
+	cnrID := cidtest.ID()
+
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(oidtest.ID())
+	box := &accessbox.Box{Gate: &accessbox.GateData{AccessKey: "secret"}}
+
+	neoFS := &neoFSMock{attributes: map[oid.ID]string{
+		addr.Object(): "ci-key",
+		oidtest.ID():  "duplicated",
+		oidtest.ID():  "duplicated",
+	}}
+	n := NewNeoFS(neoFS, &credentialsMock{boxes: map[oid.Address]*accessbox.Box{addr: box}}, cnrID, testCacheConfig())
+
+	res, err := n.ResolveAccessKey(ctx, "ci-key")
+	require.NoError(t, err)
+	require.Equal(t, box, res)
+
+	_, err = n.ResolveAccessKey(ctx, "unknown-key")
+	require.ErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+
+	_, err = n.ResolveAccessKey(ctx, "duplicated")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, s3errors.GetAPIError(s3errors.ErrInvalidAccessKeyID))
+}
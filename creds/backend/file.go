@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+)
+
+// File resolves access key IDs to credentials listed in a JSON file.
+type File struct {
+	boxes map[string]*accessbox.Box
+}
+
+// NewFile reads the JSON array of Credentials from the file at the path.
+func NewFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read credentials file: %w", err)
+	}
+
+	var list []Credentials
+	if err = json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("unmarshal credentials file: %w", err)
+	}
+
+	f := &File{boxes: make(map[string]*accessbox.Box, len(list))}
+	for i := range list {
+		if list[i].AccessKeyID == "" {
+			return nil, fmt.Errorf("credentials %d: empty access key id", i)
+		}
+		if _, ok := f.boxes[list[i].AccessKeyID]; ok {
+			return nil, fmt.Errorf("duplicated access key id '%s'", list[i].AccessKeyID)
+		}
+
+		box, err := list[i].box()
+		if err != nil {
+			return nil, fmt.Errorf("credentials '%s': %w", list[i].AccessKeyID, err)
+		}
+		f.boxes[list[i].AccessKeyID] = box
+	}
+
+	return f, nil
+}
+
+// ResolveAccessKey implements auth.CredentialsBackend interface method.
+func (f *File) ResolveAccessKey(_ context.Context, accessKeyID string) (*accessbox.Box, error) {
+	box, ok := f.boxes[accessKeyID]
+	if !ok {
+		return nil, errUnknownAccessKey
+	}
+	return box, nil
+}
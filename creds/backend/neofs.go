@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/registry"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+type (
+	// NeoFS represents virtual connection to NeoFS network.
+	NeoFS interface {
+		// SearchObjectsByAttribute returns identifiers of the container
+		// objects having the attribute with the value.
+		SearchObjectsByAttribute(ctx context.Context, cnrID cid.ID, attribute, value string) ([]oid.ID, error)
+	}
+
+	// NeoFSStorage resolves access key IDs chosen by issuers of credentials
+	// stored in the container. Access boxes of such credentials have
+	// S3-Access-Key-Id attribute.
+	NeoFSStorage struct {
+		neoFS NeoFS
+		boxes tokens.Credentials
+		cnrID cid.ID
+		// cache keeps addresses of access boxes, boxes are cached by tokens.Credentials
+		cache gcache.Cache
+	}
+)
+
+// NewNeoFS creates a new NeoFS backend for the container.
+func NewNeoFS(neoFS NeoFS, boxes tokens.Credentials, cnrID cid.ID, config *cache.Config) *NeoFSStorage {
+	return &NeoFSStorage{
+		neoFS: neoFS,
+		boxes: boxes,
+		cnrID: cnrID,
+		cache: gcache.New(config.Size).LRU().Expiration(config.Lifetime).Build(),
+	}
+}
+
+// ResolveAccessKey implements auth.CredentialsBackend interface method.
+func (n *NeoFSStorage) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	addr, err := n.address(ctx, accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	box, err := n.boxes.GetBox(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("get box: %w", err)
+	}
+
+	return box, nil
+}
+
+func (n *NeoFSStorage) address(ctx context.Context, accessKeyID string) (oid.Address, error) {
+	if entry, err := n.cache.Get(accessKeyID); err == nil {
+		if addr, ok := entry.(oid.Address); ok {
+			return addr, nil
+		}
+	}
+
+	var addr oid.Address
+	if accessKeyID == "" {
+		return addr, errUnknownAccessKey
+	}
+
+	ids, err := n.neoFS.SearchObjectsByAttribute(ctx, n.cnrID, registry.AttributeAccessKeyID, accessKeyID)
+	if err != nil {
+		return addr, fmt.Errorf("search credentials: %w", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return addr, errUnknownAccessKey
+	case 1:
+	default:
+		// anyone with access to the container can issue credentials, so
+		// ambiguous access key IDs are never resolved
+		return addr, fmt.Errorf("access key id '%s' is ambiguous: %d credentials found", accessKeyID, len(ids))
+	}
+
+	addr.SetContainer(n.cnrID)
+	addr.SetObject(ids[0])
+
+	if err = n.cache.Set(accessKeyID, addr); err != nil {
+		return addr, fmt.Errorf("put address into cache: %w", err)
+	}
+
+	return addr, nil
+}
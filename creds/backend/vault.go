@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+)
+
+type (
+	// Vault resolves access key IDs to credentials stored in KV version 2
+	// secrets engine of HashiCorp Vault. Every access key ID is a secret
+	// with secret_access_key and bearer_token keys.
+	Vault struct {
+		client  *http.Client
+		address string
+		token   string
+		mount   string
+		path    string
+		cache   gcache.Cache
+	}
+
+	// VaultConfig groups parameters of Vault connection.
+	VaultConfig struct {
+		// Address is an URL of Vault server.
+		Address string
+		Token   string
+		// Mount is a path of KV secrets engine, "secret" is used if empty.
+		Mount string
+		// Path is a path of secrets in the engine.
+		Path    string
+		Timeout time.Duration
+		// Cache keeps resolved credentials, so Vault isn't requested for
+		// every request of the user.
+		Cache *cache.Config
+	}
+
+	vaultSecretResponse struct {
+		Data struct {
+			Data Credentials `json:"data"`
+		} `json:"data"`
+	}
+)
+
+const defaultVaultMount = "secret"
+
+// NewVault creates a new Vault backend.
+func NewVault(cfg VaultConfig) (*Vault, error) {
+	if _, err := url.ParseRequestURI(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+
+	mount := cfg.Mount
+	if mount == "" {
+		mount = defaultVaultMount
+	}
+
+	return &Vault{
+		client:  &http.Client{Timeout: cfg.Timeout},
+		address: strings.TrimSuffix(cfg.Address, "/"),
+		token:   cfg.Token,
+		mount:   strings.Trim(mount, "/"),
+		path:    strings.Trim(cfg.Path, "/"),
+		cache:   gcache.New(cfg.Cache.Size).LRU().Expiration(cfg.Cache.Lifetime).Build(),
+	}, nil
+}
+
+// ResolveAccessKey implements auth.CredentialsBackend interface method.
+func (v *Vault) ResolveAccessKey(ctx context.Context, accessKeyID string) (*accessbox.Box, error) {
+	if entry, err := v.cache.Get(accessKeyID); err == nil {
+		if box, ok := entry.(*accessbox.Box); ok {
+			return box, nil
+		}
+	}
+
+	// access key IDs are taken from requests, they mustn't escape the path
+	if accessKeyID == "" || strings.ContainsAny(accessKeyID, "/?#") || accessKeyID == "." || accessKeyID == ".." {
+		return nil, errUnknownAccessKey
+	}
+
+	secretURL := v.address + "/v1/" + path.Join(v.mount, "data", v.path, url.PathEscape(accessKeyID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errUnknownAccessKey
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected vault response status: %s", resp.Status)
+	}
+
+	var secret vaultSecretResponse
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode vault response: %w", err)
+	}
+
+	box, err := secret.Data.Data.box()
+	if err != nil {
+		return nil, fmt.Errorf("credentials '%s': %w", accessKeyID, err)
+	}
+
+	if err = v.cache.Set(accessKeyID, box); err != nil {
+		return nil, fmt.Errorf("put credentials into cache: %w", err)
+	}
+
+	return box, nil
+}
@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signed := encodeSegment(t, jwtHeader{Alg: "RS256", Kid: kid}) + "." + encodeSegment(t, claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	signed := encodeSegment(t, jwtHeader{Alg: "ES256", Kid: kid}) + "." + encodeSegment(t, claims)
+	hash := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{
+			{
+				Kty: "RSA", Kid: "rsa", Use: "sig",
+				N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				Kty: "EC", Kid: "ec", Crv: "P-256",
+				X: base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+				Y: base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+			},
+			{Kty: "oct", Kid: "symmetric"},
+		}})
+	}))
+	defer srv.Close()
+
+	_, err = NewVerifier(Config{Issuer: "https://idp.example.com", JWKSURL: srv.URL})
+	require.Error(t, err)
+
+	v, err := NewVerifier(Config{Issuer: "https://idp.example.com", Audience: "s3", JWKSURL: srv.URL})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now()
+	claims := func() map[string]any {
+		return map[string]any{
+			"iss": "https://idp.example.com",
+			"aud": []string{"other", "s3"},
+			"sub": "alice",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+
+	res, err := v.Verify(ctx, signRS256(t, rsaKey, "rsa", claims()))
+	require.NoError(t, err)
+	require.Equal(t, "alice", res.Subject())
+	res, err = v.Verify(ctx, signES256(t, ecKey, "ec", claims()))
+	require.NoError(t, err)
+	require.Equal(t, "alice", res.Subject())
+	require.EqualValues(t, 1, fetches.Load())
+
+	c := claims()
+	c["aud"] = "s3"
+	_, err = v.Verify(ctx, signRS256(t, rsaKey, "rsa", c))
+	require.NoError(t, err)
+
+	c["exp"] = now.Add(-2 * Leeway).Unix()
+	_, err = v.Verify(ctx, signRS256(t, rsaKey, "rsa", c))
+	require.ErrorIs(t, err, ErrExpiredToken)
+
+	for name, modify := range map[string]func(map[string]any){
+		"issuer":     func(c map[string]any) { c["iss"] = "https://evil.example.com" },
+		"audience":   func(c map[string]any) { c["aud"] = "other" },
+		"expiration": func(c map[string]any) { delete(c, "exp") },
+		"not before": func(c map[string]any) { c["nbf"] = now.Add(2 * Leeway).Unix() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := claims()
+			modify(c)
+			_, err = v.Verify(ctx, signRS256(t, rsaKey, "rsa", c))
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// the signature must be made with the key of the ID and the algorithm
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = v.Verify(ctx, signRS256(t, otherKey, "rsa", claims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = v.Verify(ctx, signRS256(t, rsaKey, "ec", claims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	token := signRS256(t, rsaKey, "rsa", claims())
+	header := encodeSegment(t, jwtHeader{Alg: "none", Kid: "rsa"})
+	_, err = v.Verify(ctx, header+token[len(encodeSegment(t, jwtHeader{Alg: "RS256", Kid: "rsa"})):])
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = v.Verify(ctx, "not a token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// unknown keys are refetched, but not too often
+	require.EqualValues(t, 1, fetches.Load())
+	_, err = v.Verify(ctx, signRS256(t, rsaKey, "unknown", claims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.EqualValues(t, 1, fetches.Load())
+
+	v.now = func() time.Time { return now.Add(2 * jwksMinRefreshInterval) }
+	_, err = v.Verify(ctx, signRS256(t, rsaKey, "unknown", claims()))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.EqualValues(t, 2, fetches.Load())
+}
@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// Attributes of access box objects describing issued credentials.
+const (
+	AttributeIssuer      = "S3-Issuer"
+	AttributeExpiresAt   = "S3-Expires-At"
+	AttributeScopes      = "S3-Scopes"
+	AttributeGates       = "S3-Gates"
+	AttributeDescription = "S3-Description"
+	// AttributeAccessKeyID is an access key ID chosen by the issuer instead
+	// of the access box address, it's resolved by NeoFS credentials backend.
+	AttributeAccessKeyID = "S3-Access-Key-Id"
+)
+
+// Scopes of issued credentials.
+const (
+	ScopeObject          = "object"
+	ScopeContainerPut    = "container-put"
+	ScopeContainerDelete = "container-delete"
+	ScopeContainerEACL   = "container-seteacl"
+)
+
+const listSeparator = ","
+
+type (
+	// Record is a metadata of the issued credentials.
+	Record struct {
+		AccessKeyID     string    `json:"access_key_id"`
+		Issuer          string    `json:"issuer"`
+		CreatedAt       time.Time `json:"created_at"`
+		ExpiresAt       time.Time `json:"expires_at"`
+		ExpirationEpoch uint64    `json:"expiration_epoch"`
+		Scopes          []string  `json:"scopes"`
+		Gates           []string  `json:"gates"`
+		Description     string    `json:"description,omitempty"`
+		// Alias is the access key ID chosen by the issuer (optional).
+		Alias string `json:"alias,omitempty"`
+	}
+
+	// Filter selects listed credentials, empty fields match any record.
+	Filter struct {
+		Issuer string
+		// Gate is a hex encoded public key of the gateway.
+		Gate  string
+		Scope string
+		// Description is a substring of the description.
+		Description string
+		// ActiveAt selects credentials not expired at the time.
+		ActiveAt time.Time
+	}
+
+	// NeoFS represents virtual connection to NeoFS network.
+	NeoFS interface {
+		// SearchObjects returns identifiers of the container objects having
+		// the attribute.
+		SearchObjects(context.Context, cid.ID, string) ([]oid.ID, error)
+
+		// ReadObjectHeader reads header of the object from NeoFS network by
+		// address.
+		ReadObjectHeader(context.Context, oid.Address) (*object.Object, error)
+	}
+
+	// Registry lists credentials issued to the auth container.
+	Registry struct {
+		neoFS NeoFS
+	}
+)
+
+// New creates a new Registry using the given NeoFS connection.
+func New(neoFS NeoFS) *Registry {
+	return &Registry{neoFS: neoFS}
+}
+
+// Attributes returns attributes of the access box object describing the
+// credentials. Access key ID, creation time and expiration epoch are
+// attributes of the object itself, so they are ignored.
+func (r *Record) Attributes() [][2]string {
+	attrs := [][2]string{
+		{AttributeIssuer, r.Issuer},
+		{AttributeExpiresAt, r.ExpiresAt.UTC().Format(time.RFC3339)},
+		{AttributeScopes, strings.Join(r.Scopes, listSeparator)},
+		{AttributeGates, strings.Join(r.Gates, listSeparator)},
+	}
+	if r.Description != "" {
+		attrs = append(attrs, [2]string{AttributeDescription, r.Description})
+	}
+	if r.Alias != "" {
+		attrs = append(attrs, [2]string{AttributeAccessKeyID, r.Alias})
+	}
+
+	return attrs
+}
+
+// Match checks whether the record is selected by the filter.
+func (f *Filter) Match(r *Record) bool {
+	if f.Issuer != "" && r.Issuer != f.Issuer {
+		return false
+	}
+	if f.Gate != "" && !contains(r.Gates, strings.ToLower(f.Gate)) {
+		return false
+	}
+	if f.Scope != "" && !contains(r.Scopes, f.Scope) {
+		return false
+	}
+	if f.Description != "" && !strings.Contains(r.Description, f.Description) {
+		return false
+	}
+
+	return f.ActiveAt.IsZero() || r.ExpiresAt.After(f.ActiveAt)
+}
+
+// List returns credentials of the container selected by the filter sorted by
+// creation time. Credentials issued without registry attributes aren't listed.
+func (x *Registry) List(ctx context.Context, cnrID cid.ID, filter Filter) ([]Record, error) {
+	ids, err := x.neoFS.SearchObjects(ctx, cnrID, AttributeIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("search credentials: %w", err)
+	}
+
+	res := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		var addr oid.Address
+		addr.SetContainer(cnrID)
+		addr.SetObject(id)
+
+		header, err := x.neoFS.ReadObjectHeader(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("read credentials header '%s': %w", addr, err)
+		}
+
+		rec := recordFromHeader(addr, header)
+		if filter.Match(&rec) {
+			res = append(res, rec)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+
+	return res, nil
+}
+
+func recordFromHeader(addr oid.Address, header *object.Object) Record {
+	rec := Record{
+		// the same as issued by authmate
+		AccessKeyID: addr.Container().EncodeToString() + "0" + addr.Object().EncodeToString(),
+	}
+
+	for _, attr := range header.Attributes() {
+		switch attr.Key() {
+		case AttributeIssuer:
+			rec.Issuer = attr.Value()
+		case AttributeExpiresAt:
+			rec.ExpiresAt, _ = time.Parse(time.RFC3339, attr.Value())
+		case AttributeScopes:
+			rec.Scopes = split(attr.Value())
+		case AttributeGates:
+			rec.Gates = split(attr.Value())
+		case AttributeDescription:
+			rec.Description = attr.Value()
+		case AttributeAccessKeyID:
+			rec.Alias = attr.Value()
+		case object.AttributeTimestamp:
+			if unix, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				rec.CreatedAt = time.Unix(unix, 0).UTC()
+			}
+		case object.AttributeExpirationEpoch:
+			rec.ExpirationEpoch, _ = strconv.ParseUint(attr.Value(), 10, 64)
+		}
+	}
+
+	return rec
+}
+
+func split(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, listSeparator)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
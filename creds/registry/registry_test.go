@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNeoFS struct {
+	objects map[oid.Address]*object.Object
+}
+
+func (m *mockNeoFS) SearchObjects(_ context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error) {
+	var res []oid.ID
+	for addr, obj := range m.objects {
+		if addr.Container() != cnrID {
+			continue
+		}
+		for _, attr := range obj.Attributes() {
+			if attr.Key() == attribute {
+				res = append(res, addr.Object())
+			}
+		}
+	}
+	return res, nil
+}
+
+func (m *mockNeoFS) ReadObjectHeader(_ context.Context, addr oid.Address) (*object.Object, error) {
+	return m.objects[addr], nil
+}
+
+func (m *mockNeoFS) put(cnrID cid.ID, created time.Time, attrs [][2]string) oid.Address {
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(oidtest.ID())
+
+	attrs = append(attrs,
+		[2]string{object.AttributeTimestamp, strconv.FormatInt(created.Unix(), 10)},
+		[2]string{object.AttributeExpirationEpoch, "100"})
+
+	obj := object.New()
+	objAttrs := make([]object.Attribute, len(attrs))
+	for i := range attrs {
+		objAttrs[i].SetKey(attrs[i][0])
+		objAttrs[i].SetValue(attrs[i][1])
+	}
+	obj.SetAttributes(objAttrs...)
+	m.objects[addr] = obj
+
+	return addr
+}
+
+func TestRegistryList(t *testing.T) {
+	ctx := context.Background()
+	neoFS := &mockNeoFS{objects: make(map[oid.Address]*object.Object)}
+	cnrID := cidtest.ID()
+	now := time.Now().Truncate(time.Second).UTC()
+
+	first := Record{
+		Issuer:      "issuer1",
+		ExpiresAt:   now.Add(time.Hour),
+		Scopes:      []string{ScopeObject, ScopeContainerPut},
+		Gates:       []string{"02aa", "02bb"},
+		Description: "ci pipeline",
+		Alias:       "ci-pipeline-key",
+	}
+	firstAddr := neoFS.put(cnrID, now.Add(-time.Minute), first.Attributes())
+
+	second := Record{
+		Issuer:    "issuer2",
+		ExpiresAt: now.Add(-time.Hour),
+		Scopes:    []string{ScopeObject},
+		Gates:     []string{"02bb"},
+	}
+	neoFS.put(cnrID, now.Add(-2*time.Minute), second.Attributes())
+
+	// legacy credentials and credentials of other containers aren't listed
+	neoFS.put(cnrID, now, nil)
+	neoFS.put(cidtest.ID(), now, first.Attributes())
+
+	reg := New(neoFS)
+
+	records, err := reg.List(ctx, cnrID, Filter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "issuer2", records[0].Issuer)
+	require.Equal(t, "issuer1", records[1].Issuer)
+
+	rec := records[1]
+	require.Equal(t, cnrID.EncodeToString()+"0"+firstAddr.Object().EncodeToString(), rec.AccessKeyID)
+	require.Equal(t, now.Add(-time.Minute), rec.CreatedAt)
+	require.Equal(t, first.ExpiresAt, rec.ExpiresAt)
+	require.Equal(t, uint64(100), rec.ExpirationEpoch)
+	require.Equal(t, first.Scopes, rec.Scopes)
+	require.Equal(t, first.Gates, rec.Gates)
+	require.Equal(t, first.Description, rec.Description)
+	require.Equal(t, first.Alias, rec.Alias)
+	require.Empty(t, records[0].Alias)
+
+	for _, tc := range []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{name: "issuer", filter: Filter{Issuer: "issuer2"}, expected: []string{"issuer2"}},
+		{name: "gate", filter: Filter{Gate: "02BB"}, expected: []string{"issuer2", "issuer1"}},
+		{name: "scope", filter: Filter{Scope: ScopeContainerPut}, expected: []string{"issuer1"}},
+		{name: "description", filter: Filter{Description: "pipeline"}, expected: []string{"issuer1"}},
+		{name: "active", filter: Filter{ActiveAt: now}, expected: []string{"issuer1"}},
+		{name: "no match", filter: Filter{Issuer: "issuer1", Gate: "02cc"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := reg.List(ctx, cnrID, tc.filter)
+			require.NoError(t, err)
+
+			issuers := make([]string, len(records))
+			for i := range records {
+				issuers[i] = records[i].Issuer
+			}
+			require.ElementsMatch(t, tc.expected, issuers)
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Attributes of revocation objects.
+const (
+	AttributeRevokedAccessKeyID = "S3-Revoked-Access-Key-Id"
+	AttributeRevokedBy          = "S3-Revoked-By"
+	AttributeRevocationReason   = "S3-Revocation-Reason"
+)
+
+type (
+	// Entry is a revoked access key ID.
+	Entry struct {
+		AccessKeyID string    `json:"access_key_id"`
+		RevokedAt   time.Time `json:"revoked_at"`
+		// RevokedBy is a name of the admin API token used to revoke the key.
+		RevokedBy string `json:"revoked_by,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+	}
+
+	// Store persists revoked access key IDs.
+	Store interface {
+		// Save adds the entry to the store.
+		Save(context.Context, Entry) error
+		// Load returns all saved entries.
+		Load(context.Context) ([]Entry, error)
+	}
+
+	// List is a list of revoked access key IDs loaded from the store. It's
+	// refreshed periodically to get keys revoked by other gateways sharing
+	// the store, keys revoked by the gateway itself are applied immediately.
+	List struct {
+		store Store
+
+		mu      sync.RWMutex
+		revoked map[string]Entry
+	}
+
+	// FileStore keeps revoked access key IDs in a local JSON file.
+	FileStore struct {
+		path string
+		mu   sync.Mutex
+	}
+
+	// NeoFS represents virtual connection to NeoFS network.
+	NeoFS interface {
+		// CreateObject creates the object in NeoFS container.
+		CreateObject(context.Context, tokens.PrmObjectCreate) (oid.ID, error)
+
+		// SearchObjects returns identifiers of the container objects having
+		// the attribute.
+		SearchObjects(context.Context, cid.ID, string) ([]oid.ID, error)
+
+		// ReadObjectHeader reads header of the object from NeoFS network by
+		// address.
+		ReadObjectHeader(context.Context, oid.Address) (*object.Object, error)
+	}
+
+	// NeoFSStore keeps revoked access key IDs as objects of the NeoFS
+	// container, so gateways share them. The gateway must be allowed to put,
+	// search and head objects of the container.
+	NeoFSStore struct {
+		neoFS NeoFS
+		cnrID cid.ID
+		owner user.ID
+
+		mu sync.Mutex
+		// entries are cached, revocation objects never change
+		entries map[oid.ID]Entry
+	}
+)
+
+// NewList creates a new empty list of the store, it must be refreshed to
+// load saved entries.
+func NewList(store Store) *List {
+	return &List{
+		store:   store,
+		revoked: make(map[string]Entry),
+	}
+}
+
+// Refresh loads entries from the store.
+func (l *List) Refresh(ctx context.Context) error {
+	entries, err := l.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load revocations: %w", err)
+	}
+
+	revoked := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		if prev, ok := revoked[entry.AccessKeyID]; !ok || entry.RevokedAt.Before(prev.RevokedAt) {
+			revoked[entry.AccessKeyID] = entry
+		}
+	}
+
+	l.mu.Lock()
+	// keys revoked concurrently with loading must not be lost
+	for id, entry := range l.revoked {
+		if _, ok := revoked[id]; !ok {
+			revoked[id] = entry
+		}
+	}
+	l.revoked = revoked
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Revoke saves the entry revoked now to the store and rejects the access key
+// ID at once. An already revoked access key ID isn't saved again, the existing
+// entry is returned then.
+func (l *List) Revoke(ctx context.Context, entry Entry) (Entry, error) {
+	if entry.AccessKeyID == "" {
+		return Entry{}, errors.New("empty access key id")
+	}
+	entry.RevokedAt = time.Now().UTC().Truncate(time.Second)
+
+	l.mu.RLock()
+	prev, ok := l.revoked[entry.AccessKeyID]
+	l.mu.RUnlock()
+	if ok {
+		return prev, nil
+	}
+
+	if err := l.store.Save(ctx, entry); err != nil {
+		return Entry{}, fmt.Errorf("save revocation: %w", err)
+	}
+
+	l.mu.Lock()
+	l.revoked[entry.AccessKeyID] = entry
+	l.mu.Unlock()
+
+	return entry, nil
+}
+
+// IsRevoked checks whether the access key ID is revoked.
+func (l *List) IsRevoked(accessKeyID string) bool {
+	l.mu.RLock()
+	_, ok := l.revoked[accessKeyID]
+	l.mu.RUnlock()
+
+	return ok
+}
+
+// Entries returns revoked access key IDs sorted by revocation time.
+func (l *List) Entries() []Entry {
+	l.mu.RLock()
+	res := make([]Entry, 0, len(l.revoked))
+	for _, entry := range l.revoked {
+		res = append(res, entry)
+	}
+	l.mu.RUnlock()
+
+	sortEntries(res)
+	return res
+}
+
+// NewFileStore creates a store in the file at the path, the file is created
+// on the first revocation.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements Store interface method.
+func (f *FileStore) Load(context.Context) ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.load()
+}
+
+func (f *FileStore) load() ([]Entry, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read revocations file: %w", err)
+	}
+
+	var entries []Entry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unmarshal revocations file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Save implements Store interface method. The file is replaced atomically, so
+// it's never read partially written.
+func (f *FileStore) Save(_ context.Context, entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal revocations: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("replace revocations file: %w", err)
+	}
+
+	return nil
+}
+
+// NewNeoFSStore creates a store in the container, objects are created on
+// behalf of the owner.
+func NewNeoFSStore(neoFS NeoFS, cnrID cid.ID, owner user.ID) *NeoFSStore {
+	return &NeoFSStore{
+		neoFS:   neoFS,
+		cnrID:   cnrID,
+		owner:   owner,
+		entries: make(map[oid.ID]Entry),
+	}
+}
+
+// Save implements Store interface method.
+func (n *NeoFSStore) Save(ctx context.Context, entry Entry) error {
+	// revocation time is the creation time of the object
+	attrs := [][2]string{{AttributeRevokedAccessKeyID, entry.AccessKeyID}}
+	if entry.RevokedBy != "" {
+		attrs = append(attrs, [2]string{AttributeRevokedBy, entry.RevokedBy})
+	}
+	if entry.Reason != "" {
+		attrs = append(attrs, [2]string{AttributeRevocationReason, entry.Reason})
+	}
+
+	id, err := n.neoFS.CreateObject(ctx, tokens.PrmObjectCreate{
+		Creator:   n.owner,
+		Container: n.cnrID,
+		// revocations never expire
+		ExpirationEpoch: math.MaxUint64,
+		Attributes:      attrs,
+	})
+	if err != nil {
+		return fmt.Errorf("create revocation object: %w", err)
+	}
+
+	n.mu.Lock()
+	n.entries[id] = entry
+	n.mu.Unlock()
+
+	return nil
+}
+
+// Load implements Store interface method.
+func (n *NeoFSStore) Load(ctx context.Context) ([]Entry, error) {
+	ids, err := n.neoFS.SearchObjects(ctx, n.cnrID, AttributeRevokedAccessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("search revocations: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	res := make([]Entry, 0, len(ids))
+	for _, id := range ids {
+		entry, ok := n.entries[id]
+		if !ok {
+			var addr oid.Address
+			addr.SetContainer(n.cnrID)
+			addr.SetObject(id)
+
+			header, err := n.neoFS.ReadObjectHeader(ctx, addr)
+			if err != nil {
+				return nil, fmt.Errorf("read revocation header '%s': %w", addr, err)
+			}
+
+			entry = entryFromHeader(header)
+			n.entries[id] = entry
+		}
+
+		res = append(res, entry)
+	}
+
+	sortEntries(res)
+	return res, nil
+}
+
+func entryFromHeader(header *object.Object) Entry {
+	var entry Entry
+	for _, attr := range header.Attributes() {
+		switch attr.Key() {
+		case AttributeRevokedAccessKeyID:
+			entry.AccessKeyID = attr.Value()
+		case AttributeRevokedBy:
+			entry.RevokedBy = attr.Value()
+		case AttributeRevocationReason:
+			entry.Reason = attr.Value()
+		case object.AttributeTimestamp:
+			if unix, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				entry.RevokedAt = time.Unix(unix, 0).UTC()
+			}
+		}
+	}
+
+	return entry
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RevokedAt.Before(entries[j].RevokedAt)
+	})
+}
@@ -0,0 +1,125 @@
+package revocation
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNeoFS struct {
+	objects map[oid.Address]*object.Object
+	heads   int
+}
+
+func (m *mockNeoFS) CreateObject(_ context.Context, prm tokens.PrmObjectCreate) (oid.ID, error) {
+	var addr oid.Address
+	addr.SetContainer(prm.Container)
+	addr.SetObject(oidtest.ID())
+
+	attrs := append(prm.Attributes,
+		[2]string{object.AttributeTimestamp, strconv.FormatInt(time.Now().Unix(), 10)},
+		[2]string{object.AttributeExpirationEpoch, strconv.FormatUint(prm.ExpirationEpoch, 10)})
+
+	obj := object.New()
+	objAttrs := make([]object.Attribute, len(attrs))
+	for i := range attrs {
+		objAttrs[i].SetKey(attrs[i][0])
+		objAttrs[i].SetValue(attrs[i][1])
+	}
+	obj.SetAttributes(objAttrs...)
+	m.objects[addr] = obj
+
+	return addr.Object(), nil
+}
+
+func (m *mockNeoFS) SearchObjects(_ context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error) {
+	var res []oid.ID
+	for addr, obj := range m.objects {
+		if addr.Container() != cnrID {
+			continue
+		}
+		for _, attr := range obj.Attributes() {
+			if attr.Key() == attribute {
+				res = append(res, addr.Object())
+			}
+		}
+	}
+	return res, nil
+}
+
+func (m *mockNeoFS) ReadObjectHeader(_ context.Context, addr oid.Address) (*object.Object, error) {
+	m.heads++
+	return m.objects[addr], nil
+}
+
+func TestNeoFSStore(t *testing.T) {
+	ctx := context.Background()
+	neoFS := &mockNeoFS{objects: make(map[oid.Address]*object.Object)}
+	cnrID := cidtest.ID()
+
+	list := NewList(NewNeoFSStore(neoFS, cnrID, user.ID{}))
+	require.NoError(t, list.Refresh(ctx))
+	require.False(t, list.IsRevoked("key"))
+
+	entry, err := list.Revoke(ctx, Entry{AccessKeyID: "key", RevokedBy: "ops", Reason: "leaked"})
+	require.NoError(t, err)
+	require.True(t, list.IsRevoked("key"))
+	require.Equal(t, "ops", entry.RevokedBy)
+
+	// the key is revoked once
+	again, err := list.Revoke(ctx, Entry{AccessKeyID: "key", Reason: "second"})
+	require.NoError(t, err)
+	require.Equal(t, entry, again)
+	require.Len(t, neoFS.objects, 1)
+
+	// another gateway sharing the container gets the revocation on refresh
+	other := NewList(NewNeoFSStore(neoFS, cnrID, user.ID{}))
+	require.False(t, other.IsRevoked("key"))
+	require.NoError(t, other.Refresh(ctx))
+	require.True(t, other.IsRevoked("key"))
+	entries := other.Entries()
+	require.Len(t, entries, 1)
+	require.WithinDuration(t, entry.RevokedAt, entries[0].RevokedAt, time.Second)
+	entries[0].RevokedAt = entry.RevokedAt
+	require.Equal(t, entry, entries[0])
+
+	// headers of known revocations aren't read again
+	heads := neoFS.heads
+	require.NoError(t, other.Refresh(ctx))
+	require.Equal(t, heads, neoFS.heads)
+
+	_, err = list.Revoke(ctx, Entry{})
+	require.Error(t, err)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "revocations.json")
+
+	list := NewList(NewFileStore(path))
+	require.NoError(t, list.Refresh(ctx))
+	require.Empty(t, list.Entries())
+
+	first, err := list.Revoke(ctx, Entry{AccessKeyID: "first"})
+	require.NoError(t, err)
+	second, err := list.Revoke(ctx, Entry{AccessKeyID: "second", Reason: "rotated"})
+	require.NoError(t, err)
+
+	restarted := NewList(NewFileStore(path))
+	require.NoError(t, restarted.Refresh(ctx))
+	require.True(t, restarted.IsRevoked("first"))
+	require.True(t, restarted.IsRevoked("second"))
+	require.False(t, restarted.IsRevoked("third"))
+	require.ElementsMatch(t, []Entry{first, second}, restarted.Entries())
+}
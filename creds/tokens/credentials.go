@@ -1,6 +1,7 @@
 package tokens
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -19,11 +20,12 @@ type (
 	// Credentials is a bearer token get/put interface.
 	Credentials interface {
 		GetBox(context.Context, oid.Address) (*accessbox.Box, error)
-		Put(context.Context, cid.ID, user.ID, *accessbox.AccessBox, uint64, ...*keys.PublicKey) (oid.Address, error)
+		Put(context.Context, cid.ID, user.ID, *accessbox.AccessBox, uint64, [][2]string, ...*keys.PublicKey) (oid.Address, error)
 	}
 
 	cred struct {
-		key   *keys.PrivateKey
+		// keys of the gateway, the current one is the first.
+		keys  []*keys.PrivateKey
 		neoFS NeoFS
 		cache *cache.AccessBoxCache
 	}
@@ -43,6 +45,9 @@ type PrmObjectCreate struct {
 	// Last NeoFS epoch of the object lifetime.
 	ExpirationEpoch uint64
 
+	// Additional key-value object attributes (optional).
+	Attributes [][2]string
+
 	// Object payload.
 	Payload []byte
 }
@@ -74,9 +79,15 @@ var (
 
 var _ = New
 
-// New creates a new Credentials instance using the given cli and key.
-func New(neoFS NeoFS, key *keys.PrivateKey, config *cache.Config) Credentials {
-	return &cred{neoFS: neoFS, key: key, cache: cache.NewAccessBoxCache(config)}
+// New creates a new Credentials instance using the given cli and key. Access
+// boxes issued for previous keys of the gateway are decrypted with them, so
+// the key can be rotated without issuing all access boxes again.
+func New(neoFS NeoFS, key *keys.PrivateKey, config *cache.Config, previous ...*keys.PrivateKey) Credentials {
+	return &cred{
+		neoFS: neoFS,
+		keys:  append([]*keys.PrivateKey{key}, previous...),
+		cache: cache.NewAccessBoxCache(config),
+	}
 }
 
 func (c *cred) GetBox(ctx context.Context, addr oid.Address) (*accessbox.Box, error) {
@@ -90,7 +101,7 @@ func (c *cred) GetBox(ctx context.Context, addr oid.Address) (*accessbox.Box, er
 		return nil, fmt.Errorf("get access box: %w", err)
 	}
 
-	cachedBox, err = box.GetBox(c.key)
+	cachedBox, err = box.GetBox(c.gateKey(box))
 	if err != nil {
 		return nil, fmt.Errorf("get box: %w", err)
 	}
@@ -102,6 +113,22 @@ func (c *cred) GetBox(ctx context.Context, addr oid.Address) (*accessbox.Box, er
 	return cachedBox, nil
 }
 
+// gateKey returns the key of the gateway the box is issued for preferring the
+// current one. The current key is returned if the box is issued for none of
+// them, so the error refers to it.
+func (c *cred) gateKey(box *accessbox.AccessBox) *keys.PrivateKey {
+	for _, key := range c.keys {
+		pub := key.PublicKey().Bytes()
+		for _, gate := range box.Gates {
+			if bytes.Equal(gate.GatePublicKey, pub) {
+				return key
+			}
+		}
+	}
+
+	return c.keys[0]
+}
+
 func (c *cred) getAccessBox(ctx context.Context, addr oid.Address) (*accessbox.AccessBox, error) {
 	data, err := c.neoFS.ReadObjectPayload(ctx, addr)
 	if err != nil {
@@ -117,7 +144,7 @@ func (c *cred) getAccessBox(ctx context.Context, addr oid.Address) (*accessbox.A
 	return &box, nil
 }
 
-func (c *cred) Put(ctx context.Context, idCnr cid.ID, issuer user.ID, box *accessbox.AccessBox, expiration uint64, keys ...*keys.PublicKey) (oid.Address, error) {
+func (c *cred) Put(ctx context.Context, idCnr cid.ID, issuer user.ID, box *accessbox.AccessBox, expiration uint64, attributes [][2]string, keys ...*keys.PublicKey) (oid.Address, error) {
 	if len(keys) == 0 {
 		return oid.Address{}, ErrEmptyPublicKeys
 	} else if box == nil {
@@ -133,6 +160,7 @@ func (c *cred) Put(ctx context.Context, idCnr cid.ID, issuer user.ID, box *acces
 		Container:       idCnr,
 		Filepath:        strconv.FormatInt(time.Now().Unix(), 10) + "_access.box",
 		ExpirationEpoch: expiration,
+		Attributes:      attributes,
 		Payload:         data,
 	})
 	if err != nil {
@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type neoFSMock struct {
+	payloads map[oid.Address][]byte
+}
+
+func (n *neoFSMock) CreateObject(_ context.Context, prm PrmObjectCreate) (oid.ID, error) {
+	var addr oid.Address
+	addr.SetContainer(prm.Container)
+	addr.SetObject(oidtest.ID())
+	n.payloads[addr] = prm.Payload
+
+	return addr.Object(), nil
+}
+
+func (n *neoFSMock) ReadObjectPayload(_ context.Context, addr oid.Address) ([]byte, error) {
+	return n.payloads[addr], nil
+}
+
+func TestPreviousGateKeys(t *testing.T) {
+	ctx := context.Background()
+	neoFS := &neoFSMock{payloads: make(map[oid.Address][]byte)}
+
+	oldKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	newKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	otherKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	var tkn bearer.Token
+	tkn.SetEACLTable(*eacl.NewTable())
+	require.NoError(t, tkn.Sign(user.NewAutoIDSignerRFC6979(otherKey.PrivateKey)))
+
+	issue := func(gateKey *keys.PrivateKey) (oid.Address, string) {
+		box, secrets, err := accessbox.PackTokens([]*accessbox.GateData{accessbox.NewGateData(gateKey.PublicKey(), &tkn)})
+		require.NoError(t, err)
+
+		addr, err := New(neoFS, gateKey, cache.DefaultAccessBoxConfig(zap.NewNop())).
+			Put(ctx, cidtest.ID(), user.ID{}, box, 0, nil, gateKey.PublicKey())
+		require.NoError(t, err)
+
+		return addr, secrets.AccessKey
+	}
+
+	oldAddr, oldSecret := issue(oldKey)
+	newAddr, newSecret := issue(newKey)
+	otherAddr, _ := issue(otherKey)
+
+	creds := New(neoFS, newKey, cache.DefaultAccessBoxConfig(zap.NewNop()), oldKey)
+
+	box, err := creds.GetBox(ctx, newAddr)
+	require.NoError(t, err)
+	require.Equal(t, newSecret, box.Gate.AccessKey)
+
+	// boxes issued before the key rotation are still valid
+	box, err = creds.GetBox(ctx, oldAddr)
+	require.NoError(t, err)
+	require.Equal(t, oldSecret, box.Gate.AccessKey)
+
+	_, err = creds.GetBox(ctx, otherAddr)
+	require.Error(t, err)
+
+	// without previous keys boxes issued for them are rejected
+	_, err = New(neoFS, newKey, cache.DefaultAccessBoxConfig(zap.NewNop())).GetBox(ctx, oldAddr)
+	require.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Package bandwidth limits the rate request payloads are read from clients.
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Limiter is a token bucket of bytes refilled at the constant rate, its
+	// capacity is the number of bytes per second. Nil Limiter doesn't limit.
+	Limiter struct {
+		rate  float64
+		burst int
+
+		mu     sync.Mutex
+		tokens float64
+		last   time.Time
+	}
+
+	limitedReader struct {
+		io.ReadCloser
+		ctx      context.Context
+		limiters []*Limiter
+		// chunk is the maximum size read at once, so the payload is read
+		// smoothly instead of long pauses after large reads.
+		chunk int
+	}
+
+	connLimiterKey struct{}
+)
+
+// NewLimiter creates a limiter of rate bytes per second, it's nil if rate
+// isn't positive.
+func NewLimiter(rate int64) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	burst := rate
+	if burst > math.MaxInt32 {
+		burst = math.MaxInt32
+	}
+
+	return &Limiter{
+		rate:   float64(rate),
+		burst:  int(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WaitN takes n bytes from the bucket waiting until they're available or the
+// context is done. Bytes are taken in advance, so the next call waits for the
+// bucket to be refilled if n is greater than available bytes.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	tm := time.NewTimer(delay)
+	defer tm.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-tm.C:
+		return nil
+	}
+}
+
+// NewReader limits the rate r is read with all non-nil limiters until the
+// context is done.
+func NewReader(ctx context.Context, r io.ReadCloser, limiters ...*Limiter) io.ReadCloser {
+	res := &limitedReader{ReadCloser: r, ctx: ctx}
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		res.limiters = append(res.limiters, l)
+		if res.chunk == 0 || l.burst < res.chunk {
+			res.chunk = l.burst
+		}
+	}
+
+	if len(res.limiters) == 0 {
+		return r
+	}
+	return res
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.chunk {
+		p = p[:r.chunk]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	for _, l := range r.limiters {
+		if waitErr := l.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+// ConnContext returns http.Server ConnContext function adding the limiter of
+// rate bytes per second to the context of every connection, nil is returned
+// if rate isn't positive.
+func ConnContext(rate int64) func(context.Context, net.Conn) context.Context {
+	if rate <= 0 {
+		return nil
+	}
+
+	return func(ctx context.Context, _ net.Conn) context.Context {
+		return context.WithValue(ctx, connLimiterKey{}, NewLimiter(rate))
+	}
+}
+
+// connLimiter returns the limiter of the connection added by ConnContext.
+func connLimiter(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(connLimiterKey{}).(*Limiter)
+	return l
+}
+
+// Handler limits the rate bodies of requests are read with the limiter of
+// the connection and the global one shared by all connections.
+func Handler(h http.Handler, global *Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = NewReader(r.Context(), r.Body, connLimiter(r.Context()), global)
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,78 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	require.Nil(t, NewLimiter(0))
+	require.NoError(t, (*Limiter)(nil).WaitN(ctx, 1<<20))
+
+	l := NewLimiter(10000)
+
+	// the full bucket is taken at once
+	start := time.Now()
+	require.NoError(t, l.WaitN(ctx, 10000))
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	require.NoError(t, l.WaitN(ctx, 3000))
+	require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, l.WaitN(ctx, 10000), context.Canceled)
+}
+
+func TestReader(t *testing.T) {
+	payload := bytes.Repeat([]byte{'a'}, 15000)
+
+	r := io.NopCloser(bytes.NewReader(payload))
+	require.Equal(t, r, NewReader(context.Background(), r, nil, nil))
+
+	start := time.Now()
+	data, err := io.ReadAll(NewReader(context.Background(), r, NewLimiter(1<<20), NewLimiter(10000)))
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+	// the slowest limiter applies
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestHandler(t *testing.T) {
+	var read []byte
+	srv := httptest.NewUnstartedServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		read, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+	}), nil))
+	srv.Config.ConnContext = ConnContext(10000)
+	srv.Start()
+	defer srv.Close()
+
+	payload := bytes.Repeat([]byte{'a'}, 15000)
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		resp, err := http.Post(srv.URL, "application/octet-stream", bytes.NewReader(payload))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, payload, read)
+
+		// the connection is reused, so the second request waits for the
+		// bucket emptied by the first one
+		elapsed := time.Since(start)
+		if i == 0 {
+			require.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+		} else {
+			require.GreaterOrEqual(t, elapsed, 1400*time.Millisecond)
+		}
+	}
+}
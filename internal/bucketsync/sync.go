@@ -0,0 +1,340 @@
+package bucketsync
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"go.uber.org/zap"
+)
+
+// DefaultWorkers is a default number of objects copied in parallel.
+const DefaultWorkers = 8
+
+type (
+	// Bucket is a bucket of S3 compatible service.
+	Bucket struct {
+		Client s3iface.S3API
+		Name   string
+	}
+
+	// Target is a bucket objects are copied to.
+	Target struct {
+		Bucket
+		Uploader s3manageriface.UploaderAPI
+	}
+
+	// Config contains parameters of the synchronization.
+	Config struct {
+		Source Bucket
+		Target Target
+		// Prefix limits synchronized keys.
+		Prefix string
+		// Workers is a number of objects copied in parallel.
+		Workers int
+		// Delete removes target objects missing in the source.
+		Delete bool
+		// StateFile keeps copied objects, so interrupted synchronization is
+		// resumed without copying them again. It's optional.
+		StateFile string
+		Log       *zap.Logger
+	}
+
+	// Stats are results of the synchronization.
+	Stats struct {
+		Copied  int64 `json:"copied"`
+		Skipped int64 `json:"skipped"`
+		Deleted int64 `json:"deleted"`
+		Failed  int64 `json:"failed"`
+		Bytes   int64 `json:"bytes"`
+	}
+
+	// stateRecord is a line of the state file.
+	stateRecord struct {
+		Key  string `json:"key"`
+		ETag string `json:"etag"`
+	}
+
+	objectInfo struct {
+		size int64
+		etag string
+	}
+)
+
+// ErrChecksumMismatch is returned if the copied payload doesn't match the
+// source ETag.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Run copies objects missing in the target or differing from the source ones
+// and optionally removes target objects missing in the source.
+func Run(ctx context.Context, cfg Config) (*Stats, error) {
+	if cfg.Log == nil {
+		cfg.Log = zap.NewNop()
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+
+	state, err := loadState(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var stateFile *os.File
+	if cfg.StateFile != "" {
+		if stateFile, err = os.OpenFile(cfg.StateFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err != nil {
+			return nil, fmt.Errorf("open state file: %w", err)
+		}
+		defer stateFile.Close()
+	}
+
+	targetObjects, err := listObjects(ctx, cfg.Target.Bucket, cfg.Prefix, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list target objects: %w", err)
+	}
+
+	var (
+		stats   Stats
+		stateMu sync.Mutex
+		wg      sync.WaitGroup
+		keys    = make(chan string)
+		source  = make(map[string]objectInfo)
+	)
+
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				size, etag, err := copyObject(ctx, cfg, key)
+				if errors.Is(err, ErrChecksumMismatch) {
+					// corrupted copy mustn't look synchronized next time
+					if _, delErr := cfg.Target.Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+						Bucket: aws.String(cfg.Target.Name),
+						Key:    aws.String(key),
+					}); delErr != nil {
+						cfg.Log.Warn("couldn't delete corrupted object", zap.String("key", key), zap.Error(delErr))
+					}
+				}
+				if err != nil {
+					atomic.AddInt64(&stats.Failed, 1)
+					cfg.Log.Error("couldn't copy object", zap.String("key", key), zap.Error(err))
+					continue
+				}
+				atomic.AddInt64(&stats.Copied, 1)
+				atomic.AddInt64(&stats.Bytes, size)
+
+				if stateFile != nil {
+					stateMu.Lock()
+					err = appendState(stateFile, stateRecord{Key: key, ETag: etag})
+					stateMu.Unlock()
+					if err != nil {
+						cfg.Log.Warn("couldn't save state", zap.String("key", key), zap.Error(err))
+					}
+				}
+			}
+		}()
+	}
+
+	_, err = listObjects(ctx, cfg.Source, cfg.Prefix, func(key string, info objectInfo) bool {
+		source[key] = info
+		target, inTarget := targetObjects[key]
+		copied, inState := state[key]
+		if (inTarget && target == info) || (inState && copied == info.etag) {
+			atomic.AddInt64(&stats.Skipped, 1)
+			return true
+		}
+
+		select {
+		case keys <- key:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	close(keys)
+	wg.Wait()
+
+	if err != nil {
+		return &stats, fmt.Errorf("list source objects: %w", err)
+	}
+	if err = ctx.Err(); err != nil {
+		return &stats, err
+	}
+
+	// deletion is propagated only if the whole source was listed
+	if cfg.Delete {
+		for key := range targetObjects {
+			if _, ok := source[key]; ok {
+				continue
+			}
+			if _, err = cfg.Target.Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(cfg.Target.Name),
+				Key:    aws.String(key),
+			}); err != nil {
+				stats.Failed++
+				cfg.Log.Error("couldn't delete object", zap.String("key", key), zap.Error(err))
+				continue
+			}
+			stats.Deleted++
+		}
+	}
+
+	return &stats, nil
+}
+
+// listObjects lists objects of the bucket with the prefix. If the callback is
+// set, objects are passed to it instead of being collected.
+func listObjects(ctx context.Context, bkt Bucket, prefix string, cb func(string, objectInfo) bool) (map[string]objectInfo, error) {
+	res := make(map[string]objectInfo)
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bkt.Name)}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	err := bkt.Client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			info := objectInfo{size: aws.Int64Value(obj.Size), etag: normalizeETag(aws.StringValue(obj.ETag))}
+			if cb == nil {
+				res[aws.StringValue(obj.Key)] = info
+			} else if !cb(aws.StringValue(obj.Key), info) {
+				return false
+			}
+		}
+		return true
+	})
+
+	return res, err
+}
+
+// copyObject streams the object from the source to the target verifying its
+// checksum and returns the size and the ETag of the source object.
+func copyObject(ctx context.Context, cfg Config, key string) (int64, string, error) {
+	obj, err := cfg.Source.Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cfg.Source.Name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("get source object: %w", err)
+	}
+	defer obj.Body.Close()
+
+	etag := normalizeETag(aws.StringValue(obj.ETag))
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	counter := &countingReader{r: io.TeeReader(obj.Body, io.MultiWriter(md5Hash, sha256Hash))}
+
+	out, err := cfg.Target.Uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:             aws.String(cfg.Target.Name),
+		Key:                aws.String(key),
+		Body:               counter,
+		ContentType:        obj.ContentType,
+		ContentEncoding:    obj.ContentEncoding,
+		ContentDisposition: obj.ContentDisposition,
+		ContentLanguage:    obj.ContentLanguage,
+		CacheControl:       obj.CacheControl,
+		Metadata:           obj.Metadata,
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("upload target object: %w", err)
+	}
+
+	if obj.ContentLength != nil && *obj.ContentLength != counter.n {
+		return 0, "", fmt.Errorf("%w: read %d bytes of %d", ErrChecksumMismatch, counter.n, *obj.ContentLength)
+	}
+	if err = verifyETag(etag, md5Hash, sha256Hash); err != nil {
+		return 0, "", fmt.Errorf("source: %w", err)
+	}
+	if out.ETag != nil {
+		if err = verifyETag(normalizeETag(*out.ETag), md5Hash, sha256Hash); err != nil {
+			return 0, "", fmt.Errorf("target: %w", err)
+		}
+	}
+
+	return counter.n, etag, nil
+}
+
+// verifyETag compares the ETag with the digest of the same length. ETags of
+// other formats (e.g. of multipart uploads) aren't verified.
+func verifyETag(etag string, hashes ...hash.Hash) error {
+	for _, h := range hashes {
+		if len(etag) != hex.EncodedLen(h.Size()) {
+			continue
+		}
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != etag {
+			return fmt.Errorf("%w: etag %s, payload %s", ErrChecksumMismatch, etag, sum)
+		}
+	}
+
+	return nil
+}
+
+func normalizeETag(etag string) string {
+	return strings.ToLower(strings.Trim(etag, "\""))
+}
+
+func loadState(path string) (map[string]string, error) {
+	state := make(map[string]string)
+	if path == "" {
+		return state, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("open state file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec stateRecord
+		// the last line can be incomplete if the process was killed
+		if err = json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		state[rec.Key] = rec.ETag
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	return state, nil
+}
+
+func appendState(f *os.File, rec stateRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
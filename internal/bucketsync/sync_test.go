@@ -0,0 +1,191 @@
+package bucketsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/stretchr/testify/require"
+)
+
+type memObject struct {
+	payload []byte
+	etag    string
+	meta    map[string]*string
+}
+
+// memBucket implements listing, reading, writing and removal of objects.
+type memBucket struct {
+	s3iface.S3API
+
+	mu      sync.Mutex
+	objects map[string]memObject
+	gets    int
+	// corrupt replaces payloads read from the bucket
+	corrupt []byte
+}
+
+func newMemBucket() *memBucket {
+	return &memBucket{objects: make(map[string]memObject)}
+}
+
+func (b *memBucket) put(key string, payload []byte) {
+	sum := md5.Sum(payload)
+	b.mu.Lock()
+	b.objects[key] = memObject{payload: payload, etag: `"` + hex.EncodeToString(sum[:]) + `"`}
+	b.mu.Unlock()
+}
+
+func (b *memBucket) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (b *memBucket) ListObjectsV2PagesWithContext(_ aws.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	page := &s3.ListObjectsV2Output{}
+	for _, key := range b.keys() {
+		if !bytes.HasPrefix([]byte(key), []byte(aws.StringValue(input.Prefix))) {
+			continue
+		}
+		b.mu.Lock()
+		obj := b.objects[key]
+		b.mu.Unlock()
+		page.Contents = append(page.Contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(int64(len(obj.payload))), ETag: aws.String(obj.etag)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (b *memBucket) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.gets++
+	obj := b.objects[aws.StringValue(input.Key)]
+	payload := obj.payload
+	if b.corrupt != nil {
+		payload = b.corrupt
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(payload)),
+		ContentLength: aws.Int64(int64(len(payload))),
+		ETag:          aws.String(obj.etag),
+		Metadata:      obj.meta,
+	}, nil
+}
+
+func (b *memBucket) DeleteObjectWithContext(_ aws.Context, input *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	b.mu.Lock()
+	delete(b.objects, aws.StringValue(input.Key))
+	b.mu.Unlock()
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (b *memBucket) Upload(input *s3manager.UploadInput, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return b.UploadWithContext(context.Background(), input, opts...)
+}
+
+func (b *memBucket) UploadWithContext(_ aws.Context, input *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	payload, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	b.put(aws.StringValue(input.Key), payload)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	obj := b.objects[aws.StringValue(input.Key)]
+	obj.meta = input.Metadata
+	b.objects[aws.StringValue(input.Key)] = obj
+	return &s3manager.UploadOutput{ETag: aws.String(obj.etag)}, nil
+}
+
+func testConfig(src, dst *memBucket) Config {
+	return Config{
+		Source:  Bucket{Client: src, Name: "src"},
+		Target:  Target{Bucket: Bucket{Client: dst, Name: "dst"}, Uploader: dst},
+		Workers: 4,
+	}
+}
+
+func TestRun(t *testing.T) {
+	src, dst := newMemBucket(), newMemBucket()
+	src.put("a", []byte("payload a"))
+	src.put("dir/b", []byte("payload b"))
+	src.objects["dir/b"] = memObject{payload: src.objects["dir/b"].payload, etag: src.objects["dir/b"].etag,
+		meta: map[string]*string{"Color": aws.String("red")}}
+	dst.put("a", []byte("payload a"))
+	dst.put("stale", []byte("stale"))
+
+	cfg := testConfig(src, dst)
+	stats, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, &Stats{Copied: 1, Skipped: 1, Bytes: 9}, stats)
+	require.Equal(t, []string{"a", "dir/b", "stale"}, dst.keys())
+	require.Equal(t, "red", aws.StringValue(dst.objects["dir/b"].meta["Color"]))
+
+	cfg.Delete = true
+	stats, err = Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, &Stats{Skipped: 2, Deleted: 1}, stats)
+	require.Equal(t, []string{"a", "dir/b"}, dst.keys())
+}
+
+func TestRunResume(t *testing.T) {
+	src, dst := newMemBucket(), newMemBucket()
+	src.put("a", []byte("payload a"))
+	src.put("b", []byte("payload b"))
+
+	cfg := testConfig(src, dst)
+	cfg.StateFile = filepath.Join(t.TempDir(), "state")
+
+	stats, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stats.Copied)
+
+	// target ETags differ, but copied objects are in the state file
+	for key, obj := range dst.objects {
+		obj.etag = "other"
+		dst.objects[key] = obj
+	}
+	src.gets = 0
+	stats, err = Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stats.Skipped)
+	require.Zero(t, src.gets)
+
+	src.put("b", []byte("changed b"))
+	stats, err = Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Copied)
+	require.Equal(t, []byte("changed b"), dst.objects["b"].payload)
+}
+
+func TestRunChecksumMismatch(t *testing.T) {
+	src, dst := newMemBucket(), newMemBucket()
+	src.put("a", []byte("payload a"))
+	src.corrupt = []byte("corrupted")
+
+	stats, err := Run(context.Background(), testConfig(src, dst))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Failed)
+	require.Empty(t, dst.keys())
+}
@@ -0,0 +1,472 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultWorkers is a default number of items processed by all background
+// jobs in parallel.
+const DefaultWorkers = 2
+
+type (
+	// Settings are limits of the background job.
+	Settings struct {
+		// Interval between job runs, job isn't run periodically if it's zero.
+		Interval time.Duration
+		// Priority of the job, its items take free workers before the ones
+		// of jobs with lower priority.
+		Priority int
+		// Rate limits items processed per second, zero means no limit.
+		Rate float64
+		// Concurrency limits items of the job processed in parallel.
+		Concurrency int
+	}
+
+	// Job is a background work, e.g. lifecycle, replication, scrubbing or
+	// garbage collection.
+	Job struct {
+		Name string
+		// Run lists items of the job and processes them with Task.Go.
+		Run func(context.Context, *Task) error
+		// Exclusive jobs are run periodically by the leader replica only if
+		// the scheduler has the leader set, e.g. jobs processing shared data
+		// in NeoFS. Jobs updating the state of the replica aren't exclusive.
+		Exclusive bool
+		Settings
+	}
+
+	// Leader tells whether the replica is the leader among gateway replicas.
+	Leader interface {
+		IsLeader() bool
+	}
+
+	// Status describes the state of the registered job.
+	Status struct {
+		Name        string    `json:"name"`
+		Interval    string    `json:"interval"`
+		Priority    int       `json:"priority"`
+		Rate        float64   `json:"rate"`
+		Concurrency int       `json:"concurrency"`
+		Exclusive   bool      `json:"exclusive"`
+		Paused      bool      `json:"paused"`
+		Running     bool      `json:"running"`
+		LastRun     time.Time `json:"last_run"`
+		LastError   string    `json:"last_error,omitempty"`
+		Processed   uint64    `json:"processed"`
+		Failed      uint64    `json:"failed"`
+	}
+
+	// Scheduler runs background jobs sharing the limited number of workers,
+	// so background work doesn't starve S3 requests.
+	Scheduler struct {
+		log     *zap.Logger
+		workers *workers
+		leader  Leader
+
+		mu   sync.RWMutex
+		jobs map[string]*job
+	}
+
+	// Task is a single run of the job.
+	Task struct {
+		job     *job
+		workers *workers
+		wg      sync.WaitGroup
+	}
+
+	job struct {
+		// counters are accessed atomically, so they go first to be aligned
+		processed uint64
+		failed    uint64
+
+		Job
+		limiter   *limiter
+		semaphore chan struct{}
+
+		mu        sync.Mutex
+		paused    bool
+		resumed   chan struct{}
+		running   bool
+		lastRun   time.Time
+		lastError string
+	}
+
+	// workers are shared by jobs, waiters with higher priority are served
+	// first.
+	workers struct {
+		mu      sync.Mutex
+		free    int
+		waiters []*waiter
+	}
+
+	waiter struct {
+		priority int
+		ready    chan struct{}
+	}
+
+	limiter struct {
+		mu       sync.Mutex
+		interval time.Duration
+		next     time.Time
+	}
+)
+
+// ErrJobNotFound is returned if there is no job with the requested name.
+var ErrJobNotFound = errors.New("job not found")
+
+// NewScheduler creates a scheduler processing the given number of job items
+// in parallel.
+func NewScheduler(log *zap.Logger, workersCount int) *Scheduler {
+	if workersCount <= 0 {
+		workersCount = DefaultWorkers
+	}
+
+	return &Scheduler{
+		log:     log,
+		workers: &workers{free: workersCount},
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Register adds the job to the scheduler, it must be done before Start.
+func (s *Scheduler) Register(j Job) error {
+	if j.Name == "" || j.Run == nil {
+		return errors.New("job must have a name and a run function")
+	}
+	if j.Concurrency <= 0 {
+		j.Concurrency = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[j.Name]; ok {
+		return fmt.Errorf("job '%s' is already registered", j.Name)
+	}
+
+	s.jobs[j.Name] = &job{
+		Job:       j,
+		limiter:   newLimiter(j.Rate),
+		semaphore: make(chan struct{}, j.Concurrency),
+	}
+
+	return nil
+}
+
+// SetLeader makes exclusive jobs run only while the replica is the leader, it
+// must be done before Start.
+func (s *Scheduler) SetLeader(leader Leader) {
+	s.leader = leader
+}
+
+// Start runs registered jobs periodically until the context is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, j := range s.jobs {
+		if j.Interval <= 0 {
+			continue
+		}
+		go s.loop(ctx, j)
+	}
+}
+
+// Pause stops processing of new items of the job until it's resumed.
+func (s *Scheduler) Pause(name string) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	if !j.paused {
+		j.paused = true
+		j.resumed = make(chan struct{})
+	}
+	j.mu.Unlock()
+
+	s.log.Info("job paused", zap.String("job", name))
+	return nil
+}
+
+// Resume continues processing of the paused job.
+func (s *Scheduler) Resume(name string) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	if j.paused {
+		j.paused = false
+		close(j.resumed)
+	}
+	j.mu.Unlock()
+
+	s.log.Info("job resumed", zap.String("job", name))
+	return nil
+}
+
+// Status returns states of registered jobs sorted by name.
+func (s *Scheduler) Status() []Status {
+	s.mu.RLock()
+	res := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, j.status())
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// Run runs the job once, it returns the job error. Items of the paused job
+// wait until it's resumed.
+func (s *Scheduler) Run(ctx context.Context, name string) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+
+	return s.run(ctx, j)
+}
+
+func (s *Scheduler) job(name string) (*job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	return j, nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	tm := time.NewTicker(j.Interval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tm.C:
+		}
+
+		j.mu.Lock()
+		skip := j.paused || j.running
+		j.mu.Unlock()
+		if skip || j.Exclusive && s.leader != nil && !s.leader.IsLeader() {
+			continue
+		}
+
+		if err := s.run(ctx, j); err != nil && ctx.Err() == nil {
+			s.log.Error("job failed", zap.String("job", j.Name), zap.Error(err))
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job) error {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return fmt.Errorf("job '%s' is already running", j.Name)
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	start := time.Now()
+	task := &Task{job: j, workers: s.workers}
+	err := j.Run(ctx, task)
+	task.wg.Wait()
+
+	j.mu.Lock()
+	j.running = false
+	j.lastRun = start
+	j.lastError = ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	s.log.Debug("job finished", zap.String("job", j.Name), zap.Duration("duration", time.Since(start)), zap.Error(err))
+	return err
+}
+
+// Go processes the item of the job in background. It blocks until the item
+// can be started: the job is resumed, the rate and concurrency limits allow it
+// and a shared worker is free. Failed items are counted in the job status.
+func (t *Task) Go(ctx context.Context, f func(context.Context) error) error {
+	j := t.job
+
+	j.mu.Lock()
+	paused, resumed := j.paused, j.resumed
+	j.mu.Unlock()
+	if paused {
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := j.limiter.wait(ctx); err != nil {
+		return err
+	}
+
+	select {
+	case j.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := t.workers.acquire(ctx, j.Priority); err != nil {
+		<-j.semaphore
+		return err
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer func() {
+			t.workers.release()
+			<-j.semaphore
+			t.wg.Done()
+		}()
+
+		if err := f(ctx); err != nil {
+			atomic.AddUint64(&j.failed, 1)
+			return
+		}
+		atomic.AddUint64(&j.processed, 1)
+	}()
+
+	return nil
+}
+
+// Wait blocks until all items of the task are processed, so the job can sum
+// up results of the run before it returns.
+func (t *Task) Wait() {
+	t.wg.Wait()
+}
+
+func (j *job) status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return Status{
+		Name:        j.Name,
+		Interval:    j.Interval.String(),
+		Priority:    j.Priority,
+		Rate:        j.Rate,
+		Concurrency: j.Concurrency,
+		Exclusive:   j.Exclusive,
+		Paused:      j.paused,
+		Running:     j.running,
+		LastRun:     j.lastRun,
+		LastError:   j.lastError,
+		Processed:   atomic.LoadUint64(&j.processed),
+		Failed:      atomic.LoadUint64(&j.failed),
+	}
+}
+
+func (w *workers) acquire(ctx context.Context, priority int) error {
+	w.mu.Lock()
+	if w.free > 0 && len(w.waiters) == 0 {
+		w.free--
+		w.mu.Unlock()
+		return nil
+	}
+
+	wt := &waiter{priority: priority, ready: make(chan struct{})}
+	// waiters of the same priority are served in order of arrival
+	i := sort.Search(len(w.waiters), func(i int) bool {
+		return w.waiters[i].priority < priority
+	})
+	w.waiters = append(w.waiters, nil)
+	copy(w.waiters[i+1:], w.waiters[i:])
+	w.waiters[i] = wt
+	w.mu.Unlock()
+
+	select {
+	case <-wt.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	w.mu.Lock()
+	for i := range w.waiters {
+		if w.waiters[i] == wt {
+			w.waiters = append(w.waiters[:i], w.waiters[i+1:]...)
+			w.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	w.mu.Unlock()
+
+	// the worker has been passed to the waiter concurrently
+	w.release()
+	return ctx.Err()
+}
+
+func (w *workers) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.waiters) == 0 {
+		w.free++
+		return
+	}
+
+	close(w.waiters[0].ready)
+	w.waiters = w.waiters[1:]
+}
+
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &limiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	tm := time.NewTimer(delay)
+	defer tm.Stop()
+
+	select {
+	case <-tm.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 4)
+
+	var running, maxRunning, finished, waited int32
+	require.NoError(t, s.Register(Job{
+		Name:     "test",
+		Settings: Settings{Concurrency: 2},
+		Run: func(ctx context.Context, task *Task) error {
+			for i := 0; i < 10; i++ {
+				i := i
+				if err := task.Go(ctx, func(context.Context) error {
+					cur := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&finished, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						prev := atomic.LoadInt32(&maxRunning)
+						if cur <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, cur) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					if i%5 == 0 {
+						return errors.New("item failed")
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+			task.Wait()
+			waited = atomic.LoadInt32(&finished)
+			return nil
+		},
+	}))
+	require.Error(t, s.Register(Job{Name: "test", Run: func(context.Context, *Task) error { return nil }}))
+
+	require.NoError(t, s.Run(context.Background(), "test"))
+	require.LessOrEqual(t, maxRunning, int32(2))
+	require.EqualValues(t, 10, waited)
+
+	status := s.Status()
+	require.Len(t, status, 1)
+	require.Equal(t, uint64(8), status[0].Processed)
+	require.Equal(t, uint64(2), status[0].Failed)
+	require.False(t, status[0].Running)
+	require.False(t, status[0].LastRun.IsZero())
+
+	require.ErrorIs(t, s.Run(context.Background(), "unknown"), ErrJobNotFound)
+}
+
+func TestSchedulerRate(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 4)
+	require.NoError(t, s.Register(Job{
+		Name:     "test",
+		Settings: Settings{Rate: 100, Concurrency: 4},
+		Run: func(ctx context.Context, task *Task) error {
+			for i := 0; i < 5; i++ {
+				if err := task.Go(ctx, func(context.Context) error { return nil }); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}))
+
+	start := time.Now()
+	require.NoError(t, s.Run(context.Background(), "test"))
+	// the first item is started immediately
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestSchedulerPause(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 1)
+
+	var processed int32
+	require.NoError(t, s.Register(Job{
+		Name: "test",
+		Run: func(ctx context.Context, task *Task) error {
+			return task.Go(ctx, func(context.Context) error {
+				atomic.AddInt32(&processed, 1)
+				return nil
+			})
+		},
+	}))
+	require.NoError(t, s.Pause("test"))
+	require.True(t, s.Status()[0].Paused)
+
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background(), "test") }()
+
+	time.Sleep(20 * time.Millisecond)
+	require.Zero(t, atomic.LoadInt32(&processed))
+	require.True(t, s.Status()[0].Running)
+
+	require.NoError(t, s.Resume("test"))
+	require.NoError(t, <-done)
+	require.Equal(t, int32(1), atomic.LoadInt32(&processed))
+
+	require.ErrorIs(t, s.Pause("unknown"), ErrJobNotFound)
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 1)
+
+	// occupy the only worker, so items of both jobs wait for it
+	require.NoError(t, s.workers.acquire(context.Background(), 0))
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	for i, j := range []Job{
+		{Name: "low", Settings: Settings{Priority: 1}},
+		{Name: "high", Settings: Settings{Priority: 10}},
+	} {
+		name := j.Name
+		j.Run = func(ctx context.Context, task *Task) error {
+			return task.Go(ctx, func(context.Context) error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return nil
+			})
+		}
+		require.NoError(t, s.Register(j))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, s.Run(context.Background(), name))
+		}()
+		// let the item wait for the worker
+		require.Eventually(t, func() bool {
+			s.workers.mu.Lock()
+			defer s.workers.mu.Unlock()
+			return len(s.workers.waiters) == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	s.workers.release()
+	wg.Wait()
+
+	require.Equal(t, []string{"high", "low"}, order)
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 1)
+	require.NoError(t, s.workers.acquire(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, s.workers.acquire(ctx, 0), context.DeadlineExceeded)
+	require.Empty(t, s.workers.waiters)
+
+	s.workers.release()
+	require.Equal(t, 1, s.workers.free)
+}
+
+type testLeader struct {
+	leader atomic.Bool
+}
+
+func (l *testLeader) IsLeader() bool {
+	return l.leader.Load()
+}
+
+func TestSchedulerExclusive(t *testing.T) {
+	s := NewScheduler(zap.NewNop(), 1)
+	leader := &testLeader{}
+	s.SetLeader(leader)
+
+	var exclusiveRuns, sharedRuns int32
+	require.NoError(t, s.Register(Job{
+		Name:      "exclusive",
+		Exclusive: true,
+		Settings:  Settings{Interval: time.Millisecond},
+		Run: func(context.Context, *Task) error {
+			atomic.AddInt32(&exclusiveRuns, 1)
+			return nil
+		},
+	}))
+	require.NoError(t, s.Register(Job{
+		Name:     "shared",
+		Settings: Settings{Interval: time.Millisecond},
+		Run: func(context.Context, *Task) error {
+			atomic.AddInt32(&sharedRuns, 1)
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&sharedRuns) > 2 }, time.Second, time.Millisecond)
+	require.Zero(t, atomic.LoadInt32(&exclusiveRuns))
+	require.True(t, s.Status()[0].Exclusive)
+
+	leader.leader.Store(true)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&exclusiveRuns) > 0 }, time.Second, time.Millisecond)
+
+	// the job is still run on demand by followers
+	leader.leader.Store(false)
+	runs := atomic.LoadInt32(&exclusiveRuns)
+	require.Eventually(t, func() bool { return s.Run(ctx, "exclusive") == nil }, time.Second, time.Millisecond)
+	require.Greater(t, atomic.LoadInt32(&exclusiveRuns), runs)
+}
@@ -0,0 +1,291 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+const (
+	attributeElection  = ".s3-leader-election"
+	attributeCandidate = ".s3-leader-candidate"
+	attributeExpires   = ".s3-leader-expires"
+
+	// resignTimeout limits removal of leases on shutdown.
+	resignTimeout = 5 * time.Second
+)
+
+type (
+	// Config defines the election.
+	Config struct {
+		// Container keeps leases of candidates. The gateway must be allowed
+		// to put, search, head and delete objects of the container.
+		Container cid.ID
+		// Owner creates lease objects.
+		Owner user.ID
+		// Name of the election, candidates of the same election compete.
+		Name string
+		// CandidateID identifies the gateway, it must be unique even for
+		// replicas sharing the key.
+		CandidateID string
+		// TTL is a time the lease of the leader is valid, it's renewed every
+		// third of TTL.
+		TTL time.Duration
+	}
+
+	// Elector elects the leader among candidates sharing the container.
+	//
+	// The lease is a system object of the container with the candidate ID
+	// and the expiration time in attributes. The candidate puts its lease if
+	// there is no unexpired lease of other candidates and then searches leases
+	// again, on conflict the candidate with the least ID wins and the others
+	// remove their leases. The leader renews the lease by putting a new one,
+	// it considers itself the leader until its last lease expires, so there is
+	// at most one leader if clocks of candidates are in sync, except for short
+	// conflicts resolved with the next heartbeat.
+	Elector struct {
+		neoFS layer.NeoFS
+		cfg   Config
+		log   *zap.Logger
+
+		mu sync.RWMutex
+		// leaderUntil is the expiration of the last lease put by the leader,
+		// it's zero for followers.
+		leaderUntil time.Time
+		// leases are objects of the put leases not removed yet.
+		leases []oid.ID
+	}
+
+	lease struct {
+		candidate string
+		expires   time.Time
+	}
+)
+
+// NewElector creates a new elector of the gateway, it's a follower until Run
+// elects it.
+func NewElector(neoFS layer.NeoFS, cfg Config, log *zap.Logger) *Elector {
+	return &Elector{
+		neoFS: neoFS,
+		cfg:   cfg,
+		log:   log.With(zap.String("election", cfg.Name), zap.String("candidate", cfg.CandidateID)),
+	}
+}
+
+// IsLeader checks whether the gateway is the leader now.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return time.Now().Before(e.leaderUntil)
+}
+
+// Run takes part in the election until the context is done, the leases are
+// removed then, so another candidate is elected without waiting for them to
+// expire.
+func (e *Elector) Run(ctx context.Context) {
+	tm := time.NewTicker(e.cfg.TTL / 3)
+	defer tm.Stop()
+
+	for {
+		if err := e.heartbeat(ctx); err != nil && ctx.Err() == nil {
+			e.log.Error("leader election heartbeat failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			e.resign()
+			return
+		case <-tm.C:
+		}
+	}
+}
+
+func (e *Elector) heartbeat(ctx context.Context) error {
+	// the gateway clock is used, leases are compared with it only
+	start := time.Now()
+
+	leases, err := e.activeLeases(ctx, start)
+	if err != nil {
+		return err
+	}
+
+	// followers don't challenge the leader, conflicting leaders are resolved
+	// by candidate IDs
+	wasLeader := e.IsLeader()
+	if hasOthers(leases, e.cfg.CandidateID) && (!wasLeader || !e.wins(leases)) {
+		e.stepDown(ctx, wasLeader)
+		return nil
+	}
+
+	id, err := e.putLease(ctx, start)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.leases = append(e.leases, id)
+	e.mu.Unlock()
+
+	if !wasLeader {
+		// other candidates could put their leases concurrently
+		if leases, err = e.activeLeases(ctx, start); err != nil {
+			return err
+		}
+		if !e.wins(leases) {
+			e.stepDown(ctx, false)
+			return nil
+		}
+		e.log.Info("elected as the leader")
+	}
+
+	e.mu.Lock()
+	e.leaderUntil = start.Add(e.cfg.TTL)
+	old := e.leases[:len(e.leases)-1]
+	e.leases = e.leases[len(e.leases)-1:]
+	e.mu.Unlock()
+
+	// the new lease is valid, previous ones are not needed
+	e.removeLeases(ctx, old)
+
+	return nil
+}
+
+// wins checks whether the candidate has the least ID among the candidates
+// with active leases.
+func (e *Elector) wins(leases []lease) bool {
+	for _, l := range leases {
+		if l.candidate < e.cfg.CandidateID {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Elector) stepDown(ctx context.Context, wasLeader bool) {
+	e.mu.Lock()
+	e.leaderUntil = time.Time{}
+	leases := e.leases
+	e.leases = nil
+	e.mu.Unlock()
+
+	if wasLeader {
+		e.log.Warn("leadership is lost")
+	}
+
+	e.removeLeases(ctx, leases)
+}
+
+func (e *Elector) resign() {
+	ctx, cancel := context.WithTimeout(context.Background(), resignTimeout)
+	defer cancel()
+
+	e.stepDown(ctx, false)
+}
+
+func (e *Elector) removeLeases(ctx context.Context, ids []oid.ID) {
+	for _, id := range ids {
+		err := e.neoFS.DeleteObject(ctx, layer.PrmObjectDelete{
+			Container: e.cfg.Container,
+			Object:    id,
+		})
+		// leases expire anyway, so the failure is not critical
+		if err != nil && !errors.Is(err, apistatus.ErrObjectNotFound) {
+			e.log.Warn("couldn't remove lease", zap.Stringer("oid", id), zap.Error(err))
+		}
+	}
+}
+
+func (e *Elector) putLease(ctx context.Context, now time.Time) (oid.ID, error) {
+	expires := now.Add(e.cfg.TTL)
+
+	_, expEpoch, err := e.neoFS.TimeToEpoch(ctx, now, expires)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("compute lease expiration epoch: %w", err)
+	}
+
+	id, err := e.neoFS.CreateObject(ctx, layer.PrmObjectCreate{
+		Container:    e.cfg.Container,
+		Creator:      e.cfg.Owner,
+		CreationTime: now,
+		Attributes: [][2]string{
+			{attributeElection, e.cfg.Name},
+			{attributeCandidate, e.cfg.CandidateID},
+			{attributeExpires, strconv.FormatInt(expires.UnixMilli(), 10)},
+			{object.AttributeExpirationEpoch, strconv.FormatUint(expEpoch, 10)},
+		},
+		Payload: strings.NewReader(""),
+	})
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("put lease: %w", err)
+	}
+
+	return id, nil
+}
+
+// activeLeases returns unexpired leases of the election.
+func (e *Elector) activeLeases(ctx context.Context, now time.Time) ([]lease, error) {
+	ids, err := e.neoFS.SearchObjects(ctx, layer.PrmObjectSearch{
+		Container: e.cfg.Container,
+		Attribute: attributeElection,
+		Value:     e.cfg.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search leases: %w", err)
+	}
+
+	res := make([]lease, 0, len(ids))
+	for _, id := range ids {
+		obj, err := e.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+			Container:  e.cfg.Container,
+			Object:     id,
+			WithHeader: true,
+		})
+		if err != nil {
+			// the lease is removed since it has been found
+			if errors.Is(err, apistatus.ErrObjectNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("head lease: %w", err)
+		}
+
+		var l lease
+		for _, attr := range obj.Head.Attributes() {
+			switch attr.Key() {
+			case attributeCandidate:
+				l.candidate = attr.Value()
+			case attributeExpires:
+				if ms, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+					l.expires = time.UnixMilli(ms)
+				}
+			}
+		}
+
+		if l.candidate != "" && now.Before(l.expires) {
+			res = append(res, l)
+		}
+	}
+
+	return res, nil
+}
+
+func hasOthers(leases []lease, candidate string) bool {
+	for _, l := range leases {
+		if l.candidate != candidate {
+			return true
+		}
+	}
+	return false
+}
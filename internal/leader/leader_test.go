@@ -0,0 +1,84 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestElector(neoFS layer.NeoFS, cnrID cid.ID, candidate string, ttl time.Duration) *Elector {
+	return NewElector(neoFS, Config{
+		Container:   cnrID,
+		Owner:       user.ID{},
+		Name:        "jobs",
+		CandidateID: candidate,
+		TTL:         ttl,
+	}, zap.NewNop())
+}
+
+func TestElector(t *testing.T) {
+	ctx := context.Background()
+	neoFS := layer.NewTestNeoFS()
+	cnrID := cidtest.ID()
+
+	first := newTestElector(neoFS, cnrID, "b", time.Hour)
+	second := newTestElector(neoFS, cnrID, "a", time.Hour)
+
+	require.NoError(t, first.heartbeat(ctx))
+	require.True(t, first.IsLeader())
+
+	// the follower doesn't challenge the leader even with the least ID
+	require.NoError(t, second.heartbeat(ctx))
+	require.False(t, second.IsLeader())
+
+	// the renewed lease replaces the previous one
+	require.NoError(t, first.heartbeat(ctx))
+	require.True(t, first.IsLeader())
+	require.Len(t, neoFS.AllObjects(cnrID), 1)
+
+	// another candidate is elected at once after the leader resigns
+	first.resign()
+	require.False(t, first.IsLeader())
+	require.Empty(t, neoFS.AllObjects(cnrID))
+
+	require.NoError(t, second.heartbeat(ctx))
+	require.True(t, second.IsLeader())
+	require.NoError(t, first.heartbeat(ctx))
+	require.False(t, first.IsLeader())
+
+	// conflicting leaders are resolved in favor of the least ID
+	_, err := first.putLease(ctx, time.Now())
+	require.NoError(t, err)
+	first.mu.Lock()
+	first.leaderUntil = time.Now().Add(time.Hour)
+	first.mu.Unlock()
+
+	require.NoError(t, second.heartbeat(ctx))
+	require.True(t, second.IsLeader())
+	require.NoError(t, first.heartbeat(ctx))
+	require.False(t, first.IsLeader())
+}
+
+func TestElectorExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	neoFS := layer.NewTestNeoFS()
+	cnrID := cidtest.ID()
+
+	failed := newTestElector(neoFS, cnrID, "a", 10*time.Millisecond)
+	require.NoError(t, failed.heartbeat(ctx))
+	require.True(t, failed.IsLeader())
+
+	// the leader stopped renewing the lease
+	require.Eventually(t, func() bool { return !failed.IsLeader() }, time.Second, time.Millisecond)
+
+	other := newTestElector(neoFS, cnrID, "b", time.Hour)
+	require.NoError(t, other.heartbeat(ctx))
+	require.True(t, other.IsLeader())
+}
@@ -10,11 +10,14 @@ import (
 	"math"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
@@ -37,18 +40,36 @@ type Config struct {
 	MaxObjectSize        int64
 	IsSlicerEnabled      bool
 	IsHomomorphicEnabled bool
+	// HedgeToReplicas makes hedged reads go directly to container nodes.
+	HedgeToReplicas   bool
+	NodeDialTimeout   time.Duration
+	NodeStreamTimeout time.Duration
+	// PreviousKeys of the gateway sign requests with access boxes issued for
+	// them, bearer and session tokens of the boxes are issued to these keys.
+	PreviousKeys []*keys.PrivateKey
 }
 
 // NeoFS represents virtual connection to the NeoFS network.
 // It is used to provide an interface to dependent packages
 // which work with NeoFS.
 type NeoFS struct {
-	pool        *pool.Pool
+	pool atomic.Pointer[pool.Pool]
+	// writePool is used to store and delete objects and containers, it's
+	// the same as pool unless a separate one is set with SwapWritePool.
+	writePool atomic.Pointer[pool.Pool]
+	// poolsMu guards swaps of pools and their borrowing, borrowed are
+	// numbers of operations using pools and drained are notified once
+	// replaced pools aren't borrowed anymore.
+	poolsMu     sync.Mutex
+	borrowed    map[*pool.Pool]int
+	drained     map[*pool.Pool]chan struct{}
 	gateSigner  user.Signer
 	anonSigner  user.Signer
+	prevKeys    previousKeys
 	cfg         Config
 	epochGetter EpochGetter
 	buffers     *sync.Pool
+	replicas    *replicas
 }
 
 // NewNeoFS creates new NeoFS using provided pool.Pool.
@@ -59,14 +80,113 @@ func NewNeoFS(p *pool.Pool, signer user.Signer, anonSigner user.Signer, cfg Conf
 		return &b
 	}
 
-	return &NeoFS{
-		pool:        p,
+	neoFS := &NeoFS{
 		gateSigner:  signer,
 		anonSigner:  anonSigner,
+		prevKeys:    newPreviousKeys(cfg.PreviousKeys),
 		cfg:         cfg,
 		epochGetter: epochGetter,
 		buffers:     &buffers,
+		replicas:    newReplicas(cfg),
+		borrowed:    make(map[*pool.Pool]int),
+		drained:     make(map[*pool.Pool]chan struct{}),
 	}
+	neoFS.pool.Store(p)
+	neoFS.writePool.Store(p)
+
+	return neoFS
+}
+
+// SwapPool replaces the connection pool used for new operations. Operations
+// started before the call keep using the previous pool, so it must not be
+// closed until the returned channel is closed, it happens once they are
+// finished. Writes are switched to the new pool too unless a separate one is
+// set with SwapWritePool.
+func (x *NeoFS) SwapPool(p *pool.Pool) <-chan struct{} {
+	x.poolsMu.Lock()
+	defer x.poolsMu.Unlock()
+
+	old := x.pool.Swap(p)
+	x.writePool.CompareAndSwap(old, p)
+
+	return x.drainedLocked(old)
+}
+
+// SwapWritePool replaces the connection pool used for new write operations,
+// see SwapPool. Writes use the same pool as reads until it's called, so heavy
+// uploads can't take connections of reads once a separate pool is set.
+func (x *NeoFS) SwapWritePool(p *pool.Pool) <-chan struct{} {
+	x.poolsMu.Lock()
+	defer x.poolsMu.Unlock()
+
+	return x.drainedLocked(x.writePool.Swap(p))
+}
+
+// drainedLocked returns the channel closed once the replaced pool isn't
+// borrowed, it must be called with x.poolsMu held.
+func (x *NeoFS) drainedLocked(old *pool.Pool) <-chan struct{} {
+	ch, ok := x.drained[old]
+	if !ok {
+		ch = make(chan struct{})
+		x.drained[old] = ch
+	}
+	x.notifyDrainedLocked(old)
+
+	return ch
+}
+
+// notifyDrainedLocked closes the drained channel of the pool if it's replaced
+// and isn't borrowed, it must be called with x.poolsMu held.
+func (x *NeoFS) notifyDrainedLocked(p *pool.Pool) {
+	if x.borrowed[p] > 0 || p == x.pool.Load() || p == x.writePool.Load() {
+		return
+	}
+	if ch, ok := x.drained[p]; ok {
+		close(ch)
+		delete(x.drained, p)
+	}
+}
+
+// borrowPool returns the current pool, the write one if write is set, and
+// the function releasing it once the operation is finished.
+func (x *NeoFS) borrowPool(write bool) (*pool.Pool, func()) {
+	x.poolsMu.Lock()
+	p := x.pool.Load()
+	if write {
+		p = x.writePool.Load()
+	}
+	x.borrowed[p]++
+	x.poolsMu.Unlock()
+
+	var once sync.Once
+	return p, func() {
+		once.Do(func() {
+			x.poolsMu.Lock()
+			defer x.poolsMu.Unlock()
+
+			if x.borrowed[p]--; x.borrowed[p] == 0 {
+				delete(x.borrowed, p)
+			}
+			x.notifyDrainedLocked(p)
+		})
+	}
+}
+
+// releasingReader releases the borrowed pool once the payload is closed.
+type releasingReader struct {
+	io.ReadCloser
+	release func()
+}
+
+func (x releasingReader) Close() error {
+	defer x.release()
+	return x.ReadCloser.Close()
+}
+
+// MaxObjectSize returns the maximum payload size of NeoFS objects, bigger ones
+// are split.
+func (x *NeoFS) MaxObjectSize() int64 {
+	return x.cfg.MaxObjectSize
 }
 
 func (x *NeoFS) signer(ctx context.Context) user.Signer {
@@ -74,9 +194,39 @@ func (x *NeoFS) signer(ctx context.Context) user.Signer {
 		return x.anonSigner
 	}
 
+	if key := x.prevKeys.of(ctx); key != nil {
+		return user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	}
+
 	return x.gateSigner
 }
 
+// previousKeys are previous keys of the gateway by public keys.
+type previousKeys map[string]*keys.PrivateKey
+
+func newPreviousKeys(list []*keys.PrivateKey) previousKeys {
+	res := make(previousKeys, len(list))
+	for _, key := range list {
+		res[string(key.PublicKey().Bytes())] = key
+	}
+	return res
+}
+
+// of returns the previous key the access box of the request is issued for, it's
+// nil if the box is issued for the current key.
+func (p previousKeys) of(ctx context.Context) *keys.PrivateKey {
+	if len(p) == 0 {
+		return nil
+	}
+
+	box, ok := ctx.Value(api.BoxData).(*accessbox.Box)
+	if !ok || box == nil || box.Gate == nil || box.Gate.GateKey == nil {
+		return nil
+	}
+
+	return p[string(box.Gate.GateKey.Bytes())]
+}
+
 // TimeToEpoch implements neofs.NeoFS interface method.
 func (x *NeoFS) TimeToEpoch(ctx context.Context, now, futureTime time.Time) (uint64, uint64, error) {
 	dur := futureTime.Sub(now)
@@ -85,7 +235,9 @@ func (x *NeoFS) TimeToEpoch(ctx context.Context, now, futureTime time.Time) (uin
 			futureTime.Format(time.RFC3339), now.Format(time.RFC3339))
 	}
 
-	networkInfo, err := x.pool.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	conns, release := x.borrowPool(false)
+	defer release()
+	networkInfo, err := conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
 	if err != nil {
 		return 0, 0, fmt.Errorf("get network info via client: %w", err)
 	}
@@ -116,7 +268,9 @@ func (x *NeoFS) TimeToEpoch(ctx context.Context, now, futureTime time.Time) (uin
 // Container implements neofs.NeoFS interface method.
 func (x *NeoFS) Container(ctx context.Context, idCnr cid.ID) (*container.Container, error) {
 	var prm client.PrmContainerGet
-	res, err := x.pool.ContainerGet(ctx, idCnr, prm)
+	conns, release := x.borrowPool(false)
+	defer release()
+	res, err := conns.ContainerGet(ctx, idCnr, prm)
 	if err != nil {
 		return nil, fmt.Errorf("read container via connection pool: %w", err)
 	}
@@ -160,7 +314,9 @@ func (x *NeoFS) CreateContainer(ctx context.Context, prm layer.PrmContainerCreat
 
 	cnr.SetAttribute(layer.AttributeOwnerPublicKey, hex.EncodeToString(prm.CreatorPubKey.Bytes()))
 
-	err := client.SyncContainerWithNetwork(ctx, &cnr, x.pool)
+	conns, release := x.borrowPool(false)
+	defer release()
+	err := client.SyncContainerWithNetwork(ctx, &cnr, conns)
 	if err != nil {
 		return cid.ID{}, fmt.Errorf("sync container with the network state: %w", err)
 	}
@@ -170,7 +326,9 @@ func (x *NeoFS) CreateContainer(ctx context.Context, prm layer.PrmContainerCreat
 		prmPut.WithinSession(*prm.SessionToken)
 	}
 
-	putWaiter := waiter.NewContainerPutWaiter(x.pool, waiter.DefaultPollInterval)
+	writeConns, releaseWrite := x.borrowPool(true)
+	defer releaseWrite()
+	putWaiter := waiter.NewContainerPutWaiter(writeConns, waiter.DefaultPollInterval)
 
 	// send request to save the container
 	idCnr, err := putWaiter.ContainerPut(ctx, cnr, x.signer(ctx), prmPut)
@@ -184,7 +342,9 @@ func (x *NeoFS) CreateContainer(ctx context.Context, prm layer.PrmContainerCreat
 // UserContainers implements neofs.NeoFS interface method.
 func (x *NeoFS) UserContainers(ctx context.Context, id user.ID) ([]cid.ID, error) {
 	var prm client.PrmContainerList
-	r, err := x.pool.ContainerList(ctx, id, prm)
+	conns, release := x.borrowPool(false)
+	defer release()
+	r, err := conns.ContainerList(ctx, id, prm)
 	if err != nil {
 		return nil, fmt.Errorf("list user containers via connection pool: %w", err)
 	}
@@ -199,7 +359,9 @@ func (x *NeoFS) SetContainerEACL(ctx context.Context, table eacl.Table, sessionT
 		prm.WithinSession(*sessionToken)
 	}
 
-	eaclWaiter := waiter.NewContainerSetEACLWaiter(x.pool, waiter.DefaultPollInterval)
+	conns, release := x.borrowPool(true)
+	defer release()
+	eaclWaiter := waiter.NewContainerSetEACLWaiter(conns, waiter.DefaultPollInterval)
 	err := eaclWaiter.ContainerSetEACL(ctx, table, x.signer(ctx), prm)
 	if err != nil {
 		return fmt.Errorf("save eACL via connection pool: %w", err)
@@ -211,7 +373,9 @@ func (x *NeoFS) SetContainerEACL(ctx context.Context, table eacl.Table, sessionT
 // ContainerEACL implements neofs.NeoFS interface method.
 func (x *NeoFS) ContainerEACL(ctx context.Context, id cid.ID) (*eacl.Table, error) {
 	var prm client.PrmContainerEACL
-	res, err := x.pool.ContainerEACL(ctx, id, prm)
+	conns, release := x.borrowPool(false)
+	defer release()
+	res, err := conns.ContainerEACL(ctx, id, prm)
 	if err != nil {
 		return nil, fmt.Errorf("read eACL via connection pool: %w", err)
 	}
@@ -226,7 +390,9 @@ func (x *NeoFS) DeleteContainer(ctx context.Context, id cid.ID, token *session.C
 		prm.WithinSession(*token)
 	}
 
-	deleteWaiter := waiter.NewContainerDeleteWaiter(x.pool, waiter.DefaultPollInterval)
+	conns, release := x.borrowPool(true)
+	defer release()
+	deleteWaiter := waiter.NewContainerDeleteWaiter(conns, waiter.DefaultPollInterval)
 	err := deleteWaiter.ContainerDelete(ctx, id, x.signer(ctx), prm)
 	if err != nil {
 		return fmt.Errorf("delete container via connection pool: %w", err)
@@ -308,7 +474,10 @@ func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oi
 			opts.SetBearerToken(*prm.BearerToken)
 		}
 
-		objID, err := slicer.Put(ctx, x.pool, obj, x.signer(ctx), prm.Payload, opts)
+		conns, release := x.borrowPool(true)
+		defer release()
+		ow := sessionRetryInitializer{objectPutInitializer: conns, keepFirst: true}
+		objID, err := slicer.Put(ctx, ow, obj, x.signer(ctx), prm.Payload, opts)
 		x.buffers.Put(chunk)
 
 		if err != nil {
@@ -325,7 +494,10 @@ func (x *NeoFS) CreateObject(ctx context.Context, prm layer.PrmObjectCreate) (oi
 		prmObjPutInit.WithBearerToken(*prm.BearerToken)
 	}
 
-	writer, err := x.pool.ObjectPutInit(ctx, obj, x.signer(ctx), prmObjPutInit)
+	conns, release := x.borrowPool(true)
+	defer release()
+	ow := sessionRetryInitializer{objectPutInitializer: conns}
+	writer, err := ow.ObjectPutInit(ctx, obj, x.signer(ctx), prmObjPutInit)
 	if err != nil {
 		reason, ok := isErrAccessDenied(err)
 		if ok {
@@ -378,7 +550,9 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 
 	if prm.WithHeader {
 		if prm.WithPayload {
-			header, res, err := x.pool.ObjectGetInit(ctx, prm.Container, prm.Object, x.signer(ctx), prmGet)
+			conns, release := x.borrowPool(false)
+			defer release()
+			header, res, err := conns.ObjectGetInit(ctx, prm.Container, prm.Object, x.signer(ctx), prmGet)
 			if err != nil {
 				if reason, ok := isErrAccessDenied(err); ok {
 					return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
@@ -407,7 +581,9 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 			prmHead.WithBearerToken(*prm.BearerToken)
 		}
 
-		hdr, err := x.pool.ObjectHead(ctx, prm.Container, prm.Object, x.signer(ctx), prmHead)
+		conns, release := x.borrowPool(false)
+		defer release()
+		hdr, err := conns.ObjectHead(ctx, prm.Container, prm.Object, x.signer(ctx), prmHead)
 		if err != nil {
 			if reason, ok := isErrAccessDenied(err); ok {
 				return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
@@ -419,9 +595,26 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		return &layer.ObjectPart{
 			Head: hdr,
 		}, nil
-	} else if prm.PayloadRange[0]+prm.PayloadRange[1] == 0 {
-		_, res, err := x.pool.ObjectGetInit(ctx, prm.Container, prm.Object, x.signer(ctx), prmGet)
+	}
+
+	if prm.Hedged && x.cfg.HedgeToReplicas {
+		payload, err := x.readFromReplica(ctx, prm)
+		if err == nil {
+			return &layer.ObjectPart{
+				Payload: payload,
+			}, nil
+		}
+		// Fall back to the pool, it may still choose another node.
+	}
+
+	// Payload is read after the return, so the pool is released once it's
+	// closed.
+	conns, release := x.borrowPool(false)
+
+	if prm.PayloadRange[0]+prm.PayloadRange[1] == 0 {
+		_, res, err := conns.ObjectGetInit(ctx, prm.Container, prm.Object, x.signer(ctx), prmGet)
 		if err != nil {
+			release()
 			if reason, ok := isErrAccessDenied(err); ok {
 				return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
 			}
@@ -430,7 +623,7 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		}
 
 		return &layer.ObjectPart{
-			Payload: res,
+			Payload: releasingReader{ReadCloser: res, release: release},
 		}, nil
 	}
 
@@ -440,8 +633,9 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 		prmRange.WithBearerToken(*prm.BearerToken)
 	}
 
-	res, err := x.pool.ObjectRangeInit(ctx, prm.Container, prm.Object, prm.PayloadRange[0], prm.PayloadRange[1], x.signer(ctx), prmRange)
+	res, err := conns.ObjectRangeInit(ctx, prm.Container, prm.Object, prm.PayloadRange[0], prm.PayloadRange[1], x.signer(ctx), prmRange)
 	if err != nil {
+		release()
 		if reason, ok := isErrAccessDenied(err); ok {
 			return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
 		}
@@ -450,7 +644,7 @@ func (x *NeoFS) ReadObject(ctx context.Context, prm layer.PrmObjectRead) (*layer
 	}
 
 	return &layer.ObjectPart{
-		Payload: payloadReader{res},
+		Payload: payloadReader{releasingReader{ReadCloser: res, release: release}},
 	}, nil
 }
 
@@ -462,7 +656,9 @@ func (x *NeoFS) DeleteObject(ctx context.Context, prm layer.PrmObjectDelete) err
 		prmDelete.WithBearerToken(*prm.BearerToken)
 	}
 
-	_, err := x.pool.ObjectDelete(ctx, prm.Container, prm.Object, x.signer(ctx), prmDelete)
+	conns, release := x.borrowPool(true)
+	defer release()
+	_, err := conns.ObjectDelete(ctx, prm.Container, prm.Object, x.signer(ctx), prmDelete)
 	if err != nil {
 		if reason, ok := isErrAccessDenied(err); ok {
 			return fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
@@ -474,6 +670,119 @@ func (x *NeoFS) DeleteObject(ctx context.Context, prm layer.PrmObjectDelete) err
 	return nil
 }
 
+// tombstoneLifetime is the number of epochs tombstones saved by DeleteObjects
+// live for, it's the default of NeoFS storage nodes.
+const tombstoneLifetime = 5
+
+// DeleteObjects implements neofs.NeoFS interface method.
+func (x *NeoFS) DeleteObjects(ctx context.Context, prm layer.PrmObjectsDelete) error {
+	conns, release := x.borrowPool(false)
+	defer release()
+	networkInfo, err := conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return fmt.Errorf("get network info via client: %w", err)
+	}
+	expiration := networkInfo.CurrentEpoch() + tombstoneLifetime
+
+	tomb := object.NewTombstone()
+	tomb.SetExpirationEpoch(expiration)
+	tomb.SetMembers(prm.Objects)
+
+	payload, err := tomb.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshal tombstone: %w", err)
+	}
+
+	a := object.NewAttribute()
+	a.SetKey(object.AttributeExpirationEpoch)
+	a.SetValue(strconv.FormatUint(expiration, 10))
+
+	var obj object.Object
+	obj.SetContainerID(prm.Container)
+	obj.SetOwnerID(&prm.Creator)
+	obj.SetType(object.TypeTombstone)
+	obj.SetAttributes(*a)
+	obj.SetPayloadSize(uint64(len(payload)))
+
+	var prmObjPutInit client.PrmObjectPutInit
+
+	if prm.BearerToken != nil {
+		prmObjPutInit.WithBearerToken(*prm.BearerToken)
+	}
+
+	writeConns, releaseWrite := x.borrowPool(true)
+	defer releaseWrite()
+	ow := sessionRetryInitializer{objectPutInitializer: writeConns}
+	writer, err := ow.ObjectPutInit(ctx, obj, x.signer(ctx), prmObjPutInit)
+	if err == nil {
+		if _, err = writer.Write(payload); err == nil {
+			err = writer.Close()
+		}
+	}
+	if err != nil {
+		if reason, ok := isErrAccessDenied(err); ok {
+			return fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
+		}
+
+		return fmt.Errorf("save tombstone via connection pool: %w", err)
+	}
+
+	return nil
+}
+
+// searchMatchTypes maps layer search matches to NeoFS ones.
+var searchMatchTypes = map[layer.SearchMatch]object.SearchMatchType{
+	layer.SearchMatchEqual:    object.MatchStringEqual,
+	layer.SearchMatchNotEqual: object.MatchStringNotEqual,
+	layer.SearchMatchPrefix:   object.MatchCommonPrefix,
+}
+
+// SearchObjects implements neofs.NeoFS interface method.
+func (x *NeoFS) SearchObjects(ctx context.Context, prm layer.PrmObjectSearch) ([]oid.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	if prm.Value != "" {
+		filters.AddFilter(prm.Attribute, prm.Value, object.MatchStringEqual)
+	} else {
+		filters.AddFilter(prm.Attribute, "", object.MatchCommonPrefix)
+	}
+	for _, f := range prm.Filters {
+		filters.AddFilter(f.Attribute, f.Value, searchMatchTypes[f.Match])
+	}
+
+	var prmSearch client.PrmObjectSearch
+	prmSearch.SetFilters(filters)
+
+	if prm.BearerToken != nil {
+		prmSearch.WithBearerToken(*prm.BearerToken)
+	}
+
+	conns, release := x.borrowPool(false)
+	defer release()
+	res, err := conns.ObjectSearchInit(ctx, prm.Container, x.signer(ctx), prmSearch)
+	if err != nil {
+		if reason, ok := isErrAccessDenied(err); ok {
+			return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
+		}
+
+		return nil, fmt.Errorf("init object search via connection pool: %w", err)
+	}
+
+	var ids []oid.ID
+	if err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	}); err != nil {
+		if reason, ok := isErrAccessDenied(err); ok {
+			return nil, fmt.Errorf("%w: %s", layer.ErrAccessDenied, reason)
+		}
+
+		return nil, fmt.Errorf("search objects via connection pool: %w", err)
+	}
+
+	return ids, nil
+}
+
 func isErrAccessDenied(err error) (string, bool) {
 	unwrappedErr := errors.Unwrap(err)
 	for unwrappedErr != nil {
@@ -547,6 +856,9 @@ func (x *AuthmateNeoFS) CreateContainer(ctx context.Context, prm authmate.PrmCon
 	basicACL := acl.Private
 	// allow reading objects to OTHERS in order to provide read access to S3 gateways
 	basicACL.AllowOp(acl.OpObjectGet, acl.RoleOthers)
+	// allow listing credentials to OTHERS for the registry of S3 gateways
+	basicACL.AllowOp(acl.OpObjectHead, acl.RoleOthers)
+	basicACL.AllowOp(acl.OpObjectSearch, acl.RoleOthers)
 
 	return x.neoFS.CreateContainer(ctx, layer.PrmContainerCreate{
 		Creator:       prm.Owner,
@@ -579,12 +891,43 @@ func (x *AuthmateNeoFS) CreateObject(ctx context.Context, prm tokens.PrmObjectCr
 		Creator:   prm.Creator,
 		Container: prm.Container,
 		Filepath:  prm.Filepath,
-		Attributes: [][2]string{
-			{object.AttributeExpirationEpoch, strconv.FormatUint(prm.ExpirationEpoch, 10)}},
+		Attributes: append([][2]string{
+			{object.AttributeExpirationEpoch, strconv.FormatUint(prm.ExpirationEpoch, 10)}}, prm.Attributes...),
 		Payload: bytes.NewReader(prm.Payload),
 	})
 }
 
+// SearchObjects implements registry.NeoFS interface method.
+func (x *AuthmateNeoFS) SearchObjects(ctx context.Context, cnrID cid.ID, attribute string) ([]oid.ID, error) {
+	return x.neoFS.SearchObjects(ctx, layer.PrmObjectSearch{
+		Container: cnrID,
+		Attribute: attribute,
+	})
+}
+
+// SearchObjectsByAttribute implements backend.NeoFS interface method.
+func (x *AuthmateNeoFS) SearchObjectsByAttribute(ctx context.Context, cnrID cid.ID, attribute, value string) ([]oid.ID, error) {
+	return x.neoFS.SearchObjects(ctx, layer.PrmObjectSearch{
+		Container: cnrID,
+		Attribute: attribute,
+		Value:     value,
+	})
+}
+
+// ReadObjectHeader implements registry.NeoFS interface method.
+func (x *AuthmateNeoFS) ReadObjectHeader(ctx context.Context, addr oid.Address) (*object.Object, error) {
+	res, err := x.neoFS.ReadObject(ctx, layer.PrmObjectRead{
+		Container:  addr.Container(),
+		Object:     addr.Object(),
+		WithHeader: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Head, nil
+}
+
 // PoolStatistic is a mediator which implements authmate.NeoFS through pool.Pool.
 type PoolStatistic struct {
 	poolStat *stat.PoolStat
@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
 	"github.com/nspcc-dev/neofs-sdk-go/container"
@@ -234,3 +236,88 @@ func uploadDownload(ctx context.Context, t *testing.T, neo *NeoFS, p *pool.Pool,
 		require.True(t, bytes.Equal(payload, pl))
 	}
 }
+
+func TestPreviousKeysSigner(t *testing.T) {
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	prevKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	anonKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	neoFS := NewNeoFS(nil, user.NewAutoIDSignerRFC6979(gateKey.PrivateKey), user.NewAutoIDSignerRFC6979(anonKey.PrivateKey),
+		Config{PreviousKeys: []*keys.PrivateKey{prevKey}}, nil)
+
+	withBox := func(key *keys.PrivateKey) context.Context {
+		box := &accessbox.Box{Gate: accessbox.NewGateData(key.PublicKey(), nil)}
+		return context.WithValue(context.Background(), api.BoxData, box)
+	}
+
+	gateID := user.NewAutoIDSignerRFC6979(gateKey.PrivateKey).UserID()
+	require.Equal(t, gateID, neoFS.signer(context.Background()).UserID())
+	require.Equal(t, gateID, neoFS.signer(withBox(gateKey)).UserID())
+	require.Equal(t, user.NewAutoIDSignerRFC6979(prevKey.PrivateKey).UserID(), neoFS.signer(withBox(prevKey)).UserID())
+}
+
+func TestSwapWritePool(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	reads, writes := new(pool.Pool), new(pool.Pool)
+	neoFS := NewNeoFS(reads, signer, signer, Config{}, nil)
+	require.Same(t, reads, neoFS.writePool.Load())
+
+	// the read pool is still used
+	drained := neoFS.SwapWritePool(writes)
+	require.Same(t, reads, neoFS.pool.Load())
+	require.Same(t, writes, neoFS.writePool.Load())
+	require.False(t, isClosed(drained))
+
+	// recycling of the read pool doesn't affect writes
+	recycled := new(pool.Pool)
+	require.True(t, isClosed(neoFS.SwapPool(recycled)))
+	require.Same(t, recycled, neoFS.pool.Load())
+	require.Same(t, writes, neoFS.writePool.Load())
+}
+
+func TestSwapPoolDrained(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	first := new(pool.Pool)
+	neoFS := NewNeoFS(first, signer, signer, Config{}, nil)
+
+	borrowed, release := neoFS.borrowPool(false)
+	require.Same(t, first, borrowed)
+	_, releaseWrite := neoFS.borrowPool(true)
+
+	// writes are switched to the recycled pool if they share it with reads
+	second := new(pool.Pool)
+	drained := neoFS.SwapPool(second)
+	require.Same(t, second, neoFS.pool.Load())
+	require.Same(t, second, neoFS.writePool.Load())
+
+	// the replaced pool is drained once all operations release it
+	release()
+	release()
+	require.False(t, isClosed(drained))
+	releaseWrite()
+	require.True(t, isClosed(drained))
+
+	borrowed, release = neoFS.borrowPool(false)
+	require.Same(t, second, borrowed)
+	release()
+	require.Empty(t, neoFS.borrowed)
+	require.Empty(t, neoFS.drained)
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,310 @@
+package neofs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+const (
+	// netmapLifetime is the time the network map is used for placement
+	// of hedged reads before it is requested again.
+	netmapLifetime = time.Minute
+
+	// maxCachedPolicies limits the number of cached container policies.
+	maxCachedPolicies = 1000
+)
+
+// replicas reads objects directly from container nodes storing them.
+type replicas struct {
+	dialTimeout   time.Duration
+	streamTimeout time.Duration
+
+	mu        sync.Mutex
+	netmap    *netmap.NetMap
+	netmapExp time.Time
+	policies  map[cid.ID]netmap.PlacementPolicy
+	clients   map[string]*client.Client
+}
+
+func newReplicas(cfg Config) *replicas {
+	return &replicas{
+		dialTimeout:   cfg.NodeDialTimeout,
+		streamTimeout: cfg.NodeStreamTimeout,
+		policies:      make(map[cid.ID]netmap.PlacementPolicy),
+		clients:       make(map[string]*client.Client),
+	}
+}
+
+// readFromReplica reads object payload locally from a container node storing
+// it. The first node of the placement is tried last, because the regular
+// request is most likely served by it.
+func (x *NeoFS) readFromReplica(ctx context.Context, prm layer.PrmObjectRead) (io.ReadCloser, error) {
+	nodes, err := x.objectNodes(ctx, prm.Container, prm.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) > 1 {
+		nodes = append(nodes[1:], nodes[0])
+	}
+
+	lastErr := errors.New("no container nodes")
+	for _, node := range nodes {
+		c, err := x.replicas.client(ctx, node)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		payload, err := x.readLocal(ctx, c, prm)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return payload, nil
+	}
+
+	return nil, fmt.Errorf("read from replicas: %w", lastErr)
+}
+
+func (x *NeoFS) readLocal(ctx context.Context, c *client.Client, prm layer.PrmObjectRead) (io.ReadCloser, error) {
+	if prm.PayloadRange[0]+prm.PayloadRange[1] == 0 {
+		var prmGet client.PrmObjectGet
+		prmGet.MarkLocal()
+		if prm.BearerToken != nil {
+			prmGet.WithBearerToken(*prm.BearerToken)
+		}
+
+		_, res, err := c.ObjectGetInit(ctx, prm.Container, prm.Object, x.signer(ctx), prmGet)
+		if err != nil {
+			return nil, err
+		}
+
+		return payloadReader{res}, nil
+	}
+
+	var prmRange client.PrmObjectRange
+	prmRange.MarkLocal()
+	if prm.BearerToken != nil {
+		prmRange.WithBearerToken(*prm.BearerToken)
+	}
+
+	res, err := c.ObjectRangeInit(ctx, prm.Container, prm.Object, prm.PayloadRange[0], prm.PayloadRange[1], x.signer(ctx), prmRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return payloadReader{res}, nil
+}
+
+// objectNodes returns container nodes storing the object in the placement order.
+func (x *NeoFS) objectNodes(ctx context.Context, cnrID cid.ID, objID oid.ID) ([]netmap.NodeInfo, error) {
+	nm, err := x.replicasNetmap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := x.replicasPolicy(ctx, cnrID)
+	if err != nil {
+		return nil, err
+	}
+
+	cnrNodes, err := nm.ContainerNodes(policy, cnrID)
+	if err != nil {
+		return nil, fmt.Errorf("build container nodes: %w", err)
+	}
+
+	vectors, err := nm.PlacementVectors(cnrNodes, objID)
+	if err != nil {
+		return nil, fmt.Errorf("build placement vectors: %w", err)
+	}
+
+	return placementNodes(vectors), nil
+}
+
+func (x *NeoFS) replicasNetmap(ctx context.Context) (*netmap.NetMap, error) {
+	x.replicas.mu.Lock()
+	nm := x.replicas.netmap
+	valid := time.Now().Before(x.replicas.netmapExp)
+	x.replicas.mu.Unlock()
+
+	if nm != nil && valid {
+		return nm, nil
+	}
+
+	conns, release := x.borrowPool(false)
+	defer release()
+	res, err := conns.NetMapSnapshot(ctx, client.PrmNetMapSnapshot{})
+	if err != nil {
+		return nil, fmt.Errorf("get network map: %w", err)
+	}
+
+	x.replicas.mu.Lock()
+	x.replicas.netmap = &res
+	x.replicas.netmapExp = time.Now().Add(netmapLifetime)
+	x.replicas.mu.Unlock()
+
+	return &res, nil
+}
+
+func (x *NeoFS) replicasPolicy(ctx context.Context, cnrID cid.ID) (netmap.PlacementPolicy, error) {
+	x.replicas.mu.Lock()
+	policy, ok := x.replicas.policies[cnrID]
+	x.replicas.mu.Unlock()
+
+	if ok {
+		return policy, nil
+	}
+
+	cnr, err := x.Container(ctx, cnrID)
+	if err != nil {
+		return netmap.PlacementPolicy{}, err
+	}
+	policy = cnr.PlacementPolicy()
+
+	x.replicas.mu.Lock()
+	if len(x.replicas.policies) >= maxCachedPolicies {
+		x.replicas.policies = make(map[cid.ID]netmap.PlacementPolicy)
+	}
+	x.replicas.policies[cnrID] = policy
+	x.replicas.mu.Unlock()
+
+	return policy, nil
+}
+
+// client returns the client connected to the node, dialing it if needed.
+func (r *replicas) client(ctx context.Context, node netmap.NodeInfo) (*client.Client, error) {
+	var addrs []string
+	node.IterateNetworkEndpoints(func(endpoint string) bool {
+		if addr, err := endpointToURI(endpoint); err == nil {
+			addrs = append(addrs, addr)
+		}
+		return false
+	})
+
+	r.mu.Lock()
+	for _, addr := range addrs {
+		if c, ok := r.clients[addr]; ok {
+			r.mu.Unlock()
+			return c, nil
+		}
+	}
+	r.mu.Unlock()
+
+	if len(addrs) == 0 {
+		return nil, errors.New("node has no supported network endpoints")
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		c, err := r.dial(ctx, addr)
+		if err != nil {
+			lastErr = fmt.Errorf("dial %s: %w", addr, err)
+			continue
+		}
+
+		r.mu.Lock()
+		if existing, ok := r.clients[addr]; ok {
+			r.mu.Unlock()
+			_ = c.Close()
+			return existing, nil
+		}
+		r.clients[addr] = c
+		r.mu.Unlock()
+
+		return c, nil
+	}
+
+	return nil, lastErr
+}
+
+func (r *replicas) dial(ctx context.Context, addr string) (*client.Client, error) {
+	c, err := client.New(client.PrmInit{})
+	if err != nil {
+		return nil, err
+	}
+
+	var prmDial client.PrmDial
+	prmDial.SetServerURI(addr)
+	prmDial.SetContext(ctx)
+	if r.dialTimeout > 0 {
+		prmDial.SetTimeout(r.dialTimeout)
+	}
+	if r.streamTimeout > 0 {
+		prmDial.SetStreamTimeout(r.streamTimeout)
+	}
+
+	if err = c.Dial(prmDial); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// placementNodes flattens placement vectors removing duplicates.
+func placementNodes(vectors [][]netmap.NodeInfo) []netmap.NodeInfo {
+	var (
+		res  []netmap.NodeInfo
+		seen = make(map[string]struct{})
+	)
+
+	for _, vector := range vectors {
+		for _, node := range vector {
+			key := string(node.PublicKey())
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			res = append(res, node)
+		}
+	}
+
+	return res
+}
+
+// endpointToURI converts the node network endpoint in multiaddr format
+// (e.g. /dns4/s01.neofs.devenv/tcp/8080/tls) to URI accepted by the client.
+func endpointToURI(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		// Some nodes announce plain host:port or URI.
+		return endpoint, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(endpoint, "/"), "/")
+	if len(parts) < 4 || parts[2] != "tcp" {
+		return "", fmt.Errorf("unsupported endpoint: %s", endpoint)
+	}
+
+	var host string
+	switch parts[0] {
+	case "ip4", "dns", "dns4", "dns6":
+		host = parts[1]
+	case "ip6":
+		host = "[" + parts[1] + "]"
+	default:
+		return "", fmt.Errorf("unsupported endpoint: %s", endpoint)
+	}
+
+	scheme := "grpc"
+	switch {
+	case len(parts) == 4:
+	case len(parts) == 5 && parts[4] == "tls":
+		scheme = "grpcs"
+	default:
+		return "", fmt.Errorf("unsupported endpoint: %s", endpoint)
+	}
+
+	return scheme + "://" + host + ":" + parts[3], nil
+}
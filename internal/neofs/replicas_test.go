@@ -0,0 +1,47 @@
+package neofs
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointToURI(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint string
+		uri      string
+		err      bool
+	}{
+		{endpoint: "/dns4/s01.neofs.devenv/tcp/8080", uri: "grpc://s01.neofs.devenv:8080"},
+		{endpoint: "/ip4/10.0.0.1/tcp/8080/tls", uri: "grpcs://10.0.0.1:8080"},
+		{endpoint: "/ip6/::1/tcp/8080", uri: "grpc://[::1]:8080"},
+		{endpoint: "grpcs://s01.neofs.devenv:8082", uri: "grpcs://s01.neofs.devenv:8082"},
+		{endpoint: "/ip4/10.0.0.1/udp/8080", err: true},
+		{endpoint: "/unix/tmp/sock", err: true},
+		{endpoint: "/ip4/10.0.0.1/tcp/8080/ws", err: true},
+	} {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			uri, err := endpointToURI(tc.endpoint)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.uri, uri)
+		})
+	}
+}
+
+func TestPlacementNodes(t *testing.T) {
+	nodes := make([]netmap.NodeInfo, 3)
+	for i := range nodes {
+		nodes[i].SetPublicKey([]byte{byte(i)})
+	}
+
+	res := placementNodes([][]netmap.NodeInfo{
+		{nodes[1], nodes[0]},
+		{nodes[0], nodes[2]},
+	})
+	require.Equal(t, []netmap.NodeInfo{nodes[1], nodes[0], nodes[2]}, res)
+}
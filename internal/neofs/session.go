@@ -0,0 +1,129 @@
+package neofs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+type (
+	// objectPutInitializer opens object streams, it's implemented by pool.Pool.
+	objectPutInitializer interface {
+		ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error)
+	}
+
+	// sessionRetryInitializer opens object streams which are reopened if NeoFS
+	// rejects them because of the expired session token.
+	sessionRetryInitializer struct {
+		objectPutInitializer
+		// keepFirst allows replaying the first written chunk on close, see
+		// sessionRetryWriter.
+		keepFirst bool
+	}
+
+	// sessionRetryWriter reopens the object stream once if NeoFS rejects it
+	// because of the expired session token. It happens when the epoch changes
+	// while the upload is in progress: the pool drops the session expired at
+	// the new epoch and the reopened stream gets a new one. The payload can't
+	// be read again, so the stream is reopened only if it failed before
+	// anything was written or during the first Write call. If keepFirst is
+	// set, the data of the first Write is kept to be written again when the
+	// stream is rejected on close, it's safe only for writers like the slicer
+	// which write object payload from the memory with a single Write.
+	sessionRetryWriter struct {
+		init      func() (client.ObjectWriter, error)
+		writer    client.ObjectWriter
+		keepFirst bool
+		first     []byte
+		writes    int
+		reopened  bool
+	}
+)
+
+// ObjectPutInit implements slicer.ObjectWriter interface.
+func (x sessionRetryInitializer) ObjectPutInit(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	return initSessionRetryWriter(func() (client.ObjectWriter, error) {
+		return x.objectPutInitializer.ObjectPutInit(ctx, hdr, signer, prm)
+	}, x.keepFirst)
+}
+
+func initSessionRetryWriter(init func() (client.ObjectWriter, error), keepFirst bool) (*sessionRetryWriter, error) {
+	w := &sessionRetryWriter{init: init, keepFirst: keepFirst}
+
+	var err error
+	if w.writer, err = init(); err != nil {
+		if !w.reopen(err) {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+func (w *sessionRetryWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if err != nil {
+		if w.writes > 0 || !w.reopen(err) {
+			return n, err
+		}
+		n, err = w.writer.Write(p)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	w.writes++
+	w.first = nil
+	if w.keepFirst && w.writes == 1 {
+		w.first = p
+	}
+
+	return n, nil
+}
+
+func (w *sessionRetryWriter) Close() error {
+	err := w.writer.Close()
+	if err == nil || (w.writes > 0 && w.first == nil) || !w.reopen(err) {
+		return err
+	}
+
+	if w.first != nil {
+		if _, err = w.writer.Write(w.first); err != nil {
+			return err
+		}
+	}
+
+	return w.writer.Close()
+}
+
+func (w *sessionRetryWriter) GetResult() client.ResObjectPut {
+	return w.writer.GetResult()
+}
+
+// reopen initializes the stream again if err is caused by the session token
+// and the stream hasn't been reopened yet.
+func (w *sessionRetryWriter) reopen(err error) bool {
+	if w.reopened || !isErrSessionExpired(err) {
+		return false
+	}
+	w.reopened = true
+
+	writer, err := w.init()
+	if err != nil {
+		return false
+	}
+	w.writer = writer
+
+	return true
+}
+
+// isErrSessionExpired checks if NeoFS rejected the request because of the
+// expired session token. Node removes expired tokens, so they may be reported
+// as not found as well.
+func isErrSessionExpired(err error) bool {
+	return errors.Is(err, apistatus.ErrSessionTokenExpired) || errors.Is(err, apistatus.ErrSessionTokenNotFound)
+}
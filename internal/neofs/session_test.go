@@ -0,0 +1,129 @@
+package neofs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// testStreams opens streams failing as configured, streams are indexed
+	// by the order of opening.
+	testStreams struct {
+		initErrs  map[int]error
+		writeErrs map[int]error
+		closeErrs map[int]error
+		opened    []*testStream
+	}
+
+	testStream struct {
+		payload  bytes.Buffer
+		writeErr error
+		closeErr error
+		closed   bool
+	}
+)
+
+func (s *testStreams) ObjectPutInit(context.Context, object.Object, user.Signer, client.PrmObjectPutInit) (client.ObjectWriter, error) {
+	i := len(s.opened)
+	stream := &testStream{writeErr: s.writeErrs[i], closeErr: s.closeErrs[i]}
+	s.opened = append(s.opened, stream)
+
+	if err := s.initErrs[i]; err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+func (s *testStream) Write(p []byte) (int, error) {
+	if s.writeErr != nil {
+		return 0, s.writeErr
+	}
+	return s.payload.Write(p)
+}
+
+func (s *testStream) Close() error {
+	s.closed = true
+	return s.closeErr
+}
+
+func (s *testStream) GetResult() client.ResObjectPut {
+	return client.ResObjectPut{}
+}
+
+func TestSessionRetryWriter(t *testing.T) {
+	payload := []byte("payload")
+	errExpired := apistatus.ErrSessionTokenExpired
+	errNotFound := apistatus.ErrSessionTokenNotFound
+
+	put := func(streams *testStreams, keepFirst bool, chunks ...[]byte) error {
+		w, err := sessionRetryInitializer{objectPutInitializer: streams, keepFirst: keepFirst}.
+			ObjectPutInit(context.Background(), object.Object{}, nil, client.PrmObjectPutInit{})
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			if _, err = w.Write(chunk); err != nil {
+				return err
+			}
+		}
+
+		return w.Close()
+	}
+
+	t.Run("init", func(t *testing.T) {
+		streams := &testStreams{initErrs: map[int]error{0: errExpired}}
+		require.NoError(t, put(streams, false, payload))
+		require.Len(t, streams.opened, 2)
+		require.Equal(t, payload, streams.opened[1].payload.Bytes())
+	})
+
+	t.Run("first write", func(t *testing.T) {
+		streams := &testStreams{writeErrs: map[int]error{0: errNotFound}}
+		require.NoError(t, put(streams, false, payload, payload))
+		require.Len(t, streams.opened, 2)
+		require.Equal(t, append(payload, payload...), streams.opened[1].payload.Bytes())
+	})
+
+	t.Run("close", func(t *testing.T) {
+		streams := &testStreams{closeErrs: map[int]error{0: errExpired}}
+		require.ErrorIs(t, put(streams, false, payload), errExpired)
+		require.Len(t, streams.opened, 1)
+
+		streams = &testStreams{closeErrs: map[int]error{0: errExpired}}
+		require.NoError(t, put(streams, true, payload))
+		require.Len(t, streams.opened, 2)
+		require.Equal(t, payload, streams.opened[1].payload.Bytes())
+		require.True(t, streams.opened[1].closed)
+
+		streams = &testStreams{closeErrs: map[int]error{0: errExpired}}
+		require.ErrorIs(t, put(streams, true, payload, payload), errExpired)
+		require.Len(t, streams.opened, 1)
+	})
+
+	t.Run("once", func(t *testing.T) {
+		streams := &testStreams{writeErrs: map[int]error{0: errExpired, 1: errExpired}}
+		require.ErrorIs(t, put(streams, false, payload), errExpired)
+		require.Len(t, streams.opened, 2)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		errOther := errors.New("some error")
+		streams := &testStreams{writeErrs: map[int]error{0: errOther}}
+		require.ErrorIs(t, put(streams, true, payload), errOther)
+		require.Len(t, streams.opened, 1)
+
+		streams = &testStreams{closeErrs: map[int]error{0: apistatus.ErrObjectAccessDenied}}
+		require.ErrorIs(t, put(streams, true, payload), apistatus.ErrObjectAccessDenied)
+		require.Len(t, streams.opened, 1)
+	})
+}
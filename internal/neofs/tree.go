@@ -2,6 +2,7 @@ package neofs
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -23,9 +24,10 @@ import (
 
 type (
 	TreeClient struct {
-		key     *keys.PrivateKey
-		conn    *grpc.ClientConn
-		service tree.TreeServiceClient
+		key      *keys.PrivateKey
+		prevKeys previousKeys
+		conn     *grpc.ClientConn
+		service  tree.TreeServiceClient
 	}
 
 	TreeNode struct {
@@ -50,6 +52,14 @@ type (
 const (
 	versioningKV        = "Versioning"
 	lockConfigurationKV = "LockConfiguration"
+	compressionKV       = "Compression"
+	deduplicationKV     = "Deduplication"
+	uploadConstraintsKV = "UploadConstraints"
+	anonymousAccessKV   = "AnonymousAccess"
+	deletionKV          = "Deletion"
+	privacyKV           = "Privacy"
+	schemaVersionKV     = "SchemaVersion"
+	revisionKV          = "Revision"
 	oidKV               = "OID"
 	fileNameKV          = "FileName"
 	isUnversionedKV     = "IsUnversioned"
@@ -58,6 +68,7 @@ const (
 	partNumberKV        = "Number"
 	sizeKV              = "Size"
 	etagKV              = "ETag"
+	refsKV              = "Refs"
 
 	// keys for lock.
 	isLockKV       = "IsLock"
@@ -71,10 +82,14 @@ const (
 	ownerKV          = "Owner"
 	createdKV        = "Created"
 
+	// keys for trashed version nodes.
+	deletedKV = "Deleted"
+
 	settingsFileName      = "bucket-settings"
 	notifConfFileName     = "bucket-notifications"
 	corsFilename          = "bucket-cors"
 	bucketTaggingFilename = "bucket-tagging"
+	payloadFilenamePrefix = "payload-"
 
 	// versionTree -- ID of a tree with object versions.
 	versionTree = "version"
@@ -83,6 +98,10 @@ const (
 	// i.e. bucket settings with versioning and lock configuration, cors, notifications.
 	systemTree = "system"
 
+	// trashTree -- ID of a tree with object versions deleted in buckets with
+	// soft deletion.
+	trashTree = "trash"
+
 	separator            = "/"
 	userDefinedTagPrefix = "User-Tag-"
 
@@ -90,7 +109,8 @@ const (
 )
 
 // NewTreeClient creates instance of TreeClient using provided address and create grpc connection.
-func NewTreeClient(ctx context.Context, addr string, key *keys.PrivateKey) (*TreeClient, error) {
+// Requests with access boxes issued for previous keys of the gateway are signed with them.
+func NewTreeClient(ctx context.Context, addr string, key *keys.PrivateKey, previous ...*keys.PrivateKey) (*TreeClient, error) {
 	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, fmt.Errorf("did not connect: %v", err)
@@ -102,9 +122,10 @@ func NewTreeClient(ctx context.Context, addr string, key *keys.PrivateKey) (*Tre
 	}
 
 	return &TreeClient{
-		key:     key,
-		conn:    conn,
-		service: c,
+		key:      key,
+		prevKeys: newPreviousKeys(previous),
+		conn:     conn,
+		service:  c,
 	}, nil
 }
 
@@ -270,12 +291,17 @@ func newPartInfo(node NodeResponse) (*data.PartInfo, error) {
 }
 
 func (c *TreeClient) GetSettingsNode(ctx context.Context, bktInfo *data.BucketInfo) (*data.BucketSettings, error) {
-	keysToReturn := []string{versioningKV, lockConfigurationKV}
+	keysToReturn := []string{versioningKV, lockConfigurationKV, compressionKV, deduplicationKV, uploadConstraintsKV, anonymousAccessKV, deletionKV, privacyKV, schemaVersionKV, revisionKV}
 	node, err := c.getSystemNode(ctx, bktInfo, []string{settingsFileName}, keysToReturn)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't get node: %w", err)
 	}
 
+	return newBucketSettings(node)
+}
+
+func newBucketSettings(node *TreeNode) (*data.BucketSettings, error) {
+	var err error
 	settings := &data.BucketSettings{Versioning: data.VersioningUnversioned}
 	if versioningValue, ok := node.Get(versioningKV); ok {
 		settings.Versioning = versioningValue
@@ -287,6 +313,56 @@ func (c *TreeClient) GetSettingsNode(ctx context.Context, bktInfo *data.BucketIn
 		}
 	}
 
+	if compressionValue, ok := node.Get(compressionKV); ok {
+		settings.Compression = compressionValue
+	}
+
+	if deduplicationValue, ok := node.Get(deduplicationKV); ok {
+		if settings.Deduplication, err = strconv.ParseBool(deduplicationValue); err != nil {
+			return nil, fmt.Errorf("settings node: invalid deduplication: %w", err)
+		}
+	}
+
+	if constraintsValue, ok := node.Get(uploadConstraintsKV); ok && constraintsValue != "" {
+		settings.UploadConstraints = new(data.UploadConstraints)
+		if err = json.Unmarshal([]byte(constraintsValue), settings.UploadConstraints); err != nil {
+			return nil, fmt.Errorf("settings node: invalid upload constraints: %w", err)
+		}
+	}
+
+	if accessValue, ok := node.Get(anonymousAccessKV); ok && accessValue != "" {
+		settings.AnonymousAccess = new(data.AnonymousAccess)
+		if err = json.Unmarshal([]byte(accessValue), settings.AnonymousAccess); err != nil {
+			return nil, fmt.Errorf("settings node: invalid anonymous access: %w", err)
+		}
+	}
+
+	if deletionValue, ok := node.Get(deletionKV); ok && deletionValue != "" {
+		settings.Deletion = new(data.DeletionConfiguration)
+		if err = json.Unmarshal([]byte(deletionValue), settings.Deletion); err != nil {
+			return nil, fmt.Errorf("settings node: invalid deletion configuration: %w", err)
+		}
+	}
+
+	if privacyValue, ok := node.Get(privacyKV); ok && privacyValue != "" {
+		settings.Privacy = new(data.PrivacyConfiguration)
+		if err = json.Unmarshal([]byte(privacyValue), settings.Privacy); err != nil {
+			return nil, fmt.Errorf("settings node: invalid privacy configuration: %w", err)
+		}
+	}
+
+	if versionValue, ok := node.Get(schemaVersionKV); ok {
+		if settings.SchemaVersion, err = strconv.Atoi(versionValue); err != nil {
+			return nil, fmt.Errorf("settings node: invalid schema version: %w", err)
+		}
+	}
+
+	if revisionValue, ok := node.Get(revisionKV); ok {
+		if settings.Revision, err = strconv.ParseUint(revisionValue, 10, 64); err != nil {
+			return nil, fmt.Errorf("settings node: invalid revision: %w", err)
+		}
+	}
+
 	return settings, nil
 }
 
@@ -380,6 +456,163 @@ func (c *TreeClient) DeleteBucketCORS(ctx context.Context, bktInfo *data.BucketI
 	return oid.ID{}, layer.ErrNoNodeToRemove
 }
 
+func (c *TreeClient) GetDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, hash string) (*data.DeduplicatedPayload, error) {
+	node, err := c.getSystemNode(ctx, bktInfo, []string{payloadFilenamePrefix + hash}, []string{oidKV, refsKV})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &data.DeduplicatedPayload{
+		Hash: hash,
+		OID:  node.ObjID,
+	}
+
+	if refsValue, ok := node.Get(refsKV); ok {
+		if payload.Refs, err = strconv.ParseUint(refsValue, 10, 64); err != nil {
+			return nil, fmt.Errorf("payload node: invalid refs: %w", err)
+		}
+	}
+
+	return payload, nil
+}
+
+func (c *TreeClient) PutDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, payload *data.DeduplicatedPayload) error {
+	fileName := payloadFilenamePrefix + payload.Hash
+	node, err := c.getSystemNode(ctx, bktInfo, []string{fileName}, []string{})
+	isErrNotFound := errors.Is(err, layer.ErrNodeNotFound)
+	if err != nil && !isErrNotFound {
+		return fmt.Errorf("couldn't get node: %w", err)
+	}
+
+	meta := map[string]string{
+		fileNameKV: fileName,
+		oidKV:      payload.OID.EncodeToString(),
+		refsKV:     strconv.FormatUint(payload.Refs, 10),
+	}
+
+	if isErrNotFound {
+		_, err = c.addNode(ctx, bktInfo, systemTree, 0, meta)
+		return err
+	}
+
+	return c.moveNode(ctx, bktInfo, systemTree, node.ID, 0, meta)
+}
+
+func (c *TreeClient) DeleteDeduplicatedPayload(ctx context.Context, bktInfo *data.BucketInfo, hash string) error {
+	node, err := c.getSystemNode(ctx, bktInfo, []string{payloadFilenamePrefix + hash}, []string{})
+	if err != nil {
+		if errors.Is(err, layer.ErrNodeNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return c.removeNode(ctx, bktInfo, systemTree, node.ID)
+}
+
+func (c *TreeClient) AddTrashedVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.TrashedVersion) error {
+	meta := map[string]string{
+		oidKV:      version.OID.EncodeToString(),
+		fileNameKV: version.FilePath,
+		deletedKV:  strconv.FormatInt(version.Deleted.UTC().UnixMilli(), 10),
+	}
+
+	if version.Size > 0 {
+		meta[sizeKV] = strconv.FormatInt(version.Size, 10)
+	}
+	if len(version.ETag) > 0 {
+		meta[etagKV] = version.ETag
+	}
+	if version.IsUnversioned {
+		meta[isUnversionedKV] = "true"
+	}
+
+	var err error
+	version.ID, err = c.addNode(ctx, bktInfo, trashTree, 0, meta)
+	return err
+}
+
+func (c *TreeClient) GetTrashedVersions(ctx context.Context, bktInfo *data.BucketInfo, objectName string) ([]*data.TrashedVersion, error) {
+	var nodes []NodeResponse
+	if objectName == "" {
+		subTree, err := c.getSubTree(ctx, bktInfo, trashTree, 0, 2)
+		if err != nil {
+			if errors.Is(err, layer.ErrNodeNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, node := range subTree {
+			nodes = append(nodes, node)
+		}
+	} else {
+		p := &getNodesParams{
+			BktInfo:  bktInfo,
+			TreeID:   trashTree,
+			Path:     []string{objectName},
+			AllAttrs: true,
+		}
+		infos, err := c.getNodes(ctx, p)
+		if err != nil {
+			if errors.Is(err, layer.ErrNodeNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, node := range infos {
+			nodes = append(nodes, node)
+		}
+	}
+
+	result := make([]*data.TrashedVersion, 0, len(nodes))
+	for _, node := range nodes {
+		// the root node of the tree has no file name
+		if node.GetNodeId() == 0 {
+			continue
+		}
+
+		version, err := newTrashedVersion(node)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, version)
+	}
+
+	return result, nil
+}
+
+func (c *TreeClient) RemoveTrashedVersion(ctx context.Context, bktInfo *data.BucketInfo, nodeID uint64) error {
+	return c.removeNode(ctx, bktInfo, trashTree, nodeID)
+}
+
+func newTrashedVersion(node NodeResponse) (*data.TrashedVersion, error) {
+	treeNode, err := newTreeNode(node)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tree node: %w", err)
+	}
+
+	fileName, ok := treeNode.FileName()
+	if !ok {
+		return nil, fmt.Errorf("trashed version node %d has no file name", treeNode.ID)
+	}
+
+	version := newNodeVersionFromTreeNode(fileName, treeNode)
+	trashed := &data.TrashedVersion{
+		BaseNodeVersion: version.BaseNodeVersion,
+		IsUnversioned:   version.IsUnversioned,
+	}
+
+	if deletedStr, ok := treeNode.Get(deletedKV); ok {
+		utcMilli, err := strconv.ParseInt(deletedStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deleted time '%s': %w", deletedStr, err)
+		}
+		trashed.Deleted = time.UnixMilli(utcMilli)
+	}
+
+	return trashed, nil
+}
+
 func (c *TreeClient) GetObjectTagging(ctx context.Context, bktInfo *data.BucketInfo, objVersion *data.NodeVersion) (map[string]string, error) {
 	tagNode, err := c.getTreeNode(ctx, bktInfo, objVersion.ID, isTagKV)
 	if err != nil {
@@ -806,18 +1039,28 @@ func (c *TreeClient) getUnversioned(ctx context.Context, bktInfo *data.BucketInf
 		return nil, err
 	}
 
-	if len(nodes) > 1 {
-		return nil, fmt.Errorf("found more than one unversioned node")
+	if len(nodes) == 0 {
+		return nil, layer.ErrNodeNotFound
 	}
 
-	if len(nodes) != 1 {
-		return nil, layer.ErrNodeNotFound
+	return latestNodeVersion(nodes), nil
+}
+
+// latestNodeVersion returns the most recent of nodes. There can be several
+// unversioned nodes for one object if it was concurrently uploaded through
+// different gateways, the last writer wins then.
+func latestNodeVersion(nodes []*data.NodeVersion) *data.NodeVersion {
+	latest := nodes[0]
+	for _, node := range nodes[1:] {
+		if node.Timestamp > latest.Timestamp || node.Timestamp == latest.Timestamp && node.ID > latest.ID {
+			latest = node
+		}
 	}
 
-	return nodes[0], nil
+	return latest
 }
 
-func (c *TreeClient) AddVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion) (uint64, error) {
+func (c *TreeClient) AddVersion(ctx context.Context, bktInfo *data.BucketInfo, version *data.NodeVersion) (uint64, []*data.NodeVersion, error) {
 	return c.addVersion(ctx, bktInfo, versionTree, version)
 }
 
@@ -1041,7 +1284,7 @@ func (c *TreeClient) Close() error {
 	return nil
 }
 
-func (c *TreeClient) addVersion(ctx context.Context, bktInfo *data.BucketInfo, treeID string, version *data.NodeVersion) (uint64, error) {
+func (c *TreeClient) addVersion(ctx context.Context, bktInfo *data.BucketInfo, treeID string, version *data.NodeVersion) (uint64, []*data.NodeVersion, error) {
 	path := pathFromName(version.FilePath)
 	meta := map[string]string{
 		oidKV:      version.OID.EncodeToString(),
@@ -1064,21 +1307,35 @@ func (c *TreeClient) addVersion(ctx context.Context, bktInfo *data.BucketInfo, t
 	if version.IsUnversioned {
 		meta[isUnversionedKV] = "true"
 
-		node, err := c.getUnversioned(ctx, bktInfo, treeID, version.FilePath)
-		if err == nil {
+		nodes, err := c.getVersions(ctx, bktInfo, treeID, version.FilePath, true)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if len(nodes) != 0 {
+			node := latestNodeVersion(nodes)
 			if err = c.moveNode(ctx, bktInfo, treeID, node.ID, node.ParenID, meta); err != nil {
-				return 0, err
+				return 0, nil, err
 			}
 
-			return node.ID, c.clearOutdatedVersionInfo(ctx, bktInfo, treeID, node.ID)
-		}
+			// Drop duplicates left by concurrent uploads, so the object has
+			// a single unversioned node again.
+			var removed []*data.NodeVersion
+			for _, dup := range nodes {
+				if dup.ID != node.ID {
+					if err = c.removeNode(ctx, bktInfo, treeID, dup.ID); err != nil {
+						return 0, removed, fmt.Errorf("remove duplicated unversioned node: %w", err)
+					}
+					removed = append(removed, dup)
+				}
+			}
 
-		if !errors.Is(err, layer.ErrNodeNotFound) {
-			return 0, err
+			return node.ID, removed, c.clearOutdatedVersionInfo(ctx, bktInfo, treeID, node.ID)
 		}
 	}
 
-	return c.addNodeByPath(ctx, bktInfo, treeID, path[:len(path)-1], meta)
+	id, err := c.addNodeByPath(ctx, bktInfo, treeID, path[:len(path)-1], meta)
+	return id, nil, err
 }
 
 func (c *TreeClient) clearOutdatedVersionInfo(ctx context.Context, bktInfo *data.BucketInfo, treeID string, nodeID uint64) error {
@@ -1140,7 +1397,7 @@ func (c *TreeClient) getSubTree(ctx context.Context, bktInfo *data.BucketInfo, t
 		},
 	}
 
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
@@ -1169,11 +1426,32 @@ func (c *TreeClient) getSubTree(ctx context.Context, bktInfo *data.BucketInfo, t
 }
 
 func metaFromSettings(settings *data.BucketSettings) map[string]string {
-	results := make(map[string]string, 3)
+	results := make(map[string]string, 5)
 
 	results[fileNameKV] = settingsFileName
+	results[schemaVersionKV] = strconv.Itoa(data.SettingsSchemaVersion)
+	results[revisionKV] = strconv.FormatUint(settings.Revision, 10)
 	results[versioningKV] = settings.Versioning
 	results[lockConfigurationKV] = encodeLockConfiguration(settings.LockConfiguration)
+	results[compressionKV] = settings.Compression
+	results[deduplicationKV] = strconv.FormatBool(settings.Deduplication)
+	if settings.UploadConstraints != nil {
+		// Marshaling of the plain struct can't fail.
+		constraints, _ := json.Marshal(settings.UploadConstraints)
+		results[uploadConstraintsKV] = string(constraints)
+	}
+	if settings.AnonymousAccess != nil {
+		access, _ := json.Marshal(settings.AnonymousAccess)
+		results[anonymousAccessKV] = string(access)
+	}
+	if settings.Deletion != nil {
+		deletion, _ := json.Marshal(settings.Deletion)
+		results[deletionKV] = string(deletion)
+	}
+	if settings.Privacy != nil {
+		privacy, _ := json.Marshal(settings.Privacy)
+		results[privacyKV] = string(privacy)
+	}
 
 	return results
 }
@@ -1232,7 +1510,7 @@ func (c *TreeClient) getNodes(ctx context.Context, p *getNodesParams) ([]*tree.G
 		},
 	}
 
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
@@ -1279,7 +1557,7 @@ func (c *TreeClient) addNode(ctx context.Context, bktInfo *data.BucketInfo, tree
 			BearerToken: getBearer(ctx, bktInfo),
 		},
 	}
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
@@ -1308,7 +1586,7 @@ func (c *TreeClient) addNodeByPath(ctx context.Context, bktInfo *data.BucketInfo
 		},
 	}
 
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
@@ -1345,7 +1623,7 @@ func (c *TreeClient) moveNode(ctx context.Context, bktInfo *data.BucketInfo, tre
 		},
 	}
 
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
@@ -1370,7 +1648,7 @@ func (c *TreeClient) removeNode(ctx context.Context, bktInfo *data.BucketInfo, t
 			BearerToken: getBearer(ctx, bktInfo),
 		},
 	}
-	if err := c.signRequest(request.Body, func(key, sign []byte) {
+	if err := c.signRequest(ctx, request.Body, func(key, sign []byte) {
 		request.Signature = &tree.Signature{
 			Key:  key,
 			Sign: sign,
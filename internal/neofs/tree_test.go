@@ -121,3 +121,51 @@ func TestHandleError(t *testing.T) {
 		})
 	}
 }
+
+func TestLatestNodeVersion(t *testing.T) {
+	nodes := []*data.NodeVersion{
+		{BaseNodeVersion: data.BaseNodeVersion{ID: 1, Timestamp: 10}},
+		{BaseNodeVersion: data.BaseNodeVersion{ID: 3, Timestamp: 20}},
+		{BaseNodeVersion: data.BaseNodeVersion{ID: 2, Timestamp: 20}},
+		{BaseNodeVersion: data.BaseNodeVersion{ID: 4, Timestamp: 15}},
+	}
+
+	require.Equal(t, uint64(3), latestNodeVersion(nodes).ID)
+	require.Equal(t, uint64(1), latestNodeVersion(nodes[:1]).ID)
+}
+
+func TestBucketSettingsEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		settings data.BucketSettings
+	}{
+		{
+			name: "defaults",
+			settings: data.BucketSettings{
+				Versioning:        data.VersioningUnversioned,
+				LockConfiguration: &data.ObjectLockConfiguration{},
+			},
+		},
+		{
+			name: "anonymous access",
+			settings: data.BucketSettings{
+				Versioning:        data.VersioningEnabled,
+				LockConfiguration: &data.ObjectLockConfiguration{},
+				Deduplication:     true,
+				Revision:          3,
+				AnonymousAccess:   &data.AnonymousAccess{Mode: "ReadWithoutListing"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := newBucketSettings(&TreeNode{Meta: metaFromSettings(&tc.settings)})
+			require.NoError(t, err)
+
+			tc.settings.SchemaVersion = data.SettingsSchemaVersion
+			require.Equal(t, &tc.settings, settings)
+		})
+	}
+
+	_, err := newBucketSettings(&TreeNode{Meta: map[string]string{anonymousAccessKV: "{"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+// Package ratelimit limits the rate of requests of every access key.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often buckets refilled to the full capacity are
+// dropped, they're identical to new ones.
+const cleanupInterval = time.Minute
+
+type (
+	// Limiter is a set of token buckets of requests keyed by access key IDs,
+	// every bucket is refilled at the constant rate up to its burst. Nil
+	// Limiter doesn't limit.
+	Limiter struct {
+		rate  float64
+		burst float64
+		now   func() time.Time
+
+		mu          sync.Mutex
+		buckets     map[string]*bucket
+		lastCleanup time.Time
+	}
+
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// NewLimiter creates a limiter of rps requests per second for every key
+// with up to burst requests at once, burst is the rate rounded up if it isn't
+// positive. The limiter is nil if rps isn't positive.
+func NewLimiter(rps float64, burst int) *Limiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = int(math.Ceil(rps))
+	}
+
+	return &Limiter{
+		rate:        rps,
+		burst:       float64(burst),
+		now:         time.Now,
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a request from the bucket of the key, it returns false if the
+// bucket is empty, the request must be rejected then.
+func (l *Limiter) Allow(key string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) >= cleanupInterval {
+		l.cleanup(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.refill(now, l.rate, l.burst)
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+func (l *Limiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if b.refill(now, l.rate, l.burst); b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*rate)
+		b.last = now
+	}
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	require.Nil(t, NewLimiter(0, 10))
+	require.True(t, (*Limiter)(nil).Allow("key"))
+
+	now := time.Now()
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	// the full burst is allowed at once
+	for i := 0; i < 3; i++ {
+		require.True(t, l.Allow("key"))
+	}
+	require.False(t, l.Allow("key"))
+
+	// other keys have their own buckets
+	require.True(t, l.Allow("other"))
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, l.Allow("key"))
+	require.False(t, l.Allow("key"))
+
+	// buckets aren't refilled over the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.True(t, l.Allow("key"))
+	}
+	require.False(t, l.Allow("key"))
+
+	// idle buckets are dropped
+	require.Len(t, l.buckets, 1)
+	now = now.Add(cleanupInterval)
+	require.True(t, l.Allow("another"))
+	require.Len(t, l.buckets, 1)
+
+	require.EqualValues(t, 3, NewLimiter(2.5, 0).burst)
+}
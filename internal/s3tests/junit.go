@@ -0,0 +1,91 @@
+package s3tests
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Test results as they are written in docs/s3_test_results.md.
+const (
+	ResultOK          = "ok"
+	ResultFail        = "FAIL"
+	ResultError       = "ERROR"
+	ResultSkip        = "SKIP"
+	ResultUnsupported = "UNSUPPORTED"
+)
+
+// Result is a result of a single test of the suite.
+type Result struct {
+	// Name is the full test name, e.g. s3tests_boto3.functional.test_s3.test_bucket_list_empty.
+	Name    string
+	Result  string
+	Message string
+}
+
+type (
+	junitSuites struct {
+		Suites []junitSuite `xml:"testsuite"`
+	}
+
+	junitSuite struct {
+		Cases []junitCase `xml:"testcase"`
+	}
+
+	junitCase struct {
+		ClassName string        `xml:"classname,attr"`
+		Name      string        `xml:"name,attr"`
+		Failure   *junitMessage `xml:"failure"`
+		Error     *junitMessage `xml:"error"`
+		Skipped   *junitMessage `xml:"skipped"`
+	}
+
+	junitMessage struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// ParseJUnit reads results from JUnit XML report produced by pytest
+// (--junitxml) or nose (--with-xunit).
+func ParseJUnit(r io.Reader) ([]Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+
+	// pytest wraps suites into testsuites element, nose writes a single suite
+	var suites junitSuites
+	if err = xml.Unmarshal(data, &suites); err != nil {
+		return nil, fmt.Errorf("decode report: %w", err)
+	}
+	if len(suites.Suites) == 0 {
+		var suite junitSuite
+		if err = xml.Unmarshal(data, &suite); err != nil {
+			return nil, fmt.Errorf("decode report: %w", err)
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	var results []Result
+	for _, suite := range suites.Suites {
+		for _, c := range suite.Cases {
+			res := Result{Name: c.Name, Result: ResultOK}
+			if c.ClassName != "" {
+				res.Name = c.ClassName + "." + c.Name
+			}
+
+			switch {
+			case c.Error != nil:
+				res.Result, res.Message = ResultError, c.Error.Message
+			case c.Failure != nil:
+				res.Result, res.Message = ResultFail, c.Failure.Message
+			case c.Skipped != nil:
+				res.Result, res.Message = ResultSkip, c.Skipped.Message
+			}
+
+			results = append(results, res)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,122 @@
+package s3tests
+
+import (
+	"time"
+)
+
+// OthersGroup contains tests missing in the table of known results.
+const OthersGroup = "Others"
+
+type (
+	// Report is a machine-readable conformance report.
+	Report struct {
+		Gateway string        `json:"gateway,omitempty"`
+		Created time.Time     `json:"created"`
+		Summary Summary       `json:"summary"`
+		Groups  []GroupReport `json:"groups"`
+		Tests   []TestReport  `json:"tests"`
+	}
+
+	// Summary contains numbers of tests by result.
+	Summary struct {
+		Total   int `json:"total"`
+		Passed  int `json:"passed"`
+		Failed  int `json:"failed"`
+		Errors  int `json:"errors"`
+		Skipped int `json:"skipped"`
+	}
+
+	// GroupReport contains results of tests of an API group.
+	GroupReport struct {
+		Name string `json:"name"`
+		Summary
+		// NewlyPassed are tests which pass now, but didn't before.
+		NewlyPassed []string `json:"newly_passed,omitempty"`
+		// Regressed are tests which passed before, but don't now.
+		Regressed []string `json:"regressed,omitempty"`
+	}
+
+	// TestReport is a result of a single test.
+	TestReport struct {
+		Name     string `json:"name"`
+		Group    string `json:"group"`
+		Result   string `json:"result"`
+		Previous string `json:"previous,omitempty"`
+		Message  string `json:"message,omitempty"`
+	}
+)
+
+// NewReport groups results and compares them with the known ones. Groups
+// are ordered like in groups, unknown ones are appended.
+func NewReport(results []Result, known map[string]KnownResult, groups []string) *Report {
+	report := &Report{
+		Created: time.Now().UTC(),
+		Tests:   make([]TestReport, 0, len(results)),
+	}
+
+	index := make(map[string]int, len(groups))
+	for _, group := range groups {
+		index[group] = len(report.Groups)
+		report.Groups = append(report.Groups, GroupReport{Name: group})
+	}
+
+	for _, res := range results {
+		test := TestReport{
+			Name:    res.Name,
+			Group:   OthersGroup,
+			Result:  res.Result,
+			Message: res.Message,
+		}
+
+		if prev, ok := known[res.Name]; ok {
+			test.Group, test.Previous = prev.Group, prev.Result
+		}
+
+		i, ok := index[test.Group]
+		if !ok {
+			i = len(report.Groups)
+			index[test.Group] = i
+			report.Groups = append(report.Groups, GroupReport{Name: test.Group})
+		}
+
+		group := &report.Groups[i]
+		group.Summary.add(test.Result)
+		report.Summary.add(test.Result)
+
+		if test.Previous != "" {
+			switch {
+			case test.Result == ResultOK && test.Previous != ResultOK:
+				group.NewlyPassed = append(group.NewlyPassed, test.Name)
+			case test.Result != ResultOK && test.Previous == ResultOK:
+				group.Regressed = append(group.Regressed, test.Name)
+			}
+		}
+
+		report.Tests = append(report.Tests, test)
+	}
+
+	return report
+}
+
+// Regressions returns all tests which passed before, but don't now.
+func (r *Report) Regressions() []string {
+	var res []string
+	for _, group := range r.Groups {
+		res = append(res, group.Regressed...)
+	}
+	return res
+}
+
+func (s *Summary) add(result string) {
+	s.Total++
+	switch result {
+	case ResultOK:
+		s.Passed++
+	case ResultFail:
+		s.Failed++
+	case ResultError:
+		s.Errors++
+	case ResultSkip:
+		s.Skipped++
+	}
+}
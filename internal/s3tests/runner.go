@@ -0,0 +1,78 @@
+package s3tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// DefaultTests are the suite tests run if none are specified.
+var DefaultTests = []string{"s3tests_boto3/functional"}
+
+// RunConfig contains parameters of the suite run.
+type RunConfig struct {
+	// SuiteDir is a checkout of https://github.com/ceph/s3-tests.
+	SuiteDir string
+	// Config is the suite configuration file pointing to the gateway.
+	Config string
+	// Python is the interpreter with the suite requirements installed.
+	Python string
+	// Tests are pytest selectors of the tests to run.
+	Tests []string
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run executes the suite with pytest and returns results of the tests.
+func Run(ctx context.Context, cfg RunConfig) ([]Result, error) {
+	config, err := filepath.Abs(cfg.Config)
+	if err != nil {
+		return nil, fmt.Errorf("config path: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp("", "s3tests")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	junit := filepath.Join(tmp, "junit.xml")
+
+	tests := cfg.Tests
+	if len(tests) == 0 {
+		tests = DefaultTests
+	}
+
+	python := cfg.Python
+	if python == "" {
+		python = "python3"
+	}
+
+	args := append([]string{"-m", "pytest", "-v", "--junitxml=" + junit}, tests...)
+	cmd := exec.CommandContext(ctx, python, args...)
+	cmd.Dir = cfg.SuiteDir
+	cmd.Env = append(os.Environ(), "S3TEST_CONF="+config)
+	cmd.Stdout = cfg.Stdout
+	cmd.Stderr = cfg.Stderr
+
+	// pytest exits with code 1 if some tests failed, it's a regular result
+	if err = cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("run suite: %w", err)
+		}
+	}
+
+	f, err := os.Open(junit)
+	if err != nil {
+		return nil, fmt.Errorf("open junit report: %w", err)
+	}
+	defer f.Close()
+
+	return ParseJUnit(f)
+}
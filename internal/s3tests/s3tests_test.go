@@ -0,0 +1,113 @@
+package s3tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pytestReport = `<?xml version="1.0" encoding="utf-8"?>
+<testsuites><testsuite name="pytest" tests="4">
+<testcase classname="s3tests_boto3.functional.test_s3" name="test_bucket_list_empty"/>
+<testcase classname="s3tests_boto3.functional.test_s3" name="test_object_copy_not_owned_bucket"><failure message="AssertionError"/></testcase>
+<testcase classname="s3tests_boto3.functional.test_s3" name="test_object_copy_not_owned_object_bucket"/>
+<testcase classname="s3tests_boto3.functional.test_s3" name="test_new_api"><skipped message="not configured"/></testcase>
+</testsuite></testsuites>`
+
+	noseReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="nosetests" tests="1">
+<testcase classname="s3tests_boto3.functional.test_s3" name="test_bucket_list_empty"><error message="ClientError"/></testcase>
+</testsuite>`
+
+	resultsTable = `# S3 compatibility test results
+
+## CopyObject
+
+|     | Test                                                                      | s3-gw | minio | aws s3 |
+|-----|---------------------------------------------------------------------------|-------|-------|--------|
+| 1   | s3tests_boto3.functional.test_s3.test_object_copy_not_owned_bucket        | ok    | FAIL  | ok     |
+| 2   | s3tests_boto3.functional.test_s3.test_object_copy_not_owned_object_bucket | ERROR | ok    | ok     |
+
+## ListObjects
+
+|     | Test                                                | s3-gw | minio | aws s3 |
+|-----|-----------------------------------------------------|-------|-------|--------|
+| 1   | s3tests_boto3.functional.test_s3.test_bucket_list_empty | ok    | ok    | ok     |
+`
+)
+
+func TestParseJUnit(t *testing.T) {
+	results, err := ParseJUnit(strings.NewReader(pytestReport))
+	require.NoError(t, err)
+	require.Equal(t, []Result{
+		{Name: "s3tests_boto3.functional.test_s3.test_bucket_list_empty", Result: ResultOK},
+		{Name: "s3tests_boto3.functional.test_s3.test_object_copy_not_owned_bucket", Result: ResultFail, Message: "AssertionError"},
+		{Name: "s3tests_boto3.functional.test_s3.test_object_copy_not_owned_object_bucket", Result: ResultOK},
+		{Name: "s3tests_boto3.functional.test_s3.test_new_api", Result: ResultSkip, Message: "not configured"},
+	}, results)
+
+	results, err = ParseJUnit(strings.NewReader(noseReport))
+	require.NoError(t, err)
+	require.Equal(t, []Result{
+		{Name: "s3tests_boto3.functional.test_s3.test_bucket_list_empty", Result: ResultError, Message: "ClientError"},
+	}, results)
+
+	_, err = ParseJUnit(strings.NewReader("not xml"))
+	require.Error(t, err)
+}
+
+func TestNewReport(t *testing.T) {
+	known, groups, err := ParseResultsTable(strings.NewReader(resultsTable))
+	require.NoError(t, err)
+	require.Equal(t, []string{"CopyObject", "ListObjects"}, groups)
+	require.Equal(t, KnownResult{Group: "CopyObject", Result: ResultError},
+		known["s3tests_boto3.functional.test_s3.test_object_copy_not_owned_object_bucket"])
+
+	results, err := ParseJUnit(strings.NewReader(pytestReport))
+	require.NoError(t, err)
+
+	report := NewReport(results, known, groups)
+	require.Equal(t, Summary{Total: 4, Passed: 2, Failed: 1, Skipped: 1}, report.Summary)
+	require.Len(t, report.Groups, 3)
+
+	copyGroup := report.Groups[0]
+	require.Equal(t, "CopyObject", copyGroup.Name)
+	require.Equal(t, Summary{Total: 2, Passed: 1, Failed: 1}, copyGroup.Summary)
+	require.Equal(t, []string{"s3tests_boto3.functional.test_s3.test_object_copy_not_owned_object_bucket"}, copyGroup.NewlyPassed)
+	require.Equal(t, []string{"s3tests_boto3.functional.test_s3.test_object_copy_not_owned_bucket"}, copyGroup.Regressed)
+
+	require.Equal(t, OthersGroup, report.Groups[2].Name)
+	require.Equal(t, Summary{Total: 1, Skipped: 1}, report.Groups[2].Summary)
+	require.Equal(t, copyGroup.Regressed, report.Regressions())
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	// fake interpreter writes the report to --junitxml path and fails like pytest with failed tests
+	python := filepath.Join(dir, "python")
+	script := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --junitxml=*) cat "$S3TEST_CONF" > "${arg#--junitxml=}" ;;
+  esac
+done
+exit 1
+`
+	require.NoError(t, os.WriteFile(python, []byte(script), 0o755))
+
+	conf := filepath.Join(dir, "report.xml")
+	require.NoError(t, os.WriteFile(conf, []byte(noseReport), 0o644))
+
+	results, err := Run(context.Background(), RunConfig{SuiteDir: dir, Config: conf, Python: python})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	_, err = Run(context.Background(), RunConfig{SuiteDir: dir, Config: conf, Python: filepath.Join(dir, "missing")})
+	require.Error(t, err)
+}
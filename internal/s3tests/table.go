@@ -0,0 +1,53 @@
+package s3tests
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KnownResult is a test result recorded in docs/s3_test_results.md.
+type KnownResult struct {
+	Group  string
+	Result string
+}
+
+// ParseResultsTable reads groups and gateway results of tests from the
+// markdown file with tables of test results.
+func ParseResultsTable(r io.Reader) (map[string]KnownResult, []string, error) {
+	var (
+		group   string
+		groups  []string
+		known   = make(map[string]KnownResult)
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "## ") {
+			group = strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			groups = append(groups, group)
+			continue
+		}
+
+		// | 1   | s3tests_boto3.functional.test_s3.test_name | s3-gw | minio | aws s3 |
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		if len(cells) < 3 {
+			continue
+		}
+
+		name := strings.TrimSpace(cells[1])
+		if !strings.HasPrefix(name, "s3tests") {
+			continue
+		}
+
+		known[name] = KnownResult{Group: group, Result: strings.TrimSpace(cells[2])}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read results table: %w", err)
+	}
+
+	return known, groups, nil
+}
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/nspcc-dev/neo-go/cli/flags"
@@ -21,6 +22,24 @@ func GetPassword(v *viper.Viper, variable string) *string {
 	return password
 }
 
+// GetKeyFromString parses the private key encoded as WIF or hex string.
+func GetKeyFromString(key string) (*keys.PrivateKey, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key must not be empty")
+	}
+
+	if k, err := keys.NewPrivateKeyFromWIF(key); err == nil {
+		return k, nil
+	}
+
+	k, err := keys.NewPrivateKeyFromHex(key)
+	if err != nil {
+		return nil, errors.New("key is neither valid WIF nor hex")
+	}
+
+	return k, nil
+}
+
 // GetKeyFromPath reads a wallet and gets the private key.
 func GetKeyFromPath(walletPath, addrStr string, password *string) (*keys.PrivateKey, error) {
 	if len(walletPath) == 0 {
@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetKeyFromString(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	for name, raw := range map[string]string{
+		"wif": key.WIF(),
+		"hex": hex.EncodeToString(key.Bytes()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := GetKeyFromString(raw)
+			require.NoError(t, err)
+			require.Equal(t, key.Bytes(), res.Bytes())
+		})
+	}
+
+	_, err = GetKeyFromString("")
+	require.Error(t, err)
+
+	_, err = GetKeyFromString("invalid")
+	require.Error(t, err)
+}
+
+func TestGetKeyFromPath(t *testing.T) {
+	const password = "pass"
+
+	walletPath := filepath.Join(t.TempDir(), "wallet.json")
+	w, err := wallet.NewWallet(walletPath)
+	require.NoError(t, err)
+
+	var accKeys []*keys.PrivateKey
+	for i := 0; i < 2; i++ {
+		key, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+
+		acc := wallet.NewAccountFromPrivateKey(key)
+		require.NoError(t, acc.Encrypt(password, w.Scrypt))
+		w.AddAccount(acc)
+		accKeys = append(accKeys, key)
+	}
+	require.NoError(t, w.Save())
+
+	pwd := password
+	key, err := GetKeyFromPath(walletPath, accKeys[1].Address(), &pwd)
+	require.NoError(t, err)
+	require.Equal(t, accKeys[1].Bytes(), key.Bytes())
+
+	key, err = GetKeyFromPath(walletPath, "", &pwd)
+	require.NoError(t, err)
+	require.Equal(t, accKeys[0].Bytes(), key.Bytes())
+
+	wrong := "wrong"
+	_, err = GetKeyFromPath(walletPath, accKeys[1].Address(), &wrong)
+	require.Error(t, err)
+
+	_, err = GetKeyFromPath(walletPath, "NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP", &pwd)
+	require.Error(t, err)
+
+	_, err = GetKeyFromPath("", "", &pwd)
+	require.Error(t, err)
+}
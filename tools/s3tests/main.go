@@ -0,0 +1,175 @@
+// Command s3tests runs ceph/s3-tests suite against a running gateway and
+// produces a machine-readable conformance report.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/s3tests"
+)
+
+func main() {
+	os.Exit(execute())
+}
+
+func execute() int {
+	var (
+		suite      = flag.String("suite", "s3-tests", "ceph/s3-tests checkout directory")
+		conf       = flag.String("conf", "s3tests.conf", "s3-tests configuration file pointing to the gateway")
+		python     = flag.String("python", "python3", "python interpreter with s3-tests requirements installed")
+		junit      = flag.String("junit", "", "build report from existing JUnit XML instead of running the suite")
+		results    = flag.String("results", "docs/s3_test_results.md", "known results to group tests and detect changes")
+		endpoint   = flag.String("endpoint", "", "gateway URL to get its version from capabilities (optional)")
+		output     = flag.String("o", "-", "report file, '-' for stdout")
+		regression = flag.Bool("fail-on-regression", false, "exit with non-zero code if some tests regressed")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [tests...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := run(ctx, *suite, *conf, *python, *junit, *results, *endpoint, flag.Args())
+	if err == nil {
+		err = writeReport(report, *output)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "passed %d/%d, failed %d, errors %d, skipped %d\n", report.Summary.Passed,
+		report.Summary.Total, report.Summary.Failed, report.Summary.Errors, report.Summary.Skipped)
+
+	if regressed := report.Regressions(); len(regressed) > 0 {
+		fmt.Fprintf(os.Stderr, "regressed:\n  %s\n", strings.Join(regressed, "\n  "))
+		if *regression {
+			return 2
+		}
+	}
+
+	return 0
+}
+
+func run(ctx context.Context, suite, conf, python, junit, results, endpoint string, tests []string) (*s3tests.Report, error) {
+	known, groups, err := readKnownResults(results)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []s3tests.Result
+	if junit != "" {
+		f, err := os.Open(junit)
+		if err != nil {
+			return nil, fmt.Errorf("open junit report: %w", err)
+		}
+		defer f.Close()
+
+		if res, err = s3tests.ParseJUnit(f); err != nil {
+			return nil, err
+		}
+	} else {
+		res, err = s3tests.Run(ctx, s3tests.RunConfig{
+			SuiteDir: suite,
+			Config:   conf,
+			Python:   python,
+			Tests:    tests,
+			Stdout:   os.Stderr,
+			Stderr:   os.Stderr,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := s3tests.NewReport(res, known, groups)
+
+	if endpoint != "" {
+		if report.Gateway, err = gatewayVersion(ctx, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func readKnownResults(path string) (map[string]s3tests.KnownResult, []string, error) {
+	if path == "" {
+		return nil, nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("open known results: %w", err)
+	}
+	defer f.Close()
+
+	return s3tests.ParseResultsTable(f)
+}
+
+func gatewayVersion(ctx context.Context, endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+api.CapabilitiesPath, nil)
+	if err != nil {
+		return "", fmt.Errorf("capabilities request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("get capabilities: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get capabilities: unexpected status %s", resp.Status)
+	}
+
+	var capabilities struct {
+		Version string `json:"version"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&capabilities); err != nil {
+		return "", fmt.Errorf("decode capabilities: %w", err)
+	}
+
+	return capabilities.Version, nil
+}
+
+func writeReport(report *s3tests.Report, output string) error {
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return nil
+}